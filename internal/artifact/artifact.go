@@ -14,9 +14,11 @@ import (
 	"io"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
 )
 
 // Type defines the type of an artifact
@@ -43,24 +45,81 @@ const (
 	Checksum
 	// Signature is a signature file
 	Signature
+	// Config is the effective configuration used for the release
+	Config
+	// UploadableSourceArchive is the archive of the project source code
+	UploadableSourceArchive
+	// ArchiveManifest lists the files of an archive, with their checksums
+	ArchiveManifest
+	// SBOM is a software bill of materials of another artifact
+	SBOM
+	// Certificate is the certificate of a signature, e.g. from keyless
+	// signing
+	Certificate
+	// Provenance is an in-toto attestation of how the artifacts were built
+	Provenance
+	// DockerManifest is a published manifest list of docker images
+	DockerManifest
+	// UploadableDockerImage is a docker image saved as a tarball, to be
+	// uploaded
+	UploadableDockerImage
+	// Flatpak is a flatpak single-file bundle
+	Flatpak
+	// AppImage is a self-contained linux executable
+	AppImage
+	// MSI is a windows installer
+	MSI
+	// MacOSPackage is a macOS installer package (.pkg)
+	MacOSPackage
+	// UploadableFile is a file not built by goreleaser, to be uploaded
+	UploadableFile
+	// MirrorReport lists the files served by the mirrors, with their
+	// checksums
+	MirrorReport
 )
 
 func (t Type) String() string {
 	switch t {
 	case UploadableArchive:
 		return "Archive"
-	case UploadableBinary:
-	case Binary:
+	case UploadableBinary, Binary:
 		return "Binary"
 	case LinuxPackage:
 		return "Linux Package"
-	case DockerImage:
-	case PublishableDockerImage:
+	case DockerImage, PublishableDockerImage:
 		return "Docker Image"
 	case Checksum:
 		return "Checksum"
 	case Signature:
 		return "Signature"
+	case Config:
+		return "Config"
+	case UploadableSourceArchive:
+		return "Source"
+	case ArchiveManifest:
+		return "Archive Manifest"
+	case SBOM:
+		return "SBOM"
+	case Certificate:
+		return "Certificate"
+	case Provenance:
+		return "Provenance"
+	case DockerManifest:
+		return "Docker Manifest"
+	case UploadableDockerImage:
+		return "Docker Image Archive"
+	case Flatpak:
+		return "Flatpak"
+	case AppImage:
+		return "AppImage"
+	case MSI:
+		return "MSI"
+	case MacOSPackage:
+		return "macOS Package"
+	case UploadableFile:
+		return "File"
+	case MirrorReport:
+		return "Mirror Report"
 	}
 	return "unknown"
 }
@@ -85,15 +144,41 @@ func (a Artifact) ExtraOr(key string, or interface{}) interface{} {
 	return a.Extra[key]
 }
 
+// digest is a checksum computed for a file in a given state.
+type digest struct {
+	size    int64
+	modTime time.Time
+	sum     string
+}
+
+// digests caches the checksums already computed for each path and algorithm,
+// so pipes that need the same checksum don't read multi-GB files again.
+// nolint: gochecknoglobals
+var digests sync.Map
+
 // Checksum calculates the checksum of the artifact.
+// The file is streamed through the hash, and the result is reused by later
+// calls as long as the file doesn't change.
 // nolint: gosec
 func (a Artifact) Checksum(algorithm string) (string, error) {
-	log.Debugf("calculating checksum for %s", a.Path)
 	file, err := os.Open(a.Path)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to checksum")
 	}
 	defer file.Close() // nolint: errcheck
+	info, err := file.Stat()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to checksum")
+	}
+	var key = algorithm + ":" + a.Path
+	if cached, ok := digests.Load(key); ok {
+		var d = cached.(digest)
+		if d.size == info.Size() && d.modTime.Equal(info.ModTime()) {
+			log.Debugf("reusing %s checksum for %s", algorithm, a.Path)
+			return d.sum, nil
+		}
+	}
+	log.Debugf("calculating checksum for %s", a.Path)
 	var h hash.Hash
 	switch algorithm {
 	case "crc32":
@@ -110,6 +195,8 @@ func (a Artifact) Checksum(algorithm string) (string, error) {
 		h = sha1.New()
 	case "sha512":
 		h = sha512.New()
+	case "blake2b":
+		h, _ = blake2b.New512(nil)
 	default:
 		return "", fmt.Errorf("invalid algorith: %s", algorithm)
 	}
@@ -117,7 +204,13 @@ func (a Artifact) Checksum(algorithm string) (string, error) {
 	if err != nil {
 		return "", errors.Wrap(err, "failed to checksum")
 	}
-	return hex.EncodeToString(h.Sum(nil)), nil
+	var sum = hex.EncodeToString(h.Sum(nil))
+	digests.Store(key, digest{
+		size:    info.Size(),
+		modTime: info.ModTime(),
+		sum:     sum,
+	})
+	return sum, nil
 }
 
 // Artifacts is a list of artifacts
@@ -161,6 +254,25 @@ func (artifacts *Artifacts) Add(a *Artifact) {
 	artifacts.items = append(artifacts.items, a)
 }
 
+// Remove removes the artifacts that match the given filter from the list
+func (artifacts *Artifacts) Remove(filter Filter) {
+	artifacts.lock.Lock()
+	defer artifacts.lock.Unlock()
+	var result = make([]*Artifact, 0, len(artifacts.items))
+	for _, a := range artifacts.items {
+		if filter(a) {
+			log.WithFields(log.Fields{
+				"name": a.Name,
+				"path": a.Path,
+				"type": a.Type,
+			}).Debug("removing artifact")
+			continue
+		}
+		result = append(result, a)
+	}
+	artifacts.items = result
+}
+
 // Filter defines an artifact filter which can be used within the Filter
 // function
 type Filter func(a *Artifact) bool
@@ -211,13 +323,50 @@ func ByIDs(ids ...string) Filter {
 	for _, id := range ids {
 		id := id
 		filters = append(filters, func(a *Artifact) bool {
-			// checksum are allways for all artifacts, so return always true.
-			return a.Type == Checksum || a.ExtraOr("ID", "") == id
+			// checksum, config and source are allways for all artifacts, so return always true.
+			// so are the signatures of artifacts without ids, and extra files.
+			return a.Type == Checksum ||
+				a.Type == Config ||
+				a.Type == UploadableFile ||
+				a.Type == UploadableSourceArchive ||
+				((a.Type == Signature || a.Type == Certificate) && a.ExtraOr("ID", "") == "") ||
+				a.ExtraOr("ID", "") == id
 		})
 	}
 	return Or(filters...)
 }
 
+// ByUploadable filters the artifacts that are released as files: the
+// archives, binaries, packages and installers, and the files describing them,
+// like checksums, signatures, SBOMs and provenance.
+func ByUploadable() Filter {
+	return Or(
+		ByType(UploadableArchive),
+		ByType(UploadableBinary),
+		ByType(UploadableSourceArchive),
+		ByType(LinuxPackage),
+		ByType(UploadableDockerImage),
+		ByType(Flatpak),
+		ByType(AppImage),
+		ByType(MSI),
+		ByType(MacOSPackage),
+		ByType(UploadableFile),
+		ByType(Checksum),
+		ByType(Signature),
+		ByType(Certificate),
+		ByType(ArchiveManifest),
+		ByType(SBOM),
+		ByType(Provenance),
+	)
+}
+
+// Not negates the given filter
+func Not(filter Filter) Filter {
+	return func(a *Artifact) bool {
+		return !filter(a)
+	}
+}
+
 // Or performs an OR between all given filters
 func Or(filters ...Filter) Filter {
 	return func(a *Artifact) bool {
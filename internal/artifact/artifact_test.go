@@ -45,6 +45,17 @@ func TestAdd(t *testing.T) {
 	assert.Len(t, artifacts.List(), 4)
 }
 
+func TestRemove(t *testing.T) {
+	var artifacts = New()
+	artifacts.Add(&Artifact{Name: "foo", Goos: "darwin", Type: Binary})
+	artifacts.Add(&Artifact{Name: "bar", Goos: "linux", Type: Binary})
+	artifacts.Add(&Artifact{Name: "foobar", Goos: "darwin", Type: UploadableArchive})
+	artifacts.Remove(And(ByGoos("darwin"), ByType(Binary)))
+	assert.Len(t, artifacts.List(), 2)
+	assert.Equal(t, "bar", artifacts.List()[0].Name)
+	assert.Equal(t, "foobar", artifacts.List()[1].Name)
+}
+
 func TestFilter(t *testing.T) {
 	var data = []*Artifact{
 		{
@@ -106,6 +117,45 @@ func TestFilter(t *testing.T) {
 	).List(), 2)
 }
 
+func TestByUploadable(t *testing.T) {
+	var artifacts = New()
+	for _, typ := range []Type{
+		UploadableArchive,
+		UploadableBinary,
+		Binary,
+		PublishableDockerImage,
+		DockerImage,
+		Checksum,
+		Signature,
+		Config,
+		SBOM,
+		UploadableFile,
+	} {
+		artifacts.Add(&Artifact{Name: typ.String(), Type: typ})
+	}
+	var names = func(filter Filter) []string {
+		var result []string
+		for _, a := range artifacts.Filter(filter).List() {
+			result = append(result, a.Name)
+		}
+		return result
+	}
+	assert.ElementsMatch(t, []string{
+		UploadableArchive.String(),
+		UploadableBinary.String(),
+		Checksum.String(),
+		Signature.String(),
+		SBOM.String(),
+		UploadableFile.String(),
+	}, names(ByUploadable()))
+	assert.ElementsMatch(t, []string{
+		UploadableArchive.String(),
+		UploadableBinary.String(),
+		SBOM.String(),
+		UploadableFile.String(),
+	}, names(And(ByUploadable(), Not(Or(ByType(Checksum), ByType(Signature))))))
+}
+
 func TestGroupByPlatform(t *testing.T) {
 	var data = []*Artifact{
 		{
@@ -150,13 +200,14 @@ func TestChecksum(t *testing.T) {
 	}
 
 	for algo, result := range map[string]string{
-		"sha256": "5e2bf57d3f40c4b6df69daf1936cb766f832374b4fc0259a7cbff06e2f70f269",
-		"sha512": "f80eebd9aabb1a15fb869ed568d858a5c0dca3d5da07a410e1bd988763918d973e344814625f7c844695b2de36ffd27af290d0e34362c51dee5947d58d40527a",
-		"sha1":   "bfb7759a67daeb65410490b4d98bb9da7d1ea2ce",
-		"crc32":  "72d7748e",
-		"md5":    "80a751fde577028640c419000e33eba6",
-		"sha224": "e191edf06005712583518ced92cc2ac2fac8d6e4623b021a50736a91",
-		"sha384": "597493a6cf1289757524e54dfd6f68b332c7214a716a3358911ef5c09907adc8a654a18c1d721e183b0025f996f6e246",
+		"sha256":  "5e2bf57d3f40c4b6df69daf1936cb766f832374b4fc0259a7cbff06e2f70f269",
+		"sha512":  "f80eebd9aabb1a15fb869ed568d858a5c0dca3d5da07a410e1bd988763918d973e344814625f7c844695b2de36ffd27af290d0e34362c51dee5947d58d40527a",
+		"sha1":    "bfb7759a67daeb65410490b4d98bb9da7d1ea2ce",
+		"crc32":   "72d7748e",
+		"md5":     "80a751fde577028640c419000e33eba6",
+		"sha224":  "e191edf06005712583518ced92cc2ac2fac8d6e4623b021a50736a91",
+		"sha384":  "597493a6cf1289757524e54dfd6f68b332c7214a716a3358911ef5c09907adc8a654a18c1d721e183b0025f996f6e246",
+		"blake2b": "ca0dbbe27fca7e5d97b612a76b66d9d42fd67ece4265a50c09ccaefcdc03d9d5a87fa1fddc926ae10c6667342c69df5c33117cf636fca82ac1377c2b4e23e2bc",
 	} {
 		t.Run(algo, func(t *testing.T) {
 			sum, err := artifact.Checksum(algo)
@@ -166,6 +217,29 @@ func TestChecksum(t *testing.T) {
 	}
 }
 
+func TestChecksumChangedFile(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	var file = filepath.Join(folder, "subject")
+	require.NoError(t, ioutil.WriteFile(file, []byte("lorem ipsum"), 0644))
+	var artifact = Artifact{
+		Path: file,
+	}
+
+	sum, err := artifact.Checksum("md5")
+	require.NoError(t, err)
+	require.Equal(t, "80a751fde577028640c419000e33eba6", sum)
+
+	sum, err = artifact.Checksum("md5")
+	require.NoError(t, err)
+	require.Equal(t, "80a751fde577028640c419000e33eba6", sum)
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("lorem ipsum dolor"), 0644))
+	sum, err = artifact.Checksum("md5")
+	require.NoError(t, err)
+	require.NotEqual(t, "80a751fde577028640c419000e33eba6", sum)
+}
+
 func TestChecksumFileDoesntExist(t *testing.T) {
 	var artifact = Artifact{
 		Path: "/tmp/adasdasdas/asdasd/asdas",
@@ -226,15 +300,26 @@ func TestByIDs(t *testing.T) {
 			Name: "checksum",
 			Type: Checksum,
 		},
+		{
+			Name: "checksum.sig",
+			Type: Signature,
+		},
+		{
+			Name: "foo.sig",
+			Type: Signature,
+			Extra: map[string]interface{}{
+				"ID": "foo",
+			},
+		},
 	}
 	var artifacts = New()
 	for _, a := range data {
 		artifacts.Add(a)
 	}
 
-	require.Len(t, artifacts.Filter(ByIDs("check")).items, 2)
-	require.Len(t, artifacts.Filter(ByIDs("foo")).items, 3)
-	require.Len(t, artifacts.Filter(ByIDs("foo", "bar")).items, 4)
+	require.Len(t, artifacts.Filter(ByIDs("check")).items, 3)
+	require.Len(t, artifacts.Filter(ByIDs("foo")).items, 5)
+	require.Len(t, artifacts.Filter(ByIDs("foo", "bar")).items, 6)
 }
 
 func TestByFormats(t *testing.T) {
@@ -0,0 +1,135 @@
+// Package bazel provides a builder for Go projects built with Bazel and
+// rules_go.
+package bazel
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// Default builder instance
+// nolint: gochecknoglobals
+var Default = &Builder{}
+
+// nolint: gochecknoinits
+func init() {
+	api.Register("bazel", Default)
+}
+
+// Builder is the bazel builder
+type Builder struct{}
+
+// WithDefaults sets the defaults for a bazel build and returns it
+func (*Builder) WithDefaults(build config.Build) config.Build {
+	if build.Main == "" {
+		build.Main = "//:" + build.Binary
+	}
+	if len(build.Targets) == 0 {
+		build.Targets = []string{"linux_amd64", "darwin_amd64"}
+	}
+	return build
+}
+
+// Build builds the bazel target for the given goreleaser target and copies
+// its output into dist
+func (*Builder) Build(ctx *context.Context, build config.Build, options api.Options) error {
+	var parts = strings.Split(options.Target, "_")
+	if len(parts) < 2 {
+		return fmt.Errorf("%s is not a valid build target", options.Target)
+	}
+	var env = append(ctx.Env.Strings(), build.Env...)
+	var a = &artifact.Artifact{
+		Type:   artifact.Binary,
+		Path:   options.Path,
+		Name:   options.Name,
+		Goos:   parts[0],
+		Goarch: parts[1],
+		Extra: map[string]interface{}{
+			"Binary": build.Binary,
+			"Ext":    options.Ext,
+			"ID":     build.ID,
+		},
+	}
+	if len(parts) > 2 {
+		a.Goarm = parts[2]
+	}
+
+	var flags = []string{platformFlag(a)}
+	for _, rawFlag := range build.Flags {
+		flag, err := tmpl.New(ctx).WithEnvS(env).WithArtifact(a, map[string]string{}).Apply(rawFlag)
+		if err != nil {
+			return err
+		}
+		flags = append(flags, flag)
+	}
+
+	if _, err := run(ctx, append(append([]string{"bazel", "build"}, flags...), build.Main), env); err != nil {
+		return errors.Wrapf(err, "failed to build for %s", options.Target)
+	}
+	out, err := run(ctx, append(append([]string{"bazel", "cquery"}, flags...), "--output=files", build.Main), env)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the output of %s", build.Main)
+	}
+	var files = strings.Fields(out)
+	if len(files) != 1 {
+		return fmt.Errorf("expected %s to output a single file, got %d", build.Main, len(files))
+	}
+	if err := copyFile(files[0], options.Path); err != nil {
+		return err
+	}
+	ctx.Artifacts.Add(a)
+	return nil
+}
+
+// platformFlag returns the rules_go platform of the given artifact
+func platformFlag(a *artifact.Artifact) string {
+	return fmt.Sprintf("--platforms=@io_bazel_rules_go//go/toolchain:%s_%s", a.Goos, a.Goarch)
+}
+
+func copyFile(src, dst string) error {
+	log.WithField("src", src).WithField("dst", dst).Debug("copying bazel output")
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.Wrap(err, "failed to open bazel output")
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	return err
+}
+
+func run(ctx *context.Context, command, env []string) (string, error) {
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, command[0], command[1:]...)
+	var log = log.WithField("env", env).WithField("cmd", command)
+	cmd.Env = env
+	log.Debug("running")
+	out, err := cmd.Output()
+	if err != nil {
+		log.WithError(err).Debug("failed")
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", errors.New(string(exitErr.Stderr))
+		}
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,94 @@
+package bazel
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBazel puts a fake bazel in the PATH which logs its arguments and
+// "builds" bazel-bin/foo
+func fakeBazel(t *testing.T, folder string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"bazel": `echo "$@" >> ` + filepath.Join(folder, "calls") + `
+if [ "$1" = "cquery" ]; then
+	mkdir -p ` + folder + `/bazel-bin
+	echo fake > ` + folder + `/bazel-bin/foo
+	echo ` + folder + `/bazel-bin/foo
+fi
+`,
+	})
+}
+
+func TestWithDefaults(t *testing.T) {
+	var build = Default.WithDefaults(config.Build{Binary: "foo"})
+	assert.Equal(t, "//:foo", build.Main)
+	assert.Equal(t, []string{"linux_amd64", "darwin_amd64"}, build.Targets)
+}
+
+func TestBuild(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeBazel(t, folder)()
+	var ctx = context.New(config.Project{})
+	var path = filepath.Join(folder, "dist", "foo_linux_arm_7", "foo")
+	require.NoError(t, Default.Build(ctx, config.Build{
+		ID:     "foo",
+		Binary: "foo",
+		Main:   "//cmd/foo",
+		Flags:  []string{"--config={{ .Os }}"},
+	}, api.Options{
+		Target: "linux_arm_7",
+		Name:   "foo",
+		Path:   path,
+	}))
+	calls, err := ioutil.ReadFile(filepath.Join(folder, "calls"))
+	require.NoError(t, err)
+	assert.Equal(t, "build --platforms=@io_bazel_rules_go//go/toolchain:linux_arm --config=linux //cmd/foo\n"+
+		"cquery --platforms=@io_bazel_rules_go//go/toolchain:linux_arm --config=linux --output=files //cmd/foo\n", string(calls))
+	bts, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "fake\n", string(bts))
+	assert.Equal(t, []*artifact.Artifact{
+		{
+			Type:   artifact.Binary,
+			Name:   "foo",
+			Path:   path,
+			Goos:   "linux",
+			Goarch: "arm",
+			Goarm:  "7",
+			Extra: map[string]interface{}{
+				"Binary": "foo",
+				"Ext":    "",
+				"ID":     "foo",
+			},
+		},
+	}, ctx.Artifacts.List())
+}
+
+func TestBuildInvalidTarget(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	assert.EqualError(t, Default.Build(ctx, config.Build{}, api.Options{
+		Target: "wasm",
+	}), "wasm is not a valid build target")
+}
+
+func TestBuildFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer testlib.FakeTools(t, folder, map[string]string{
+		"bazel": "echo 'no such target' >&2\nexit 1\n",
+	})()
+	var ctx = context.New(config.Project{})
+	assert.EqualError(t, Default.Build(ctx, config.Build{Main: "//nope"}, api.Options{
+		Target: "linux_amd64",
+	}), "failed to build for linux_amd64: no such target\n")
+}
@@ -7,6 +7,8 @@ import (
 	"go/token"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/apex/log"
@@ -30,8 +32,28 @@ func init() {
 // Builder is golang builder
 type Builder struct{}
 
+// nolint: gochecknoglobals
+var ldflagsPresets = map[string]string{
+	"strip":        "-s -w",
+	"version-info": "-s -w -X {{pkg}}.version={{.Version}} -X {{pkg}}.commit={{.Commit}} -X {{pkg}}.date={{.Date}} -X {{pkg}}.builtBy=goreleaser",
+}
+
 // WithDefaults sets the defaults for a golang build and returns it
-func (*Builder) WithDefaults(build config.Build) config.Build {
+func (b *Builder) WithDefaults(build config.Build) config.Build {
+	build, err := b.defaults(build)
+	if err != nil {
+		log.WithError(err).Warn("failed to set build defaults")
+	}
+	return build
+}
+
+// Defaults sets the defaults for a golang build, expanding its ldflags
+// presets
+func (b *Builder) Defaults(_ *context.Context, build config.Build) (config.Build, error) {
+	return b.defaults(build)
+}
+
+func (*Builder) defaults(build config.Build) (config.Build, error) {
 	if build.Main == "" {
 		build.Main = "."
 	}
@@ -44,18 +66,40 @@ func (*Builder) WithDefaults(build config.Build) config.Build {
 	if len(build.Goarm) == 0 {
 		build.Goarm = []string{"6"}
 	}
+	if build.LdflagsPackage == "" {
+		build.LdflagsPackage = "main"
+	}
+	for _, name := range build.LdflagsPresets {
+		preset, ok := ldflagsPresets[name]
+		if !ok {
+			return build, fmt.Errorf("invalid ldflags preset: %s", name)
+		}
+		build.Ldflags = append(build.Ldflags, strings.ReplaceAll(preset, "{{pkg}}", build.LdflagsPackage))
+	}
 	if len(build.Ldflags) == 0 {
 		build.Ldflags = []string{"-s -w -X main.version={{.Version}} -X main.commit={{.Commit}} -X main.date={{.Date}} -X main.builtBy=goreleaser"}
 	}
 	if len(build.Targets) == 0 {
 		build.Targets = matrix(build)
 	}
-	return build
+	if build.CrossCompile.Enabled && build.CrossCompile.Image == "" {
+		build.CrossCompile.Image = defaultCrossCompileImage
+	}
+	switch build.CrossCompile.Libc {
+	case "", "gnu", "musl":
+	default:
+		return build, fmt.Errorf("invalid cross_compile.libc %q, use gnu or musl", build.CrossCompile.Libc)
+	}
+	return build, nil
 }
 
 // Build builds a golang build
 func (*Builder) Build(ctx *context.Context, build config.Build, options api.Options) error {
-	if err := checkMain(build); err != nil {
+	var cmd = []string{"go", "build"}
+	if build.Test {
+		// test binaries are built from any package with tests, no main needed
+		cmd = []string{"go", "test", "-c"}
+	} else if err := checkMain(build); err != nil {
 		return err
 	}
 	target, err := newBuildTarget(options.Target)
@@ -63,9 +107,11 @@ func (*Builder) Build(ctx *context.Context, build config.Build, options api.Opti
 		return err
 	}
 
-	var cmd = []string{"go", "build"}
-
-	var env = append(ctx.Env.Strings(), build.Env...)
+	var env = ctx.Env.Strings()
+	if cgoEnabled(append(env, build.Env...)) {
+		env = append(env, toolchainEnv(ctx, target)...)
+	}
+	env = append(env, build.Env...)
 	env = append(env, target.Env()...)
 
 	artifact := &artifact.Artifact{
@@ -110,7 +156,25 @@ func (*Builder) Build(ctx *context.Context, build config.Build, options api.Opti
 
 	cmd = append(cmd, processedLdFlags)
 
-	cmd = append(cmd, "-o", options.Path, build.Main)
+	var output = options.Path
+	var image = containerFor(ctx, build, target)
+	var wd string
+	if image != "" {
+		wd, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+		output, err = containerPath(wd, options.Path)
+		if err != nil {
+			return err
+		}
+	}
+	cmd = append(cmd, "-o", output, build.Main)
+	if image != "" {
+		volumes, cacheEnv := goCaches(ctx)
+		cmd = dockerRun(image, wd, append(cacheEnv, containerEnv(ctx, build, target)...), volumes, cmd)
+		env = ctx.Env.Strings()
+	}
 	if err := run(ctx, cmd, env); err != nil {
 		return errors.Wrapf(err, "failed to build for %s", options.Target)
 	}
@@ -151,6 +215,111 @@ func run(ctx *context.Context, command, env []string) error {
 	return nil
 }
 
+func cgoEnabled(env []string) bool {
+	var enabled bool
+	for _, e := range env {
+		if strings.HasPrefix(e, "CGO_ENABLED=") {
+			enabled = e == "CGO_ENABLED=1"
+		}
+	}
+	return enabled
+}
+
+// toolchainEnv returns the CC/CXX/AR env for the given target, falling back
+// to the os_arch toolchain for arm targets without a goarm specific one.
+func toolchainEnv(ctx *context.Context, target buildTarget) []string {
+	toolchain, ok := ctx.Config.Toolchains[target.String()]
+	if !ok {
+		toolchain, ok = ctx.Config.Toolchains[target.os+"_"+target.arch]
+	}
+	if !ok {
+		return nil
+	}
+	log.WithField("target", target.String()).
+		WithField("toolchain", toolchain).
+		Debug("using toolchain")
+	var env []string
+	if toolchain.CC != "" {
+		env = append(env, "CC="+toolchain.CC)
+	}
+	if toolchain.CXX != "" {
+		env = append(env, "CXX="+toolchain.CXX)
+	}
+	if toolchain.AR != "" {
+		env = append(env, "AR="+toolchain.AR)
+	}
+	return env
+}
+
+// containerFor returns the docker image the given target should be built
+// in, if any, falling back to the os_arch image for arm targets, and then to
+// the cross compile image for cgo builds
+func containerFor(ctx *context.Context, build config.Build, target buildTarget) string {
+	if image, ok := build.Containers[target.String()]; ok {
+		return image
+	}
+	if image, ok := build.Containers[target.os+"_"+target.arch]; ok {
+		return image
+	}
+	var cross = build.CrossCompile
+	if cross.Enabled && cgoEnabled(append(ctx.Env.Strings(), build.Env...)) && crossToolchainEnv(cross, target) != nil {
+		return build.CrossCompile.Image
+	}
+	return ""
+}
+
+// containerEnv is the env of a containerized build: the host env is left
+// out, as it would likely break the container toolchain, but cgo stays
+// enabled if the host env enables it
+func containerEnv(ctx *context.Context, build config.Build, target buildTarget) []string {
+	var env []string
+	if cgoEnabled(append(ctx.Env.Strings(), build.Env...)) {
+		var toolchain = toolchainEnv(ctx, target)
+		if toolchain == nil && build.CrossCompile.Enabled {
+			toolchain = crossToolchainEnv(build.CrossCompile, target)
+		}
+		env = append(env, toolchain...)
+		if !cgoEnabled(build.Env) {
+			env = append(env, "CGO_ENABLED=1")
+		}
+	}
+	env = append(env, build.Env...)
+	return append(env, target.Env()...)
+}
+
+// containerPath returns the given path relative to the working directory,
+// which is where the source is mounted in the container
+func containerPath(wd, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return path, nil
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s must be inside the current directory to be built in a container", path)
+	}
+	return rel, nil
+}
+
+// dockerRun wraps the given command to run it in a container of the given
+// image, with the working directory and the given volumes mounted in it.
+// The command replaces the entrypoint of the image, as images like the
+// cross compile one have an entrypoint of their own.
+func dockerRun(image, wd string, env, volumes, command []string) []string {
+	var cmd = []string{"docker", "run", "--rm"}
+	if runtime.GOOS != "windows" {
+		cmd = append(cmd, "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()))
+	}
+	cmd = append(cmd, "-v", wd+":/src", "-w", "/src")
+	for _, v := range volumes {
+		cmd = append(cmd, "-v", v)
+	}
+	for _, e := range env {
+		cmd = append(cmd, "-e", e)
+	}
+	cmd = append(cmd, "--entrypoint", command[0], image)
+	return append(cmd, command[1:]...)
+}
+
 type buildTarget struct {
 	os, arch, arm string
 }
@@ -169,6 +338,13 @@ func newBuildTarget(s string) (buildTarget, error) {
 	return t, nil
 }
 
+func (b buildTarget) String() string {
+	if b.arm != "" {
+		return fmt.Sprintf("%s_%s_%s", b.os, b.arch, b.arm)
+	}
+	return fmt.Sprintf("%s_%s", b.os, b.arch)
+}
+
 func (b buildTarget) Env() []string {
 	return []string{
 		"GOOS=" + b.os,
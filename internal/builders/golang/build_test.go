@@ -76,6 +76,37 @@ func TestWithDefaults(t *testing.T) {
 	}
 }
 
+func TestLdflagsPresets(t *testing.T) {
+	t.Run("version-info", func(t *testing.T) {
+		build, err := Default.Defaults(context.New(config.Project{}), config.Build{
+			Ldflags:        []string{"-linkmode external"},
+			LdflagsPresets: []string{"version-info"},
+			LdflagsPackage: "github.com/goreleaser/foo/internal/version",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, config.StringArray{
+			"-linkmode external",
+			"-s -w -X github.com/goreleaser/foo/internal/version.version={{.Version}} -X github.com/goreleaser/foo/internal/version.commit={{.Commit}} -X github.com/goreleaser/foo/internal/version.date={{.Date}} -X github.com/goreleaser/foo/internal/version.builtBy=goreleaser",
+		}, build.Ldflags)
+	})
+
+	t.Run("default package", func(t *testing.T) {
+		build, err := Default.Defaults(context.New(config.Project{}), config.Build{
+			LdflagsPresets: []string{"strip"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "main", build.LdflagsPackage)
+		assert.Equal(t, config.StringArray{"-s -w"}, build.Ldflags)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := Default.Defaults(context.New(config.Project{}), config.Build{
+			LdflagsPresets: []string{"nope"},
+		})
+		assert.EqualError(t, err, "invalid ldflags preset: nope")
+	})
+}
+
 func TestBuild(t *testing.T) {
 	folder, back := testlib.Mktmp(t)
 	defer back()
@@ -92,6 +123,7 @@ func TestBuild(t *testing.T) {
 					"windows_amd64",
 					"linux_arm_6",
 					"js_wasm",
+					"wasip1_wasm",
 				},
 				Asmflags: []string{".=", "all="},
 				Gcflags:  []string{"all="},
@@ -108,7 +140,7 @@ func TestBuild(t *testing.T) {
 		if strings.HasPrefix(target, "windows") {
 			ext = ".exe"
 		}
-		if target == "js_wasm" {
+		if strings.HasSuffix(target, "_wasm") {
 			ext = ".wasm"
 		}
 		var err = Default.Build(ctx, build, api.Options{
@@ -181,6 +213,18 @@ func TestBuild(t *testing.T) {
 				"ID":     "foo",
 			},
 		},
+		{
+			Name:   "foo",
+			Path:   filepath.Join(folder, "dist", "wasip1_wasm", "foo"),
+			Goos:   "wasip1",
+			Goarch: "wasm",
+			Type:   artifact.Binary,
+			Extra: map[string]interface{}{
+				"Ext":    ".wasm",
+				"Binary": "foo",
+				"ID":     "foo",
+			},
+		},
 	})
 }
 
@@ -520,6 +564,268 @@ func TestJoinLdFlags(t *testing.T) {
 	}
 }
 
+func TestCgoEnabled(t *testing.T) {
+	assert.False(t, cgoEnabled([]string{}))
+	assert.False(t, cgoEnabled([]string{"CGO_ENABLED=0"}))
+	assert.True(t, cgoEnabled([]string{"CGO_ENABLED=1"}))
+	assert.False(t, cgoEnabled([]string{"CGO_ENABLED=1", "CGO_ENABLED=0"}))
+	assert.True(t, cgoEnabled([]string{"CGO_ENABLED=0", "FOO=bar", "CGO_ENABLED=1"}))
+}
+
+func TestToolchainEnv(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Toolchains: map[string]config.Toolchain{
+			"linux_arm64": {
+				CC:  "aarch64-linux-gnu-gcc",
+				CXX: "aarch64-linux-gnu-g++",
+				AR:  "aarch64-linux-gnu-ar",
+			},
+			"linux_arm": {
+				CC: "arm-linux-gnueabi-gcc",
+			},
+			"linux_arm_7": {
+				CC: "arm-linux-gnueabihf-gcc",
+			},
+		},
+	})
+	for target, env := range map[string][]string{
+		"linux_arm64": {
+			"CC=aarch64-linux-gnu-gcc",
+			"CXX=aarch64-linux-gnu-g++",
+			"AR=aarch64-linux-gnu-ar",
+		},
+		"linux_arm_7":  {"CC=arm-linux-gnueabihf-gcc"},
+		"linux_arm_6":  {"CC=arm-linux-gnueabi-gcc"},
+		"darwin_amd64": nil,
+	} {
+		t.Run(target, func(t *testing.T) {
+			bt, err := newBuildTarget(target)
+			assert.NoError(t, err)
+			assert.Equal(t, env, toolchainEnv(ctx, bt))
+		})
+	}
+}
+
+func TestContainerFor(t *testing.T) {
+	var build = config.Build{
+		Containers: map[string]string{
+			"darwin_amd64": "goreleaser/osxcross",
+			"linux_arm":    "goreleaser/arm",
+			"linux_arm_7":  "goreleaser/armhf",
+		},
+	}
+	for target, image := range map[string]string{
+		"darwin_amd64": "goreleaser/osxcross",
+		"linux_arm_6":  "goreleaser/arm",
+		"linux_arm_7":  "goreleaser/armhf",
+		"linux_amd64":  "",
+	} {
+		t.Run(target, func(t *testing.T) {
+			bt, err := newBuildTarget(target)
+			assert.NoError(t, err)
+			assert.Equal(t, image, containerFor(context.New(config.Project{}), build, bt))
+		})
+	}
+}
+
+func TestContainerPath(t *testing.T) {
+	path, err := containerPath("/src/foo", "/src/foo/dist/bar")
+	assert.NoError(t, err)
+	assert.Equal(t, "dist/bar", path)
+
+	path, err = containerPath("/src/foo", "dist/bar")
+	assert.NoError(t, err)
+	assert.Equal(t, "dist/bar", path)
+
+	_, err = containerPath("/src/foo", "/tmp/dist/bar")
+	assert.EqualError(t, err, "/tmp/dist/bar must be inside the current directory to be built in a container")
+}
+
+func TestBuildInContainer(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	writeGoodMain(t, folder)
+	defer testlib.FakeTools(t, folder, map[string]string{
+		"docker": "echo \"$@\" > " + filepath.Join(folder, "args") + "\n",
+	})()
+
+	var ctx = context.New(config.Project{
+		Toolchains: map[string]config.Toolchain{
+			"darwin_amd64": {CC: "o64-clang"},
+		},
+	})
+	var build = config.Build{
+		ID:     "foo",
+		Binary: "foo",
+		Main:   ".",
+		Env:    []string{"CGO_ENABLED=1"},
+		Containers: map[string]string{
+			"darwin_amd64": "goreleaser/osxcross",
+		},
+	}
+	assert.NoError(t, Default.Build(ctx, build, api.Options{
+		Target: "darwin_amd64",
+		Name:   "foo",
+		Path:   filepath.Join(folder, "dist", "darwin_amd64", "foo"),
+	}))
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "args"))
+	assert.NoError(t, err)
+	var args = string(bts)
+	assert.Contains(t, args, "run --rm")
+	assert.Contains(t, args, "-v "+folder+":/src -w /src")
+	assert.Contains(t, args, "-e CC=o64-clang -e CGO_ENABLED=1 -e GOOS=darwin -e GOARCH=amd64")
+	assert.Contains(t, args, "--entrypoint go goreleaser/osxcross build")
+	assert.Contains(t, args, "-o dist/darwin_amd64/foo .")
+	assert.Len(t, ctx.Artifacts.List(), 1)
+}
+
+func TestContainerForCrossCompile(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.Env = context.Env{}
+	var build = config.Build{
+		Env: []string{"CGO_ENABLED=1"},
+		Containers: map[string]string{
+			"darwin_amd64": "goreleaser/osxcross",
+		},
+		CrossCompile: config.CrossCompile{
+			Enabled: true,
+			Image:   "goreleaser/cross",
+		},
+	}
+	for target, image := range map[string]string{
+		"darwin_amd64":  "goreleaser/osxcross",
+		"windows_amd64": "goreleaser/cross",
+		"linux_arm_6":   "goreleaser/cross",
+		"linux_mips":    "",
+	} {
+		t.Run(target, func(t *testing.T) {
+			bt, err := newBuildTarget(target)
+			assert.NoError(t, err)
+			assert.Equal(t, image, containerFor(ctx, build, bt))
+		})
+	}
+
+	t.Run("no cgo", func(t *testing.T) {
+		build.Env = nil
+		bt, err := newBuildTarget("windows_amd64")
+		assert.NoError(t, err)
+		assert.Empty(t, containerFor(ctx, build, bt))
+	})
+
+	t.Run("cgo in the global env", func(t *testing.T) {
+		build.Env = nil
+		ctx.Env = context.Env{"CGO_ENABLED": "1"}
+		bt, err := newBuildTarget("windows_amd64")
+		assert.NoError(t, err)
+		assert.Equal(t, "goreleaser/cross", containerFor(ctx, build, bt))
+		assert.Equal(t, []string{
+			"CC=x86_64-w64-mingw32-gcc",
+			"CXX=x86_64-w64-mingw32-g++",
+			"CGO_ENABLED=1",
+			"GOOS=windows",
+			"GOARCH=amd64",
+			"GOARM=",
+		}, containerEnv(ctx, build, bt))
+	})
+}
+
+func TestCrossToolchainEnvMusl(t *testing.T) {
+	var cross = config.CrossCompile{Enabled: true, Libc: "musl"}
+	for target, env := range map[string][]string{
+		"linux_amd64":   {"CC=x86_64-linux-musl-gcc", "CXX=x86_64-linux-musl-g++"},
+		"linux_arm_6":   {"CC=arm-linux-musleabi-gcc", "CXX=arm-linux-musleabi-g++"},
+		"linux_arm_7":   {"CC=arm-linux-musleabihf-gcc", "CXX=arm-linux-musleabihf-g++"},
+		"linux_mips":    nil,
+		"darwin_amd64":  {"CC=o64-clang", "CXX=o64-clang++"},
+		"windows_amd64": {"CC=x86_64-w64-mingw32-gcc", "CXX=x86_64-w64-mingw32-g++"},
+	} {
+		t.Run(target, func(t *testing.T) {
+			bt, err := newBuildTarget(target)
+			assert.NoError(t, err)
+			assert.Equal(t, env, crossToolchainEnv(cross, bt))
+		})
+	}
+}
+
+func TestCrossCompileInvalidLibc(t *testing.T) {
+	_, err := Default.Defaults(context.New(config.Project{}), config.Build{
+		CrossCompile: config.CrossCompile{Enabled: true, Libc: "uclibc"},
+	})
+	assert.EqualError(t, err, `invalid cross_compile.libc "uclibc", use gnu or musl`)
+}
+
+func TestCrossCompileDefaultImage(t *testing.T) {
+	var build = Default.WithDefaults(config.Build{
+		CrossCompile: config.CrossCompile{Enabled: true},
+	})
+	assert.Equal(t, defaultCrossCompileImage, build.CrossCompile.Image)
+}
+
+func TestBuildCrossCompile(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	writeGoodMain(t, folder)
+	defer testlib.FakeTools(t, folder, map[string]string{
+		"docker": "echo \"$@\" > " + filepath.Join(folder, "args") + "\n",
+	})()
+
+	var ctx = context.New(config.Project{})
+	var build = config.Build{
+		ID:     "foo",
+		Binary: "foo",
+		Main:   ".",
+		Env:    []string{"CGO_ENABLED=1"},
+		CrossCompile: config.CrossCompile{
+			Enabled: true,
+			Image:   "goreleaser/cross",
+		},
+	}
+	assert.NoError(t, Default.Build(ctx, build, api.Options{
+		Target: "windows_amd64",
+		Name:   "foo.exe",
+		Path:   filepath.Join(folder, "dist", "windows_amd64", "foo.exe"),
+	}))
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "args"))
+	assert.NoError(t, err)
+	var args = string(bts)
+	assert.Contains(t, args, ":/gocache -v ")
+	assert.Contains(t, args, ":/gomodcache")
+	assert.Contains(t, args, "-e GOCACHE=/gocache -e GOMODCACHE=/gomodcache")
+	assert.Contains(t, args, "-e CC=x86_64-w64-mingw32-gcc -e CXX=x86_64-w64-mingw32-g++ -e CGO_ENABLED=1")
+	assert.Contains(t, args, "--entrypoint go goreleaser/cross build")
+}
+
+func TestBuildTestBinary(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	assert.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "lib.go"),
+		[]byte("package lib\nfunc Add(a, b int) int { return a + b }"),
+		0644,
+	))
+	assert.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "lib_test.go"),
+		[]byte("package lib\nimport \"testing\"\nfunc TestAdd(t *testing.T) { if Add(1, 1) != 2 { t.Fail() } }"),
+		0644,
+	))
+	var ctx = context.New(config.Project{})
+	var build = config.Build{
+		ID:     "conformance",
+		Binary: "lib.test",
+		Main:   ".",
+		Env:    []string{"GO111MODULE=off"},
+		Test:   true,
+	}
+	var path = filepath.Join(folder, "dist", "linux_amd64", "lib.test")
+	assert.NoError(t, Default.Build(ctx, build, api.Options{
+		Target: "linux_amd64",
+		Name:   "lib.test",
+		Path:   path,
+	}))
+	assert.FileExists(t, path)
+	assert.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.Binary)).List(), 1)
+}
+
 //
 // Helpers
 //
@@ -0,0 +1,77 @@
+package golang
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const defaultCrossCompileImage = "goreleaser/goreleaser-cross:latest"
+
+// crossToolchains are the cross compilers available in the default cross
+// compile image: osxcross for darwin, mingw for windows and gcc for linux.
+// nolint: gochecknoglobals
+var crossToolchains = map[string]config.Toolchain{
+	"darwin_amd64":  {CC: "o64-clang", CXX: "o64-clang++"},
+	"darwin_arm64":  {CC: "oa64-clang", CXX: "oa64-clang++"},
+	"windows_386":   {CC: "i686-w64-mingw32-gcc", CXX: "i686-w64-mingw32-g++"},
+	"windows_amd64": {CC: "x86_64-w64-mingw32-gcc", CXX: "x86_64-w64-mingw32-g++"},
+	"linux_386":     {CC: "i686-linux-gnu-gcc", CXX: "i686-linux-gnu-g++"},
+	"linux_amd64":   {CC: "x86_64-linux-gnu-gcc", CXX: "x86_64-linux-gnu-g++"},
+	"linux_arm64":   {CC: "aarch64-linux-gnu-gcc", CXX: "aarch64-linux-gnu-g++"},
+	"linux_arm":     {CC: "arm-linux-gnueabi-gcc", CXX: "arm-linux-gnueabi-g++"},
+	"linux_arm_7":   {CC: "arm-linux-gnueabihf-gcc", CXX: "arm-linux-gnueabihf-g++"},
+}
+
+// muslToolchains are the musl-cross compilers used for the linux targets
+// when the cross compile libc is musl. The default image doesn't have them.
+// nolint: gochecknoglobals
+var muslToolchains = map[string]config.Toolchain{
+	"linux_386":   {CC: "i686-linux-musl-gcc", CXX: "i686-linux-musl-g++"},
+	"linux_amd64": {CC: "x86_64-linux-musl-gcc", CXX: "x86_64-linux-musl-g++"},
+	"linux_arm64": {CC: "aarch64-linux-musl-gcc", CXX: "aarch64-linux-musl-g++"},
+	"linux_arm":   {CC: "arm-linux-musleabi-gcc", CXX: "arm-linux-musleabi-g++"},
+	"linux_arm_7": {CC: "arm-linux-musleabihf-gcc", CXX: "arm-linux-musleabihf-g++"},
+}
+
+// crossToolchainEnv returns the CC/CXX env of the cross compile image for
+// the given target, or nil if the image can't build it
+func crossToolchainEnv(cross config.CrossCompile, target buildTarget) []string {
+	var toolchains = crossToolchains
+	if cross.Libc == "musl" && target.os == "linux" {
+		toolchains = muslToolchains
+	}
+	toolchain, ok := toolchains[target.String()]
+	if !ok {
+		toolchain, ok = toolchains[target.os+"_"+target.arch]
+	}
+	if !ok {
+		return nil
+	}
+	return []string{"CC=" + toolchain.CC, "CXX=" + toolchain.CXX}
+}
+
+// goCaches returns the volumes and env needed to share the host go build
+// and module caches with containerized builds, so they don't start from
+// scratch on every target.
+func goCaches(ctx *context.Context) (volumes, env []string) {
+	/* #nosec */
+	out, err := exec.CommandContext(ctx, "go", "env", "GOCACHE", "GOMODCACHE").Output()
+	if err != nil {
+		log.WithError(err).Debug("not sharing go caches with the container")
+		return nil, nil
+	}
+	var paths = strings.Split(strings.TrimSpace(string(out)), "\n")
+	for i, name := range []string{"GOCACHE", "GOMODCACHE"} {
+		if i >= len(paths) || strings.TrimSpace(paths[i]) == "" {
+			continue
+		}
+		var dir = "/" + strings.ToLower(name)
+		volumes = append(volumes, strings.TrimSpace(paths[i])+":"+dir)
+		env = append(env, name+"="+dir)
+	}
+	return volumes, env
+}
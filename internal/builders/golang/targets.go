@@ -129,6 +129,7 @@ var validTargets = []string{
 	"plan9amd64",
 	"plan9arm",
 	"solarisamd64",
+	"wasip1wasm",
 	"windows386",
 	"windowsamd64",
 }
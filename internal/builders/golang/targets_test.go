@@ -101,6 +101,7 @@ func TestGoosGoarchCombos(t *testing.T) {
 		{"windows", "386", true},
 		{"windows", "amd64", true},
 		{"js", "wasm", true},
+		{"wasip1", "wasm", true},
 		// invalid targets
 		{"darwin", "arm", false},
 		{"darwin", "arm64", false},
@@ -0,0 +1,116 @@
+// Package makefile provides a builder for projects whose builds are already
+// encoded in Makefiles.
+package makefile
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// Default builder instance
+// nolint: gochecknoglobals
+var Default = &Builder{}
+
+// nolint: gochecknoinits
+func init() {
+	api.Register("make", Default)
+}
+
+// Builder is the make builder
+type Builder struct{}
+
+// WithDefaults sets the defaults for a make build and returns it
+func (*Builder) WithDefaults(build config.Build) config.Build {
+	if build.Main == "" {
+		build.Main = "build"
+	}
+	if len(build.Targets) == 0 {
+		build.Targets = []string{"linux_amd64", "darwin_amd64"}
+	}
+	return build
+}
+
+// Build runs the make target for the given platform, which must write the
+// binary to the OUTPUT path
+func (*Builder) Build(ctx *context.Context, build config.Build, options api.Options) error {
+	cmd, a, err := command(ctx, build, options)
+	if err != nil {
+		return err
+	}
+	if err := run(ctx, cmd, append(ctx.Env.Strings(), build.Env...)); err != nil {
+		return errors.Wrapf(err, "failed to build for %s", options.Target)
+	}
+	if _, err := os.Stat(a.Path); err != nil {
+		return fmt.Errorf("make %s did not write %s", cmd[1], a.Path)
+	}
+	ctx.Artifacts.Add(a)
+	return nil
+}
+
+// command returns the make command building the given target, along with
+// the artifact it produces
+func command(ctx *context.Context, build config.Build, options api.Options) ([]string, *artifact.Artifact, error) {
+	var parts = strings.Split(options.Target, "_")
+	if len(parts) < 2 {
+		return nil, nil, fmt.Errorf("%s is not a valid build target", options.Target)
+	}
+	var a = &artifact.Artifact{
+		Type:   artifact.Binary,
+		Path:   options.Path,
+		Name:   options.Name,
+		Goos:   parts[0],
+		Goarch: parts[1],
+		Extra: map[string]interface{}{
+			"Binary": build.Binary,
+			"Ext":    options.Ext,
+			"ID":     build.ID,
+		},
+	}
+	if len(parts) > 2 {
+		a.Goarm = parts[2]
+	}
+	var template = tmpl.New(ctx).WithEnvS(append(ctx.Env.Strings(), build.Env...)).WithArtifact(a, map[string]string{})
+	target, err := template.Apply(build.Main)
+	if err != nil {
+		return nil, nil, err
+	}
+	var cmd = []string{
+		"make", target,
+		"GOOS=" + a.Goos,
+		"GOARCH=" + a.Goarch,
+		"GOARM=" + a.Goarm,
+		"VERSION=" + ctx.Version,
+		"OUTPUT=" + a.Path,
+	}
+	for _, rawFlag := range build.Flags {
+		flag, err := template.Apply(rawFlag)
+		if err != nil {
+			return nil, nil, err
+		}
+		cmd = append(cmd, flag)
+	}
+	return cmd, a, nil
+}
+
+func run(ctx *context.Context, command, env []string) error {
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, command[0], command[1:]...)
+	var log = log.WithField("env", env).WithField("cmd", command)
+	cmd.Env = env
+	log.Debug("running")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		log.WithError(err).Debug("failed")
+		return errors.New(string(out))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package makefile
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDefaults(t *testing.T) {
+	var build = Default.WithDefaults(config.Build{})
+	assert.Equal(t, "build", build.Main)
+	assert.Equal(t, []string{"linux_amd64", "darwin_amd64"}, build.Targets)
+}
+
+func TestCommand(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.Version = "1.2.3"
+	cmd, a, err := command(ctx, config.Build{
+		ID:    "foo",
+		Main:  "release-{{ .Os }}",
+		Flags: []string{"-j4"},
+	}, api.Options{
+		Target: "linux_arm_6",
+		Name:   "foo",
+		Path:   "dist/foo_linux_arm_6/foo",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"make", "release-linux",
+		"GOOS=linux", "GOARCH=arm", "GOARM=6", "VERSION=1.2.3",
+		"OUTPUT=dist/foo_linux_arm_6/foo",
+		"-j4",
+	}, cmd)
+	assert.Equal(t, "arm", a.Goarch)
+	assert.Equal(t, "6", a.Goarm)
+}
+
+func TestCommandInvalidTarget(t *testing.T) {
+	_, _, err := command(context.New(config.Project{}), config.Build{}, api.Options{
+		Target: "wasm",
+	})
+	assert.EqualError(t, err, "wasm is not a valid build target")
+}
+
+func TestBuild(t *testing.T) {
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make not installed")
+	}
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, ioutil.WriteFile("Makefile", []byte("build:\n\tmkdir -p $(dir $(OUTPUT))\n\techo $(GOOS)-$(VERSION) > $(OUTPUT)\nnothing:\n\ttrue\n"), 0644))
+	var ctx = context.New(config.Project{})
+	ctx.Version = "1.0.0"
+	var path = filepath.Join(folder, "dist", "foo_linux_amd64", "foo")
+	var options = api.Options{
+		Target: "linux_amd64",
+		Name:   "foo",
+		Path:   path,
+	}
+	require.NoError(t, Default.Build(ctx, Default.WithDefaults(config.Build{}), options))
+	bts, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "linux-1.0.0\n", string(bts))
+	assert.Len(t, ctx.Artifacts.List(), 1)
+
+	options.Path = filepath.Join(folder, "nope")
+	assert.EqualError(
+		t,
+		Default.Build(ctx, config.Build{Main: "nothing"}, options),
+		"make nothing did not write "+options.Path,
+	)
+}
@@ -0,0 +1,144 @@
+// Package tinygo provides a builder for projects built with TinyGo, usually
+// targeting WebAssembly or microcontrollers.
+package tinygo
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// Default builder instance
+// nolint: gochecknoglobals
+var Default = &Builder{}
+
+// nolint: gochecknoinits
+func init() {
+	api.Register("tinygo", Default)
+}
+
+// Builder is the tinygo builder
+type Builder struct{}
+
+// tinygoTarget is what a TinyGo -target maps to in the artifacts
+type tinygoTarget struct {
+	os, arch, ext string
+}
+
+// nolint: gochecknoglobals
+var targets = map[string]tinygoTarget{
+	"wasm":   {"js", "wasm", ".wasm"},
+	"wasi":   {"wasip1", "wasm", ".wasm"},
+	"wasip1": {"wasip1", "wasm", ".wasm"},
+}
+
+// WithDefaults sets the defaults for a tinygo build and returns it
+func (*Builder) WithDefaults(build config.Build) config.Build {
+	if build.Main == "" {
+		build.Main = "."
+	}
+	if len(build.Targets) == 0 {
+		build.Targets = []string{"wasm"}
+	}
+	if len(build.Flags) == 0 {
+		build.Flags = []string{"-opt=z", "-no-debug"}
+	}
+	return build
+}
+
+// Build builds a tinygo build
+func (*Builder) Build(ctx *context.Context, build config.Build, options api.Options) error {
+	cmd, env, artifact, err := command(ctx, build, options)
+	if err != nil {
+		return err
+	}
+	if err := run(ctx, cmd, env); err != nil {
+		return errors.Wrapf(err, "failed to build for %s", options.Target)
+	}
+	ctx.Artifacts.Add(artifact)
+	return nil
+}
+
+// command returns the tinygo command and environment to build the given
+// target, along with the artifact it produces.
+// Targets are either goos_goarch pairs, built natively, or TinyGo targets,
+// like wasm, wasi or a microcontroller board.
+func command(ctx *context.Context, build config.Build, options api.Options) ([]string, []string, *artifact.Artifact, error) {
+	var env = append(ctx.Env.Strings(), build.Env...)
+	var cmd = []string{"tinygo", "build"}
+	var a = &artifact.Artifact{
+		Type: artifact.Binary,
+		Path: options.Path,
+		Name: options.Name,
+		Extra: map[string]interface{}{
+			"Binary": build.Binary,
+			"Ext":    options.Ext,
+			"ID":     build.ID,
+		},
+	}
+
+	if parts := strings.Split(options.Target, "_"); len(parts) > 1 {
+		a.Goos, a.Goarch = parts[0], parts[1]
+		env = append(env, "GOOS="+a.Goos, "GOARCH="+a.Goarch)
+		if len(parts) > 2 {
+			a.Goarm = parts[2]
+			env = append(env, "GOARM="+a.Goarm)
+		}
+	} else {
+		cmd = append(cmd, "-target", options.Target)
+		var target, ok = targets[options.Target]
+		if !ok {
+			// microcontrollers: the target is the board name
+			target = tinygoTarget{os: options.Target}
+		}
+		a.Goos, a.Goarch = target.os, target.arch
+		if options.Ext == "" && target.ext != "" {
+			a.Path += target.ext
+			a.Name += target.ext
+			a.Extra["Ext"] = target.ext
+		}
+	}
+
+	for _, rawFlag := range build.Flags {
+		flag, err := tmpl.New(ctx).WithEnvS(env).WithArtifact(a, map[string]string{}).Apply(rawFlag)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		cmd = append(cmd, flag)
+	}
+	var ldflags []string
+	for _, rawFlag := range build.Ldflags {
+		flag, err := tmpl.New(ctx).WithEnvS(env).WithArtifact(a, map[string]string{}).Apply(rawFlag)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		ldflags = append(ldflags, flag)
+	}
+	if len(ldflags) > 0 {
+		cmd = append(cmd, fmt.Sprintf("-ldflags=%s", strings.Join(ldflags, " ")))
+	}
+
+	cmd = append(cmd, "-o", a.Path, build.Main)
+	return cmd, env, a, nil
+}
+
+func run(ctx *context.Context, command, env []string) error {
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, command[0], command[1:]...)
+	var log = log.WithField("env", env).WithField("cmd", command)
+	cmd.Env = env
+	log.Debug("running")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		log.WithError(err).Debug("failed")
+		return errors.New(string(out))
+	}
+	return nil
+}
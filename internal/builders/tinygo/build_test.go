@@ -0,0 +1,102 @@
+package tinygo
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistered(t *testing.T) {
+	assert.Equal(t, Default, api.For("tinygo"))
+}
+
+func TestWithDefaults(t *testing.T) {
+	var build = Default.WithDefaults(config.Build{})
+	assert.Equal(t, ".", build.Main)
+	assert.Equal(t, []string{"wasm"}, build.Targets)
+	assert.Equal(t, config.FlagArray{"-opt=z", "-no-debug"}, build.Flags)
+}
+
+func TestCommand(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.Version = "1.2.3"
+	var build = Default.WithDefaults(config.Build{
+		ID:      "foo",
+		Binary:  "foo",
+		Ldflags: []string{"-X main.version={{.Version}}"},
+	})
+
+	t.Run("wasm", func(t *testing.T) {
+		cmd, _, a, err := command(ctx, build, api.Options{
+			Target: "wasm",
+			Name:   "foo",
+			Path:   "dist/foo_wasm/foo",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"tinygo", "build", "-target", "wasm", "-opt=z", "-no-debug",
+			"-ldflags=-X main.version=1.2.3",
+			"-o", "dist/foo_wasm/foo.wasm", ".",
+		}, cmd)
+		assert.Equal(t, &artifact.Artifact{
+			Type:   artifact.Binary,
+			Name:   "foo.wasm",
+			Path:   "dist/foo_wasm/foo.wasm",
+			Goos:   "js",
+			Goarch: "wasm",
+			Extra: map[string]interface{}{
+				"Binary": "foo",
+				"Ext":    ".wasm",
+				"ID":     "foo",
+			},
+		}, a)
+	})
+
+	t.Run("board", func(t *testing.T) {
+		cmd, _, a, err := command(ctx, build, api.Options{
+			Target: "pico",
+			Name:   "foo",
+			Path:   "dist/foo_pico/foo",
+		})
+		assert.NoError(t, err)
+		assert.Contains(t, cmd, "pico")
+		assert.Equal(t, "pico", a.Goos)
+		assert.Equal(t, "dist/foo_pico/foo", a.Path)
+	})
+
+	t.Run("native", func(t *testing.T) {
+		cmd, env, a, err := command(ctx, build, api.Options{
+			Target: "linux_arm_7",
+			Name:   "foo",
+			Path:   "dist/foo_linux_arm_7/foo",
+		})
+		assert.NoError(t, err)
+		assert.NotContains(t, cmd, "-target")
+		assert.Subset(t, env, []string{"GOOS=linux", "GOARCH=arm", "GOARM=7"})
+		assert.Equal(t, "linux", a.Goos)
+		assert.Equal(t, "arm", a.Goarch)
+		assert.Equal(t, "7", a.Goarm)
+	})
+
+	t.Run("invalid flag", func(t *testing.T) {
+		var build = build
+		build.Flags = []string{"{{ .Nope }"}
+		_, _, _, err := command(ctx, build, api.Options{Target: "wasm"})
+		assert.Error(t, err)
+	})
+}
+
+func TestBuildFails(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	var err = Default.Build(ctx, Default.WithDefaults(config.Build{}), api.Options{
+		Target: "wasm",
+		Name:   "foo",
+		Path:   "foo",
+	})
+	assert.Error(t, err)
+	assert.Empty(t, ctx.Artifacts.List())
+}
@@ -2,13 +2,20 @@
 package client
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
+// ErrTokenTypeNotImplemented indicates that a new token type was not
+// implemented
+var ErrTokenTypeNotImplemented = errors.New("token type not implemented")
+
 // Info of the repository
 type Info struct {
 	Description string
@@ -23,6 +30,66 @@ type Client interface {
 	Upload(ctx *context.Context, releaseID string, artifact *artifact.Artifact, file *os.File) (err error)
 }
 
+// Remover is implemented by clients able to undo what was published, and is
+// used to rollback releases
+type Remover interface {
+	DeleteRelease(ctx *context.Context, tag string) (err error)
+	RevertFile(ctx *context.Context, commitAuthor config.CommitAuthor, repo config.Repo, path, message string) (err error)
+}
+
+// PullRequester is implemented by clients able to propose changes to a
+// repository through pull requests. The changes are committed to a branch of
+// repo, and the pull request is opened against base, which is either repo
+// itself or the repository it was forked from.
+type PullRequester interface {
+	GetFile(ctx *context.Context, repo config.Repo, path string) (content []byte, err error)
+	OpenPullRequest(ctx *context.Context, commitAuthor config.CommitAuthor, repo, base config.Repo, files map[string][]byte, branch, title, message string) (url string, err error)
+}
+
+// Downloader is implemented by clients able to download the assets of a
+// published release, and is used to verify releases
+type Downloader interface {
+	DownloadReleaseAssets(ctx *context.Context, tag, dir string) (names []string, err error)
+}
+
+// MilestoneCloser is implemented by clients able to close the milestones of
+// a repository
+type MilestoneCloser interface {
+	CloseMilestone(ctx *context.Context, repo config.Repo, title string) (err error)
+}
+
+// Release modes, governing what happens to the release notes and assets of an
+// already existing release
+const (
+	// ReleaseModeKeepExisting keeps the release notes and assets
+	ReleaseModeKeepExisting = "keep-existing"
+	// ReleaseModeAppend appends the release notes to the existing ones, and
+	// keeps the assets
+	ReleaseModeAppend = "append"
+	// ReleaseModeReplace replaces the release notes and assets
+	ReleaseModeReplace = "replace"
+)
+
+// releaseNotes returns the release notes of a release already having the
+// existing ones, following the release mode
+func releaseNotes(ctx *context.Context, existing, body string) string {
+	if existing == "" {
+		return body
+	}
+	switch ctx.Config.Release.Mode {
+	case ReleaseModeReplace:
+		return body
+	case ReleaseModeAppend:
+		// re-running a release must not append the same notes twice
+		if strings.Contains(existing, strings.TrimSpace(body)) {
+			return existing
+		}
+		return existing + "\n\n" + body
+	default:
+		return existing
+	}
+}
+
 // New creates a new client depending on the token type
 func New(ctx *context.Context) (Client, error) {
 	if ctx.TokenType == context.TokenTypeGitHub {
@@ -36,3 +103,23 @@ func New(ctx *context.Context) (Client, error) {
 	}
 	return nil, nil
 }
+
+// DownloadURLTemplate returns the default template of the download URL of the
+// released artifacts, depending on the token type
+func DownloadURLTemplate(ctx *context.Context) (string, error) {
+	switch ctx.TokenType {
+	case context.TokenTypeGitHub:
+		return fmt.Sprintf(
+			"%s/%s/%s/releases/download/{{ .Tag }}/{{ .ArtifactName }}",
+			ctx.Config.GitHubURLs.Download,
+			ctx.Config.Release.GitHub.Owner,
+			ctx.Config.Release.GitHub.Name,
+		), nil
+	case context.TokenTypeGitLab:
+		return GitLabURLTemplate(ctx), nil
+	case context.TokenTypeGitea:
+		return GiteaURLTemplate(ctx), nil
+	default:
+		return "", ErrTokenTypeNotImplemented
+	}
+}
@@ -56,3 +56,64 @@ func TestClientNewGitLab(t *testing.T) {
 	_, ok := client.(*gitlabClient)
 	assert.True(t, ok)
 }
+
+func TestRemovers(t *testing.T) {
+	var _ Remover = &githubClient{}
+	var _ Remover = &gitlabClient{}
+}
+
+func TestReleaseNotes(t *testing.T) {
+	for mode, expected := range map[string]string{
+		"":                      "existing",
+		ReleaseModeKeepExisting: "existing",
+		ReleaseModeAppend:       "existing\n\nnew",
+		ReleaseModeReplace:      "new",
+	} {
+		t.Run(mode, func(t *testing.T) {
+			var ctx = context.New(config.Project{
+				Release: config.Release{Mode: mode},
+			})
+			assert.Equal(t, expected, releaseNotes(ctx, "existing", "new"))
+			assert.Equal(t, "new", releaseNotes(ctx, "", "new"))
+		})
+	}
+}
+
+func TestReleaseNotesAppendTwice(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Release: config.Release{Mode: ReleaseModeAppend},
+	})
+	assert.Equal(t, "existing\n\nnew\n", releaseNotes(ctx, "existing\n\nnew\n", "new\n"))
+}
+
+func TestDownloadURLTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{Download: "https://github.com"},
+		GitLabURLs: config.GitLabURLs{Download: "https://gitlab.com"},
+		GiteaURLs:  config.GiteaURLs{Download: "https://gitea.com"},
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "owner", Name: "github"},
+			GitLab: config.Repo{Owner: "owner", Name: "gitlab"},
+			Gitea:  config.Repo{Owner: "owner", Name: "gitea"},
+		},
+	})
+	for tokenType, expected := range map[context.TokenType]string{
+		context.TokenTypeGitHub: "https://github.com/owner/github/releases/download/{{ .Tag }}/{{ .ArtifactName }}",
+		context.TokenTypeGitLab: "https://gitlab.com/owner/gitlab/uploads/{{ .ArtifactUploadHash }}/{{ .ArtifactName }}",
+		context.TokenTypeGitea:  "https://gitea.com/owner/gitea/releases/download/{{ .Tag }}/{{ .ArtifactName }}",
+	} {
+		t.Run(string(tokenType), func(t *testing.T) {
+			ctx.TokenType = tokenType
+			url, err := DownloadURLTemplate(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, url)
+		})
+	}
+}
+
+func TestDownloadURLTemplateTokenTypeNotImplemented(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.TokenType = "foo"
+	_, err := DownloadURLTemplate(ctx)
+	assert.EqualError(t, err, ErrTokenTypeNotImplemented.Error())
+}
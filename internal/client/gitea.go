@@ -1,12 +1,18 @@
 package client
 
 import (
+	"bytes"
 	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
 
 	"code.gitea.io/sdk/gitea"
 	"github.com/apex/log"
@@ -18,6 +24,10 @@ import (
 
 type giteaClient struct {
 	client *gitea.Client
+	// the sdk doesn't support the contents api, which is called directly
+	http  *http.Client
+	api   string
+	token string
 }
 
 func getInstanceURL(apiURL string) (string, error) {
@@ -48,7 +58,12 @@ func NewGitea(ctx *context.Context) (Client, error) {
 	}
 	httpClient := &http.Client{Transport: transport}
 	client.SetHTTPClient(httpClient)
-	return &giteaClient{client: client}, nil
+	return &giteaClient{
+		client: client,
+		http:   httpClient,
+		api:    instanceURL + "/api/v1",
+		token:  ctx.Token,
+	}, nil
 }
 
 // CreateFile creates a file in the repository at a given path
@@ -61,8 +76,80 @@ func (c *giteaClient) CreateFile(
 	path,
 	message string,
 ) error {
-	//TODO: implement for brew and scoop support for Gitea-hosted repos
-	return nil
+	var endpoint = fmt.Sprintf(
+		"%s/repos/%s/%s/contents/%s",
+		c.api,
+		url.PathEscape(repo.Owner),
+		url.PathEscape(repo.Name),
+		path,
+	)
+	var existing struct {
+		SHA string `json:"sha"`
+	}
+	status, err := c.do(ctx, http.MethodGet, endpoint, nil, &existing)
+	if err != nil && status != http.StatusNotFound {
+		return err
+	}
+
+	var identity = map[string]string{
+		"name":  commitAuthor.Name,
+		"email": commitAuthor.Email,
+	}
+	var opts = map[string]interface{}{
+		"content":   base64.StdEncoding.EncodeToString(content),
+		"message":   message,
+		"author":    identity,
+		"committer": identity,
+	}
+	var method = http.MethodPost
+	if status != http.StatusNotFound {
+		// updating a file requires the sha of its current version
+		method = http.MethodPut
+		opts["sha"] = existing.SHA
+	}
+	log.WithFields(log.Fields{
+		"owner": repo.Owner,
+		"name":  repo.Name,
+		"path":  path,
+	}).Debug("pushing file to gitea")
+	_, err = c.do(ctx, method, endpoint, opts, nil)
+	return err
+}
+
+// do calls the gitea api at the given endpoint, with body encoded as json,
+// decoding the response in result, if given.
+// It returns the status code of the response.
+func (c *giteaClient) do(ctx *context.Context, method, endpoint string, body, result interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		bts, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(bts)
+	}
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode >= 300 {
+		bts, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, fmt.Errorf("%s %s: %d %s", method, endpoint, resp.StatusCode, string(bts))
+	}
+	if result == nil {
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(result)
 }
 
 func (c *giteaClient) createRelease(ctx *context.Context, title, body string) (*gitea.Release, error) {
@@ -154,7 +241,7 @@ func (c *giteaClient) CreateRelease(ctx *context.Context, body string) (string,
 	}
 
 	if release != nil {
-		release, err = c.updateRelease(ctx, title, body, release.ID)
+		release, err = c.updateRelease(ctx, title, releaseNotes(ctx, release.Note, body), release.ID)
 		if err != nil {
 			return "", err
 		}
@@ -165,6 +252,15 @@ func (c *giteaClient) CreateRelease(ctx *context.Context, body string) (string,
 		}
 	}
 
+	if downloadURL, err := giteaDownloadURL(ctx); err == nil {
+		ctx.ReleaseURL = fmt.Sprintf(
+			"%s/%s/%s/releases/tag/%s",
+			downloadURL,
+			releaseConfig.Gitea.Owner,
+			releaseConfig.Gitea.Name,
+			ctx.Git.CurrentTag,
+		)
+	}
 	return strconv.FormatInt(release.ID, 10), nil
 }
 
@@ -183,6 +279,46 @@ func (c *giteaClient) Upload(
 	owner := releaseConfig.Gitea.Owner
 	repoName := releaseConfig.Gitea.Name
 
+	attachments, err := c.client.ListReleaseAttachments(owner, repoName, giteaReleaseID)
+	if err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		if attachment.Name != artifact.Name {
+			continue
+		}
+		if ctx.Config.Release.Mode != ReleaseModeReplace {
+			log.WithField("name", artifact.Name).Info("already in the release, keeping it")
+			return nil
+		}
+		log.WithField("name", artifact.Name).Info("already in the release, replacing it")
+		if err := c.client.DeleteReleaseAttachment(owner, repoName, giteaReleaseID, attachment.ID); err != nil {
+			return err
+		}
+	}
+
 	_, err = c.client.CreateReleaseAttachment(owner, repoName, giteaReleaseID, file, artifact.Name)
 	return err
 }
+
+// giteaDownloadURL returns the URL of the gitea instance web interface, which
+// defaults to the one of its api
+func giteaDownloadURL(ctx *context.Context) (string, error) {
+	if ctx.Config.GiteaURLs.Download != "" {
+		return strings.TrimSuffix(ctx.Config.GiteaURLs.Download, "/"), nil
+	}
+	return getInstanceURL(ctx.Config.GiteaURLs.API)
+}
+
+// GiteaURLTemplate returns the default template of the download URL of the
+// release attachments on Gitea
+func GiteaURLTemplate(ctx *context.Context) string {
+	// the api URL is validated by the client, before anything gets published
+	downloadURL, _ := giteaDownloadURL(ctx)
+	return fmt.Sprintf(
+		"%s/%s/%s/releases/download/{{ .Tag }}/{{ .ArtifactName }}",
+		downloadURL,
+		ctx.Config.Release.Gitea.Owner,
+		ctx.Config.Release.Gitea.Name,
+	)
+}
@@ -1,8 +1,11 @@
 package client
 
 import (
+	stdctx "context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"testing"
 
@@ -241,16 +244,88 @@ func TestGiteaupdateReleaseSuite(t *testing.T) {
 	suite.Run(t, new(GiteaupdateReleaseSuite))
 }
 
-func TestGiteaCreateFile(t *testing.T) {
-	client := giteaClient{}
-	ctx := context.Context{}
-	author := config.CommitAuthor{}
-	repo := config.Repo{}
-	content := []byte{}
-	path := ""
-	message := ""
-	file := client.CreateFile(&ctx, author, repo, content, path, message)
-	assert.Nil(t, file)
+type GiteaCreateFileSuite struct {
+	GiteaReleasesTestSuite
+	fileURL string
+	author  config.CommitAuthor
+	repo    config.Repo
+}
+
+func (s *GiteaCreateFileSuite) SetupTest() {
+	s.GiteaReleasesTestSuite.SetupTest()
+	s.ctx.Context = stdctx.Background()
+	s.client.http = http.DefaultClient
+	s.client.api = s.url + "/api/v1"
+	s.client.token = "secret"
+	s.fileURL = s.url + "/api/v1/repos/owner/bucket/contents/Formula/project.rb"
+	s.author = config.CommitAuthor{Name: "bot", Email: "bot@example.com"}
+	s.repo = config.Repo{Owner: "owner", Name: "bucket"}
+}
+
+func (s *GiteaCreateFileSuite) TestCreate() {
+	t := s.T()
+	var body map[string]interface{}
+	httpmock.RegisterResponder("GET", s.fileURL, httpmock.NewStringResponder(404, ""))
+	httpmock.RegisterResponder("POST", s.fileURL, func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "token secret", req.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		return httpmock.NewStringResponse(201, "{}"), nil
+	})
+
+	err := s.client.CreateFile(s.ctx, s.author, s.repo, []byte("formula"), "Formula/project.rb", "update")
+	assert.NoError(t, err)
+	assert.Equal(t, "Zm9ybXVsYQ==", body["content"])
+	assert.Equal(t, "update", body["message"])
+	assert.Equal(t, map[string]interface{}{"name": "bot", "email": "bot@example.com"}, body["author"])
+	assert.NotContains(t, body, "sha")
+}
+
+func (s *GiteaCreateFileSuite) TestUpdate() {
+	t := s.T()
+	var body map[string]interface{}
+	httpmock.RegisterResponder("GET", s.fileURL, httpmock.NewStringResponder(200, `{"sha":"abc123"}`))
+	httpmock.RegisterResponder("PUT", s.fileURL, func(req *http.Request) (*http.Response, error) {
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		return httpmock.NewStringResponse(200, "{}"), nil
+	})
+
+	err := s.client.CreateFile(s.ctx, s.author, s.repo, []byte("formula"), "Formula/project.rb", "update")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", body["sha"])
+}
+
+func (s *GiteaCreateFileSuite) TestErrorGettingFile() {
+	t := s.T()
+	httpmock.RegisterResponder("GET", s.fileURL, httpmock.NewStringResponder(401, "unauthorized"))
+
+	err := s.client.CreateFile(s.ctx, s.author, s.repo, []byte("formula"), "Formula/project.rb", "update")
+	assert.EqualError(t, err, "GET "+s.fileURL+": 401 unauthorized")
+}
+
+func (s *GiteaCreateFileSuite) TestErrorCreatingFile() {
+	t := s.T()
+	httpmock.RegisterResponder("GET", s.fileURL, httpmock.NewStringResponder(404, ""))
+	httpmock.RegisterResponder("POST", s.fileURL, httpmock.NewStringResponder(422, "invalid"))
+
+	err := s.client.CreateFile(s.ctx, s.author, s.repo, []byte("formula"), "Formula/project.rb", "update")
+	assert.EqualError(t, err, "POST "+s.fileURL+": 422 invalid")
+}
+
+func TestGiteaCreateFileSuite(t *testing.T) {
+	suite.Run(t, new(GiteaCreateFileSuite))
+}
+
+func TestGiteaURLTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{
+		GiteaURLs: config.GiteaURLs{API: "https://gitea.example.com/api/v1"},
+		Release: config.Release{
+			Gitea: config.Repo{Owner: "owner", Name: "repo"},
+		},
+	})
+	assert.Equal(t, "https://gitea.example.com/owner/repo/releases/download/{{ .Tag }}/{{ .ArtifactName }}", GiteaURLTemplate(ctx))
+
+	ctx.Config.GiteaURLs.Download = "https://git.example.com/"
+	assert.Equal(t, "https://git.example.com/owner/repo/releases/download/{{ .Tag }}/{{ .ArtifactName }}", GiteaURLTemplate(ctx))
 }
 
 type GiteaCreateReleaseSuite struct {
@@ -361,6 +436,7 @@ func (s *GiteaUploadSuite) SetupTest() {
 	require.NotNil(t, file)
 	s.file = file
 	s.releaseAttachmentsURL = fmt.Sprintf("%v/assets", s.releaseURL)
+	httpmock.RegisterResponder("GET", s.releaseAttachmentsURL, httpmock.NewStringResponder(200, "[]"))
 }
 
 func (s *GiteaUploadSuite) TearDownTest() {
@@ -395,6 +471,39 @@ func (s *GiteaUploadSuite) TestSuccess() {
 	assert.NoError(t, err)
 }
 
+func (s *GiteaUploadSuite) TestKeepExisting() {
+	t := s.T()
+	attachments := []gitea.Attachment{{ID: 1, Name: s.artifact.Name}}
+	resp, err := httpmock.NewJsonResponder(200, &attachments)
+	require.NoError(t, err)
+	httpmock.RegisterResponder("GET", s.releaseAttachmentsURL, resp)
+
+	err = s.client.Upload(s.ctx, fmt.Sprint(s.releaseID), s.artifact, s.file)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+}
+
+func (s *GiteaUploadSuite) TestReplaceExisting() {
+	t := s.T()
+	s.ctx.Config.Release.Mode = ReleaseModeReplace
+	attachments := []gitea.Attachment{{ID: 1, Name: s.artifact.Name}}
+	resp, err := httpmock.NewJsonResponder(200, &attachments)
+	require.NoError(t, err)
+	httpmock.RegisterResponder("GET", s.releaseAttachmentsURL, resp)
+	httpmock.RegisterResponder("DELETE", s.releaseAttachmentsURL+"/1", httpmock.NewStringResponder(204, ""))
+	resp, err = httpmock.NewJsonResponder(200, &gitea.Attachment{})
+	require.NoError(t, err)
+	httpmock.RegisterResponder("POST", s.releaseAttachmentsURL, resp)
+
+	err = s.client.Upload(s.ctx, fmt.Sprint(s.releaseID), s.artifact, s.file)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		"GET " + s.releaseAttachmentsURL:           1,
+		"DELETE " + s.releaseAttachmentsURL + "/1": 1,
+		"POST " + s.releaseAttachmentsURL:          1,
+	}, httpmock.GetCallCountInfo())
+}
+
 func TestGiteaUploadSuite(t *testing.T) {
 	suite.Run(t, new(GiteaUploadSuite))
 }
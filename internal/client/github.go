@@ -2,11 +2,16 @@ package client
 
 import (
 	"crypto/tls"
+	"fmt"
+	"io"
 	"net/http"
-	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/apex/log"
 	"github.com/google/go-github/v28/github"
@@ -14,11 +19,17 @@ import (
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 )
 
 type githubClient struct {
 	client *github.Client
+
+	// the assets of each release, listed once and shared by the uploads
+	// until one of them fails
+	assetsLock sync.Mutex
+	assets     map[int64]map[string]*github.ReleaseAsset
 }
 
 // NewGitHub returns a github client implementation
@@ -39,16 +50,16 @@ func NewGitHub(ctx *context.Context) (Client, error) {
 	httpClient.Transport.(*oauth2.Transport).Base = base
 	client := github.NewClient(httpClient)
 	if ctx.Config.GitHubURLs.API != "" {
-		api, err := url.Parse(ctx.Config.GitHubURLs.API)
-		if err != nil {
-			return &githubClient{}, err
+		var upload = ctx.Config.GitHubURLs.Upload
+		if upload == "" {
+			// GitHub Enterprise serves uploads next to its api
+			upload = strings.Replace(ctx.Config.GitHubURLs.API, "/api/v3", "/api/uploads", 1)
 		}
-		upload, err := url.Parse(ctx.Config.GitHubURLs.Upload)
+		enterprise, err := github.NewEnterpriseClient(ctx.Config.GitHubURLs.API, upload, httpClient)
 		if err != nil {
 			return &githubClient{}, err
 		}
-		client.BaseURL = api
-		client.UploadURL = upload
+		client = enterprise
 	}
 
 	return &githubClient{client: client}, nil
@@ -124,17 +135,9 @@ func (c *githubClient) CreateRelease(ctx *context.Context, body string) (string,
 		ctx.Git.CurrentTag,
 	)
 	if err != nil {
-		release, _, err = c.client.Repositories.CreateRelease(
-			ctx,
-			ctx.Config.Release.GitHub.Owner,
-			ctx.Config.Release.GitHub.Name,
-			data,
-		)
+		release, err = c.createRelease(ctx, data)
 	} else {
-		// keep the pre-existing release notes
-		if release.GetBody() != "" {
-			data.Body = release.Body
-		}
+		data.Body = github.String(releaseNotes(ctx, release.GetBody(), body))
 		release, _, err = c.client.Repositories.EditRelease(
 			ctx,
 			ctx.Config.Release.GitHub.Owner,
@@ -144,10 +147,77 @@ func (c *githubClient) CreateRelease(ctx *context.Context, body string) (string,
 		)
 	}
 	log.WithField("url", release.GetHTMLURL()).Info("release updated")
+	ctx.ReleaseURL = release.GetHTMLURL()
 	githubReleaseID := strconv.FormatInt(release.GetID(), 10)
 	return githubReleaseID, err
 }
 
+// releaseWithDiscussion is a release starting a discussion, which the
+// github library doesn't support yet
+type releaseWithDiscussion struct {
+	*github.RepositoryRelease
+	DiscussionCategoryName string `json:"discussion_category_name,omitempty"`
+}
+
+// createRelease creates the given release, starting a discussion about it in
+// the configured category, if any
+func (c *githubClient) createRelease(ctx *context.Context, data *github.RepositoryRelease) (*github.RepositoryRelease, error) {
+	category, err := tmpl.New(ctx).Apply(ctx.Config.Release.DiscussionCategoryName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to template the discussion category name")
+	}
+	if category == "" {
+		release, _, err := c.client.Repositories.CreateRelease(
+			ctx,
+			ctx.Config.Release.GitHub.Owner,
+			ctx.Config.Release.GitHub.Name,
+			data,
+		)
+		return release, err
+	}
+	req, err := c.client.NewRequest(http.MethodPost, fmt.Sprintf(
+		"repos/%s/%s/releases",
+		ctx.Config.Release.GitHub.Owner,
+		ctx.Config.Release.GitHub.Name,
+	), &releaseWithDiscussion{
+		RepositoryRelease:      data,
+		DiscussionCategoryName: category,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var release = &github.RepositoryRelease{}
+	_, err = c.client.Do(ctx, req, release)
+	return release, err
+}
+
+// CloseMilestone closes the open milestone with the given title
+func (c *githubClient) CloseMilestone(ctx *context.Context, repo config.Repo, title string) error {
+	var opts = &github.MilestoneListOptions{
+		State:       "open",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		milestones, res, err := c.client.Issues.ListMilestones(ctx, repo.Owner, repo.Name, opts)
+		if err != nil {
+			return err
+		}
+		for _, milestone := range milestones {
+			if milestone.GetTitle() != title {
+				continue
+			}
+			_, _, err := c.client.Issues.EditMilestone(ctx, repo.Owner, repo.Name, milestone.GetNumber(), &github.Milestone{
+				State: github.String("closed"),
+			})
+			return err
+		}
+		if res.NextPage == 0 {
+			return fmt.Errorf("no open milestone %q found in %s", title, repo)
+		}
+		opts.Page = res.NextPage
+	}
+}
+
 func (c *githubClient) Upload(
 	ctx *context.Context,
 	releaseID string,
@@ -158,6 +228,29 @@ func (c *githubClient) Upload(
 	if err != nil {
 		return err
 	}
+	assets, err := c.releaseAssets(ctx, githubReleaseID)
+	if err != nil {
+		return err
+	}
+	if existing := assets[artifact.Name]; existing != nil {
+		if ctx.Config.Release.Mode != ReleaseModeReplace {
+			log.WithField("name", artifact.Name).Info("already in the release, keeping it")
+			return nil
+		}
+		log.WithField("name", artifact.Name).Info("already in the release, replacing it")
+		if _, err := c.client.Repositories.DeleteReleaseAsset(
+			ctx,
+			ctx.Config.Release.GitHub.Owner,
+			ctx.Config.Release.GitHub.Name,
+			existing.GetID(),
+		); err != nil {
+			c.forgetAssets(githubReleaseID)
+			return err
+		}
+		c.assetsLock.Lock()
+		delete(assets, artifact.Name)
+		c.assetsLock.Unlock()
+	}
 	_, _, err = c.client.Repositories.UploadReleaseAsset(
 		ctx,
 		ctx.Config.Release.GitHub.Owner,
@@ -168,5 +261,297 @@ func (c *githubClient) Upload(
 		},
 		file,
 	)
+	if err != nil {
+		// a failed upload may leave a broken asset behind, so the retries
+		// list the release again
+		c.forgetAssets(githubReleaseID)
+	}
 	return err
 }
+
+// forgetAssets drops the assets listed for the release, so they are listed
+// again on the next upload.
+func (c *githubClient) forgetAssets(releaseID int64) {
+	c.assetsLock.Lock()
+	defer c.assetsLock.Unlock()
+	delete(c.assets, releaseID)
+}
+
+// releaseAssets returns the assets of the release indexed by name. They are
+// listed only once per release, as every upload needs them.
+func (c *githubClient) releaseAssets(ctx *context.Context, releaseID int64) (map[string]*github.ReleaseAsset, error) {
+	c.assetsLock.Lock()
+	defer c.assetsLock.Unlock()
+	if assets, ok := c.assets[releaseID]; ok {
+		return assets, nil
+	}
+	var result = map[string]*github.ReleaseAsset{}
+	var opts = &github.ListOptions{PerPage: 100}
+	for {
+		assets, res, err := c.client.Repositories.ListReleaseAssets(
+			ctx,
+			ctx.Config.Release.GitHub.Owner,
+			ctx.Config.Release.GitHub.Name,
+			releaseID,
+			opts,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, asset := range assets {
+			result[asset.GetName()] = asset
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+	if c.assets == nil {
+		c.assets = map[int64]map[string]*github.ReleaseAsset{}
+	}
+	c.assets[releaseID] = result
+	return result, nil
+}
+
+// DeleteRelease deletes the release of the given tag and the tag itself
+func (c *githubClient) DeleteRelease(ctx *context.Context, tag string) error {
+	var repo = ctx.Config.Release.GitHub
+	release, res, err := c.client.Repositories.GetReleaseByTag(ctx, repo.Owner, repo.Name, tag)
+	if err != nil && (res == nil || res.StatusCode != 404) {
+		return err
+	}
+	if err == nil {
+		log.WithField("id", release.GetID()).Info("deleting release")
+		if _, err := c.client.Repositories.DeleteRelease(ctx, repo.Owner, repo.Name, release.GetID()); err != nil {
+			return err
+		}
+	}
+	log.WithField("tag", tag).Info("deleting tag")
+	_, err = c.client.Git.DeleteRef(ctx, repo.Owner, repo.Name, "tags/"+tag)
+	return err
+}
+
+// RevertFile restores the given file to the content it had before its last
+// commit, deleting it if that commit created it
+func (c *githubClient) RevertFile(
+	ctx *context.Context,
+	commitAuthor config.CommitAuthor,
+	repo config.Repo,
+	path,
+	message string,
+) error {
+	commits, _, err := c.client.Repositories.ListCommits(ctx, repo.Owner, repo.Name, &github.CommitsListOptions{
+		Path: path,
+		ListOptions: github.ListOptions{
+			PerPage: 2,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	file, _, _, err := c.client.Repositories.GetContents(ctx, repo.Owner, repo.Name, path, &github.RepositoryContentGetOptions{})
+	if err != nil {
+		return err
+	}
+	var options = &github.RepositoryContentFileOptions{
+		Committer: &github.CommitAuthor{
+			Name:  github.String(commitAuthor.Name),
+			Email: github.String(commitAuthor.Email),
+		},
+		Message: github.String(message),
+		SHA:     file.SHA,
+	}
+	if len(commits) < 2 {
+		_, _, err = c.client.Repositories.DeleteFile(ctx, repo.Owner, repo.Name, path, options)
+		return err
+	}
+	previous, _, _, err := c.client.Repositories.GetContents(ctx, repo.Owner, repo.Name, path, &github.RepositoryContentGetOptions{
+		Ref: commits[1].GetSHA(),
+	})
+	if err != nil {
+		return err
+	}
+	content, err := previous.GetContent()
+	if err != nil {
+		return err
+	}
+	options.Content = []byte(content)
+	_, _, err = c.client.Repositories.UpdateFile(ctx, repo.Owner, repo.Name, path, options)
+	return err
+}
+
+// DownloadReleaseAssets downloads the assets of the release of the given tag
+// to dir, returning their names
+func (c *githubClient) DownloadReleaseAssets(ctx *context.Context, tag, dir string) ([]string, error) {
+	var repo = ctx.Config.Release.GitHub
+	release, _, err := c.client.Repositories.GetReleaseByTag(ctx, repo.Owner, repo.Name, tag)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	var opts = &github.ListOptions{PerPage: 100}
+	for {
+		assets, res, err := c.client.Repositories.ListReleaseAssets(ctx, repo.Owner, repo.Name, release.GetID(), opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, asset := range assets {
+			log.WithField("file", asset.GetName()).Info("downloading")
+			if err := c.downloadAsset(ctx, repo, asset, dir); err != nil {
+				return nil, errors.Wrapf(err, "failed to download %s", asset.GetName())
+			}
+			names = append(names, asset.GetName())
+		}
+		if res.NextPage == 0 {
+			return names, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+func (c *githubClient) downloadAsset(ctx *context.Context, repo config.Repo, asset *github.ReleaseAsset, dir string) error {
+	rc, redirect, err := c.client.Repositories.DownloadReleaseAsset(ctx, repo.Owner, repo.Name, asset.GetID())
+	if err != nil {
+		return err
+	}
+	if redirect != "" {
+		req, err := http.NewRequest(http.MethodGet, redirect, nil)
+		if err != nil {
+			return err
+		}
+		res, err := http.DefaultClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		if res.StatusCode != http.StatusOK {
+			res.Body.Close() // nolint: errcheck
+			return fmt.Errorf("unexpected status %s", res.Status)
+		}
+		rc = res.Body
+	}
+	defer rc.Close() // nolint: errcheck
+	file, err := os.Create(filepath.Join(dir, filepath.Base(asset.GetName())))
+	if err != nil {
+		return err
+	}
+	defer file.Close() // nolint: errcheck
+	if _, err := io.Copy(file, rc); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// GetFile returns the content of the given file in the default branch
+func (c *githubClient) GetFile(ctx *context.Context, repo config.Repo, path string) ([]byte, error) {
+	file, _, _, err := c.client.Repositories.GetContents(ctx, repo.Owner, repo.Name, path, &github.RepositoryContentGetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	content, err := file.GetContent()
+	return []byte(content), err
+}
+
+// OpenPullRequest commits the given files to a new branch of repo, created
+// from its default branch, and opens a pull request from it against the
+// default branch of base
+func (c *githubClient) OpenPullRequest(
+	ctx *context.Context,
+	commitAuthor config.CommitAuthor,
+	repo,
+	base config.Repo,
+	files map[string][]byte,
+	branch,
+	title,
+	message string,
+) (string, error) {
+	r, _, err := c.client.Repositories.Get(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		return "", err
+	}
+	var from = r.GetDefaultBranch()
+	ref, _, err := c.client.Git.GetRef(ctx, repo.Owner, repo.Name, "heads/"+from)
+	if err != nil {
+		return "", err
+	}
+	log.WithField("branch", branch).Info("creating branch")
+	var branchRef = &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: ref.Object,
+	}
+	if _, res, err := c.client.Git.CreateRef(ctx, repo.Owner, repo.Name, branchRef); err != nil {
+		if res == nil || res.StatusCode != http.StatusUnprocessableEntity {
+			return "", err
+		}
+		// left over by a previous run, it starts again from the default branch
+		log.WithField("branch", branch).Info("branch already exists, resetting it")
+		if _, _, err := c.client.Git.UpdateRef(ctx, repo.Owner, repo.Name, branchRef, true); err != nil {
+			return "", err
+		}
+	}
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		var options = &github.RepositoryContentFileOptions{
+			Committer: &github.CommitAuthor{
+				Name:  github.String(commitAuthor.Name),
+				Email: github.String(commitAuthor.Email),
+			},
+			Content: files[path],
+			Message: github.String(message),
+			Branch:  github.String(branch),
+		}
+		file, _, res, err := c.client.Repositories.GetContents(ctx, repo.Owner, repo.Name, path, &github.RepositoryContentGetOptions{
+			Ref: branch,
+		})
+		if err != nil && (res == nil || res.StatusCode != 404) {
+			return "", err
+		}
+		if err != nil {
+			log.WithField("file", path).Info("creating")
+			if _, _, err := c.client.Repositories.CreateFile(ctx, repo.Owner, repo.Name, path, options); err != nil {
+				return "", err
+			}
+			continue
+		}
+		log.WithField("file", path).Info("updating")
+		options.SHA = file.SHA
+		if _, _, err := c.client.Repositories.UpdateFile(ctx, repo.Owner, repo.Name, path, options); err != nil {
+			return "", err
+		}
+	}
+	var head, into = branch, from
+	if base.String() != repo.String() {
+		b, _, err := c.client.Repositories.Get(ctx, base.Owner, base.Name)
+		if err != nil {
+			return "", err
+		}
+		head = repo.Owner + ":" + branch
+		into = b.GetDefaultBranch()
+	}
+	pr, res, err := c.client.PullRequests.Create(ctx, base.Owner, base.Name, &github.NewPullRequest{
+		Title: github.String(title),
+		Head:  github.String(head),
+		Base:  github.String(into),
+		Body:  github.String(message),
+	})
+	if err != nil && res != nil && res.StatusCode == http.StatusUnprocessableEntity {
+		// the pull request of a previous run is still open, and now has the
+		// new commits
+		prs, _, lerr := c.client.PullRequests.List(ctx, base.Owner, base.Name, &github.PullRequestListOptions{
+			State: "open",
+			Head:  repo.Owner + ":" + branch,
+			Base:  into,
+		})
+		if lerr == nil && len(prs) > 0 {
+			log.WithField("url", prs[0].GetHTMLURL()).Info("pull request already open")
+			return prs[0].GetHTMLURL(), nil
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return pr.GetHTMLURL(), nil
+}
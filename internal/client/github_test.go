@@ -1,6 +1,15 @@
 package client
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
@@ -21,6 +30,18 @@ func TestNewGitHubClient(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("enterprise urls", func(t *testing.T) {
+		c, err := NewGitHub(context.New(config.Project{
+			GitHubURLs: config.GitHubURLs{
+				API: "https://github.mycompany.com/api/v3",
+			},
+		}))
+
+		require.NoError(t, err)
+		require.Equal(t, "https://github.mycompany.com/api/v3/", c.(*githubClient).client.BaseURL.String())
+		require.Equal(t, "https://github.mycompany.com/api/uploads/", c.(*githubClient).client.UploadURL.String())
+	})
+
 	t.Run("bad api url", func(t *testing.T) {
 		_, err := NewGitHub(context.New(config.Project{
 			GitHubURLs: config.GitHubURLs{
@@ -69,3 +90,531 @@ func TestGitHubCreateReleaseWrongNameTemplate(t *testing.T) {
 	require.Empty(t, str)
 	require.EqualError(t, err, `template: tmpl:1: unclosed action`)
 }
+
+func newGitHubTestServer(t *testing.T, handler http.HandlerFunc) (*context.Context, Client, func()) {
+	srv := httptest.NewServer(handler)
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API:    srv.URL + "/",
+			Upload: srv.URL + "/",
+		},
+		Release: config.Release{
+			GitHub: config.Repo{
+				Owner: "goreleaser",
+				Name:  "fake",
+			},
+		},
+	})
+	client, err := NewGitHub(ctx)
+	require.NoError(t, err)
+	return ctx, client, srv.Close
+}
+
+func TestGitHubDeleteRelease(t *testing.T) {
+	var calls []string
+	ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `{"id": 42}`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer done()
+
+	require.NoError(t, client.(Remover).DeleteRelease(ctx, "v1.0.0"))
+	require.Equal(t, []string{
+		"GET /repos/goreleaser/fake/releases/tags/v1.0.0",
+		"DELETE /repos/goreleaser/fake/releases/42",
+		"DELETE /repos/goreleaser/fake/git/refs/tags/v1.0.0",
+	}, calls)
+}
+
+func TestGitHubDownloadReleaseAssets(t *testing.T) {
+	var srvURL string
+	ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/goreleaser/fake/releases/tags/v1.0.0":
+			fmt.Fprint(w, `{"id": 42}`)
+		case "/repos/goreleaser/fake/releases/42/assets":
+			fmt.Fprint(w, `[{"id": 1, "name": "foo.tar.gz"}, {"id": 2, "name": "checksums.txt"}]`)
+		case "/repos/goreleaser/fake/releases/assets/1":
+			require.Equal(t, "application/octet-stream", r.Header.Get("Accept"))
+			fmt.Fprint(w, "foo")
+		case "/repos/goreleaser/fake/releases/assets/2":
+			http.Redirect(w, r, srvURL+"/storage/checksums.txt", http.StatusFound)
+		case "/storage/checksums.txt":
+			fmt.Fprint(w, "sums")
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	})
+	defer done()
+	srvURL = strings.TrimSuffix(ctx.Config.GitHubURLs.API, "/")
+
+	dir, err := ioutil.TempDir("", "goreleaser")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	names, err := client.(Downloader).DownloadReleaseAssets(ctx, "v1.0.0", dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo.tar.gz", "checksums.txt"}, names)
+	for name, content := range map[string]string{
+		"foo.tar.gz":    "foo",
+		"checksums.txt": "sums",
+	} {
+		bts, err := ioutil.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		require.Equal(t, content, string(bts))
+	}
+}
+
+func TestGitHubRevertFile(t *testing.T) {
+	var previous = base64.StdEncoding.EncodeToString([]byte("previous formula"))
+	var updated map[string]interface{}
+	ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/goreleaser/tap/commits":
+			require.Equal(t, "Formula/fake.rb", r.URL.Query().Get("path"))
+			fmt.Fprint(w, `[{"sha": "new"}, {"sha": "old"}]`)
+		case r.Method == http.MethodGet && r.URL.Query().Get("ref") == "old":
+			fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`, previous)
+		case r.Method == http.MethodGet:
+			fmt.Fprint(w, `{"type": "file", "sha": "current"}`)
+		case r.Method == http.MethodPut:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&updated))
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	})
+	defer done()
+
+	require.NoError(t, client.(Remover).RevertFile(
+		ctx,
+		config.CommitAuthor{Name: "bot", Email: "bot@example.com"},
+		config.Repo{Owner: "goreleaser", Name: "tap"},
+		"Formula/fake.rb",
+		"revert",
+	))
+	require.Equal(t, "current", updated["sha"])
+	require.Equal(t, previous, updated["content"])
+	require.Equal(t, "revert", updated["message"])
+}
+
+func TestGitHubRevertCreatedFile(t *testing.T) {
+	var deleted bool
+	ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/goreleaser/tap/commits":
+			fmt.Fprint(w, `[{"sha": "new"}]`)
+		case r.Method == http.MethodGet:
+			fmt.Fprint(w, `{"type": "file", "sha": "current"}`)
+		case r.Method == http.MethodDelete:
+			deleted = true
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	})
+	defer done()
+
+	require.NoError(t, client.(Remover).RevertFile(
+		ctx,
+		config.CommitAuthor{Name: "bot", Email: "bot@example.com"},
+		config.Repo{Owner: "goreleaser", Name: "tap"},
+		"Formula/fake.rb",
+		"revert",
+	))
+	require.True(t, deleted)
+}
+
+func TestGitHubOpenPullRequest(t *testing.T) {
+	var calls []string
+	var updated map[string]interface{}
+	var pull map[string]interface{}
+	ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/goreleaser/fake":
+			fmt.Fprint(w, `{"default_branch": "master"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/goreleaser/fake/git/refs/heads/master":
+			fmt.Fprint(w, `{"ref": "refs/heads/master", "object": {"sha": "abc"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/goreleaser/fake/git/refs":
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodGet:
+			require.Equal(t, "bump-v1.0.0", r.URL.Query().Get("ref"))
+			fmt.Fprint(w, `{"type": "file", "sha": "current"}`)
+		case r.Method == http.MethodPut:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&updated))
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/goreleaser/fake/pulls":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&pull))
+			fmt.Fprint(w, `{"html_url": "https://github.com/goreleaser/fake/pull/1"}`)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	})
+	defer done()
+
+	url, err := client.(PullRequester).OpenPullRequest(
+		ctx,
+		config.CommitAuthor{Name: "bot", Email: "bot@example.com"},
+		config.Repo{Owner: "goreleaser", Name: "fake"},
+		config.Repo{Owner: "goreleaser", Name: "fake"},
+		map[string][]byte{"README.md": []byte("v1.0.0")},
+		"bump-v1.0.0",
+		"Bump to v1.0.0",
+		"bump",
+	)
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/goreleaser/fake/pull/1", url)
+	require.Equal(t, []string{
+		"GET /repos/goreleaser/fake",
+		"GET /repos/goreleaser/fake/git/refs/heads/master",
+		"POST /repos/goreleaser/fake/git/refs",
+		"GET /repos/goreleaser/fake/contents/README.md",
+		"PUT /repos/goreleaser/fake/contents/README.md",
+		"POST /repos/goreleaser/fake/pulls",
+	}, calls)
+	require.Equal(t, "bump-v1.0.0", updated["branch"])
+	require.Equal(t, "current", updated["sha"])
+	require.Equal(t, base64.StdEncoding.EncodeToString([]byte("v1.0.0")), updated["content"])
+	require.Equal(t, "master", pull["base"])
+	require.Equal(t, "bump-v1.0.0", pull["head"])
+}
+
+func TestGitHubOpenPullRequestAgain(t *testing.T) {
+	var calls []string
+	var reset map[string]interface{}
+	ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/goreleaser/fake":
+			fmt.Fprint(w, `{"default_branch": "master"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/goreleaser/fake/git/refs/heads/master":
+			fmt.Fprint(w, `{"ref": "refs/heads/master", "object": {"sha": "abc"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/goreleaser/fake/git/refs":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "Reference already exists"}`)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/goreleaser/fake/git/refs/heads/bump-v1.0.0":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&reset))
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/goreleaser/fake/contents/README.md":
+			fmt.Fprint(w, `{"type": "file", "sha": "current"}`)
+		case r.Method == http.MethodPut:
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/goreleaser/fake/pulls":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "Validation Failed", "errors": [{"message": "A pull request already exists for goreleaser:bump-v1.0.0."}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/goreleaser/fake/pulls":
+			require.Equal(t, "goreleaser:bump-v1.0.0", r.URL.Query().Get("head"))
+			require.Equal(t, "master", r.URL.Query().Get("base"))
+			fmt.Fprint(w, `[{"html_url": "https://github.com/goreleaser/fake/pull/1"}]`)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	})
+	defer done()
+
+	url, err := client.(PullRequester).OpenPullRequest(
+		ctx,
+		config.CommitAuthor{Name: "bot", Email: "bot@example.com"},
+		config.Repo{Owner: "goreleaser", Name: "fake"},
+		config.Repo{Owner: "goreleaser", Name: "fake"},
+		map[string][]byte{"README.md": []byte("v1.0.0")},
+		"bump-v1.0.0",
+		"Bump to v1.0.0",
+		"bump",
+	)
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/goreleaser/fake/pull/1", url)
+	require.Equal(t, []string{
+		"GET /repos/goreleaser/fake",
+		"GET /repos/goreleaser/fake/git/refs/heads/master",
+		"POST /repos/goreleaser/fake/git/refs",
+		"PATCH /repos/goreleaser/fake/git/refs/heads/bump-v1.0.0",
+		"GET /repos/goreleaser/fake/contents/README.md",
+		"PUT /repos/goreleaser/fake/contents/README.md",
+		"POST /repos/goreleaser/fake/pulls",
+		"GET /repos/goreleaser/fake/pulls",
+	}, calls)
+	require.Equal(t, "abc", reset["sha"])
+	require.Equal(t, true, reset["force"])
+}
+
+func TestGitHubOpenPullRequestFromFork(t *testing.T) {
+	var calls []string
+	var created map[string]interface{}
+	var pull map[string]interface{}
+	ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/bot/pkgs":
+			fmt.Fprint(w, `{"default_branch": "main"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/upstream/pkgs":
+			fmt.Fprint(w, `{"default_branch": "master"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/bot/pkgs/git/refs/heads/main":
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "abc"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/bot/pkgs/git/refs":
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		case r.Method == http.MethodPut:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/upstream/pkgs/pulls":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&pull))
+			fmt.Fprint(w, `{"html_url": "https://github.com/upstream/pkgs/pull/1"}`)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	})
+	defer done()
+
+	url, err := client.(PullRequester).OpenPullRequest(
+		ctx,
+		config.CommitAuthor{Name: "bot", Email: "bot@example.com"},
+		config.Repo{Owner: "bot", Name: "pkgs"},
+		config.Repo{Owner: "upstream", Name: "pkgs"},
+		map[string][]byte{"foo/1.0.0.yaml": []byte("foo")},
+		"foo-1.0.0",
+		"New version: foo 1.0.0",
+		"foo",
+	)
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/upstream/pkgs/pull/1", url)
+	require.Equal(t, []string{
+		"GET /repos/bot/pkgs",
+		"GET /repos/bot/pkgs/git/refs/heads/main",
+		"POST /repos/bot/pkgs/git/refs",
+		"GET /repos/bot/pkgs/contents/foo/1.0.0.yaml",
+		"PUT /repos/bot/pkgs/contents/foo/1.0.0.yaml",
+		"GET /repos/upstream/pkgs",
+		"POST /repos/upstream/pkgs/pulls",
+	}, calls)
+	require.Equal(t, "foo-1.0.0", created["branch"])
+	require.Nil(t, created["sha"])
+	require.Equal(t, "master", pull["base"])
+	require.Equal(t, "bot:foo-1.0.0", pull["head"])
+}
+
+func TestGitHubCreateReleaseModes(t *testing.T) {
+	for mode, expected := range map[string]string{
+		ReleaseModeKeepExisting: "existing notes",
+		ReleaseModeAppend:       "existing notes\n\nnew notes",
+		ReleaseModeReplace:      "new notes",
+	} {
+		t.Run(mode, func(t *testing.T) {
+			var edited map[string]interface{}
+			ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/repos/goreleaser/fake/releases/tags/v1.0.0":
+					fmt.Fprint(w, `{"id": 42, "body": "existing notes"}`)
+				case r.Method == http.MethodPatch && r.URL.Path == "/repos/goreleaser/fake/releases/42":
+					require.NoError(t, json.NewDecoder(r.Body).Decode(&edited))
+					fmt.Fprint(w, `{"id": 42}`)
+				default:
+					t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
+				}
+			})
+			defer done()
+			ctx.Config.Release.Mode = mode
+			ctx.Config.Release.NameTemplate = "{{ .Tag }}"
+			ctx.Git.CurrentTag = "v1.0.0"
+
+			id, err := client.CreateRelease(ctx, "new notes")
+			require.NoError(t, err)
+			require.Equal(t, "42", id)
+			require.Equal(t, expected, edited["body"])
+		})
+	}
+}
+
+func TestGitHubUploadExistingAsset(t *testing.T) {
+	for mode, expected := range map[string][]string{
+		ReleaseModeKeepExisting: {
+			"GET /repos/goreleaser/fake/releases/42/assets",
+		},
+		ReleaseModeReplace: {
+			"GET /repos/goreleaser/fake/releases/42/assets",
+			"DELETE /repos/goreleaser/fake/releases/assets/1",
+			"POST /repos/goreleaser/fake/releases/42/assets",
+		},
+	} {
+		t.Run(mode, func(t *testing.T) {
+			var calls []string
+			ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, r.Method+" "+r.URL.Path)
+				switch r.Method {
+				case http.MethodGet:
+					fmt.Fprint(w, `[{"id": 1, "name": "foo.tar.gz"}]`)
+				case http.MethodDelete:
+					w.WriteHeader(http.StatusNoContent)
+				default:
+					fmt.Fprint(w, `{"id": 2, "name": "foo.tar.gz"}`)
+				}
+			})
+			defer done()
+			ctx.Config.Release.Mode = mode
+
+			file, err := ioutil.TempFile("", "goreleaser")
+			require.NoError(t, err)
+			defer os.Remove(file.Name())
+			defer file.Close()
+			require.NoError(t, client.Upload(ctx, "42", &artifact.Artifact{Name: "foo.tar.gz"}, file))
+			require.Equal(t, expected, calls)
+		})
+	}
+}
+
+func TestGitHubUploadNewAsset(t *testing.T) {
+	var calls []string
+	ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `[{"id": 1, "name": "checksums.txt"}]`)
+			return
+		}
+		require.Equal(t, "foo.tar.gz", r.URL.Query().Get("name"))
+		fmt.Fprint(w, `{"id": 2, "name": "foo.tar.gz"}`)
+	})
+	defer done()
+
+	file, err := ioutil.TempFile("", "goreleaser")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	defer file.Close()
+	require.NoError(t, client.Upload(ctx, "42", &artifact.Artifact{Name: "foo.tar.gz"}, file))
+	require.Equal(t, []string{
+		"GET /repos/goreleaser/fake/releases/42/assets",
+		"POST /repos/goreleaser/fake/releases/42/assets",
+	}, calls)
+}
+
+func TestGitHubUploadListsAssetsOnce(t *testing.T) {
+	var calls []string
+	ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path+"?"+r.URL.Query().Get("page"))
+		if r.Method == http.MethodGet {
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", `<http://`+r.Host+r.URL.Path+`?page=2>; rel="next"`)
+				fmt.Fprint(w, `[{"id": 1, "name": "checksums.txt"}]`)
+				return
+			}
+			fmt.Fprint(w, `[{"id": 2, "name": "bar.tar.gz"}]`)
+			return
+		}
+		fmt.Fprint(w, `{"id": 3, "name": "foo.tar.gz"}`)
+	})
+	defer done()
+
+	file, err := ioutil.TempFile("", "goreleaser")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	defer file.Close()
+	require.NoError(t, client.Upload(ctx, "42", &artifact.Artifact{Name: "foo.tar.gz"}, file))
+	require.NoError(t, client.Upload(ctx, "42", &artifact.Artifact{Name: "bar.tar.gz"}, file))
+	require.Equal(t, []string{
+		"GET /repos/goreleaser/fake/releases/42/assets?",
+		"GET /repos/goreleaser/fake/releases/42/assets?2",
+		"POST /repos/goreleaser/fake/releases/42/assets?",
+	}, calls)
+}
+
+func TestGitHubUploadReplaceRetry(t *testing.T) {
+	var calls []string
+	var deleted, failed bool
+	ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			if deleted {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			fmt.Fprint(w, `[{"id": 1, "name": "foo.tar.gz"}]`)
+		case http.MethodDelete:
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			if !failed {
+				failed = true
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprint(w, `{"id": 2, "name": "foo.tar.gz"}`)
+		}
+	})
+	defer done()
+	ctx.Config.Release.Mode = ReleaseModeReplace
+
+	var upload = func() error {
+		file, err := ioutil.TempFile("", "goreleaser")
+		require.NoError(t, err)
+		defer os.Remove(file.Name())
+		defer file.Close()
+		return client.Upload(ctx, "42", &artifact.Artifact{Name: "foo.tar.gz"}, file)
+	}
+	require.Error(t, upload())
+	require.NoError(t, upload())
+	require.Equal(t, []string{
+		"GET /repos/goreleaser/fake/releases/42/assets",
+		"DELETE /repos/goreleaser/fake/releases/assets/1",
+		"POST /repos/goreleaser/fake/releases/42/assets",
+		"GET /repos/goreleaser/fake/releases/42/assets",
+		"POST /repos/goreleaser/fake/releases/42/assets",
+	}, calls)
+}
+
+func TestGitHubCreateReleaseWithDiscussion(t *testing.T) {
+	var created map[string]interface{}
+	ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/goreleaser/fake/releases/tags/v1.0.0":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/goreleaser/fake/releases":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			fmt.Fprint(w, `{"id": 42}`)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	})
+	defer done()
+	ctx.Config.Release.NameTemplate = "{{ .Tag }}"
+	ctx.Config.Release.DiscussionCategoryName = "{{ .Env.CATEGORY }}"
+	ctx.Env = map[string]string{"CATEGORY": "Announcements"}
+	ctx.Git.CurrentTag = "v1.0.0"
+
+	id, err := client.CreateRelease(ctx, "notes")
+	require.NoError(t, err)
+	require.Equal(t, "42", id)
+	require.Equal(t, "Announcements", created["discussion_category_name"])
+	require.Equal(t, "v1.0.0", created["tag_name"])
+	require.Equal(t, "notes", created["body"])
+}
+
+func TestGitHubCloseMilestone(t *testing.T) {
+	var edited map[string]interface{}
+	ctx, client, done := newGitHubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/goreleaser/fake/milestones":
+			require.Equal(t, "open", r.URL.Query().Get("state"))
+			fmt.Fprint(w, `[{"number": 1, "title": "v0.9.0"}, {"number": 2, "title": "v1.0.0"}]`)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/goreleaser/fake/milestones/2":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&edited))
+			fmt.Fprint(w, `{"number": 2}`)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	})
+	defer done()
+	var repo = config.Repo{Owner: "goreleaser", Name: "fake"}
+	require.NoError(t, client.(MilestoneCloser).CloseMilestone(ctx, repo, "v1.0.0"))
+	require.Equal(t, "closed", edited["state"])
+	require.EqualError(
+		t,
+		client.(MilestoneCloser).CloseMilestone(ctx, repo, "v2.0.0"),
+		`no open milestone "v2.0.0" found in goreleaser/fake`,
+	)
+}
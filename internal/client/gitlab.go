@@ -3,7 +3,9 @@ package client
 import (
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 
@@ -203,10 +205,11 @@ func (c *gitlabClient) CreateRelease(ctx *context.Context, body string) (release
 		}
 		log.WithField("name", release.Name).Info("release created")
 	} else {
-		desc := body
-		if release != nil && release.DescriptionHTML != "" {
-			desc = release.DescriptionHTML
+		var existing string
+		if release != nil {
+			existing = release.Description
 		}
+		desc := releaseNotes(ctx, existing, body)
 
 		release, _, err = c.client.Releases.UpdateRelease(projectID, tagName, &gitlab.UpdateReleaseOptions{
 			Name:        &name,
@@ -222,6 +225,7 @@ func (c *gitlabClient) CreateRelease(ctx *context.Context, body string) (release
 		log.WithField("name", release.Name).Info("release updated")
 	}
 
+	ctx.ReleaseURL = fmt.Sprintf("%s/%s/-/tags/%s", ctx.Config.GitLabURLs.Download, projectID, tagName)
 	return tagName, err // gitlab references a tag in a repo by its name
 }
 
@@ -234,6 +238,25 @@ func (c *gitlabClient) Upload(
 ) error {
 	projectID := ctx.Config.Release.GitLab.Owner + "/" + ctx.Config.Release.GitLab.Name
 
+	existing, err := c.findReleaseLink(projectID, releaseID, artifact.Name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if ctx.Config.Release.Mode != ReleaseModeReplace {
+			log.WithField("name", artifact.Name).Info("already in the release, keeping it")
+			return keepUploadHash(artifact, existing.URL)
+		}
+		log.WithField("name", artifact.Name).Info("already in the release, replacing it")
+		if _, _, err := c.client.ReleaseLinks.DeleteReleaseLink(projectID, releaseID, existing.ID); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Config.GitLabURLs.UsePackageRegistry {
+		return c.uploadToPackageRegistry(ctx, releaseID, artifact, file)
+	}
+
 	log.WithField("file", file.Name()).Debug("uploading file")
 	projectFile, _, err := c.client.Projects.UploadFile(
 		projectID,
@@ -253,24 +276,10 @@ func (c *gitlabClient) Upload(
 	gitlabBaseURL := ctx.Config.GitLabURLs.Download
 	// projectFile.URL from upload: /uploads/<hash>/filename.txt
 	linkURL := gitlabBaseURL + "/" + projectID + projectFile.URL
-	name := artifact.Name
-	releaseLink, _, err := c.client.ReleaseLinks.CreateReleaseLink(
-		projectID,
-		releaseID,
-		&gitlab.CreateReleaseLinkOptions{
-			Name: &name,
-			URL:  &linkURL,
-		})
-
-	if err != nil {
+	if err := c.createReleaseLink(projectID, releaseID, artifact.Name, linkURL); err != nil {
 		return err
 	}
 
-	log.WithFields(log.Fields{
-		"id":  releaseLink.ID,
-		"url": releaseLink.URL,
-	}).Debug("created release link")
-
 	fileUploadHash, err := extractProjectFileHashFrom(projectFile.URL)
 	if err != nil {
 		return err
@@ -287,6 +296,128 @@ func (c *gitlabClient) Upload(
 	return err
 }
 
+// findReleaseLink returns the link of the release with the given name, if any
+func (c *gitlabClient) findReleaseLink(projectID, releaseID, name string) (*gitlab.ReleaseLink, error) {
+	var opts = &gitlab.ListReleaseLinksOptions{PerPage: 100}
+	for {
+		links, res, err := c.client.ReleaseLinks.ListReleaseLinks(projectID, releaseID, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			if link.Name == name {
+				return link, nil
+			}
+		}
+		if res.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+// keepUploadHash sets the upload hash of an artifact kept in the release from
+// the url of its link, as following publish pipes need it
+func keepUploadHash(artifact *artifact.Artifact, linkURL string) error {
+	var idx = strings.Index(linkURL, "/uploads/")
+	if idx < 0 {
+		// uploaded to the package registry
+		return nil
+	}
+	fileUploadHash, err := extractProjectFileHashFrom(linkURL[idx:])
+	if err != nil {
+		return err
+	}
+	if artifact.Extra == nil {
+		artifact.Extra = make(map[string]interface{})
+	}
+	artifact.Extra["ArtifactUploadHash"] = fileUploadHash
+	return nil
+}
+
+// uploadToPackageRegistry uploads the file to the generic package of the
+// project version, and links it to the release
+func (c *gitlabClient) uploadToPackageRegistry(
+	ctx *context.Context,
+	releaseID string,
+	artifact *artifact.Artifact,
+	file *os.File,
+) error {
+	projectID := ctx.Config.Release.GitLab.Owner + "/" + ctx.Config.Release.GitLab.Name
+	path := packageRegistryPath(ctx) + "/" + url.PathEscape(artifact.Name)
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	req, err := c.client.NewRequest(http.MethodPut, path, nil, nil)
+	if err != nil {
+		return err
+	}
+	req.Body = file
+	req.GetBody = nil
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	log.WithField("file", file.Name()).Debug("uploading file to the package registry")
+	if _, err := c.client.Do(req, nil); err != nil {
+		return err
+	}
+	return c.createReleaseLink(projectID, releaseID, artifact.Name, c.client.BaseURL().String()+path)
+}
+
+// packageRegistryPath returns the API path of the generic package of the
+// project version
+func packageRegistryPath(ctx *context.Context) string {
+	return fmt.Sprintf(
+		"projects/%s/packages/generic/%s/%s",
+		url.PathEscape(ctx.Config.Release.GitLab.Owner+"/"+ctx.Config.Release.GitLab.Name),
+		url.PathEscape(ctx.Config.ProjectName),
+		url.PathEscape(ctx.Version),
+	)
+}
+
+// GitLabURLTemplate returns the default template of the download URL of the
+// release assets on GitLab, which are either in the project uploads or in
+// the package registry
+func GitLabURLTemplate(ctx *context.Context) string {
+	if !ctx.Config.GitLabURLs.UsePackageRegistry {
+		return fmt.Sprintf(
+			"%s/%s/%s/uploads/{{ .ArtifactUploadHash }}/{{ .ArtifactName }}",
+			ctx.Config.GitLabURLs.Download,
+			ctx.Config.Release.GitLab.Owner,
+			ctx.Config.Release.GitLab.Name,
+		)
+	}
+	var api = ctx.Config.GitLabURLs.API
+	if api == "" {
+		api = ctx.Config.GitLabURLs.Download + "/api/v4/"
+	}
+	if !strings.HasSuffix(api, "/") {
+		api += "/"
+	}
+	return api + packageRegistryPath(ctx) + "/{{ .ArtifactName }}"
+}
+
+func (c *gitlabClient) createReleaseLink(projectID, releaseID, name, linkURL string) error {
+	releaseLink, _, err := c.client.ReleaseLinks.CreateReleaseLink(
+		projectID,
+		releaseID,
+		&gitlab.CreateReleaseLinkOptions{
+			Name: &name,
+			URL:  &linkURL,
+		})
+
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"id":  releaseLink.ID,
+		"url": releaseLink.URL,
+	}).Debug("created release link")
+	return nil
+}
+
 // extractProjectFileHashFrom extracts the hash from the
 // relative project file url of the format '/uploads/<hash>/filename.ext'
 func extractProjectFileHashFrom(projectFileURL string) (string, error) {
@@ -304,3 +435,82 @@ func extractProjectFileHashFrom(projectFileURL string) (string, error) {
 	}).Debug("extracted file hash")
 	return fileHash, nil
 }
+
+// CloseMilestone closes the active milestone with the given title
+func (c *gitlabClient) CloseMilestone(ctx *context.Context, repo config.Repo, title string) error {
+	projectID := repo.Owner + "/" + repo.Name
+	milestones, _, err := c.client.Milestones.ListMilestones(projectID, &gitlab.ListMilestonesOptions{
+		Title: &title,
+		State: gitlab.String("active"),
+	})
+	if err != nil {
+		return err
+	}
+	if len(milestones) == 0 {
+		return fmt.Errorf("no active milestone %q found in %s", title, repo)
+	}
+	_, _, err = c.client.Milestones.UpdateMilestone(projectID, milestones[0].ID, &gitlab.UpdateMilestoneOptions{
+		StateEvent: gitlab.String("close"),
+	})
+	return err
+}
+
+// DeleteRelease deletes the release of the given tag and the tag itself
+func (c *gitlabClient) DeleteRelease(ctx *context.Context, tag string) error {
+	projectID := ctx.Config.Release.GitLab.Owner + "/" + ctx.Config.Release.GitLab.Name
+	_, res, err := c.client.Releases.DeleteRelease(projectID, tag)
+	if err != nil && (res == nil || res.StatusCode != 404) {
+		return err
+	}
+	log.WithField("tag", tag).Info("deleting tag")
+	_, err = c.client.Tags.DeleteTag(projectID, tag)
+	return err
+}
+
+// RevertFile restores the given file to the content it had before its last
+// commit, deleting it if that commit created it
+func (c *gitlabClient) RevertFile(
+	ctx *context.Context,
+	commitAuthor config.CommitAuthor,
+	repo config.Repo,
+	path,
+	message string,
+) error {
+	// we assume having the formula in the master branch only
+	branch := "master"
+	projectID := repo.Owner + "/" + repo.Name
+	commits, _, err := c.client.Commits.ListCommits(projectID, &gitlab.ListCommitsOptions{
+		RefName: &branch,
+		Path:    &path,
+		ListOptions: gitlab.ListOptions{
+			PerPage: 2,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if len(commits) < 2 {
+		_, err = c.client.RepositoryFiles.DeleteFile(projectID, path, &gitlab.DeleteFileOptions{
+			Branch:        &branch,
+			AuthorName:    &commitAuthor.Name,
+			AuthorEmail:   &commitAuthor.Email,
+			CommitMessage: &message,
+		})
+		return err
+	}
+	content, _, err := c.client.RepositoryFiles.GetRawFile(projectID, path, &gitlab.GetRawFileOptions{
+		Ref: &commits[1].ID,
+	})
+	if err != nil {
+		return err
+	}
+	castedContent := string(content)
+	_, _, err = c.client.RepositoryFiles.UpdateFile(projectID, path, &gitlab.UpdateFileOptions{
+		Branch:        &branch,
+		AuthorName:    &commitAuthor.Name,
+		AuthorEmail:   &commitAuthor.Email,
+		Content:       &castedContent,
+		CommitMessage: &message,
+	})
+	return err
+}
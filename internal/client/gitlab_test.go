@@ -2,8 +2,13 @@ package client
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,3 +36,64 @@ func TestFailToExtractHashFromProjectFileURL(t *testing.T) {
 		t.Errorf("expected an error but got none for path-too-small in url")
 	}
 }
+
+func TestGitLabURLTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "proj",
+		GitLabURLs: config.GitLabURLs{
+			Download: "https://gitlab.com",
+		},
+		Release: config.Release{
+			GitLab: config.Repo{Owner: "group/sub", Name: "repo"},
+		},
+	})
+	ctx.Version = "1.0.0"
+	assert.Equal(t, "https://gitlab.com/group/sub/repo/uploads/{{ .ArtifactUploadHash }}/{{ .ArtifactName }}", GitLabURLTemplate(ctx))
+
+	ctx.Config.GitLabURLs.UsePackageRegistry = true
+	assert.Equal(t, "https://gitlab.com/api/v4/projects/group%2Fsub%2Frepo/packages/generic/proj/1.0.0/{{ .ArtifactName }}", GitLabURLTemplate(ctx))
+
+	ctx.Config.GitLabURLs.API = "https://gitlab.company.com/api/v4"
+	assert.Equal(t, "https://gitlab.company.com/api/v4/projects/group%2Fsub%2Frepo/packages/generic/proj/1.0.0/{{ .ArtifactName }}", GitLabURLTemplate(ctx))
+}
+
+func TestKeepUploadHash(t *testing.T) {
+	var a = &artifact.Artifact{Name: "foo.tar.gz"}
+	assert.NoError(t, keepUploadHash(a, "https://gitlab.com/owner/repo/uploads/22e8b1508b0f28433b94754a5ea2f4aa/foo.tar.gz"))
+	assert.Equal(t, "22e8b1508b0f28433b94754a5ea2f4aa", a.Extra["ArtifactUploadHash"])
+
+	a = &artifact.Artifact{Name: "foo.tar.gz"}
+	assert.NoError(t, keepUploadHash(a, "https://gitlab.com/api/v4/projects/owner%2Frepo/packages/generic/foo/1.0.0/foo.tar.gz"))
+	assert.Nil(t, a.Extra)
+}
+
+func TestGitLabFindReleaseLinkPaginates(t *testing.T) {
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var page = r.URL.Query().Get("page")
+		pages = append(pages, page)
+		if page == "" {
+			w.Header().Set("X-Next-Page", "2")
+			fmt.Fprint(w, `[{"id": 1, "name": "checksums.txt"}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"id": 2, "name": "foo.tar.gz"}]`)
+	}))
+	defer srv.Close()
+	var ctx = context.New(config.Project{
+		GitLabURLs: config.GitLabURLs{API: srv.URL + "/api/v4"},
+	})
+	client, err := NewGitLab(ctx)
+	assert.NoError(t, err)
+
+	link, err := client.(*gitlabClient).findReleaseLink("owner/repo", "v1.0.0", "foo.tar.gz")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, link.ID)
+	assert.Equal(t, []string{"", "2"}, pages)
+
+	pages = nil
+	link, err = client.(*gitlabClient).findReleaseLink("owner/repo", "v1.0.0", "bar.tar.gz")
+	assert.NoError(t, err)
+	assert.Nil(t, link)
+	assert.Equal(t, []string{"", "2"}, pages)
+}
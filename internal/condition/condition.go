@@ -0,0 +1,88 @@
+// Package condition evaluates the `if` conditions used to skip parts of the
+// release depending on the git state, like the branch or the current tag.
+package condition
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	zglob "github.com/mattn/go-zglob"
+)
+
+// Check applies the given condition template, which must render to either
+// true or false. Empty conditions are always true.
+func Check(ctx *context.Context, condition string) (bool, error) {
+	if condition == "" {
+		return true, nil
+	}
+	var changes = &changes{ctx: ctx}
+	result, err := tmpl.New(ctx).
+		WithFuncs(template.FuncMap{
+			"changed": changes.match,
+			"match":   zglob.Match,
+		}).
+		Apply(condition)
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate condition %q: %s", condition, err.Error())
+	}
+	ok, err := strconv.ParseBool(strings.TrimSpace(result))
+	if err != nil {
+		return false, fmt.Errorf("condition %q should be true or false, got %q", condition, result)
+	}
+	return ok, nil
+}
+
+// changes lazily lists the files changed since the previous tag.
+type changes struct {
+	ctx   *context.Context
+	files []string
+	all   bool
+	done  bool
+}
+
+func (c *changes) match(pattern string) (bool, error) {
+	if !c.done {
+		if err := c.load(); err != nil {
+			return false, err
+		}
+		c.done = true
+	}
+	if c.all {
+		return true, nil
+	}
+	for _, file := range c.files {
+		ok, err := zglob.Match(pattern, file)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *changes) load() error {
+	var current = c.ctx.Git.CurrentTag
+	previous, err := git.Clean(git.Run("describe", "--tags", "--abbrev=0", fmt.Sprintf("tags/%s^", current)))
+	if err != nil {
+		// first release: everything changed
+		c.all = true
+		return nil
+	}
+	out, err := git.Run("diff", "--name-only", previous, "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to list the files changed since %s: %s", previous, err.Error())
+	}
+	for _, file := range strings.Split(out, "\n") {
+		if file != "" {
+			c.files = append(c.files, file)
+		}
+	}
+	return nil
+}
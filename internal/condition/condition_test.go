@@ -0,0 +1,84 @@
+package condition
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.Git.Branch = "master"
+	ctx.Git.CurrentTag = "v1.2.3-rc1"
+	ctx.Semver.Prerelease = "rc1"
+	for condition, expected := range map[string]bool{
+		"":                              true,
+		"true":                          true,
+		" false\n":                      false,
+		`{{ eq .Branch "master" }}`:     true,
+		`{{ eq .Branch "develop" }}`:    false,
+		`{{ not .Prerelease }}`:         false,
+		`{{ not .IsSnapshot }}`:         true,
+		`{{ match "v1.*" .Tag }}`:       true,
+		`{{ match "v*-hotfix*" .Tag }}`: false,
+		`{{ and .Prerelease (eq .Branch "master") }}`: true,
+	} {
+		ok, err := Check(ctx, condition)
+		require.NoError(t, err, condition)
+		require.Equal(t, expected, ok, condition)
+	}
+}
+
+func TestCheckInvalid(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	_, err := Check(ctx, "{{ .Nope }}")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `failed to evaluate condition "{{ .Nope }}"`)
+
+	_, err = Check(ctx, "yes please")
+	require.EqualError(t, err, `condition "yes please" should be true or false, got "yes please"`)
+}
+
+func TestCheckChanged(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	write(t, folder, "README.md")
+	testlib.GitAdd(t)
+	testlib.GitCommit(t, "first")
+	testlib.GitTag(t, "v1.0.0")
+
+	var ctx = context.New(config.Project{})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ok, err := Check(ctx, `{{ changed "docs/**" }}`)
+	require.NoError(t, err)
+	require.True(t, ok, "everything changed on the first release")
+
+	write(t, folder, "docs/index.md")
+	testlib.GitAdd(t)
+	testlib.GitCommit(t, "docs")
+	testlib.GitTag(t, "v1.1.0")
+
+	ctx.Git.CurrentTag = "v1.1.0"
+	for condition, expected := range map[string]bool{
+		`{{ changed "docs/**" }}`:                           true,
+		`{{ changed "cmd/**" }}`:                            false,
+		`{{ or (changed "cmd/**") (changed "README.md") }}`: false,
+	} {
+		ok, err := Check(ctx, condition)
+		require.NoError(t, err, condition)
+		require.Equal(t, expected, ok, condition)
+	}
+}
+
+func write(t *testing.T, folder, name string) {
+	var path = filepath.Join(folder, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(name), 0644))
+}
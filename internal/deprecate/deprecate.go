@@ -6,18 +6,23 @@ import (
 	"strings"
 
 	"github.com/apex/log"
-	"github.com/apex/log/handlers/cli"
 	"github.com/fatih/color"
+	"github.com/goreleaser/goreleaser/internal/middleware"
+	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
 const baseURL = "https://goreleaser.com/deprecations#"
 
-// Notice warns the user about the deprecation of the given property
-func Notice(property string) {
-	cli.Default.Padding += 3
-	defer func() {
-		cli.Default.Padding -= 3
-	}()
+// Notice warns the user about the deprecation of the given property, and
+// marks the context as using deprecated options
+func Notice(ctx *context.Context, property string) {
+	ctx.Deprecated = true
+	middleware.SetPadding(func(padding int) int {
+		return padding + 3
+	})
+	defer middleware.SetPadding(func(padding int) int {
+		return padding - 3
+	})
 	// replaces . and _ with -
 	url := baseURL + strings.NewReplacer(
 		".", "-",
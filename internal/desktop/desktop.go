@@ -0,0 +1,148 @@
+// Package desktop validates the freedesktop.org desktop entries and icons
+// installed by multiple pipes.
+package desktop
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/pkg/errors"
+)
+
+var iconSizeRe = regexp.MustCompile(`^[0-9]+x[0-9]+(@[0-9]+)?$`)
+
+// Entry is a validated desktop entry and its icons
+type Entry struct {
+	// File is the path of the desktop file
+	File string
+	// Keys of the [Desktop Entry] group
+	Keys map[string]string
+	// Icons by size, as in the config
+	Icons map[string]string
+}
+
+// Load reads and validates the desktop entry and the icons of the given
+// config.
+func Load(desktop config.Desktop) (Entry, error) {
+	if desktop.File == "" {
+		return Entry{}, fmt.Errorf("desktop icons require a desktop file")
+	}
+	keys, err := readDesktopEntry(desktop.File)
+	if err != nil {
+		return Entry{}, err
+	}
+	if err := validateDesktopEntry(keys, len(desktop.Icons) > 0); err != nil {
+		return Entry{}, errors.Wrapf(err, "invalid desktop file %s", desktop.File)
+	}
+	for size, src := range desktop.Icons {
+		if size == "scalable" {
+			if filepath.Ext(src) != ".svg" {
+				return Entry{}, fmt.Errorf("scalable icon %s must be a .svg file", src)
+			}
+		} else if !iconSizeRe.MatchString(size) {
+			return Entry{}, fmt.Errorf("invalid icon size %q, must be like 48x48 or scalable", size)
+		}
+		if !isIconFile(src) {
+			return Entry{}, fmt.Errorf("icon %s must be a .png, .svg or .xpm file", src)
+		}
+	}
+	return Entry{
+		File:  desktop.File,
+		Keys:  keys,
+		Icons: desktop.Icons,
+	}, nil
+}
+
+// Files returns the files, source to destination, needed to install the
+// desktop entry and its icons in the share folder of the given prefix.
+func (e Entry) Files(prefix string) map[string]string {
+	var files = map[string]string{
+		e.File: path.Join(prefix, "share", "applications", filepath.Base(e.File)),
+	}
+	for size, src := range e.Icons {
+		files[src] = path.Join(prefix, "share", "icons", "hicolor", size, "apps", e.Keys["Icon"]+filepath.Ext(src))
+	}
+	return files
+}
+
+// readDesktopEntry reads the keys of the [Desktop Entry] group of a desktop
+// file.
+func readDesktopEntry(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read desktop file")
+	}
+	defer f.Close() // nolint: errcheck
+	var entry = map[string]string{}
+	var group string
+	var scanner = bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line = strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			if group == "" && line != "[Desktop Entry]" {
+				return nil, fmt.Errorf("invalid desktop file %s: first group must be [Desktop Entry]", path)
+			}
+			group = line
+			continue
+		}
+		if group == "" {
+			return nil, fmt.Errorf("invalid desktop file %s: %q is outside of a group", path, line)
+		}
+		var parts = strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid desktop file %s: %q is not a key=value pair", path, line)
+		}
+		if group == "[Desktop Entry]" {
+			entry[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read desktop file")
+	}
+	if group == "" {
+		return nil, fmt.Errorf("invalid desktop file %s: missing [Desktop Entry] group", path)
+	}
+	return entry, nil
+}
+
+func validateDesktopEntry(entry map[string]string, hasIcons bool) error {
+	var required = []string{"Type", "Name"}
+	switch entry["Type"] {
+	case "Application":
+		required = append(required, "Exec")
+	case "Link":
+		required = append(required, "URL")
+	case "Directory", "":
+	default:
+		return fmt.Errorf("invalid Type %q", entry["Type"])
+	}
+	if hasIcons {
+		required = append(required, "Icon")
+	}
+	for _, key := range required {
+		if entry[key] == "" {
+			return fmt.Errorf("missing %s key", key)
+		}
+	}
+	if hasIcons && (strings.Contains(entry["Icon"], "/") || isIconFile(entry["Icon"])) {
+		return fmt.Errorf("the Icon key must be a name without path or extension to use the packaged icons, got %q", entry["Icon"])
+	}
+	return nil
+}
+
+func isIconFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".png", ".svg", ".xpm":
+		return true
+	}
+	return false
+}
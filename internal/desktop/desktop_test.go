@@ -0,0 +1,115 @@
+package desktop
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+const validDesktopFile = `# generated
+[Desktop Entry]
+Type=Application
+Name=My App
+Exec=myapp %U
+Icon=org.example.MyApp
+
+[Desktop Action New]
+Name=New Window
+Exec=myapp --new-window
+`
+
+func writeDesktopFile(t *testing.T, content string) string {
+	folder, err := ioutil.TempDir("", "desktop")
+	require.NoError(t, err)
+	var path = filepath.Join(folder, "org.example.MyApp.desktop")
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	var path = writeDesktopFile(t, validDesktopFile)
+	entry, err := Load(config.Desktop{
+		File: path,
+		Icons: map[string]string{
+			"48x48":     "icons/48.png",
+			"256x256@2": "icons/256.png",
+			"scalable":  "icons/app.svg",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "My App", entry.Keys["Name"])
+	require.Equal(t, map[string]string{
+		path:            "/usr/share/applications/org.example.MyApp.desktop",
+		"icons/48.png":  "/usr/share/icons/hicolor/48x48/apps/org.example.MyApp.png",
+		"icons/256.png": "/usr/share/icons/hicolor/256x256@2/apps/org.example.MyApp.png",
+		"icons/app.svg": "/usr/share/icons/hicolor/scalable/apps/org.example.MyApp.svg",
+	}, entry.Files("/usr"))
+	require.Equal(t, map[string]string{
+		path:            "share/applications/org.example.MyApp.desktop",
+		"icons/48.png":  "share/icons/hicolor/48x48/apps/org.example.MyApp.png",
+		"icons/256.png": "share/icons/hicolor/256x256@2/apps/org.example.MyApp.png",
+		"icons/app.svg": "share/icons/hicolor/scalable/apps/org.example.MyApp.svg",
+	}, entry.Files(""))
+}
+
+func TestLoadIconsWithoutFile(t *testing.T) {
+	_, err := Load(config.Desktop{
+		Icons: map[string]string{"48x48": "icon.png"},
+	})
+	require.EqualError(t, err, "desktop icons require a desktop file")
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, err := Load(config.Desktop{
+		File: "testdata/nope.desktop",
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read desktop file")
+}
+
+func TestLoadInvalidIcons(t *testing.T) {
+	var path = writeDesktopFile(t, validDesktopFile)
+	for icons, msg := range map[string]string{
+		"big":      `invalid icon size "big", must be like 48x48 or scalable`,
+		"scalable": "scalable icon icon.png must be a .svg file",
+		"48x48":    "icon icon.ico must be a .png, .svg or .xpm file",
+	} {
+		var src = "icon.png"
+		if icons == "48x48" {
+			src = "icon.ico"
+		}
+		_, err := Load(config.Desktop{
+			File:  path,
+			Icons: map[string]string{icons: src},
+		})
+		require.EqualError(t, err, msg)
+	}
+}
+
+func TestLoadInvalidEntries(t *testing.T) {
+	for content, msg := range map[string]string{
+		"":                                      "missing [Desktop Entry] group",
+		"Name=foo\n":                            `"Name=foo" is outside of a group`,
+		"[Other]\nName=foo\n":                   "first group must be [Desktop Entry]",
+		"[Desktop Entry]\nName\n":               `"Name" is not a key=value pair`,
+		"[Desktop Entry]\nName=foo\n":           "missing Type key",
+		"[Desktop Entry]\nType=Foo\nName=foo\n": `invalid Type "Foo"`,
+		"[Desktop Entry]\nType=Application\n":   "missing Name key",
+		"[Desktop Entry]\nType=Application\nName=foo\n":                          "missing Exec key",
+		"[Desktop Entry]\nType=Link\nName=foo\n":                                 "missing URL key",
+		"[Desktop Entry]\nType=Application\nName=foo\nExec=foo\n":                "missing Icon key",
+		"[Desktop Entry]\nType=Application\nName=foo\nExec=foo\nIcon=/foo.png\n": `the Icon key must be a name without path or extension to use the packaged icons, got "/foo.png"`,
+		"[Desktop Entry]\nType=Application\nName=foo\nExec=foo\nIcon=foo.svg\n":  `the Icon key must be a name without path or extension to use the packaged icons, got "foo.svg"`,
+	} {
+		var path = writeDesktopFile(t, content)
+		_, err := Load(config.Desktop{
+			File:  path,
+			Icons: map[string]string{"48x48": "icon.png"},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), msg)
+	}
+}
@@ -0,0 +1,48 @@
+package git
+
+import (
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// RunWithAuth runs a git command that talks to a remote, like a push.
+//
+// By default git uses its own configuration to authenticate, so credential
+// helpers, .netrc and ssh-agent all work as they do outside of goreleaser.
+// The given auth can override the credential helper and the ssh command.
+// Terminal prompts are always disabled, so missing credentials make the
+// command fail instead of hanging.
+func RunWithAuth(auth config.GitAuth, args ...string) (string, error) {
+	return run(authEnv(auth), append(authArgs(auth), args...)...)
+}
+
+// MergeAuth returns the override auth, falling back to the defaults for
+// unset fields.
+func MergeAuth(defaults, override config.GitAuth) config.GitAuth {
+	if override.CredentialHelper == "" {
+		override.CredentialHelper = defaults.CredentialHelper
+	}
+	if override.SSHCommand == "" {
+		override.SSHCommand = defaults.SSHCommand
+	}
+	return override
+}
+
+func authArgs(auth config.GitAuth) []string {
+	if auth.CredentialHelper == "" {
+		return nil
+	}
+	// the empty helper resets the helpers inherited from the git config,
+	// so only the given one is used
+	return []string{
+		"-c", "credential.helper=",
+		"-c", "credential.helper=" + auth.CredentialHelper,
+	}
+}
+
+func authEnv(auth config.GitAuth) []string {
+	var env = []string{"GIT_TERMINAL_PROMPT=0"}
+	if auth.SSHCommand != "" {
+		env = append(env, "GIT_SSH_COMMAND="+auth.SSHCommand)
+	}
+	return env
+}
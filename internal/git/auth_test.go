@@ -0,0 +1,36 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunWithAuth(t *testing.T) {
+	out, err := RunWithAuth(
+		config.GitAuth{CredentialHelper: "store --file=/tmp/creds"},
+		"config", "--get-all", "credential.helper",
+	)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "\nstore --file=/tmp/creds\n")
+}
+
+func TestAuthEnv(t *testing.T) {
+	assert.Equal(t, []string{"GIT_TERMINAL_PROMPT=0"}, authEnv(config.GitAuth{}))
+	assert.Equal(t, []string{
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_SSH_COMMAND=ssh -i deploy_key",
+	}, authEnv(config.GitAuth{SSHCommand: "ssh -i deploy_key"}))
+}
+
+func TestMergeAuth(t *testing.T) {
+	assert.Equal(t, config.GitAuth{
+		CredentialHelper: "cache",
+		SSHCommand:       "ssh -i deploy_key",
+	}, MergeAuth(
+		config.GitAuth{CredentialHelper: "store", SSHCommand: "ssh -i deploy_key"},
+		config.GitAuth{CredentialHelper: "cache"},
+	))
+	assert.Equal(t, config.GitAuth{}, MergeAuth(config.GitAuth{}, config.GitAuth{}))
+}
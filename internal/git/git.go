@@ -3,6 +3,7 @@ package git
 
 import (
 	"errors"
+	"os"
 	"os/exec"
 	"strings"
 
@@ -17,6 +18,10 @@ func IsRepo() bool {
 
 // Run runs a git command and returns its output or errors
 func Run(args ...string) (string, error) {
+	return run(nil, args...)
+}
+
+func run(env []string, args ...string) (string, error) {
 	// TODO: use exex.CommandContext here and refactor.
 	var extraArgs = []string{
 		"-c", "log.showSignature=false",
@@ -24,6 +29,9 @@ func Run(args ...string) (string, error) {
 	args = append(extraArgs, args...)
 	/* #nosec */
 	var cmd = exec.Command("git", args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	log.WithField("args", args).Debug("running git")
 	bts, err := cmd.CombinedOutput()
 	log.WithField("output", string(bts)).
@@ -19,6 +19,7 @@ import (
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
@@ -80,6 +81,9 @@ func defaults(put *config.Put) {
 	if put.Mode == "" {
 		put.Mode = ModeArchive
 	}
+	if put.Method == "" {
+		put.Method = h.MethodPut
+	}
 }
 
 // CheckConfig validates a Put configuration returning a descriptive error when appropriate
@@ -96,8 +100,14 @@ func CheckConfig(ctx *context.Context, put *config.Put, kind string) error {
 		return misconfigured(kind, put, "mode must be 'binary' or 'archive'")
 	}
 
+	if put.Method != "" && put.Method != h.MethodPut && put.Method != h.MethodPost {
+		return misconfigured(kind, put, "method must be 'PUT' or 'POST'")
+	}
+
+	// the secret is not needed when authenticating with a custom header,
+	// like a token
 	envName := fmt.Sprintf("%s_%s_SECRET", strings.ToUpper(kind), strings.ToUpper(put.Name))
-	if _, ok := ctx.Env[envName]; !ok {
+	if _, ok := ctx.Env[envName]; !ok && !hasAuthorizationHeader(put) {
 		return misconfigured(kind, put, fmt.Sprintf("missing %s environment variable", envName))
 	}
 
@@ -108,8 +118,17 @@ func CheckConfig(ctx *context.Context, put *config.Put, kind string) error {
 	return nil
 }
 
+func hasAuthorizationHeader(put *config.Put) bool {
+	for header := range put.CustomHeaders {
+		if strings.EqualFold(header, "Authorization") {
+			return true
+		}
+	}
+	return false
+}
+
 func misconfigured(kind string, upload *config.Put, reason string) error {
-	return pipe.Skip(fmt.Sprintf("%s section '%s' is not configured properly (%s)", kind, upload.Name, reason))
+	return pipe.Misconfigured(fmt.Sprintf("%s section '%s' is not configured properly (%s)", kind, upload.Name, reason))
 }
 
 // ResponseChecker is a function capable of validating an http server response.
@@ -130,17 +149,26 @@ func Upload(ctx *context.Context, puts []config.Put, kind string, check Response
 			filters = append(filters, artifact.ByType(artifact.Checksum))
 		}
 		if put.Signature {
-			filters = append(filters, artifact.ByType(artifact.Signature))
+			filters = append(filters,
+				artifact.ByType(artifact.Signature),
+				artifact.ByType(artifact.Certificate),
+			)
 		}
 		// We support two different modes
 		//	- "archive": Upload all artifacts
 		//	- "binary": Upload only the raw binaries
 		switch v := strings.ToLower(put.Mode); v {
 		case ModeArchive:
-			filters = append(filters,
-				artifact.ByType(artifact.UploadableArchive),
-				artifact.ByType(artifact.LinuxPackage),
-			)
+			// binaries, checksums and signatures have their own options
+			filters = append(filters, artifact.And(
+				artifact.ByUploadable(),
+				artifact.Not(artifact.Or(
+					artifact.ByType(artifact.UploadableBinary),
+					artifact.ByType(artifact.Checksum),
+					artifact.ByType(artifact.Signature),
+					artifact.ByType(artifact.Certificate),
+				)),
+			))
 		case ModeBinary:
 			filters = append(filters, artifact.ByType(artifact.UploadableBinary))
 		default:
@@ -216,6 +244,22 @@ func uploadAsset(ctx *context.Context, put *config.Put, artifact *artifact.Artif
 		}
 		headers[put.ChecksumHeader] = sum
 	}
+	for header, algorithm := range put.ChecksumHeaders {
+		sum, err := artifact.Checksum(algorithm)
+		if err != nil {
+			return err
+		}
+		headers[header] = sum
+	}
+	for header, value := range put.CustomHeaders {
+		value, err := tmpl.New(ctx).
+			WithArtifact(artifact, ctx.Config.Archive.Replacements).
+			Apply(value)
+		if err != nil {
+			return errors.Wrapf(err, "%s: failed to template the %s header", kind, header)
+		}
+		headers[header] = value
+	}
 
 	res, err := uploadAssetToServer(ctx, put, targetURL, username, secret, headers, asset, check)
 	if err != nil {
@@ -240,7 +284,7 @@ func uploadAsset(ctx *context.Context, put *config.Put, artifact *artifact.Artif
 
 // uploadAssetToServer uploads the asset file to target
 func uploadAssetToServer(ctx *context.Context, put *config.Put, target, username, secret string, headers map[string]string, a *asset, check ResponseChecker) (*h.Response, error) {
-	req, err := newUploadRequest(target, username, secret, headers, a)
+	req, err := newUploadRequest(put.Method, target, username, secret, headers, a)
 	if err != nil {
 		return nil, err
 	}
@@ -249,13 +293,18 @@ func uploadAssetToServer(ctx *context.Context, put *config.Put, target, username
 }
 
 // newUploadRequest creates a new h.Request for uploading
-func newUploadRequest(target, username, secret string, headers map[string]string, a *asset) (*h.Request, error) {
-	req, err := h.NewRequest(h.MethodPut, target, a.ReadCloser)
+func newUploadRequest(method, target, username, secret string, headers map[string]string, a *asset) (*h.Request, error) {
+	if method == "" {
+		method = h.MethodPut
+	}
+	req, err := h.NewRequest(method, target, a.ReadCloser)
 	if err != nil {
 		return nil, err
 	}
 	req.ContentLength = a.Size
-	req.SetBasicAuth(username, secret)
+	if username != "" || secret != "" {
+		req.SetBasicAuth(username, secret)
+	}
 
 	for k, v := range headers {
 		req.Header.Add(k, v)
@@ -264,7 +313,9 @@ func newUploadRequest(target, username, secret string, headers map[string]string
 	return req, err
 }
 
-func getHTTPClient(put *config.Put) (*h.Client, error) {
+// Client returns the http client of the given put, trusting its
+// certificates, if any
+func Client(put *config.Put) (*h.Client, error) {
 	if put.TrustedCerts == "" {
 		return h.DefaultClient, nil
 	}
@@ -289,7 +340,7 @@ func getHTTPClient(put *config.Put) (*h.Client, error) {
 
 // executeHTTPRequest processes the http call with respect of context ctx
 func executeHTTPRequest(ctx *context.Context, put *config.Put, req *h.Request, check ResponseChecker) (*h.Response, error) {
-	client, err := getHTTPClient(put)
+	client, err := Client(put)
 	if err != nil {
 		return nil, err
 	}
@@ -342,10 +393,14 @@ func resolveTargetTemplate(ctx *context.Context, put *config.Put, artifact *arti
 	}
 
 	if put.Mode == ModeBinary {
-		// TODO: multiple archives here
-		data.Os = replace(ctx.Config.Archive.Replacements, artifact.Goos)
-		data.Arch = replace(ctx.Config.Archive.Replacements, artifact.Goarch)
-		data.Arm = replace(ctx.Config.Archive.Replacements, artifact.Goarm)
+		// use the replacements of the archive the binary comes from
+		var replacements = ctx.Config.Archive.Replacements
+		if r, ok := artifact.ExtraOr("Replacements", nil).(map[string]string); ok {
+			replacements = r
+		}
+		data.Os = replace(replacements, artifact.Goos)
+		data.Arch = replace(replacements, artifact.Goarch)
+		data.Arm = replace(replacements, artifact.Goarm)
 	}
 
 	var out bytes.Buffer
@@ -421,6 +421,19 @@ func TestUpload(t *testing.T) {
 			},
 			checks(check{"/blah/2.1.0/a.ubi", "u2", "x", content, map[string]string{"-x-sha256": "5e2bf57d3f40c4b6df69daf1936cb766f832374b4fc0259a7cbff06e2f70f269"}}),
 		},
+		{"checksumheaders", true, true, false, false,
+			func(s *httptest.Server) (*context.Context, config.Put) {
+				return ctx, config.Put{
+					Mode:            ModeBinary,
+					Name:            "a",
+					Target:          s.URL + "/{{.ProjectName}}/{{.Version}}/",
+					Username:        "u2",
+					ChecksumHeaders: map[string]string{"-x-sha1": "sha1", "-x-md5": "md5"},
+					TrustedCerts:    cert(s),
+				}
+			},
+			checks(check{"/blah/2.1.0/a.ubi", "u2", "x", content, map[string]string{"-x-sha1": "bfb7759a67daeb65410490b4d98bb9da7d1ea2ce", "-x-md5": "80a751fde577028640c419000e33eba6"}}),
+		},
 	}
 
 	uploadAndCheck := func(t *testing.T, setup func(*httptest.Server) (*context.Context, config.Put), wantErrPlain, wantErrTLS bool, check func(r []*h.Request) error, srv *httptest.Server) {
@@ -468,3 +481,30 @@ func cert(srv *httptest.Server) string {
 	}
 	return string(pem.EncodeToMemory(block))
 }
+
+func TestResolveTargetTemplateReplacements(t *testing.T) {
+	var ctx = context.New(config.Project{ProjectName: "foo"})
+	ctx.Version = "1.2.3"
+	var put = &config.Put{
+		Mode:   ModeBinary,
+		Target: "https://example.com/{{ .ProjectName }}/{{ .Version }}/{{ .Os }}-{{ .Arch }}{{ .Arm }}",
+	}
+	var a = &artifact.Artifact{
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Extra: map[string]interface{}{
+			"Replacements": map[string]string{
+				"darwin": "macOS",
+				"amd64":  "x86_64",
+			},
+		},
+	}
+	target, err := resolveTargetTemplate(ctx, put, a)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/foo/1.2.3/macOS-x86_64", target)
+
+	a.Extra = nil
+	target, err = resolveTargetTemplate(ctx, put, a)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/foo/1.2.3/darwin-amd64", target)
+}
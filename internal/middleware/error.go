@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"fmt"
+
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -14,6 +16,9 @@ func ErrHandler(action Action) Action {
 		if err == nil {
 			return nil
 		}
+		if pipe.IsMisconfigured(err) && ctx.Config.Strict {
+			return fmt.Errorf("strict mode: %s", err.Error())
+		}
 		if pipe.IsSkip(err) {
 			log.WithError(err).Warn("pipe skipped")
 			return nil
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,6 +19,20 @@ func TestError(t *testing.T) {
 		require.NoError(t, ErrHandler(mockAction(pipe.ErrSkipValidateEnabled))(ctx))
 	})
 
+	t.Run("pipe misconfigured", func(t *testing.T) {
+		require.NoError(t, ErrHandler(mockAction(pipe.Misconfigured("missing token")))(ctx))
+	})
+
+	t.Run("pipe misconfigured in strict mode", func(t *testing.T) {
+		var ctx = context.New(config.Project{Strict: true})
+		require.EqualError(
+			t,
+			ErrHandler(mockAction(pipe.Misconfigured("missing token")))(ctx),
+			"strict mode: missing token",
+		)
+		require.NoError(t, ErrHandler(mockAction(pipe.ErrSkipValidateEnabled))(ctx))
+	})
+
 	t.Run("some err", func(t *testing.T) {
 		require.Error(t, ErrHandler(mockAction(fmt.Errorf("pipe errored")))(ctx))
 	})
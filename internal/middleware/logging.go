@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"strings"
+	"sync"
 
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/cli"
@@ -18,6 +19,28 @@ const DefaultInitialPadding Padding = 3
 // ExtraPadding is the double of the DefaultInitialPadding.
 const ExtraPadding Padding = DefaultInitialPadding * 2
 
+// paddingMu guards the padding of cli.Default, which is shared by all the
+// pipes, even when they run concurrently.
+// nolint: gochecknoglobals
+var paddingMu sync.Mutex
+
+// SetPadding changes the padding of the cli log handler to the one returned
+// by fn, given the current one.
+func SetPadding(fn func(padding int) int) {
+	paddingMu.Lock()
+	defer paddingMu.Unlock()
+	cli.Default.Padding = fn(cli.Default.Padding)
+}
+
+// Handler is the cli log handler, which does not log entries while its
+// padding is being changed.
+// nolint: gochecknoglobals
+var Handler = log.HandlerFunc(func(e *log.Entry) error {
+	paddingMu.Lock()
+	defer paddingMu.Unlock()
+	return cli.Default.HandleLog(e)
+})
+
 // Logging pretty prints the given action and its title.
 // You can have different padding levels by providing different initial
 // paddings. The middleware will print the title in the given padding and the
@@ -26,12 +49,16 @@ const ExtraPadding Padding = DefaultInitialPadding * 2
 // The middleware always resets to the default padding.
 func Logging(title string, next Action, padding Padding) Action {
 	return func(ctx *context.Context) error {
-		defer func() {
-			cli.Default.Padding = int(DefaultInitialPadding)
-		}()
-		cli.Default.Padding = int(padding)
+		defer SetPadding(func(int) int {
+			return int(DefaultInitialPadding)
+		})
+		SetPadding(func(int) int {
+			return int(padding)
+		})
 		log.Infof(color.New(color.Bold).Sprint(strings.ToUpper(title)))
-		cli.Default.Padding = int(padding + DefaultInitialPadding)
+		SetPadding(func(int) int {
+			return int(padding + DefaultInitialPadding)
+		})
 		return next(ctx)
 	}
 }
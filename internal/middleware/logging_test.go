@@ -3,9 +3,26 @@ package middleware
 import (
 	"testing"
 
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/cli"
+	"github.com/apex/log/handlers/discard"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
 )
 
 func TestLogging(t *testing.T) {
 	require.NoError(t, Logging("foo", mockAction(nil), DefaultInitialPadding)(ctx))
 }
+
+func TestLoggingConcurrently(t *testing.T) {
+	log.SetHandler(Handler)
+	defer log.SetHandler(discard.Default)
+	var g errgroup.Group
+	for i := 0; i < 4; i++ {
+		g.Go(func() error {
+			return Logging("foo", mockAction(nil), ExtraPadding)(ctx)
+		})
+	}
+	require.NoError(t, g.Wait())
+	require.Equal(t, int(DefaultInitialPadding), cli.Default.Padding)
+}
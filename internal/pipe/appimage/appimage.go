@@ -0,0 +1,253 @@
+// Package appimage implements the Pipe interface building AppImages with
+// appimagetool.
+package appimage
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/desktop"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoAppImageTool is shown when appimagetool cannot be found in $PATH
+var ErrNoAppImageTool = errors.New("appimagetool not present in $PATH")
+
+const defaultNameTemplate = "{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ if .Arm }}v{{ .Arm }}{{ end }}"
+
+const defaultAppRunTemplate = `#!/bin/sh
+HERE="$(dirname "$(readlink -f "$0")")"
+exec "$HERE/usr/bin/{{ .Command }}" "$@"
+`
+
+// Pipe for AppImage packaging
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "AppImages"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	var ids = ids.New("appimages")
+	for i := range ctx.Config.AppImages {
+		var appimage = &ctx.Config.AppImages[i]
+		if appimage.ID == "" {
+			appimage.ID = "default"
+		}
+		if appimage.Desktop.File == "" || len(appimage.Desktop.Icons) == 0 {
+			return fmt.Errorf("appimage %s: desktop file and icons are required", appimage.ID)
+		}
+		if appimage.NameTemplate == "" {
+			appimage.NameTemplate = defaultNameTemplate
+		}
+		if len(appimage.Builds) == 0 {
+			for _, b := range ctx.Config.Builds {
+				appimage.Builds = append(appimage.Builds, b.ID)
+			}
+		}
+		if appimage.AppRunTemplate == "" {
+			appimage.AppRunTemplate = defaultAppRunTemplate
+		}
+		ids.Inc(appimage.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.AppImages) == 0 {
+		return pipe.Skip("appimage section is not configured")
+	}
+	if _, err := exec.LookPath("appimagetool"); err != nil {
+		return ErrNoAppImageTool
+	}
+	for _, appimage := range ctx.Config.AppImages {
+		ok, err := condition.Check(ctx, appimage.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("appimage", appimage.ID).Info("skipped because its condition is false")
+			continue
+		}
+		if err := doRun(ctx, appimage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, appimage config.AppImage) error {
+	var linuxBinaries = ctx.Artifacts.Filter(artifact.And(
+		artifact.ByGoos("linux"),
+		artifact.ByType(artifact.Binary),
+		artifact.ByIDs(appimage.Builds...),
+	)).GroupByPlatform()
+	if len(linuxBinaries) == 0 {
+		return fmt.Errorf("no linux binaries found for appimage %s", appimage.ID)
+	}
+	entry, err := desktop.Load(appimage.Desktop)
+	if err != nil {
+		return err
+	}
+	var g = semerrgroup.New(ctx.Parallelism)
+	for platform, binaries := range linuxBinaries {
+		var arch = archFor(binaries[0])
+		if arch == "" {
+			log.WithField("platform", platform).Warn("ignored unsupported arch")
+			continue
+		}
+		binaries := binaries
+		g.Go(func() error {
+			return create(ctx, appimage, entry, arch, binaries)
+		})
+	}
+	return g.Wait()
+}
+
+// archFor returns the appimagetool arch of the given binary, or an empty
+// string if it doesn't support it
+func archFor(binary *artifact.Artifact) string {
+	switch binary.Goarch + binary.Goarm {
+	case "amd64":
+		return "x86_64"
+	case "386":
+		return "i686"
+	case "arm64":
+		return "aarch64"
+	case "arm6", "arm7":
+		return "armhf"
+	}
+	return ""
+}
+
+// rootIcon returns the icon to put at the root of the AppDir: the scalable
+// one if any, the largest one otherwise
+func rootIcon(entry desktop.Entry) string {
+	if icon, ok := entry.Icons["scalable"]; ok {
+		return icon
+	}
+	var icon string
+	var largest int
+	for size, src := range entry.Icons {
+		var width int
+		_, _ = fmt.Sscanf(size, "%dx", &width)
+		if icon == "" || width > largest || (width == largest && src < icon) {
+			icon, largest = src, width
+		}
+	}
+	return icon
+}
+
+func create(ctx *context.Context, appimage config.AppImage, entry desktop.Entry, arch string, binaries []*artifact.Artifact) error {
+	name, err := tmpl.New(ctx).WithArtifact(binaries[0], map[string]string{}).Apply(appimage.NameTemplate)
+	if err != nil {
+		return err
+	}
+	var log = log.WithField("appimage", name).WithField("arch", arch)
+	var appDir = filepath.Join(ctx.Config.Dist, name+".AppDir")
+	if err := os.RemoveAll(appDir); err != nil {
+		return err
+	}
+
+	var command = appimage.Command
+	if command == "" {
+		command = binaries[0].Name
+	}
+	apprun, err := tmpl.New(ctx).
+		WithArtifact(binaries[0], map[string]string{}).
+		WithExtraFields(tmpl.Fields{
+			"Name":    entry.Keys["Name"],
+			"Command": command,
+			"Icon":    entry.Keys["Icon"],
+		}).
+		Apply(appimage.AppRunTemplate)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute apprun template")
+	}
+
+	log.WithField("dir", appDir).Debug("creating AppDir")
+	for _, binary := range binaries {
+		if err := copyFile(binary.Path, filepath.Join(appDir, "usr", "bin", binary.Name), 0755); err != nil {
+			return errors.Wrapf(err, "failed to copy %s", binary.Path)
+		}
+	}
+	// appimagetool wants the desktop file and its icon at the root, and
+	// desktop integration tools look for them in usr/share
+	var files = entry.Files("usr")
+	var icon = rootIcon(entry)
+	for src, dst := range appimage.Files {
+		files[src] = dst
+	}
+	for src, dst := range files {
+		if err := copyFile(src, filepath.Join(appDir, dst), 0644); err != nil {
+			return errors.Wrapf(err, "failed to copy %s", src)
+		}
+	}
+	for src, dst := range map[string]string{
+		entry.File: filepath.Base(entry.File),
+		icon:       entry.Keys["Icon"] + filepath.Ext(icon),
+	} {
+		if err := copyFile(src, filepath.Join(appDir, dst), 0644); err != nil {
+			return errors.Wrapf(err, "failed to copy %s", src)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(appDir, "AppRun"), []byte(apprun), 0755); err != nil {
+		return err
+	}
+
+	var path = filepath.Join(ctx.Config.Dist, name+".AppImage")
+	log.WithField("file", path).Info("creating")
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "appimagetool", "--no-appstream", appDir, path)
+	cmd.Env = append(ctx.Env.Strings(), "ARCH="+arch)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create %s: %s", name+".AppImage", string(out))
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   artifact.AppImage,
+		Name:   name + ".AppImage",
+		Path:   path,
+		Goos:   binaries[0].Goos,
+		Goarch: binaries[0].Goarch,
+		Goarm:  binaries[0].Goarm,
+		Extra: map[string]interface{}{
+			"ID": appimage.ID,
+		},
+	})
+	return nil
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src) // #nosec
+	if err != nil {
+		return err
+	}
+	defer in.Close() // nolint: errcheck
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
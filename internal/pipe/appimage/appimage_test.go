@@ -0,0 +1,272 @@
+package appimage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/desktop"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Builds:      []config.Build{{ID: "foo"}},
+		AppImages:   []config.AppImage{{Desktop: fooDesktop}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.AppImage{
+		ID:             "default",
+		Builds:         []string{"foo"},
+		NameTemplate:   defaultNameTemplate,
+		Desktop:        fooDesktop,
+		AppRunTemplate: defaultAppRunTemplate,
+	}, ctx.Config.AppImages[0])
+}
+
+func TestDefaultNoDesktop(t *testing.T) {
+	for name, desktop := range map[string]config.Desktop{
+		"empty":    {},
+		"no icons": {File: "foo.desktop"},
+		"no file":  {Icons: map[string]string{"48x48": "foo.png"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{
+				AppImages: []config.AppImage{{ID: "foo", Desktop: desktop}},
+			})
+			require.EqualError(t, Pipe{}.Default(ctx), "appimage foo: desktop file and icons are required")
+		})
+	}
+}
+
+func TestDefaultDuplicatedID(t *testing.T) {
+	var ctx = context.New(config.Project{
+		AppImages: []config.AppImage{
+			{ID: "a", Desktop: fooDesktop},
+			{ID: "a", Desktop: fooDesktop},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 appimages with the ID 'a', please fix your config")
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+// fakeAppImageTool puts a fake appimagetool in the PATH, which logs its
+// arguments and ARCH and creates the AppImage, returning a func that restores
+// the PATH.
+func fakeAppImageTool(t *testing.T, folder, log string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"appimagetool": `echo "ARCH=$ARCH" "$@" >> ` + log + `
+touch "$3"
+`,
+	})
+}
+
+const fooDesktopFile = "[Desktop Entry]\nType=Application\nName=Foo\nExec=foo\nIcon=com.example.Foo\n"
+
+var fooDesktop = config.Desktop{
+	File: "foo.desktop",
+	Icons: map[string]string{
+		"48x48":   "foo-48.png",
+		"256x256": "foo-256.png",
+	},
+}
+
+// appImageContext creates a context with the given appimage config, which
+// uses fooDesktop by default, and binaries for a few platforms in folder.
+func appImageContext(t *testing.T, folder string, appimage config.AppImage) *context.Context {
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0755))
+	for file, content := range map[string]string{
+		"foo.desktop": fooDesktopFile,
+		"foo-48.png":  "png48",
+		"foo-256.png": "png256",
+	} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(folder, file), []byte(content), 0644))
+	}
+	if appimage.Desktop.File == "" {
+		appimage.Desktop = fooDesktop
+	}
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		Builds:      []config.Build{{ID: "foo"}},
+		AppImages:   []config.AppImage{appimage},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	for _, platform := range []struct{ goos, goarch, goarm string }{
+		{"linux", "amd64", ""},
+		{"linux", "arm", "7"},
+		{"linux", "mips", ""},
+		{"darwin", "amd64", ""},
+	} {
+		var path = filepath.Join(dist, platform.goos+platform.goarch+platform.goarm, "foo")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte("foo"), 0755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "foo",
+			Path:   path,
+			Goos:   platform.goos,
+			Goarch: platform.goarch,
+			Goarm:  platform.goarm,
+			Type:   artifact.Binary,
+			Extra:  map[string]interface{}{"ID": "foo"},
+		})
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestRunPipe(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "appimagetool.log")
+	defer fakeAppImageTool(t, folder, log)()
+	require.NoError(t, ioutil.WriteFile("LICENSE", []byte("MIT"), 0644))
+	var ctx = appImageContext(t, folder, config.AppImage{
+		Files: map[string]string{"LICENSE": "usr/share/doc/foo/LICENSE"},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var appimages = ctx.Artifacts.Filter(artifact.ByType(artifact.AppImage)).List()
+	require.Len(t, appimages, 2)
+	var names []string
+	for _, appimage := range appimages {
+		names = append(names, appimage.Name)
+		require.FileExists(t, appimage.Path)
+		require.Equal(t, "default", appimage.ExtraOr("ID", ""))
+	}
+	require.ElementsMatch(t, []string{"foo_1.0.0_linux_amd64.AppImage", "foo_1.0.0_linux_armv7.AppImage"}, names)
+
+	var appDir = filepath.Join(ctx.Config.Dist, "foo_1.0.0_linux_amd64.AppDir")
+	for file, content := range map[string]string{
+		"usr/bin/foo":                                              "foo",
+		"usr/share/doc/foo/LICENSE":                                "MIT",
+		"usr/share/applications/foo.desktop":                       fooDesktopFile,
+		"usr/share/icons/hicolor/48x48/apps/com.example.Foo.png":   "png48",
+		"usr/share/icons/hicolor/256x256/apps/com.example.Foo.png": "png256",
+		"foo.desktop":                                              fooDesktopFile,
+		"com.example.Foo.png":                                      "png256",
+		"AppRun":                                                   "#!/bin/sh\nHERE=\"$(dirname \"$(readlink -f \"$0\")\")\"\nexec \"$HERE/usr/bin/foo\" \"$@\"\n",
+	} {
+		bts, err := ioutil.ReadFile(filepath.Join(appDir, file))
+		require.NoError(t, err)
+		require.Equal(t, content, string(bts))
+	}
+	info, err := os.Stat(filepath.Join(appDir, "AppRun"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	bts, err := ioutil.ReadFile(log)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "ARCH=x86_64 --no-appstream "+appDir+" "+filepath.Join(ctx.Config.Dist, "foo_1.0.0_linux_amd64.AppImage"))
+	require.Contains(t, string(bts), "ARCH=armhf --no-appstream")
+}
+
+func TestRunPipeCustomTemplate(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeAppImageTool(t, folder, filepath.Join(folder, "appimagetool.log"))()
+	var ctx = appImageContext(t, folder, config.AppImage{
+		Command:        "foo --gui",
+		AppRunTemplate: "#!/bin/sh\nexec {{ .Command }} {{ .Arch }} {{ .Name }} {{ .Icon }}\n",
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	var appDir = filepath.Join(ctx.Config.Dist, "foo_1.0.0_linux_amd64.AppDir")
+	bts, err := ioutil.ReadFile(filepath.Join(appDir, "AppRun"))
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh\nexec foo --gui amd64 Foo com.example.Foo\n", string(bts))
+}
+
+func TestRunPipeInvalidTemplate(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeAppImageTool(t, folder, filepath.Join(folder, "appimagetool.log"))()
+	var ctx = appImageContext(t, folder, config.AppImage{AppRunTemplate: "{{ .Nope }"})
+	var err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to execute apprun template")
+}
+
+func TestRunPipeInvalidDesktop(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeAppImageTool(t, folder, filepath.Join(folder, "appimagetool.log"))()
+	require.NoError(t, ioutil.WriteFile("bad.desktop", []byte("[Desktop Entry]\nType=Application\nName=Foo\nExec=foo\n"), 0644))
+	var ctx = appImageContext(t, folder, config.AppImage{
+		Desktop: config.Desktop{
+			File:  "bad.desktop",
+			Icons: map[string]string{"48x48": "foo-48.png"},
+		},
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), "invalid desktop file bad.desktop: missing Icon key")
+}
+
+func TestRunPipeMissingIcon(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeAppImageTool(t, folder, filepath.Join(folder, "appimagetool.log"))()
+	var ctx = appImageContext(t, folder, config.AppImage{
+		Desktop: config.Desktop{
+			File:  "foo.desktop",
+			Icons: map[string]string{"48x48": "nope.png"},
+		},
+	})
+	var err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to copy nope.png")
+}
+
+func TestRunPipeNoBinaries(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeAppImageTool(t, folder, filepath.Join(folder, "appimagetool.log"))()
+	var ctx = appImageContext(t, folder, config.AppImage{Builds: []string{"nope"}})
+	require.EqualError(t, Pipe{}.Run(ctx), "no linux binaries found for appimage default")
+}
+
+func TestRunPipeToolFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeAppImageTool(t, folder, filepath.Join(folder, "appimagetool.log"))()
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "bin", "appimagetool"),
+		[]byte("#!/bin/sh\necho 'no desktop file'\nexit 1\n"),
+		0755,
+	))
+	var ctx = appImageContext(t, folder, config.AppImage{})
+	var err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no desktop file")
+}
+
+func TestRootIcon(t *testing.T) {
+	for icon, icons := range map[string]map[string]string{
+		"foo.svg":   {"48x48": "foo-48.png", "scalable": "foo.svg"},
+		"foo-1.png": {"48x48": "foo-48.png", "256x256": "foo-1.png", "64x64@2": "foo-64.png"},
+	} {
+		require.Equal(t, icon, rootIcon(desktop.Entry{Icons: icons}))
+	}
+}
+
+func TestArchFor(t *testing.T) {
+	for arch, binary := range map[string]artifact.Artifact{
+		"x86_64":  {Goarch: "amd64"},
+		"i686":    {Goarch: "386"},
+		"aarch64": {Goarch: "arm64"},
+		"armhf":   {Goarch: "arm", Goarm: "6"},
+		"":        {Goarch: "arm", Goarm: "5"},
+	} {
+		binary := binary
+		require.Equal(t, arch, archFor(&binary))
+	}
+}
@@ -5,17 +5,21 @@ package archive
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/apex/log"
 	"github.com/campoy/unique"
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
 	"github.com/goreleaser/goreleaser/internal/deprecate"
 	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/archive"
@@ -46,7 +50,7 @@ func (Pipe) Default(ctx *context.Context) error {
 	if len(ctx.Config.Archives) == 0 {
 		ctx.Config.Archives = append(ctx.Config.Archives, ctx.Config.Archive)
 		if !reflect.DeepEqual(ctx.Config.Archive, config.Archive{}) {
-			deprecate.Notice("archive")
+			deprecate.Notice(ctx, "archive")
 		}
 	}
 	for i := range ctx.Config.Archives {
@@ -54,19 +58,30 @@ func (Pipe) Default(ctx *context.Context) error {
 		if archive.Format == "" {
 			archive.Format = "tar.gz"
 		}
+		if err := validateFormat(archive.Format); err != nil {
+			return err
+		}
+		for _, override := range archive.FormatOverrides {
+			if err := validateFormat(override.Format); err != nil {
+				return fmt.Errorf("invalid archive format override for %s: %s", override.Goos, override.Format)
+			}
+		}
+		if err := validateManifest(archive.Manifest); err != nil {
+			return err
+		}
 		if archive.ID == "" {
 			archive.ID = "default"
 		}
 		if len(archive.Files) == 0 {
-			archive.Files = []string{
-				"licence*",
-				"LICENCE*",
-				"license*",
-				"LICENSE*",
-				"readme*",
-				"README*",
-				"changelog*",
-				"CHANGELOG*",
+			archive.Files = []config.File{
+				{Source: "licence*"},
+				{Source: "LICENCE*"},
+				{Source: "license*"},
+				{Source: "LICENSE*"},
+				{Source: "readme*"},
+				{Source: "README*"},
+				{Source: "changelog*"},
+				{Source: "CHANGELOG*"},
 			}
 		}
 		if archive.NameTemplate == "" {
@@ -80,6 +95,12 @@ func (Pipe) Default(ctx *context.Context) error {
 				archive.Builds = append(archive.Builds, build.ID)
 			}
 		}
+		if err := validateBuilds(ctx, *archive); err != nil {
+			return err
+		}
+		if err := validateFiles(*archive); err != nil {
+			return err
+		}
 		ids.Inc(archive.ID)
 	}
 	return ids.Validate()
@@ -90,12 +111,25 @@ func (Pipe) Run(ctx *context.Context) error {
 	var g = semerrgroup.New(ctx.Parallelism)
 	for _, archive := range ctx.Config.Archives {
 		archive := archive
+		ok, err := condition.Check(ctx, archive.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("archive", archive.ID).Info("skipped because its condition is false")
+			continue
+		}
 		var filtered = ctx.Artifacts.Filter(
 			artifact.And(
 				artifact.ByType(artifact.Binary),
 				artifact.ByIDs(archive.Builds...),
 			),
 		)
+		if len(filtered.List()) == 0 {
+			if err := pipe.Warn(ctx, fmt.Sprintf("no binaries found for archive %s", archive.ID)); err != nil {
+				return err
+			}
+		}
 		for group, artifacts := range filtered.GroupByPlatform() {
 			log.Debugf("group %s has %d binaries", group, len(artifacts))
 			artifacts := artifacts
@@ -114,7 +148,7 @@ func create(ctx *context.Context, archive config.Archive, binaries []*artifact.A
 	var format = packageFormat(archive, binaries[0].Goos)
 	folder, err := tmpl.New(ctx).
 		WithArtifact(binaries[0], archive.Replacements).
-		Apply(archive.NameTemplate)
+		ApplyName(archive.NameTemplate)
 	if err != nil {
 		return err
 	}
@@ -142,24 +176,28 @@ func create(ctx *context.Context, archive config.Archive, binaries []*artifact.A
 		return err
 	}
 
-	var a = NewEnhancedArchive(archivelib.New(archiveFile), wrap)
-	defer a.Close() // nolint: errcheck
+	// reproducible archives only read their files on Close, so the generated
+	// files must outlive the archive
+	generated, err := ioutil.TempDir("", "goreleaserarchive")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(generated) // nolint: errcheck
 
-	files, err := findFiles(archive)
+	compressed, err := newArchive(ctx, archiveFile, archive.CompressionLevel, archive.Reproducible)
 	if err != nil {
-		return fmt.Errorf("failed to find files to archive: %s", err.Error())
+		return fmt.Errorf("failed to create archive %s: %s", archivePath, err.Error())
 	}
-	for _, f := range files {
-		if err = a.Add(f, f); err != nil {
-			return fmt.Errorf("failed to add %s to the archive: %s", f, err.Error())
-		}
+	var a = NewEnhancedArchive(compressed, wrap)
+	manifest, err := addFiles(ctx, a, archive, binaries, wrap, generated)
+	if err != nil {
+		_ = a.Close()
+		return err
 	}
-	for _, binary := range binaries {
-		if err := a.Add(binary.Name, binary.Path); err != nil {
-			return fmt.Errorf("failed to add %s -> %s to the archive: %s", binary.Path, binary.Name, err.Error())
-		}
+	if err := a.Close(); err != nil {
+		return fmt.Errorf("failed to close archive %s: %s", archivePath, err.Error())
 	}
-	ctx.Artifacts.Add(&artifact.Artifact{
+	var art = &artifact.Artifact{
 		Type:   artifact.UploadableArchive,
 		Name:   folder + "." + format,
 		Path:   archivePath,
@@ -167,14 +205,85 @@ func create(ctx *context.Context, archive config.Archive, binaries []*artifact.A
 		Goarch: binaries[0].Goarch,
 		Goarm:  binaries[0].Goarm,
 		Extra: map[string]interface{}{
-			"Builds": binaries,
-			"ID":     archive.ID,
-			"Format": archive.Format,
+			"Builds":       binaries,
+			"ID":           archive.ID,
+			"Format":       archive.Format,
+			"WrappedIn":    wrap,
+			"Replacements": archive.Replacements,
 		},
-	})
+	}
+	ctx.Artifacts.Add(art)
+	if archive.Manifest != "" {
+		return writeManifest(ctx, archive.Manifest, art, manifest)
+	}
 	return nil
 }
 
+// addFiles adds the extra files, the rendered templated files and the
+// binaries to the archive, returning the manifest entries of all of them.
+func addFiles(ctx *context.Context, a archivelib.Archive, archive config.Archive, binaries []*artifact.Artifact, wrap, generated string) ([]ManifestEntry, error) {
+	files, err := findFiles(ctx, archive, binaries[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to find files to archive: %s", err.Error())
+	}
+	rendered, err := renderFiles(ctx, archive, binaries[0], generated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render files to archive: %s", err.Error())
+	}
+	files = append(files, rendered...)
+	var manifest []ManifestEntry
+	for _, f := range files {
+		if err = addFile(a, f, archive.PreserveSymlinks); err != nil {
+			return nil, fmt.Errorf("failed to add %s to the archive: %s", f.Source, err.Error())
+		}
+		if archive.Manifest != "" {
+			entry, err := manifestEntry(nameInArchive(wrap, f.Destination), f.Source, f.Mode, archive.PreserveSymlinks)
+			if err != nil {
+				return nil, fmt.Errorf("failed to add %s to the archive manifest: %s", f.Source, err.Error())
+			}
+			manifest = append(manifest, entry)
+		}
+	}
+	for _, binary := range binaries {
+		if err := a.Add(binary.Name, binary.Path); err != nil {
+			return nil, fmt.Errorf("failed to add %s -> %s to the archive: %s", binary.Path, binary.Name, err.Error())
+		}
+		if archive.Manifest != "" {
+			entry, err := manifestEntry(nameInArchive(wrap, binary.Name), binary.Path, 0, false)
+			if err != nil {
+				return nil, fmt.Errorf("failed to add %s to the archive manifest: %s", binary.Path, err.Error())
+			}
+			manifest = append(manifest, entry)
+		}
+	}
+	return manifest, nil
+}
+
+// newArchive creates the archive, using the commit date as the modification
+// time of its entries when it must be reproducible.
+func newArchive(ctx *context.Context, file *os.File, level int, reproducible bool) (archivelib.Archive, error) {
+	if reproducible {
+		return archivelib.NewReproducible(file, level, ctx.Git.CommitDate)
+	}
+	return archivelib.NewWithLevel(file, level)
+}
+
+// addFile adds the given file to the archive, with its custom mode if any.
+// Symlinks are added as such when they are preserved, and as the file they
+// point to otherwise.
+func addFile(a archivelib.Archive, f config.File, symlinks bool) error {
+	if symlinks {
+		info, err := os.Lstat(f.Source)
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return archivelib.AddSymlink(a, f.Destination, f.Source)
+		}
+	}
+	return archivelib.AddWithMode(a, f.Destination, f.Source, f.Mode)
+}
+
 func wrapFolder(a config.Archive) string {
 	switch a.WrapInDirectory {
 	case "true":
@@ -191,7 +300,7 @@ func skip(ctx *context.Context, archive config.Archive, binaries []*artifact.Art
 		log.WithField("binary", binary.Name).Info("skip archiving")
 		name, err := tmpl.New(ctx).
 			WithArtifact(binary, archive.Replacements).
-			Apply(archive.NameTemplate)
+			ApplyName(archive.NameTemplate)
 		if err != nil {
 			return err
 		}
@@ -203,30 +312,134 @@ func skip(ctx *context.Context, archive config.Archive, binaries []*artifact.Art
 			Goarch: binary.Goarch,
 			Goarm:  binary.Goarm,
 			Extra: map[string]interface{}{
-				"Builds": []*artifact.Artifact{binary},
-				"ID":     archive.ID,
-				"Format": archive.Format,
+				"Builds":       []*artifact.Artifact{binary},
+				"ID":           archive.ID,
+				"Format":       archive.Format,
+				"Replacements": archive.Replacements,
 			},
 		})
 	}
 	return nil
 }
 
-func findFiles(archive config.Archive) (result []string, err error) {
-	for _, glob := range archive.Files {
+// findFiles resolves the archive files, returning each matched file as its
+// Source and its path inside the archive as its Destination.
+func findFiles(ctx *context.Context, archive config.Archive, binary *artifact.Artifact) (result []config.File, err error) {
+	var template = tmpl.New(ctx).WithArtifact(binary, archive.Replacements)
+	for _, f := range archive.Files {
+		if f.Contents != "" {
+			continue
+		}
+		glob, err := template.Apply(f.Source)
+		if err != nil {
+			return result, fmt.Errorf("failed to apply template %s: %s", f.Source, err.Error())
+		}
+		dst, err := template.Apply(f.Destination)
+		if err != nil {
+			return result, fmt.Errorf("failed to apply template %s: %s", f.Destination, err.Error())
+		}
 		files, err := zglob.Glob(glob)
 		if err != nil {
 			return result, fmt.Errorf("globbing failed for pattern %s: %s", glob, err.Error())
 		}
-		result = append(result, files...)
+		for _, file := range files {
+			var name = file
+			if f.StripParent {
+				// the folder structure is dropped, so are the folders
+				info, err := os.Stat(file)
+				if err != nil {
+					return result, err
+				}
+				if info.IsDir() {
+					continue
+				}
+				name = filepath.Base(file)
+			}
+			result = append(result, config.File{
+				Source:      file,
+				Destination: filepath.Join(dst, name),
+				Mode:        f.Mode,
+			})
+		}
 	}
 	// remove duplicates
 	unique.Slice(&result, func(i, j int) bool {
-		return strings.Compare(result[i], result[j]) < 0
+		return strings.Compare(result[i].Destination, result[j].Destination) < 0 ||
+			(result[i].Destination == result[j].Destination &&
+				strings.Compare(result[i].Source, result[j].Source) < 0)
 	})
 	return
 }
 
+// renderFiles writes the files whose contents are a template to the given
+// folder, returning them like findFiles does.
+func renderFiles(ctx *context.Context, archive config.Archive, binary *artifact.Artifact, folder string) (result []config.File, err error) {
+	var template = tmpl.New(ctx).WithArtifact(binary, archive.Replacements)
+	for i, f := range archive.Files {
+		if f.Contents == "" {
+			continue
+		}
+		dst, err := template.Apply(f.Destination)
+		if err != nil {
+			return result, fmt.Errorf("failed to apply template %s: %s", f.Destination, err.Error())
+		}
+		contents, err := template.Apply(f.Contents)
+		if err != nil {
+			return result, fmt.Errorf("failed to apply template %s: %s", f.Contents, err.Error())
+		}
+		var path = filepath.Join(folder, strconv.Itoa(i))
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			return result, err
+		}
+		result = append(result, config.File{
+			Source:      path,
+			Destination: dst,
+			Mode:        f.Mode,
+		})
+	}
+	return result, nil
+}
+
+// validateFiles checks that the files with contents have a destination, and
+// no glob.
+func validateFiles(archive config.Archive) error {
+	for _, f := range archive.Files {
+		if f.Contents == "" {
+			continue
+		}
+		if f.Destination == "" || f.Source != "" {
+			return fmt.Errorf("archive %s: files with contents need a dst and no src", archive.ID)
+		}
+	}
+	return nil
+}
+
+// validateBuilds checks that the build IDs of the archive exist, as a typo
+// would silently leave the binaries of that build out of it.
+func validateBuilds(ctx *context.Context, archive config.Archive) error {
+	if len(ctx.Config.Builds) == 0 {
+		return nil
+	}
+	var builds = map[string]bool{}
+	for _, build := range ctx.Config.Builds {
+		builds[build.ID] = true
+	}
+	for _, id := range archive.Builds {
+		if !builds[id] {
+			return fmt.Errorf("archive %s: no build with id %s", archive.ID, id)
+		}
+	}
+	return nil
+}
+
+func validateFormat(format string) error {
+	switch format {
+	case "tar.gz", "tar.xz", "tar.zst", "gz", "zip", "7z", "binary":
+		return nil
+	}
+	return fmt.Errorf("invalid archive format: %s", format)
+}
+
 func packageFormat(archive config.Archive, platform string) string {
 	for _, override := range archive.FormatOverrides {
 		if strings.HasPrefix(platform, override.Goos) {
@@ -257,13 +470,41 @@ type EnhancedArchive struct {
 
 // Add adds a file
 func (d EnhancedArchive) Add(name, path string) error {
-	name = strings.Replace(filepath.Join(d.wrap, name), "\\", "/", -1)
+	return d.AddWithMode(name, path, 0)
+}
+
+// AddWithMode adds a file with the given permissions
+func (d EnhancedArchive) AddWithMode(name, path string, mode os.FileMode) error {
+	name, err := d.name(name, path)
+	if err != nil {
+		return err
+	}
+	return archive.AddWithMode(d.a, name, path, mode)
+}
+
+// AddSymlink adds a symlink as a symlink
+func (d EnhancedArchive) AddSymlink(name, path string) error {
+	name, err := d.name(name, path)
+	if err != nil {
+		return err
+	}
+	return archive.AddSymlink(d.a, name, path)
+}
+
+func (d EnhancedArchive) name(name, path string) (string, error) {
+	name = nameInArchive(d.wrap, name)
 	log.Debugf("adding file: %s as %s", path, name)
 	if _, ok := d.files[name]; ok {
-		return fmt.Errorf("file %s already exists in the archive", name)
+		return "", fmt.Errorf("file %s already exists in the archive", name)
 	}
 	d.files[name] = path
-	return d.a.Add(name, path)
+	return name, nil
+}
+
+// nameInArchive returns the name of a file inside an archive wrapped in the
+// given folder.
+func nameInArchive(wrap, name string) string {
+	return strings.Replace(filepath.Join(wrap, name), "\\", "/", -1)
 }
 
 // Close closes the underlying archive
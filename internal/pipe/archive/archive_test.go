@@ -4,11 +4,13 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/testlib"
@@ -49,9 +51,9 @@ func TestRunPipe(t *testing.T) {
 							ID:           "defaultarch",
 							Builds:       []string{"default"},
 							NameTemplate: defaultNameTemplate,
-							Files: []string{
-								"README.*",
-								"./foo/**/*",
+							Files: []config.File{
+								{Source: "README.*"},
+								{Source: "./foo/**/*"},
 							},
 							FormatOverrides: []config.FormatOverride{
 								{
@@ -189,6 +191,10 @@ func TestRunPipeBinary(t *testing.T) {
 					Format:       "binary",
 					NameTemplate: defaultBinaryNameTemplate,
 					Builds:       []string{"default"},
+					Replacements: map[string]string{
+						"darwin": "macOS",
+						"amd64":  "x86_64",
+					},
 				},
 			},
 		},
@@ -222,8 +228,12 @@ func TestRunPipeBinary(t *testing.T) {
 	var binaries = ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableBinary))
 	darwin := binaries.Filter(artifact.ByGoos("darwin")).List()[0]
 	windows := binaries.Filter(artifact.ByGoos("windows")).List()[0]
-	require.Equal(t, "mybin_0.0.1_darwin_amd64", darwin.Name)
-	require.Equal(t, "mybin_0.0.1_windows_amd64.exe", windows.Name)
+	require.Equal(t, "mybin_0.0.1_macOS_x86_64", darwin.Name)
+	require.Equal(t, "mybin_0.0.1_windows_x86_64.exe", windows.Name)
+	require.Equal(t, map[string]string{
+		"darwin": "macOS",
+		"amd64":  "x86_64",
+	}, darwin.ExtraOr("Replacements", nil))
 	require.Len(t, binaries.List(), 2)
 }
 
@@ -272,8 +282,8 @@ func TestRunPipeInvalidGlob(t *testing.T) {
 					Builds:       []string{"default"},
 					NameTemplate: "foo",
 					Format:       "zip",
-					Files: []string{
-						"[x-]",
+					Files: []config.File{
+						{Source: "[x-]"},
 					},
 				},
 			},
@@ -387,8 +397,8 @@ func TestRunPipeWrap(t *testing.T) {
 					Replacements: map[string]string{
 						"darwin": "macOS",
 					},
-					Files: []string{
-						"README.*",
+					Files: []config.File{
+						{Source: "README.*"},
 					},
 				},
 			},
@@ -424,6 +434,50 @@ func TestRunPipeWrap(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, filepath.Join("foo_macOS", n), h.Name)
 	}
+
+	var archives = ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List()
+	require.Len(t, archives, 1)
+	require.Equal(t, "foo_macOS", archives[0].ExtraOr("WrappedIn", ""))
+}
+
+func TestRunPipeInvalidCompressionLevel(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0755))
+	require.NoError(t, os.Mkdir(filepath.Join(dist, "darwinamd64"), 0755))
+	_, err := os.Create(filepath.Join(dist, "darwinamd64", "mybin"))
+	require.NoError(t, err)
+	var ctx = context.New(
+		config.Project{
+			Dist: dist,
+			Archives: []config.Archive{
+				{
+					Builds:           []string{"default"},
+					NameTemplate:     "foo",
+					Format:           "tar.zst",
+					CompressionLevel: 99,
+				},
+			},
+		},
+	)
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join("dist", "darwinamd64", "mybin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"Binary": "mybin",
+			"ID":     "default",
+		},
+	})
+	require.EqualError(
+		t,
+		Pipe{}.Run(ctx),
+		"failed to create archive "+filepath.Join(dist, "foo.tar.zst")+": zstd: invalid compression level: 99",
+	)
 }
 
 func TestDefault(t *testing.T) {
@@ -446,8 +500,8 @@ func TestDefaultSet(t *testing.T) {
 					Builds:       []string{"default"},
 					NameTemplate: "foo",
 					Format:       "zip",
-					Files: []string{
-						"foo",
+					Files: []config.File{
+						{Source: "foo"},
 					},
 				},
 			},
@@ -456,7 +510,7 @@ func TestDefaultSet(t *testing.T) {
 	require.NoError(t, Pipe{}.Default(ctx))
 	require.Equal(t, "foo", ctx.Config.Archives[0].NameTemplate)
 	require.Equal(t, "zip", ctx.Config.Archives[0].Format)
-	require.Equal(t, "foo", ctx.Config.Archives[0].Files[0])
+	require.Equal(t, "foo", ctx.Config.Archives[0].Files[0].Source)
 }
 
 func TestDefaultFormatBinary(t *testing.T) {
@@ -473,6 +527,37 @@ func TestDefaultFormatBinary(t *testing.T) {
 	require.Equal(t, defaultBinaryNameTemplate, ctx.Config.Archives[0].NameTemplate)
 }
 
+func TestDefaultInvalidFormat(t *testing.T) {
+	var ctx = &context.Context{
+		Config: config.Project{
+			Archives: []config.Archive{
+				{
+					Format: "rar",
+				},
+			},
+		},
+	}
+	require.EqualError(t, Pipe{}.Default(ctx), "invalid archive format: rar")
+}
+
+func TestDefaultInvalidFormatOverride(t *testing.T) {
+	var ctx = &context.Context{
+		Config: config.Project{
+			Archives: []config.Archive{
+				{
+					FormatOverrides: []config.FormatOverride{
+						{
+							Goos:   "windows",
+							Format: "rar",
+						},
+					},
+				},
+			},
+		},
+	}
+	require.EqualError(t, Pipe{}.Default(ctx), "invalid archive format override for windows: rar")
+}
+
 func TestFormatFor(t *testing.T) {
 	var ctx = &context.Context{
 		Config: config.Project{
@@ -517,8 +602,8 @@ func TestBinaryOverride(t *testing.T) {
 						{
 							Builds:       []string{"default"},
 							NameTemplate: defaultNameTemplate,
-							Files: []string{
-								"README.*",
+							Files: []config.File{
+								{Source: "README.*"},
 							},
 							FormatOverrides: []config.FormatOverride{
 								{
@@ -590,9 +675,9 @@ func TestRunPipeSameArchiveFilename(t *testing.T) {
 				{
 					Builds:       []string{"default"},
 					NameTemplate: "same-filename",
-					Files: []string{
-						"README.*",
-						"./foo/**/*",
+					Files: []config.File{
+						{Source: "README.*"},
+						{Source: "./foo/**/*"},
 					},
 					Format: "tar.gz",
 				},
@@ -680,3 +765,308 @@ func TestSeveralArchivesWithTheSameID(t *testing.T) {
 	}
 	require.EqualError(t, Pipe{}.Default(ctx), "found 2 archives with the ID 'a', please fix your config")
 }
+
+func TestRunPipeNoBinaries(t *testing.T) {
+	var archives = []config.Archive{
+		{
+			ID:     "foo",
+			Builds: []string{"nope"},
+			Format: "tar.gz",
+		},
+	}
+	require.NoError(t, Pipe{}.Run(context.New(config.Project{
+		Archives: archives,
+	})))
+	require.EqualError(t, Pipe{}.Run(context.New(config.Project{
+		Strict:   true,
+		Archives: archives,
+	})), "strict mode: no binaries found for archive foo")
+}
+
+func TestFindFiles(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	for _, f := range []string{
+		"LICENSE",
+		"completions/bash/mybin",
+		"completions/zsh/_mybin",
+		"config/darwin.yml",
+		"config/linux.yml",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(folder, f)), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(folder, f), []byte(f), 0644))
+	}
+	var ctx = context.New(config.Project{})
+	files, err := findFiles(ctx, config.Archive{
+		Files: []config.File{
+			{Source: "LICENSE"},
+			{Source: "LICENSE"},
+			{Source: "completions/**/*", Destination: "completions", StripParent: true},
+			{Source: "config/{{ .Os }}.yml", Destination: "etc/{{ .Os }}"},
+		},
+	}, &artifact.Artifact{Goos: "darwin", Goarch: "amd64"})
+	require.NoError(t, err)
+	require.Equal(t, []config.File{
+		{Source: "LICENSE", Destination: "LICENSE"},
+		{Source: "completions/zsh/_mybin", Destination: "completions/_mybin"},
+		{Source: "completions/bash/mybin", Destination: "completions/mybin"},
+		{Source: "config/darwin.yml", Destination: "etc/darwin/config/darwin.yml"},
+	}, files)
+}
+
+func TestFindFilesInvalidTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	for _, f := range []config.File{
+		{Source: "{{ .Nope }"},
+		{Source: "LICENSE", Destination: "{{ .Nope }"},
+	} {
+		_, err := findFiles(ctx, config.Archive{
+			Files: []config.File{f},
+		}, &artifact.Artifact{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to apply template")
+	}
+}
+
+func TestRunPipeConditionFalse(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Archives: []config.Archive{
+			{
+				ID:     "foo",
+				Builds: []string{"default"},
+				If:     `{{ eq .Branch "release" }}`,
+			},
+		},
+	})
+	ctx.Git.Branch = "master"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   "nope",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"ID": "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Empty(t, ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List())
+
+	ctx.Config.Archives[0].If = "{{ .Nope }}"
+	require.Error(t, Pipe{}.Run(ctx))
+}
+
+func TestAddFileSymlinksAndModes(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, ioutil.WriteFile("README.md", []byte("readme"), 0644))
+	require.NoError(t, os.Symlink("README.md", "README"))
+	f, err := os.Create(filepath.Join(folder, "test.tar.gz"))
+	require.NoError(t, err)
+	var a = NewEnhancedArchive(archive.New(f), "foo")
+	require.NoError(t, addFile(a, config.File{Source: "README", Destination: "README"}, true))
+	require.NoError(t, addFile(a, config.File{Source: "README.md", Destination: "README.md", Mode: 0600}, true))
+	require.NoError(t, addFile(a, config.File{Source: "README", Destination: "README.copy"}, false))
+	require.NoError(t, a.Close())
+	require.NoError(t, f.Close())
+
+	f, err = os.Open(f.Name())
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	gr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	var r = tar.NewReader(gr)
+	var headers = map[string]*tar.Header{}
+	for {
+		next, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		headers[next.Name] = next
+	}
+	require.Equal(t, byte(tar.TypeSymlink), headers["foo/README"].Typeflag)
+	require.Equal(t, "README.md", headers["foo/README"].Linkname)
+	require.Equal(t, int64(0600), headers["foo/README.md"].Mode)
+	require.Equal(t, byte(tar.TypeReg), headers["foo/README.copy"].Typeflag)
+}
+
+func TestDefaultUnknownBuild(t *testing.T) {
+	var ctx = &context.Context{
+		Config: config.Project{
+			Builds: []config.Build{
+				{ID: "cli"},
+				{ID: "agent"},
+			},
+			Archives: []config.Archive{
+				{ID: "cli", Builds: []string{"cli"}},
+				{ID: "agent", Builds: []string{"agnet"}},
+			},
+		},
+	}
+	require.EqualError(t, Pipe{}.Default(ctx), "archive agent: no build with id agnet")
+}
+
+func TestRunPipeManifest(t *testing.T) {
+	for _, format := range []string{"json", "txt"} {
+		t.Run(format, func(t *testing.T) {
+			folder, back := testlib.Mktmp(t)
+			defer back()
+			var dist = filepath.Join(folder, "dist")
+			require.NoError(t, os.MkdirAll(filepath.Join(dist, "linuxamd64"), 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dist, "linuxamd64", "mybin"), []byte("bin"), 0755))
+			require.NoError(t, ioutil.WriteFile("README.md", []byte("readme"), 0644))
+			var ctx = context.New(config.Project{
+				Dist: dist,
+				Archives: []config.Archive{
+					{
+						ID:              "default",
+						Builds:          []string{"default"},
+						NameTemplate:    "foo",
+						WrapInDirectory: "foo",
+						Format:          "tar.gz",
+						Manifest:        format,
+						Files: []config.File{
+							{Source: "README.md", Mode: 0600},
+						},
+					},
+				},
+			})
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Goos:   "linux",
+				Goarch: "amd64",
+				Name:   "mybin",
+				Path:   filepath.Join(dist, "linuxamd64", "mybin"),
+				Type:   artifact.Binary,
+				Extra: map[string]interface{}{
+					"Binary": "mybin",
+					"ID":     "default",
+				},
+			})
+			require.NoError(t, Pipe{}.Run(ctx))
+
+			var manifests = ctx.Artifacts.Filter(artifact.ByType(artifact.ArchiveManifest)).List()
+			require.Len(t, manifests, 1)
+			require.Equal(t, "foo.tar.gz.manifest."+format, manifests[0].Name)
+			require.Equal(t, "default", manifests[0].ExtraOr("ID", ""))
+			bts, err := ioutil.ReadFile(manifests[0].Path)
+			require.NoError(t, err)
+
+			var readme = ManifestEntry{
+				Name:   "foo/README.md",
+				Size:   6,
+				Mode:   "-rw-------",
+				SHA256: "711a6108ba2ce6ca93dd47d6817f2361db10d8ab6eec89460b2dfc2c325efabe",
+			}
+			var bin = ManifestEntry{
+				Name:   "foo/mybin",
+				Size:   3,
+				Mode:   "-rwxr-xr-x",
+				SHA256: "51a1f05af85e342e3c849b47d387086476282d5f50dc240c19216d6edfb1eb5a",
+			}
+			if format == "txt" {
+				require.Equal(t, readme.SHA256+"  -rw-------  6  foo/README.md\n"+bin.SHA256+"  -rwxr-xr-x  3  foo/mybin\n", string(bts))
+				return
+			}
+			var entries []ManifestEntry
+			require.NoError(t, json.Unmarshal(bts, &entries))
+			require.Equal(t, []ManifestEntry{readme, bin}, entries)
+		})
+	}
+}
+
+func TestDefaultInvalidManifest(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Archives: []config.Archive{{Manifest: "yaml"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "invalid archive manifest format: yaml")
+}
+
+func TestRenderFiles(t *testing.T) {
+	var folder, err = ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	var ctx = context.New(config.Project{ProjectName: "foo"})
+	ctx.Version = "1.2.3"
+	files, err := renderFiles(ctx, config.Archive{
+		Files: []config.File{
+			{Source: "LICENSE"},
+			{Destination: "version.txt", Contents: "{{ .Version }}\n"},
+			{Destination: "bin/install-{{ .Os }}.sh", Contents: "#!/bin/sh\ncp {{ .ProjectName }} /usr/local/bin\n", Mode: 0755},
+		},
+	}, &artifact.Artifact{Goos: "darwin", Goarch: "amd64"}, folder)
+	require.NoError(t, err)
+	require.Equal(t, []config.File{
+		{Source: filepath.Join(folder, "1"), Destination: "version.txt"},
+		{Source: filepath.Join(folder, "2"), Destination: "bin/install-darwin.sh", Mode: 0755},
+	}, files)
+	bts, err := ioutil.ReadFile(files[0].Source)
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3\n", string(bts))
+	bts, err = ioutil.ReadFile(files[1].Source)
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh\ncp foo /usr/local/bin\n", string(bts))
+}
+
+func TestRunPipeReproducibleWithGeneratedFiles(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.MkdirAll(filepath.Join(dist, "linuxamd64"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dist, "linuxamd64", "mybin"), []byte("bin"), 0755))
+	var ctx = context.New(config.Project{
+		Dist: dist,
+		Archives: []config.Archive{
+			{
+				ID:           "default",
+				Builds:       []string{"default"},
+				NameTemplate: "foo",
+				Format:       "tar.gz",
+				Reproducible: true,
+				Files: []config.File{
+					{Destination: "version.txt", Contents: "{{ .Version }}\n"},
+				},
+			},
+		},
+	})
+	ctx.Version = "1.2.3"
+	ctx.Git.CommitDate = time.Unix(1600000000, 0)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join(dist, "linuxamd64", "mybin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"Binary": "mybin",
+			"ID":     "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, []string{"mybin", "version.txt"}, tarFiles(t, filepath.Join(dist, "foo.tar.gz")))
+}
+
+func TestRenderFilesInvalidTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	for _, f := range []config.File{
+		{Destination: "version.txt", Contents: "{{ .Nope }"},
+		{Destination: "{{ .Nope }", Contents: "foo"},
+	} {
+		_, err := renderFiles(ctx, config.Archive{
+			Files: []config.File{f},
+		}, &artifact.Artifact{}, os.TempDir())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to apply template")
+	}
+}
+
+func TestDefaultFilesWithContents(t *testing.T) {
+	for _, f := range []config.File{
+		{Contents: "foo"},
+		{Source: "LICENSE", Destination: "LICENSE", Contents: "foo"},
+	} {
+		var ctx = context.New(config.Project{
+			Archives: []config.Archive{{ID: "foo", Files: []config.File{f}}},
+		})
+		require.EqualError(t, Pipe{}.Default(ctx), "archive foo: files with contents need a dst and no src")
+	}
+}
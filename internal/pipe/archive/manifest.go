@@ -0,0 +1,121 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// ManifestEntry is a file listed in an archive manifest
+type ManifestEntry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Mode   string `json:"mode"`
+	SHA256 string `json:"sha256,omitempty"`
+	Link   string `json:"link,omitempty"`
+}
+
+func validateManifest(format string) error {
+	switch format {
+	case "", "json", "txt":
+		return nil
+	}
+	return fmt.Errorf("invalid archive manifest format: %s", format)
+}
+
+// manifestEntry describes the file at path, added to the archive as name,
+// with the given custom mode if any. Symlinks are described as such only
+// when they are preserved in the archive.
+func manifestEntry(name, path string, mode os.FileMode, symlink bool) (ManifestEntry, error) {
+	var entry = ManifestEntry{Name: name}
+	var stat = os.Stat
+	if symlink {
+		stat = os.Lstat
+	}
+	info, err := stat(path)
+	if err != nil {
+		return entry, err
+	}
+	if mode == 0 {
+		mode = info.Mode()
+	} else {
+		mode = info.Mode()&^os.ModePerm | mode
+	}
+	entry.Mode = mode.String()
+	if info.Mode()&os.ModeSymlink != 0 {
+		entry.Link, err = os.Readlink(path)
+		return entry, err
+	}
+	if info.IsDir() {
+		return entry, nil
+	}
+	entry.Size = info.Size()
+	file, err := os.Open(path) // #nosec
+	if err != nil {
+		return entry, err
+	}
+	defer file.Close() // nolint: errcheck
+	var sum = sha256.New()
+	if _, err := io.Copy(sum, file); err != nil {
+		return entry, err
+	}
+	entry.SHA256 = hex.EncodeToString(sum.Sum(nil))
+	return entry, nil
+}
+
+// writeManifest writes the manifest of the given archive next to it, and adds
+// it to the artifacts.
+func writeManifest(ctx *context.Context, format string, archive *artifact.Artifact, entries []ManifestEntry) error {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	var content []byte
+	switch format {
+	case "json":
+		bts, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		content = append(bts, '\n')
+	case "txt":
+		var sb strings.Builder
+		for _, entry := range entries {
+			var sum = entry.SHA256
+			if sum == "" {
+				sum = "-"
+			}
+			var name = entry.Name
+			if entry.Link != "" {
+				name += " -> " + entry.Link
+			}
+			fmt.Fprintf(&sb, "%s  %s  %d  %s\n", sum, entry.Mode, entry.Size, name)
+		}
+		content = []byte(sb.String())
+	}
+	var path = archive.Path + ".manifest." + format
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write archive manifest %s: %s", path, err.Error())
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   artifact.ArchiveManifest,
+		Name:   archive.Name + ".manifest." + format,
+		Path:   path,
+		Goos:   archive.Goos,
+		Goarch: archive.Goarch,
+		Goarm:  archive.Goarm,
+		Extra: map[string]interface{}{
+			"ID":     archive.ExtraOr("ID", ""),
+			"Format": format,
+		},
+	})
+	return nil
+}
@@ -46,7 +46,17 @@ func (Pipe) String() string {
 // Default sets the pipe defaults
 func (Pipe) Default(ctx *context.Context) error {
 	for i := range ctx.Config.Artifactories {
-		ctx.Config.Artifactories[i].ChecksumHeader = "X-Checksum-SHA256"
+		var artifactory = &ctx.Config.Artifactories[i]
+		if artifactory.ChecksumHeader == "" {
+			artifactory.ChecksumHeader = "X-Checksum-SHA256"
+		}
+		// artifactory verifies the deployed files against these
+		if artifactory.ChecksumHeaders == nil {
+			artifactory.ChecksumHeaders = map[string]string{
+				"X-Checksum-Sha1": "sha1",
+				"X-Checksum":      "md5",
+			}
+		}
 	}
 	return http.Defaults(ctx.Config.Artifactories)
 }
@@ -64,7 +74,7 @@ func (Pipe) Publish(ctx *context.Context) error {
 	for _, instance := range ctx.Config.Artifactories {
 		instance := instance
 		if skip := http.CheckConfig(ctx, &instance, "artifactory"); skip != nil {
-			return pipe.Skip(skip.Error())
+			return skip
 		}
 	}
 
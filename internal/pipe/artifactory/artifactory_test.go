@@ -787,4 +787,25 @@ func TestDefaultSet(t *testing.T) {
 	var artifactory = ctx.Config.Artifactories[0]
 	assert.Equal(t, "custom", artifactory.Mode)
 	assert.Equal(t, "X-Checksum-SHA256", artifactory.ChecksumHeader)
+	assert.Equal(t, map[string]string{
+		"X-Checksum-Sha1": "sha1",
+		"X-Checksum":      "md5",
+	}, artifactory.ChecksumHeaders)
+}
+
+func TestDefaultKeepsChecksumHeaders(t *testing.T) {
+	var ctx = &context.Context{
+		Config: config.Project{
+			Artifactories: []config.Put{
+				{
+					ChecksumHeader:  "X-Sha256",
+					ChecksumHeaders: map[string]string{},
+				},
+			},
+		},
+	}
+	assert.NoError(t, Pipe{}.Default(ctx))
+	var artifactory = ctx.Config.Artifactories[0]
+	assert.Equal(t, "X-Sha256", artifactory.ChecksumHeader)
+	assert.Empty(t, artifactory.ChecksumHeaders)
 }
@@ -0,0 +1,370 @@
+// Package aur provides a Pipe that generates a PKGBUILD and a .SRCINFO for the
+// released archives and pushes them to an AUR (Arch User Repository) package.
+package aur
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoArchivesFound happens when 0 archives are found
+var ErrNoArchivesFound = errors.New("no linux archives found")
+
+// ErrMultipleArchivesSameArch happens when the config yields multiple
+// archives for the same arch.
+var ErrMultipleArchivesSameArch = errors.New("one aur package can handle only one archive of an arch. Consider using ids in the aur section")
+
+// Pipe for AUR deployment
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "arch user repository"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.AURs {
+		var aur = &ctx.Config.AURs[i]
+		if aur.Name == "" {
+			aur.Name = ctx.Config.ProjectName + "-bin"
+		}
+		if aur.Description == "" {
+			aur.Description = ctx.Config.Metadata.Description
+		}
+		if aur.Homepage == "" {
+			aur.Homepage = ctx.Config.Metadata.Homepage
+		}
+		if aur.License == "" {
+			aur.License = ctx.Config.Metadata.License
+		}
+		if len(aur.Maintainers) == 0 {
+			aur.Maintainers = ctx.Config.Metadata.Maintainers
+		}
+		if len(aur.Provides) == 0 {
+			aur.Provides = []string{ctx.Config.ProjectName}
+		}
+		if len(aur.Conflicts) == 0 {
+			aur.Conflicts = []string{ctx.Config.ProjectName}
+		}
+		if aur.Rel == "" {
+			aur.Rel = "1"
+		}
+		if aur.CommitAuthor.Name == "" {
+			aur.CommitAuthor.Name = "goreleaserbot"
+		}
+		if aur.CommitAuthor.Email == "" {
+			aur.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+		}
+	}
+	return nil
+}
+
+// Publish the AUR packages
+func (Pipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.AURs) == 0 {
+		return pipe.Skip("aur section is not configured")
+	}
+	for _, aur := range ctx.Config.AURs {
+		ok, err := condition.Check(ctx, aur.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("aur", aur.Name).Info("skipped because its condition is false")
+			continue
+		}
+		if err := doRun(ctx, aur); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, aur config.AUR) error {
+	if aur.GitURL == "" {
+		return pipe.Skip("aur.git_url is not set")
+	}
+
+	var filters = []artifact.Filter{
+		artifact.ByGoos("linux"),
+		artifact.ByFormats("tar.gz", "tar.xz", "zip"),
+		artifact.ByType(artifact.UploadableArchive),
+	}
+	if len(aur.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(aur.IDs...))
+	}
+	var archives []*artifact.Artifact
+	for _, archive := range ctx.Artifacts.Filter(artifact.And(filters...)).List() {
+		if archFor(archive) != "" {
+			archives = append(archives, archive)
+		}
+	}
+	if len(archives) == 0 {
+		return ErrNoArchivesFound
+	}
+
+	data, err := dataFor(ctx, aur, archives)
+	if err != nil {
+		return err
+	}
+	var dir = filepath.Join(ctx.Config.Dist, "aur", aur.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for name, tpl := range map[string]string{
+		"PKGBUILD": pkgBuildTemplate,
+		".SRCINFO": srcInfoTemplate,
+	} {
+		content, err := build(ctx, name, tpl, data)
+		if err != nil {
+			return err
+		}
+		var path = filepath.Join(dir, name)
+		log.WithField("file", path).Info("writing")
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	if strings.TrimSpace(aur.SkipUpload) == "true" {
+		return pipe.Skip("aur.skip_upload is set")
+	}
+	if ctx.SkipPublish {
+		return pipe.ErrSkipPublishEnabled
+	}
+	if ctx.Config.Release.Draft {
+		return pipe.Skip("release is marked as draft")
+	}
+	if ctx.Config.Release.Disable {
+		return pipe.Skip("release is disabled")
+	}
+	if strings.TrimSpace(aur.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping aur publish")
+	}
+	return push(ctx, aur, data, dir)
+}
+
+func build(ctx *context.Context, name, tpl string, data templateData) (string, error) {
+	t, err := template.New(name).Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return tmpl.New(ctx).Apply(out.String())
+}
+
+func dataFor(ctx *context.Context, cfg config.AUR, archives []*artifact.Artifact) (templateData, error) {
+	var result = templateData{
+		Name:         cfg.Name,
+		Desc:         cfg.Description,
+		Homepage:     cfg.Homepage,
+		Version:      pkgVer(ctx.Version),
+		Rel:          cfg.Rel,
+		License:      cfg.License,
+		Maintainers:  cfg.Maintainers,
+		Contributors: cfg.Contributors,
+		Provides:     cfg.Provides,
+		Conflicts:    cfg.Conflicts,
+		Depends:      cfg.Depends,
+		OptDepends:   cfg.OptDepends,
+		Package:      split(cfg.Package),
+	}
+	if len(result.Package) == 0 {
+		result.Package = defaultPackage(archives[0])
+	}
+
+	if cfg.URLTemplate == "" {
+		urlTemplate, err := client.DownloadURLTemplate(ctx)
+		if err != nil {
+			return result, err
+		}
+		cfg.URLTemplate = urlTemplate
+	}
+
+	var seen = map[string]bool{}
+	for _, archive := range archives {
+		var arch = archFor(archive)
+		if seen[arch] {
+			return result, ErrMultipleArchivesSameArch
+		}
+		seen[arch] = true
+		sum, err := archive.Checksum("sha256")
+		if err != nil {
+			return result, err
+		}
+		url, err := tmpl.New(ctx).WithArtifact(archive, map[string]string{}).Apply(cfg.URLTemplate)
+		if err != nil {
+			return result, err
+		}
+		result.Arches = append(result.Arches, arch)
+		result.Sources = append(result.Sources, source{
+			Arch:        arch,
+			DownloadURL: url,
+			SHA256:      sum,
+		})
+	}
+	sort.Strings(result.Arches)
+	sort.Slice(result.Sources, func(i, j int) bool {
+		return result.Sources[i].Arch < result.Sources[j].Arch
+	})
+	return result, nil
+}
+
+// archFor returns the arch linux name of the arch of the archive, or an empty
+// string if arch linux doesn't support it
+func archFor(archive *artifact.Artifact) string {
+	switch archive.Goarch + archive.Goarm {
+	case "amd64":
+		return "x86_64"
+	case "386":
+		return "i686"
+	case "arm64":
+		return "aarch64"
+	case "arm6":
+		return "armv6h"
+	case "arm7":
+		return "armv7h"
+	}
+	return ""
+}
+
+// pkgVer returns the version in a format accepted by pkgver, which can't have
+// dashes
+func pkgVer(version string) string {
+	return strings.Replace(version, "-", "_", -1)
+}
+
+// defaultPackage installs the binaries of the archive in /usr/bin
+func defaultPackage(archive *artifact.Artifact) []string {
+	var wrap = archive.ExtraOr("WrappedIn", "").(string)
+	var lines []string
+	for _, bin := range archive.ExtraOr("Builds", []*artifact.Artifact{}).([]*artifact.Artifact) {
+		lines = append(lines, fmt.Sprintf(
+			`install -Dm755 "./%s" "${pkgdir}/usr/bin/%s"`,
+			path.Join(wrap, bin.Name),
+			bin.Name,
+		))
+	}
+	return lines
+}
+
+func split(s string) []string {
+	strings := strings.Split(strings.TrimSpace(s), "\n")
+	if len(strings) == 1 && strings[0] == "" {
+		return []string{}
+	}
+	return strings
+}
+
+// push clones the AUR repository, updates its PKGBUILD and .SRCINFO with the
+// ones in dir and pushes them
+func push(ctx *context.Context, aur config.AUR, data templateData, dir string) error {
+	auth, cleanup, err := gitAuth(ctx, aur)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	clone, err := ioutil.TempDir("", "goreleaser-aur")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(clone) // nolint: errcheck
+
+	log.WithField("repo", aur.GitURL).Info("cloning")
+	if _, err := git.Clean(git.RunWithAuth(auth, "clone", aur.GitURL, clone)); err != nil {
+		return errors.Wrapf(err, "failed to clone %s", aur.GitURL)
+	}
+	for _, name := range []string{"PKGBUILD", ".SRCINFO"} {
+		bts, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(clone, name), bts, 0644); err != nil {
+			return err
+		}
+	}
+	status, err := git.Run("-C", clone, "status", "--porcelain")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(status) == "" {
+		log.WithField("repo", aur.GitURL).Info("already up to date")
+		return nil
+	}
+
+	var msg = fmt.Sprintf("Update to %s-%s", data.Version, data.Rel)
+	if _, err := git.Clean(git.Run("-C", clone, "add", "PKGBUILD", ".SRCINFO")); err != nil {
+		return errors.Wrap(err, "failed to add the aur files")
+	}
+	if _, err := git.Clean(git.Run(
+		"-C", clone,
+		"-c", "user.name="+aur.CommitAuthor.Name,
+		"-c", "user.email="+aur.CommitAuthor.Email,
+		"commit", "-m", msg,
+	)); err != nil {
+		return errors.Wrap(err, "failed to commit the aur files")
+	}
+	log.WithField("repo", aur.GitURL).Info("pushing")
+	if _, err := git.Clean(git.RunWithAuth(auth, "-C", clone, "push", "origin", "HEAD:master")); err != nil {
+		return errors.Wrapf(err, "failed to push to %s", aur.GitURL)
+	}
+	return nil
+}
+
+// gitAuth returns the auth used to talk to the AUR. The private_key, either a
+// path or the key itself, is given to ssh unless the aur has its own
+// ssh_command.
+func gitAuth(ctx *context.Context, aur config.AUR) (config.GitAuth, func(), error) {
+	var auth = git.MergeAuth(ctx.Config.Git.Auth, aur.Auth)
+	var cleanup = func() {}
+	if aur.PrivateKey == "" || aur.Auth.SSHCommand != "" {
+		return auth, cleanup, nil
+	}
+	key, err := tmpl.New(ctx).Apply(aur.PrivateKey)
+	if err != nil {
+		return auth, cleanup, errors.Wrap(err, "failed to template the aur private_key")
+	}
+	var path = key
+	if _, err := os.Stat(key); err != nil {
+		f, err := ioutil.TempFile("", "goreleaser-aur-key")
+		if err != nil {
+			return auth, cleanup, err
+		}
+		defer f.Close() // nolint: errcheck
+		cleanup = func() {
+			_ = os.Remove(f.Name())
+		}
+		if _, err := f.WriteString(strings.TrimSpace(key) + "\n"); err != nil {
+			cleanup()
+			return auth, func() {}, err
+		}
+		path = f.Name()
+	}
+	auth.SSHCommand = fmt.Sprintf(
+		"ssh -i %s -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new -F /dev/null",
+		path,
+	)
+	return auth, cleanup, nil
+}
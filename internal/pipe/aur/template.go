@@ -0,0 +1,89 @@
+package aur
+
+type templateData struct {
+	Name         string
+	Desc         string
+	Homepage     string
+	Version      string
+	Rel          string
+	License      string
+	Maintainers  []string
+	Contributors []string
+	Provides     []string
+	Conflicts    []string
+	Depends      []string
+	OptDepends   []string
+	Arches       []string
+	Sources      []source
+	Package      []string
+}
+
+type source struct {
+	Arch        string
+	DownloadURL string
+	SHA256      string
+}
+
+const pkgBuildTemplate = `# This file was generated by GoReleaser. DO NOT EDIT.
+{{- range .Maintainers }}
+# Maintainer: {{ . }}
+{{- end }}
+{{- range .Contributors }}
+# Contributor: {{ . }}
+{{- end }}
+
+pkgname='{{ .Name }}'
+pkgver={{ .Version }}
+pkgrel={{ .Rel }}
+pkgdesc='{{ .Desc }}'
+url='{{ .Homepage }}'
+arch=({{ range $i, $arch := .Arches }}{{ if $i }} {{ end }}'{{ $arch }}'{{ end }})
+license=('{{ .License }}')
+provides=({{ range $i, $p := .Provides }}{{ if $i }} {{ end }}'{{ $p }}'{{ end }})
+conflicts=({{ range $i, $c := .Conflicts }}{{ if $i }} {{ end }}'{{ $c }}'{{ end }})
+{{- if .Depends }}
+depends=({{ range $i, $d := .Depends }}{{ if $i }} {{ end }}'{{ $d }}'{{ end }})
+{{- end }}
+{{- if .OptDepends }}
+optdepends=({{ range $i, $d := .OptDepends }}{{ if $i }} {{ end }}'{{ $d }}'{{ end }})
+{{- end }}
+{{ range .Sources }}
+source_{{ .Arch }}=('{{ .DownloadURL }}')
+sha256sums_{{ .Arch }}=('{{ .SHA256 }}')
+{{- end }}
+
+package() {
+{{- range .Package }}
+  {{ . }}
+{{- end }}
+}
+`
+
+const srcInfoTemplate = `pkgbase = {{ .Name }}
+	pkgdesc = {{ .Desc }}
+	pkgver = {{ .Version }}
+	pkgrel = {{ .Rel }}
+	url = {{ .Homepage }}
+{{- range .Arches }}
+	arch = {{ . }}
+{{- end }}
+	license = {{ .License }}
+{{- range .Provides }}
+	provides = {{ . }}
+{{- end }}
+{{- range .Conflicts }}
+	conflicts = {{ . }}
+{{- end }}
+{{- range .Depends }}
+	depends = {{ . }}
+{{- end }}
+{{- range .OptDepends }}
+	optdepends = {{ . }}
+{{- end }}
+{{- range .Sources }}
+	source_{{ .Arch }} = {{ .DownloadURL }}
+	sha256sums_{{ .Arch }} = {{ .SHA256 }}
+{{- end }}
+
+pkgname = {{ .Name }}
+`
@@ -0,0 +1,222 @@
+// Package authenticode provides a Pipe that signs the windows binaries with
+// an Authenticode certificate, before they are archived.
+package authenticode
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoCertificate happens when authenticode is enabled without a certificate
+var ErrNoCertificate = errors.New("authenticode.certificate is required when authenticode is enabled")
+
+// ErrSigntoolPassword happens when signtool would need the password on its
+// command line, where any user can read it
+var ErrSigntoolPassword = errors.New("authenticode: signtool only reads the certificate password from the command line, import the certificate in the certificate store or use osslsigncode instead")
+
+// Pipe for authenticode signing
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "signing windows binaries"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	var cfg = &ctx.Config.Authenticode
+	if cfg.Cmd == "" {
+		cfg.Cmd = "osslsigncode"
+	}
+	return nil
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	if err := check(ctx); err != nil {
+		return err
+	}
+	var cfg = ctx.Config.Authenticode
+	var filters = []artifact.Filter{
+		artifact.ByType(artifact.Binary),
+		artifact.ByGoos("windows"),
+	}
+	if len(cfg.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(cfg.IDs...))
+	}
+	var g = semerrgroup.New(ctx.Parallelism)
+	for _, binary := range ctx.Artifacts.Filter(artifact.And(filters...)).List() {
+		binary := binary
+		g.Go(func() error {
+			return sign(ctx, cfg, binary)
+		})
+	}
+	return g.Wait()
+}
+
+// Sign signs the given windows artifact in place, e.g. an installer built
+// from the signed binaries. It does nothing when authenticode is not enabled,
+// signing is skipped or the condition is false.
+func Sign(ctx *context.Context, a *artifact.Artifact) error {
+	if err := check(ctx); err != nil {
+		if pipe.IsSkip(err) {
+			return nil
+		}
+		return err
+	}
+	return sign(ctx, ctx.Config.Authenticode, a)
+}
+
+// check returns a skip error when the artifacts should not be signed.
+func check(ctx *context.Context) error {
+	var cfg = ctx.Config.Authenticode
+	if !cfg.Enabled {
+		return pipe.Skip("authenticode is not enabled")
+	}
+	if ctx.SkipSign {
+		return pipe.ErrSkipSignEnabled
+	}
+	if cfg.Certificate == "" {
+		return ErrNoCertificate
+	}
+	ok, err := condition.Check(ctx, cfg.If)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return pipe.Skip("authenticode condition is false")
+	}
+	return nil
+}
+
+// sign signs the binary in place. Tools that can't, like osslsigncode,
+// write the signed binary to ${signed}, which then replaces it.
+func sign(ctx *context.Context, cfg config.Authenticode, binary *artifact.Artifact) error {
+	var template = tmpl.New(ctx).WithArtifact(binary, map[string]string{})
+	var vars = map[string]string{
+		"artifact": binary.Path,
+		"signed":   binary.Path + ".signed",
+	}
+	for key, value := range map[string]string{
+		"certificate":   cfg.Certificate,
+		"key":           cfg.Key,
+		"password":      cfg.Password,
+		"description":   cfg.Description,
+		"timestamp_url": cfg.TimestampURL,
+	} {
+		s, err := template.Apply(value)
+		if err != nil {
+			return errors.Wrapf(err, "authenticode: failed to execute %s template", key)
+		}
+		vars[key] = s
+	}
+	if vars["password"] != "" {
+		path, err := passwordFile(vars["password"])
+		if err != nil {
+			return errors.Wrap(err, "authenticode: failed to write the password file")
+		}
+		defer os.Remove(path) // nolint: errcheck
+		vars["password_file"] = path
+	}
+	var args = cfg.Args
+	if len(args) == 0 {
+		defaults, err := defaultArgs(cfg.Cmd, vars)
+		if err != nil {
+			return err
+		}
+		args = defaults
+	}
+	// nolint: prealloc
+	var expanded []string
+	for _, arg := range args {
+		expanded = append(expanded, os.Expand(arg, func(key string) string {
+			return vars[key]
+		}))
+	}
+
+	log.WithField("binary", binary.Path).Info("signing")
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, cfg.Cmd, expanded...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("authenticode: %s failed with %q", cfg.Cmd, string(out))
+	}
+	if _, err := os.Stat(vars["signed"]); err == nil {
+		return replace(vars["signed"], binary.Path)
+	}
+	return nil
+}
+
+// passwordFile writes the password to a file only readable by the current
+// user, so it isn't passed on the command line.
+func passwordFile(password string) (string, error) {
+	f, err := ioutil.TempFile("", "authenticode")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(password); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// defaultArgs returns the arguments for signtool and osslsigncode, skipping
+// the options that aren't set.
+func defaultArgs(cmd string, vars map[string]string) ([]string, error) {
+	if cmd == "signtool" || cmd == "signtool.exe" {
+		if vars["password"] != "" {
+			return nil, ErrSigntoolPassword
+		}
+		var args = []string{"sign", "/fd", "sha256", "/f", "${certificate}"}
+		if vars["description"] != "" {
+			args = append(args, "/d", "${description}")
+		}
+		if vars["timestamp_url"] != "" {
+			args = append(args, "/tr", "${timestamp_url}", "/td", "sha256")
+		}
+		return append(args, "${artifact}"), nil
+	}
+	var args = []string{"sign", "-h", "sha256"}
+	if vars["key"] != "" {
+		args = append(args, "-certs", "${certificate}", "-key", "${key}")
+	} else {
+		args = append(args, "-pkcs12", "${certificate}")
+	}
+	if vars["password"] != "" {
+		args = append(args, "-readpass", "${password_file}")
+	}
+	if vars["description"] != "" {
+		args = append(args, "-n", "${description}")
+	}
+	if vars["timestamp_url"] != "" {
+		args = append(args, "-ts", "${timestamp_url}")
+	}
+	return append(args, "-in", "${artifact}", "-out", "${signed}"), nil
+}
+
+// replace moves the signed binary over the original one, keeping its
+// permissions.
+func replace(signed, binary string) error {
+	info, err := os.Stat(binary)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(signed, info.Mode()); err != nil {
+		return err
+	}
+	if err := os.Rename(signed, binary); err != nil {
+		return errors.Wrap(err, "authenticode: failed to replace binary")
+	}
+	return nil
+}
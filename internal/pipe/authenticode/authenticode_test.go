@@ -0,0 +1,231 @@
+package authenticode
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "osslsigncode", ctx.Config.Authenticode.Cmd)
+}
+
+func TestSkipNotEnabled(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+func TestSkipSign(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Authenticode: config.Authenticode{Enabled: true},
+	})
+	ctx.SkipSign = true
+	require.Equal(t, pipe.ErrSkipSignEnabled, Pipe{}.Run(ctx))
+}
+
+func TestSkipCondition(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Authenticode: config.Authenticode{
+			Enabled:     true,
+			Certificate: "cert.p12",
+			If:          `{{ eq .Env.SIGN "true" }}`,
+		},
+	})
+	ctx.Env = map[string]string{"SIGN": "false"}
+	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+}
+
+func TestNoCertificate(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Authenticode: config.Authenticode{Enabled: true},
+	})
+	require.Equal(t, ErrNoCertificate, Pipe{}.Run(ctx))
+}
+
+func TestDefaultArgs(t *testing.T) {
+	args, err := defaultArgs("osslsigncode", map[string]string{})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"sign", "-h", "sha256", "-pkcs12", "${certificate}",
+		"-in", "${artifact}", "-out", "${signed}",
+	}, args)
+
+	args, err = defaultArgs("osslsigncode", map[string]string{
+		"key":           "key.pem",
+		"password":      "secret",
+		"description":   "foo",
+		"timestamp_url": "http://timestamp.digicert.com",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"sign", "-h", "sha256", "-certs", "${certificate}", "-key", "${key}",
+		"-readpass", "${password_file}", "-n", "${description}", "-ts", "${timestamp_url}",
+		"-in", "${artifact}", "-out", "${signed}",
+	}, args)
+
+	args, err = defaultArgs("signtool.exe", map[string]string{
+		"timestamp_url": "http://timestamp.digicert.com",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"sign", "/fd", "sha256", "/f", "${certificate}",
+		"/tr", "${timestamp_url}", "/td", "sha256", "${artifact}",
+	}, args)
+
+	_, err = defaultArgs("signtool", map[string]string{"password": "secret"})
+	require.Equal(t, ErrSigntoolPassword, err)
+}
+
+// fakeOsslsigncode puts a fake osslsigncode in the PATH, which writes the
+// input file and its arguments to the output file.
+func fakeOsslsigncode(t *testing.T, folder string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"osslsigncode": "while [ \"$1\" != \"-in\" ]; do shift; done\n{ cat \"$2\"; echo \" signed\"; } > \"$4\"\n",
+	})
+}
+
+func TestRunPipe(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeOsslsigncode(t, folder)()
+
+	var ctx = context.New(config.Project{
+		Authenticode: config.Authenticode{
+			Enabled:     true,
+			IDs:         []string{"foo"},
+			Certificate: "{{ .Env.CERT }}",
+		},
+	})
+	ctx.Env = map[string]string{"CERT": "cert.p12"}
+	require.NoError(t, Pipe{}.Default(ctx))
+	for _, a := range []struct {
+		id, goos, name string
+	}{
+		{"foo", "windows", "foo.exe"},
+		{"bar", "windows", "bar.exe"},
+		{"foo", "linux", "foo"},
+	} {
+		var path = filepath.Join(folder, a.goos+"_"+a.name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(a.name), 0755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:  a.name,
+			Path:  path,
+			Goos:  a.goos,
+			Type:  artifact.Binary,
+			Extra: map[string]interface{}{"ID": a.id},
+		})
+	}
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	for path, content := range map[string]string{
+		"windows_foo.exe": "foo.exe signed\n",
+		"windows_bar.exe": "bar.exe",
+		"linux_foo":       "foo",
+	} {
+		bts, err := ioutil.ReadFile(filepath.Join(folder, path))
+		require.NoError(t, err)
+		require.Equal(t, content, string(bts), path)
+	}
+	info, err := os.Stat(filepath.Join(folder, "windows_foo.exe"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0755), info.Mode())
+	_, err = os.Stat(filepath.Join(folder, "windows_foo.exe.signed"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestRunPipePasswordFile(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+
+	var ctx = context.New(config.Project{
+		Authenticode: config.Authenticode{
+			Enabled:     true,
+			Cmd:         "sh",
+			Certificate: "cert.p12",
+			Password:    "{{ .Env.PASSWORD }}",
+			Args:        []string{"-c", "cat ${password_file} > pass.txt && echo ${password_file} > path.txt"},
+		},
+	})
+	ctx.Env = map[string]string{"PASSWORD": "secret"}
+	var path = filepath.Join(folder, "foo.exe")
+	require.NoError(t, ioutil.WriteFile(path, []byte("foo"), 0755))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "foo.exe",
+		Path: path,
+		Goos: "windows",
+		Type: artifact.Binary,
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	bts, err := ioutil.ReadFile("pass.txt")
+	require.NoError(t, err)
+	require.Equal(t, "secret", string(bts))
+	bts, err = ioutil.ReadFile("path.txt")
+	require.NoError(t, err)
+	require.NoFileExists(t, strings.TrimSpace(string(bts)))
+}
+
+func TestRunPipeFailed(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+
+	var ctx = context.New(config.Project{
+		Authenticode: config.Authenticode{
+			Enabled:     true,
+			Cmd:         "false",
+			Certificate: "cert.p12",
+		},
+	})
+	var path = filepath.Join(folder, "foo.exe")
+	require.NoError(t, ioutil.WriteFile(path, []byte("foo"), 0755))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "foo.exe",
+		Path: path,
+		Goos: "windows",
+		Type: artifact.Binary,
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), `authenticode: false failed with ""`)
+}
+
+func TestSign(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeOsslsigncode(t, folder)()
+
+	var path = filepath.Join(folder, "foo.msi")
+	var msi = &artifact.Artifact{Name: "foo.msi", Path: path, Goos: "windows"}
+	for name, tt := range map[string]struct {
+		cfg      config.Authenticode
+		skipSign bool
+		content  string
+	}{
+		"disabled":  {cfg: config.Authenticode{Certificate: "cert.p12"}, content: "msi"},
+		"skip sign": {cfg: config.Authenticode{Enabled: true, Certificate: "cert.p12"}, skipSign: true, content: "msi"},
+		"condition": {cfg: config.Authenticode{Enabled: true, Certificate: "cert.p12", If: "false"}, content: "msi"},
+		"enabled":   {cfg: config.Authenticode{Enabled: true, Certificate: "cert.p12"}, content: "msi signed\n"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, ioutil.WriteFile(path, []byte("msi"), 0644))
+			var ctx = context.New(config.Project{Authenticode: tt.cfg})
+			ctx.SkipSign = tt.skipSign
+			require.NoError(t, Pipe{}.Default(ctx))
+			require.NoError(t, Sign(ctx, msi))
+			bts, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, tt.content, string(bts))
+		})
+	}
+
+	var ctx = context.New(config.Project{Authenticode: config.Authenticode{Enabled: true}})
+	require.Equal(t, ErrNoCertificate, Sign(ctx, msi))
+}
@@ -21,9 +21,15 @@ func (Pipe) String() string {
 
 // Run executes the hooks
 func (Pipe) Run(ctx *context.Context) error {
+	return RunHooks(ctx, ctx.Config.Before.Hooks)
+}
+
+// RunHooks templates and executes the given hooks, in order, stopping at the
+// first one that fails
+func RunHooks(ctx *context.Context, hooks []string) error {
 	var tmpl = tmpl.New(ctx)
 	/* #nosec */
-	for _, step := range ctx.Config.Before.Hooks {
+	for _, step := range hooks {
 		s, err := tmpl.Apply(step)
 		if err != nil {
 			return err
@@ -3,8 +3,11 @@ package blob
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/condition"
 	"github.com/goreleaser/goreleaser/internal/deprecate"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
@@ -23,7 +26,7 @@ func (Pipe) String() string {
 // Default sets the pipe defaults
 func (Pipe) Default(ctx *context.Context) error {
 	if len(ctx.Config.Blob) > 0 {
-		deprecate.Notice("blob")
+		deprecate.Notice(ctx, "blob")
 		ctx.Config.Blobs = append(ctx.Config.Blobs, ctx.Config.Blob...)
 	}
 	for i := range ctx.Config.Blobs {
@@ -49,6 +52,14 @@ func (Pipe) Publish(ctx *context.Context) error {
 	var g = semerrgroup.New(ctx.Parallelism)
 	for _, conf := range ctx.Config.Blobs {
 		conf := conf
+		ok, err := condition.Check(ctx, conf.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("bucket", conf.Bucket).Info("skipped because its condition is false")
+			continue
+		}
 		template := tmpl.New(ctx)
 		folder, err := template.Apply(conf.Folder)
 		if err != nil {
@@ -61,6 +72,43 @@ func (Pipe) Publish(ctx *context.Context) error {
 	return g.Wait()
 }
 
+// Upload describes the files uploaded to a bucket
+type Upload struct {
+	URL  string   `json:"url"`
+	Keys []string `json:"keys"`
+}
+
+// Uploads returns the files the pipe uploads (or uploaded) to each
+// configured bucket
+func Uploads(ctx *context.Context) ([]Upload, error) {
+	var uploads []Upload
+	for _, conf := range ctx.Config.Blobs {
+		ok, err := condition.Check(ctx, conf.If)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		folder, err := tmpl.New(ctx).Apply(conf.Folder)
+		if err != nil {
+			return nil, err
+		}
+		bucketURL, err := urlFor(ctx, conf)
+		if err != nil {
+			return nil, err
+		}
+		var upload = Upload{
+			URL: bucketURL,
+		}
+		for _, artifact := range ctx.Artifacts.Filter(filterFor(conf)).List() {
+			upload.Keys = append(upload.Keys, filepath.Join(folder, artifact.Name))
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads, nil
+}
+
 // errorContains check if error contains specific string
 func errorContains(err error, subs ...string) bool {
 	for _, sub := range subs {
@@ -14,6 +14,8 @@ import (
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/stretchr/testify/assert"
+
+	_ "gocloud.dev/blob/fileblob"
 )
 
 func TestDescription(t *testing.T) {
@@ -253,3 +255,143 @@ func unsetEnv(env map[string]string) {
 		os.Unsetenv(k)
 	}
 }
+
+func TestUploads(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "blah",
+		Blobs: []config.Blob{
+			{
+				Provider: "s3",
+				Bucket:   "foo",
+				Folder:   "{{ .ProjectName }}/{{ .Tag }}",
+			},
+			{
+				Provider: "gs",
+				Bucket:   "bar",
+				Folder:   "releases",
+				IDs:      []string{"bar"},
+			},
+		},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "foo.tar.gz",
+		Extra: map[string]interface{}{
+			"ID": "foo",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Checksum,
+		Name: "checksums.txt",
+	})
+	uploads, err := Uploads(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Upload{
+		{
+			URL:  "s3://foo",
+			Keys: []string{"blah/v1.0.0/foo.tar.gz", "blah/v1.0.0/checksums.txt"},
+		},
+		{
+			URL:  "gs://bar",
+			Keys: []string{"releases/checksums.txt"},
+		},
+	}, uploads)
+}
+
+func TestDelete(t *testing.T) {
+	var folder, back = testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "foo"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "foo", "a.tar.gz"), []byte("a"), 0644))
+	require.NoError(t, Delete(context.New(config.Project{}), Upload{
+		URL:  "file://" + folder,
+		Keys: []string{"foo/a.tar.gz", "foo/missing.tar.gz"},
+	}))
+	_, err := os.Stat(filepath.Join(folder, "foo", "a.tar.gz"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestUploadToFileBucket(t *testing.T) {
+	var folder, back = testlib.Mktmp(t)
+	defer back()
+	var bucket = filepath.Join(folder, "bucket")
+	require.NoError(t, os.Mkdir(bucket, 0755))
+	var archive = filepath.Join(folder, "bin.tar.gz")
+	require.NoError(t, ioutil.WriteFile(archive, []byte("fake archive"), 0644))
+	var ctx = context.New(config.Project{})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "bin.tar.gz",
+		Path: archive,
+	})
+	require.NoError(t, Bucket{}.Upload(ctx, config.Blob{
+		Provider: "file",
+		Bucket:   bucket,
+	}, "releases"))
+	bts, err := ioutil.ReadFile(filepath.Join(bucket, "releases", "bin.tar.gz"))
+	require.NoError(t, err)
+	require.Equal(t, "fake archive", string(bts))
+}
+
+func TestURLFor(t *testing.T) {
+	var ctx = context.New(config.Project{ProjectName: "blah"})
+	ctx.Env = map[string]string{"PROVIDER": "gs", "MINIO": "localhost:9000"}
+	for expected, conf := range map[string]config.Blob{
+		"gs://blah-releases": {
+			Provider: "{{ .Env.PROVIDER }}",
+			Bucket:   "{{ .ProjectName }}-releases",
+			Region:   "ignored",
+		},
+		"s3://foo": {
+			Provider: "s3",
+			Bucket:   "foo",
+		},
+		"s3://foo?region=us-west-1": {
+			Provider: "s3",
+			Bucket:   "foo",
+			Region:   "us-west-1",
+		},
+		"s3://foo?disableSSL=true&endpoint=localhost%3A9000&region=us-east-1&s3ForcePathStyle=true": {
+			Provider:   "s3",
+			Bucket:     "foo",
+			Region:     "us-east-1",
+			Endpoint:   "{{ .Env.MINIO }}",
+			DisableSSL: true,
+		},
+	} {
+		t.Run(expected, func(t *testing.T) {
+			url, err := urlFor(ctx, conf)
+			require.NoError(t, err)
+			require.Equal(t, expected, url)
+		})
+	}
+}
+
+func TestURLForInvalidTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	_, err := urlFor(ctx, config.Blob{Provider: "s3", Bucket: "{{ .Env.NOPE }}"})
+	require.Contains(t, err.Error(), "failed to template the bucket")
+	_, err = urlFor(ctx, config.Blob{Provider: "{{ .Nope", Bucket: "foo"})
+	require.Contains(t, err.Error(), "failed to template the provider")
+}
+
+func TestUploadToTemplatedFileBucket(t *testing.T) {
+	var folder, back = testlib.Mktmp(t)
+	defer back()
+	var bucket = filepath.Join(folder, "blah")
+	require.NoError(t, os.Mkdir(bucket, 0755))
+	var archive = filepath.Join(folder, "bin.tar.gz")
+	require.NoError(t, ioutil.WriteFile(archive, []byte("fake archive"), 0644))
+	var ctx = context.New(config.Project{ProjectName: "blah"})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "bin.tar.gz",
+		Path: archive,
+	})
+	require.NoError(t, Bucket{}.Upload(ctx, config.Blob{
+		Provider: "file",
+		Bucket:   filepath.Join(folder, "{{ .ProjectName }}"),
+	}, "releases"))
+	require.FileExists(t, filepath.Join(bucket, "releases", "bin.tar.gz"))
+}
@@ -2,16 +2,21 @@ package blob
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net/url"
+	"os"
 	"path/filepath"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/pkg/errors"
 	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
 	"gocloud.dev/secrets"
 
 	// Import the blob packages we want to be able to open.
@@ -53,7 +58,10 @@ func (b Bucket) Connect(ctx *context.Context, bucketURL string) (*blob.Bucket, e
 // Upload takes connection initilized from newOpenBucket to upload goreleaser artifacts
 // Takes goreleaser context(which includes artificats) and bucketURL for upload destination (gs://gorelease-bucket)
 func (b Bucket) Upload(ctx *context.Context, conf config.Blob, folder string) error {
-	var bucketURL = fmt.Sprintf("%s://%s", conf.Provider, conf.Bucket)
+	bucketURL, err := urlFor(ctx, conf)
+	if err != nil {
+		return err
+	}
 
 	// Get the openbucket connection for specific provider
 	conn, err := b.Connect(ctx, bucketURL)
@@ -62,19 +70,8 @@ func (b Bucket) Upload(ctx *context.Context, conf config.Blob, folder string) er
 	}
 	defer conn.Close()
 
-	var filter = artifact.Or(
-		artifact.ByType(artifact.UploadableArchive),
-		artifact.ByType(artifact.UploadableBinary),
-		artifact.ByType(artifact.Checksum),
-		artifact.ByType(artifact.Signature),
-		artifact.ByType(artifact.LinuxPackage),
-	)
-	if len(conf.IDs) > 0 {
-		filter = artifact.And(filter, artifact.ByIDs(conf.IDs...))
-	}
-
 	var g = semerrgroup.New(ctx.Parallelism)
-	for _, artifact := range ctx.Artifacts.Filter(filter).List() {
+	for _, artifact := range ctx.Artifacts.Filter(filterFor(conf)).List() {
 		artifact := artifact
 		g.Go(func() error {
 			log.WithFields(log.Fields{
@@ -87,12 +84,7 @@ func (b Bucket) Upload(ctx *context.Context, conf config.Blob, folder string) er
 			if err != nil {
 				return errors.Wrap(err, "failed to obtain writer")
 			}
-			data, err := getData(ctx, conf, artifact.Path)
-			if err != nil {
-				return err
-			}
-			_, err = w.Write(data)
-			if err != nil {
+			if err := writeData(ctx, conf, w, artifact.Path); err != nil {
 				switch {
 				case errorContains(err, "NoSuchBucket", "ContainerNotFound", "notFound"):
 					return errors.Wrapf(err, "provided bucket does not exist: %s", bucketURL)
@@ -128,22 +120,98 @@ func (b Bucket) Upload(ctx *context.Context, conf config.Blob, folder string) er
 	return g.Wait()
 }
 
-func getData(ctx *context.Context, conf config.Blob, path string) ([]byte, error) {
-	data, err := ioutil.ReadFile(path)
+// urlFor returns the url of the bucket of the given config, with its provider
+// and bucket templated, and the s3 options as query parameters
+func urlFor(ctx *context.Context, conf config.Blob) (string, error) {
+	var template = tmpl.New(ctx)
+	provider, err := template.Apply(conf.Provider)
 	if err != nil {
-		return data, errors.Wrapf(err, "failed to open file %s", path)
+		return "", errors.Wrap(err, "failed to template the provider")
+	}
+	bucket, err := template.Apply(conf.Bucket)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to template the bucket")
+	}
+	var bucketURL = fmt.Sprintf("%s://%s", provider, bucket)
+	if provider != "s3" {
+		return bucketURL, nil
+	}
+	var query = url.Values{}
+	if conf.Region != "" {
+		query.Set("region", conf.Region)
+	}
+	if conf.Endpoint != "" {
+		endpoint, err := template.Apply(conf.Endpoint)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to template the endpoint")
+		}
+		query.Set("endpoint", endpoint)
+		// s3 compatible storages, like minio, usually don't support
+		// virtual hosted buckets
+		query.Set("s3ForcePathStyle", "true")
+	}
+	if conf.DisableSSL {
+		query.Set("disableSSL", "true")
+	}
+	if len(query) == 0 {
+		return bucketURL, nil
 	}
+	return bucketURL + "?" + query.Encode(), nil
+}
+
+func filterFor(conf config.Blob) artifact.Filter {
+	var filter = artifact.ByUploadable()
+	if len(conf.IDs) > 0 {
+		filter = artifact.And(filter, artifact.ByIDs(conf.IDs...))
+	}
+	return filter
+}
+
+// writeData streams the file at path into w. Files are only loaded into
+// memory when they need to be encrypted with a KMS key.
+func writeData(ctx *context.Context, conf config.Blob, w io.Writer, path string) error {
 	if conf.KMSKey == "" {
-		return data, nil
+		file, err := os.Open(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open file %s", path)
+		}
+		defer file.Close() // nolint: errcheck
+		_, err = io.Copy(w, file)
+		return err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open file %s", path)
 	}
 	keeper, err := secrets.OpenKeeper(ctx, conf.KMSKey)
 	if err != nil {
-		return data, errors.Wrapf(err, "failed to open kms %s", conf.KMSKey)
+		return errors.Wrapf(err, "failed to open kms %s", conf.KMSKey)
 	}
 	defer keeper.Close()
 	data, err = keeper.Encrypt(ctx, data)
 	if err != nil {
-		return data, errors.Wrap(err, "failed to encrypt with kms")
+		return errors.Wrap(err, "failed to encrypt with kms")
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Delete removes the given upload keys from its bucket, ignoring the ones
+// that are already gone
+func Delete(ctx *context.Context, upload Upload) error {
+	conn, err := blob.OpenBucket(ctx, upload.URL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	for _, key := range upload.Keys {
+		log.WithFields(log.Fields{
+			"provider": upload.URL,
+			"key":      key,
+		}).Info("deleting")
+		if err := conn.Delete(ctx, key); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+			return errors.Wrapf(err, "failed to delete %s from %s", key, upload.URL)
+		}
 	}
-	return data, err
+	return nil
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/condition"
 	"github.com/goreleaser/goreleaser/internal/deprecate"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
@@ -28,9 +29,6 @@ var ErrNoArchivesFound = errors.New("no linux/macos archives found")
 // for linux or windows.
 var ErrMultipleArchivesSameOS = errors.New("one tap can handle only archive of an OS/Arch combination. Consider using ids in the brew section")
 
-// ErrTokenTypeNotImplementedForBrew indicates that a new token type was not implemented for this pipe
-var ErrTokenTypeNotImplementedForBrew = errors.New("token type not implemented for brew pipe")
-
 // Pipe for brew deployment
 type Pipe struct{}
 
@@ -45,6 +43,14 @@ func (Pipe) Publish(ctx *context.Context) error {
 		return err
 	}
 	for _, brew := range ctx.Config.Brews {
+		ok, err := condition.Check(ctx, brew.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("brew", brew.Name).Info("skipped because its condition is false")
+			continue
+		}
 		if err := doRun(ctx, brew, client); err != nil {
 			return err
 		}
@@ -57,7 +63,7 @@ func (Pipe) Default(ctx *context.Context) error {
 	if len(ctx.Config.Brews) == 0 {
 		ctx.Config.Brews = append(ctx.Config.Brews, ctx.Config.Brew)
 		if !reflect.DeepEqual(ctx.Config.Brew, config.Homebrew{}) {
-			deprecate.Notice("brew")
+			deprecate.Notice(ctx, "brew")
 		}
 	}
 	for i := range ctx.Config.Brews {
@@ -78,6 +84,12 @@ func (Pipe) Default(ctx *context.Context) error {
 			brew.Install = strings.Join(installs, "\n")
 			log.Warnf("optimistically guessing `brew[%d].installs`, double check", i)
 		}
+		if brew.Description == "" {
+			brew.Description = ctx.Config.Metadata.Description
+		}
+		if brew.Homepage == "" {
+			brew.Homepage = ctx.Config.Metadata.Homepage
+		}
 		if brew.CommitAuthor.Name == "" {
 			brew.CommitAuthor.Name = "goreleaserbot"
 		}
@@ -96,12 +108,24 @@ func (Pipe) Default(ctx *context.Context) error {
 }
 
 func isBrewBuild(build config.Build) bool {
+	if !contains(build.Goos, "darwin") {
+		return false
+	}
+	for _, goarch := range []string{"amd64", "arm64"} {
+		if contains(build.Goarch, goarch) && !isIgnored(build, goarch) {
+			return true
+		}
+	}
+	return false
+}
+
+func isIgnored(build config.Build, goarch string) bool {
 	for _, ignore := range build.Ignore {
-		if ignore.Goos == "darwin" && ignore.Goarch == "amd64" {
-			return false
+		if ignore.Goos == "darwin" && ignore.Goarch == goarch {
+			return true
 		}
 	}
-	return contains(build.Goos, "darwin") && contains(build.Goarch, "amd64")
+	return false
 }
 
 func contains(ss []string, s string) bool {
@@ -113,8 +137,8 @@ func contains(ss []string, s string) bool {
 	return false
 }
 
-func doRun(ctx *context.Context, brew config.Homebrew, client client.Client) error {
-	if brew.GitHub.Name == "" && brew.GitLab.Name == "" {
+func doRun(ctx *context.Context, brew config.Homebrew, cli client.Client) error {
+	if brew.GitHub.Name == "" && brew.GitLab.Name == "" && brew.Gitea.Name == "" {
 		return pipe.Skip("brew section is not configured")
 	}
 
@@ -124,10 +148,11 @@ func doRun(ctx *context.Context, brew config.Homebrew, client client.Client) err
 			artifact.ByGoos("darwin"),
 			artifact.ByGoos("linux"),
 		),
-		artifact.ByFormats("zip", "tar.gz"),
+		artifact.ByFormats("zip", "tar.gz", "tar.xz"),
 		artifact.Or(
 			artifact.ByGoarch("amd64"),
 			artifact.ByGoarch("arm64"),
+			artifact.ByGoarch("all"),
 			artifact.And(
 				artifact.ByGoarch("arm"),
 				artifact.ByGoarm(brew.Goarm),
@@ -144,7 +169,7 @@ func doRun(ctx *context.Context, brew config.Homebrew, client client.Client) err
 		return ErrNoArchivesFound
 	}
 
-	content, err := buildFormula(ctx, brew, ctx.TokenType, archives)
+	content, err := buildFormula(ctx, brew, archives)
 	if err != nil {
 		return err
 	}
@@ -178,8 +203,10 @@ func doRun(ctx *context.Context, brew config.Homebrew, client client.Client) err
 		repo = brew.GitHub
 	case context.TokenTypeGitLab:
 		repo = brew.GitLab
+	case context.TokenTypeGitea:
+		repo = brew.Gitea
 	default:
-		return ErrTokenTypeNotImplementedForBrew
+		return client.ErrTokenTypeNotImplemented
 	}
 
 	var gpath = buildFormulaPath(brew.Folder, filename)
@@ -188,15 +215,23 @@ func doRun(ctx *context.Context, brew config.Homebrew, client client.Client) err
 		Info("pushing")
 
 	var msg = fmt.Sprintf("Brew formula update for %s version %s", ctx.Config.ProjectName, ctx.Git.CurrentTag)
-	return client.CreateFile(ctx, brew.CommitAuthor, repo, []byte(content), gpath, msg)
+	if err := cli.CreateFile(ctx, brew.CommitAuthor, repo, []byte(content), gpath, msg); err != nil {
+		return err
+	}
+	ctx.CommittedFiles = append(ctx.CommittedFiles, context.CommittedFile{
+		Repo:         repo,
+		Path:         gpath,
+		CommitAuthor: brew.CommitAuthor,
+	})
+	return nil
 }
 
 func buildFormulaPath(folder, filename string) string {
 	return path.Join(folder, filename)
 }
 
-func buildFormula(ctx *context.Context, brew config.Homebrew, tokenType context.TokenType, artifacts []*artifact.Artifact) (string, error) {
-	data, err := dataFor(ctx, brew, tokenType, artifacts)
+func buildFormula(ctx *context.Context, brew config.Homebrew, artifacts []*artifact.Artifact) (string, error) {
+	data, err := dataFor(ctx, brew, artifacts)
 	if err != nil {
 		return "", err
 	}
@@ -215,7 +250,7 @@ func doBuildFormula(ctx *context.Context, data templateData) (string, error) {
 	return tmpl.New(ctx).Apply(out.String())
 }
 
-func dataFor(ctx *context.Context, cfg config.Homebrew, tokenType context.TokenType, artifacts []*artifact.Artifact) (templateData, error) {
+func dataFor(ctx *context.Context, cfg config.Homebrew, artifacts []*artifact.Artifact) (templateData, error) {
 	var result = templateData{
 		Name:             formulaNameFor(cfg.Name),
 		Desc:             cfg.Description,
@@ -225,11 +260,13 @@ func dataFor(ctx *context.Context, cfg config.Homebrew, tokenType context.TokenT
 		Dependencies:     cfg.Dependencies,
 		Conflicts:        cfg.Conflicts,
 		Plist:            cfg.Plist,
+		Service:          split(cfg.Service),
 		Install:          split(cfg.Install),
 		Tests:            split(cfg.Test),
 		DownloadStrategy: cfg.DownloadStrategy,
 		CustomRequire:    cfg.CustomRequire,
 		CustomBlock:      split(cfg.CustomBlock),
+		Head:             cfg.Head,
 	}
 
 	for _, artifact := range artifacts {
@@ -239,24 +276,11 @@ func dataFor(ctx *context.Context, cfg config.Homebrew, tokenType context.TokenT
 		}
 
 		if cfg.URLTemplate == "" {
-			switch tokenType {
-			case context.TokenTypeGitHub:
-				cfg.URLTemplate = fmt.Sprintf(
-					"%s/%s/%s/releases/download/{{ .Tag }}/{{ .ArtifactName }}",
-					ctx.Config.GitHubURLs.Download,
-					ctx.Config.Release.GitHub.Owner,
-					ctx.Config.Release.GitHub.Name,
-				)
-			case context.TokenTypeGitLab:
-				cfg.URLTemplate = fmt.Sprintf(
-					"%s/%s/%s/uploads/{{ .ArtifactUploadHash }}/{{ .ArtifactName }}",
-					ctx.Config.GitLabURLs.Download,
-					ctx.Config.Release.GitLab.Owner,
-					ctx.Config.Release.GitLab.Name,
-				)
-			default:
-				return result, ErrTokenTypeNotImplementedForBrew
+			urlTemplate, err := client.DownloadURLTemplate(ctx)
+			if err != nil {
+				return result, err
 			}
+			cfg.URLTemplate = urlTemplate
 		}
 		url, err := tmpl.New(ctx).WithArtifact(artifact, map[string]string{}).Apply(cfg.URLTemplate)
 		if err != nil {
@@ -267,10 +291,19 @@ func dataFor(ctx *context.Context, cfg config.Homebrew, tokenType context.TokenT
 			SHA256:      sum,
 		}
 		if artifact.Goos == "darwin" {
-			if result.MacOS.DownloadURL != "" {
-				return result, ErrMultipleArchivesSameOS
+			switch artifact.Goarch {
+			case "arm64":
+				if result.MacOSArm64.DownloadURL != "" {
+					return result, ErrMultipleArchivesSameOS
+				}
+				result.MacOSArm64 = down
+			default:
+				// amd64 and universal binaries
+				if result.MacOS.DownloadURL != "" {
+					return result, ErrMultipleArchivesSameOS
+				}
+				result.MacOS = down
 			}
-			result.MacOS = down
 		} else if artifact.Goos == "linux" {
 			switch artifact.Goarch {
 			case "386", "amd64":
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
 	"github.com/goreleaser/goreleaser/internal/testlib"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -68,9 +69,11 @@ func assertDefaultTemplateData(t *testing.T, formulae string) {
 func TestFullFormulae(t *testing.T) {
 	data := defaultTemplateData
 	data.Caveats = []string{"Here are some caveats"}
-	data.Dependencies = []string{"gtk+"}
+	data.Dependencies = []config.HomebrewDependency{{Name: "gtk+"}, {Name: "go", Type: "build"}}
 	data.Conflicts = []string{"svn"}
 	data.Plist = "it works"
+	data.Service = []string{`run [opt_bin/"foo", "serve"]`, "keep_alive true"}
+	data.Head = config.HomebrewHead{URL: "https://github.com/caarlos0/test.git", Branch: "main"}
 	data.CustomBlock = []string{"devel do", `  url "https://github.com/caarlos0/test/releases/download/v0.1.3/test_Darwin_x86_64.tar.gz"`, `  sha256 "1633f61598ab0791e213135923624eb342196b3494909c91899bcd0560f84c68"`, "end"}
 	data.Install = []string{"custom install script", "another install script"}
 	data.Tests = []string{`system "#{bin}/{{.ProjectName}} -version"`}
@@ -163,6 +166,44 @@ func TestRunPipe(t *testing.T) {
 
 			ctx.Config.Brews[0].CustomBlock = `head "https://github.com/caarlos0/test.git"`
 		},
+		"macos_arm64": func(ctx *context.Context) {
+			ctx.TokenType = context.TokenTypeGitHub
+			ctx.Config.GitHubURLs.Download = "https://github.com"
+			ctx.Config.Release.GitHub.Owner = "test"
+			ctx.Config.Release.GitHub.Name = "test"
+			ctx.Config.Brews[0].GitHub.Owner = "test"
+			ctx.Config.Brews[0].GitHub.Name = "test"
+			ctx.Config.Brews[0].Homepage = "https://github.com/goreleaser"
+
+			var path = filepath.Join(ctx.Config.Dist, "bin_arm64.tar.gz")
+			_, err := os.Create(path)
+			assert.NoError(t, err)
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name:   "bin_arm64.tar.gz",
+				Path:   path,
+				Goos:   "darwin",
+				Goarch: "arm64",
+				Type:   artifact.UploadableArchive,
+				Extra: map[string]interface{}{
+					"ID":     "foo",
+					"Format": "tar.gz",
+				},
+			})
+		},
+		"service_and_head": func(ctx *context.Context) {
+			ctx.TokenType = context.TokenTypeGitHub
+			ctx.Config.GitHubURLs.Download = "https://github.com"
+			ctx.Config.Release.GitHub.Owner = "test"
+			ctx.Config.Release.GitHub.Name = "test"
+			ctx.Config.Brews[0].GitHub.Owner = "test"
+			ctx.Config.Brews[0].GitHub.Name = "test"
+			ctx.Config.Brews[0].Homepage = "https://github.com/goreleaser"
+
+			ctx.Config.Brews[0].Plist = ""
+			ctx.Config.Brews[0].Service = "run [opt_bin/\"{{ .ProjectName }}\", \"serve\"]\nkeep_alive true"
+			ctx.Config.Brews[0].Head = config.HomebrewHead{URL: "https://github.com/test/test.git"}
+			ctx.Config.Brews[0].Dependencies = []config.HomebrewDependency{{Name: "zsh"}, {Name: "go", Type: "build"}}
+		},
 		"default_gitlab": func(ctx *context.Context) {
 			ctx.TokenType = context.TokenTypeGitLab
 			ctx.Config.GitLabURLs.Download = "https://gitlab.com"
@@ -183,6 +224,15 @@ func TestRunPipe(t *testing.T) {
 
 			ctx.Config.GitLabURLs.Download = "https://gitlab.my-company.org"
 		},
+		"default_gitea": func(ctx *context.Context) {
+			ctx.TokenType = context.TokenTypeGitea
+			ctx.Config.GiteaURLs.API = "https://gitea.my-company.org/api/v1"
+			ctx.Config.Release.Gitea.Owner = "test"
+			ctx.Config.Release.Gitea.Name = "test"
+			ctx.Config.Brews[0].Gitea.Owner = "test"
+			ctx.Config.Brews[0].Gitea.Name = "test"
+			ctx.Config.Brews[0].Homepage = "https://gitea.my-company.org/goreleaser"
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			folder, err := ioutil.TempDir("", "goreleasertest")
@@ -209,7 +259,7 @@ func TestRunPipe(t *testing.T) {
 							Caveats:      "don't do this {{ .ProjectName }}",
 							Test:         "system \"true\"\nsystem \"#{bin}/foo -h\"",
 							Plist:        `<xml>whatever</xml>`,
-							Dependencies: []string{"zsh", "bash"},
+							Dependencies: []config.HomebrewDependency{{Name: "zsh"}, {Name: "bash"}},
 							Conflicts:    []string{"gtk+", "qt"},
 							Install:      `bin.install "{{ .ProjectName }}"`,
 						},
@@ -298,7 +348,7 @@ func TestRunPipeForMultipleArmVersions(t *testing.T) {
 						Caveats:      "don't do this {{ .ProjectName }}",
 						Test:         "system \"true\"\nsystem \"#{bin}/foo -h\"",
 						Plist:        `<xml>whatever</xml>`,
-						Dependencies: []string{"zsh", "bash"},
+						Dependencies: []config.HomebrewDependency{{Name: "zsh"}, {Name: "bash"}},
 						Conflicts:    []string{"gtk+", "qt"},
 						Install:      `bin.install "{{ .ProjectName }}"`,
 						GitHub: config.Repo{
@@ -454,6 +504,23 @@ func TestRunPipeMultipleArchivesSameOsBuild(t *testing.T) {
 				},
 			},
 		},
+		{
+			expectedError: ErrMultipleArchivesSameOS,
+			osarchs: []struct {
+				goos   string
+				goarch string
+				goarm  string
+			}{
+				{
+					goos:   "darwin",
+					goarch: "arm64",
+				},
+				{
+					goos:   "darwin",
+					goarch: "arm64",
+				},
+			},
+		},
 		{
 			expectedError: ErrMultipleArchivesSameOS,
 			osarchs: []struct {
@@ -698,8 +765,7 @@ func TestRunTokenTypeNotImplementedForBrew(t *testing.T) {
 			"Format": "tar.gz",
 		},
 	})
-	client := &DummyClient{}
-	assert.Equal(t, ErrTokenTypeNotImplementedForBrew, doRun(ctx, ctx.Config.Brews[0], client))
+	assert.Equal(t, client.ErrTokenTypeNotImplemented, doRun(ctx, ctx.Config.Brews[0], &DummyClient{}))
 }
 
 func TestDefault(t *testing.T) {
@@ -740,6 +806,22 @@ func TestDefault(t *testing.T) {
 	assert.Equal(t, `bin.install "foo"`, ctx.Config.Brews[0].Install)
 }
 
+func TestIsBrewBuild(t *testing.T) {
+	assert.True(t, isBrewBuild(config.Build{Goos: []string{"darwin"}, Goarch: []string{"amd64"}}))
+	assert.True(t, isBrewBuild(config.Build{Goos: []string{"darwin"}, Goarch: []string{"arm64"}}))
+	assert.True(t, isBrewBuild(config.Build{
+		Goos:   []string{"darwin"},
+		Goarch: []string{"amd64", "arm64"},
+		Ignore: []config.IgnoredBuild{{Goos: "darwin", Goarch: "amd64"}},
+	}))
+	assert.False(t, isBrewBuild(config.Build{
+		Goos:   []string{"darwin"},
+		Goarch: []string{"arm64"},
+		Ignore: []config.IgnoredBuild{{Goos: "darwin", Goarch: "arm64"}},
+	}))
+	assert.False(t, isBrewBuild(config.Build{Goos: []string{"linux"}, Goarch: []string{"arm64"}}))
+}
+
 func TestGHFolder(t *testing.T) {
 	assert.Equal(t, "bar.rb", buildFormulaPath("", "bar.rb"))
 	assert.Equal(t, "fooo/bar.rb", buildFormulaPath("fooo", "bar.rb"))
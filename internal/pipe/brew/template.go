@@ -1,5 +1,7 @@
 package brew
 
+import "github.com/goreleaser/goreleaser/pkg/config"
+
 type templateData struct {
 	Name             string
 	Desc             string
@@ -7,14 +9,17 @@ type templateData struct {
 	Version          string
 	Caveats          []string
 	Plist            string
+	Service          []string
 	DownloadStrategy string
 	Install          []string
-	Dependencies     []string
+	Dependencies     []config.HomebrewDependency
 	Conflicts        []string
 	Tests            []string
 	CustomRequire    string
 	CustomBlock      []string
+	Head             config.HomebrewHead
 	MacOS            downloadable
+	MacOSArm64       downloadable
 	Linux            downloadable
 	Arm              downloadable
 	Arm64            downloadable
@@ -36,11 +41,24 @@ class {{ .Name }} < Formula
   bottle :unneeded
 
   if OS.mac?
-    {{- if .MacOS.DownloadURL }}
+    {{- if and .MacOS.DownloadURL .MacOSArm64.DownloadURL }}
+    if Hardware::CPU.intel?
+      url "{{ .MacOS.DownloadURL }}"
+      {{- if .DownloadStrategy }}, :using => {{ .DownloadStrategy }}{{- end }}
+      sha256 "{{ .MacOS.SHA256 }}"
+    end
+    {{- else if .MacOS.DownloadURL }}
     url "{{ .MacOS.DownloadURL }}"
     {{- if .DownloadStrategy }}, :using => {{ .DownloadStrategy }}{{- end }}
     sha256 "{{ .MacOS.SHA256 }}"
     {{- end }}
+    {{- if .MacOSArm64.DownloadURL }}
+    if Hardware::CPU.arm?
+      url "{{ .MacOSArm64.DownloadURL }}"
+      {{- if .DownloadStrategy }}, :using => {{ .DownloadStrategy }}{{- end }}
+      sha256 "{{ .MacOSArm64.SHA256 }}"
+    end
+    {{- end }}
   elsif OS.linux?
     {{- if .Linux.DownloadURL }}
     if Hardware::CPU.intel?
@@ -68,6 +86,11 @@ class {{ .Name }} < Formula
 	{{- end }}
   end
 
+  {{- with .Head.URL }}
+
+  head "{{ . }}"{{ with $.Head.Branch }}, :branch => "{{ . }}"{{ end }}
+  {{- end }}
+
   {{- with .CustomBlock }}
   {{ range $index, $element := . }}
   {{ . }}
@@ -76,7 +99,7 @@ class {{ .Name }} < Formula
 
   {{- with .Dependencies }}
   {{ range $index, $element := . }}
-  depends_on "{{ . }}"
+  depends_on "{{ .Name }}"{{ with .Type }} => :{{ . }}{{ end }}
   {{- end }}
   {{- end -}}
 
@@ -112,6 +135,15 @@ class {{ .Name }} < Formula
   end
   {{- end -}}
 
+  {{- with .Service }}
+
+  service do
+    {{- range $index, $element := . }}
+    {{ . -}}
+    {{- end }}
+  end
+  {{- end -}}
+
   {{- if .Tests }}
 
   test do
@@ -7,9 +7,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 
 	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/ids"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
@@ -19,7 +21,10 @@ import (
 	"github.com/pkg/errors"
 
 	// langs to init
+	_ "github.com/goreleaser/goreleaser/internal/builders/bazel"
 	_ "github.com/goreleaser/goreleaser/internal/builders/golang"
+	_ "github.com/goreleaser/goreleaser/internal/builders/makefile"
+	_ "github.com/goreleaser/goreleaser/internal/builders/tinygo"
 )
 
 // Pipe for build
@@ -31,9 +36,13 @@ func (Pipe) String() string {
 
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) error {
+	cache, err := newBuildCache(ctx)
+	if err != nil {
+		return err
+	}
 	for _, build := range ctx.Config.Builds {
 		log.WithField("build", build).Debug("building")
-		if err := runPipeOnBuild(ctx, build); err != nil {
+		if err := runPipeOnBuild(ctx, build, cache); err != nil {
 			return err
 		}
 	}
@@ -42,20 +51,48 @@ func (Pipe) Run(ctx *context.Context) error {
 
 // Default sets the pipe defaults
 func (Pipe) Default(ctx *context.Context) error {
+	if err := builders.RegisterPlugins(ctx.Config.BuilderPlugins); err != nil {
+		return err
+	}
 	var ids = ids.New("builds")
 	for i, build := range ctx.Config.Builds {
-		ctx.Config.Builds[i] = buildWithDefaults(ctx, build)
+		build, err := buildWithDefaults(ctx, build)
+		if err != nil {
+			return err
+		}
+		ctx.Config.Builds[i] = build
 		ids.Inc(ctx.Config.Builds[i].ID)
 	}
 	if len(ctx.Config.Builds) == 0 {
-		ctx.Config.Builds = []config.Build{
-			buildWithDefaults(ctx, ctx.Config.SingleBuild),
+		build, err := buildWithDefaults(ctx, ctx.Config.SingleBuild)
+		if err != nil {
+			return err
 		}
+		ctx.Config.Builds = []config.Build{build}
+	}
+	for _, build := range ctx.Config.Builds {
+		if build.Generate {
+			addGenerator(ctx, config.Generator{Cmd: "go generate ./...", Env: build.Env})
+		}
+	}
+	if ctx.Config.Incremental.Enabled && ctx.Config.Incremental.CacheDir == "" {
+		ctx.Config.Incremental.CacheDir = defaultCacheDir(ctx)
 	}
 	return ids.Validate()
 }
 
-func buildWithDefaults(ctx *context.Context, build config.Build) config.Build {
+// addGenerator adds the go generate of a build to the generate section,
+// unless a build with the same env already did.
+func addGenerator(ctx *context.Context, gen config.Generator) {
+	for _, g := range ctx.Config.Generate {
+		if reflect.DeepEqual(g, gen) {
+			return
+		}
+	}
+	ctx.Config.Generate = append(ctx.Config.Generate, gen)
+}
+
+func buildWithDefaults(ctx *context.Context, build config.Build) (config.Build, error) {
 	if build.Lang == "" {
 		build.Lang = "go"
 	}
@@ -68,10 +105,17 @@ func buildWithDefaults(ctx *context.Context, build config.Build) config.Build {
 	for k, v := range build.Env {
 		build.Env[k] = os.ExpandEnv(v)
 	}
-	return builders.For(build.Lang).WithDefaults(build)
+	var builder = builders.For(build.Lang)
+	if builder == nil {
+		return build, fmt.Errorf("no builder found for lang %s", build.Lang)
+	}
+	if failer, ok := builder.(builders.DefaultsFailer); ok {
+		return failer.Defaults(ctx, build)
+	}
+	return builder.WithDefaults(build), nil
 }
 
-func runPipeOnBuild(ctx *context.Context, build config.Build) error {
+func runPipeOnBuild(ctx *context.Context, build config.Build, cache *buildCache) error {
 	if err := runHook(ctx, build.Env, build.Hooks.Pre); err != nil {
 		return errors.Wrap(err, "pre hook failed")
 	}
@@ -80,7 +124,7 @@ func runPipeOnBuild(ctx *context.Context, build config.Build) error {
 		target := target
 		build := build
 		g.Go(func() error {
-			return doBuild(ctx, build, target)
+			return doBuild(ctx, build, target, cache)
 		})
 	}
 	if err := g.Wait(); err != nil {
@@ -103,10 +147,12 @@ func runHook(ctx *context.Context, env []string, hook string) error {
 	return run(ctx, cmd, env)
 }
 
-func doBuild(ctx *context.Context, build config.Build, target string) error {
+func doBuild(ctx *context.Context, build config.Build, target string, cache *buildCache) error {
 	var ext = extFor(target)
 
-	binary, err := tmpl.New(ctx).Apply(build.Binary)
+	binary, err := tmpl.New(ctx).
+		WithArtifact(targetArtifact(target), map[string]string{}).
+		Apply(build.Binary)
 	if err != nil {
 		return err
 	}
@@ -118,20 +164,58 @@ func doBuild(ctx *context.Context, build config.Build, target string) error {
 		fmt.Sprintf("%s_%s", build.ID, target),
 		name,
 	)
-	log.WithField("binary", path).Info("building")
-	return builders.For(build.Lang).Build(ctx, build, builders.Options{
+	var options = builders.Options{
 		Target: target,
 		Name:   name,
 		Path:   path,
 		Ext:    ext,
-	})
+	}
+	if cache == nil {
+		log.WithField("binary", path).Info("building")
+		return builders.For(build.Lang).Build(ctx, build, options)
+	}
+
+	key, err := cache.key(ctx, build, target)
+	if err != nil {
+		return err
+	}
+	if ok, err := cache.reuse(ctx, key); ok || err != nil {
+		return err
+	}
+	log.WithField("binary", path).Info("building")
+	// the builder adds its artifacts to a list of its own, so the ones it
+	// actually built are cached, whatever their names and paths
+	var buildCtx = *ctx
+	buildCtx.Artifacts = artifact.New()
+	if err := builders.For(build.Lang).Build(&buildCtx, build, options); err != nil {
+		return err
+	}
+	var built = buildCtx.Artifacts.List()
+	for _, a := range built {
+		ctx.Artifacts.Add(a)
+	}
+	return cache.store(key, built)
+}
+
+// targetArtifact returns an artifact with the platform of the given target,
+// so target-specific fields like Os and Arch can be used in the binary name.
+func targetArtifact(target string) *artifact.Artifact {
+	var parts = strings.Split(target, "_")
+	var a = &artifact.Artifact{Goos: parts[0]}
+	if len(parts) > 1 {
+		a.Goarch = parts[1]
+	}
+	if len(parts) > 2 {
+		a.Goarm = parts[2]
+	}
+	return a
 }
 
 func extFor(target string) string {
 	if strings.Contains(target, "windows") {
 		return ".exe"
 	}
-	if target == "js_wasm" {
+	if strings.HasSuffix(target, "_wasm") {
 		return ".wasm"
 	}
 	return ""
@@ -45,8 +45,37 @@ func (f *fakeBuilder) Build(ctx *context.Context, build config.Build, options ap
 	return nil
 }
 
+// fakeWasmBuilder appends .wasm to the binary, like tinygo does
+type fakeWasmBuilder struct{}
+
+func (*fakeWasmBuilder) WithDefaults(build config.Build) config.Build {
+	return build
+}
+
+func (*fakeWasmBuilder) Build(ctx *context.Context, build config.Build, options api.Options) error {
+	var path = options.Path + ".wasm"
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, []byte(ctx.Version), 0755); err != nil {
+		return err
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   options.Name + ".wasm",
+		Path:   path,
+		Goos:   "js",
+		Goarch: "wasm",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"ID": build.ID,
+		},
+	})
+	return nil
+}
+
 func init() {
 	api.Register("fake", &fakeBuilder{})
+	api.Register("fakeWasm", &fakeWasmBuilder{})
 	api.Register("fakeFail", &fakeBuilder{
 		fail: true,
 	})
@@ -79,10 +108,43 @@ func TestBuild(t *testing.T) {
 		Version: "1.2.3",
 		Config:  config,
 	}
-	error := doBuild(ctx, ctx.Config.Builds[0], "darwin_amd64")
+	error := doBuild(ctx, ctx.Config.Builds[0], "darwin_amd64", nil)
 	assert.NoError(t, error)
 }
 
+func TestBuildBinaryPerTarget(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		Dist: folder,
+		Builds: []config.Build{
+			{
+				ID:     "foo",
+				Lang:   "fake",
+				Binary: `{{ if eq .Os "windows" }}Foo{{ else }}foo{{ end }}_{{ .Arch }}{{ .Arm }}`,
+			},
+		},
+	})
+	for target, path := range map[string]string{
+		"windows_amd64": "foo_windows_amd64/Foo_amd64.exe",
+		"linux_arm_7":   "foo_linux_arm_7/foo_arm7",
+		"darwin_amd64":  "foo_darwin_amd64/foo_amd64",
+	} {
+		assert.NoError(t, doBuild(ctx, ctx.Config.Builds[0], target, nil))
+		assert.FileExists(t, filepath.Join(folder, path))
+	}
+}
+
+func TestTargetArtifact(t *testing.T) {
+	for target, want := range map[string]artifact.Artifact{
+		"linux_arm_6":  {Goos: "linux", Goarch: "arm", Goarm: "6"},
+		"darwin_amd64": {Goos: "darwin", Goarch: "amd64"},
+		"wasm":         {Goos: "wasm"},
+	} {
+		assert.Equal(t, want, *targetArtifact(target))
+	}
+}
+
 func TestRunPipe(t *testing.T) {
 	folder, back := testlib.Mktmp(t)
 	defer back()
@@ -239,6 +301,55 @@ func TestDefaultEmptyBuild(t *testing.T) {
 	assert.Equal(t, "-s -w -X main.version={{.Version}} -X main.commit={{.Commit}} -X main.date={{.Date}} -X main.builtBy=goreleaser", build.Ldflags[0])
 }
 
+func TestDefaultUnknownLang(t *testing.T) {
+	var ctx = &context.Context{
+		Config: config.Project{
+			ProjectName: "foo",
+			Builds: []config.Build{
+				{
+					Lang: "cobol",
+				},
+			},
+		},
+	}
+	assert.EqualError(t, Pipe{}.Default(ctx), "no builder found for lang cobol")
+}
+
+func TestDefaultBuilderPlugin(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var plugin = filepath.Join(folder, "plugin")
+	assert.NoError(t, ioutil.WriteFile(plugin, []byte("#!/bin/sh\necho '{\"error\": \"no cargo\"}'\n"), 0755))
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		BuilderPlugins: []config.BuilderPlugin{
+			{
+				Lang: "rust",
+				Cmd:  plugin,
+			},
+		},
+		Builds: []config.Build{
+			{
+				Lang: "rust",
+			},
+		},
+	})
+	assert.EqualError(t, Pipe{}.Default(ctx), "builder plugin "+plugin+" failed: no cargo")
+}
+
+func TestDefaultBuilderPluginBuiltinLang(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		BuilderPlugins: []config.BuilderPlugin{
+			{
+				Lang: "go",
+				Cmd:  "goreleaser-go",
+			},
+		},
+	})
+	assert.EqualError(t, Pipe{}.Default(ctx), "builder plugin goreleaser-go: lang go already has a builder")
+}
+
 func TestDefaultBuildID(t *testing.T) {
 	var ctx = &context.Context{
 		Config: config.Project{
@@ -342,6 +453,7 @@ func TestExtWindows(t *testing.T) {
 
 func TestExtWasm(t *testing.T) {
 	assert.Equal(t, ".wasm", extFor("js_wasm"))
+	assert.Equal(t, ".wasm", extFor("wasip1_wasm"))
 }
 
 func TestExtOthers(t *testing.T) {
@@ -414,3 +526,149 @@ touch "$BAR"`
 		assert.FileExists(t, filepath.Join(tmp, "bar"))
 	})
 }
+
+func TestDefaultGenerate(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Generate: []config.Generator{{Cmd: "make gen"}},
+		Builds: []config.Build{
+			{ID: "foo", Generate: true, Env: []string{"FOO={{ .Tag }}"}},
+			{ID: "bar", Generate: true, Env: []string{"FOO={{ .Tag }}"}},
+			{ID: "other"},
+			{ID: "another", Generate: true},
+		},
+	})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Equal(t, []config.Generator{
+		{Cmd: "make gen"},
+		{Cmd: "go generate ./...", Env: []string{"FOO={{ .Tag }}"}},
+		{Cmd: "go generate ./..."},
+	}, ctx.Config.Generate)
+}
+
+func TestIncrementalBuild(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	assert.NoError(t, ioutil.WriteFile("main.go", []byte("package main"), 0644))
+	testlib.GitAdd(t)
+	testlib.GitCommit(t, "first")
+
+	var newContext = func(version string) *context.Context {
+		var ctx = context.New(config.Project{
+			Dist: filepath.Join(folder, "dist"),
+			Incremental: config.Incremental{
+				Enabled:  true,
+				CacheDir: filepath.Join(folder, "cache"),
+			},
+			Builds: []config.Build{
+				{
+					ID:      "foo",
+					Lang:    "fakeWasm",
+					Binary:  "foo",
+					Targets: []string{"wasm"},
+				},
+			},
+		})
+		ctx.Git.CurrentTag = "v" + version
+		ctx.Version = version
+		return ctx
+	}
+	var path = filepath.Join(folder, "dist", "foo_wasm", "foo.wasm")
+	var expected = []*artifact.Artifact{{
+		Name:   "foo.wasm",
+		Path:   path,
+		Goos:   "js",
+		Goarch: "wasm",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"ID": "foo",
+		},
+	}}
+	var requireBuilt = func(ctx *context.Context, content string) {
+		t.Helper()
+		assert.Equal(t, expected, ctx.Artifacts.List())
+		bts, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(bts))
+	}
+
+	var ctx = newContext("1.2.3")
+	assert.NoError(t, Pipe{}.Run(ctx))
+	requireBuilt(ctx, "1.2.3")
+	assert.FileExists(t, filepath.Join(folder, "cache", mustKey(t, ctx, "wasm"), "foo.wasm"))
+
+	// the next release reuses the binary built for the previous one
+	assert.NoError(t, os.RemoveAll(filepath.Join(folder, "dist")))
+	ctx = newContext("1.2.4")
+	assert.NoError(t, Pipe{}.Run(ctx))
+	requireBuilt(ctx, "1.2.3")
+
+	assert.NoError(t, ioutil.WriteFile("main.go", []byte("package main\n"), 0644))
+	ctx = newContext("1.2.5")
+	assert.NoError(t, Pipe{}.Run(ctx))
+	requireBuilt(ctx, "1.2.5")
+}
+
+func TestIncrementalKey(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	assert.NoError(t, ioutil.WriteFile("go.mod", []byte("module foo\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile("README.md", []byte("foo"), 0644))
+
+	var newContext = func(version string) *context.Context {
+		var ctx = context.New(config.Project{
+			Incremental: config.Incremental{
+				Enabled:  true,
+				CacheDir: filepath.Join(folder, "cache"),
+			},
+			Builds: []config.Build{
+				{
+					ID:      "foo",
+					Lang:    "go",
+					Main:    ".",
+					Binary:  "foo",
+					Ldflags: []string{"-X main.version={{ .Version }}"},
+				},
+			},
+		})
+		ctx.Version = version
+		return ctx
+	}
+	var ctx = newContext("1.2.3")
+	var key = mustKey(t, ctx, "linux_amd64")
+	assert.Equal(t, key, mustKey(t, ctx, "linux_amd64"))
+	assert.NotEqual(t, key, mustKey(t, ctx, "darwin_amd64"))
+	assert.NotEqual(t, key, mustKey(t, newContext("1.2.4"), "linux_amd64"))
+
+	// only the flags embedding the version change the key
+	var otherCommit = newContext("1.2.4")
+	otherCommit.Git.Commit = "abc"
+	otherCommit.Config.Builds[0].Ldflags = nil
+	ctx.Config.Builds[0].Ldflags = nil
+	key = mustKey(t, ctx, "linux_amd64")
+	assert.Equal(t, key, mustKey(t, otherCommit, "linux_amd64"))
+
+	assert.NoError(t, ioutil.WriteFile("README.md", []byte("bar"), 0644))
+	assert.Equal(t, key, mustKey(t, ctx, "linux_amd64"))
+
+	assert.NoError(t, ioutil.WriteFile("main.go", []byte("package main\n\nfunc main() { println() }\n"), 0644))
+	assert.NotEqual(t, key, mustKey(t, ctx, "linux_amd64"))
+}
+
+func TestIncrementalDefaultCacheDir(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Incremental: config.Incremental{Enabled: true},
+	})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Equal(t, "foo", filepath.Base(ctx.Config.Incremental.CacheDir))
+}
+
+func mustKey(t *testing.T, ctx *context.Context, target string) string {
+	cache, err := newBuildCache(ctx)
+	assert.NoError(t, err)
+	key, err := cache.key(ctx, ctx.Config.Builds[0], target)
+	assert.NoError(t, err)
+	return key
+}
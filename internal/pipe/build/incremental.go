@@ -0,0 +1,327 @@
+package build
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// buildCache keeps the binaries of previous runs, keyed by a hash of their
+// inputs, so unchanged builds can be reused instead of rebuilt.
+type buildCache struct {
+	dir       string
+	goVersion string
+}
+
+// newBuildCache returns the build cache, or nil if incremental builds are
+// disabled.
+func newBuildCache(ctx *context.Context) (*buildCache, error) {
+	if !ctx.Config.Incremental.Enabled {
+		return nil, nil
+	}
+	var cache = &buildCache{dir: ctx.Config.Incremental.CacheDir}
+	for _, build := range ctx.Config.Builds {
+		if build.Lang != "go" {
+			continue
+		}
+		/* #nosec */
+		var cmd = exec.CommandContext(ctx, "go", "version")
+		cmd.Env = ctx.Env.Strings()
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the go version: %s", string(out))
+		}
+		cache.goVersion = strings.TrimSpace(string(out))
+		break
+	}
+	return cache, nil
+}
+
+// defaultCacheDir is the folder of the project in the user cache dir.
+func defaultCacheDir(ctx *context.Context) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "goreleaser", ctx.Config.ProjectName)
+}
+
+// key of the given build target: the go version, the build config with its
+// binary name and flags rendered, the env affecting the build and the
+// contents of its sources. The release itself isn't part of it, so unchanged
+// binaries are reused by the next releases, unless their flags embed the
+// version or the commit.
+func (c *buildCache) key(ctx *context.Context, build config.Build, target string) (string, error) {
+	var a = targetArtifact(target)
+	var env = append(append(ctx.Env.Strings(), build.Env...), targetEnv(a)...)
+	var h = sha256.New()
+	var write = func(fields ...string) {
+		for _, field := range fields {
+			_, _ = io.WriteString(h, field+"\x00")
+		}
+	}
+	write(
+		target,
+		ctx.Config.Dist,
+		c.goVersion,
+		build.ID,
+		build.Lang,
+		build.Main,
+		build.Binary,
+		strconv.FormatBool(build.Test),
+		build.Containers[target],
+		strconv.FormatBool(build.CrossCompile.Enabled),
+		build.CrossCompile.Image,
+	)
+	flags, err := renderFlags(ctx, a, env, build.Flags)
+	if err != nil {
+		return "", err
+	}
+	write(flags...)
+	for _, group := range [][]string{build.Asmflags, build.Gcflags, build.Ldflags} {
+		rendered, err := renderFlags(ctx, a, env, group)
+		if err != nil {
+			return "", err
+		}
+		write("")
+		write(rendered...)
+	}
+	write(buildEnv(env)...)
+
+	var sources []string
+	if build.Lang == "go" {
+		sources, err = goSources(ctx, build, env, flags)
+	} else {
+		sources, err = gitSources()
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to list the sources of %s", build.ID)
+	}
+	for _, name := range sources {
+		write(name)
+		if err := hashFile(h, name); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func renderFlags(ctx *context.Context, a *artifact.Artifact, env, flags []string) ([]string, error) {
+	var rendered []string
+	for _, flag := range flags {
+		flag, err := tmpl.New(ctx).WithEnvS(env).WithArtifact(a, map[string]string{}).Apply(flag)
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, flag)
+	}
+	return rendered, nil
+}
+
+// targetEnv is the env the go builder sets for the given target.
+func targetEnv(a *artifact.Artifact) []string {
+	return []string{
+		"GOOS=" + a.Goos,
+		"GOARCH=" + a.Goarch,
+		"GOARM=" + a.Goarm,
+	}
+}
+
+// buildEnv filters the env down to the variables that change the output of
+// a build, so the rest of the environment doesn't invalidate the cache.
+func buildEnv(env []string) []string {
+	var result []string
+	for _, e := range env {
+		var name = strings.SplitN(e, "=", 2)[0]
+		switch name {
+		case "GOPATH", "GOCACHE", "GOMODCACHE":
+			continue
+		case "CC", "CXX", "AR", "PKG_CONFIG":
+			result = append(result, e)
+			continue
+		}
+		if strings.HasPrefix(name, "GO") || strings.HasPrefix(name, "CGO_") {
+			result = append(result, e)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// goPackage is the part of the go list output listing the files of a package.
+type goPackage struct {
+	Dir          string
+	Standard     bool
+	GoFiles      []string
+	CgoFiles     []string
+	CFiles       []string
+	CXXFiles     []string
+	HFiles       []string
+	SFiles       []string
+	SysoFiles    []string
+	EmbedFiles   []string
+	TestGoFiles  []string
+	XTestGoFiles []string
+}
+
+// goSources lists the files of the packages the build depends on, standard
+// library aside, as the go list of the main package for the target sees them.
+func goSources(ctx *context.Context, build config.Build, env, flags []string) ([]string, error) {
+	var args = append([]string{"list", "-deps", "-json"}, flags...)
+	if build.Test {
+		args = append(args, "-test")
+	}
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "go", append(args, build.Main)...)
+	cmd.Env = env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(err, stderr.String())
+	}
+	var sources []string
+	var decoder = json.NewDecoder(bytes.NewReader(out))
+	for decoder.More() {
+		var pkg goPackage
+		if err := decoder.Decode(&pkg); err != nil {
+			return nil, err
+		}
+		if pkg.Standard {
+			continue
+		}
+		for _, files := range [][]string{
+			pkg.GoFiles, pkg.CgoFiles, pkg.CFiles, pkg.CXXFiles, pkg.HFiles,
+			pkg.SFiles, pkg.SysoFiles, pkg.EmbedFiles, pkg.TestGoFiles, pkg.XTestGoFiles,
+		} {
+			for _, file := range files {
+				sources = append(sources, filepath.Join(pkg.Dir, file))
+			}
+		}
+	}
+	sort.Strings(sources)
+	return sources, nil
+}
+
+// gitSources lists all the files tracked by git, the only way to know the
+// inputs of the builds of other languages.
+func gitSources() ([]string, error) {
+	out, err := git.Run("ls-files", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var sources []string
+	for _, name := range strings.Split(out, "\x00") {
+		if name != "" {
+			sources = append(sources, name)
+		}
+	}
+	return sources, nil
+}
+
+func hashFile(w io.Writer, path string) error {
+	f, err := os.Open(path) // #nosec
+	if os.IsNotExist(err) {
+		// deleted but not staged yet
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint: errcheck
+	if info, err := f.Stat(); err != nil || info.IsDir() {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// cachedArtifacts is the file listing the artifacts of a cached build, next to
+// their copies
+const cachedArtifacts = "artifacts.json"
+
+func (c *buildCache) path(key, name string) string {
+	return filepath.Join(c.dir, key, name)
+}
+
+// reuse copies the cached artifacts of the given key back to their paths
+// and adds them. It returns false if the build isn't cached.
+func (c *buildCache) reuse(ctx *context.Context, key string) (bool, error) {
+	bts, err := ioutil.ReadFile(c.path(key, cachedArtifacts))
+	if err != nil {
+		return false, nil
+	}
+	var artifacts []*artifact.Artifact
+	if err := json.Unmarshal(bts, &artifacts); err != nil {
+		return false, errors.Wrapf(err, "failed to read the cached artifacts of %s", key)
+	}
+	for _, a := range artifacts {
+		log.WithField("binary", a.Path).Info("reusing unchanged binary")
+		if err := copyFile(c.path(key, a.Name), a.Path); err != nil {
+			return false, errors.Wrapf(err, "failed to reuse %s", a.Name)
+		}
+	}
+	for _, a := range artifacts {
+		ctx.Artifacts.Add(a)
+	}
+	return true, nil
+}
+
+// store copies the built artifacts to the cache. Their list is written last,
+// so a build is only reused once all its artifacts are cached.
+func (c *buildCache) store(key string, artifacts []*artifact.Artifact) error {
+	for _, a := range artifacts {
+		if err := copyFile(a.Path, c.path(key, a.Name)); err != nil {
+			return errors.Wrapf(err, "failed to cache %s", a.Path)
+		}
+	}
+	bts, err := json.Marshal(artifacts)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(
+		ioutil.WriteFile(c.path(key, cachedArtifacts), bts, 0644),
+		"failed to cache the artifacts of %s", key,
+	)
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src) // #nosec
+	if err != nil {
+		return err
+	}
+	defer in.Close() // nolint: errcheck
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
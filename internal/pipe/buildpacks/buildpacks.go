@@ -0,0 +1,232 @@
+// Package buildpacks provides a Pipe that creates container images from the
+// built binaries with cloud native buildpacks, without a Dockerfile.
+package buildpacks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoPack is shown when pack cannot be found in $PATH
+var ErrNoPack = errors.New("pack not present in $PATH")
+
+const (
+	defaultBuilder   = "paketobuildpacks/builder:tiny"
+	defaultBuildpack = "paketo-buildpacks/procfile"
+)
+
+// Pipe for buildpacks
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "buildpack images"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Buildpacks {
+		var bp = &ctx.Config.Buildpacks[i]
+		if bp.Goos == "" {
+			bp.Goos = "linux"
+		}
+		if bp.Goarch == "" {
+			bp.Goarch = "amd64"
+		}
+		if bp.Builder == "" {
+			bp.Builder = defaultBuilder
+		}
+		if len(bp.Buildpacks) == 0 {
+			bp.Buildpacks = []string{defaultBuildpack}
+		}
+		if len(bp.Binaries) == 0 && len(ctx.Config.Builds) > 0 {
+			bp.Binaries = []string{ctx.Config.Builds[0].Binary}
+		}
+		for _, f := range bp.Files {
+			if f == "." || strings.HasPrefix(f, ctx.Config.Dist) {
+				return fmt.Errorf("invalid buildpacks.extra_files: can't be . or inside dist folder: %s", f)
+			}
+		}
+	}
+	return nil
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.Buildpacks) == 0 {
+		return pipe.Skip("buildpacks section is not configured")
+	}
+	if _, err := exec.LookPath("pack"); err != nil {
+		return ErrNoPack
+	}
+	var g = semerrgroup.NewSkipAware(semerrgroup.New(ctx.Parallelism))
+	for _, bp := range ctx.Config.Buildpacks {
+		bp := bp
+		ok, err := condition.Check(ctx, bp.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("images", bp.ImageTemplates).Info("skipped because its condition is false")
+			continue
+		}
+		g.Go(func() error {
+			binaries, err := findBinaries(ctx, bp)
+			if err != nil {
+				return err
+			}
+			return process(ctx, bp, binaries)
+		})
+	}
+	return g.Wait()
+}
+
+func findBinaries(ctx *context.Context, bp config.Buildpack) ([]*artifact.Artifact, error) {
+	var names = map[string]bool{}
+	for _, b := range bp.Binaries {
+		name, err := tmpl.New(ctx).Apply(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to execute binary template '%s'", b)
+		}
+		names[name] = true
+	}
+	var binaries = ctx.Artifacts.Filter(
+		artifact.And(
+			artifact.ByGoos(bp.Goos),
+			artifact.ByGoarch(bp.Goarch),
+			artifact.ByGoarm(bp.Goarm),
+			artifact.ByType(artifact.Binary),
+			func(a *artifact.Artifact) bool {
+				return names[a.ExtraOr("Binary", "").(string)]
+			},
+		),
+	).List()
+	if len(binaries) != len(bp.Binaries) {
+		return nil, fmt.Errorf(
+			"%d binaries match buildpack definition: %v: %s_%s_%s, should be %d",
+			len(binaries),
+			bp.Binaries, bp.Goos, bp.Goarch, bp.Goarm,
+			len(bp.Binaries),
+		)
+	}
+	return binaries, nil
+}
+
+func process(ctx *context.Context, bp config.Buildpack, bins []*artifact.Artifact) error {
+	if len(bp.ImageTemplates) == 0 {
+		return pipe.Skip("buildpack has no image_templates")
+	}
+	var images []string
+	for _, t := range bp.ImageTemplates {
+		image, err := tmpl.New(ctx).Apply(t)
+		if err != nil {
+			return errors.Wrapf(err, "failed to execute image template '%s'", t)
+		}
+		images = append(images, image)
+	}
+
+	app, err := ioutil.TempDir(ctx.Config.Dist, "goreleaserbuildpack")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary dir")
+	}
+	log.Debug("tempdir: " + app)
+	for _, file := range bp.Files {
+		if err := os.MkdirAll(filepath.Join(app, filepath.Dir(file)), 0755); err != nil {
+			return errors.Wrapf(err, "failed to link extra file '%s'", file)
+		}
+		if err := os.Link(file, filepath.Join(app, file)); err != nil {
+			return errors.Wrapf(err, "failed to link extra file '%s'", file)
+		}
+	}
+	for _, bin := range bins {
+		if err := os.Link(bin.Path, filepath.Join(app, filepath.Base(bin.Path))); err != nil {
+			return errors.Wrap(err, "failed to link binary")
+		}
+	}
+	// the procfile buildpack runs the first binary, unless the extra files
+	// have a Procfile already
+	var procfile = filepath.Join(app, "Procfile")
+	if _, err := os.Stat(procfile); os.IsNotExist(err) {
+		var content = "web: ./" + filepath.Base(bins[0].Path) + "\n"
+		if err := ioutil.WriteFile(procfile, []byte(content), 0644); err != nil {
+			return errors.Wrap(err, "failed to write Procfile")
+		}
+	}
+
+	if err := packBuild(ctx, app, images, bp); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(bp.SkipPush) == "true" {
+		return pipe.Skip("buildpacks.skip_push is set")
+	}
+	if ctx.SkipPublish {
+		return pipe.ErrSkipPublishEnabled
+	}
+	if ctx.Config.Release.Draft {
+		return pipe.Skip("release is marked as draft")
+	}
+	if strings.TrimSpace(bp.SkipPush) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' push, skipping buildpack publish")
+	}
+	// pushed by the docker pipe, along with the other images
+	for _, img := range images {
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type:   artifact.PublishableDockerImage,
+			Name:   img,
+			Path:   img,
+			Goarch: bp.Goarch,
+			Goos:   bp.Goos,
+			Goarm:  bp.Goarm,
+		})
+	}
+	return nil
+}
+
+func packBuild(ctx *context.Context, app string, images []string, bp config.Buildpack) error {
+	log.WithField("image", images[0]).Info("building buildpack image")
+	args, err := buildCommand(ctx, app, images, bp)
+	if err != nil {
+		return err
+	}
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "pack", args...)
+	log.WithField("cmd", cmd.Args).Debug("running")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "failed to build buildpack image: \n%s", string(out))
+	}
+	log.Debugf("pack build output: \n%s", string(out))
+	return nil
+}
+
+func buildCommand(ctx *context.Context, app string, images []string, bp config.Buildpack) ([]string, error) {
+	var args = []string{"build", images[0], "--builder", bp.Builder, "--path", app}
+	for _, image := range images[1:] {
+		args = append(args, "--tag", image)
+	}
+	for _, buildpack := range bp.Buildpacks {
+		args = append(args, "--buildpack", buildpack)
+	}
+	for _, e := range bp.Env {
+		env, err := tmpl.New(ctx).Apply(e)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to execute env template '%s'", e)
+		}
+		args = append(args, "--env", env)
+	}
+	return args, nil
+}
@@ -0,0 +1,129 @@
+package buildpacks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Builds: []config.Build{
+			{Binary: "foo"},
+		},
+		Buildpacks: []config.Buildpack{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Buildpack{
+		Binaries:   []string{"foo"},
+		Goos:       "linux",
+		Goarch:     "amd64",
+		Builder:    defaultBuilder,
+		Buildpacks: []string{defaultBuildpack},
+	}, ctx.Config.Buildpacks[0])
+}
+
+func TestDefaultInvalidFiles(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Dist: "dist",
+		Buildpacks: []config.Buildpack{
+			{Files: []string{"dist/foo"}},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "invalid buildpacks.extra_files: can't be . or inside dist folder: dist/foo")
+}
+
+func TestSkipNotConfigured(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+func TestBuildCommand(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.Version = "1.2.3"
+	args, err := buildCommand(ctx, "app", []string{"foo/bar:1.2.3", "foo/bar:latest"}, config.Buildpack{
+		Builder:    "my/builder",
+		Buildpacks: []string{"a", "b"},
+		Env:        []string{"VERSION={{ .Version }}"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"build", "foo/bar:1.2.3",
+		"--builder", "my/builder",
+		"--path", "app",
+		"--tag", "foo/bar:latest",
+		"--buildpack", "a",
+		"--buildpack", "b",
+		"--env", "VERSION=1.2.3",
+	}, args)
+}
+
+func TestRunPipe(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	// fake pack that records its args and the generated Procfile
+	defer testlib.FakeTools(t, folder, map[string]string{
+		"pack": "echo \"$@\" > " + filepath.Join(folder, "args") + "\ncp $6/Procfile " + filepath.Join(folder, "Procfile") + "\n",
+	})()
+
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.MkdirAll(filepath.Join(dist, "foo_linux_amd64"), 0755))
+	var binary = filepath.Join(dist, "foo_linux_amd64", "foo")
+	require.NoError(t, ioutil.WriteFile(binary, []byte("foo"), 0755))
+
+	var ctx = context.New(config.Project{
+		Dist: dist,
+		Buildpacks: []config.Buildpack{
+			{
+				Binaries:       []string{"foo"},
+				ImageTemplates: []string{"foo/bar:{{ .Version }}"},
+			},
+		},
+	})
+	ctx.Version = "1.2.3"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo",
+		Path:   binary,
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"Binary": "foo",
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	args, err := ioutil.ReadFile(filepath.Join(folder, "args"))
+	require.NoError(t, err)
+	require.Contains(t, string(args), "build foo/bar:1.2.3 --builder "+defaultBuilder)
+	require.Contains(t, string(args), "--buildpack "+defaultBuildpack)
+	procfile, err := ioutil.ReadFile(filepath.Join(folder, "Procfile"))
+	require.NoError(t, err)
+	require.Equal(t, "web: ./foo\n", string(procfile))
+
+	var images = ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableDockerImage)).List()
+	require.Len(t, images, 1)
+	require.Equal(t, "foo/bar:1.2.3", images[0].Name)
+}
+
+func TestRunPipeBinaryMismatch(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Buildpacks: []config.Buildpack{
+			{
+				Binaries:       []string{"foo"},
+				Goos:           "linux",
+				Goarch:         "amd64",
+				ImageTemplates: []string{"foo/bar"},
+			},
+		},
+	})
+	_, err := findBinaries(ctx, ctx.Config.Buildpacks[0])
+	require.EqualError(t, err, "0 binaries match buildpack definition: [foo]: linux_amd64_, should be 1")
+}
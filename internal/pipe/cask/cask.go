@@ -0,0 +1,298 @@
+// Package cask provides a Pipe that generates a homebrew cask installing the
+// macOS archives, e.g. of GUI applications, and pushes it to a tap.
+package cask
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoArchivesFound happens when there are no macOS archives to install
+var ErrNoArchivesFound = errors.New("no macos archives found")
+
+// Pipe for homebrew casks
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "homebrew tap casks"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.HomebrewCasks {
+		var cask = &ctx.Config.HomebrewCasks[i]
+		if cask.Name == "" {
+			cask.Name = ctx.Config.ProjectName
+		}
+		if cask.Folder == "" {
+			cask.Folder = "Casks"
+		}
+		if cask.Description == "" {
+			cask.Description = ctx.Config.Metadata.Description
+		}
+		if cask.Homepage == "" {
+			cask.Homepage = ctx.Config.Metadata.Homepage
+		}
+		if cask.App == "" && cask.Pkg == "" && len(cask.Binaries) == 0 {
+			for _, build := range ctx.Config.Builds {
+				if contains(build.Goos, "darwin") && !contains(cask.Binaries, build.Binary) {
+					cask.Binaries = append(cask.Binaries, build.Binary)
+				}
+			}
+			log.Warnf("optimistically guessing `homebrew_casks[%d].binaries`, double check", i)
+		}
+		if cask.CommitAuthor.Name == "" {
+			cask.CommitAuthor.Name = "goreleaserbot"
+		}
+		if cask.CommitAuthor.Email == "" {
+			cask.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+		}
+	}
+	return nil
+}
+
+func contains(ss []string, s string) bool {
+	for _, zs := range ss {
+		if zs == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish the homebrew casks
+func (Pipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.HomebrewCasks) == 0 {
+		return pipe.Skip("homebrew_casks section is not configured")
+	}
+	client, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	for _, cask := range ctx.Config.HomebrewCasks {
+		ok, err := condition.Check(ctx, cask.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("cask", cask.Name).Info("skipped because its condition is false")
+			continue
+		}
+		if err := doRun(ctx, cask, client); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, cask config.HomebrewCask, client client.Client) error {
+	if cask.Repository.Name == "" {
+		return pipe.Skip("homebrew_casks.repository is not set")
+	}
+	var filters = []artifact.Filter{
+		artifact.ByGoos("darwin"),
+		artifact.ByFormats("zip", "tar.gz", "tar.xz"),
+		artifact.Or(
+			artifact.ByGoarch("amd64"),
+			artifact.ByGoarch("arm64"),
+			artifact.ByGoarch("all"),
+		),
+		artifact.ByType(artifact.UploadableArchive),
+	}
+	if len(cask.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(cask.IDs...))
+	}
+	var archives = ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(archives) == 0 {
+		return ErrNoArchivesFound
+	}
+
+	data, err := dataFor(ctx, cask, archives)
+	if err != nil {
+		return err
+	}
+	content, err := buildCask(data)
+	if err != nil {
+		return err
+	}
+	var filename = cask.Name + ".rb"
+	var path = filepath.Join(ctx.Config.Dist, filename)
+	log.WithField("cask", path).Info("writing")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(cask.SkipUpload) == "true" {
+		return pipe.Skip("homebrew_casks.skip_upload is set")
+	}
+	if ctx.SkipPublish {
+		return pipe.ErrSkipPublishEnabled
+	}
+	if ctx.Config.Release.Draft {
+		return pipe.Skip("release is marked as draft")
+	}
+	if ctx.Config.Release.Disable {
+		return pipe.Skip("release is disabled")
+	}
+	if strings.TrimSpace(cask.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping homebrew cask publish")
+	}
+
+	var gpath = buildCaskPath(cask.Folder, filename)
+	log.WithField("cask", gpath).
+		WithField("repo", cask.Repository.String()).
+		Info("pushing")
+	var msg = fmt.Sprintf("Brew cask update for %s version %s", ctx.Config.ProjectName, ctx.Git.CurrentTag)
+	if err := client.CreateFile(ctx, cask.CommitAuthor, cask.Repository, []byte(content), gpath, msg); err != nil {
+		return err
+	}
+	ctx.CommittedFiles = append(ctx.CommittedFiles, context.CommittedFile{
+		Repo:         cask.Repository,
+		Path:         gpath,
+		CommitAuthor: cask.CommitAuthor,
+	})
+	return nil
+}
+
+func buildCaskPath(folder, filename string) string {
+	return path.Join(folder, filename)
+}
+
+func buildCask(data templateData) (string, error) {
+	t, err := template.New(data.Name).
+		Funcs(template.FuncMap{"quote": quote}).
+		Parse(caskTemplate)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func dataFor(ctx *context.Context, cask config.HomebrewCask, archives []*artifact.Artifact) (templateData, error) {
+	var result = templateData{
+		Name:         cask.Name,
+		Version:      ctx.Version,
+		Dependencies: cask.Dependencies,
+		Conflicts:    cask.Conflicts,
+	}
+	// the fields are templated one by one, so the rendered cask itself is
+	// never parsed as a template
+	var t = tmpl.New(ctx)
+	var err error
+	if result.Desc, err = t.Apply(cask.Description); err != nil {
+		return result, err
+	}
+	if result.Homepage, err = t.Apply(cask.Homepage); err != nil {
+		return result, err
+	}
+	if result.App, err = t.Apply(cask.App); err != nil {
+		return result, err
+	}
+	if result.Pkg, err = t.Apply(cask.Pkg); err != nil {
+		return result, err
+	}
+	if result.Binaries, err = applyAll(t, cask.Binaries); err != nil {
+		return result, err
+	}
+	if result.Zap, err = applyAll(t, cask.Zap); err != nil {
+		return result, err
+	}
+	caveats, err := t.Apply(cask.Caveats)
+	if err != nil {
+		return result, err
+	}
+	result.Caveats = split(caveats)
+
+	if cask.URLTemplate == "" {
+		urlTemplate, err := client.DownloadURLTemplate(ctx)
+		if err != nil {
+			return result, err
+		}
+		cask.URLTemplate = urlTemplate
+	}
+
+	var intel, arm *download
+	for _, archive := range archives {
+		url, err := tmpl.New(ctx).WithArtifact(archive, map[string]string{}).Apply(cask.URLTemplate)
+		if err != nil {
+			return result, err
+		}
+		sum, err := archive.Checksum("sha256")
+		if err != nil {
+			return result, err
+		}
+		var down = &download{URL: url, SHA256: sum}
+		switch archive.Goarch {
+		case "arm64":
+			if arm != nil {
+				return result, fmt.Errorf("cask %s: found multiple macos arm64 archives, use ids to pick one", cask.Name)
+			}
+			arm = down
+		default:
+			// amd64 and universal binaries
+			if intel != nil {
+				return result, fmt.Errorf("cask %s: found multiple macos amd64 archives, use ids to pick one", cask.Name)
+			}
+			intel = down
+		}
+	}
+	switch {
+	case intel != nil && arm != nil:
+		intel.Block = "intel"
+		arm.Block = "arm"
+		result.Downloads = []download{*intel, *arm}
+	case intel != nil:
+		// also installable on arm macs through rosetta
+		result.Downloads = []download{*intel}
+	default:
+		arm.Block = "arm"
+		result.Downloads = []download{*arm}
+	}
+	return result, nil
+}
+
+func applyAll(t *tmpl.Template, values []string) ([]string, error) {
+	var result []string
+	for _, value := range values {
+		applied, err := t.Apply(value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, applied)
+	}
+	return result, nil
+}
+
+func split(s string) []string {
+	var lines = []string{}
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// quote returns the given string as a ruby string literal
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
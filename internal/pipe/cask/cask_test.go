@@ -0,0 +1,343 @@
+package cask
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update .golden files")
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Metadata: config.Metadata{
+			Description: "A foo",
+			Homepage:    "https://example.com",
+		},
+		Builds: []config.Build{
+			{Binary: "foo", Goos: []string{"linux", "darwin"}},
+			{Binary: "bar", Goos: []string{"linux"}},
+			{Binary: "foo", Goos: []string{"darwin"}},
+		},
+		HomebrewCasks: []config.HomebrewCask{{}, {App: "Foo.app"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.HomebrewCask{
+		Name:        "foo",
+		Folder:      "Casks",
+		Description: "A foo",
+		Homepage:    "https://example.com",
+		Binaries:    []string{"foo"},
+		CommitAuthor: config.CommitAuthor{
+			Name:  "goreleaserbot",
+			Email: "goreleaser@carlosbecker.com",
+		},
+	}, ctx.Config.HomebrewCasks[0])
+	require.Empty(t, ctx.Config.HomebrewCasks[1].Binaries)
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Publish(context.New(config.Project{})))
+}
+
+func TestQuote(t *testing.T) {
+	require.Equal(t, `"a \"b\" \\ c"`, quote(`a "b" \ c`))
+}
+
+func TestSplit(t *testing.T) {
+	require.Equal(t, []string{"a", "b"}, split("a\n\n  b  \n"))
+	require.Equal(t, []string{}, split(""))
+}
+
+func caskContext(t *testing.T, folder string, cask config.HomebrewCask, goarchs ...string) *context.Context {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        folder,
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "foo", Name: "bar"},
+			GitLab: config.Repo{Owner: "foo", Name: "bar"},
+		},
+		GitHubURLs:    config.GitHubURLs{Download: "https://github.com"},
+		GitLabURLs:    config.GitLabURLs{Download: "https://gitlab.com"},
+		HomebrewCasks: []config.HomebrewCask{cask},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	for _, goarch := range goarchs {
+		var name = "foo_darwin_" + goarch + ".zip"
+		var path = filepath.Join(folder, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(name), 0644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type:   artifact.UploadableArchive,
+			Name:   name,
+			Path:   path,
+			Goos:   "darwin",
+			Goarch: goarch,
+			Extra: map[string]interface{}{
+				"ID":     "foo",
+				"Format": "zip",
+			},
+		})
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   artifact.UploadableArchive,
+		Name:   "foo_linux_amd64.zip",
+		Path:   "doesnt matter",
+		Goos:   "linux",
+		Goarch: "amd64",
+		Extra: map[string]interface{}{
+			"ID":     "foo",
+			"Format": "zip",
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestRunPipe(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cask    config.HomebrewCask
+		goarchs []string
+		gitlab  bool
+	}{
+		"default": {
+			cask: config.HomebrewCask{
+				Repository:  config.Repo{Owner: "foo", Name: "homebrew-tap"},
+				Description: "A foo",
+				Homepage:    "https://example.com",
+				Binaries:    []string{"foo"},
+			},
+			goarchs: []string{"amd64"},
+		},
+		"full": {
+			cask: config.HomebrewCask{
+				Name:        "foo-app",
+				Repository:  config.Repo{Owner: "foo", Name: "homebrew-tap"},
+				Description: `The "foo" app`,
+				Homepage:    "https://example.com",
+				App:         "Foo.app",
+				Pkg:         "Foo-{{ .Version }}.pkg",
+				Binaries:    []string{"Foo.app/Contents/MacOS/foo"},
+				Dependencies: []config.HomebrewCaskDependency{
+					{Formula: "git"},
+					{Cask: "xquartz"},
+				},
+				Conflicts: []string{"foo-nightly"},
+				Zap: []string{
+					"~/Library/Application Support/Foo",
+					"~/Library/Preferences/com.example.foo.plist",
+				},
+				Caveats: "Foo needs accessibility permissions\nto work",
+			},
+			goarchs: []string{"amd64", "arm64"},
+		},
+		"arm64": {
+			cask: config.HomebrewCask{
+				Repository: config.Repo{Owner: "foo", Name: "homebrew-tap"},
+				App:        "Foo.app",
+			},
+			goarchs: []string{"arm64"},
+		},
+		"universal": {
+			cask: config.HomebrewCask{
+				Repository: config.Repo{Owner: "foo", Name: "homebrew-tap"},
+				App:        "Foo.app",
+			},
+			goarchs: []string{"all"},
+		},
+		"gitlab": {
+			cask: config.HomebrewCask{
+				Repository: config.Repo{Owner: "foo", Name: "homebrew-tap"},
+				App:        "Foo.app",
+			},
+			goarchs: []string{"amd64"},
+			gitlab:  true,
+		},
+		"url_template": {
+			cask: config.HomebrewCask{
+				Repository:  config.Repo{Owner: "foo", Name: "homebrew-tap"},
+				App:         "Foo.app",
+				URLTemplate: "https://dl.example.com/{{ .Tag }}/{{ .ArtifactName }}",
+			},
+			goarchs: []string{"amd64"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder, err := ioutil.TempDir("", "casktest")
+			require.NoError(t, err)
+			var ctx = caskContext(t, folder, tt.cask, tt.goarchs...)
+			if tt.gitlab {
+				ctx.TokenType = context.TokenTypeGitLab
+				for _, a := range ctx.Artifacts.List() {
+					a.Extra["ArtifactUploadHash"] = "820ead5d9d2266c728dce6d4d55b6460"
+				}
+			}
+			var cask = ctx.Config.HomebrewCasks[0]
+			var client = &DummyClient{}
+			require.NoError(t, doRun(ctx, cask, client))
+			require.True(t, client.CreatedFile)
+			require.Equal(t, "Casks/"+cask.Name+".rb", client.Path)
+
+			var golden = "testdata/" + name + ".rb.golden"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(golden, []byte(client.Content), 0655))
+			}
+			bts, err := ioutil.ReadFile(golden)
+			require.NoError(t, err)
+			require.Equal(t, string(bts), client.Content)
+
+			dist, err := ioutil.ReadFile(filepath.Join(folder, cask.Name+".rb"))
+			require.NoError(t, err)
+			require.Equal(t, string(bts), string(dist))
+			require.Len(t, ctx.CommittedFiles, 1)
+		})
+	}
+}
+
+func TestRunPipeNoArchives(t *testing.T) {
+	folder, err := ioutil.TempDir("", "casktest")
+	require.NoError(t, err)
+	var ctx = caskContext(t, folder, config.HomebrewCask{
+		Repository: config.Repo{Owner: "foo", Name: "homebrew-tap"},
+	})
+	var client = &DummyClient{}
+	require.Equal(t, ErrNoArchivesFound, doRun(ctx, ctx.Config.HomebrewCasks[0], client))
+	require.False(t, client.CreatedFile)
+}
+
+func TestRunPipeMultipleArchivesSameArch(t *testing.T) {
+	for goarch, goarchs := range map[string][]string{
+		"amd64": {"amd64", "all"},
+		"arm64": {"arm64", "arm64"},
+	} {
+		t.Run(goarch, func(t *testing.T) {
+			folder, err := ioutil.TempDir("", "casktest")
+			require.NoError(t, err)
+			var ctx = caskContext(t, folder, config.HomebrewCask{
+				Repository: config.Repo{Owner: "foo", Name: "homebrew-tap"},
+			}, goarchs...)
+			require.EqualError(
+				t,
+				doRun(ctx, ctx.Config.HomebrewCasks[0], &DummyClient{}),
+				"cask foo: found multiple macos "+goarch+" archives, use ids to pick one",
+			)
+		})
+	}
+}
+
+func TestRunPipeNoRepository(t *testing.T) {
+	var client = &DummyClient{}
+	testlib.AssertSkipped(t, doRun(context.New(config.Project{}), config.HomebrewCask{}, client))
+	require.False(t, client.CreatedFile)
+}
+
+func TestRunPipeTokenTypeNotImplemented(t *testing.T) {
+	folder, err := ioutil.TempDir("", "casktest")
+	require.NoError(t, err)
+	var ctx = caskContext(t, folder, config.HomebrewCask{
+		Repository: config.Repo{Owner: "foo", Name: "homebrew-tap"},
+	}, "amd64")
+	ctx.TokenType = ""
+	require.Equal(t, client.ErrTokenTypeNotImplemented, doRun(ctx, ctx.Config.HomebrewCasks[0], &DummyClient{}))
+}
+
+func TestRunPipeInvalidTemplate(t *testing.T) {
+	for name, cask := range map[string]config.HomebrewCask{
+		"description": {Description: "{{ .Nope }"},
+		"homepage":    {Homepage: "{{ .Nope }"},
+		"app":         {App: "{{ .Nope }"},
+		"pkg":         {Pkg: "{{ .Nope }"},
+		"binaries":    {Binaries: []string{"foo", "{{ .Nope }"}},
+		"zap":         {Zap: []string{"{{ .Nope }"}},
+		"caveats":     {Caveats: "{{ .Nope }"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder, err := ioutil.TempDir("", "casktest")
+			require.NoError(t, err)
+			cask.Repository = config.Repo{Owner: "foo", Name: "homebrew-tap"}
+			var ctx = caskContext(t, folder, cask, "amd64")
+			var client = &DummyClient{}
+			require.Error(t, doRun(ctx, ctx.Config.HomebrewCasks[0], client))
+			require.False(t, client.CreatedFile)
+		})
+	}
+}
+
+func TestRunPipeRenderedCaskNotTemplated(t *testing.T) {
+	folder, err := ioutil.TempDir("", "casktest")
+	require.NoError(t, err)
+	var ctx = caskContext(t, folder, config.HomebrewCask{
+		Repository:  config.Repo{Owner: "foo", Name: "homebrew-tap"},
+		App:         "Foo.app",
+		URLTemplate: "https://dl.example.com/{{ .Tag }}/{{ .ArtifactName }}?raw={{ `{{ .Tag }}` }}",
+	}, "amd64")
+	var client = &DummyClient{}
+	require.NoError(t, doRun(ctx, ctx.Config.HomebrewCasks[0], client))
+	require.Contains(t, client.Content, `url "https://dl.example.com/v1.0.0/foo_darwin_amd64.zip?raw={{ .Tag }}"`)
+}
+
+func TestRunPipeSkipPublish(t *testing.T) {
+	for name, setup := range map[string]func(ctx *context.Context){
+		"skip_upload": func(ctx *context.Context) {
+			ctx.Config.HomebrewCasks[0].SkipUpload = "true"
+		},
+		"auto": func(ctx *context.Context) {
+			ctx.Config.HomebrewCasks[0].SkipUpload = "auto"
+			ctx.Semver.Prerelease = "beta1"
+		},
+		"skip_publish": func(ctx *context.Context) {
+			ctx.SkipPublish = true
+		},
+		"draft": func(ctx *context.Context) {
+			ctx.Config.Release.Draft = true
+		},
+		"disabled": func(ctx *context.Context) {
+			ctx.Config.Release.Disable = true
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder, err := ioutil.TempDir("", "casktest")
+			require.NoError(t, err)
+			var ctx = caskContext(t, folder, config.HomebrewCask{
+				Repository: config.Repo{Owner: "foo", Name: "homebrew-tap"},
+			}, "amd64")
+			setup(ctx)
+			var client = &DummyClient{}
+			testlib.AssertSkipped(t, doRun(ctx, ctx.Config.HomebrewCasks[0], client))
+			require.False(t, client.CreatedFile)
+			require.FileExists(t, filepath.Join(folder, "foo.rb"))
+		})
+	}
+}
+
+type DummyClient struct {
+	CreatedFile bool
+	Content     string
+	Path        string
+}
+
+func (client *DummyClient) CreateRelease(ctx *context.Context, body string) (releaseID string, err error) {
+	return
+}
+
+func (client *DummyClient) CreateFile(ctx *context.Context, commitAuthor config.CommitAuthor, repo config.Repo, content []byte, path, msg string) (err error) {
+	client.CreatedFile = true
+	client.Content = string(content)
+	client.Path = path
+	return
+}
+
+func (client *DummyClient) Upload(ctx *context.Context, releaseID string, artifact *artifact.Artifact, file *os.File) (err error) {
+	return
+}
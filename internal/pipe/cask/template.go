@@ -0,0 +1,93 @@
+package cask
+
+import "github.com/goreleaser/goreleaser/pkg/config"
+
+type templateData struct {
+	Name         string
+	Version      string
+	Desc         string
+	Homepage     string
+	Downloads    []download
+	App          string
+	Pkg          string
+	Binaries     []string
+	Dependencies []config.HomebrewCaskDependency
+	Conflicts    []string
+	Zap          []string
+	Caveats      []string
+}
+
+// download is the archive of the cask, guarded by an arch block when there
+// are archives for both intel and arm macs
+type download struct {
+	Block  string
+	URL    string
+	SHA256 string
+}
+
+const caskTemplate = `# This file was generated by GoReleaser. DO NOT EDIT.
+cask {{ quote .Name }} do
+  version {{ quote .Version }}
+{{- range .Downloads }}
+{{- if .Block }}
+
+  on_{{ .Block }} do
+    url {{ quote .URL }}
+    sha256 {{ quote .SHA256 }}
+  end
+{{- else }}
+
+  url {{ quote .URL }}
+  sha256 {{ quote .SHA256 }}
+{{- end }}
+{{- end }}
+
+  name {{ quote .Name }}
+{{- if .Desc }}
+  desc {{ quote .Desc }}
+{{- end }}
+{{- if .Homepage }}
+  homepage {{ quote .Homepage }}
+{{- end }}
+{{- with .Dependencies }}
+{{ range . }}
+{{- if .Cask }}
+  depends_on cask: {{ quote .Cask }}
+{{- end }}
+{{- if .Formula }}
+  depends_on formula: {{ quote .Formula }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- with .Conflicts }}
+{{ range . }}
+  conflicts_with cask: {{ quote . }}
+{{- end }}
+{{- end }}
+{{ if .App }}
+  app {{ quote .App }}
+{{- end }}
+{{- if .Pkg }}
+  pkg {{ quote .Pkg }}
+{{- end }}
+{{- range .Binaries }}
+  binary {{ quote . }}
+{{- end }}
+{{- with .Zap }}
+
+  zap trash: [
+{{- range . }}
+    {{ quote . }},
+{{- end }}
+  ]
+{{- end }}
+{{- with .Caveats }}
+
+  caveats <<~EOS
+{{- range . }}
+    {{ . }}
+{{- end }}
+  EOS
+{{- end }}
+end
+`
@@ -2,7 +2,6 @@
 package changelog
 
 import (
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
@@ -13,7 +12,9 @@ import (
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/git"
 	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
 )
 
 // ErrInvalidSortDirection happens when the sort order is invalid
@@ -46,13 +47,30 @@ func (Pipe) Run(ctx *context.Context) error {
 	if ctx.ReleaseNotes != "" {
 		return nil
 	}
-	if err := checkSortDirection(ctx.Config.Changelog.Sort); err != nil {
+	notes, err := Notes(ctx)
+	if err != nil {
 		return err
 	}
-	entries, err := buildChangelog(ctx)
+	notes, err = WithHeaderAndFooter(ctx, notes)
 	if err != nil {
 		return err
 	}
+	ctx.ReleaseNotes = notes
+	var path = filepath.Join(ctx.Config.Dist, "CHANGELOG.md")
+	log.WithField("changelog", path).Info("writing")
+	return ioutil.WriteFile(path, []byte(ctx.ReleaseNotes), 0644)
+}
+
+// Notes builds the changelog of the current tag, without writing it
+// anywhere
+func Notes(ctx *context.Context) (string, error) {
+	if err := checkSortDirection(ctx.Config.Changelog.Sort); err != nil {
+		return "", err
+	}
+	entries, err := buildChangelog(ctx)
+	if err != nil {
+		return "", err
+	}
 
 	changelogStringJoiner := "\n"
 	if ctx.TokenType == context.TokenTypeGitLab || ctx.TokenType == context.TokenTypeGitea {
@@ -61,10 +79,38 @@ func (Pipe) Run(ctx *context.Context) error {
 		log.Debug("is gitlab or gitea changelog")
 		changelogStringJoiner = "   \n"
 	}
-	ctx.ReleaseNotes = fmt.Sprintf("## Changelog\n\n%v\n", strings.Join(entries, changelogStringJoiner))
-	var path = filepath.Join(ctx.Config.Dist, "CHANGELOG.md")
-	log.WithField("changelog", path).Info("writing")
-	return ioutil.WriteFile(path, []byte(ctx.ReleaseNotes), 0644)
+	return fmt.Sprintf("## Changelog\n\n%v\n", strings.Join(entries, changelogStringJoiner)), nil
+}
+
+// WithHeaderAndFooter adds the release header and footer around the given
+// changelog. They are either loaded from the files given in the command line
+// or templated from the release config.
+func WithHeaderAndFooter(ctx *context.Context, notes string) (string, error) {
+	header, err := headerOrFooter(ctx, ctx.ReleaseHeaderFile, ctx.Config.Release.Header)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to load the release header")
+	}
+	footer, err := headerOrFooter(ctx, ctx.ReleaseFooterFile, ctx.Config.Release.Footer)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to load the release footer")
+	}
+	var parts []string
+	for _, part := range []string{header, notes, footer} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return "", nil
+	}
+	return strings.Join(parts, "\n\n") + "\n", nil
+}
+
+func headerOrFooter(ctx *context.Context, file, template string) (string, error) {
+	if file != "" {
+		return loadFromFile(file)
+	}
+	return tmpl.New(ctx).Apply(template)
 }
 
 func loadFromFile(file string) (string, error) {
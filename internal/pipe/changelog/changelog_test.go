@@ -314,3 +314,66 @@ func TestChangelogOnBranchWithSameNameAsTag(t *testing.T) {
 		require.Contains(t, ctx.ReleaseNotes, msg)
 	}
 }
+
+func TestChangelogWithHeaderAndFooter(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	testlib.GitCommit(t, "first")
+	testlib.GitTag(t, "v0.0.1")
+	testlib.GitCommit(t, "added feature 1")
+	testlib.GitTag(t, "v0.0.2")
+	var ctx = context.New(config.Project{
+		Dist: folder,
+		Release: config.Release{
+			Header: "## {{ .Tag }} is out\n",
+			Footer: "Thanks to all contributors!",
+		},
+	})
+	ctx.Git.CurrentTag = "v0.0.2"
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Regexp(t, "^## v0.0.2 is out\n\n## Changelog\n\n[0-9a-f]+ added feature 1\n\nThanks to all contributors!\n$", ctx.ReleaseNotes)
+
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "CHANGELOG.md"))
+	require.NoError(t, err)
+	require.Equal(t, ctx.ReleaseNotes, string(bts))
+}
+
+func TestChangelogWithHeaderAndFooterFiles(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			Header: "{{ .Nope }}",
+			Footer: "footer",
+		},
+	})
+	ctx.ReleaseHeaderFile = "testdata/changes.md"
+	notes, err := WithHeaderAndFooter(ctx, "## Changelog\n")
+	require.NoError(t, err)
+	require.Equal(t, "c0ff33 coffeee\n\n## Changelog\n\nfooter\n", notes)
+
+	ctx.ReleaseFooterFile = "testdata/changes.nope"
+	_, err = WithHeaderAndFooter(ctx, "## Changelog\n")
+	require.EqualError(t, err, "failed to load the release footer: open testdata/changes.nope: no such file or directory")
+}
+
+func TestChangelogWithInvalidHeader(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			Header: "{{ .Nope }}",
+		},
+	})
+	_, err := WithHeaderAndFooter(ctx, "## Changelog\n")
+	require.Contains(t, err.Error(), "failed to load the release header")
+}
+
+func TestChangelogProvidedViaFlagIgnoresHeaderAndFooter(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			Header: "header",
+			Footer: "footer",
+		},
+	})
+	ctx.ReleaseNotes = "testdata/changes.md"
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, "c0ff33 coffeee\n", ctx.ReleaseNotes)
+}
@@ -15,6 +15,12 @@ import (
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
+const (
+	defaultNameTemplate         = "{{ .ProjectName }}_{{ .Version }}_checksums.txt"
+	defaultMultipleNameTemplate = "{{ .ProjectName }}_{{ .Version }}_{{ .Algorithm }}_checksums.txt"
+	defaultAlgorithm            = "sha256"
+)
+
 // Pipe for checksums
 type Pipe struct{}
 
@@ -24,54 +30,122 @@ func (Pipe) String() string {
 
 // Default sets the pipe defaults
 func (Pipe) Default(ctx *context.Context) error {
-	if ctx.Config.Checksum.NameTemplate == "" {
-		ctx.Config.Checksum.NameTemplate = "{{ .ProjectName }}_{{ .Version }}_checksums.txt"
+	var cfg = &ctx.Config.Checksum
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = defaultAlgorithm
+	}
+	if len(cfg.Algorithms) == 0 {
+		cfg.Algorithms = []string{cfg.Algorithm}
 	}
-	if ctx.Config.Checksum.Algorithm == "" {
-		ctx.Config.Checksum.Algorithm = "sha256"
+	if cfg.NameTemplate == "" {
+		cfg.NameTemplate = defaultNameTemplate
+		if len(cfg.Algorithms) > 1 {
+			cfg.NameTemplate = defaultMultipleNameTemplate
+		}
 	}
 	return nil
 }
 
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) (err error) {
-	filename, err := tmpl.New(ctx).Apply(ctx.Config.Checksum.NameTemplate)
-	if err != nil {
-		return err
+	var artifacts = ctx.Artifacts.Filter(
+		artifact.And(
+			artifact.ByUploadable(),
+			artifact.Not(artifact.Or(
+				artifact.ByType(artifact.Checksum),
+				artifact.ByType(artifact.Signature),
+				artifact.ByType(artifact.Certificate),
+			)),
+		),
+	).List()
+	if ctx.Config.Checksum.Split {
+		return split(ctx, artifacts)
 	}
-	file, err := os.OpenFile(
-		filepath.Join(ctx.Config.Dist, filename),
-		os.O_APPEND|os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
-		0444,
-	)
-	if err != nil {
-		return err
+	var names = map[string]bool{}
+	var g = semerrgroup.New(ctx.Parallelism)
+	for _, algorithm := range algorithms(ctx) {
+		filename, err := tmpl.New(ctx).
+			WithExtraFields(tmpl.Fields{"Algorithm": algorithm}).
+			ApplyName(ctx.Config.Checksum.NameTemplate)
+		if err != nil {
+			return err
+		}
+		if names[filename] {
+			return fmt.Errorf("checksum file %s already exists, use {{ .Algorithm }} in the checksum name template", filename)
+		}
+		names[filename] = true
+		file, err := os.OpenFile(
+			filepath.Join(ctx.Config.Dist, filename),
+			os.O_APPEND|os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
+			0444,
+		)
+		if err != nil {
+			return err
+		}
+		defer file.Close() // nolint: errcheck
+
+		for _, artifact := range artifacts {
+			algorithm, artifact := algorithm, artifact
+			g.Go(func() error {
+				return checksums(algorithm, file, artifact)
+			})
+		}
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type: artifact.Checksum,
+			Path: file.Name(),
+			Name: filename,
+			Extra: map[string]interface{}{
+				"Algorithm": algorithm,
+			},
+		})
 	}
-	defer file.Close() // nolint: errcheck
+	return g.Wait()
+}
 
+// split writes a checksum file for each artifact and algorithm, named after
+// the artifact, e.g. foo.tar.gz.sha256.
+func split(ctx *context.Context, artifacts []*artifact.Artifact) error {
 	var g = semerrgroup.New(ctx.Parallelism)
-	for _, artifact := range ctx.Artifacts.Filter(
-		artifact.Or(
-			artifact.ByType(artifact.UploadableArchive),
-			artifact.ByType(artifact.UploadableBinary),
-			artifact.ByType(artifact.LinuxPackage),
-		),
-	).List() {
-		artifact := artifact
-		g.Go(func() error {
-			return checksums(ctx.Config.Checksum.Algorithm, file, artifact)
-		})
+	for _, algorithm := range algorithms(ctx) {
+		for _, a := range artifacts {
+			algorithm, a := algorithm, a
+			g.Go(func() error {
+				var name = a.Name + "." + algorithm
+				var path = filepath.Join(ctx.Config.Dist, name)
+				file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0444)
+				if err != nil {
+					return err
+				}
+				defer file.Close() // nolint: errcheck
+				if err := checksums(algorithm, file, a); err != nil {
+					return err
+				}
+				ctx.Artifacts.Add(&artifact.Artifact{
+					Type: artifact.Checksum,
+					Path: path,
+					Name: name,
+					Extra: map[string]interface{}{
+						"Algorithm": algorithm,
+					},
+				})
+				return nil
+			})
+		}
 	}
-	ctx.Artifacts.Add(&artifact.Artifact{
-		Type: artifact.Checksum,
-		Path: file.Name(),
-		Name: filename,
-	})
 	return g.Wait()
 }
 
+// algorithms returns the checksum algorithms to use, falling back to the
+// single algorithm setting
+func algorithms(ctx *context.Context) []string {
+	if len(ctx.Config.Checksum.Algorithms) > 0 {
+		return ctx.Config.Checksum.Algorithms
+	}
+	return []string{ctx.Config.Checksum.Algorithm}
+}
+
 func checksums(algorithm string, w io.Writer, artifact *artifact.Artifact) error {
-	log.WithField("file", artifact.Name).Info("checksumming")
+	log.WithField("file", artifact.Name).WithField("algorithm", algorithm).Info("checksumming")
 	sha, err := artifact.Checksum(algorithm)
 	if err != nil {
 		return err
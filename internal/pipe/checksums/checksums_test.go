@@ -2,6 +2,7 @@ package checksums
 
 import (
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -45,6 +46,31 @@ func TestPipe(t *testing.T) {
 		Path: file,
 		Type: artifact.UploadableArchive,
 	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: binary + ".docker.tar",
+		Path: file,
+		Type: artifact.UploadableDockerImage,
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: binary + ".flatpak",
+		Path: file,
+		Type: artifact.Flatpak,
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: binary + ".AppImage",
+		Path: file,
+		Type: artifact.AppImage,
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: binary + ".msi",
+		Path: file,
+		Type: artifact.MSI,
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: binary + ".pkg",
+		Path: file,
+		Type: artifact.MacOSPackage,
+	})
 	assert.NoError(t, Pipe{}.Run(ctx))
 	var artifacts []string
 	for _, a := range ctx.Artifacts.List() {
@@ -55,6 +81,11 @@ func TestPipe(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Contains(t, string(bts), "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  binary")
 	assert.Contains(t, string(bts), "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  binary.tar.gz")
+	assert.Contains(t, string(bts), "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  binary.docker.tar")
+	assert.Contains(t, string(bts), "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  binary.flatpak")
+	assert.Contains(t, string(bts), "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  binary.AppImage")
+	assert.Contains(t, string(bts), "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  binary.msi")
+	assert.Contains(t, string(bts), "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  binary.pkg")
 }
 
 func TestPipeFileNotExist(t *testing.T) {
@@ -144,6 +175,23 @@ func TestDefault(t *testing.T) {
 		ctx.Config.Checksum.NameTemplate,
 	)
 	assert.Equal(t, "sha256", ctx.Config.Checksum.Algorithm)
+	assert.Equal(t, []string{"sha256"}, ctx.Config.Checksum.Algorithms)
+}
+
+func TestDefaultMultipleAlgorithms(t *testing.T) {
+	var ctx = &context.Context{
+		Config: config.Project{
+			Checksum: config.Checksum{
+				Algorithms: []string{"sha256", "sha512"},
+			},
+		},
+	}
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Equal(
+		t,
+		"{{ .ProjectName }}_{{ .Version }}_{{ .Algorithm }}_checksums.txt",
+		ctx.Config.Checksum.NameTemplate,
+	)
 }
 
 func TestDefaultSet(t *testing.T) {
@@ -157,3 +205,87 @@ func TestDefaultSet(t *testing.T) {
 	assert.NoError(t, Pipe{}.Default(ctx))
 	assert.Equal(t, "checksums.txt", ctx.Config.Checksum.NameTemplate)
 }
+
+func TestPipeMultipleAlgorithms(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var file = filepath.Join(folder, "binary")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("some string"), 0644))
+	var ctx = context.New(
+		config.Project{
+			Dist:        folder,
+			ProjectName: "binary",
+			Checksum: config.Checksum{
+				NameTemplate: "{{ .Algorithm }}sums.txt",
+				Algorithms:   []string{"sha256", "sha512", "blake2b"},
+			},
+		},
+	)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "binary",
+		Path: file,
+		Type: artifact.UploadableBinary,
+	})
+	assert.NoError(t, Pipe{}.Run(ctx))
+	var sums = ctx.Artifacts.Filter(artifact.ByType(artifact.Checksum)).List()
+	assert.Len(t, sums, 3)
+	for algorithm, sum := range map[string]string{
+		"sha256":  "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc",
+		"sha512":  "14925e01a7a0cf0801aa95fe52d542b578af58ae7997ada66db3a6eae68a329d50600a5b7b442eabf4ea77ea8ef5fe40acf2ab31d47311b2a232c4f64009aac1",
+		"blake2b": "5823d250906e17854136b9a9381712377d6b9f81dc694db47a9edfb2a48f6964b3f2e2b3c5f7560d13f2137480c79ba3cfc3d5f1453bc6691eb04def115cae08",
+	} {
+		bts, err := ioutil.ReadFile(filepath.Join(folder, algorithm+"sums.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, sum+"  binary\n", string(bts))
+	}
+	assert.Equal(t, "sha256", sums[0].ExtraOr("Algorithm", ""))
+}
+
+func TestPipeMultipleAlgorithmsSameName(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var ctx = context.New(
+		config.Project{
+			Dist: folder,
+			Checksum: config.Checksum{
+				NameTemplate: "checksums.txt",
+				Algorithms:   []string{"sha256", "sha512"},
+			},
+		},
+	)
+	assert.EqualError(t, Pipe{}.Run(ctx), "checksum file checksums.txt already exists, use {{ .Algorithm }} in the checksum name template")
+}
+
+func TestPipeSplit(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var file = filepath.Join(folder, "binary")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("some string"), 0644))
+	var ctx = context.New(
+		config.Project{
+			Dist: folder,
+			Checksum: config.Checksum{
+				NameTemplate: "checksums.txt",
+				Algorithms:   []string{"sha256", "sha1"},
+				Split:        true,
+			},
+		},
+	)
+	for _, name := range []string{"binary", "binary.tar.gz"} {
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: name,
+			Path: file,
+			Type: artifact.UploadableArchive,
+		})
+	}
+	assert.NoError(t, Pipe{}.Run(ctx))
+	assert.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.Checksum)).List(), 4)
+	_, err = os.Stat(filepath.Join(folder, "checksums.txt"))
+	assert.True(t, os.IsNotExist(err))
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "binary.tar.gz.sha256"))
+	assert.NoError(t, err)
+	assert.Equal(t, "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  binary.tar.gz\n", string(bts))
+	bts, err = ioutil.ReadFile(filepath.Join(folder, "binary.sha1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "8b45e4bd1c6acb88bebf6407d16205f567e62a3e  binary\n", string(bts))
+}
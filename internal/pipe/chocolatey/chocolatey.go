@@ -0,0 +1,332 @@
+// Package chocolatey provides a Pipe that packs a chocolatey package
+// installing the windows release archives and pushes it to a nuget feed.
+package chocolatey
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoChoco is shown when choco cannot be found in $PATH
+var ErrNoChoco = errors.New("choco not present in $PATH")
+
+// ErrNoWindowsArchives happens when there are no windows zip archives to
+// install
+var ErrNoWindowsArchives = errors.New("chocolatey requires windows zip archives for amd64 or 386")
+
+// ErrNoAPIKey happens when the api key of the feed renders empty
+var ErrNoAPIKey = errors.New("chocolatey api_key is empty")
+
+const defaultSourceRepo = "https://push.chocolatey.org/"
+
+// Pipe for chocolatey packaging
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "chocolatey packages"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Chocolateys {
+		var choco = &ctx.Config.Chocolateys[i]
+		if choco.Name == "" {
+			choco.Name = ctx.Config.ProjectName
+		}
+		if choco.Title == "" {
+			choco.Title = choco.Name
+		}
+		if choco.Authors == "" {
+			choco.Authors = ctx.Config.Metadata.Vendor
+		}
+		if choco.Authors == "" {
+			choco.Authors = ctx.Config.ProjectName
+		}
+		if choco.Description == "" {
+			choco.Description = ctx.Config.Metadata.Description
+		}
+		if choco.ProjectURL == "" {
+			choco.ProjectURL = ctx.Config.Metadata.Homepage
+		}
+		if choco.SourceRepo == "" {
+			choco.SourceRepo = defaultSourceRepo
+		}
+		if choco.APIKey == "" {
+			choco.APIKey = "{{ .Env.CHOCOLATEY_API_KEY }}"
+		}
+	}
+	return nil
+}
+
+// Publish packs and pushes the chocolatey packages
+func (Pipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.Chocolateys) == 0 {
+		return pipe.Skip("chocolatey section is not configured")
+	}
+	if _, err := exec.LookPath("choco"); err != nil {
+		return ErrNoChoco
+	}
+	for _, choco := range ctx.Config.Chocolateys {
+		ok, err := condition.Check(ctx, choco.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("chocolatey", choco.Name).Info("skipped because its condition is false")
+			continue
+		}
+		if err := doRun(ctx, choco); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, choco config.Chocolatey) error {
+	var filters = []artifact.Filter{
+		artifact.ByGoos("windows"),
+		artifact.ByFormats("zip"),
+		artifact.ByType(artifact.UploadableArchive),
+		artifact.Or(
+			artifact.ByGoarch("amd64"),
+			artifact.ByGoarch("386"),
+		),
+	}
+	if len(choco.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(choco.IDs...))
+	}
+	var archives = ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(archives) == 0 {
+		return ErrNoWindowsArchives
+	}
+
+	var dir = filepath.Join(ctx.Config.Dist, "chocolatey", choco.Name)
+	if err := os.MkdirAll(filepath.Join(dir, "tools"), 0755); err != nil {
+		return err
+	}
+	script, err := buildInstallScript(ctx, choco, archives)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "tools", "chocolateyinstall.ps1"), script, 0644); err != nil {
+		return err
+	}
+	spec, err := buildNuspec(ctx, choco)
+	if err != nil {
+		return err
+	}
+	var specPath = filepath.Join(dir, choco.Name+".nuspec")
+	if err := ioutil.WriteFile(specPath, spec, 0644); err != nil {
+		return err
+	}
+
+	log.WithField("package", choco.Name).Info("packing")
+	if err := run(ctx, "pack", specPath, "--out", dir); err != nil {
+		return err
+	}
+	packages, err := filepath.Glob(filepath.Join(dir, "*.nupkg"))
+	if err != nil {
+		return err
+	}
+	if len(packages) != 1 {
+		return fmt.Errorf("expected choco to pack one package in %s, found %d", dir, len(packages))
+	}
+
+	if choco.SkipPublish {
+		return pipe.Skip("chocolatey.skip_publish is set")
+	}
+	if ctx.SkipPublish {
+		return pipe.ErrSkipPublishEnabled
+	}
+	if ctx.Config.Release.Draft {
+		return pipe.Skip("release is marked as draft")
+	}
+	if ctx.Config.Release.Disable {
+		return pipe.Skip("release is disabled")
+	}
+	key, err := tmpl.New(ctx).Apply(choco.APIKey)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return ErrNoAPIKey
+	}
+	log.WithField("package", filepath.Base(packages[0])).
+		WithField("source", choco.SourceRepo).
+		Info("pushing")
+	return push(ctx, choco.SourceRepo, key, packages[0])
+}
+
+// push uploads the package to the nuget feed like choco push does, so the
+// api key is sent in a header instead of being visible in the process list.
+func push(ctx *context.Context, source, key, path string) error {
+	u, err := url.Parse(source)
+	if err != nil {
+		return errors.Wrap(err, "invalid chocolatey source_repo")
+	}
+	// feeds given by their host only get the nuget v2 push endpoint
+	if strings.Trim(u.Path, "/") == "" {
+		u.Path = "/api/v2/package/"
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint: errcheck
+	var body bytes.Buffer
+	var form = multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("package", filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("X-NuGet-ApiKey", key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to push %s", filepath.Base(path))
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		bts, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("failed to push %s: %s: %s", filepath.Base(path), resp.Status, string(bts))
+	}
+	return nil
+}
+
+func run(ctx *context.Context, args ...string) error {
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "choco", args...)
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to run choco %s: %s", args[0], string(out))
+	}
+	return nil
+}
+
+func buildNuspec(ctx *context.Context, choco config.Chocolatey) ([]byte, error) {
+	var notes = choco.ReleaseNotes
+	if notes == "" {
+		notes = ctx.ReleaseNotes
+	} else {
+		var err error
+		if notes, err = tmpl.New(ctx).Apply(notes); err != nil {
+			return nil, err
+		}
+	}
+	var spec = Nuspec{
+		Xmlns: nuspecSchema,
+		Metadata: Metadata{
+			ID:                       choco.Name,
+			Version:                  ctx.Version,
+			PackageSourceURL:         choco.PackageSourceURL,
+			Owners:                   choco.Owners,
+			Title:                    choco.Title,
+			Authors:                  choco.Authors,
+			ProjectURL:               choco.ProjectURL,
+			IconURL:                  choco.IconURL,
+			Copyright:                choco.Copyright,
+			LicenseURL:               choco.LicenseURL,
+			RequireLicenseAcceptance: choco.RequireLicenseAcceptance,
+			ProjectSourceURL:         choco.ProjectSourceURL,
+			DocsURL:                  choco.DocsURL,
+			BugTrackerURL:            choco.BugTrackerURL,
+			Tags:                     choco.Tags,
+			Summary:                  choco.Summary,
+			Description:              choco.Description,
+			ReleaseNotes:             notes,
+		},
+		Files: Files{File: []File{{Source: `tools\**`, Target: "tools"}}},
+	}
+	if len(choco.Dependencies) > 0 {
+		spec.Metadata.Dependencies = &Dependencies{}
+		for _, dep := range choco.Dependencies {
+			spec.Metadata.Dependencies.Dependency = append(
+				spec.Metadata.Dependencies.Dependency,
+				Dependency{ID: dep.ID, Version: dep.Version},
+			)
+		}
+	}
+	return spec.Bytes()
+}
+
+// download is the archive the install script downloads for an arch
+type download struct {
+	URL    string
+	SHA256 string
+}
+
+func buildInstallScript(ctx *context.Context, choco config.Chocolatey, archives []*artifact.Artifact) ([]byte, error) {
+	if choco.URLTemplate == "" {
+		urlTemplate, err := client.DownloadURLTemplate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		choco.URLTemplate = urlTemplate
+	}
+
+	var downloads = map[string]*download{}
+	for _, archive := range archives {
+		if downloads[archive.Goarch] != nil {
+			return nil, fmt.Errorf("chocolatey %s: found multiple windows %s archives, use ids to pick one", choco.Name, archive.Goarch)
+		}
+		url, err := tmpl.New(ctx).WithArtifact(archive, map[string]string{}).Apply(choco.URLTemplate)
+		if err != nil {
+			return nil, err
+		}
+		sum, err := archive.Checksum("sha256")
+		if err != nil {
+			return nil, err
+		}
+		downloads[archive.Goarch] = &download{URL: url, SHA256: sum}
+	}
+
+	t, err := template.New(choco.Name).
+		Funcs(template.FuncMap{"quote": quote}).
+		Parse(installTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	err = t.Execute(&out, struct{ X86, X64 *download }{
+		X86: downloads["386"],
+		X64: downloads["amd64"],
+	})
+	return out.Bytes(), err
+}
+
+// quote returns the given string as a powershell single-quoted string
+func quote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,331 @@
+package chocolatey
+
+import (
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update .golden files")
+
+// testdata is resolved before the tests move to their temporary folders
+var testdata, _ = filepath.Abs("testdata")
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Metadata: config.Metadata{
+			Description: "A foo",
+			Homepage:    "https://example.com",
+			Vendor:      "Foo Inc",
+		},
+		Chocolateys: []config.Chocolatey{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Chocolatey{
+		Name:        "foo",
+		Title:       "foo",
+		Authors:     "Foo Inc",
+		Description: "A foo",
+		ProjectURL:  "https://example.com",
+		SourceRepo:  "https://push.chocolatey.org/",
+		APIKey:      "{{ .Env.CHOCOLATEY_API_KEY }}",
+	}, ctx.Config.Chocolateys[0])
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Publish(context.New(config.Project{})))
+}
+
+func TestQuote(t *testing.T) {
+	require.Equal(t, `'it''s'`, quote("it's"))
+}
+
+// fakeChoco puts a fake choco in the PATH, which logs its arguments and
+// packs a package, returning a func that restores the PATH.
+func fakeChoco(t *testing.T, folder string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"choco": `echo "$@" >> ` + filepath.Join(folder, "choco.log") + `
+if [ "$1" = "pack" ]; then
+	echo nupkg > "$4/foo.1.0.0.nupkg"
+fi
+`,
+	})
+}
+
+// fakeFeed is a nuget feed recording the pushed packages.
+type fakeFeed struct {
+	*httptest.Server
+	pushes []string
+}
+
+func newFakeFeed(t *testing.T) *fakeFeed {
+	var feed = &fakeFeed{}
+	feed.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-NuGet-ApiKey") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		file, header, err := r.FormFile("package")
+		require.NoError(t, err)
+		bts, err := ioutil.ReadAll(file)
+		require.NoError(t, err)
+		feed.pushes = append(feed.pushes, r.Method+" "+r.URL.Path+" "+header.Filename+" "+string(bts))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	return feed
+}
+
+func chocoContext(t *testing.T, folder string, choco config.Chocolatey) *context.Context {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        folder,
+		Metadata:    config.Metadata{Description: "A foo"},
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "foo", Name: "bar"},
+			GitLab: config.Repo{Owner: "foo", Name: "bar"},
+		},
+		GitHubURLs:  config.GitHubURLs{Download: "https://github.com"},
+		GitLabURLs:  config.GitLabURLs{Download: "https://gitlab.com"},
+		Chocolateys: []config.Chocolatey{choco},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.ReleaseNotes = "## Changelog\n\n* a change"
+	ctx.Env = map[string]string{"CHOCOLATEY_API_KEY": "secret"}
+	for _, archive := range []struct {
+		goarch, format, id string
+	}{
+		{"amd64", "zip", "default"},
+		{"386", "zip", "default"},
+		{"arm64", "zip", "default"},
+		{"amd64", "tar.gz", "default"},
+		{"amd64", "zip", "other"},
+	} {
+		var name = "foo_windows_" + archive.goarch + "_" + archive.id + "." + archive.format
+		var path = filepath.Join(folder, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(name), 0644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type:   artifact.UploadableArchive,
+			Name:   name,
+			Path:   path,
+			Goos:   "windows",
+			Goarch: archive.goarch,
+			Extra: map[string]interface{}{
+				"ID":     archive.id,
+				"Format": archive.format,
+			},
+		})
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestRunPipe(t *testing.T) {
+	for name, tt := range map[string]struct {
+		choco  config.Chocolatey
+		gitlab bool
+	}{
+		"default": {
+			choco: config.Chocolatey{IDs: []string{"default"}},
+		},
+		"full": {
+			choco: config.Chocolatey{
+				IDs:                      []string{"default"},
+				Title:                    "Foo & Bar",
+				Authors:                  "Foo Inc",
+				Owners:                   "foo",
+				Summary:                  "Foo things",
+				Tags:                     "foo bar",
+				Copyright:                "2020 Foo Inc",
+				ProjectURL:               "https://example.com",
+				PackageSourceURL:         "https://github.com/foo/choco",
+				ProjectSourceURL:         "https://github.com/foo/bar",
+				DocsURL:                  "https://example.com/docs",
+				BugTrackerURL:            "https://github.com/foo/bar/issues",
+				IconURL:                  "https://example.com/foo.png",
+				LicenseURL:               "https://github.com/foo/bar/blob/master/LICENSE",
+				RequireLicenseAcceptance: true,
+				ReleaseNotes:             "https://github.com/foo/bar/releases/tag/{{ .Tag }}",
+				Dependencies: []config.ChocolateyDependency{
+					{ID: "git"},
+					{ID: "vcredist140", Version: "[14.0,)"},
+				},
+			},
+		},
+		"gitlab": {
+			choco:  config.Chocolatey{IDs: []string{"default"}},
+			gitlab: true,
+		},
+		"url_template": {
+			choco: config.Chocolatey{
+				IDs:         []string{"other"},
+				URLTemplate: "https://dl.example.com/{{ .Tag }}/{{ .ArtifactName }}",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder, back := testlib.Mktmp(t)
+			defer back()
+			defer fakeChoco(t, folder)()
+			var feed = newFakeFeed(t)
+			defer feed.Close()
+			tt.choco.SourceRepo = feed.URL
+			var ctx = chocoContext(t, folder, tt.choco)
+			if tt.gitlab {
+				ctx.TokenType = context.TokenTypeGitLab
+				for _, a := range ctx.Artifacts.List() {
+					a.Extra["ArtifactUploadHash"] = "820ead5d9d2266c728dce6d4d55b6460"
+				}
+			}
+			require.NoError(t, Pipe{}.Publish(ctx))
+
+			var dir = filepath.Join(folder, "chocolatey", "foo")
+			for file, golden := range map[string]string{
+				"foo.nuspec":                  name + ".nuspec.golden",
+				"tools/chocolateyinstall.ps1": name + ".ps1.golden",
+			} {
+				bts, err := ioutil.ReadFile(filepath.Join(dir, file))
+				require.NoError(t, err)
+				var golden = filepath.Join(testdata, golden)
+				if *update {
+					require.NoError(t, ioutil.WriteFile(golden, bts, 0655))
+				}
+				expected, err := ioutil.ReadFile(golden)
+				require.NoError(t, err)
+				require.Equal(t, string(expected), string(bts))
+			}
+
+			log, err := ioutil.ReadFile(filepath.Join(folder, "choco.log"))
+			require.NoError(t, err)
+			require.Equal(t, "pack "+filepath.Join(dir, "foo.nuspec")+" --out "+dir+"\n", string(log))
+			require.Equal(t, []string{"PUT /api/v2/package/ foo.1.0.0.nupkg nupkg\n"}, feed.pushes)
+		})
+	}
+}
+
+func TestRunPipeFeedPath(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeChoco(t, folder)()
+	var feed = newFakeFeed(t)
+	defer feed.Close()
+	var ctx = chocoContext(t, folder, config.Chocolatey{
+		IDs:        []string{"default"},
+		SourceRepo: feed.URL + "/repository/choco/",
+	})
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.Equal(t, []string{"PUT /repository/choco/ foo.1.0.0.nupkg nupkg\n"}, feed.pushes)
+}
+
+func TestRunPipePushFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeChoco(t, folder)()
+	var feed = newFakeFeed(t)
+	defer feed.Close()
+	var ctx = chocoContext(t, folder, config.Chocolatey{
+		IDs:        []string{"default"},
+		SourceRepo: feed.URL,
+	})
+	ctx.Env["CHOCOLATEY_API_KEY"] = "wrong"
+	require.EqualError(t, Pipe{}.Publish(ctx), "failed to push foo.1.0.0.nupkg: 403 Forbidden: ")
+	require.Empty(t, feed.pushes)
+}
+
+func TestRunPipeNoArchives(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeChoco(t, folder)()
+	var ctx = chocoContext(t, folder, config.Chocolatey{IDs: []string{"nope"}})
+	require.Equal(t, ErrNoWindowsArchives, Pipe{}.Publish(ctx))
+}
+
+func TestRunPipeMultipleArchives(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeChoco(t, folder)()
+	var ctx = chocoContext(t, folder, config.Chocolatey{})
+	require.EqualError(t, Pipe{}.Publish(ctx), "chocolatey foo: found multiple windows amd64 archives, use ids to pick one")
+}
+
+func TestRunPipeTokenTypeNotImplemented(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeChoco(t, folder)()
+	var ctx = chocoContext(t, folder, config.Chocolatey{IDs: []string{"default"}})
+	ctx.TokenType = ""
+	require.Equal(t, client.ErrTokenTypeNotImplemented, Pipe{}.Publish(ctx))
+}
+
+func TestRunPipeNoAPIKey(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeChoco(t, folder)()
+	var ctx = chocoContext(t, folder, config.Chocolatey{IDs: []string{"default"}})
+	ctx.Env = map[string]string{"CHOCOLATEY_API_KEY": ""}
+	require.Equal(t, ErrNoAPIKey, Pipe{}.Publish(ctx))
+}
+
+func TestRunPipeChocoFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeChoco(t, folder)()
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "bin", "choco"),
+		[]byte("#!/bin/sh\necho 'invalid nuspec'\nexit 1\n"),
+		0755,
+	))
+	var ctx = chocoContext(t, folder, config.Chocolatey{IDs: []string{"default"}})
+	require.EqualError(t, Pipe{}.Publish(ctx), "failed to run choco pack: invalid nuspec\n")
+}
+
+func TestRunPipeSkipPublish(t *testing.T) {
+	for name, setup := range map[string]func(ctx *context.Context){
+		"skip_publish_config": func(ctx *context.Context) {
+			ctx.Config.Chocolateys[0].SkipPublish = true
+		},
+		"skip_publish": func(ctx *context.Context) {
+			ctx.SkipPublish = true
+		},
+		"draft": func(ctx *context.Context) {
+			ctx.Config.Release.Draft = true
+		},
+		"disabled": func(ctx *context.Context) {
+			ctx.Config.Release.Disable = true
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder, back := testlib.Mktmp(t)
+			defer back()
+			defer fakeChoco(t, folder)()
+			var ctx = chocoContext(t, folder, config.Chocolatey{IDs: []string{"default"}})
+			setup(ctx)
+			testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+			require.FileExists(t, filepath.Join(folder, "chocolatey", "foo", "foo.1.0.0.nupkg"))
+			log, err := ioutil.ReadFile(filepath.Join(folder, "choco.log"))
+			require.NoError(t, err)
+			require.NotContains(t, string(log), "push")
+		})
+	}
+}
+
+func TestRunPipeConditionFalse(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeChoco(t, folder)()
+	var ctx = chocoContext(t, folder, config.Chocolatey{If: "{{ .IsSnapshot }}"})
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.NoFileExists(t, filepath.Join(folder, "choco.log"))
+}
@@ -0,0 +1,76 @@
+package chocolatey
+
+import (
+	"bytes"
+	"encoding/xml"
+)
+
+const nuspecSchema = "http://schemas.microsoft.com/packaging/2015/06/nuspec.xsd"
+
+// Nuspec represents a nuget package specification, more info:
+// https://docs.microsoft.com/en-us/nuget/reference/nuspec
+type Nuspec struct {
+	XMLName  xml.Name `xml:"package"`
+	Xmlns    string   `xml:"xmlns,attr,omitempty"`
+	Metadata Metadata `xml:"metadata"`
+	Files    Files    `xml:"files,omitempty"`
+}
+
+// Metadata contains information about the package
+type Metadata struct {
+	ID                       string        `xml:"id"`
+	Version                  string        `xml:"version"`
+	PackageSourceURL         string        `xml:"packageSourceUrl,omitempty"`
+	Owners                   string        `xml:"owners,omitempty"`
+	Title                    string        `xml:"title,omitempty"`
+	Authors                  string        `xml:"authors"`
+	ProjectURL               string        `xml:"projectUrl,omitempty"`
+	IconURL                  string        `xml:"iconUrl,omitempty"`
+	Copyright                string        `xml:"copyright,omitempty"`
+	LicenseURL               string        `xml:"licenseUrl,omitempty"`
+	RequireLicenseAcceptance bool          `xml:"requireLicenseAcceptance"`
+	ProjectSourceURL         string        `xml:"projectSourceUrl,omitempty"`
+	DocsURL                  string        `xml:"docsUrl,omitempty"`
+	BugTrackerURL            string        `xml:"bugTrackerUrl,omitempty"`
+	Tags                     string        `xml:"tags,omitempty"`
+	Summary                  string        `xml:"summary,omitempty"`
+	Description              string        `xml:"description"`
+	ReleaseNotes             string        `xml:"releaseNotes,omitempty"`
+	Dependencies             *Dependencies `xml:"dependencies,omitempty"`
+}
+
+// Dependencies are the packages the package depends on
+type Dependencies struct {
+	Dependency []Dependency `xml:"dependency"`
+}
+
+// Dependency is a package the package depends on, optionally restricted to
+// a version range
+type Dependency struct {
+	ID      string `xml:"id,attr"`
+	Version string `xml:"version,attr,omitempty"`
+}
+
+// Files are the files included in the package
+type Files struct {
+	File []File `xml:"file"`
+}
+
+// File is a set of files included in the package
+type File struct {
+	Source string `xml:"src,attr"`
+	Target string `xml:"target,attr,omitempty"`
+}
+
+// Bytes returns the nuspec as an indented XML document
+func (n Nuspec) Bytes() ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteString(xml.Header)
+	var enc = xml.NewEncoder(&out)
+	enc.Indent("", "  ")
+	if err := enc.Encode(n); err != nil {
+		return nil, err
+	}
+	out.WriteString("\n")
+	return out.Bytes(), nil
+}
@@ -0,0 +1,25 @@
+package chocolatey
+
+// installTemplate is the tools/chocolateyinstall.ps1 script, which downloads
+// the release archive matching the machine arch and extracts it to the tools
+// folder, where chocolatey shims its executables.
+const installTemplate = `$ErrorActionPreference = 'Stop'
+$toolsDir = "$(Split-Path -parent $MyInvocation.MyCommand.Definition)"
+
+$packageArgs = @{
+  packageName    = $env:ChocolateyPackageName
+  unzipLocation  = $toolsDir
+{{- with .X86 }}
+  url            = {{ quote .URL }}
+  checksum       = {{ quote .SHA256 }}
+  checksumType   = 'sha256'
+{{- end }}
+{{- with .X64 }}
+  url64bit       = {{ quote .URL }}
+  checksum64     = {{ quote .SHA256 }}
+  checksumType64 = 'sha256'
+{{- end }}
+}
+
+Install-ChocolateyZipPackage @packageArgs
+`
@@ -0,0 +1,150 @@
+// Package ci provides a Pipe that detects the CI system goreleaser is
+// running on and exports the release results using its native mechanism.
+package ci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// System is a supported CI system
+type System string
+
+const (
+	// GitHubActions also covers Gitea and Forgejo Actions, which are
+	// compatible with it
+	GitHubActions System = "github-actions"
+	// GitLabCI is GitLab's CI
+	GitLabCI System = "gitlab-ci"
+	// Woodpecker is Woodpecker CI
+	Woodpecker System = "woodpecker"
+	// Jenkins is Jenkins
+	Jenkins System = "jenkins"
+)
+
+// Pipe that exports CI outputs
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "exporting CI outputs"
+}
+
+type output struct {
+	key, value string
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	artifacts, err := writeArtifacts(ctx)
+	if err != nil {
+		return err
+	}
+	var system = Detect(ctx.Env)
+	if system == "" {
+		return pipe.Skip("no CI environment detected")
+	}
+	var outputs = []output{
+		{"version", ctx.Version},
+		{"tag", ctx.Git.CurrentTag},
+		{"release_url", ctx.ReleaseURL},
+		{"artifacts", artifacts},
+	}
+	log.WithField("ci", system).Info("exporting outputs")
+	switch system {
+	case GitHubActions:
+		return writeGitHubOutputs(ctx, outputs)
+	case Jenkins:
+		return writeLines(ctx, "goreleaser.properties", outputs)
+	default:
+		return writeLines(ctx, "goreleaser.env", outputs)
+	}
+}
+
+// Detect returns the CI system described by the given environment, if any.
+func Detect(env context.Env) System {
+	switch {
+	case env["GITHUB_ACTIONS"] == "true",
+		env["GITEA_ACTIONS"] == "true",
+		env["FORGEJO_ACTIONS"] == "true":
+		return GitHubActions
+	case env["GITLAB_CI"] == "true":
+		return GitLabCI
+	case env["CI"] == "woodpecker":
+		return Woodpecker
+	case env["JENKINS_URL"] != "":
+		return Jenkins
+	}
+	return ""
+}
+
+type artifactOutput struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Goos   string `json:"goos,omitempty"`
+	Goarch string `json:"goarch,omitempty"`
+	Goarm  string `json:"goarm,omitempty"`
+	Type   string `json:"type"`
+}
+
+func writeArtifacts(ctx *context.Context) (string, error) {
+	// nolint: prealloc
+	var result []artifactOutput
+	for _, a := range ctx.Artifacts.List() {
+		result = append(result, artifactOutput{
+			Name:   a.Name,
+			Path:   a.Path,
+			Goos:   a.Goos,
+			Goarch: a.Goarch,
+			Goarm:  a.Goarm,
+			Type:   a.Type.String(),
+		})
+	}
+	bts, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	var path = filepath.Join(ctx.Config.Dist, "artifacts.json")
+	log.WithField("file", path).Info("writing artifact list")
+	return path, ioutil.WriteFile(path, bts, 0644)
+}
+
+// writeGitHubOutputs appends the outputs to the $GITHUB_OUTPUT file.
+func writeGitHubOutputs(ctx *context.Context, outputs []output) error {
+	var path = ctx.Env["GITHUB_OUTPUT"]
+	if path == "" {
+		return pipe.Skip("GITHUB_OUTPUT is not set")
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to open GITHUB_OUTPUT")
+	}
+	defer f.Close() // nolint: errcheck
+	for _, o := range outputs {
+		if _, err := fmt.Fprintf(f, "%s=%s\n", o.key, o.value); err != nil {
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// writeLines writes the outputs as GORELEASER_KEY=value lines to the given
+// file inside the dist folder, which works both as a dotenv and as a java
+// properties file.
+func writeLines(ctx *context.Context, name string, outputs []output) error {
+	var sb strings.Builder
+	for _, o := range outputs {
+		sb.WriteString(fmt.Sprintf("GORELEASER_%s=%s\n", strings.ToUpper(o.key), o.value))
+	}
+	var path = filepath.Join(ctx.Config.Dist, name)
+	log.WithField("file", path).Info("writing")
+	return ioutil.WriteFile(path, []byte(sb.String()), 0644)
+}
@@ -0,0 +1,113 @@
+package ci
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	for expected, env := range map[System]context.Env{
+		GitHubActions: {"GITHUB_ACTIONS": "true"},
+		GitLabCI:      {"GITLAB_CI": "true"},
+		Woodpecker:    {"CI": "woodpecker"},
+		Jenkins:       {"JENKINS_URL": "https://jenkins.local"},
+		"":            {"CI": "true"},
+	} {
+		assert.Equal(t, expected, Detect(env))
+	}
+	assert.Equal(t, GitHubActions, Detect(context.Env{"FORGEJO_ACTIONS": "true"}))
+}
+
+func newContext(dist string, env context.Env) *context.Context {
+	var ctx = context.New(config.Project{
+		Dist: dist,
+	})
+	ctx.Env = env
+	ctx.Version = "1.2.3"
+	ctx.Git.CurrentTag = "v1.2.3"
+	ctx.ReleaseURL = "https://github.com/goreleaser/fake/releases/tag/v1.2.3"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "fake_linux_amd64.tar.gz",
+		Path:   "dist/fake_linux_amd64.tar.gz",
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.UploadableArchive,
+	})
+	return ctx
+}
+
+func TestRunNoCI(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = newContext(folder, context.Env{})
+	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "artifacts.json"))
+	require.NoError(t, err)
+	var artifacts []artifactOutput
+	require.NoError(t, json.Unmarshal(bts, &artifacts))
+	assert.Equal(t, []artifactOutput{
+		{
+			Name:   "fake_linux_amd64.tar.gz",
+			Path:   "dist/fake_linux_amd64.tar.gz",
+			Goos:   "linux",
+			Goarch: "amd64",
+			Type:   "Archive",
+		},
+	}, artifacts)
+}
+
+func TestRunGitHubActions(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var out = filepath.Join(folder, "output")
+	require.NoError(t, ioutil.WriteFile(out, []byte("foo=bar\n"), 0644))
+	var ctx = newContext(folder, context.Env{
+		"GITHUB_ACTIONS": "true",
+		"GITHUB_OUTPUT":  out,
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	bts, err := ioutil.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "foo=bar\n"+
+		"version=1.2.3\n"+
+		"tag=v1.2.3\n"+
+		"release_url=https://github.com/goreleaser/fake/releases/tag/v1.2.3\n"+
+		"artifacts="+filepath.Join(folder, "artifacts.json")+"\n", string(bts))
+}
+
+func TestRunGitHubActionsNoOutput(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = newContext(folder, context.Env{"GITHUB_ACTIONS": "true"})
+	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+}
+
+func TestRunFileOutputs(t *testing.T) {
+	for file, env := range map[string]context.Env{
+		"goreleaser.env":        {"GITLAB_CI": "true"},
+		"goreleaser.properties": {"JENKINS_URL": "https://jenkins.local"},
+	} {
+		t.Run(file, func(t *testing.T) {
+			folder, back := testlib.Mktmp(t)
+			defer back()
+			var ctx = newContext(folder, env)
+			require.NoError(t, Pipe{}.Run(ctx))
+			bts, err := ioutil.ReadFile(filepath.Join(folder, file))
+			require.NoError(t, err)
+			assert.Equal(t, "GORELEASER_VERSION=1.2.3\n"+
+				"GORELEASER_TAG=v1.2.3\n"+
+				"GORELEASER_RELEASE_URL=https://github.com/goreleaser/fake/releases/tag/v1.2.3\n"+
+				"GORELEASER_ARTIFACTS="+filepath.Join(folder, "artifacts.json")+"\n", string(bts))
+		})
+	}
+}
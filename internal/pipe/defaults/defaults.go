@@ -3,11 +3,20 @@
 package defaults
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+
 	"github.com/goreleaser/goreleaser/internal/middleware"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/goreleaser/goreleaser/pkg/defaults"
 )
 
+// ErrDeprecatedStrict happens when the config uses deprecated options and
+// strict mode is enabled
+var ErrDeprecatedStrict = errors.New("strict mode: the config uses deprecated options, check the warnings above")
+
 // Pipe that sets the defaults
 type Pipe struct{}
 
@@ -20,8 +29,8 @@ func (Pipe) Run(ctx *context.Context) error {
 	if ctx.Config.Dist == "" {
 		ctx.Config.Dist = "dist"
 	}
-	if ctx.Config.GitHubURLs.Download == "" {
-		ctx.Config.GitHubURLs.Download = "https://github.com"
+	if err := githubURLs(ctx); err != nil {
+		return err
 	}
 	if ctx.Config.GitLabURLs.Download == "" {
 		ctx.Config.GitLabURLs.Download = "https://gitlab.com"
@@ -35,5 +44,38 @@ func (Pipe) Run(ctx *context.Context) error {
 			return err
 		}
 	}
+	if ctx.Config.Strict && ctx.Deprecated {
+		return ErrDeprecatedStrict
+	}
+	return nil
+}
+
+// githubURLs templates the github urls, so GitHub Enterprise instances can be
+// set from the environment, and defaults the download url to the host of the
+// api, or to github.com
+func githubURLs(ctx *context.Context) error {
+	var urls = &ctx.Config.GitHubURLs
+	var template = tmpl.New(ctx)
+	for _, u := range []struct {
+		name  string
+		value *string
+	}{
+		{"api", &urls.API},
+		{"upload", &urls.Upload},
+		{"download", &urls.Download},
+	} {
+		result, err := template.Apply(*u.value)
+		if err != nil {
+			return fmt.Errorf("failed to template github_urls.%s: %s", u.name, err.Error())
+		}
+		*u.value = result
+	}
+	if urls.Download != "" {
+		return nil
+	}
+	urls.Download = "https://github.com"
+	if api, err := url.Parse(urls.API); err == nil && api.Host != "" && api.Host != "api.github.com" {
+		urls.Download = api.Scheme + "://" + api.Host
+	}
 	return nil
 }
@@ -64,8 +64,8 @@ func TestFillPartial(t *testing.T) {
 				},
 			},
 			Archive: config.Archive{
-				Files: []string{
-					"glob/*",
+				Files: []config.File{
+					{Source: "glob/*"},
 				},
 			},
 			Builds: []config.Build{
@@ -100,3 +100,53 @@ func TestFillPartial(t *testing.T) {
 	assert.Equal(t, "disttt", ctx.Config.Dist)
 	assert.NotEqual(t, "https://github.com", ctx.Config.GitHubURLs.Download)
 }
+
+func TestGitHubURLs(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "git@github.company.com:goreleaser/goreleaser.git")
+
+	var ctx = context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API:    "https://{{ .Env.GHE_HOST }}/api/v3/",
+			Upload: "https://{{ .Env.GHE_HOST }}/api/uploads/",
+		},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Env = map[string]string{"GHE_HOST": "github.company.com"}
+	assert.NoError(t, Pipe{}.Run(ctx))
+	assert.Equal(t, config.GitHubURLs{
+		API:      "https://github.company.com/api/v3/",
+		Upload:   "https://github.company.com/api/uploads/",
+		Download: "https://github.company.com",
+	}, ctx.Config.GitHubURLs)
+}
+
+func TestGitHubURLsInvalidTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			Upload: "https://{{ .Env.NOPE }}/api/uploads/",
+		},
+	})
+	assert.Contains(t, Pipe{}.Run(ctx).Error(), "failed to template github_urls.upload")
+}
+
+func TestDeprecatedStrict(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "git@github.com:goreleaser/goreleaser.git")
+
+	var ctx = &context.Context{
+		TokenType: context.TokenTypeGitHub,
+		Config: config.Project{
+			Strict: true,
+			Archive: config.Archive{
+				Format: "zip",
+			},
+		},
+	}
+	assert.EqualError(t, Pipe{}.Run(ctx), ErrDeprecatedStrict.Error())
+	assert.True(t, ctx.Deprecated)
+}
@@ -6,11 +6,16 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
+// CacheDir is the folder of dist that is kept between runs, even with
+// --rm-dist, so pipes can skip the work that didn't change since the last one
+const CacheDir = ".cache"
+
 // Pipe for cleandis
 type Pipe struct{}
 
@@ -25,17 +30,24 @@ func (Pipe) Run(ctx *context.Context) (err error) {
 		log.Debug("./dist doesn't exist, creating empty folder")
 		return mkdir(ctx)
 	}
+	files, err := ioutil.ReadDir(ctx.Config.Dist)
+	if err != nil {
+		return
+	}
 	if ctx.RmDist {
 		log.Info("--rm-dist is set, cleaning it up")
-		err = os.RemoveAll(ctx.Config.Dist)
-		if err == nil {
-			err = mkdir(ctx)
+		for _, file := range files {
+			if file.Name() == CacheDir {
+				continue
+			}
+			if err := os.RemoveAll(filepath.Join(ctx.Config.Dist, file.Name())); err != nil {
+				return err
+			}
 		}
-		return err
+		return nil
 	}
-	files, err := ioutil.ReadDir(ctx.Config.Dist)
-	if err != nil {
-		return
+	if len(files) == 1 && files[0].Name() == CacheDir {
+		files = nil
 	}
 	if len(files) != 0 {
 		log.Debugf("there are %d files on ./dist", len(files))
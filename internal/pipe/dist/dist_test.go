@@ -46,6 +46,28 @@ func TestPopulatedDistExists(t *testing.T) {
 	assert.False(t, os.IsExist(err))
 }
 
+func TestDistKeepsCache(t *testing.T) {
+	folder, err := ioutil.TempDir("", "disttest")
+	assert.NoError(t, err)
+	var dist = filepath.Join(folder, "dist")
+	var cache = filepath.Join(dist, CacheDir, "generate.json")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(cache), 0755))
+	assert.NoError(t, ioutil.WriteFile(cache, []byte("{}"), 0644))
+	var ctx = &context.Context{
+		Config: config.Project{
+			Dist: dist,
+		},
+	}
+	assert.NoError(t, Pipe{}.Run(ctx))
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dist, "mybin"), []byte("bin"), 0644))
+	assert.Error(t, Pipe{}.Run(ctx))
+	ctx.RmDist = true
+	assert.NoError(t, Pipe{}.Run(ctx))
+	assert.NoFileExists(t, filepath.Join(dist, "mybin"))
+	assert.FileExists(t, cache)
+}
+
 func TestEmptyDistExists(t *testing.T) {
 	folder, err := ioutil.TempDir("", "disttest")
 	assert.NoError(t, err)
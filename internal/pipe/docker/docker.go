@@ -7,10 +7,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
@@ -22,6 +26,9 @@ import (
 // ErrNoDocker is shown when docker cannot be found in $PATH
 var ErrNoDocker = errors.New("docker not present in $PATH")
 
+// ErrNoPodman is shown when podman cannot be found in $PATH
+var ErrNoPodman = errors.New("podman not present in $PATH")
+
 // Pipe for docker
 type Pipe struct{}
 
@@ -45,6 +52,27 @@ func (Pipe) Default(ctx *context.Context) error {
 				return fmt.Errorf("invalid docker.files: can't be . or inside dist folder: %s", f)
 			}
 		}
+		if docker.Use == "" {
+			docker.Use = "docker"
+		}
+		if docker.Save && docker.SaveNameTemplate == "" {
+			docker.SaveNameTemplate = "{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ if .Arm }}v{{ .Arm }}{{ end }}.docker.tar"
+		}
+		switch docker.Use {
+		case "docker", "podman":
+		case "registry":
+			if docker.BaseImage == "" {
+				docker.BaseImage = "gcr.io/distroless/static:nonroot"
+			}
+			if err := validateRegistryBuild(*docker); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("invalid docker.use: %s, should be docker, podman or registry", docker.Use)
+		}
+	}
+	if err := validateRegistries(ctx.Config.DockerRegistries); err != nil {
+		return err
 	}
 	// only set defaults if there is exactly 1 docker setup in the config file.
 	if len(ctx.Config.Dockers) != 1 {
@@ -55,7 +83,7 @@ func (Pipe) Default(ctx *context.Context) error {
 			ctx.Config.Builds[0].Binary,
 		}
 	}
-	if ctx.Config.Dockers[0].Dockerfile == "" {
+	if ctx.Config.Dockers[0].Dockerfile == "" && ctx.Config.Dockers[0].Use != "registry" {
 		ctx.Config.Dockers[0].Dockerfile = "Dockerfile"
 	}
 	return nil
@@ -66,28 +94,60 @@ func (Pipe) Run(ctx *context.Context) error {
 	if len(ctx.Config.Dockers) == 0 || len(ctx.Config.Dockers[0].ImageTemplates) == 0 {
 		return pipe.Skip("docker section is not configured")
 	}
-	_, err := exec.LookPath("docker")
+	for _, docker := range ctx.Config.Dockers {
+		switch docker.Use {
+		case "registry":
+			continue
+		case "podman":
+			if _, err := exec.LookPath("podman"); err != nil {
+				return ErrNoPodman
+			}
+		default:
+			if _, err := exec.LookPath("docker"); err != nil {
+				return ErrNoDocker
+			}
+		}
+	}
+	// the base images of the builds without docker may need credentials
+	creds, err := Login(ctx)
 	if err != nil {
-		return ErrNoDocker
+		return err
 	}
-	return doRun(ctx)
+	defer creds.Logout()
+	return doRun(ctx, creds)
 }
 
 // Publish the docker images
 func (Pipe) Publish(ctx *context.Context) error {
 	var images = ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableDockerImage)).List()
+	if len(images) == 0 {
+		return nil
+	}
+	creds, err := Login(ctx)
+	if err != nil {
+		return err
+	}
+	defer creds.Logout()
 	for _, image := range images {
-		if err := dockerPush(ctx, image); err != nil {
+		if err := dockerPush(ctx, creds, image); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func doRun(ctx *context.Context) error {
+func doRun(ctx *context.Context, creds *Credentials) error {
 	var g = semerrgroup.NewSkipAware(semerrgroup.New(ctx.Parallelism))
 	for _, docker := range ctx.Config.Dockers {
 		docker := docker
+		ok, err := condition.Check(ctx, docker.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("images", docker.ImageTemplates).Info("skipped because its condition is false")
+			continue
+		}
 		g.Go(func() error {
 			log.WithField("docker", docker).Debug("looking for binaries matching")
 			var binaryNames = make([]string, len(docker.Binaries))
@@ -124,48 +184,51 @@ func doRun(ctx *context.Context) error {
 					len(docker.Binaries),
 				)
 			}
-			return process(ctx, docker, binaries)
+			return process(ctx, creds, docker, binaries)
 		})
 	}
 	return g.Wait()
 }
 
-func process(ctx *context.Context, docker config.Docker, bins []*artifact.Artifact) error {
-	tmp, err := ioutil.TempDir(ctx.Config.Dist, "goreleaserdocker")
-	if err != nil {
-		return errors.Wrap(err, "failed to create temporary dir")
-	}
-	log.Debug("tempdir: " + tmp)
-
+func process(ctx *context.Context, creds *Credentials, docker config.Docker, bins []*artifact.Artifact) error {
 	images, err := processImageTemplates(ctx, docker)
 	if err != nil {
 		return err
 	}
 
-	if err := os.Link(docker.Dockerfile, filepath.Join(tmp, "Dockerfile")); err != nil {
-		return errors.Wrap(err, "failed to link dockerfile")
-	}
-	for _, file := range docker.Files {
-		if err := os.MkdirAll(filepath.Join(tmp, filepath.Dir(file)), 0755); err != nil {
-			return errors.Wrapf(err, "failed to link extra file '%s'", file)
-		}
-		if err := link(file, filepath.Join(tmp, file)); err != nil {
-			return errors.Wrapf(err, "failed to link extra file '%s'", file)
+	var saved string
+	if docker.Save {
+		saved, err = templater(ctx, docker).Apply(docker.SaveNameTemplate)
+		if err != nil {
+			return errors.Wrapf(err, "failed to execute save name template '%s'", docker.SaveNameTemplate)
 		}
 	}
-	for _, bin := range bins {
-		if err := os.Link(bin.Path, filepath.Join(tmp, filepath.Base(bin.Path))); err != nil {
-			return errors.Wrap(err, "failed to link binary")
+
+	var tarball string
+	if docker.Use == "registry" {
+		var path = tarballName(images[0])
+		if saved != "" {
+			path = saved
+		}
+		tarball, err = registryBuild(ctx, creds, docker, bins, images, filepath.Join(ctx.Config.Dist, path))
+	} else {
+		err = daemonBuild(ctx, creds, docker, bins, images)
+		if err == nil && saved != "" {
+			err = dockerSave(ctx, creds, daemon(docker), filepath.Join(ctx.Config.Dist, saved), images)
 		}
 	}
-
-	buildFlags, err := processBuildFlagTemplates(ctx, docker)
 	if err != nil {
 		return err
 	}
-
-	if err := dockerBuild(ctx, tmp, images, buildFlags); err != nil {
-		return err
+	if saved != "" {
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type:   artifact.UploadableDockerImage,
+			Name:   saved,
+			Path:   filepath.Join(ctx.Config.Dist, saved),
+			Goarch: docker.Goarch,
+			Goos:   docker.Goos,
+			Goarm:  docker.Goarm,
+		})
 	}
 
 	if strings.TrimSpace(docker.SkipPush) == "true" {
@@ -181,23 +244,91 @@ func process(ctx *context.Context, docker config.Docker, bins []*artifact.Artifa
 		return pipe.Skip("prerelease detected with 'auto' push, skipping docker publish")
 	}
 	for _, img := range images {
-		ctx.Artifacts.Add(&artifact.Artifact{
+		var a = &artifact.Artifact{
 			Type:   artifact.PublishableDockerImage,
 			Name:   img,
 			Path:   img,
 			Goarch: docker.Goarch,
 			Goos:   docker.Goos,
 			Goarm:  docker.Goarm,
-		})
+		}
+		if tarball != "" {
+			a.Extra = map[string]interface{}{"Tarball": tarball}
+		}
+		if docker.Use == "podman" {
+			a.Extra = map[string]interface{}{"Use": docker.Use}
+		}
+		if docker.Attest.SBOM || docker.Attest.Provenance {
+			if a.Extra == nil {
+				a.Extra = map[string]interface{}{}
+			}
+			a.Extra["Attest"] = docker.Attest
+		}
+		ctx.Artifacts.Add(a)
 	}
 	return nil
 }
 
+// daemonBuild builds the image with docker, or podman, from the Dockerfile
+func daemonBuild(ctx *context.Context, creds *Credentials, docker config.Docker, bins []*artifact.Artifact, images []string) error {
+	tmp, err := ioutil.TempDir(ctx.Config.Dist, "goreleaserdocker")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary dir")
+	}
+	log.Debug("tempdir: " + tmp)
+
+	dockerfile, err := templater(ctx, docker).Apply(docker.Dockerfile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute dockerfile template '%s'", docker.Dockerfile)
+	}
+	if err := os.Link(dockerfile, filepath.Join(tmp, "Dockerfile")); err != nil {
+		return errors.Wrap(err, "failed to link dockerfile")
+	}
+	for _, file := range docker.Files {
+		if err := os.MkdirAll(filepath.Join(tmp, filepath.Dir(file)), 0755); err != nil {
+			return errors.Wrapf(err, "failed to link extra file '%s'", file)
+		}
+		if err := link(file, filepath.Join(tmp, file)); err != nil {
+			return errors.Wrapf(err, "failed to link extra file '%s'", file)
+		}
+	}
+	for _, bin := range bins {
+		if err := os.Link(bin.Path, filepath.Join(tmp, filepath.Base(bin.Path))); err != nil {
+			return errors.Wrap(err, "failed to link binary")
+		}
+	}
+
+	buildFlags, err := processBuildFlagTemplates(ctx, docker)
+	if err != nil {
+		return err
+	}
+
+	return dockerBuild(ctx, creds, daemon(docker), tmp, images, buildFlags)
+}
+
+// daemon returns the command that builds the images of the given config
+func daemon(docker config.Docker) string {
+	if docker.Use == "podman" {
+		return docker.Use
+	}
+	return "docker"
+}
+
+// templater returns the template engine for the given docker config, which
+// knows the platform of the image, e.g. to use a Dockerfile per architecture
+func templater(ctx *context.Context, docker config.Docker) *tmpl.Template {
+	return tmpl.New(ctx).WithExtraFields(tmpl.Fields{
+		"Os":   docker.Goos,
+		"Arch": docker.Goarch,
+		"Arm":  docker.Goarm,
+	})
+}
+
 func processImageTemplates(ctx *context.Context, docker config.Docker) ([]string, error) {
 	// nolint:prealloc
 	var images []string
 	for _, imageTemplate := range docker.ImageTemplates {
-		image, err := tmpl.New(ctx).Apply(imageTemplate)
+		image, err := templater(ctx, docker).Apply(imageTemplate)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to execute image template '%s'", imageTemplate)
 		}
@@ -212,13 +343,106 @@ func processBuildFlagTemplates(ctx *context.Context, docker config.Docker) ([]st
 	// nolint:prealloc
 	var buildFlags []string
 	for _, buildFlagTemplate := range docker.BuildFlagTemplates {
-		buildFlag, err := tmpl.New(ctx).Apply(buildFlagTemplate)
+		buildFlag, err := templater(ctx, docker).Apply(buildFlagTemplate)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to process build flag template '%s'", buildFlagTemplate)
 		}
 		buildFlags = append(buildFlags, buildFlag)
 	}
-	return buildFlags, nil
+	labels, err := processLabels(ctx, docker)
+	if err != nil {
+		return nil, err
+	}
+	buildFlags = append(buildFlags, labels...)
+	buildFlags = append(buildFlags, releaseLabels(ctx, buildFlags)...)
+	return append(buildFlags, metadataLabels(ctx.Config.Metadata, buildFlags)...), nil
+}
+
+func processLabels(ctx *context.Context, docker config.Docker) ([]string, error) {
+	var keys = make([]string, 0, len(docker.Labels))
+	for key := range docker.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var labels = make([]string, 0, len(keys))
+	for _, key := range keys {
+		value, err := templater(ctx, docker).Apply(docker.Labels[key])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to execute label template '%s'", docker.Labels[key])
+		}
+		labels = append(labels, fmt.Sprintf("--label=%s=%s", key, value))
+	}
+	return labels, nil
+}
+
+// releaseLabels returns the OCI image labels of the release that were not
+// already set in the given build flags.
+func releaseLabels(ctx *context.Context, flags []string) []string {
+	// the commit date keeps the images reproducible, unlike the build date
+	var created string
+	if !ctx.Git.CommitDate.IsZero() {
+		created = ctx.Git.CommitDate.UTC().Format(time.RFC3339)
+	}
+	var labels = []struct {
+		key   string
+		value string
+	}{
+		{"org.opencontainers.image.version", ctx.Version},
+		{"org.opencontainers.image.revision", ctx.Git.FullCommit},
+		{"org.opencontainers.image.created", created},
+		{"org.opencontainers.image.source", sourceURL(ctx.Git.URL)},
+	}
+	var result []string
+	for _, label := range labels {
+		if label.value == "" || hasLabel(flags, label.key) {
+			continue
+		}
+		result = append(result, fmt.Sprintf("--label=%s=%s", label.key, label.value))
+	}
+	return result
+}
+
+// sourceURL returns the browsable URL of the given git remote, e.g.
+// https://github.com/goreleaser/goreleaser for
+// git@github.com:goreleaser/goreleaser.git
+func sourceURL(remote string) string {
+	var url = strings.TrimSuffix(remote, ".git")
+	if strings.HasPrefix(url, "git@") {
+		url = "https://" + strings.Replace(strings.TrimPrefix(url, "git@"), ":", "/", 1)
+	}
+	return url
+}
+
+// metadataLabels returns the OCI image labels of the project metadata that
+// were not already set in the given build flags.
+func metadataLabels(meta config.Metadata, flags []string) []string {
+	var labels = []struct {
+		key   string
+		value string
+	}{
+		{"org.opencontainers.image.description", meta.Description},
+		{"org.opencontainers.image.url", meta.Homepage},
+		{"org.opencontainers.image.licenses", meta.License},
+		{"org.opencontainers.image.authors", strings.Join(meta.Maintainers, ", ")},
+		{"org.opencontainers.image.vendor", meta.Vendor},
+	}
+	var result []string
+	for _, label := range labels {
+		if label.value == "" || hasLabel(flags, label.key) {
+			continue
+		}
+		result = append(result, fmt.Sprintf("--label=%s=%s", label.key, label.value))
+	}
+	return result
+}
+
+func hasLabel(flags []string, key string) bool {
+	for _, flag := range flags {
+		if strings.Contains(flag, key+"=") {
+			return true
+		}
+	}
+	return false
 }
 
 // walks the src, recreating dirs and hard-linking files
@@ -244,17 +468,32 @@ func link(src, dest string) error {
 	})
 }
 
-func dockerBuild(ctx *context.Context, root string, images, flags []string) error {
+func dockerBuild(ctx *context.Context, creds *Credentials, bin, root string, images, flags []string) error {
 	log.WithField("image", images[0]).Info("building docker image")
 	/* #nosec */
-	var cmd = exec.CommandContext(ctx, "docker", buildCommand(images, flags)...)
+	var cmd = exec.CommandContext(ctx, bin, buildCommand(images, flags)...)
+	cmd.Env = creds.Env(ctx)
 	cmd.Dir = root
 	log.WithField("cmd", cmd.Args).WithField("cwd", cmd.Dir).Debug("running")
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return errors.Wrapf(err, "failed to build docker image: \n%s", string(out))
 	}
-	log.Debugf("docker build output: \n%s", string(out))
+	log.Debugf("%s build output: \n%s", bin, string(out))
+	return nil
+}
+
+// dockerSave saves the images to a tarball that can be loaded with docker
+// load
+func dockerSave(ctx *context.Context, creds *Credentials, bin, path string, images []string) error {
+	log.WithField("file", path).Info("saving docker image")
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, bin, append([]string{"save", "-o", path}, images...)...)
+	cmd.Env = creds.Env(ctx)
+	log.WithField("cmd", cmd.Args).Debug("running")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "failed to save docker image: \n%s", string(out))
+	}
 	return nil
 }
 
@@ -267,23 +506,89 @@ func buildCommand(images, flags []string) []string {
 	return base
 }
 
-func dockerPush(ctx *context.Context, image *artifact.Artifact) error {
+func dockerPush(ctx *context.Context, creds *Credentials, image *artifact.Artifact) error {
 	log.WithField("image", image.Name).Info("pushing docker image")
-	/* #nosec */
-	var cmd = exec.CommandContext(ctx, "docker", "push", image.Name)
-	log.WithField("cmd", cmd.Args).Debug("running")
-	out, err := cmd.CombinedOutput()
+	var digest string
+	var err error
+	if tarball := image.ExtraOr("Tarball", "").(string); tarball != "" {
+		digest, err = registryPush(ctx, creds, image, tarball)
+	} else {
+		digest, err = daemonPush(ctx, creds, image)
+	}
 	if err != nil {
-		return errors.Wrapf(err, "failed to push docker image: \n%s", string(out))
+		return err
 	}
-	log.Debugf("docker push output: \n%s", string(out))
-	ctx.Artifacts.Add(&artifact.Artifact{
+	var pushed = &artifact.Artifact{
 		Type:   artifact.DockerImage,
 		Name:   image.Name,
 		Path:   image.Path,
 		Goarch: image.Goarch,
 		Goos:   image.Goos,
 		Goarm:  image.Goarm,
-	})
+	}
+	pushed.Extra = map[string]interface{}{}
+	if digest != "" {
+		pushed.Extra["Digest"] = digest
+	}
+	if attest, ok := image.Extra["Attest"]; ok {
+		// the attestations are attached once the image is pushed
+		pushed.Extra["Attest"] = attest
+	}
+	ctx.Artifacts.Add(pushed)
 	return nil
 }
+
+// daemonPush pushes the image with docker, returning its digest, if any
+func daemonPush(ctx *context.Context, creds *Credentials, image *artifact.Artifact) (string, error) {
+	if image.ExtraOr("Use", "") == "podman" {
+		return podmanPush(ctx, creds, image)
+	}
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "docker", "push", image.Name)
+	cmd.Env = creds.Env(ctx)
+	log.WithField("cmd", cmd.Args).Debug("running")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to push docker image: \n%s", string(out))
+	}
+	log.Debugf("docker push output: \n%s", string(out))
+	return digest(out), nil
+}
+
+// nolint: gochecknoglobals
+var digestRe = regexp.MustCompile(`digest: (sha256:[0-9a-f]{64})`)
+
+// digest returns the digest of the pushed image from the docker push output,
+// if any.
+func digest(out []byte) string {
+	var match = digestRe.FindSubmatch(out)
+	if match == nil {
+		return ""
+	}
+	return string(match[1])
+}
+
+// podmanPush pushes the image with podman, which writes its digest to a file
+// instead of printing it
+func podmanPush(ctx *context.Context, creds *Credentials, image *artifact.Artifact) (string, error) {
+	file, err := ioutil.TempFile("", "goreleaserdigest")
+	if err != nil {
+		return "", err
+	}
+	_ = file.Close()
+	defer os.Remove(file.Name()) // nolint: errcheck
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "podman", "push", "--digestfile", file.Name(), image.Name)
+	cmd.Env = creds.Env(ctx)
+	log.WithField("cmd", cmd.Args).Debug("running")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to push docker image: \n%s", string(out))
+	}
+	log.Debugf("podman push output: \n%s", string(out))
+	bts, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(bts)), nil
+}
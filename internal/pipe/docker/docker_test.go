@@ -10,6 +10,7 @@ import (
 	"strings"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/pipe"
@@ -615,6 +616,69 @@ func TestRunPipe(t *testing.T) {
 	}
 }
 
+func TestMetadataLabels(t *testing.T) {
+	var meta = config.Metadata{
+		Description: "Some description",
+		Homepage:    "https://example.com",
+		License:     "MIT",
+		Maintainers: []string{"Foo <foo@example.com>", "Bar <bar@example.com>"},
+	}
+	require.Equal(t, []string{
+		"--label=org.opencontainers.image.description=Some description",
+		"--label=org.opencontainers.image.licenses=MIT",
+		"--label=org.opencontainers.image.authors=Foo <foo@example.com>, Bar <bar@example.com>",
+	}, metadataLabels(meta, []string{"--label=org.opencontainers.image.url=https://foo.bar"}))
+	require.Empty(t, metadataLabels(config.Metadata{}, nil))
+}
+
+func TestReleaseLabels(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.Version = "1.0.0"
+	ctx.Git.FullCommit = "a1b2c3d4"
+	ctx.Git.URL = "git@github.com:goreleaser/goreleaser.git"
+	ctx.Git.CommitDate = time.Date(2020, 10, 1, 12, 30, 0, 0, time.FixedZone("BRT", -3*60*60))
+	var labels = releaseLabels(ctx, []string{"--label=org.opencontainers.image.version=custom"})
+	require.Equal(t, []string{
+		"--label=org.opencontainers.image.revision=a1b2c3d4",
+		"--label=org.opencontainers.image.created=2020-10-01T15:30:00Z",
+		"--label=org.opencontainers.image.source=https://github.com/goreleaser/goreleaser",
+	}, labels)
+}
+
+func TestSourceURL(t *testing.T) {
+	for remote, url := range map[string]string{
+		"git@github.com:goreleaser/goreleaser.git":     "https://github.com/goreleaser/goreleaser",
+		"https://github.com/goreleaser/goreleaser.git": "https://github.com/goreleaser/goreleaser",
+		"https://gitlab.com/foo/bar":                   "https://gitlab.com/foo/bar",
+		"":                                             "",
+	} {
+		require.Equal(t, url, sourceURL(remote))
+	}
+}
+
+func TestProcessLabels(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.Git.CurrentTag = "v1.0.0"
+	labels, err := processLabels(ctx, config.Docker{
+		Goarch: "arm64",
+		Labels: map[string]string{
+			"org.opencontainers.image.title": "foo-{{ .Arch }}",
+			"com.example.tag":                "{{ .Tag }}",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"--label=com.example.tag=v1.0.0",
+		"--label=org.opencontainers.image.title=foo-arm64",
+	}, labels)
+
+	_, err = processLabels(ctx, config.Docker{
+		Labels: map[string]string{"foo": "{{ .Nope }"},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to execute label template")
+}
+
 func TestBuildCommand(t *testing.T) {
 	images := []string{"goreleaser/test_build_flag", "goreleaser/test_multiple_tags"}
 	tests := []struct {
@@ -646,6 +710,15 @@ func TestBuildCommand(t *testing.T) {
 	}
 }
 
+func TestDigest(t *testing.T) {
+	var out = []byte(`The push refers to repository [docker.io/goreleaser/test]
+5216338b40a7: Pushed
+v1.0.0: digest: sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae size: 528
+`)
+	assert.Equal(t, "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae", digest(out))
+	assert.Empty(t, digest([]byte("pushed")))
+}
+
 func TestDescription(t *testing.T) {
 	assert.NotEmpty(t, Pipe{}.String())
 }
@@ -793,6 +866,7 @@ func Test_processImageTemplates(t *testing.T) {
 						"user/image:{{.Tag}}",
 						"gcr.io/image:{{.Tag}}-{{.Env.FOO}}",
 						"gcr.io/image:v{{.Major}}.{{.Minor}}",
+						"user/image:{{.Tag}}-{{.Os}}-{{.Arch}}",
 					},
 					SkipPush: "true",
 				},
@@ -826,9 +900,25 @@ func Test_processImageTemplates(t *testing.T) {
 		"user/image:v1.0.0",
 		"gcr.io/image:v1.0.0-123",
 		"gcr.io/image:v1.0",
+		"user/image:v1.0.0-linux-amd64",
 	}, images)
 }
 
+func TestProcessDockerfileTemplate(t *testing.T) {
+	folder, err := ioutil.TempDir("", "dockerfile")
+	require.NoError(t, err)
+	defer os.RemoveAll(folder)
+	var ctx = context.New(config.Project{Dist: folder})
+
+	err = process(ctx, &Credentials{}, config.Docker{Dockerfile: "Dockerfile.{{ .Arch }"}, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to execute dockerfile template")
+
+	err = process(ctx, &Credentials{}, config.Docker{Dockerfile: filepath.Join(folder, "Dockerfile.{{ .Arch }}"), Goarch: "arm64"}, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Dockerfile.arm64")
+}
+
 func TestLinkFile(t *testing.T) {
 	src, err := ioutil.TempFile("", "src")
 	require.NoError(t, err)
@@ -942,3 +1032,90 @@ func inode(file string) uint64 {
 	stat := fileInfo.Sys().(*syscall.Stat_t)
 	return stat.Ino
 }
+
+func TestPodmanPush(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakePodman(t, folder)()
+
+	var ctx = context.New(config.Project{})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:  "foo/bar:v1.0.0",
+		Path:  "foo/bar:v1.0.0",
+		Type:  artifact.PublishableDockerImage,
+		Extra: map[string]interface{}{"Use": "podman"},
+	})
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "podman.log"))
+	require.NoError(t, err)
+	require.Equal(t, "push --digestfile foo/bar:v1.0.0\n", string(bts))
+	var images = ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImage)).List()
+	require.Len(t, images, 1)
+	require.Equal(t, manifestDigest, images[0].ExtraOr("Digest", ""))
+}
+
+func TestRunPipeNoPodman(t *testing.T) {
+	var path = os.Getenv("PATH")
+	defer func() {
+		require.NoError(t, os.Setenv("PATH", path))
+	}()
+	require.NoError(t, os.Setenv("PATH", ""))
+	var ctx = context.New(config.Project{
+		Dockers: []config.Docker{
+			{
+				Use:            "podman",
+				ImageTemplates: []string{"a/b"},
+			},
+		},
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), ErrNoPodman.Error())
+}
+
+func TestRunPipeSave(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeDocker(t, folder)()
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "Dockerfile"), []byte("FROM scratch\n"), 0644))
+	var bin = filepath.Join(dist, "mybin")
+	require.NoError(t, ioutil.WriteFile(bin, []byte("#!/bin/sh\n"), 0755))
+
+	var ctx = context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		Dockers: []config.Docker{
+			{
+				Goarch:         "arm",
+				Goarm:          "7",
+				Binaries:       []string{"mybin"},
+				Dockerfile:     filepath.Join(folder, "Dockerfile"),
+				ImageTemplates: []string{"foo/bar:{{ .Tag }}", "foo/bar:latest"},
+				Save:           true,
+			},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   bin,
+		Goos:   "linux",
+		Goarch: "arm",
+		Goarm:  "7",
+		Type:   artifact.Binary,
+		Extra:  map[string]interface{}{"Binary": "mybin"},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var path = filepath.Join(dist, "mybin_1.0.0_linux_armv7.docker.tar")
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "docker.log"))
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "save -o "+path+" foo/bar:v1.0.0 foo/bar:latest\n")
+	var saved = ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableDockerImage)).List()
+	require.Len(t, saved, 1)
+	require.Equal(t, "mybin_1.0.0_linux_armv7.docker.tar", saved[0].Name)
+	require.Equal(t, path, saved[0].Path)
+}
@@ -0,0 +1,165 @@
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+func validateRegistries(registries []config.DockerRegistry) error {
+	for _, registry := range registries {
+		if registry.Registry == "" {
+			return errors.New("docker_registries: registry is required")
+		}
+		if registry.CredentialHelper == "" && registry.Username == "" {
+			return fmt.Errorf("docker_registries: %s needs a username and password or a credential_helper", registry.Registry)
+		}
+	}
+	return nil
+}
+
+// Credentials is the docker config written by Login, with the credentials of
+// the configured registries on top of the ones of the user's docker config.
+type Credentials struct {
+	dir string
+}
+
+// Env returns ctx.Env with DOCKER_CONFIG, and podman's REGISTRY_AUTH_FILE,
+// pointing to the docker config with the credentials, so the commands run
+// with it use them.
+func (c *Credentials) Env(ctx *context.Context) []string {
+	var env = ctx.Env.Strings()
+	if c.dir == "" {
+		return env
+	}
+	return append(
+		env,
+		"DOCKER_CONFIG="+c.dir,
+		"REGISTRY_AUTH_FILE="+filepath.Join(c.dir, "config.json"),
+	)
+}
+
+// Keychain returns the keychain resolving the credentials of the registries
+// from the docker config with the credentials.
+func (c *Credentials) Keychain(ctx *context.Context) authn.Keychain {
+	if c.dir == "" {
+		return keychain{dir: ctx.Env["DOCKER_CONFIG"]}
+	}
+	return keychain{dir: c.dir}
+}
+
+// Logout removes the docker config with the credentials.
+func (c *Credentials) Logout() {
+	if c.dir != "" {
+		_ = os.RemoveAll(c.dir)
+	}
+}
+
+// Login writes a docker config with the credentials of the configured
+// registries, on top of the ones of the user's docker config. As docker
+// ignores the auths when the config has a credentials store, the temporary
+// config has none: the registries stored in it use it as their credential
+// helper instead, so the credentials of the configured registries never end
+// up in the user's credentials store. It doesn't change ctx.Env, which other
+// pipes may read concurrently: the commands and registry clients get the
+// credentials from the returned Credentials.
+func Login(ctx *context.Context) (*Credentials, error) {
+	var registries = ctx.Config.DockerRegistries
+	if len(registries) == 0 {
+		return &Credentials{}, nil
+	}
+	cfg, err := userConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var auths = section(cfg, "auths")
+	var helpers = section(cfg, "credHelpers")
+	if store, _ := cfg["credsStore"].(string); store != "" {
+		// docker keeps an empty auth for each registry in the store
+		for registry := range auths {
+			if _, ok := helpers[registry]; !ok {
+				helpers[registry] = store
+			}
+			delete(auths, registry)
+		}
+		delete(cfg, "credsStore")
+	}
+	for _, registry := range registries {
+		log.WithField("registry", registry.Registry).Info("logging in")
+		if registry.CredentialHelper != "" {
+			helpers[registry.Registry] = registry.CredentialHelper
+			delete(auths, registry.Registry)
+			continue
+		}
+		username, err := tmpl.New(ctx).Apply(registry.Username)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to execute username template of %s", registry.Registry)
+		}
+		password, err := tmpl.New(ctx).Apply(registry.Password)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to execute password template of %s", registry.Registry)
+		}
+		if username == "" || password == "" {
+			return nil, fmt.Errorf("docker_registries: empty username or password for %s", registry.Registry)
+		}
+		delete(helpers, registry.Registry)
+		auths[registry.Registry] = map[string]interface{}{
+			"auth": base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+		}
+	}
+	cfg["auths"] = auths
+	cfg["credHelpers"] = helpers
+
+	bts, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	dir, err := ioutil.TempDir("", "goreleaserdocker")
+	if err != nil {
+		return nil, err
+	}
+	var creds = &Credentials{dir: dir}
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.json"), bts, 0600); err != nil {
+		creds.Logout()
+		return nil, err
+	}
+	return creds, nil
+}
+
+// userConfig reads the docker config of the user, so the registries that
+// aren't configured keep working as usual
+func userConfig(ctx *context.Context) (map[string]interface{}, error) {
+	var dir = ctx.Env["DOCKER_CONFIG"]
+	if dir == "" {
+		dir = filepath.Join(ctx.Env["HOME"], ".docker")
+	}
+	var cfg = map[string]interface{}{}
+	bts, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bts, &cfg); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the docker config")
+	}
+	return cfg, nil
+}
+
+func section(cfg map[string]interface{}, key string) map[string]interface{} {
+	if s, ok := cfg[key].(map[string]interface{}); ok {
+		return s
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,204 @@
+package docker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRegistries(t *testing.T) {
+	require.NoError(t, validateRegistries([]config.DockerRegistry{
+		{Registry: "ghcr.io", Username: "foo", Password: "bar"},
+		{Registry: "123.dkr.ecr.us-east-1.amazonaws.com", CredentialHelper: "ecr-login"},
+	}))
+	require.EqualError(t, validateRegistries([]config.DockerRegistry{
+		{Username: "foo"},
+	}), "docker_registries: registry is required")
+	require.EqualError(t, validateRegistries([]config.DockerRegistry{
+		{Registry: "quay.io"},
+	}), "docker_registries: quay.io needs a username and password or a credential_helper")
+}
+
+func TestLoginNoRegistries(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	creds, err := Login(ctx)
+	require.NoError(t, err)
+	require.ElementsMatch(t, ctx.Env.Strings(), creds.Env(ctx))
+	creds.Logout()
+	require.NotContains(t, ctx.Env, "DOCKER_CONFIG")
+}
+
+func TestLogin(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	defer os.RemoveAll(folder) // nolint: errcheck
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "config.json"), []byte(`{
+		"auths": {"docker.io": {"auth": "dXNlcjpwYXNz"}, "quay.io": {"auth": "b2xkOm9sZA=="}},
+		"credHelpers": {"ghcr.io": "desktop"},
+		"experimental": "enabled"
+	}`), 0600))
+
+	var ctx = context.New(config.Project{
+		DockerRegistries: []config.DockerRegistry{
+			{Registry: "ghcr.io", Username: "foo", Password: "{{ .Env.GHCR_TOKEN }}"},
+			{Registry: "quay.io", CredentialHelper: "quay"},
+			{Registry: "123.dkr.ecr.us-east-1.amazonaws.com", CredentialHelper: "ecr-login"},
+		},
+	})
+	ctx.Env["DOCKER_CONFIG"] = folder
+	ctx.Env["GHCR_TOKEN"] = "secret"
+	creds, err := Login(ctx)
+	require.NoError(t, err)
+	require.Equal(t, folder, ctx.Env["DOCKER_CONFIG"])
+	require.NotContains(t, ctx.Env, "REGISTRY_AUTH_FILE")
+
+	var dir = creds.dir
+	require.NotEqual(t, folder, dir)
+	var env = creds.Env(ctx)
+	require.Contains(t, env, "DOCKER_CONFIG="+dir)
+	require.Contains(t, env, "REGISTRY_AUTH_FILE="+filepath.Join(dir, "config.json"))
+	require.Equal(t, keychain{dir: dir}, creds.Keychain(ctx))
+	bts, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	require.NoError(t, err)
+	var cfg map[string]interface{}
+	require.NoError(t, json.Unmarshal(bts, &cfg))
+	require.Equal(t, map[string]interface{}{
+		"auths": map[string]interface{}{
+			"docker.io": map[string]interface{}{"auth": "dXNlcjpwYXNz"},
+			"ghcr.io":   map[string]interface{}{"auth": "Zm9vOnNlY3JldA=="},
+		},
+		"credHelpers": map[string]interface{}{
+			"quay.io":                             "quay",
+			"123.dkr.ecr.us-east-1.amazonaws.com": "ecr-login",
+		},
+		"experimental": "enabled",
+	}, cfg)
+
+	creds.Logout()
+	_, err = os.Stat(dir)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestLoginCredentialsStore(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	defer os.RemoveAll(folder) // nolint: errcheck
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "config.json"), []byte(`{
+		"auths": {"docker.io": {}, "ghcr.io": {}, "gcr.io": {}},
+		"credHelpers": {"gcr.io": "gcloud"},
+		"credsStore": "desktop"
+	}`), 0600))
+
+	var ctx = context.New(config.Project{
+		DockerRegistries: []config.DockerRegistry{
+			{Registry: "ghcr.io", Username: "foo", Password: "secret"},
+			{Registry: "quay.io", CredentialHelper: "quay"},
+		},
+	})
+	ctx.Env["DOCKER_CONFIG"] = folder
+	creds, err := Login(ctx)
+	require.NoError(t, err)
+	defer creds.Logout()
+
+	bts, err := ioutil.ReadFile(filepath.Join(creds.dir, "config.json"))
+	require.NoError(t, err)
+	var cfg map[string]interface{}
+	require.NoError(t, json.Unmarshal(bts, &cfg))
+	require.Equal(t, map[string]interface{}{
+		"auths": map[string]interface{}{
+			"ghcr.io": map[string]interface{}{"auth": "Zm9vOnNlY3JldA=="},
+		},
+		"credHelpers": map[string]interface{}{
+			"docker.io": "desktop",
+			"gcr.io":    "gcloud",
+			"quay.io":   "quay",
+		},
+	}, cfg)
+}
+
+func TestLoginNoUserConfig(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	defer os.RemoveAll(folder) // nolint: errcheck
+	var ctx = context.New(config.Project{
+		DockerRegistries: []config.DockerRegistry{
+			{Registry: "ghcr.io", Username: "foo", Password: "bar"},
+		},
+	})
+	ctx.Env["HOME"] = folder
+	creds, err := Login(ctx)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(creds.dir, "config.json"))
+	creds.Logout()
+	require.NotContains(t, ctx.Env, "DOCKER_CONFIG")
+}
+
+func TestLoginEmptyPassword(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	defer os.RemoveAll(folder) // nolint: errcheck
+	var ctx = context.New(config.Project{
+		DockerRegistries: []config.DockerRegistry{
+			{Registry: "ghcr.io", Username: "foo", Password: "{{ .Env.GHCR_TOKEN }}"},
+		},
+	})
+	ctx.Env["HOME"] = folder
+	ctx.Env["GHCR_TOKEN"] = ""
+	_, err = Login(ctx)
+	require.EqualError(t, err, "docker_registries: empty username or password for ghcr.io")
+}
+
+func TestLoginBadTemplate(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	defer os.RemoveAll(folder) // nolint: errcheck
+	var ctx = context.New(config.Project{
+		DockerRegistries: []config.DockerRegistry{
+			{Registry: "ghcr.io", Username: "{{ .Nope }", Password: "bar"},
+		},
+	})
+	ctx.Env["HOME"] = folder
+	_, err = Login(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to execute username template of ghcr.io")
+}
+
+func TestLoginInvalidUserConfig(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	defer os.RemoveAll(folder) // nolint: errcheck
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "config.json"), []byte("nope"), 0600))
+	var ctx = context.New(config.Project{
+		DockerRegistries: []config.DockerRegistry{
+			{Registry: "quay.io", CredentialHelper: "quay"},
+		},
+	})
+	ctx.Env["DOCKER_CONFIG"] = folder
+	_, err = Login(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to parse the docker config")
+}
+
+func TestRunCmdWithCredentials(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	defer os.RemoveAll(folder) // nolint: errcheck
+	var ctx = context.New(config.Project{
+		DockerRegistries: []config.DockerRegistry{
+			{Registry: "ghcr.io", Username: "foo", Password: "bar"},
+		},
+	})
+	ctx.Env["DOCKER_CONFIG"] = folder
+	creds, err := Login(ctx)
+	require.NoError(t, err)
+	defer creds.Logout()
+	out, err := runCmd(ctx, creds, "sh", "-c", "echo $DOCKER_CONFIG $REGISTRY_AUTH_FILE")
+	require.NoError(t, err)
+	require.Equal(t, creds.dir+" "+filepath.Join(creds.dir, "config.json")+"\n", string(out))
+}
@@ -0,0 +1,222 @@
+package docker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ManifestPipe creates and pushes the manifest lists of the per platform
+// images pushed by the docker pipe.
+type ManifestPipe struct{}
+
+func (ManifestPipe) String() string {
+	return "Docker manifests"
+}
+
+// Default sets the pipe defaults
+func (ManifestPipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.DockerManifests {
+		var manifest = &ctx.Config.DockerManifests[i]
+		if manifest.Use == "" {
+			manifest.Use = "docker"
+		}
+		if manifest.Use != "docker" && manifest.Use != "buildx" && manifest.Use != "podman" {
+			return fmt.Errorf("invalid docker_manifests.use: %s, should be docker, buildx or podman", manifest.Use)
+		}
+		if manifest.Use != "buildx" && len(manifest.Annotations) > 0 {
+			return fmt.Errorf("invalid docker_manifests.annotations: only supported with use: buildx")
+		}
+	}
+	return nil
+}
+
+// Publish the manifest lists
+func (ManifestPipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.DockerManifests) == 0 {
+		return pipe.Skip("docker_manifests section is not configured")
+	}
+	creds, err := Login(ctx)
+	if err != nil {
+		return err
+	}
+	defer creds.Logout()
+	for _, manifest := range ctx.Config.DockerManifests {
+		if strings.TrimSpace(manifest.SkipPush) == "true" {
+			log.WithField("manifest", manifest.NameTemplate).Info("skipped because skip_push is set")
+			continue
+		}
+		if strings.TrimSpace(manifest.SkipPush) == "auto" && ctx.Semver.Prerelease != "" {
+			log.WithField("manifest", manifest.NameTemplate).Info("skipped because of the prerelease")
+			continue
+		}
+		if err := publishManifest(ctx, creds, manifest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishManifest(ctx *context.Context, creds *Credentials, manifest config.DockerManifest) error {
+	name, err := tmpl.New(ctx).Apply(manifest.NameTemplate)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute manifest name template '%s'", manifest.NameTemplate)
+	}
+	var pushed = map[string]bool{}
+	for _, image := range ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImage)).List() {
+		pushed[image.Name] = true
+	}
+	// nolint:prealloc
+	var images []string
+	for _, imageTemplate := range manifest.ImageTemplates {
+		image, err := tmpl.New(ctx).Apply(imageTemplate)
+		if err != nil {
+			return errors.Wrapf(err, "failed to execute image template '%s'", imageTemplate)
+		}
+		if !pushed[image] {
+			return fmt.Errorf("docker manifest %s: image %s wasn't pushed in this release", name, image)
+		}
+		images = append(images, image)
+	}
+	if len(images) == 0 {
+		return fmt.Errorf("docker manifest %s has no images", name)
+	}
+
+	log.WithField("manifest", name).WithField("images", images).Info("pushing docker manifest")
+	var digest string
+	switch manifest.Use {
+	case "buildx":
+		digest, err = buildxManifest(ctx, creds, name, images, manifest)
+	case "podman":
+		digest, err = podmanManifest(ctx, creds, name, images, manifest)
+	default:
+		digest, err = dockerManifest(ctx, creds, name, images, manifest)
+	}
+	if err != nil {
+		return err
+	}
+	var a = &artifact.Artifact{
+		Type: artifact.DockerManifest,
+		Name: name,
+		Path: name,
+	}
+	if digest != "" {
+		a.Extra = map[string]interface{}{"Digest": digest}
+	}
+	ctx.Artifacts.Add(a)
+	return nil
+}
+
+// nolint: gochecknoglobals
+var manifestDigestRe = regexp.MustCompile(`sha256:[0-9a-f]{64}`)
+
+// dockerManifest creates the manifest list locally and pushes it, which
+// prints its digest
+func dockerManifest(ctx *context.Context, creds *Credentials, name string, images []string, manifest config.DockerManifest) (string, error) {
+	var create = append([]string{"manifest", "create", name}, images...)
+	if _, err := runDocker(ctx, creds, append(create, manifest.CreateFlags...)...); err != nil {
+		return "", err
+	}
+	var push = append([]string{"manifest", "push", name}, manifest.PushFlags...)
+	out, err := runDocker(ctx, creds, push...)
+	if err != nil {
+		return "", err
+	}
+	return string(manifestDigestRe.Find(out)), nil
+}
+
+// nolint: gochecknoglobals
+var buildxDigestRe = regexp.MustCompile(`Digest:\s+(sha256:[0-9a-f]{64})`)
+
+// buildxManifest creates the manifest list directly in the registry, and
+// inspects it to get its digest
+func buildxManifest(ctx *context.Context, creds *Credentials, name string, images []string, manifest config.DockerManifest) (string, error) {
+	annotations, err := processAnnotations(ctx, manifest)
+	if err != nil {
+		return "", err
+	}
+	var create = append([]string{"buildx", "imagetools", "create", "-t", name}, annotations...)
+	create = append(create, manifest.CreateFlags...)
+	if _, err := runDocker(ctx, creds, append(create, images...)...); err != nil {
+		return "", err
+	}
+	out, err := runDocker(ctx, creds, "buildx", "imagetools", "inspect", name)
+	if err != nil {
+		return "", err
+	}
+	if match := buildxDigestRe.FindSubmatch(out); match != nil {
+		return string(match[1]), nil
+	}
+	return "", nil
+}
+
+// processAnnotations returns the flags of the annotations of the manifest
+// list, which is an image index
+func processAnnotations(ctx *context.Context, manifest config.DockerManifest) ([]string, error) {
+	var keys = make([]string, 0, len(manifest.Annotations))
+	for key := range manifest.Annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var flags = make([]string, 0, len(keys))
+	for _, key := range keys {
+		value, err := tmpl.New(ctx).Apply(manifest.Annotations[key])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to execute annotation template '%s'", manifest.Annotations[key])
+		}
+		flags = append(flags, fmt.Sprintf("--annotation=index:%s=%s", key, value))
+	}
+	return flags, nil
+}
+
+// podmanManifest creates the manifest list in the local storage and pushes
+// it, writing its digest to a file
+func podmanManifest(ctx *context.Context, creds *Credentials, name string, images []string, manifest config.DockerManifest) (string, error) {
+	var create = append([]string{"manifest", "create"}, manifest.CreateFlags...)
+	if _, err := runCmd(ctx, creds, "podman", append(append(create, name), images...)...); err != nil {
+		return "", err
+	}
+	file, err := ioutil.TempFile("", "goreleaserdigest")
+	if err != nil {
+		return "", err
+	}
+	_ = file.Close()
+	defer os.Remove(file.Name()) // nolint: errcheck
+	var push = append([]string{"manifest", "push", "--all", "--digestfile", file.Name()}, manifest.PushFlags...)
+	if _, err := runCmd(ctx, creds, "podman", append(push, name, "docker://"+name)...); err != nil {
+		return "", err
+	}
+	bts, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(bts)), nil
+}
+
+func runDocker(ctx *context.Context, creds *Credentials, args ...string) ([]byte, error) {
+	return runCmd(ctx, creds, "docker", args...)
+}
+
+func runCmd(ctx *context.Context, creds *Credentials, bin string, args ...string) ([]byte, error) {
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, bin, args...)
+	cmd.Env = creds.Env(ctx)
+	log.WithField("cmd", cmd.Args).Debug("running")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to run %s %s: \n%s", bin, strings.Join(args[:2], " "), string(out))
+	}
+	return out, nil
+}
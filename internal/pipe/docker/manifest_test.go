@@ -0,0 +1,213 @@
+package docker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+const manifestDigest = "sha256:fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+
+func TestManifestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		DockerManifests: []config.DockerManifest{{}, {Use: "buildx"}},
+	})
+	require.NoError(t, ManifestPipe{}.Default(ctx))
+	require.Equal(t, "docker", ctx.Config.DockerManifests[0].Use)
+	require.Equal(t, "buildx", ctx.Config.DockerManifests[1].Use)
+}
+
+func TestManifestDefaultInvalidUse(t *testing.T) {
+	var ctx = context.New(config.Project{
+		DockerManifests: []config.DockerManifest{{Use: "nerdctl"}},
+	})
+	require.EqualError(t, ManifestPipe{}.Default(ctx), "invalid docker_manifests.use: nerdctl, should be docker, buildx or podman")
+}
+
+func TestManifestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, ManifestPipe{}.Publish(context.New(config.Project{})))
+}
+
+// fakeDocker puts a fake docker in the PATH, which logs its arguments to
+// docker.log and prints digests like the real one.
+func fakeDocker(t *testing.T, folder string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"docker": "echo \"$*\" >> " + filepath.Join(folder, "docker.log") + "\n" +
+			"case \"$*\" in\n" +
+			"  \"manifest push\"*) echo " + manifestDigest + " ;;\n" +
+			"  \"buildx imagetools inspect\"*) echo \"Name: $4\"; echo \"Digest:    " + manifestDigest + "\" ;;\n" +
+			"esac\n",
+	})
+}
+
+// fakePodman puts a fake podman in the PATH, which logs its arguments to
+// podman.log and writes digests to the --digestfile like the real one.
+func fakePodman(t *testing.T, folder string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"podman": "echo \"$*\" | sed 's/--digestfile [^ ]*/--digestfile/' >> " + filepath.Join(folder, "podman.log") + "\n" +
+			"while [ $# -gt 0 ]; do\n" +
+			"  if [ \"$1\" = --digestfile ]; then echo " + manifestDigest + " > \"$2\"; fi\n" +
+			"  shift\n" +
+			"done\n",
+	})
+}
+
+func manifestContext(manifests ...config.DockerManifest) *context.Context {
+	var ctx = context.New(config.Project{DockerManifests: manifests})
+	ctx.Git.CurrentTag = "v1.0.0"
+	for _, image := range []string{"foo/bar:v1.0.0-amd64", "foo/bar:v1.0.0-arm64"} {
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: image,
+			Path: image,
+			Type: artifact.DockerImage,
+		})
+	}
+	return ctx
+}
+
+func TestManifestPublish(t *testing.T) {
+	for use, calls := range map[string][]string{
+		"docker": {
+			"manifest create foo/bar:v1.0.0 foo/bar:v1.0.0-amd64 foo/bar:v1.0.0-arm64 --amend",
+			"manifest push foo/bar:v1.0.0 --purge",
+		},
+		"buildx": {
+			"buildx imagetools create -t foo/bar:v1.0.0 --amend foo/bar:v1.0.0-amd64 foo/bar:v1.0.0-arm64",
+			"buildx imagetools inspect foo/bar:v1.0.0",
+		},
+	} {
+		t.Run(use, func(t *testing.T) {
+			folder, back := testlib.Mktmp(t)
+			defer back()
+			defer fakeDocker(t, folder)()
+
+			var ctx = manifestContext(config.DockerManifest{
+				NameTemplate:   "foo/bar:{{ .Tag }}",
+				ImageTemplates: []string{"foo/bar:{{ .Tag }}-amd64", "foo/bar:{{ .Tag }}-arm64"},
+				CreateFlags:    []string{"--amend"},
+				PushFlags:      []string{"--purge"},
+				Use:            use,
+			}, config.DockerManifest{
+				NameTemplate:   "foo/bar:latest",
+				ImageTemplates: []string{"foo/bar:{{ .Tag }}-amd64"},
+				SkipPush:       "true",
+			})
+			require.NoError(t, ManifestPipe{}.Publish(ctx))
+
+			bts, err := ioutil.ReadFile(filepath.Join(folder, "docker.log"))
+			require.NoError(t, err)
+			require.Equal(t, calls, strings.Split(strings.TrimSpace(string(bts)), "\n"))
+			var manifests = ctx.Artifacts.Filter(artifact.ByType(artifact.DockerManifest)).List()
+			require.Len(t, manifests, 1)
+			require.Equal(t, "foo/bar:v1.0.0", manifests[0].Name)
+			require.Equal(t, manifestDigest, manifests[0].ExtraOr("Digest", ""))
+		})
+	}
+}
+
+func TestManifestPublishPodman(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakePodman(t, folder)()
+
+	var ctx = manifestContext(config.DockerManifest{
+		NameTemplate:   "foo/bar:{{ .Tag }}",
+		ImageTemplates: []string{"foo/bar:{{ .Tag }}-amd64", "foo/bar:{{ .Tag }}-arm64"},
+		CreateFlags:    []string{"--amend"},
+		Use:            "podman",
+	})
+	require.NoError(t, ManifestPipe{}.Publish(ctx))
+
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "podman.log"))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"manifest create --amend foo/bar:v1.0.0 foo/bar:v1.0.0-amd64 foo/bar:v1.0.0-arm64",
+		"manifest push --all --digestfile foo/bar:v1.0.0 docker://foo/bar:v1.0.0",
+	}, strings.Split(strings.TrimSpace(string(bts)), "\n"))
+	var manifests = ctx.Artifacts.Filter(artifact.ByType(artifact.DockerManifest)).List()
+	require.Len(t, manifests, 1)
+	require.Equal(t, manifestDigest, manifests[0].ExtraOr("Digest", ""))
+}
+
+func TestManifestDefaultAnnotationsWithDocker(t *testing.T) {
+	var ctx = context.New(config.Project{
+		DockerManifests: []config.DockerManifest{{Annotations: map[string]string{"foo": "bar"}}},
+	})
+	require.EqualError(t, ManifestPipe{}.Default(ctx), "invalid docker_manifests.annotations: only supported with use: buildx")
+}
+
+func TestManifestPublishAnnotations(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeDocker(t, folder)()
+
+	var ctx = manifestContext(config.DockerManifest{
+		NameTemplate:   "foo/bar:{{ .Tag }}",
+		ImageTemplates: []string{"foo/bar:{{ .Tag }}-amd64"},
+		Use:            "buildx",
+		Annotations: map[string]string{
+			"org.opencontainers.image.version":     "{{ .Tag }}",
+			"org.opencontainers.image.description": "Foo bar",
+		},
+	})
+	require.NoError(t, ManifestPipe{}.Publish(ctx))
+
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "docker.log"))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"buildx imagetools create -t foo/bar:v1.0.0 --annotation=index:org.opencontainers.image.description=Foo bar --annotation=index:org.opencontainers.image.version=v1.0.0 foo/bar:v1.0.0-amd64",
+		"buildx imagetools inspect foo/bar:v1.0.0",
+	}, strings.Split(strings.TrimSpace(string(bts)), "\n"))
+}
+
+func TestManifestPublishBadAnnotation(t *testing.T) {
+	var ctx = manifestContext(config.DockerManifest{
+		NameTemplate:   "foo/bar:{{ .Tag }}",
+		ImageTemplates: []string{"foo/bar:{{ .Tag }}-amd64"},
+		Use:            "buildx",
+		Annotations:    map[string]string{"foo": "{{ .Nope }"},
+	})
+	var err = ManifestPipe{}.Publish(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to execute annotation template")
+}
+
+func TestManifestPublishImageNotPushed(t *testing.T) {
+	var ctx = manifestContext(config.DockerManifest{
+		NameTemplate:   "foo/bar:{{ .Tag }}",
+		ImageTemplates: []string{"foo/bar:{{ .Tag }}-386"},
+	})
+	require.EqualError(t, ManifestPipe{}.Publish(ctx), "docker manifest foo/bar:v1.0.0: image foo/bar:v1.0.0-386 wasn't pushed in this release")
+}
+
+func TestManifestPublishFailed(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, os.Mkdir(filepath.Join(folder, "bin"), 0755))
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "bin", "docker"),
+		[]byte("#!/bin/sh\necho no such manifest\nexit 1\n"),
+		0755,
+	))
+	var path = os.Getenv("PATH")
+	require.NoError(t, os.Setenv("PATH", filepath.Join(folder, "bin")+string(os.PathListSeparator)+path))
+	defer os.Setenv("PATH", path) // nolint: errcheck
+
+	var ctx = manifestContext(config.DockerManifest{
+		NameTemplate:   "foo/bar:{{ .Tag }}",
+		ImageTemplates: []string{"foo/bar:{{ .Tag }}-amd64"},
+		Use:            "docker",
+	})
+	var err = ManifestPipe{}.Publish(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to run docker manifest create")
+	require.Contains(t, err.Error(), "no such manifest")
+}
@@ -0,0 +1,232 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/apex/log"
+	dockerconfig "github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// binDir is where the binaries are put in the images built without a
+// Dockerfile
+const binDir = "/usr/local/bin"
+
+// registryBuild builds the image without a docker daemon, adding a layer with
+// the binaries on top of the base image, fetched from its registry. The image
+// is saved as a tarball to the given path, so it can be pushed later.
+func registryBuild(ctx *context.Context, creds *Credentials, docker config.Docker, bins []*artifact.Artifact, images []string, path string) (string, error) {
+	log.WithField("image", images[0]).WithField("base", docker.BaseImage).Info("building image")
+	var refs = make([]name.Reference, 0, len(images))
+	for _, image := range images {
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid image name '%s'", image)
+		}
+		refs = append(refs, ref)
+	}
+	base, err := name.ParseReference(docker.BaseImage)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid base image '%s'", docker.BaseImage)
+	}
+	var platform = v1.Platform{OS: docker.Goos, Architecture: docker.Goarch}
+	if docker.Goarm != "" {
+		platform.Variant = "v" + docker.Goarm
+	}
+	img, err := remote.Image(base, remoteOptions(ctx, creds, remote.WithPlatform(platform))...)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to fetch base image '%s'", docker.BaseImage)
+	}
+
+	bts, err := binariesLayer(bins)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create the binaries layer")
+	}
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(bts)), nil
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create the binaries layer")
+	}
+	img, err = mutate.Append(img, mutate.Addendum{
+		Layer: layer,
+		History: v1.History{
+			Author:    "goreleaser",
+			CreatedBy: "goreleaser",
+			Created:   v1.Time{Time: ctx.Git.CommitDate},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	img, err = configure(ctx, docker, img, bins)
+	if err != nil {
+		return "", err
+	}
+
+	var tagged = map[name.Reference]v1.Image{}
+	for _, ref := range refs {
+		tagged[ref] = img
+	}
+	if err := tarball.MultiRefWriteToFile(path, tagged); err != nil {
+		return "", errors.Wrap(err, "failed to save image")
+	}
+	return path, nil
+}
+
+// binariesLayer returns an uncompressed tar with the binaries in binDir
+func binariesLayer(bins []*artifact.Artifact) ([]byte, error) {
+	var buf bytes.Buffer
+	var w = tar.NewWriter(&buf)
+	var dir = ""
+	for _, part := range strings.Split(strings.Trim(binDir, "/"), "/") {
+		dir = path.Join(dir, part)
+		if err := w.WriteHeader(&tar.Header{
+			Name:     dir + "/",
+			Typeflag: tar.TypeDir,
+			Mode:     0755,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, bin := range bins {
+		bts, err := ioutil.ReadFile(bin.Path)
+		if err != nil {
+			return nil, err
+		}
+		if err := w.WriteHeader(&tar.Header{
+			Name:     path.Join(dir, bin.Name),
+			Typeflag: tar.TypeReg,
+			Mode:     0755,
+			Size:     int64(len(bts)),
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(bts); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// configure makes the first binary the entrypoint of the image and sets its
+// labels
+func configure(ctx *context.Context, docker config.Docker, img v1.Image, bins []*artifact.Artifact) (v1.Image, error) {
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	cfg = cfg.DeepCopy()
+	cfg.Config.Entrypoint = []string{path.Join(binDir, bins[0].Name)}
+	cfg.Config.Cmd = nil
+	flags, err := processBuildFlagTemplates(ctx, docker)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Config.Labels == nil {
+		cfg.Config.Labels = map[string]string{}
+	}
+	for _, flag := range flags {
+		var label = strings.SplitN(strings.TrimPrefix(flag, "--label="), "=", 2)
+		cfg.Config.Labels[label[0]] = label[1]
+	}
+	img, err = mutate.ConfigFile(img, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return mutate.CreatedAt(img, v1.Time{Time: ctx.Git.CommitDate})
+}
+
+func tarballName(image string) string {
+	return strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(image) + ".tar"
+}
+
+// registryPush pushes the image saved by registryBuild, returning its digest
+func registryPush(ctx *context.Context, creds *Credentials, image *artifact.Artifact, path string) (string, error) {
+	ref, err := name.ParseReference(image.Name)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid image name '%s'", image.Name)
+	}
+	img, err := tarball.ImageFromPath(path, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to load image")
+	}
+	if err := remote.Write(ref, img, remoteOptions(ctx, creds)...); err != nil {
+		return "", errors.Wrap(err, "failed to push image")
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return "", err
+	}
+	return digest.String(), nil
+}
+
+func remoteOptions(ctx *context.Context, creds *Credentials, options ...remote.Option) []remote.Option {
+	return append(
+		options,
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(creds.Keychain(ctx)),
+	)
+}
+
+// keychain resolves the credentials of the registries from the docker config
+// in the given dir, like docker does
+type keychain struct {
+	dir string
+}
+
+func (k keychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	cf, err := dockerconfig.Load(k.dir)
+	if err != nil {
+		return nil, err
+	}
+	var key = target.RegistryStr()
+	if key == name.DefaultRegistry {
+		key = authn.DefaultAuthKey
+	}
+	cfg, err := cf.GetAuthConfig(key)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == (types.AuthConfig{}) {
+		return authn.Anonymous, nil
+	}
+	return authn.FromConfig(authn.AuthConfig{
+		Username:      cfg.Username,
+		Password:      cfg.Password,
+		Auth:          cfg.Auth,
+		IdentityToken: cfg.IdentityToken,
+		RegistryToken: cfg.RegistryToken,
+	}), nil
+}
+
+func validateRegistryBuild(docker config.Docker) error {
+	if docker.Dockerfile != "" || len(docker.Files) > 0 {
+		return fmt.Errorf("invalid docker config: dockerfile and extra_files can't be used with use: registry")
+	}
+	for _, flag := range docker.BuildFlagTemplates {
+		if !strings.HasPrefix(flag, "--label=") || !strings.Contains(strings.TrimPrefix(flag, "--label="), "=") {
+			return fmt.Errorf("invalid docker config: only --label build flags can be used with use: registry, got %s", flag)
+		}
+	}
+	return nil
+}
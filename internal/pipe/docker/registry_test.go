@@ -0,0 +1,193 @@
+package docker
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	ggcrregistry "github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// testRegistry starts an in memory registry with a base image, returning its
+// host
+func testRegistry(t *testing.T) (string, func()) {
+	var server = httptest.NewServer(ggcrregistry.New(ggcrregistry.Logger(log.New(ioutil.Discard, "", 0))))
+	var host = strings.TrimPrefix(server.URL, "http://")
+	base, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(host + "/base:latest")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, base))
+	return host, server.Close
+}
+
+func registryContext(t *testing.T, dist string, docker config.Docker) *context.Context {
+	var ctx = context.New(config.Project{
+		Dist:    dist,
+		Dockers: []config.Docker{docker},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Git.FullCommit = "a1b2c3d4"
+	// no docker config with credentials for the test registry
+	ctx.Env["DOCKER_CONFIG"] = dist
+	var bin = filepath.Join(dist, "mybin")
+	require.NoError(t, ioutil.WriteFile(bin, []byte("#!/bin/sh\necho hi\n"), 0755))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   bin,
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra:  map[string]interface{}{"Binary": "mybin"},
+	})
+	return ctx
+}
+
+func TestRegistryBuildAndPush(t *testing.T) {
+	host, stop := testRegistry(t)
+	defer stop()
+	dist, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dist) // nolint: errcheck
+
+	var ctx = registryContext(t, dist, config.Docker{
+		Use:            "registry",
+		BaseImage:      host + "/base:latest",
+		Binaries:       []string{"mybin"},
+		ImageTemplates: []string{host + "/app:{{ .Tag }}", host + "/app:latest"},
+		Labels:         map[string]string{"org.opencontainers.image.title": "{{ .ProjectName }}"},
+		Attest:         config.DockerAttest{SBOM: true},
+	})
+	ctx.Config.ProjectName = "mybin"
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Empty(t, ctx.Config.Dockers[0].Dockerfile)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var images = ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableDockerImage)).List()
+	require.Len(t, images, 2)
+	var tarball = filepath.Join(dist, tarballName(host+"/app:v1.0.0"))
+	require.FileExists(t, tarball)
+	for _, image := range images {
+		require.Equal(t, tarball, image.ExtraOr("Tarball", ""))
+	}
+
+	require.NoError(t, Pipe{}.Publish(ctx))
+	var pushed = ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImage)).List()
+	require.Len(t, pushed, 2)
+
+	ref, err := name.ParseReference(host + "/app:v1.0.0")
+	require.NoError(t, err)
+	img, err := remote.Image(ref)
+	require.NoError(t, err)
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	for _, image := range pushed {
+		require.Equal(t, digest.String(), image.ExtraOr("Digest", ""))
+		require.Equal(t, config.DockerAttest{SBOM: true}, image.ExtraOr("Attest", nil))
+	}
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	require.Len(t, layers, 2)
+	cfg, err := img.ConfigFile()
+	require.NoError(t, err)
+	require.Equal(t, []string{"/usr/local/bin/mybin"}, cfg.Config.Entrypoint)
+	require.Equal(t, "mybin", cfg.Config.Labels["org.opencontainers.image.title"])
+	require.Equal(t, "1.0.0", cfg.Config.Labels["org.opencontainers.image.version"])
+	require.Equal(t, "a1b2c3d4", cfg.Config.Labels["org.opencontainers.image.revision"])
+}
+
+func TestRegistryBuildSave(t *testing.T) {
+	host, stop := testRegistry(t)
+	defer stop()
+	dist, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dist) // nolint: errcheck
+
+	var ctx = registryContext(t, dist, config.Docker{
+		Use:            "registry",
+		BaseImage:      host + "/base:latest",
+		Binaries:       []string{"mybin"},
+		ImageTemplates: []string{host + "/app:{{ .Tag }}", host + "/app:latest"},
+		Save:           true,
+		SkipPush:       "true",
+	})
+	ctx.Config.ProjectName = "mybin"
+	require.NoError(t, Pipe{}.Default(ctx))
+	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+
+	var saved = ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableDockerImage)).List()
+	require.Len(t, saved, 1)
+	require.Equal(t, "mybin_1.0.0_linux_amd64.docker.tar", saved[0].Name)
+	require.Equal(t, filepath.Join(dist, "mybin_1.0.0_linux_amd64.docker.tar"), saved[0].Path)
+	for _, image := range []string{host + "/app:v1.0.0", host + "/app:latest"} {
+		tag, err := name.NewTag(image)
+		require.NoError(t, err)
+		img, err := tarball.ImageFromPath(saved[0].Path, &tag)
+		require.NoError(t, err)
+		cfg, err := img.ConfigFile()
+		require.NoError(t, err)
+		require.Equal(t, []string{"/usr/local/bin/mybin"}, cfg.Config.Entrypoint)
+	}
+}
+
+func TestRegistryBuildBaseImageNotFound(t *testing.T) {
+	host, stop := testRegistry(t)
+	defer stop()
+	dist, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dist) // nolint: errcheck
+
+	var ctx = registryContext(t, dist, config.Docker{
+		Use:            "registry",
+		BaseImage:      host + "/nope:latest",
+		Binaries:       []string{"mybin"},
+		ImageTemplates: []string{host + "/app:{{ .Tag }}"},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to fetch base image '"+host+"/nope:latest'")
+}
+
+func TestRegistryDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Dockers: []config.Docker{{Use: "registry", Binaries: []string{"mybin"}}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "gcr.io/distroless/static:nonroot", ctx.Config.Dockers[0].BaseImage)
+	require.Empty(t, ctx.Config.Dockers[0].Dockerfile)
+}
+
+func TestRegistryDefaultInvalid(t *testing.T) {
+	for docker, err := range map[*config.Docker]string{
+		{Use: "nerdctl"}: "invalid docker.use: nerdctl, should be docker, podman or registry",
+		{Use: "registry", Dockerfile: "Dockerfile"}:                     "invalid docker config: dockerfile and extra_files can't be used with use: registry",
+		{Use: "registry", Files: []string{"config.yml"}}:                "invalid docker config: dockerfile and extra_files can't be used with use: registry",
+		{Use: "registry", BuildFlagTemplates: []string{"--pull"}}:       "invalid docker config: only --label build flags can be used with use: registry, got --pull",
+		{Use: "registry", BuildFlagTemplates: []string{"--label=nope"}}: "invalid docker config: only --label build flags can be used with use: registry, got --label=nope",
+	} {
+		var ctx = context.New(config.Project{
+			Dist:    "dist",
+			Dockers: []config.Docker{*docker, *docker},
+		})
+		require.EqualError(t, Pipe{}.Default(ctx), err)
+	}
+}
+
+func TestTarballName(t *testing.T) {
+	require.Equal(t, "ghcr.io_foo_bar_v1.0.0.tar", tarballName("ghcr.io/foo/bar:v1.0.0"))
+}
@@ -1,14 +1,26 @@
 package effectiveconfig
 
 import (
+	"bytes"
 	"io/ioutil"
 	"path/filepath"
+	"regexp"
+	"sort"
 
 	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	yaml "gopkg.in/yaml.v2"
 )
 
+// Filename of the effective config file inside the dist folder
+const Filename = "config.yaml"
+
+const redacted = "[REDACTED]"
+
+// nolint: gochecknoglobals
+var secretEnv = regexp.MustCompile(`(?i)(token|secret|password|passphrase|key)`)
+
 // Pipe that writes the effective config file to dist
 type Pipe struct {
 }
@@ -19,11 +31,43 @@ func (Pipe) String() string {
 
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) (err error) {
-	var path = filepath.Join(ctx.Config.Dist, "config.yaml")
+	var path = filepath.Join(ctx.Config.Dist, Filename)
 	bts, err := yaml.Marshal(ctx.Config)
 	if err != nil {
 		return err
 	}
 	log.WithField("config", path).Info("writing")
-	return ioutil.WriteFile(path, bts, 0644)
+	if err := ioutil.WriteFile(path, redact(ctx, bts), 0644); err != nil {
+		return err
+	}
+	if ctx.Config.Release.IncludeConfig {
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type: artifact.Config,
+			Name: ctx.Config.ProjectName + "_" + ctx.Version + "_" + Filename,
+			Path: path,
+		})
+	}
+	return nil
+}
+
+// redact replaces the token and the values of secret-looking environment
+// variables in the given config.
+func redact(ctx *context.Context, bts []byte) []byte {
+	var secrets []string
+	if ctx.Token != "" {
+		secrets = append(secrets, ctx.Token)
+	}
+	for k, v := range ctx.Env {
+		if v != "" && secretEnv.MatchString(k) {
+			secrets = append(secrets, v)
+		}
+	}
+	// longer secrets first, so secrets containing others are fully redacted
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+	for _, secret := range secrets {
+		bts = bytes.ReplaceAll(bts, []byte(secret), []byte(redacted))
+	}
+	return bts
 }
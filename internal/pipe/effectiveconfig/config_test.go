@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/testlib"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -31,3 +32,49 @@ func Test(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, string(bts))
 }
+
+func TestRedactAndInclude(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(
+		config.Project{
+			ProjectName: "foo",
+			Dist:        folder,
+			Env: []string{
+				"API_KEY=supersecret",
+				"NAME=foo-app",
+			},
+			Release: config.Release{
+				IncludeConfig: true,
+			},
+		},
+	)
+	ctx.Version = "1.0.0"
+	ctx.Token = "ghp_token"
+	ctx.Env = context.Env{
+		"API_KEY":        "supersecret",
+		"GPG_PASSPHRASE": "",
+		"NAME":           "foo-app",
+	}
+	ctx.Config.Brews = []config.Homebrew{{Description: "token is ghp_token"}}
+	assert.NoError(t, Pipe{}.Run(ctx))
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "config.yaml"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(bts), "supersecret")
+	assert.NotContains(t, string(bts), "ghp_token")
+	assert.Contains(t, string(bts), "API_KEY=[REDACTED]")
+	assert.Contains(t, string(bts), "NAME=foo-app")
+
+	var configs = ctx.Artifacts.Filter(artifact.ByType(artifact.Config)).List()
+	assert.Len(t, configs, 1)
+	assert.Equal(t, "foo_1.0.0_config.yaml", configs[0].Name)
+	assert.Equal(t, filepath.Join(folder, "config.yaml"), configs[0].Path)
+}
+
+func TestDontInclude(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{Dist: folder})
+	assert.NoError(t, Pipe{}.Run(ctx))
+	assert.Empty(t, ctx.Artifacts.List())
+}
@@ -4,8 +4,12 @@ package env
 
 import (
 	"bufio"
+	"fmt"
+	"net/url"
 	"os"
+	"strings"
 
+	"github.com/goreleaser/goreleaser/internal/git"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	homedir "github.com/mitchellh/go-homedir"
@@ -64,8 +68,26 @@ func (Pipe) Run(ctx *context.Context) error {
 	if giteaToken != "" {
 		numOfTokens++
 	}
-	if numOfTokens > 1 {
-		return ErrMultipleTokens
+	var tokenType = context.TokenType(ctx.Config.ForceToken)
+	if tokenType == "" && numOfTokens > 1 {
+		tokenType = remoteTokenType(ctx)
+	}
+	switch tokenType {
+	case "":
+		if numOfTokens > 1 {
+			return ErrMultipleTokens
+		}
+	case context.TokenTypeGitHub:
+		gitlabToken, gitlabTokenErr = "", nil
+		giteaToken, giteaTokenErr = "", nil
+	case context.TokenTypeGitLab:
+		githubToken, githubTokenErr = "", nil
+		giteaToken, giteaTokenErr = "", nil
+	case context.TokenTypeGitea:
+		githubToken, githubTokenErr = "", nil
+		gitlabToken, gitlabTokenErr = "", nil
+	default:
+		return fmt.Errorf("invalid force_token %q, use github, gitlab or gitea", ctx.Config.ForceToken)
 	}
 
 	noTokens := githubToken == "" && gitlabToken == "" && giteaToken == ""
@@ -105,6 +127,44 @@ func (Pipe) Run(ctx *context.Context) error {
 	return nil
 }
 
+// remoteTokenType guesses the token type from the host of the origin remote,
+// returning an empty type if it can't
+func remoteTokenType(ctx *context.Context) context.TokenType {
+	remote, err := git.Clean(git.Run("config", "--get", "remote.origin.url"))
+	if err != nil || remote == "" {
+		return ""
+	}
+	for _, known := range []struct {
+		url       string
+		tokenType context.TokenType
+	}{
+		{ctx.Config.GitLabURLs.Download, context.TokenTypeGitLab},
+		{ctx.Config.GitLabURLs.API, context.TokenTypeGitLab},
+		{ctx.Config.GiteaURLs.API, context.TokenTypeGitea},
+		{ctx.Config.GitHubURLs.Download, context.TokenTypeGitHub},
+		{ctx.Config.GitHubURLs.API, context.TokenTypeGitHub},
+	} {
+		if host := urlHost(known.url); host != "" && strings.Contains(remote, host) {
+			return known.tokenType
+		}
+	}
+	switch {
+	case strings.Contains(remote, "gitlab"):
+		return context.TokenTypeGitLab
+	case strings.Contains(remote, "github"):
+		return context.TokenTypeGitHub
+	}
+	return ""
+}
+
+func urlHost(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
 func loadEnv(env, path string) (string, error) {
 	val := os.Getenv(env)
 	if val != "" {
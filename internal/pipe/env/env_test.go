@@ -83,6 +83,8 @@ func TestInvalidEnv(t *testing.T) {
 }
 
 func TestMultipleEnvTokens(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
 	assert.NoError(t, os.Setenv("GITHUB_TOKEN", "asdf"))
 	assert.NoError(t, os.Setenv("GITLAB_TOKEN", "qwertz"))
 	assert.NoError(t, os.Setenv("GITEA_TOKEN", "token"))
@@ -97,6 +99,64 @@ func TestMultipleEnvTokens(t *testing.T) {
 	assert.NoError(t, os.Unsetenv("GITEA_TOKEN"))
 }
 
+func TestMultipleEnvTokensFromRemote(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "git@gitlab.com:goreleaser/goreleaser.git")
+	assert.NoError(t, os.Setenv("GITHUB_TOKEN", "asdf"))
+	assert.NoError(t, os.Setenv("GITLAB_TOKEN", "qwertz"))
+	var ctx = &context.Context{
+		Config: config.Project{},
+	}
+	assert.NoError(t, Pipe{}.Run(ctx))
+	assert.Equal(t, "qwertz", ctx.Token)
+	assert.Equal(t, context.TokenTypeGitLab, ctx.TokenType)
+	// so the tests do not depend on each other
+	assert.NoError(t, os.Unsetenv("GITHUB_TOKEN"))
+	assert.NoError(t, os.Unsetenv("GITLAB_TOKEN"))
+}
+
+func TestMultipleEnvTokensFromCustomRemote(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "https://git.example.com/goreleaser/goreleaser.git")
+	assert.NoError(t, os.Setenv("GITHUB_TOKEN", "asdf"))
+	assert.NoError(t, os.Setenv("GITEA_TOKEN", "token"))
+	var ctx = &context.Context{
+		Config: config.Project{
+			GiteaURLs: config.GiteaURLs{API: "https://git.example.com/api/v1"},
+		},
+	}
+	assert.NoError(t, Pipe{}.Run(ctx))
+	assert.Equal(t, "token", ctx.Token)
+	assert.Equal(t, context.TokenTypeGitea, ctx.TokenType)
+	// so the tests do not depend on each other
+	assert.NoError(t, os.Unsetenv("GITHUB_TOKEN"))
+	assert.NoError(t, os.Unsetenv("GITEA_TOKEN"))
+}
+
+func TestForceToken(t *testing.T) {
+	assert.NoError(t, os.Setenv("GITHUB_TOKEN", "asdf"))
+	assert.NoError(t, os.Setenv("GITLAB_TOKEN", "qwertz"))
+	var ctx = &context.Context{
+		Config: config.Project{ForceToken: "gitlab"},
+	}
+	assert.NoError(t, Pipe{}.Run(ctx))
+	assert.Equal(t, "qwertz", ctx.Token)
+	assert.Equal(t, context.TokenTypeGitLab, ctx.TokenType)
+
+	ctx.Config.ForceToken = "gitea"
+	assert.EqualError(t, Pipe{}.Run(ctx), ErrMissingToken.Error())
+
+	ctx.Config.ForceToken = "bitbucket"
+	assert.EqualError(t, Pipe{}.Run(ctx), `invalid force_token "bitbucket", use github, gitlab or gitea`)
+	// so the tests do not depend on each other
+	assert.NoError(t, os.Unsetenv("GITHUB_TOKEN"))
+	assert.NoError(t, os.Unsetenv("GITLAB_TOKEN"))
+}
+
 func TestEmptyGithubFileEnv(t *testing.T) {
 	assert.NoError(t, os.Unsetenv("GITHUB_TOKEN"))
 	var ctx = &context.Context{
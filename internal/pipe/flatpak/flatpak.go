@@ -0,0 +1,397 @@
+// Package flatpak implements the Pipe interface building flatpak bundles with
+// flatpak-builder.
+package flatpak
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/desktop"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoFlatpakBuilder is shown when flatpak-builder cannot be found in $PATH
+var ErrNoFlatpakBuilder = errors.New("flatpak-builder not present in $PATH")
+
+// ErrNoFlatpak is shown when flatpak cannot be found in $PATH
+var ErrNoFlatpak = errors.New("flatpak not present in $PATH")
+
+const defaultNameTemplate = "{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}"
+
+// Manifest of the flatpak-builder build
+type Manifest struct {
+	AppID          string   `json:"app-id"`
+	Runtime        string   `json:"runtime"`
+	RuntimeVersion string   `json:"runtime-version"`
+	SDK            string   `json:"sdk"`
+	Command        string   `json:"command"`
+	FinishArgs     []string `json:"finish-args,omitempty"`
+	// RenameDesktopFile and RenameIcon name the desktop entry and its icons
+	// after the app ID, as flatpak only exports those
+	RenameDesktopFile string   `json:"rename-desktop-file,omitempty"`
+	RenameIcon        string   `json:"rename-icon,omitempty"`
+	Modules           []Module `json:"modules"`
+}
+
+// Module of the manifest, installing the binaries and files in /app
+type Module struct {
+	Name          string   `json:"name"`
+	BuildSystem   string   `json:"buildsystem"`
+	BuildCommands []string `json:"build-commands"`
+	Sources       []Source `json:"sources"`
+}
+
+// Source of a module
+type Source struct {
+	Type         string `json:"type"`
+	Path         string `json:"path"`
+	DestFilename string `json:"dest-filename,omitempty"`
+}
+
+// Pipe for flatpak packaging
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "flatpak bundles"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	var ids = ids.New("flatpaks")
+	for i := range ctx.Config.Flatpaks {
+		var flatpak = &ctx.Config.Flatpaks[i]
+		if flatpak.ID == "" {
+			flatpak.ID = "default"
+		}
+		if flatpak.AppID == "" {
+			return fmt.Errorf("flatpak %s: app_id is required", flatpak.ID)
+		}
+		if flatpak.NameTemplate == "" {
+			flatpak.NameTemplate = defaultNameTemplate
+		}
+		if len(flatpak.Builds) == 0 {
+			for _, b := range ctx.Config.Builds {
+				flatpak.Builds = append(flatpak.Builds, b.ID)
+			}
+		}
+		if flatpak.Runtime == "" {
+			flatpak.Runtime = "org.freedesktop.Platform"
+		}
+		if flatpak.RuntimeVersion == "" {
+			flatpak.RuntimeVersion = "23.08"
+		}
+		if flatpak.SDK == "" {
+			flatpak.SDK = "org.freedesktop.Sdk"
+		}
+		if flatpak.Branch == "" {
+			flatpak.Branch = "stable"
+		}
+		ids.Inc(flatpak.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.Flatpaks) == 0 {
+		return pipe.Skip("flatpak section is not configured")
+	}
+	if _, err := exec.LookPath("flatpak-builder"); err != nil {
+		return ErrNoFlatpakBuilder
+	}
+	if _, err := exec.LookPath("flatpak"); err != nil {
+		return ErrNoFlatpak
+	}
+	for _, flatpak := range ctx.Config.Flatpaks {
+		ok, err := condition.Check(ctx, flatpak.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("flatpak", flatpak.ID).Info("skipped because its condition is false")
+			continue
+		}
+		if err := doRun(ctx, flatpak); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, flatpak config.Flatpak) error {
+	var linuxBinaries = ctx.Artifacts.Filter(artifact.And(
+		artifact.ByGoos("linux"),
+		artifact.ByType(artifact.Binary),
+		artifact.ByIDs(flatpak.Builds...),
+	)).GroupByPlatform()
+	if len(linuxBinaries) == 0 {
+		return fmt.Errorf("no linux binaries found for flatpak %s", flatpak.ID)
+	}
+	var entry *desktop.Entry
+	if flatpak.Desktop.File != "" || len(flatpak.Desktop.Icons) > 0 {
+		loaded, err := desktop.Load(flatpak.Desktop)
+		if err != nil {
+			return err
+		}
+		entry = &loaded
+	}
+	var g = semerrgroup.New(ctx.Parallelism)
+	for platform, binaries := range linuxBinaries {
+		var arch = archFor(binaries[0].Goarch)
+		if arch == "" {
+			log.WithField("platform", platform).Warn("ignored unsupported arch")
+			continue
+		}
+		binaries := binaries
+		g.Go(func() error {
+			return create(ctx, flatpak, entry, arch, binaries)
+		})
+	}
+	return g.Wait()
+}
+
+// archFor returns the flatpak arch of the given goarch, or an empty string if
+// flatpak doesn't support it
+func archFor(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	}
+	return ""
+}
+
+func create(ctx *context.Context, flatpak config.Flatpak, entry *desktop.Entry, arch string, binaries []*artifact.Artifact) error {
+	name, err := tmpl.New(ctx).WithArtifact(binaries[0], map[string]string{}).Apply(flatpak.NameTemplate)
+	if err != nil {
+		return err
+	}
+	var log = log.WithField("flatpak", name).WithField("arch", arch)
+	var dir = filepath.Join(ctx.Config.Dist, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var file = filepath.Join(dir, flatpak.AppID+".json")
+	log.WithField("file", file).Debug("creating manifest")
+	manifest, err := manifestFor(flatpak, entry, binaries)
+	if err != nil {
+		return err
+	}
+	bts, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(file, bts, 0644); err != nil {
+		return err
+	}
+
+	var repo = filepath.Join(dir, "repo")
+	log.Info("building")
+	if err := run(
+		ctx,
+		"flatpak-builder", "--force-clean",
+		"--arch="+arch,
+		"--default-branch="+flatpak.Branch,
+		"--repo="+repo,
+		filepath.Join(dir, "build"),
+		file,
+	); err != nil {
+		return errors.Wrapf(err, "failed to build %s", name)
+	}
+	var bundle = filepath.Join(ctx.Config.Dist, name+".flatpak")
+	log.WithField("bundle", bundle).Info("creating bundle")
+	if err := run(
+		ctx,
+		"flatpak", "build-bundle",
+		"--arch="+arch,
+		repo,
+		bundle,
+		flatpak.AppID,
+		flatpak.Branch,
+	); err != nil {
+		return errors.Wrapf(err, "failed to bundle %s", name)
+	}
+
+	var publishRepo string
+	if flatpak.Repo != "" {
+		publishRepo, err = tmpl.New(ctx).WithArtifact(binaries[0], map[string]string{}).Apply(flatpak.Repo)
+		if err != nil {
+			return err
+		}
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   artifact.Flatpak,
+		Name:   name + ".flatpak",
+		Path:   bundle,
+		Goos:   binaries[0].Goos,
+		Goarch: binaries[0].Goarch,
+		Goarm:  binaries[0].Goarm,
+		Extra: map[string]interface{}{
+			"ID":          flatpak.ID,
+			"AppID":       flatpak.AppID,
+			"Branch":      flatpak.Branch,
+			"Arch":        arch,
+			"Repo":        repo,
+			"PublishRepo": publishRepo,
+		},
+	})
+	return nil
+}
+
+// manifestFor returns the manifest of a flatpak with a single module, which
+// installs the binaries in /app/bin, the desktop entry and its icons in
+// /app/share and the files in /app
+func manifestFor(flatpak config.Flatpak, entry *desktop.Entry, binaries []*artifact.Artifact) (Manifest, error) {
+	var module = Module{
+		Name:        flatpak.AppID,
+		BuildSystem: "simple",
+	}
+	var seen = map[string]string{}
+	// add installs src as dst, name being its file name in the build folder
+	var add = func(src, name, dst string, mode string) error {
+		if other, ok := seen[name]; ok {
+			return fmt.Errorf("flatpak %s: %s and %s have the same name", flatpak.ID, other, src)
+		}
+		seen[name] = src
+		abs, err := filepath.Abs(src)
+		if err != nil {
+			return err
+		}
+		var source = Source{Type: "file", Path: abs}
+		if name != filepath.Base(src) {
+			source.DestFilename = name
+		}
+		module.Sources = append(module.Sources, source)
+		module.BuildCommands = append(
+			module.BuildCommands,
+			fmt.Sprintf("install -Dm%s %s %s", mode, name, path.Join("/app", dst)),
+		)
+		return nil
+	}
+	for _, binary := range binaries {
+		if err := add(binary.Path, filepath.Base(binary.Path), path.Join("bin", binary.Name), "755"); err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	var manifest = Manifest{
+		AppID:          flatpak.AppID,
+		Runtime:        flatpak.Runtime,
+		RuntimeVersion: flatpak.RuntimeVersion,
+		SDK:            flatpak.SDK,
+		Command:        flatpak.Command,
+		FinishArgs:     flatpak.FinishArgs,
+	}
+	if manifest.Command == "" {
+		manifest.Command = binaries[0].Name
+	}
+	if entry != nil {
+		var files = entry.Files("")
+		if err := add(entry.File, filepath.Base(entry.File), files[entry.File], "644"); err != nil {
+			return Manifest{}, err
+		}
+		var sizes = make([]string, 0, len(entry.Icons))
+		for size := range entry.Icons {
+			sizes = append(sizes, size)
+		}
+		sort.Strings(sizes)
+		for _, size := range sizes {
+			var src = entry.Icons[size]
+			// icons of different sizes often have the same file name
+			if err := add(src, "icon-"+size+filepath.Ext(src), files[src], "644"); err != nil {
+				return Manifest{}, err
+			}
+		}
+		if name := filepath.Base(entry.File); name != flatpak.AppID+".desktop" {
+			manifest.RenameDesktopFile = name
+		}
+		if icon := entry.Keys["Icon"]; len(entry.Icons) > 0 && icon != flatpak.AppID {
+			manifest.RenameIcon = icon
+		}
+	}
+	var srcs = make([]string, 0, len(flatpak.Files))
+	for src := range flatpak.Files {
+		srcs = append(srcs, src)
+	}
+	sort.Strings(srcs)
+	for _, src := range srcs {
+		if err := add(src, filepath.Base(src), flatpak.Files[src], "644"); err != nil {
+			return Manifest{}, err
+		}
+	}
+	manifest.Modules = []Module{module}
+	return manifest, nil
+}
+
+// Publish commits the bundled builds to their flatpak repos
+func (Pipe) Publish(ctx *context.Context) error {
+	var bundles = ctx.Artifacts.Filter(artifact.And(
+		artifact.ByType(artifact.Flatpak),
+		func(a *artifact.Artifact) bool {
+			return a.ExtraOr("PublishRepo", "") != ""
+		},
+	)).List()
+	if len(bundles) == 0 {
+		return pipe.Skip("no flatpak repo configured")
+	}
+	// commits are done one at a time, as they lock the repo
+	var repos []string
+	var seen = map[string]bool{}
+	for _, bundle := range bundles {
+		var repo = bundle.ExtraOr("PublishRepo", "").(string)
+		var ref = fmt.Sprintf(
+			"app/%s/%s/%s",
+			bundle.ExtraOr("AppID", ""),
+			bundle.ExtraOr("Arch", ""),
+			bundle.ExtraOr("Branch", ""),
+		)
+		log.WithField("ref", ref).WithField("repo", repo).Info("publishing")
+		if err := run(
+			ctx,
+			"flatpak", "build-commit-from",
+			"--src-repo="+bundle.ExtraOr("Repo", "").(string),
+			repo,
+			ref,
+		); err != nil {
+			return errors.Wrapf(err, "failed to publish %s to %s", bundle.Name, repo)
+		}
+		if !seen[repo] {
+			seen[repo] = true
+			repos = append(repos, repo)
+		}
+	}
+	for _, repo := range repos {
+		log.WithField("repo", repo).Info("updating repo")
+		if err := run(ctx, "flatpak", "build-update-repo", repo); err != nil {
+			return errors.Wrapf(err, "failed to update %s", repo)
+		}
+	}
+	return nil
+}
+
+func run(ctx *context.Context, bin string, args ...string) error {
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, bin, args...)
+	cmd.Env = ctx.Env.Strings()
+	log.WithField("cmd", cmd.Args).Debug("running")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %s", bin, string(out))
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+package flatpak
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Builds:   []config.Build{{ID: "foo"}},
+		Flatpaks: []config.Flatpak{{AppID: "dev.goreleaser.Foo"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Flatpak{
+		ID:             "default",
+		AppID:          "dev.goreleaser.Foo",
+		Builds:         []string{"foo"},
+		NameTemplate:   defaultNameTemplate,
+		Runtime:        "org.freedesktop.Platform",
+		RuntimeVersion: "23.08",
+		SDK:            "org.freedesktop.Sdk",
+		Branch:         "stable",
+	}, ctx.Config.Flatpaks[0])
+}
+
+func TestDefaultNoAppID(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Flatpaks: []config.Flatpak{{ID: "foo"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "flatpak foo: app_id is required")
+}
+
+func TestDefaultDuplicatedID(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Flatpaks: []config.Flatpak{
+			{ID: "a", AppID: "dev.goreleaser.A"},
+			{ID: "a", AppID: "dev.goreleaser.B"},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 flatpaks with the ID 'a', please fix your config")
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+	testlib.AssertSkipped(t, Pipe{}.Publish(context.New(config.Project{})))
+}
+
+// fakeFlatpak puts a fake flatpak-builder and flatpak in the PATH, which log
+// their arguments and create the bundles, returning a func that restores the
+// PATH.
+func fakeFlatpak(t *testing.T, folder, log string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"flatpak-builder": "echo flatpak-builder \"$@\" >> " + log + "\n",
+		"flatpak": `echo flatpak "$@" >> ` + log + `
+if [ "$1" = build-bundle ]; then
+  touch "$4"
+fi
+`,
+	})
+}
+
+func flatpakContext(t *testing.T, folder string, flatpak config.Flatpak) *context.Context {
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0755))
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		Builds:      []config.Build{{ID: "foo"}},
+		Flatpaks:    []config.Flatpak{flatpak},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	for _, platform := range []struct{ goos, goarch string }{
+		{"linux", "amd64"},
+		{"linux", "arm64"},
+		{"linux", "386"},
+		{"darwin", "amd64"},
+	} {
+		var path = filepath.Join(dist, platform.goos+platform.goarch, "foo")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte("foo"), 0755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "foo",
+			Path:   path,
+			Goos:   platform.goos,
+			Goarch: platform.goarch,
+			Type:   artifact.Binary,
+			Extra:  map[string]interface{}{"ID": "foo"},
+		})
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestRunPipe(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "flatpak.log")
+	defer fakeFlatpak(t, folder, log)()
+	require.NoError(t, ioutil.WriteFile("foo.desktop", []byte("[Desktop Entry]"), 0644))
+	var ctx = flatpakContext(t, folder, config.Flatpak{
+		AppID:      "dev.goreleaser.Foo",
+		FinishArgs: []string{"--share=network"},
+		Files: map[string]string{
+			"foo.desktop": "share/applications/dev.goreleaser.Foo.desktop",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var bundles = ctx.Artifacts.Filter(artifact.ByType(artifact.Flatpak)).List()
+	require.Len(t, bundles, 2)
+	var names []string
+	for _, bundle := range bundles {
+		names = append(names, bundle.Name)
+		require.FileExists(t, bundle.Path)
+		require.Equal(t, "", bundle.ExtraOr("PublishRepo", nil))
+	}
+	require.ElementsMatch(t, []string{"foo_1.0.0_linux_amd64.flatpak", "foo_1.0.0_linux_arm64.flatpak"}, names)
+
+	var dir = filepath.Join(ctx.Config.Dist, "foo_1.0.0_linux_amd64")
+	bts, err := ioutil.ReadFile(filepath.Join(dir, "dev.goreleaser.Foo.json"))
+	require.NoError(t, err)
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(bts, &manifest))
+	require.Equal(t, Manifest{
+		AppID:          "dev.goreleaser.Foo",
+		Runtime:        "org.freedesktop.Platform",
+		RuntimeVersion: "23.08",
+		SDK:            "org.freedesktop.Sdk",
+		Command:        "foo",
+		FinishArgs:     []string{"--share=network"},
+		Modules: []Module{{
+			Name:        "dev.goreleaser.Foo",
+			BuildSystem: "simple",
+			BuildCommands: []string{
+				"install -Dm755 foo /app/bin/foo",
+				"install -Dm644 foo.desktop /app/share/applications/dev.goreleaser.Foo.desktop",
+			},
+			Sources: []Source{
+				{Type: "file", Path: filepath.Join(ctx.Config.Dist, "linuxamd64", "foo")},
+				{Type: "file", Path: filepath.Join(folder, "foo.desktop")},
+			},
+		}},
+	}, manifest)
+
+	bts, err = ioutil.ReadFile(log)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), strings.Join([]string{
+		"flatpak-builder --force-clean --arch=x86_64 --default-branch=stable",
+		"--repo=" + filepath.Join(dir, "repo"),
+		filepath.Join(dir, "build"),
+		filepath.Join(dir, "dev.goreleaser.Foo.json"),
+	}, " "))
+	require.Contains(t, string(bts), strings.Join([]string{
+		"flatpak build-bundle --arch=x86_64",
+		filepath.Join(dir, "repo"),
+		filepath.Join(ctx.Config.Dist, "foo_1.0.0_linux_amd64.flatpak"),
+		"dev.goreleaser.Foo stable",
+	}, " "))
+
+	testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+}
+
+func TestRunPipeSkipCondition(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeFlatpak(t, folder, filepath.Join(folder, "flatpak.log"))()
+	var ctx = flatpakContext(t, folder, config.Flatpak{
+		AppID: "dev.goreleaser.Foo",
+		If:    "false",
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Empty(t, ctx.Artifacts.Filter(artifact.ByType(artifact.Flatpak)).List())
+}
+
+func TestRunPipeNoBinaries(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeFlatpak(t, folder, filepath.Join(folder, "flatpak.log"))()
+	var ctx = flatpakContext(t, folder, config.Flatpak{
+		AppID:  "dev.goreleaser.Foo",
+		Builds: []string{"nope"},
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), "no linux binaries found for flatpak default")
+}
+
+func TestRunPipeSameFileNames(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeFlatpak(t, folder, filepath.Join(folder, "flatpak.log"))()
+	var ctx = flatpakContext(t, folder, config.Flatpak{
+		AppID: "dev.goreleaser.Foo",
+		Files: map[string]string{"completions/foo": "share/foo"},
+	})
+	var err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "have the same name")
+}
+
+func TestRunPipeDesktop(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeFlatpak(t, folder, filepath.Join(folder, "flatpak.log"))()
+	require.NoError(t, os.MkdirAll(filepath.Join("icons", "48"), 0755))
+	for file, content := range map[string]string{
+		"foo.desktop":      "[Desktop Entry]\nType=Application\nName=Foo\nExec=foo\nIcon=foo\n",
+		"icons/48/foo.png": "png",
+		"icons/foo.svg":    "svg",
+	} {
+		require.NoError(t, ioutil.WriteFile(file, []byte(content), 0644))
+	}
+	var ctx = flatpakContext(t, folder, config.Flatpak{
+		AppID: "dev.goreleaser.Foo",
+		Desktop: config.Desktop{
+			File: "foo.desktop",
+			Icons: map[string]string{
+				"48x48":    "icons/48/foo.png",
+				"scalable": "icons/foo.svg",
+			},
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	bts, err := ioutil.ReadFile(filepath.Join(ctx.Config.Dist, "foo_1.0.0_linux_amd64", "dev.goreleaser.Foo.json"))
+	require.NoError(t, err)
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(bts, &manifest))
+	require.Equal(t, "foo.desktop", manifest.RenameDesktopFile)
+	require.Equal(t, "foo", manifest.RenameIcon)
+	require.Equal(t, []string{
+		"install -Dm755 foo /app/bin/foo",
+		"install -Dm644 foo.desktop /app/share/applications/foo.desktop",
+		"install -Dm644 icon-48x48.png /app/share/icons/hicolor/48x48/apps/foo.png",
+		"install -Dm644 icon-scalable.svg /app/share/icons/hicolor/scalable/apps/foo.svg",
+	}, manifest.Modules[0].BuildCommands)
+	require.Equal(t, []Source{
+		{Type: "file", Path: filepath.Join(folder, "foo.desktop")},
+		{Type: "file", Path: filepath.Join(folder, "icons", "48", "foo.png"), DestFilename: "icon-48x48.png"},
+		{Type: "file", Path: filepath.Join(folder, "icons", "foo.svg"), DestFilename: "icon-scalable.svg"},
+	}, manifest.Modules[0].Sources[1:])
+}
+
+func TestRunPipeInvalidDesktop(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeFlatpak(t, folder, filepath.Join(folder, "flatpak.log"))()
+	require.NoError(t, ioutil.WriteFile("foo.desktop", []byte("[Desktop Entry]\nName=Foo\n"), 0644))
+	var ctx = flatpakContext(t, folder, config.Flatpak{
+		AppID:   "dev.goreleaser.Foo",
+		Desktop: config.Desktop{File: "foo.desktop"},
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), "invalid desktop file foo.desktop: missing Type key")
+}
+
+func TestRunPipeBuildFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeFlatpak(t, folder, filepath.Join(folder, "flatpak.log"))()
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "bin", "flatpak-builder"),
+		[]byte("#!/bin/sh\necho 'runtime not installed'\nexit 1\n"),
+		0755,
+	))
+	var ctx = flatpakContext(t, folder, config.Flatpak{AppID: "dev.goreleaser.Foo"})
+	var err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "flatpak-builder failed: runtime not installed")
+}
+
+func TestPublish(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "flatpak.log")
+	defer fakeFlatpak(t, folder, log)()
+	var ctx = flatpakContext(t, folder, config.Flatpak{
+		AppID:  "dev.goreleaser.Foo",
+		Branch: "beta",
+		Repo:   "{{ .Env.FLATPAK_REPO }}",
+	})
+	ctx.Env["FLATPAK_REPO"] = filepath.Join(folder, "flatpak-repo")
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	bts, err := ioutil.ReadFile(log)
+	require.NoError(t, err)
+	var repo = filepath.Join(folder, "flatpak-repo")
+	for _, arch := range []string{"x86_64", "aarch64"} {
+		require.Contains(t, string(bts), "flatpak build-commit-from --src-repo=")
+		require.Contains(t, string(bts), repo+" app/dev.goreleaser.Foo/"+arch+"/beta\n")
+	}
+	require.Equal(t, 1, strings.Count(string(bts), "flatpak build-update-repo "+repo+"\n"))
+}
+
+func TestPublishFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeFlatpak(t, folder, filepath.Join(folder, "flatpak.log"))()
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "bin", "flatpak"),
+		[]byte("#!/bin/sh\necho 'repo locked'\nexit 1\n"),
+		0755,
+	))
+	var ctx = context.New(config.Project{})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "foo.flatpak",
+		Type: artifact.Flatpak,
+		Extra: map[string]interface{}{
+			"AppID":       "dev.goreleaser.Foo",
+			"Arch":        "x86_64",
+			"Branch":      "stable",
+			"Repo":        "dist/foo/repo",
+			"PublishRepo": "repo",
+		},
+	})
+	var err = Pipe{}.Publish(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to publish foo.flatpak to repo: flatpak failed: repo locked")
+	require.False(t, pipe.IsSkip(err))
+}
+
+func TestArchFor(t *testing.T) {
+	require.Equal(t, "x86_64", archFor("amd64"))
+	require.Equal(t, "aarch64", archFor("arm64"))
+	require.Empty(t, archFor("386"))
+}
@@ -0,0 +1,230 @@
+// Package generate provides a Pipe that runs code generators before the
+// builds, skipping the ones whose inputs didn't change since the last run.
+package generate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/pipe/dist"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	zglob "github.com/mattn/go-zglob"
+	"github.com/pkg/errors"
+)
+
+// cacheFile is where the input and output hashes of each generator are
+// recorded between runs
+func cacheFile(ctx *context.Context) string {
+	return filepath.Join(ctx.Config.Dist, dist.CacheDir, "generate.json")
+}
+
+type entry struct {
+	Inputs  string `json:"inputs"`
+	Outputs string `json:"outputs"`
+}
+
+// Pipe that runs the code generators
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "generating code"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Generate {
+		if ctx.Config.Generate[i].Cmd == "" {
+			ctx.Config.Generate[i].Cmd = "go generate ./..."
+		}
+	}
+	return nil
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.Generate) == 0 {
+		return pipe.Skip("generate section is not configured")
+	}
+	var cache = loadCache(ctx)
+	for _, gen := range ctx.Config.Generate {
+		if err := run(ctx, gen, cache); err != nil {
+			return err
+		}
+	}
+	return saveCache(ctx, cache)
+}
+
+func run(ctx *context.Context, gen config.Generator, cache map[string]entry) error {
+	var genEnv []string
+	for _, e := range gen.Env {
+		ee, err := tmpl.New(ctx).Apply(e)
+		if err != nil {
+			return err
+		}
+		genEnv = append(genEnv, ee)
+	}
+	var env = append(ctx.Env.Strings(), genEnv...)
+	cmd, err := tmpl.New(ctx).WithEnvS(env).Apply(gen.Cmd)
+	if err != nil {
+		return err
+	}
+	var log = log.WithField("cmd", cmd)
+	var key = strings.Join(append([]string{cmd}, genEnv...), "\n")
+
+	// the command and its env are part of the inputs, so changing them
+	// also runs the generator again
+	inputs, err := hash(append([]string{cmd}, genEnv...), gen.Inputs)
+	if err != nil {
+		return err
+	}
+	if len(gen.Inputs) > 0 {
+		outputs, err := hash(nil, gen.Outputs)
+		if err != nil {
+			return err
+		}
+		if cached, ok := cache[key]; ok && cached.Inputs == inputs && cached.Outputs == outputs {
+			log.Info("inputs unchanged, skipping")
+			return nil
+		}
+	}
+
+	log.Info("running")
+	args, err := fields(cmd)
+	if err != nil {
+		return err
+	}
+	/* #nosec */
+	var c = exec.CommandContext(ctx, args[0], args[1:]...)
+	c.Env = env
+	if out, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("generator failed: %s\n%v", cmd, string(out))
+	}
+
+	outputs, err := hash(nil, gen.Outputs)
+	if err != nil {
+		return err
+	}
+	cache[key] = entry{Inputs: inputs, Outputs: outputs}
+	return nil
+}
+
+// hash returns the sha256 of the given strings and of the names and contents
+// of the files matching the given globs
+func hash(strs, globs []string) (string, error) {
+	var h = sha256.New()
+	for _, s := range strs {
+		fmt.Fprintln(h, s)
+	}
+	var files []string
+	for _, glob := range globs {
+		matches, err := zglob.Glob(glob)
+		if err != nil && !os.IsNotExist(err) {
+			return "", errors.Wrapf(err, "globbing failed for pattern %s", glob)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if err := hashFile(h, file); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(w io.Writer, path string) error {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint: errcheck
+	fmt.Fprintln(w, path)
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// fields splits the command in its arguments like a shell would, so quoted
+// arguments can contain spaces
+func fields(cmd string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	var inArg bool
+	var quote rune
+	var escaped bool
+	for _, r := range cmd {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in generator: %s", cmd)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty generator command")
+	}
+	return args, nil
+}
+
+func loadCache(ctx *context.Context) map[string]entry {
+	var cache = map[string]entry{}
+	bts, err := ioutil.ReadFile(cacheFile(ctx))
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(bts, &cache); err != nil {
+		log.WithError(err).Warn("ignoring invalid generate cache")
+		return map[string]entry{}
+	}
+	return cache
+}
+
+func saveCache(ctx *context.Context, cache map[string]entry) error {
+	bts, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	var path = cacheFile(ctx)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, bts, 0644)
+}
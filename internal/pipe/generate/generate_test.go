@@ -0,0 +1,142 @@
+package generate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Generate: []config.Generator{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "go generate ./...", ctx.Config.Generate[0].Cmd)
+}
+
+func TestRunCached(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, ioutil.WriteFile("schema.txt", []byte("v1"), 0644))
+	require.NoError(t, ioutil.WriteFile("gen.sh", []byte("#!/bin/sh\necho run >> runs.txt\ncp schema.txt gen.txt\n"), 0755))
+	var ctx = context.New(config.Project{
+		Dist: filepath.Join(folder, "dist"),
+		Generate: []config.Generator{
+			{
+				Cmd:     filepath.Join(folder, "gen.sh"),
+				Inputs:  []string{"schema.txt"},
+				Outputs: []string{"gen.txt"},
+			},
+		},
+	})
+
+	var runs = func() int {
+		bts, err := ioutil.ReadFile("runs.txt")
+		require.NoError(t, err)
+		return strings.Count(string(bts), "run")
+	}
+
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, 1, runs())
+	require.FileExists(t, cacheFile(ctx))
+
+	// nothing changed
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, 1, runs())
+
+	// input changed
+	require.NoError(t, ioutil.WriteFile("schema.txt", []byte("v2"), 0644))
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, 2, runs())
+
+	// output removed
+	require.NoError(t, os.Remove("gen.txt"))
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, 3, runs())
+	bts, err := ioutil.ReadFile("gen.txt")
+	require.NoError(t, err)
+	require.Equal(t, "v2", string(bts))
+}
+
+func TestRunWithoutInputs(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var out = filepath.Join(folder, "out")
+	var ctx = context.New(config.Project{
+		Generate: []config.Generator{
+			{Cmd: "touch " + out},
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.FileExists(t, out)
+	require.NoError(t, os.Remove(out))
+	// without inputs, it always runs
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.FileExists(t, out)
+}
+
+func TestRunQuotedArgs(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		Generate: []config.Generator{
+			{Cmd: `sh -c 'echo "$0" > out.txt' "foo bar"`},
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	bts, err := ioutil.ReadFile("out.txt")
+	require.NoError(t, err)
+	require.Equal(t, "foo bar\n", string(bts))
+}
+
+func TestFields(t *testing.T) {
+	for cmd, args := range map[string][]string{
+		"go generate ./...":        {"go", "generate", "./..."},
+		`protoc  --go_out="a b" x`: {"protoc", "--go_out=a b", "x"},
+		`sh -c 'echo "$FOO"'`:      {"sh", "-c", `echo "$FOO"`},
+		`touch foo\ bar ""`:        {"touch", "foo bar", ""},
+	} {
+		t.Run(cmd, func(t *testing.T) {
+			got, err := fields(cmd)
+			require.NoError(t, err)
+			require.Equal(t, args, got)
+		})
+	}
+	for _, cmd := range []string{"", "  ", `echo "foo`, `echo foo\`} {
+		_, err := fields(cmd)
+		require.Error(t, err, cmd)
+	}
+}
+
+func TestRunFails(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		Generate: []config.Generator{
+			{Cmd: "false"},
+		},
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), "generator failed: false\n")
+	_, err := os.Stat(cacheFile(ctx))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestRunInvalidTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Generate: []config.Generator{
+			{Cmd: "{{ .Nope }"},
+		},
+	})
+	require.Error(t, Pipe{}.Run(ctx))
+}
@@ -2,7 +2,9 @@ package git
 
 import (
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/git"
@@ -23,6 +25,9 @@ func (Pipe) Run(ctx *context.Context) error {
 	if _, err := exec.LookPath("git"); err != nil {
 		return ErrNoGit
 	}
+	if err := tagAndPush(ctx); err != nil {
+		return err
+	}
 	info, err := getInfo(ctx)
 	if err != nil {
 		return err
@@ -69,25 +74,35 @@ func getGitInfo() (context.GitInfo, error) {
 	if err != nil {
 		return context.GitInfo{}, errors.Wrap(err, "couldn't get current commit")
 	}
+	date, err := getCommitDate()
+	if err != nil {
+		return context.GitInfo{}, errors.Wrap(err, "couldn't get commit date")
+	}
 	url, err := getURL()
 	if err != nil {
 		return context.GitInfo{}, errors.Wrap(err, "couldn't get remote URL")
 	}
+	// the branch is only informative, and there is none on a detached HEAD
+	branch, _ := getBranch()
 	tag, err := getTag()
 	if err != nil {
 		return context.GitInfo{
 			Commit:      full,
 			FullCommit:  full,
 			ShortCommit: short,
+			CommitDate:  date,
 			URL:         url,
+			Branch:      branch,
 			CurrentTag:  "v0.0.0",
 		}, ErrNoTag
 	}
 	return context.GitInfo{
 		CurrentTag:  tag,
+		Branch:      branch,
 		Commit:      full,
 		FullCommit:  full,
 		ShortCommit: short,
+		CommitDate:  date,
 		URL:         url,
 	}, nil
 }
@@ -121,6 +136,29 @@ func getFullCommit() (string, error) {
 	return git.Clean(git.Run("show", "--format='%H'", "HEAD", "-q"))
 }
 
+func getCommitDate() (time.Time, error) {
+	ct, err := git.Clean(git.Run("show", "--format='%ct'", "HEAD", "-q"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if ct == "" {
+		return time.Time{}, nil
+	}
+	i, err := strconv.ParseInt(ct, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(i, 0).UTC(), nil
+}
+
+func getBranch() (string, error) {
+	branch, err := git.Clean(git.Run("rev-parse", "--abbrev-ref", "HEAD"))
+	if branch == "HEAD" {
+		return "", err
+	}
+	return branch, err
+}
+
 func getTag() (string, error) {
 	return git.Clean(git.Run("describe", "--tags", "--abbrev=0"))
 }
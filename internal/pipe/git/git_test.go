@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/goreleaser/goreleaser/internal/git"
 	"github.com/goreleaser/goreleaser/internal/testlib"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -132,6 +133,7 @@ func TestValidState(t *testing.T) {
 	assert.NoError(t, Pipe{}.Run(ctx))
 	assert.Equal(t, "v0.0.2", ctx.Git.CurrentTag)
 	assert.Equal(t, "git@github.com:foo/bar.git", ctx.Git.URL)
+	assert.False(t, ctx.Git.CommitDate.IsZero())
 }
 
 func TestSnapshotNoTags(t *testing.T) {
@@ -189,3 +191,74 @@ func TestGitNotInPath(t *testing.T) {
 	assert.NoError(t, os.Setenv("PATH", ""))
 	assert.EqualError(t, Pipe{}.Run(context.New(config.Project{})), ErrNoGit.Error())
 }
+
+func TestTagAndPush(t *testing.T) {
+	remote, err := ioutil.TempDir("", "goreleasertestremote")
+	assert.NoError(t, err)
+	_, err = git.Run("init", "--bare", remote)
+	assert.NoError(t, err)
+
+	_, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, remote)
+	testlib.GitCommit(t, "commit1")
+	_, err = git.Run("config", "user.name", "GoReleaser")
+	assert.NoError(t, err)
+	_, err = git.Run("config", "user.email", "test@goreleaser.github.com")
+	assert.NoError(t, err)
+
+	var ctx = context.New(config.Project{
+		Git: config.Git{
+			TagAndPush: config.TagAndPush{
+				Enabled: true,
+				Tag:     "v{{ .Env.NEXT_VERSION }}",
+			},
+		},
+	})
+	ctx.Env["NEXT_VERSION"] = "1.2.3"
+	assert.NoError(t, Pipe{}.Run(ctx))
+	assert.Equal(t, "v1.2.3", ctx.Git.CurrentTag)
+	assert.Equal(t, "1.2.3", ctx.Version)
+
+	out, err := git.Run("ls-remote", "--tags", "origin")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "refs/tags/v1.2.3")
+}
+
+func TestTagAndPushNoTemplate(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "git@github.com:foo/bar.git")
+	testlib.GitCommit(t, "commit1")
+	var ctx = context.New(config.Project{
+		Git: config.Git{
+			TagAndPush: config.TagAndPush{
+				Enabled: true,
+			},
+		},
+	})
+	assert.EqualError(t, Pipe{}.Run(ctx), ErrNoTagTemplate.Error())
+}
+
+func TestTagAndPushSnapshot(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "git@github.com:foo/bar.git")
+	testlib.GitCommit(t, "commit1")
+	var ctx = context.New(config.Project{
+		Git: config.Git{
+			TagAndPush: config.TagAndPush{
+				Enabled: true,
+				Tag:     "v1.0.0",
+			},
+		},
+	})
+	ctx.Snapshot = true
+	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+	out, err := git.Run("tag", "-l")
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+}
@@ -0,0 +1,55 @@
+package git
+
+import (
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoTagTemplate happens when git.tag_and_push is enabled but no tag
+// template was given.
+var ErrNoTagTemplate = errors.New("git.tag_and_push.tag is required when tag_and_push is enabled")
+
+// tagAndPush creates the release tag and pushes it to the configured remote,
+// so the rest of the pipeline can pick it up as the current tag.
+func tagAndPush(ctx *context.Context) error {
+	var cfg = ctx.Config.Git.TagAndPush
+	if !cfg.Enabled {
+		return nil
+	}
+	if ctx.Snapshot {
+		log.Warn("not creating a tag because this is a snapshot")
+		return nil
+	}
+	if cfg.Tag == "" {
+		return ErrNoTagTemplate
+	}
+	tag, err := tmpl.New(ctx).Apply(cfg.Tag)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply tag template")
+	}
+	var remote = cfg.Remote
+	if remote == "" {
+		remote = "origin"
+	}
+
+	var args = []string{"tag", "-a"}
+	if cfg.Sign {
+		args = []string{"tag", "-s"}
+	}
+	args = append(args, "-m", tag, tag)
+	log.WithField("tag", tag).Info("creating tag")
+	if _, err := git.Clean(git.Run(args...)); err != nil {
+		return errors.Wrapf(err, "failed to create tag %s", tag)
+	}
+	log.WithField("tag", tag).WithField("remote", remote).Info("pushing tag")
+	if _, err := git.Clean(git.RunWithAuth(
+		git.MergeAuth(ctx.Config.Git.Auth, cfg.Auth),
+		"push", remote, tag,
+	)); err != nil {
+		return errors.Wrapf(err, "failed to push tag %s to %s", tag, remote)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Package githubpackages provides a Pipe that publishes library artifacts,
+// like jars and npm tarballs, to GitHub Packages.
+package githubpackages
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	zglob "github.com/mattn/go-zglob"
+	"github.com/pkg/errors"
+)
+
+// ErrTokenTypeNotGitHub happens when GitHub Packages are configured but the
+// token is not a GitHub one
+var ErrTokenTypeNotGitHub = errors.New("github packages require a GITHUB_TOKEN")
+
+// nolint: gochecknoglobals
+var (
+	mavenURL = "https://maven.pkg.github.com"
+	npmURL   = "https://npm.pkg.github.com"
+)
+
+// Pipe for GitHub Packages
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "GitHub Packages"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.GitHubPackages {
+		var pkg = &ctx.Config.GitHubPackages[i]
+		if pkg.Type == "" {
+			pkg.Type = "maven"
+		}
+		if pkg.Type != "maven" && pkg.Type != "npm" {
+			return fmt.Errorf("invalid github package type: %s", pkg.Type)
+		}
+		if pkg.Repo.Name == "" {
+			pkg.Repo = ctx.Config.Release.GitHub
+		}
+		if pkg.Artifact == "" {
+			pkg.Artifact = ctx.Config.ProjectName
+		}
+		if pkg.Version == "" {
+			pkg.Version = "{{ .Version }}"
+		}
+		if pkg.Type == "maven" && pkg.Group == "" {
+			return fmt.Errorf("github maven package %s needs a group", pkg.Artifact)
+		}
+	}
+	return nil
+}
+
+// Publish the packages
+func (Pipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.GitHubPackages) == 0 {
+		return pipe.Skip("github_packages section is not configured")
+	}
+	if ctx.TokenType != context.TokenTypeGitHub {
+		return ErrTokenTypeNotGitHub
+	}
+	for _, pkg := range ctx.Config.GitHubPackages {
+		files, err := findFiles(ctx, pkg)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if err := publish(ctx, pkg, file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func findFiles(ctx *context.Context, pkg config.GitHubPackage) ([]string, error) {
+	var result []string
+	for _, glob := range pkg.Files {
+		glob, err := tmpl.New(ctx).Apply(glob)
+		if err != nil {
+			return nil, err
+		}
+		files, err := zglob.Glob(glob)
+		if err != nil {
+			return nil, errors.Wrapf(err, "globbing failed for pattern %s", glob)
+		}
+		result = append(result, files...)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no files matched for the %s package of %s", pkg.Type, pkg.Repo.String())
+	}
+	return result, nil
+}
+
+func publish(ctx *context.Context, pkg config.GitHubPackage, file string) error {
+	log.WithFields(log.Fields{
+		"type": pkg.Type,
+		"repo": pkg.Repo.String(),
+		"file": file,
+	}).Info("publishing")
+	if pkg.Type == "npm" {
+		return publishNpm(ctx, file)
+	}
+	return publishMaven(ctx, pkg, file)
+}
+
+// publishMaven uploads the file to the maven repository layout:
+// group/artifact/version/file
+func publishMaven(ctx *context.Context, pkg config.GitHubPackage, file string) error {
+	version, err := tmpl.New(ctx).Apply(pkg.Version)
+	if err != nil {
+		return err
+	}
+	var url = strings.Join([]string{
+		mavenURL,
+		pkg.Repo.Owner,
+		pkg.Repo.Name,
+		strings.ReplaceAll(pkg.Group, ".", "/"),
+		pkg.Artifact,
+		version,
+		filepath.Base(file),
+	}, "/")
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint: errcheck
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, url, f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.SetBasicAuth(pkg.Repo.Owner, ctx.Token)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "failed to upload %s", file)
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("failed to upload %s: unexpected http response status: %s", file, resp.Status)
+	}
+	return nil
+}
+
+// publishNpm publishes the tarball using the npm cli, authenticated through
+// a temporary npmrc so the token doesn't show up in the process list
+func publishNpm(ctx *context.Context, file string) error {
+	npmrc, err := ioutil.TempFile("", "goreleasernpmrc")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(npmrc.Name()) // nolint: errcheck
+	var host = strings.TrimPrefix(strings.TrimPrefix(npmURL, "https:"), "http:")
+	if _, err := fmt.Fprintf(npmrc, "%s/:_authToken=%s\n", host, ctx.Token); err != nil {
+		return err
+	}
+	if err := npmrc.Close(); err != nil {
+		return err
+	}
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "npm", "publish", file, "--registry", npmURL, "--userconfig", npmrc.Name())
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "failed to publish %s: %s", file, string(out))
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package githubpackages
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "goreleaser", Name: "foo"},
+		},
+		GitHubPackages: []config.GitHubPackage{{Group: "com.example"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.GitHubPackage{
+		Type:     "maven",
+		Group:    "com.example",
+		Repo:     config.Repo{Owner: "goreleaser", Name: "foo"},
+		Artifact: "foo",
+		Version:  "{{ .Version }}",
+	}, ctx.Config.GitHubPackages[0])
+}
+
+func TestDefaultInvalidType(t *testing.T) {
+	var ctx = context.New(config.Project{
+		GitHubPackages: []config.GitHubPackage{{Type: "pypi"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "invalid github package type: pypi")
+}
+
+func TestDefaultNoGroup(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName:    "foo",
+		GitHubPackages: []config.GitHubPackage{{}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "github maven package foo needs a group")
+
+	ctx = context.New(config.Project{
+		ProjectName:    "foo",
+		GitHubPackages: []config.GitHubPackage{{Type: "npm"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Publish(context.New(config.Project{})))
+}
+
+func TestNotGitHubToken(t *testing.T) {
+	var ctx = context.New(config.Project{
+		GitHubPackages: []config.GitHubPackage{{Type: "maven"}},
+	})
+	ctx.TokenType = context.TokenTypeGitLab
+	require.Equal(t, ErrTokenTypeNotGitHub, Pipe{}.Publish(ctx))
+}
+
+func TestNoFiles(t *testing.T) {
+	var ctx = context.New(config.Project{
+		GitHubPackages: []config.GitHubPackage{{
+			Type:  "maven",
+			Repo:  config.Repo{Owner: "goreleaser", Name: "foo"},
+			Files: []string{"nope/*.jar"},
+		}},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	require.EqualError(t, Pipe{}.Publish(ctx), "no files matched for the maven package of goreleaser/foo")
+}
+
+func TestPublishMaven(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "target"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "target", "foo-1.2.3.jar"), []byte("jar"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "target", "foo-1.2.3.pom"), []byte("pom"), 0644))
+
+	var uploads = map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		user, pass, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "goreleaser", user)
+		require.Equal(t, "secret", pass)
+		bts, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		uploads[r.URL.Path] = string(bts)
+	}))
+	defer srv.Close()
+	var previous = mavenURL
+	mavenURL = srv.URL
+	defer func() { mavenURL = previous }()
+
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		GitHubPackages: []config.GitHubPackage{{
+			Repo:  config.Repo{Owner: "goreleaser", Name: "foo"},
+			Group: "com.example",
+			Files: []string{"target/{{ .ProjectName }}-*"},
+		}},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Token = "secret"
+	ctx.Version = "1.2.3"
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.Equal(t, map[string]string{
+		"/goreleaser/foo/com/example/foo/1.2.3/foo-1.2.3.jar": "jar",
+		"/goreleaser/foo/com/example/foo/1.2.3/foo-1.2.3.pom": "pom",
+	}, uploads)
+}
+
+func TestPublishMavenFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "foo.jar"), []byte("jar"), 0644))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	var previous = mavenURL
+	mavenURL = srv.URL
+	defer func() { mavenURL = previous }()
+
+	var ctx = context.New(config.Project{
+		GitHubPackages: []config.GitHubPackage{{
+			Type:     "maven",
+			Repo:     config.Repo{Owner: "goreleaser", Name: "foo"},
+			Artifact: "foo",
+			Version:  "1.0.0",
+			Files:    []string{"foo.jar"},
+		}},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	require.EqualError(t, Pipe{}.Publish(ctx), "failed to upload foo.jar: unexpected http response status: 401 Unauthorized")
+}
+
+func TestPublishNpm(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "foo-1.0.0.tgz"), []byte("tgz"), 0644))
+	defer testlib.FakeTools(t, folder, map[string]string{
+		"npm": `echo "$1 $2 $3 $4" > ` + folder + `/args
+cat "$6" > ` + folder + `/npmrc
+`,
+	})()
+
+	var ctx = context.New(config.Project{
+		GitHubPackages: []config.GitHubPackage{{
+			Type:  "npm",
+			Repo:  config.Repo{Owner: "goreleaser", Name: "foo"},
+			Files: []string{"*.tgz"},
+		}},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Token = "secret"
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	args, err := ioutil.ReadFile(filepath.Join(folder, "args"))
+	require.NoError(t, err)
+	require.Equal(t, "publish foo-1.0.0.tgz --registry https://npm.pkg.github.com\n", string(args))
+	npmrc, err := ioutil.ReadFile(filepath.Join(folder, "npmrc"))
+	require.NoError(t, err)
+	require.Equal(t, "//npm.pkg.github.com/:_authToken=secret\n", string(npmrc))
+}
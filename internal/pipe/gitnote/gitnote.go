@@ -0,0 +1,144 @@
+// Package gitnote provides a Pipe that records the release, with its changelog
+// and the digests of its artifacts, as a git note on the released commit.
+package gitnote
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// Filename of the note inside the dist folder
+const Filename = "release-note.txt"
+
+// Pipe for git notes
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "git note"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	var note = &ctx.Config.Git.Note
+	if note.Ref == "" {
+		note.Ref = "refs/notes/releases"
+	}
+	if note.Remote == "" {
+		note.Remote = "origin"
+	}
+	return nil
+}
+
+// Publish adds the note to the released commit and pushes it
+func (Pipe) Publish(ctx *context.Context) error {
+	var cfg = ctx.Config.Git.Note
+	if !cfg.Enabled {
+		return pipe.Skip("git.note is not enabled")
+	}
+	content, err := note(ctx)
+	if err != nil {
+		return err
+	}
+	if cfg.Sign {
+		if content, err = sign(ctx, content); err != nil {
+			return err
+		}
+	}
+	var path = filepath.Join(ctx.Config.Dist, Filename)
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		return err
+	}
+
+	var auth = git.MergeAuth(ctx.Config.Git.Auth, cfg.Auth)
+	if err := fetch(auth, cfg.Ref, cfg.Remote); err != nil {
+		return err
+	}
+	log.WithField("ref", cfg.Ref).WithField("commit", ctx.Git.Commit).Info("adding git note")
+	if _, err := git.Clean(git.Run("notes", "--ref", cfg.Ref, "add", "-f", "-F", path, ctx.Git.Commit)); err != nil {
+		return errors.Wrapf(err, "failed to add git note to %s", ctx.Git.Commit)
+	}
+	log.WithField("ref", cfg.Ref).WithField("remote", cfg.Remote).Info("pushing git note")
+	if _, err := git.Clean(git.RunWithAuth(auth, "push", cfg.Remote, cfg.Ref)); err != nil {
+		return errors.Wrapf(err, "failed to push %s to %s", cfg.Ref, cfg.Remote)
+	}
+	return nil
+}
+
+// remoteRef is where the notes of the remote are fetched to, before being
+// merged into the local ones
+const remoteRef = "refs/notes/goreleaser-remote"
+
+// fetch merges the notes of the remote into the local ones, so pushing them
+// doesn't fail, nor overwrite the notes of the previous releases when the
+// local ones are missing or outdated.
+func fetch(auth config.GitAuth, ref, remote string) error {
+	log.WithField("ref", ref).WithField("remote", remote).Info("fetching git notes")
+	if _, err := git.Clean(git.RunWithAuth(auth, "fetch", remote, "+"+ref+":"+remoteRef)); err != nil {
+		if strings.Contains(err.Error(), "couldn't find remote ref") {
+			// first note
+			return nil
+		}
+		return errors.Wrapf(err, "failed to fetch %s from %s", ref, remote)
+	}
+	defer git.Run("update-ref", "-d", remoteRef) // nolint: errcheck
+	if _, err := git.Clean(git.Run("notes", "--ref", ref, "merge", "-s", "theirs", remoteRef)); err != nil {
+		return errors.Wrapf(err, "failed to merge the %s notes of %s", ref, remote)
+	}
+	return nil
+}
+
+// note returns the content of the note: the release, its changelog and the
+// sha256 of every released artifact.
+func note(ctx *context.Context) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Release %s of %s\n", ctx.Git.CurrentTag, ctx.Config.ProjectName)
+	if ctx.ReleaseURL != "" {
+		fmt.Fprintf(&b, "%s\n", ctx.ReleaseURL)
+	}
+	if notes := strings.TrimSpace(ctx.ReleaseNotes); notes != "" {
+		fmt.Fprintf(&b, "\n%s\n", notes)
+	}
+
+	var artifacts = ctx.Artifacts.Filter(artifact.ByUploadable()).List()
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].Name < artifacts[j].Name
+	})
+	if len(artifacts) > 0 {
+		fmt.Fprintf(&b, "\nArtifacts (sha256):\n")
+	}
+	for _, a := range artifacts {
+		sum, err := a.Checksum("sha256")
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "%s  %s\n", sum, a.Name)
+	}
+	return b.Bytes(), nil
+}
+
+// sign clearsigns the note with the default GPG key.
+func sign(ctx *context.Context, content []byte) ([]byte, error) {
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "gpg", "--batch", "--clearsign")
+	cmd.Stdin = bytes.NewReader(content)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to sign git note: \n%s", stderr.String())
+	}
+	return out, nil
+}
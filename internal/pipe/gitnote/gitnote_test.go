@@ -0,0 +1,164 @@
+package gitnote
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "refs/notes/releases", ctx.Config.Git.Note.Ref)
+	require.Equal(t, "origin", ctx.Config.Git.Note.Remote)
+}
+
+func TestSkipNotEnabled(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Publish(context.New(config.Project{})))
+}
+
+func TestNote(t *testing.T) {
+	var folder, err = ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	var ctx = context.New(config.Project{ProjectName: "foo"})
+	ctx.Git.CurrentTag = "v1.2.3"
+	ctx.ReleaseURL = "https://github.com/goreleaser/foo/releases/tag/v1.2.3"
+	ctx.ReleaseNotes = "## Changelog\n\nabc123 fix things\n"
+	for name, typ := range map[string]artifact.Type{
+		"foo.tar.gz":    artifact.UploadableArchive,
+		"checksums.txt": artifact.Checksum,
+		"foo":           artifact.Binary,
+	} {
+		var path = filepath.Join(folder, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(name), 0644))
+		ctx.Artifacts.Add(&artifact.Artifact{Name: name, Path: path, Type: typ})
+	}
+	content, err := note(ctx)
+	require.NoError(t, err)
+	require.Equal(t, `Release v1.2.3 of foo
+https://github.com/goreleaser/foo/releases/tag/v1.2.3
+
+## Changelog
+
+abc123 fix things
+
+Artifacts (sha256):
+092ed35ce184329ae3ccf786a43135951d8af11dc3a9bd313435f757626b3527  checksums.txt
+cf051bf611a94884ba5e4c2d03932d14e83875c5b77f0fdf55c404cad0e4a6e6  foo.tar.gz
+`, string(content))
+}
+
+func TestPublish(t *testing.T) {
+	var remote, err = ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	_, err = git.Run("init", "--bare", remote)
+	require.NoError(t, err)
+
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, remote)
+	testlib.GitCommit(t, "first")
+	commit, err := git.Clean(git.Run("rev-parse", "HEAD"))
+	require.NoError(t, err)
+	for _, env := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		require.NoError(t, os.Setenv(env, "test@goreleaser.com"))
+		defer os.Unsetenv(env) // nolint: errcheck
+	}
+
+	// fake gpg that wraps its input
+	defer testlib.FakeTools(t, folder, map[string]string{
+		"gpg": "echo BEGIN SIGNED\ncat\necho END SIGNED\n",
+	})()
+
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        folder,
+		Git: config.Git{
+			Note: config.GitNote{Enabled: true, Sign: true},
+		},
+	})
+	ctx.Git.CurrentTag = "v1.2.3"
+	ctx.Git.Commit = commit
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	out, err := git.Run("notes", "--ref", "refs/notes/releases", "show", commit)
+	require.NoError(t, err)
+	require.Equal(t, "BEGIN SIGNED\nRelease v1.2.3 of foo\nEND SIGNED\n", out)
+	out, err = git.Run("--git-dir", remote, "notes", "--ref", "refs/notes/releases", "show", commit)
+	require.NoError(t, err)
+	require.Contains(t, out, "Release v1.2.3 of foo")
+}
+
+func TestPublishMergesRemoteNotes(t *testing.T) {
+	var remote, err = ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	_, err = git.Run("init", "--bare", remote)
+	require.NoError(t, err)
+	for _, env := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		require.NoError(t, os.Setenv(env, "test@goreleaser.com"))
+		defer os.Unsetenv(env) // nolint: errcheck
+	}
+
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, remote)
+	testlib.GitCommit(t, "first")
+	first, err := git.Clean(git.Run("rev-parse", "HEAD"))
+	require.NoError(t, err)
+	// the note of a previous release, pushed from somewhere else
+	_, err = git.Run("notes", "--ref", "refs/notes/releases", "add", "-m", "Release v1.2.2 of foo", first)
+	require.NoError(t, err)
+	_, err = git.Run("push", "origin", "refs/notes/releases")
+	require.NoError(t, err)
+	_, err = git.Run("update-ref", "-d", "refs/notes/releases")
+	require.NoError(t, err)
+	testlib.GitCommit(t, "second")
+	second, err := git.Clean(git.Run("rev-parse", "HEAD"))
+	require.NoError(t, err)
+
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        folder,
+		Git: config.Git{
+			Note: config.GitNote{Enabled: true},
+		},
+	})
+	ctx.Git.CurrentTag = "v1.2.3"
+	ctx.Git.Commit = second
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	out, err := git.Run("--git-dir", remote, "notes", "--ref", "refs/notes/releases", "show", first)
+	require.NoError(t, err)
+	require.Equal(t, "Release v1.2.2 of foo\n", out)
+	out, err = git.Run("--git-dir", remote, "notes", "--ref", "refs/notes/releases", "show", second)
+	require.NoError(t, err)
+	require.Equal(t, "Release v1.2.3 of foo\n", out)
+	_, err = git.Run("rev-parse", "--verify", "refs/notes/goreleaser-remote")
+	require.Error(t, err)
+}
+
+func TestPublishSignFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, os.Setenv("GNUPGHOME", folder))
+	defer os.Unsetenv("GNUPGHOME") // nolint: errcheck
+	var ctx = context.New(config.Project{
+		Dist: folder,
+		Git: config.Git{
+			Note: config.GitNote{Enabled: true, Sign: true},
+		},
+	})
+	require.Error(t, Pipe{}.Publish(ctx))
+}
@@ -0,0 +1,251 @@
+// Package krew provides a Pipe that generates the krew plugin manifest of a
+// kubectl plugin and proposes it to the krew index through a pull request.
+package krew
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoArchivesFound happens when there are no archives to install
+var ErrNoArchivesFound = errors.New("no linux, macos or windows archives found")
+
+// ErrNoPullRequests happens when the client can't open pull requests
+var ErrNoPullRequests = errors.New("krew is only supported on GitHub")
+
+// Pipe for krew manifests
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "krew plugin manifests"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Krews {
+		var krew = &ctx.Config.Krews[i]
+		if krew.Name == "" {
+			krew.Name = strings.TrimPrefix(ctx.Config.ProjectName, "kubectl-")
+		}
+		if krew.ShortDescription == "" {
+			krew.ShortDescription = ctx.Config.Metadata.Description
+		}
+		if krew.ShortDescription == "" {
+			return fmt.Errorf("krew %s: short_description is required", krew.Name)
+		}
+		if krew.Homepage == "" {
+			krew.Homepage = ctx.Config.Metadata.Homepage
+		}
+		if krew.Goarm == "" {
+			krew.Goarm = "6"
+		}
+		if krew.Base.Name == "" {
+			krew.Base = config.Repo{Owner: "kubernetes-sigs", Name: "krew-index"}
+		}
+		if krew.Branch == "" {
+			krew.Branch = krew.Name + "-{{ .Version }}"
+		}
+		if krew.CommitAuthor.Name == "" {
+			krew.CommitAuthor.Name = "goreleaserbot"
+		}
+		if krew.CommitAuthor.Email == "" {
+			krew.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+		}
+	}
+	return nil
+}
+
+// Publish the krew manifests
+func (Pipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.Krews) == 0 {
+		return pipe.Skip("krew section is not configured")
+	}
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	for _, krew := range ctx.Config.Krews {
+		ok, err := condition.Check(ctx, krew.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("krew", krew.Name).Info("skipped because its condition is false")
+			continue
+		}
+		if err := doRun(ctx, krew, cli); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, krew config.Krew, cli client.Client) error {
+	var filters = []artifact.Filter{
+		artifact.Or(
+			artifact.ByGoos("linux"),
+			artifact.ByGoos("darwin"),
+			artifact.ByGoos("windows"),
+		),
+		artifact.ByFormats("zip", "tar.gz"),
+		artifact.Or(
+			artifact.ByGoarch("amd64"),
+			artifact.ByGoarch("arm64"),
+			artifact.ByGoarch("386"),
+			artifact.And(
+				artifact.ByGoarch("arm"),
+				artifact.ByGoarm(krew.Goarm),
+			),
+		),
+		artifact.ByType(artifact.UploadableArchive),
+	}
+	if len(krew.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(krew.IDs...))
+	}
+	var archives = ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(archives) == 0 {
+		return ErrNoArchivesFound
+	}
+
+	content, err := buildManifest(ctx, krew, archives)
+	if err != nil {
+		return err
+	}
+	var dir = filepath.Join(ctx.Config.Dist, "krew")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var filename = krew.Name + ".yaml"
+	log.WithField("manifest", filepath.Join(dir, filename)).Info("writing")
+	if err := ioutil.WriteFile(filepath.Join(dir, filename), content, 0644); err != nil {
+		return err
+	}
+
+	if krew.Repository.Name == "" {
+		return pipe.Skip("krew.repository is not set")
+	}
+	if strings.TrimSpace(krew.SkipUpload) == "true" {
+		return pipe.Skip("krew.skip_upload is set")
+	}
+	if ctx.SkipPublish {
+		return pipe.ErrSkipPublishEnabled
+	}
+	if ctx.Config.Release.Draft {
+		return pipe.Skip("release is marked as draft")
+	}
+	if ctx.Config.Release.Disable {
+		return pipe.Skip("release is disabled")
+	}
+	if strings.TrimSpace(krew.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping krew publish")
+	}
+
+	pr, ok := cli.(client.PullRequester)
+	if !ok {
+		return ErrNoPullRequests
+	}
+	branch, err := tmpl.New(ctx).Apply(krew.Branch)
+	if err != nil {
+		return errors.Wrap(err, "failed to template the krew branch")
+	}
+	var files = map[string][]byte{
+		path.Join("plugins", filename): content,
+	}
+	var title = fmt.Sprintf("New version: %s v%s", krew.Name, ctx.Version)
+	url, err := pr.OpenPullRequest(ctx, krew.CommitAuthor, krew.Repository, krew.Base, files, branch, title, title)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open krew pull request on %s", krew.Base)
+	}
+	log.WithField("url", url).Info("opened pull request")
+	return nil
+}
+
+func buildManifest(ctx *context.Context, krew config.Krew, archives []*artifact.Artifact) ([]byte, error) {
+	if krew.URLTemplate == "" {
+		urlTemplate, err := client.DownloadURLTemplate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		krew.URLTemplate = urlTemplate
+	}
+
+	var manifest = Manifest{
+		APIVersion: apiVersion,
+		Kind:       "Plugin",
+		Metadata:   Metadata{Name: krew.Name},
+		Spec: Spec{
+			Version:          "v" + ctx.Version,
+			Homepage:         krew.Homepage,
+			ShortDescription: krew.ShortDescription,
+			Description:      krew.Description,
+			Caveats:          krew.Caveats,
+		},
+	}
+	var platforms = map[string]bool{}
+	for _, archive := range archives {
+		var platform = archive.Goos + "/" + archive.Goarch
+		if platforms[platform] {
+			return nil, fmt.Errorf("krew %s: found multiple %s archives, use ids to pick one", krew.Name, platform)
+		}
+		platforms[platform] = true
+		url, err := tmpl.New(ctx).WithArtifact(archive, map[string]string{}).Apply(krew.URLTemplate)
+		if err != nil {
+			return nil, err
+		}
+		sum, err := archive.Checksum("sha256")
+		if err != nil {
+			return nil, err
+		}
+		manifest.Spec.Platforms = append(manifest.Spec.Platforms, platformFor(krew, archive, url, sum))
+	}
+	sort.Slice(manifest.Spec.Platforms, func(i, j int) bool {
+		var a, b = manifest.Spec.Platforms[i].Selector.MatchLabels, manifest.Spec.Platforms[j].Selector.MatchLabels
+		if a.OS != b.OS {
+			return a.OS < b.OS
+		}
+		return a.Arch < b.Arch
+	})
+	return manifest.Bytes()
+}
+
+// platformFor returns the platform of the given archive, copying its
+// binaries and license to the plugin folder
+func platformFor(krew config.Krew, archive *artifact.Artifact, url, sum string) Platform {
+	var ext string
+	if archive.Goos == "windows" {
+		ext = ".exe"
+	}
+	var wrap = archive.ExtraOr("WrappedIn", "").(string)
+	var platform = Platform{
+		Selector: Selector{MatchLabels: MatchLabels{OS: archive.Goos, Arch: archive.Goarch}},
+		URI:      url,
+		Sha256:   sum,
+	}
+	for _, binary := range archive.ExtraOr("Builds", []*artifact.Artifact{}).([]*artifact.Artifact) {
+		var name = binary.ExtraOr("Binary", "").(string)
+		platform.Files = append(platform.Files, File{From: path.Join(wrap, name+ext), To: "."})
+		// the plugin binary is named after it, falling back to the first one
+		if platform.Bin == "" || name == "kubectl-"+krew.Name {
+			platform.Bin = name + ext
+		}
+	}
+	if krew.License != "" {
+		platform.Files = append(platform.Files, File{From: path.Join(wrap, krew.License), To: "."})
+	}
+	return platform
+}
@@ -0,0 +1,304 @@
+package krew
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update .golden files")
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "kubectl-foo",
+		Metadata: config.Metadata{
+			Description: "A foo",
+			Homepage:    "https://example.com",
+		},
+		Krews: []config.Krew{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Krew{
+		Name:             "foo",
+		Goarm:            "6",
+		ShortDescription: "A foo",
+		Homepage:         "https://example.com",
+		Base:             config.Repo{Owner: "kubernetes-sigs", Name: "krew-index"},
+		Branch:           "foo-{{ .Version }}",
+		CommitAuthor: config.CommitAuthor{
+			Name:  "goreleaserbot",
+			Email: "goreleaser@carlosbecker.com",
+		},
+	}, ctx.Config.Krews[0])
+}
+
+func TestDefaultNoShortDescription(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Krews:       []config.Krew{{}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "krew foo: short_description is required")
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Publish(context.New(config.Project{})))
+}
+
+func krewContext(t *testing.T, folder string, krew config.Krew) *context.Context {
+	var ctx = context.New(config.Project{
+		ProjectName: "kubectl-foo",
+		Dist:        folder,
+		Metadata:    config.Metadata{Description: "A foo"},
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "foo", Name: "bar"},
+			GitLab: config.Repo{Owner: "foo", Name: "bar"},
+		},
+		GitHubURLs: config.GitHubURLs{Download: "https://github.com"},
+		GitLabURLs: config.GitLabURLs{Download: "https://gitlab.com"},
+		Krews:      []config.Krew{krew},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	for _, archive := range []struct {
+		goos, goarch, goarm, format, id string
+	}{
+		{"linux", "amd64", "", "tar.gz", "default"},
+		{"linux", "arm64", "", "tar.gz", "default"},
+		{"linux", "arm", "6", "tar.gz", "default"},
+		{"linux", "arm", "7", "tar.gz", "default"},
+		{"darwin", "amd64", "", "tar.gz", "default"},
+		{"windows", "amd64", "", "zip", "default"},
+		{"windows", "amd64", "", "tar.gz", "other"},
+		{"freebsd", "amd64", "", "tar.gz", "default"},
+		{"linux", "amd64", "", "tar.gz", "other"},
+	} {
+		var name = "kubectl-foo_" + archive.goos + "_" + archive.goarch + archive.goarm + "_" + archive.id + "." + archive.format
+		var path = filepath.Join(folder, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(name), 0644))
+		var extra = map[string]interface{}{
+			"ID":     archive.id,
+			"Format": archive.format,
+			"Builds": []*artifact.Artifact{
+				{Extra: map[string]interface{}{"Binary": "foo-helper"}},
+				{Extra: map[string]interface{}{"Binary": "kubectl-foo"}},
+			},
+		}
+		if archive.id == "other" {
+			extra["WrappedIn"] = "kubectl-foo_1.0.0"
+		}
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type:   artifact.UploadableArchive,
+			Name:   name,
+			Path:   path,
+			Goos:   archive.goos,
+			Goarch: archive.goarch,
+			Goarm:  archive.goarm,
+			Extra:  extra,
+		})
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestRunPipe(t *testing.T) {
+	for name, tt := range map[string]struct {
+		krew   config.Krew
+		gitlab bool
+	}{
+		"default": {
+			krew: config.Krew{
+				IDs:        []string{"default"},
+				Repository: config.Repo{Owner: "foo", Name: "krew-index"},
+			},
+		},
+		"full": {
+			krew: config.Krew{
+				IDs:              []string{"default"},
+				Goarm:            "7",
+				ShortDescription: "Foo the cluster",
+				Description:      "Foo the cluster,\nthe whole cluster.",
+				Homepage:         "https://example.com",
+				Caveats:          "Requires cluster-admin",
+				License:          "LICENSE",
+				Repository:       config.Repo{Owner: "foo", Name: "krew-index"},
+			},
+		},
+		"gitlab": {
+			krew: config.Krew{
+				IDs:        []string{"default"},
+				Repository: config.Repo{Owner: "foo", Name: "krew-index"},
+			},
+			gitlab: true,
+		},
+		"url_template": {
+			krew: config.Krew{
+				IDs:         []string{"other"},
+				License:     "LICENSE",
+				URLTemplate: "https://dl.example.com/{{ .Tag }}/{{ .ArtifactName }}",
+				Repository:  config.Repo{Owner: "foo", Name: "krew-index"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder, err := ioutil.TempDir("", "krewtest")
+			require.NoError(t, err)
+			var ctx = krewContext(t, folder, tt.krew)
+			if tt.gitlab {
+				ctx.TokenType = context.TokenTypeGitLab
+				for _, a := range ctx.Artifacts.List() {
+					a.Extra["ArtifactUploadHash"] = "820ead5d9d2266c728dce6d4d55b6460"
+				}
+			}
+			var cli = &fakeClient{}
+			var krew = ctx.Config.Krews[0]
+			require.NoError(t, doRun(ctx, krew, cli))
+
+			require.Equal(t, krew.Repository, cli.repo)
+			require.Equal(t, config.Repo{Owner: "kubernetes-sigs", Name: "krew-index"}, cli.base)
+			require.Equal(t, "foo-1.0.0", cli.branch)
+			require.Equal(t, "New version: foo v1.0.0", cli.title)
+			require.Len(t, cli.files, 1)
+
+			var content = cli.files["plugins/foo.yaml"]
+			var golden = "testdata/" + name + ".yaml.golden"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(golden, content, 0655))
+			}
+			bts, err := ioutil.ReadFile(golden)
+			require.NoError(t, err)
+			require.Equal(t, string(bts), string(content))
+
+			dist, err := ioutil.ReadFile(filepath.Join(folder, "krew", "foo.yaml"))
+			require.NoError(t, err)
+			require.Equal(t, string(bts), string(dist))
+		})
+	}
+}
+
+func TestRunPipeNoArchives(t *testing.T) {
+	folder, err := ioutil.TempDir("", "krewtest")
+	require.NoError(t, err)
+	var ctx = krewContext(t, folder, config.Krew{
+		IDs:        []string{"nope"},
+		Repository: config.Repo{Owner: "foo", Name: "krew-index"},
+	})
+	require.Equal(t, ErrNoArchivesFound, doRun(ctx, ctx.Config.Krews[0], &fakeClient{}))
+}
+
+func TestRunPipeMultipleArchives(t *testing.T) {
+	folder, err := ioutil.TempDir("", "krewtest")
+	require.NoError(t, err)
+	var ctx = krewContext(t, folder, config.Krew{
+		Repository: config.Repo{Owner: "foo", Name: "krew-index"},
+	})
+	require.EqualError(
+		t,
+		doRun(ctx, ctx.Config.Krews[0], &fakeClient{}),
+		"krew foo: found multiple windows/amd64 archives, use ids to pick one",
+	)
+}
+
+func TestRunPipeTokenTypeNotImplemented(t *testing.T) {
+	folder, err := ioutil.TempDir("", "krewtest")
+	require.NoError(t, err)
+	var ctx = krewContext(t, folder, config.Krew{
+		IDs:        []string{"default"},
+		Repository: config.Repo{Owner: "foo", Name: "krew-index"},
+	})
+	ctx.TokenType = ""
+	require.Equal(t, client.ErrTokenTypeNotImplemented, doRun(ctx, ctx.Config.Krews[0], &fakeClient{}))
+}
+
+func TestRunPipeNoPullRequests(t *testing.T) {
+	folder, err := ioutil.TempDir("", "krewtest")
+	require.NoError(t, err)
+	var ctx = krewContext(t, folder, config.Krew{
+		IDs:        []string{"default"},
+		Repository: config.Repo{Owner: "foo", Name: "krew-index"},
+	})
+	require.Equal(t, ErrNoPullRequests, doRun(ctx, ctx.Config.Krews[0], &basicClient{}))
+}
+
+func TestRunPipeSkipPublish(t *testing.T) {
+	for name, setup := range map[string]func(ctx *context.Context){
+		"no_repository": func(ctx *context.Context) {
+			ctx.Config.Krews[0].Repository = config.Repo{}
+		},
+		"skip_upload": func(ctx *context.Context) {
+			ctx.Config.Krews[0].SkipUpload = "true"
+		},
+		"auto": func(ctx *context.Context) {
+			ctx.Config.Krews[0].SkipUpload = "auto"
+			ctx.Semver.Prerelease = "beta1"
+		},
+		"skip_publish": func(ctx *context.Context) {
+			ctx.SkipPublish = true
+		},
+		"draft": func(ctx *context.Context) {
+			ctx.Config.Release.Draft = true
+		},
+		"disabled": func(ctx *context.Context) {
+			ctx.Config.Release.Disable = true
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder, err := ioutil.TempDir("", "krewtest")
+			require.NoError(t, err)
+			var ctx = krewContext(t, folder, config.Krew{
+				IDs:        []string{"default"},
+				Repository: config.Repo{Owner: "foo", Name: "krew-index"},
+			})
+			setup(ctx)
+			var cli = &fakeClient{}
+			testlib.AssertSkipped(t, doRun(ctx, ctx.Config.Krews[0], cli))
+			require.Nil(t, cli.files)
+			require.FileExists(t, filepath.Join(folder, "krew", "foo.yaml"))
+		})
+	}
+}
+
+type basicClient struct{}
+
+func (*basicClient) CreateRelease(ctx *context.Context, body string) (string, error) {
+	return "", nil
+}
+
+func (*basicClient) CreateFile(ctx *context.Context, commitAuthor config.CommitAuthor, repo config.Repo, content []byte, path, message string) error {
+	return nil
+}
+
+func (*basicClient) Upload(ctx *context.Context, releaseID string, artifact *artifact.Artifact, file *os.File) error {
+	return nil
+}
+
+type fakeClient struct {
+	basicClient
+	repo   config.Repo
+	base   config.Repo
+	files  map[string][]byte
+	branch string
+	title  string
+}
+
+func (c *fakeClient) GetFile(ctx *context.Context, repo config.Repo, path string) ([]byte, error) {
+	return nil, os.ErrNotExist
+}
+
+func (c *fakeClient) OpenPullRequest(ctx *context.Context, commitAuthor config.CommitAuthor, repo, base config.Repo, files map[string][]byte, branch, title, message string) (string, error) {
+	c.repo = repo
+	c.base = base
+	c.files = files
+	c.branch = branch
+	c.title = title
+	return "https://github.com/kubernetes-sigs/krew-index/pull/1", nil
+}
@@ -0,0 +1,71 @@
+package krew
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v2"
+)
+
+const apiVersion = "krew.googlecontainertools.github.com/v1alpha2"
+
+// Manifest is the krew plugin manifest, more info:
+// https://krew.sigs.k8s.io/docs/developer-guide/plugin-manifest/
+type Manifest struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Metadata   Metadata `yaml:"metadata"`
+	Spec       Spec     `yaml:"spec"`
+}
+
+// Metadata of the plugin
+type Metadata struct {
+	Name string `yaml:"name"`
+}
+
+// Spec of the plugin
+type Spec struct {
+	Version          string     `yaml:"version"`
+	Homepage         string     `yaml:"homepage,omitempty"`
+	ShortDescription string     `yaml:"shortDescription"`
+	Description      string     `yaml:"description,omitempty"`
+	Caveats          string     `yaml:"caveats,omitempty"`
+	Platforms        []Platform `yaml:"platforms"`
+}
+
+// Platform is the archive installed on a given os and arch
+type Platform struct {
+	Selector Selector `yaml:"selector"`
+	URI      string   `yaml:"uri"`
+	Sha256   string   `yaml:"sha256"`
+	Files    []File   `yaml:"files"`
+	Bin      string   `yaml:"bin"`
+}
+
+// Selector matches the os and arch of the platform
+type Selector struct {
+	MatchLabels MatchLabels `yaml:"matchLabels"`
+}
+
+// MatchLabels are the os and arch labels of the platform
+type MatchLabels struct {
+	OS   string `yaml:"os"`
+	Arch string `yaml:"arch"`
+}
+
+// File is a file of the archive copied to the plugin folder
+type File struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// Bytes returns the manifest as YAML, with a header
+func (m Manifest) Bytes() ([]byte, error) {
+	bts, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.WriteString("# This file was generated by GoReleaser. DO NOT EDIT.\n")
+	out.Write(bts)
+	return out.Bytes(), nil
+}
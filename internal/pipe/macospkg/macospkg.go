@@ -0,0 +1,210 @@
+// Package macospkg implements the Pipe interface building macOS installer
+// packages with pkgbuild and productbuild.
+package macospkg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoPkgbuild is shown when pkgbuild or productbuild cannot be found in
+// $PATH
+var ErrNoPkgbuild = errors.New("pkgbuild and productbuild not present in $PATH")
+
+const (
+	defaultNameTemplate    = "{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}"
+	defaultInstallLocation = "/usr/local/bin"
+)
+
+// Pipe for macOS installer packages
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "macos installer packages"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	var ids = ids.New("macos_pkgs")
+	for i := range ctx.Config.MacOSPkgs {
+		var pkg = &ctx.Config.MacOSPkgs[i]
+		if pkg.ID == "" {
+			pkg.ID = "default"
+		}
+		if pkg.Identifier == "" {
+			return fmt.Errorf("macos_pkg %s: identifier is required", pkg.ID)
+		}
+		if pkg.NameTemplate == "" {
+			pkg.NameTemplate = defaultNameTemplate
+		}
+		if len(pkg.Builds) == 0 {
+			for _, b := range ctx.Config.Builds {
+				pkg.Builds = append(pkg.Builds, b.ID)
+			}
+		}
+		if pkg.InstallLocation == "" {
+			pkg.InstallLocation = defaultInstallLocation
+		}
+		ids.Inc(pkg.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.MacOSPkgs) == 0 {
+		return pipe.Skip("macos_pkgs section is not configured")
+	}
+	for _, tool := range []string{"pkgbuild", "productbuild"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return ErrNoPkgbuild
+		}
+	}
+	for _, pkg := range ctx.Config.MacOSPkgs {
+		ok, err := condition.Check(ctx, pkg.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("macos_pkg", pkg.ID).Info("skipped because its condition is false")
+			continue
+		}
+		if err := doRun(ctx, pkg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, pkg config.MacOSPkg) error {
+	var darwinBinaries = ctx.Artifacts.Filter(artifact.And(
+		artifact.ByGoos("darwin"),
+		artifact.ByType(artifact.Binary),
+		artifact.ByIDs(pkg.Builds...),
+	)).GroupByPlatform()
+	if len(darwinBinaries) == 0 {
+		return fmt.Errorf("no darwin binaries found for macos_pkg %s", pkg.ID)
+	}
+	var g = semerrgroup.New(ctx.Parallelism)
+	for _, binaries := range darwinBinaries {
+		binaries := binaries
+		g.Go(func() error {
+			return create(ctx, pkg, binaries)
+		})
+	}
+	return g.Wait()
+}
+
+func create(ctx *context.Context, pkg config.MacOSPkg, binaries []*artifact.Artifact) error {
+	var template = tmpl.New(ctx).WithArtifact(binaries[0], map[string]string{})
+	name, err := template.Apply(pkg.NameTemplate)
+	if err != nil {
+		return err
+	}
+	identity, err := template.Apply(pkg.SigningIdentity)
+	if err != nil {
+		return err
+	}
+	var log = log.WithField("pkg", name)
+
+	// pkgbuild installs the whole root folder in the install location
+	var dir = filepath.Join(ctx.Config.Dist, name)
+	var root = filepath.Join(dir, "root")
+	var sources []string
+	for _, binary := range binaries {
+		sources = append(sources, binary.Path)
+	}
+	sources = append(sources, pkg.ExtraFiles...)
+	for _, src := range sources {
+		if err := copyFile(src, filepath.Join(root, filepath.Base(src))); err != nil {
+			return errors.Wrapf(err, "failed to copy %s", src)
+		}
+	}
+
+	var component = filepath.Join(dir, name+"-component.pkg")
+	var args = []string{
+		"--root", root,
+		"--identifier", pkg.Identifier,
+		"--version", ctx.Version,
+		"--install-location", pkg.InstallLocation,
+	}
+	if pkg.Scripts != "" {
+		args = append(args, "--scripts", pkg.Scripts)
+	}
+	if err := run(ctx, "pkgbuild", append(args, component)...); err != nil {
+		return err
+	}
+
+	var path = filepath.Join(ctx.Config.Dist, name+".pkg")
+	args = []string{"--package", component}
+	switch {
+	case identity == "":
+	case ctx.SkipSign:
+		log.Warn("skipped signing because of --skip-sign")
+	default:
+		args = append(args, "--sign", identity)
+	}
+	log.WithField("file", path).Info("creating")
+	if err := run(ctx, "productbuild", append(args, path)...); err != nil {
+		return err
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   artifact.MacOSPackage,
+		Name:   name + ".pkg",
+		Path:   path,
+		Goos:   binaries[0].Goos,
+		Goarch: binaries[0].Goarch,
+		Extra: map[string]interface{}{
+			"ID": pkg.ID,
+		},
+	})
+	return nil
+}
+
+func run(ctx *context.Context, tool string, args ...string) error {
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, tool, args...)
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to run %s: %s", tool, string(out))
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src) // #nosec
+	if err != nil {
+		return err
+	}
+	defer in.Close() // nolint: errcheck
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
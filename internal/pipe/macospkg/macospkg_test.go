@@ -0,0 +1,214 @@
+package macospkg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Builds:      []config.Build{{ID: "foo"}},
+		MacOSPkgs:   []config.MacOSPkg{{Identifier: "com.example.foo"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.MacOSPkg{
+		ID:              "default",
+		Builds:          []string{"foo"},
+		NameTemplate:    defaultNameTemplate,
+		Identifier:      "com.example.foo",
+		InstallLocation: "/usr/local/bin",
+	}, ctx.Config.MacOSPkgs[0])
+}
+
+func TestDefaultNoIdentifier(t *testing.T) {
+	var ctx = context.New(config.Project{
+		MacOSPkgs: []config.MacOSPkg{{ID: "foo"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "macos_pkg foo: identifier is required")
+}
+
+func TestDefaultDuplicatedID(t *testing.T) {
+	var ctx = context.New(config.Project{
+		MacOSPkgs: []config.MacOSPkg{
+			{ID: "a", Identifier: "com.example.foo"},
+			{ID: "a", Identifier: "com.example.foo"},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 macos_pkgs with the ID 'a', please fix your config")
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+// fakeTools puts a fake pkgbuild and productbuild in the PATH, which log
+// their arguments and create the package given as their last argument,
+// returning a func that restores the PATH.
+func fakeTools(t *testing.T, folder, log string) func() {
+	var tools = map[string]string{}
+	for _, tool := range []string{"pkgbuild", "productbuild"} {
+		tools[tool] = `echo "` + tool + ` $@" >> ` + log + `
+for last; do :; done
+echo ` + tool + ` > "$last"
+`
+	}
+	return testlib.FakeTools(t, folder, tools)
+}
+
+func pkgContext(t *testing.T, folder string, pkg config.MacOSPkg) *context.Context {
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0755))
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		Builds:      []config.Build{{ID: "foo"}},
+		MacOSPkgs:   []config.MacOSPkg{pkg},
+	})
+	ctx.Version = "1.2.3"
+	ctx.Git.CurrentTag = "v1.2.3"
+	for _, platform := range []struct{ goos, goarch string }{
+		{"darwin", "amd64"},
+		{"darwin", "arm64"},
+		{"linux", "amd64"},
+	} {
+		var path = filepath.Join(dist, platform.goos+platform.goarch, "foo")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte("foo"), 0755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "foo",
+			Path:   path,
+			Goos:   platform.goos,
+			Goarch: platform.goarch,
+			Type:   artifact.Binary,
+			Extra:  map[string]interface{}{"ID": "foo"},
+		})
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestRunPipe(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	require.NoError(t, ioutil.WriteFile("LICENSE.txt", []byte("MIT"), 0644))
+	var ctx = pkgContext(t, folder, config.MacOSPkg{
+		Identifier: "com.example.foo",
+		Scripts:    "scripts",
+		ExtraFiles: []string{"LICENSE.txt"},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var pkgs = ctx.Artifacts.Filter(artifact.ByType(artifact.MacOSPackage)).List()
+	require.Len(t, pkgs, 2)
+	var names []string
+	for _, pkg := range pkgs {
+		names = append(names, pkg.Name)
+		require.FileExists(t, pkg.Path)
+		require.Equal(t, "default", pkg.ExtraOr("ID", ""))
+		require.Equal(t, "darwin", pkg.Goos)
+	}
+	require.ElementsMatch(t, []string{"foo_1.2.3_darwin_amd64.pkg", "foo_1.2.3_darwin_arm64.pkg"}, names)
+
+	var dir = filepath.Join(ctx.Config.Dist, "foo_1.2.3_darwin_amd64")
+	for _, file := range []string{"foo", "LICENSE.txt"} {
+		require.FileExists(t, filepath.Join(dir, "root", file))
+	}
+	info, err := os.Stat(filepath.Join(dir, "root", "foo"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0755), info.Mode())
+
+	bts, err := ioutil.ReadFile(log)
+	require.NoError(t, err)
+	var component = filepath.Join(dir, "foo_1.2.3_darwin_amd64-component.pkg")
+	require.Contains(t, string(bts), "pkgbuild --root "+filepath.Join(dir, "root")+
+		" --identifier com.example.foo --version 1.2.3 --install-location /usr/local/bin --scripts scripts "+component+"\n")
+	require.Contains(t, string(bts), "productbuild --package "+component+" "+
+		filepath.Join(ctx.Config.Dist, "foo_1.2.3_darwin_amd64.pkg")+"\n")
+}
+
+func TestRunPipeSigned(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	var ctx = pkgContext(t, folder, config.MacOSPkg{
+		Identifier:      "com.example.foo",
+		SigningIdentity: "Developer ID Installer: {{ .Env.TEAM }}",
+	})
+	ctx.Env = map[string]string{"TEAM": "Foo Inc"}
+	require.NoError(t, Pipe{}.Run(ctx))
+	bts, err := ioutil.ReadFile(log)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "--sign Developer ID Installer: Foo Inc ")
+}
+
+func TestRunPipeSkipSign(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	var ctx = pkgContext(t, folder, config.MacOSPkg{
+		Identifier:      "com.example.foo",
+		SigningIdentity: "Developer ID Installer: Foo Inc",
+	})
+	ctx.SkipSign = true
+	require.NoError(t, Pipe{}.Run(ctx))
+	bts, err := ioutil.ReadFile(log)
+	require.NoError(t, err)
+	require.NotContains(t, string(bts), "--sign")
+}
+
+func TestRunPipeNoBinaries(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, filepath.Join(folder, "tools.log"))()
+	var ctx = pkgContext(t, folder, config.MacOSPkg{Identifier: "com.example.foo", Builds: []string{"nope"}})
+	require.EqualError(t, Pipe{}.Run(ctx), "no darwin binaries found for macos_pkg default")
+}
+
+func TestRunPipeConditionFalse(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	var ctx = pkgContext(t, folder, config.MacOSPkg{Identifier: "com.example.foo", If: "{{ .IsSnapshot }}"})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoFileExists(t, log)
+	require.Empty(t, ctx.Artifacts.Filter(artifact.ByType(artifact.MacOSPackage)).List())
+}
+
+func TestRunPipePkgbuildFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, filepath.Join(folder, "tools.log"))()
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "bin", "pkgbuild"),
+		[]byte("#!/bin/sh\necho 'invalid install location'\nexit 1\n"),
+		0755,
+	))
+	var ctx = pkgContext(t, folder, config.MacOSPkg{Identifier: "com.example.foo"})
+	require.EqualError(t, Pipe{}.Run(ctx), "failed to run pkgbuild: invalid install location\n")
+}
+
+func TestRunPipeNoTools(t *testing.T) {
+	var path = os.Getenv("PATH")
+	defer func() {
+		require.NoError(t, os.Setenv("PATH", path))
+	}()
+	require.NoError(t, os.Setenv("PATH", ""))
+	var ctx = context.New(config.Project{
+		MacOSPkgs: []config.MacOSPkg{{Identifier: "com.example.foo"}},
+	})
+	require.Equal(t, ErrNoPkgbuild, Pipe{}.Run(ctx))
+}
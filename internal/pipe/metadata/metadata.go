@@ -0,0 +1,97 @@
+// Package metadata provides a Pipe that records what a release published,
+// so it can be rolled back later.
+package metadata
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/pipe/blob"
+	"github.com/goreleaser/goreleaser/internal/pipe/effectiveconfig"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// Filename of the metadata file inside the dist folder
+const Filename = "metadata.json"
+
+// Metadata describes what a release published
+type Metadata struct {
+	ProjectName    string                  `json:"project_name"`
+	Tag            string                  `json:"tag"`
+	Version        string                  `json:"version"`
+	Commit         string                  `json:"commit"`
+	TokenType      context.TokenType       `json:"token_type"`
+	ReleaseURL     string                  `json:"release_url,omitempty"`
+	Blobs          []blob.Upload           `json:"blobs,omitempty"`
+	DockerImages   []string                `json:"docker_images,omitempty"`
+	CommittedFiles []context.CommittedFile `json:"committed_files,omitempty"`
+	ConfigSHA256   string                  `json:"config_sha256,omitempty"`
+}
+
+// Pipe that writes the release metadata to dist
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "writing release metadata"
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	if ctx.SkipPublish {
+		return pipe.ErrSkipPublishEnabled
+	}
+	blobs, err := blob.Uploads(ctx)
+	if err != nil {
+		return err
+	}
+	var meta = Metadata{
+		ProjectName:    ctx.Config.ProjectName,
+		Tag:            ctx.Git.CurrentTag,
+		Version:        ctx.Version,
+		Commit:         ctx.Git.Commit,
+		TokenType:      ctx.TokenType,
+		ReleaseURL:     ctx.ReleaseURL,
+		Blobs:          blobs,
+		CommittedFiles: ctx.CommittedFiles,
+	}
+	for _, img := range ctx.Artifacts.Filter(artifact.Or(
+		artifact.ByType(artifact.DockerImage),
+		artifact.ByType(artifact.DockerManifest),
+	)).List() {
+		meta.DockerImages = append(meta.DockerImages, img.Name)
+	}
+	var config = artifact.Artifact{Path: filepath.Join(ctx.Config.Dist, effectiveconfig.Filename)}
+	if _, err := os.Stat(config.Path); err == nil {
+		sum, err := config.Checksum("sha256")
+		if err != nil {
+			return err
+		}
+		meta.ConfigSHA256 = sum
+	}
+	bts, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	var path = filepath.Join(ctx.Config.Dist, Filename)
+	log.WithField("file", path).Info("writing")
+	return ioutil.WriteFile(path, bts, 0644)
+}
+
+// Load reads the metadata written by a previous release
+func Load(path string) (Metadata, error) {
+	var meta Metadata
+	bts, err := ioutil.ReadFile(path)
+	if err != nil {
+		return meta, errors.Wrap(err, "failed to read release metadata")
+	}
+	if err := json.Unmarshal(bts, &meta); err != nil {
+		return meta, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	return meta, nil
+}
@@ -0,0 +1,97 @@
+package metadata
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe/blob"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipPublish(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.SkipPublish = true
+	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+}
+
+func TestRunAndLoad(t *testing.T) {
+	var folder, back = testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        folder,
+		Blobs: []config.Blob{
+			{
+				Provider: "s3",
+				Bucket:   "bucket",
+				Folder:   "{{ .ProjectName }}/{{ .Tag }}",
+			},
+		},
+	})
+	ctx.Git = context.GitInfo{
+		CurrentTag: "v1.2.3",
+		Commit:     "abcdef",
+	}
+	ctx.Version = "1.2.3"
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.ReleaseURL = "https://github.com/goreleaser/foo/releases/tag/v1.2.3"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "foo.tar.gz",
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.DockerImage,
+		Name: "goreleaser/foo:v1.2.3",
+	})
+	ctx.CommittedFiles = []context.CommittedFile{
+		{
+			Repo: config.Repo{Owner: "goreleaser", Name: "homebrew-tap"},
+			Path: "Formula/foo.rb",
+		},
+	}
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	meta, err := Load(filepath.Join(folder, Filename))
+	require.NoError(t, err)
+	require.Equal(t, Metadata{
+		ProjectName: "foo",
+		Tag:         "v1.2.3",
+		Version:     "1.2.3",
+		Commit:      "abcdef",
+		TokenType:   context.TokenTypeGitHub,
+		ReleaseURL:  "https://github.com/goreleaser/foo/releases/tag/v1.2.3",
+		Blobs: []blob.Upload{
+			{
+				URL:  "s3://bucket",
+				Keys: []string{"foo/v1.2.3/foo.tar.gz"},
+			},
+		},
+		DockerImages:   []string{"goreleaser/foo:v1.2.3"},
+		CommittedFiles: ctx.CommittedFiles,
+	}, meta)
+}
+
+func TestRunWithConfig(t *testing.T) {
+	var folder, back = testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "config.yaml"), []byte("project_name: foo\n"), 0644))
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        folder,
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	meta, err := Load(filepath.Join(folder, Filename))
+	require.NoError(t, err)
+	require.Equal(t, "f15fa631668fd0bfbec577e56f9ca8657a0a2d4d005f5bafc2b3073a74ca8ccc", meta.ConfigSHA256)
+}
+
+func TestLoadMissing(t *testing.T) {
+	_, err := Load("/nope/metadata.json")
+	require.Error(t, err)
+}
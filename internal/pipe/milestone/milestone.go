@@ -0,0 +1,80 @@
+// Package milestone provides a Pipe that closes the milestone of the release
+package milestone
+
+import (
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoMilestones happens when the client can't close milestones
+var ErrNoMilestones = errors.New("milestones are only supported on GitHub and GitLab")
+
+// Pipe for milestones
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "milestones"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Milestones {
+		var milestone = &ctx.Config.Milestones[i]
+		if milestone.NameTemplate == "" {
+			milestone.NameTemplate = "{{ .Tag }}"
+		}
+		if milestone.Repo.Name != "" {
+			continue
+		}
+		switch ctx.TokenType {
+		case context.TokenTypeGitLab:
+			milestone.Repo = ctx.Config.Release.GitLab
+		case context.TokenTypeGitea:
+			milestone.Repo = ctx.Config.Release.Gitea
+		default:
+			milestone.Repo = ctx.Config.Release.GitHub
+		}
+	}
+	return nil
+}
+
+// Publish closes the milestones
+func (Pipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.Milestones) == 0 {
+		return pipe.Skip("milestones section is not configured")
+	}
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return doPublish(ctx, cli)
+}
+
+func doPublish(ctx *context.Context, cli client.Client) error {
+	for _, milestone := range ctx.Config.Milestones {
+		if !milestone.Close {
+			continue
+		}
+		closer, ok := cli.(client.MilestoneCloser)
+		if !ok {
+			return ErrNoMilestones
+		}
+		name, err := tmpl.New(ctx).Apply(milestone.NameTemplate)
+		if err != nil {
+			return errors.Wrap(err, "failed to template the milestone name")
+		}
+		if err := closer.CloseMilestone(ctx, milestone.Repo, name); err != nil {
+			if milestone.FailOnError {
+				return errors.Wrapf(err, "failed to close milestone %s", name)
+			}
+			log.WithError(err).WithField("milestone", name).Warn("failed to close milestone")
+			continue
+		}
+		log.WithField("milestone", name).WithField("repo", milestone.Repo.String()).Info("closed milestone")
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package milestone
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "goreleaser", Name: "goreleaser"},
+			GitLab: config.Repo{Owner: "goreleaser", Name: "gitlab"},
+		},
+		Milestones: []config.Milestone{
+			{Close: true},
+			{Repo: config.Repo{Owner: "foo", Name: "bar"}, NameTemplate: "v{{ .Version }}"},
+		},
+	})
+	ctx.TokenType = context.TokenTypeGitLab
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, []config.Milestone{
+		{
+			Repo:         config.Repo{Owner: "goreleaser", Name: "gitlab"},
+			Close:        true,
+			NameTemplate: "{{ .Tag }}",
+		},
+		{
+			Repo:         config.Repo{Owner: "foo", Name: "bar"},
+			NameTemplate: "v{{ .Version }}",
+		},
+	}, ctx.Config.Milestones)
+}
+
+func TestSkipNotConfigured(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Publish(context.New(config.Project{})))
+}
+
+func milestoneContext(milestones ...config.Milestone) *context.Context {
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "goreleaser", Name: "goreleaser"},
+		},
+		Milestones: milestones,
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Version = "1.0.0"
+	_ = Pipe{}.Default(ctx)
+	return ctx
+}
+
+func TestCloseMilestone(t *testing.T) {
+	var ctx = milestoneContext(
+		config.Milestone{Close: true},
+		config.Milestone{Close: true, Repo: config.Repo{Owner: "foo", Name: "bar"}, NameTemplate: "{{ .Version }}"},
+		config.Milestone{Repo: config.Repo{Owner: "foo", Name: "open"}},
+	)
+	var cli = &fakeClient{}
+	require.NoError(t, doPublish(ctx, cli))
+	require.Equal(t, []string{"goreleaser/goreleaser v1.0.0", "foo/bar 1.0.0"}, cli.closed)
+}
+
+func TestCloseMilestoneFails(t *testing.T) {
+	var cli = &fakeClient{err: errors.New("no open milestone")}
+	require.NoError(t, doPublish(milestoneContext(config.Milestone{Close: true}), cli))
+	require.EqualError(
+		t,
+		doPublish(milestoneContext(config.Milestone{Close: true, FailOnError: true}), cli),
+		"failed to close milestone v1.0.0: no open milestone",
+	)
+}
+
+func TestCloseMilestoneInvalidTemplate(t *testing.T) {
+	var ctx = milestoneContext(config.Milestone{Close: true, NameTemplate: "{{ .Nope"})
+	require.Contains(t, doPublish(ctx, &fakeClient{}).Error(), "failed to template the milestone name")
+}
+
+func TestCloseMilestoneNotSupported(t *testing.T) {
+	var ctx = milestoneContext(config.Milestone{Close: true})
+	require.Equal(t, ErrNoMilestones, doPublish(ctx, &basicClient{}))
+}
+
+type basicClient struct{}
+
+func (*basicClient) CreateRelease(ctx *context.Context, body string) (string, error) {
+	return "", nil
+}
+
+func (*basicClient) CreateFile(ctx *context.Context, commitAuthor config.CommitAuthor, repo config.Repo, content []byte, path, message string) error {
+	return nil
+}
+
+func (*basicClient) Upload(ctx *context.Context, releaseID string, artifact *artifact.Artifact, file *os.File) error {
+	return nil
+}
+
+type fakeClient struct {
+	basicClient
+	closed []string
+	err    error
+}
+
+func (c *fakeClient) CloseMilestone(ctx *context.Context, repo config.Repo, title string) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.closed = append(c.closed, repo.String()+" "+title)
+	return nil
+}
@@ -0,0 +1,222 @@
+// Package mirror provides a Pipe that re-uploads the release assets to other
+// locations, and checks that all of them serve the same files.
+package mirror
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	h "net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/http"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// Filename of the mirror report inside the dist folder
+const Filename = "mirrors.json"
+
+const kind = "mirror"
+
+// Entry of the mirror report: a file served by a mirror
+type Entry struct {
+	Mirror   string `json:"mirror"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Expected string `json:"expected_sha256"`
+	Actual   string `json:"actual_sha256,omitempty"`
+	Error    string `json:"error,omitempty"`
+	OK       bool   `json:"ok"`
+}
+
+// Pipe for mirrors
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "mirrors"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Mirrors {
+		var mirror = &ctx.Config.Mirrors[i]
+		if mirror.DownloadURL == "" {
+			mirror.DownloadURL = mirror.Target
+		}
+	}
+	return nil
+}
+
+// Publish uploads the assets to the mirrors and checks them
+func (Pipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.Mirrors) == 0 {
+		return pipe.Skip("mirrors section is not configured")
+	}
+	var puts = make([]config.Put, 0, len(ctx.Config.Mirrors))
+	for _, mirror := range ctx.Config.Mirrors {
+		var put = toPut(mirror)
+		if err := http.CheckConfig(ctx, &put, kind); err != nil {
+			return err
+		}
+		puts = append(puts, put)
+	}
+	if err := http.Upload(ctx, puts, kind, func(res *h.Response) error {
+		if c := res.StatusCode; c < 200 || 299 < c {
+			return errors.Errorf("unexpected http response status: %s", res.Status)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return verify(ctx)
+}
+
+// toPut returns the put uploading all the assets of the release to the
+// given mirror.
+func toPut(mirror config.Mirror) config.Put {
+	return config.Put{
+		Name:         mirror.Name,
+		IDs:          mirror.IDs,
+		Target:       mirror.Target,
+		Username:     mirror.Username,
+		Mode:         http.ModeArchive,
+		TrustedCerts: mirror.TrustedCerts,
+		Checksum:     true,
+		Signature:    true,
+	}
+}
+
+// verify downloads the assets from every mirror, and compares their
+// checksums with the local files, writing the results to the mirror report.
+func verify(ctx *context.Context) error {
+	var lock sync.Mutex
+	var report []Entry
+	var g = semerrgroup.New(ctx.Parallelism)
+	for _, mirror := range ctx.Config.Mirrors {
+		mirror := mirror
+		base, err := tmpl.New(ctx).Apply(mirror.DownloadURL)
+		if err != nil {
+			return errors.Wrapf(err, "failed to template the download url of mirror %s", mirror.Name)
+		}
+		var put = toPut(mirror)
+		client, err := http.Client(&put)
+		if err != nil {
+			return err
+		}
+		for _, a := range ctx.Artifacts.Filter(filter(mirror)).List() {
+			a := a
+			g.Go(func() error {
+				var entry = check(ctx, client, mirror, strings.TrimSuffix(base, "/")+"/"+a.Name, a)
+				lock.Lock()
+				report = append(report, entry)
+				lock.Unlock()
+				return nil
+			})
+		}
+	}
+	_ = g.Wait()
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Mirror != report[j].Mirror {
+			return report[i].Mirror < report[j].Mirror
+		}
+		return report[i].Name < report[j].Name
+	})
+
+	var path = filepath.Join(ctx.Config.Dist, Filename)
+	bts, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, bts, 0644); err != nil {
+		return err
+	}
+	log.WithField("file", path).Info("writing mirror report")
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: Filename,
+		Path: path,
+		Type: artifact.MirrorReport,
+	})
+
+	var failed int
+	for _, entry := range report {
+		if !entry.OK {
+			log.WithField("mirror", entry.Mirror).WithField("url", entry.URL).Error("mirrored file doesn't match")
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d mirrored files don't match the released ones, check %s", failed, path)
+	}
+	return nil
+}
+
+func filter(mirror config.Mirror) artifact.Filter {
+	var filter = artifact.ByUploadable()
+	if len(mirror.IDs) > 0 {
+		filter = artifact.And(filter, artifact.ByIDs(mirror.IDs...))
+	}
+	return filter
+}
+
+func check(ctx *context.Context, client *h.Client, mirror config.Mirror, url string, a *artifact.Artifact) Entry {
+	var entry = Entry{
+		Mirror: mirror.Name,
+		Name:   a.Name,
+		URL:    url,
+	}
+	expected, err := a.Checksum("sha256")
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+	entry.Expected = expected
+	actual, err := download(ctx, client, mirror, url)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+	entry.Actual = actual
+	entry.OK = actual == expected
+	return entry
+}
+
+// download returns the sha256 of the file at the given url.
+func download(ctx *context.Context, client *h.Client, mirror config.Mirror, url string) (string, error) {
+	req, err := h.NewRequest(h.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	var envBase = fmt.Sprintf("%s_%s_", strings.ToUpper(kind), strings.ToUpper(mirror.Name))
+	var username = mirror.Username
+	if username == "" {
+		username = ctx.Env[envBase+"USERNAME"]
+	}
+	req.SetBasicAuth(username, ctx.Env[envBase+"SECRET"])
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close() // nolint: errcheck
+	if res.StatusCode != h.StatusOK {
+		return "", fmt.Errorf("unexpected http response status: %s", res.Status)
+	}
+	var sum = sha256.New()
+	if _, err := io.Copy(sum, res.Body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
@@ -0,0 +1,166 @@
+package mirror
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	h "net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMirror stores the uploaded files and serves them back, optionally
+// corrupting them.
+func fakeMirror(t *testing.T, corrupt bool) *httptest.Server {
+	var lock sync.Mutex
+	var files = map[string][]byte{}
+	return httptest.NewServer(h.HandlerFunc(func(w h.ResponseWriter, r *h.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch r.Method {
+		case h.MethodPut:
+			bts, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			files[r.URL.Path] = bts
+			w.WriteHeader(h.StatusCreated)
+		case h.MethodGet:
+			bts, ok := files[r.URL.Path]
+			if !ok {
+				w.WriteHeader(h.StatusNotFound)
+				return
+			}
+			if corrupt {
+				bts = append(bts, '!')
+			}
+			_, _ = w.Write(bts)
+		}
+	}))
+}
+
+func newContext(t *testing.T, mirrors ...config.Mirror) *context.Context {
+	var folder, err = ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        folder,
+		Mirrors:     mirrors,
+	})
+	ctx.Version = "1.0.0"
+	ctx.Env = map[string]string{}
+	for _, mirror := range mirrors {
+		ctx.Env["MIRROR_"+strings.ToUpper(mirror.Name)+"_SECRET"] = "secret"
+	}
+	for name, typ := range map[string]artifact.Type{
+		"foo.tar.gz":    artifact.UploadableArchive,
+		"checksums.txt": artifact.Checksum,
+		"foo":           artifact.Binary,
+	} {
+		var path = filepath.Join(folder, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(name), 0644))
+		ctx.Artifacts.Add(&artifact.Artifact{Name: name, Path: path, Type: typ})
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func readReport(t *testing.T, ctx *context.Context) []Entry {
+	bts, err := ioutil.ReadFile(filepath.Join(ctx.Config.Dist, Filename))
+	require.NoError(t, err)
+	var report []Entry
+	require.NoError(t, json.Unmarshal(bts, &report))
+	return report
+}
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Mirrors: []config.Mirror{
+			{Target: "https://example.com/up"},
+			{Target: "https://example.com/up", DownloadURL: "https://cdn.example.com"},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "https://example.com/up", ctx.Config.Mirrors[0].DownloadURL)
+	require.Equal(t, "https://cdn.example.com", ctx.Config.Mirrors[1].DownloadURL)
+}
+
+func TestSkipNotConfigured(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Publish(context.New(config.Project{})))
+}
+
+func TestMisconfigured(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Mirrors: []config.Mirror{{Name: "eu", Target: "https://example.com"}},
+	})
+	require.True(t, pipe.IsMisconfigured(Pipe{}.Publish(ctx)))
+}
+
+func TestPublish(t *testing.T) {
+	var eu = fakeMirror(t, false)
+	defer eu.Close()
+	var us = fakeMirror(t, false)
+	defer us.Close()
+	var ctx = newContext(
+		t,
+		config.Mirror{Name: "eu", Target: eu.URL + "/{{ .ProjectName }}/{{ .Version }}"},
+		config.Mirror{Name: "us", Target: us.URL + "/{{ .ProjectName }}/{{ .Version }}/"},
+	)
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	var report = readReport(t, ctx)
+	require.Len(t, report, 4)
+	for _, entry := range report {
+		require.True(t, entry.OK, entry.URL)
+		require.Empty(t, entry.Error)
+		require.Equal(t, entry.Expected, entry.Actual)
+	}
+	require.Equal(t, "eu", report[0].Mirror)
+	require.Equal(t, eu.URL+"/foo/1.0.0/checksums.txt", report[0].URL)
+	require.Equal(t, us.URL+"/foo/1.0.0/foo.tar.gz", report[3].URL)
+
+	var reports = ctx.Artifacts.Filter(artifact.ByType(artifact.MirrorReport)).List()
+	require.Len(t, reports, 1)
+	require.Equal(t, Filename, reports[0].Name)
+	require.Equal(t, filepath.Join(ctx.Config.Dist, Filename), reports[0].Path)
+}
+
+func TestPublishMismatch(t *testing.T) {
+	var srv = fakeMirror(t, true)
+	defer srv.Close()
+	var ctx = newContext(t, config.Mirror{Name: "eu", Target: srv.URL})
+	require.EqualError(
+		t,
+		Pipe{}.Publish(ctx),
+		"2 mirrored files don't match the released ones, check "+filepath.Join(ctx.Config.Dist, Filename),
+	)
+	for _, entry := range readReport(t, ctx) {
+		require.False(t, entry.OK)
+		require.NotEqual(t, entry.Expected, entry.Actual)
+	}
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.MirrorReport)).List(), 1)
+}
+
+func TestPublishMissing(t *testing.T) {
+	var upload = fakeMirror(t, false)
+	defer upload.Close()
+	var download = fakeMirror(t, false)
+	defer download.Close()
+	var ctx = newContext(t, config.Mirror{
+		Name:        "eu",
+		Target:      upload.URL,
+		DownloadURL: download.URL,
+	})
+	require.Error(t, Pipe{}.Publish(ctx))
+	for _, entry := range readReport(t, ctx) {
+		require.False(t, entry.OK)
+		require.Equal(t, "unexpected http response status: 404 Not Found", entry.Error)
+	}
+}
@@ -0,0 +1,228 @@
+// Package msi implements the Pipe interface building windows installers with
+// msitools.
+package msi
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/pipe/authenticode"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoWixl is shown when wixl cannot be found in $PATH
+var ErrNoWixl = errors.New("wixl not present in $PATH")
+
+const defaultNameTemplate = "{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}"
+
+var guid = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`)
+
+// Pipe for MSI packaging
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "windows installers"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	var ids = ids.New("msi")
+	for i := range ctx.Config.MSI {
+		var msi = &ctx.Config.MSI[i]
+		if msi.ID == "" {
+			msi.ID = "default"
+		}
+		if !guid.MatchString(msi.UpgradeCode) {
+			return fmt.Errorf("msi %s: upgrade_code must be a GUID", msi.ID)
+		}
+		if msi.NameTemplate == "" {
+			msi.NameTemplate = defaultNameTemplate
+		}
+		if len(msi.Builds) == 0 {
+			for _, b := range ctx.Config.Builds {
+				msi.Builds = append(msi.Builds, b.ID)
+			}
+		}
+		if msi.Name == "" {
+			msi.Name = ctx.Config.ProjectName
+		}
+		if msi.Manufacturer == "" {
+			msi.Manufacturer = ctx.Config.Metadata.Vendor
+		}
+		if msi.Manufacturer == "" {
+			msi.Manufacturer = ctx.Config.ProjectName
+		}
+		if msi.InstallDir == "" {
+			msi.InstallDir = msi.Name
+		}
+		ids.Inc(msi.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.MSI) == 0 {
+		return pipe.Skip("msi section is not configured")
+	}
+	if _, err := exec.LookPath("wixl"); err != nil {
+		return ErrNoWixl
+	}
+	for _, msi := range ctx.Config.MSI {
+		ok, err := condition.Check(ctx, msi.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("msi", msi.ID).Info("skipped because its condition is false")
+			continue
+		}
+		if err := doRun(ctx, msi); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, msi config.MSI) error {
+	var windowsBinaries = ctx.Artifacts.Filter(artifact.And(
+		artifact.ByGoos("windows"),
+		artifact.ByType(artifact.Binary),
+		artifact.ByIDs(msi.Builds...),
+	)).GroupByPlatform()
+	if len(windowsBinaries) == 0 {
+		return fmt.Errorf("no windows binaries found for msi %s", msi.ID)
+	}
+	var g = semerrgroup.New(ctx.Parallelism)
+	for platform, binaries := range windowsBinaries {
+		var arch = archFor(binaries[0])
+		if arch == "" {
+			log.WithField("platform", platform).Warn("ignored unsupported arch")
+			continue
+		}
+		binaries := binaries
+		g.Go(func() error {
+			return create(ctx, msi, arch, binaries)
+		})
+	}
+	return g.Wait()
+}
+
+// archFor returns the wixl arch of the given binary, or an empty string if
+// it doesn't support it
+func archFor(binary *artifact.Artifact) string {
+	switch binary.Goarch {
+	case "amd64":
+		return "x64"
+	case "386":
+		return "x86"
+	}
+	return ""
+}
+
+// file is a file installed by the MSI
+type file struct {
+	ID     string
+	Name   string
+	Source string
+}
+
+func create(ctx *context.Context, msi config.MSI, arch string, binaries []*artifact.Artifact) error {
+	name, err := tmpl.New(ctx).WithArtifact(binaries[0], map[string]string{}).Apply(msi.NameTemplate)
+	if err != nil {
+		return err
+	}
+	var log = log.WithField("msi", name).WithField("arch", arch)
+
+	var sources []string
+	for _, binary := range binaries {
+		sources = append(sources, binary.Path)
+	}
+	sources = append(sources, msi.ExtraFiles...)
+	var files []file
+	for i, src := range sources {
+		abs, err := filepath.Abs(src)
+		if err != nil {
+			return err
+		}
+		files = append(files, file{
+			ID:     fmt.Sprintf("File%d", i),
+			Name:   escape(filepath.Base(src)),
+			Source: escape(abs),
+		})
+	}
+
+	var wxsTemplate = defaultWXS
+	if msi.WXS != "" {
+		bts, err := ioutil.ReadFile(msi.WXS)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", msi.WXS)
+		}
+		wxsTemplate = string(bts)
+	}
+	wxs, err := tmpl.New(ctx).
+		WithArtifact(binaries[0], map[string]string{}).
+		WithExtraFields(tmpl.Fields{
+			"Name":         escape(msi.Name),
+			"Manufacturer": escape(msi.Manufacturer),
+			"UpgradeCode":  msi.UpgradeCode,
+			"InstallDir":   escape(msi.InstallDir),
+			"Path":         msi.Path,
+			"MSIVersion":   fmt.Sprintf("%d.%d.%d", ctx.Semver.Major, ctx.Semver.Minor, ctx.Semver.Patch),
+			"MSIArch":      arch,
+			"Files":        files,
+		}).
+		Apply(wxsTemplate)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute wxs template")
+	}
+	var wxsPath = filepath.Join(ctx.Config.Dist, name+".wxs")
+	if err := ioutil.WriteFile(wxsPath, []byte(wxs), 0644); err != nil {
+		return err
+	}
+
+	var path = filepath.Join(ctx.Config.Dist, name+".msi")
+	log.WithField("file", path).Info("creating")
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "wixl", "-a", arch, "-o", path, wxsPath)
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create %s: %s", name+".msi", string(out))
+	}
+	var installer = &artifact.Artifact{
+		Type:   artifact.MSI,
+		Name:   name + ".msi",
+		Path:   path,
+		Goos:   binaries[0].Goos,
+		Goarch: binaries[0].Goarch,
+		Extra: map[string]interface{}{
+			"ID": msi.ID,
+		},
+	}
+	if err := authenticode.Sign(ctx, installer); err != nil {
+		return err
+	}
+	ctx.Artifacts.Add(installer)
+	return nil
+}
+
+// escape escapes the given string to be used in the wxs
+func escape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
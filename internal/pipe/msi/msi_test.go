@@ -0,0 +1,260 @@
+package msi
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+const upgradeCode = "0A5C9B4E-6E0B-4E8C-9F5A-3C7B1D2E4F60"
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Builds:      []config.Build{{ID: "foo"}},
+		MSI:         []config.MSI{{UpgradeCode: upgradeCode}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.MSI{
+		ID:           "default",
+		Builds:       []string{"foo"},
+		NameTemplate: defaultNameTemplate,
+		Name:         "foo",
+		Manufacturer: "foo",
+		UpgradeCode:  upgradeCode,
+		InstallDir:   "foo",
+	}, ctx.Config.MSI[0])
+}
+
+func TestDefaultVendor(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Metadata:    config.Metadata{Vendor: "Foo Inc"},
+		MSI:         []config.MSI{{UpgradeCode: upgradeCode, Name: "Foo"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "Foo Inc", ctx.Config.MSI[0].Manufacturer)
+	require.Equal(t, "Foo", ctx.Config.MSI[0].InstallDir)
+}
+
+func TestDefaultInvalidUpgradeCode(t *testing.T) {
+	for _, code := range []string{"", "nope", upgradeCode + "0"} {
+		var ctx = context.New(config.Project{
+			MSI: []config.MSI{{ID: "foo", UpgradeCode: code}},
+		})
+		require.EqualError(t, Pipe{}.Default(ctx), "msi foo: upgrade_code must be a GUID")
+	}
+}
+
+func TestDefaultDuplicatedID(t *testing.T) {
+	var ctx = context.New(config.Project{
+		MSI: []config.MSI{
+			{ID: "a", UpgradeCode: upgradeCode},
+			{ID: "a", UpgradeCode: upgradeCode},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 msi with the ID 'a', please fix your config")
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+// fakeTools puts a fake wixl in the PATH, which logs its arguments and
+// creates the MSI, and a fake osslsigncode, which appends " signed" to it,
+// returning a func that restores the PATH.
+func fakeTools(t *testing.T, folder, log string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"wixl": `echo "$@" >> ` + log + `
+echo msi > "$4"
+`,
+		"osslsigncode": "while [ \"$1\" != \"-in\" ]; do shift; done\n{ cat \"$2\"; echo \" signed\"; } > \"$4\"\n",
+	})
+}
+
+func msiContext(t *testing.T, folder string, msi config.MSI) *context.Context {
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0755))
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		Builds:      []config.Build{{ID: "foo"}},
+		MSI:         []config.MSI{msi},
+	})
+	ctx.Version = "1.2.3-rc1"
+	ctx.Semver = context.Semver{Major: 1, Minor: 2, Patch: 3, Prerelease: "rc1"}
+	ctx.Git.CurrentTag = "v1.2.3-rc1"
+	for _, platform := range []struct{ goos, goarch string }{
+		{"windows", "amd64"},
+		{"windows", "386"},
+		{"windows", "arm64"},
+		{"linux", "amd64"},
+	} {
+		var path = filepath.Join(dist, platform.goos+platform.goarch, "foo.exe")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte("foo"), 0755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "foo.exe",
+			Path:   path,
+			Goos:   platform.goos,
+			Goarch: platform.goarch,
+			Type:   artifact.Binary,
+			Extra:  map[string]interface{}{"ID": "foo"},
+		})
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestRunPipe(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "wixl.log")
+	defer fakeTools(t, folder, log)()
+	require.NoError(t, ioutil.WriteFile("LICENSE.txt", []byte("MIT"), 0644))
+	var ctx = msiContext(t, folder, config.MSI{
+		Name:        "Foo & Bar",
+		UpgradeCode: upgradeCode,
+		Path:        true,
+		ExtraFiles:  []string{"LICENSE.txt"},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var msis = ctx.Artifacts.Filter(artifact.ByType(artifact.MSI)).List()
+	require.Len(t, msis, 2)
+	var names []string
+	for _, msi := range msis {
+		names = append(names, msi.Name)
+		require.FileExists(t, msi.Path)
+		require.Equal(t, "default", msi.ExtraOr("ID", ""))
+		require.Equal(t, "windows", msi.Goos)
+	}
+	require.ElementsMatch(t, []string{"foo_1.2.3-rc1_windows_amd64.msi", "foo_1.2.3-rc1_windows_386.msi"}, names)
+
+	var wxsPath = filepath.Join(ctx.Config.Dist, "foo_1.2.3-rc1_windows_amd64.wxs")
+	bts, err := ioutil.ReadFile(log)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "-a x64 -o "+filepath.Join(ctx.Config.Dist, "foo_1.2.3-rc1_windows_amd64.msi")+" "+wxsPath)
+	require.Contains(t, string(bts), "-a x86 -o ")
+
+	bts, err = ioutil.ReadFile(wxsPath)
+	require.NoError(t, err)
+	var wxs struct {
+		Product struct {
+			Name        string `xml:"Name,attr"`
+			Version     string `xml:"Version,attr"`
+			UpgradeCode string `xml:"UpgradeCode,attr"`
+			Directory   struct {
+				Directory struct {
+					ID        string `xml:"Id,attr"`
+					Directory struct {
+						Name      string `xml:"Name,attr"`
+						Component struct {
+							Files []struct {
+								Name   string `xml:"Name,attr"`
+								Source string `xml:"Source,attr"`
+							} `xml:"File"`
+							Environment struct {
+								Name  string `xml:"Name,attr"`
+								Value string `xml:"Value,attr"`
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	require.NoError(t, xml.Unmarshal(bts, &wxs))
+	require.Equal(t, "Foo & Bar", wxs.Product.Name)
+	require.Equal(t, "1.2.3", wxs.Product.Version)
+	require.Equal(t, upgradeCode, wxs.Product.UpgradeCode)
+	require.Equal(t, "ProgramFiles64Folder", wxs.Product.Directory.Directory.ID)
+	var installDir = wxs.Product.Directory.Directory.Directory
+	require.Equal(t, "Foo & Bar", installDir.Name)
+	require.Len(t, installDir.Component.Files, 2)
+	require.Equal(t, "foo.exe", installDir.Component.Files[0].Name)
+	require.Equal(t, filepath.Join(ctx.Config.Dist, "windowsamd64", "foo.exe"), installDir.Component.Files[0].Source)
+	require.Equal(t, "LICENSE.txt", installDir.Component.Files[1].Name)
+	require.Equal(t, filepath.Join(folder, "LICENSE.txt"), installDir.Component.Files[1].Source)
+	require.Equal(t, "PATH", installDir.Component.Environment.Name)
+	require.Equal(t, "[INSTALLDIR]", installDir.Component.Environment.Value)
+}
+
+func TestRunPipeSigned(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, filepath.Join(folder, "wixl.log"))()
+	var ctx = msiContext(t, folder, config.MSI{UpgradeCode: upgradeCode})
+	ctx.Config.Authenticode = config.Authenticode{
+		Enabled:     true,
+		Cmd:         "osslsigncode",
+		Certificate: "cert.p12",
+	}
+	require.NoError(t, Pipe{}.Run(ctx))
+	for _, msi := range ctx.Artifacts.Filter(artifact.ByType(artifact.MSI)).List() {
+		bts, err := ioutil.ReadFile(msi.Path)
+		require.NoError(t, err)
+		require.Equal(t, "msi\n signed\n", string(bts))
+	}
+}
+
+func TestRunPipeCustomWXS(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, filepath.Join(folder, "wixl.log"))()
+	require.NoError(t, ioutil.WriteFile("foo.wxs", []byte("<Wix>{{ .ProjectName }} {{ .MSIArch }}</Wix>"), 0644))
+	var ctx = msiContext(t, folder, config.MSI{UpgradeCode: upgradeCode, WXS: "foo.wxs"})
+	require.NoError(t, Pipe{}.Run(ctx))
+	bts, err := ioutil.ReadFile(filepath.Join(ctx.Config.Dist, "foo_1.2.3-rc1_windows_386.wxs"))
+	require.NoError(t, err)
+	require.Equal(t, "<Wix>foo x86</Wix>", string(bts))
+}
+
+func TestRunPipeInvalidWXS(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, filepath.Join(folder, "wixl.log"))()
+	require.NoError(t, ioutil.WriteFile("foo.wxs", []byte("{{ .Nope }"), 0644))
+	var ctx = msiContext(t, folder, config.MSI{UpgradeCode: upgradeCode, WXS: "foo.wxs"})
+	var err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to execute wxs template")
+
+	var nope = filepath.Join(folder, "nope")
+	require.NoError(t, os.Mkdir(nope, 0755))
+	ctx = msiContext(t, nope, config.MSI{UpgradeCode: upgradeCode, WXS: "nope.wxs"})
+	err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read nope.wxs")
+}
+
+func TestRunPipeNoBinaries(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, filepath.Join(folder, "wixl.log"))()
+	var ctx = msiContext(t, folder, config.MSI{UpgradeCode: upgradeCode, Builds: []string{"nope"}})
+	require.EqualError(t, Pipe{}.Run(ctx), "no windows binaries found for msi default")
+}
+
+func TestRunPipeWixlFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, filepath.Join(folder, "wixl.log"))()
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "bin", "wixl"),
+		[]byte("#!/bin/sh\necho 'unhandled element'\nexit 1\n"),
+		0755,
+	))
+	var ctx = msiContext(t, folder, config.MSI{UpgradeCode: upgradeCode})
+	var err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unhandled element")
+}
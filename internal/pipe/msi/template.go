@@ -0,0 +1,28 @@
+package msi
+
+const defaultWXS = `<?xml version="1.0" encoding="utf-8"?>
+<Wix xmlns="http://schemas.microsoft.com/wix/2006/wi">
+  <Product Id="*" Name="{{ .Name }}" Version="{{ .MSIVersion }}" Manufacturer="{{ .Manufacturer }}" UpgradeCode="{{ .UpgradeCode }}" Language="1033">
+    <Package InstallerVersion="500" Compressed="yes" InstallScope="perMachine"{{ if eq .MSIArch "x64" }} Platform="x64"{{ end }}/>
+    <MajorUpgrade DowngradeErrorMessage="A newer version of {{ .Name }} is already installed."/>
+    <Media Id="1" Cabinet="product.cab" EmbedCab="yes"/>
+    <Directory Id="TARGETDIR" Name="SourceDir">
+      <Directory Id="{{ if eq .MSIArch "x64" }}ProgramFiles64Folder{{ else }}ProgramFilesFolder{{ end }}">
+        <Directory Id="INSTALLDIR" Name="{{ .InstallDir }}">
+          <Component Id="ApplicationFiles" Guid="*"{{ if eq .MSIArch "x64" }} Win64="yes"{{ end }}>
+{{- range $i, $file := .Files }}
+            <File Id="{{ $file.ID }}" Name="{{ $file.Name }}" Source="{{ $file.Source }}"{{ if eq $i 0 }} KeyPath="yes"{{ end }}/>
+{{- end }}
+{{- if .Path }}
+            <Environment Id="PATH" Name="PATH" Value="[INSTALLDIR]" Permanent="no" Part="last" Action="set" System="yes"/>
+{{- end }}
+          </Component>
+        </Directory>
+      </Directory>
+    </Directory>
+    <Feature Id="Complete" Level="1">
+      <ComponentRef Id="ApplicationFiles"/>
+    </Feature>
+  </Product>
+</Wix>
+`
@@ -0,0 +1,142 @@
+package nfpm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/pkg/errors"
+)
+
+// systemdUnitDirs are the folders systemd units are installed in, by format.
+// apk and termux packages don't install systemd units.
+var systemdUnitDirs = map[string]string{
+	"deb": "/lib/systemd/system",
+	"rpm": "/usr/lib/systemd/system",
+}
+
+// systemctl runs systemctl only if systemd is there, as packages can be
+// installed in containers too
+const systemctl = "if command -v systemctl >/dev/null 2>&1; then\n%s\nfi\n"
+
+// templateFiles returns a copy of the given files, or symlinks, with both
+// their keys and values templated
+func templateFiles(template *tmpl.Template, files map[string]string) (map[string]string, error) {
+	var result = map[string]string{}
+	for src, dst := range files {
+		src, err := template.Apply(src)
+		if err != nil {
+			return nil, err
+		}
+		dst, err := template.Apply(dst)
+		if err != nil {
+			return nil, err
+		}
+		result[src] = dst
+	}
+	return result, nil
+}
+
+// templateFolders returns a copy of the given folders, templated
+func templateFolders(template *tmpl.Template, folders []string) ([]string, error) {
+	var result []string
+	for _, folder := range folders {
+		folder, err := template.Apply(folder)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, folder)
+	}
+	return result, nil
+}
+
+// systemdUnits writes the given systemd units, templated, in dir and returns
+// the files, source to destination, needed to install them
+func systemdUnits(template *tmpl.Template, units []string, unitDir, dir string) (map[string]string, error) {
+	var files = map[string]string{}
+	for _, unit := range units {
+		var dst = filepath.Join(dir, "systemd", filepath.Base(unit))
+		if err := templateFile(template, unit, dst); err != nil {
+			return nil, errors.Wrapf(err, "failed to template systemd unit %s", unit)
+		}
+		files[dst] = filepath.Join(unitDir, filepath.Base(unit))
+	}
+	return files, nil
+}
+
+// templateScripts writes the given maintainer scripts, templated, in dir,
+// followed by the commands enabling the given systemd units on install and
+// disabling them on removal. It returns the scripts to give to nfpm.
+func templateScripts(template *tmpl.Template, scripts config.NFPMScripts, units []string, dir string) (config.NFPMScripts, error) {
+	var names []string
+	for _, unit := range units {
+		names = append(names, filepath.Base(unit))
+	}
+	var enable, disable, reload string
+	if len(names) > 0 {
+		reload = fmt.Sprintf(systemctl, "\tsystemctl daemon-reload")
+		enable = fmt.Sprintf(systemctl, "\tsystemctl daemon-reload\n\tsystemctl enable "+strings.Join(names, " "))
+		// deb passes "remove", rpm the number of installed versions left,
+		// neither of them on upgrades
+		disable = "if [ \"$1\" = \"remove\" ] || [ \"$1\" = \"0\" ]; then\n" +
+			fmt.Sprintf(systemctl, "\tsystemctl disable --now "+strings.Join(names, " ")) +
+			"fi\n"
+	}
+	var result config.NFPMScripts
+	for _, script := range []struct {
+		src, extra, name string
+		dst              *string
+	}{
+		{scripts.PreInstall, "", "preinstall", &result.PreInstall},
+		{scripts.PostInstall, enable, "postinstall", &result.PostInstall},
+		{scripts.PreRemove, disable, "preremove", &result.PreRemove},
+		{scripts.PostRemove, reload, "postremove", &result.PostRemove},
+	} {
+		if script.src == "" && script.extra == "" {
+			continue
+		}
+		var content = "#!/bin/sh\n"
+		if script.src != "" {
+			bts, err := ioutil.ReadFile(script.src)
+			if err != nil {
+				return result, errors.Wrapf(err, "failed to read %s script", script.name)
+			}
+			content, err = template.Apply(string(bts))
+			if err != nil {
+				return result, errors.Wrapf(err, "failed to template %s script", script.name)
+			}
+			if !strings.HasSuffix(content, "\n") {
+				content += "\n"
+			}
+		}
+		var path = filepath.Join(dir, "scripts", script.name+".sh")
+		if err := writeFile(path, content+script.extra, 0755); err != nil {
+			return result, err
+		}
+		*script.dst = path
+	}
+	return result, nil
+}
+
+func templateFile(template *tmpl.Template, src, dst string) error {
+	bts, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	content, err := template.Apply(string(bts))
+	if err != nil {
+		return err
+	}
+	return writeFile(dst, content, 0644)
+}
+
+func writeFile(path, content string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(content), mode)
+}
@@ -0,0 +1,146 @@
+package nfpm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func templateContext() *tmpl.Template {
+	var ctx = context.New(config.Project{ProjectName: "mybin"})
+	ctx.Version = "1.0.0"
+	return tmpl.New(ctx)
+}
+
+func TestTemplateFiles(t *testing.T) {
+	files, err := templateFiles(templateContext(), map[string]string{
+		"dist/{{ .ProjectName }}.conf": "/etc/{{ .ProjectName }}/{{ .Version }}.conf",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"dist/mybin.conf": "/etc/mybin/1.0.0.conf"}, files)
+
+	_, err = templateFiles(templateContext(), map[string]string{"{{ .Nope }}": "/etc"})
+	require.Error(t, err)
+}
+
+func TestTemplateScripts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nfpmscripts")
+	require.NoError(t, err)
+	scripts, err := templateScripts(templateContext(), config.NFPMScripts{
+		PostInstall: "./testdata/postinstall.sh",
+	}, []string{"./testdata/mybin.service"}, dir)
+	require.NoError(t, err)
+	require.Empty(t, scripts.PreInstall)
+
+	bts, err := ioutil.ReadFile(scripts.PostInstall)
+	require.NoError(t, err)
+	require.Equal(t, `#!/bin/sh
+echo "installed mybin 1.0.0"
+if command -v systemctl >/dev/null 2>&1; then
+	systemctl daemon-reload
+	systemctl enable mybin.service
+fi
+`, string(bts))
+	info, err := os.Stat(scripts.PostInstall)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0755), info.Mode())
+
+	bts, err = ioutil.ReadFile(scripts.PreRemove)
+	require.NoError(t, err)
+	require.Equal(t, `#!/bin/sh
+if [ "$1" = "remove" ] || [ "$1" = "0" ]; then
+if command -v systemctl >/dev/null 2>&1; then
+	systemctl disable --now mybin.service
+fi
+fi
+`, string(bts))
+
+	bts, err = ioutil.ReadFile(scripts.PostRemove)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "systemctl daemon-reload")
+}
+
+func TestTemplateScriptsNoUnits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nfpmscripts")
+	require.NoError(t, err)
+	scripts, err := templateScripts(templateContext(), config.NFPMScripts{
+		PostInstall: "./testdata/postinstall.sh",
+	}, nil, dir)
+	require.NoError(t, err)
+	require.Equal(t, config.NFPMScripts{PostInstall: filepath.Join(dir, "scripts", "postinstall.sh")}, scripts)
+}
+
+func TestTemplateScriptsMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nfpmscripts")
+	require.NoError(t, err)
+	_, err = templateScripts(templateContext(), config.NFPMScripts{PreRemove: "./testdata/nope.sh"}, nil, dir)
+	require.Contains(t, err.Error(), "failed to read preremove script")
+}
+
+func TestRunPipeSystemdUnits(t *testing.T) {
+	folder, err := ioutil.TempDir("", "nfpmsystemd")
+	require.NoError(t, err)
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0755))
+	var binPath = filepath.Join(dist, "mybin")
+	require.NoError(t, ioutil.WriteFile(binPath, []byte("mybin"), 0755))
+	var ctx = context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		NFPMs: []config.NFPM{{
+			Builds:     []string{"default"},
+			Formats:    []string{"deb", "rpm", "apk"},
+			Maintainer: "me@me",
+			NFPMOverridables: config.NFPMOverridables{
+				SystemdUnits: []string{"./testdata/mybin.service"},
+				Symlinks: map[string]string{
+					"/usr/bin/{{ .ProjectName }}": "/usr/local/bin/mybin",
+				},
+				Files: map[string]string{
+					"./testdata/testfile.txt": "/usr/share/{{ .ProjectName }}/{{ .Version }}.txt",
+				},
+				Scripts: config.NFPMScripts{
+					PostInstall: "./testdata/postinstall.sh",
+				},
+			},
+		}},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   binPath,
+		Goarch: "amd64",
+		Goos:   "linux",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"ID": "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List(), 3)
+
+	require.ElementsMatch(t, []string{
+		"usr/local/bin/mybin",
+		"usr/share/mybin/1.0.0.txt",
+		"lib/systemd/system/mybin.service",
+	}, debFiles(t, filepath.Join(dist, "mybin_1.0.0_linux_amd64.deb")))
+
+	var dir = filepath.Join(dist, "nfpm", "mybin_1.0.0_linux_amd64.deb")
+	bts, err := ioutil.ReadFile(filepath.Join(dir, "systemd", "mybin.service"))
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "Description=mybin 1.0.0\n")
+	require.FileExists(t, filepath.Join(dir, "scripts", "preremove.sh"))
+	require.FileExists(t, filepath.Join(dist, "nfpm", "mybin_1.0.0_linux_amd64.rpm", "systemd", "mybin.service"))
+	require.NoFileExists(t, filepath.Join(dist, "nfpm", "mybin_1.0.0_linux_amd64.apk", "systemd", "mybin.service"))
+	require.NoFileExists(t, filepath.Join(dist, "nfpm", "mybin_1.0.0_linux_amd64.apk", "scripts", "preremove.sh"))
+	require.FileExists(t, filepath.Join(dist, "nfpm", "mybin_1.0.0_linux_amd64.apk", "scripts", "postinstall.sh"))
+}
@@ -6,10 +6,13 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
 	"github.com/goreleaser/goreleaser/internal/deprecate"
+	"github.com/goreleaser/goreleaser/internal/desktop"
 	"github.com/goreleaser/goreleaser/internal/ids"
 	"github.com/goreleaser/goreleaser/internal/linux"
 	"github.com/goreleaser/goreleaser/internal/pipe"
@@ -18,6 +21,7 @@ import (
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/goreleaser/nfpm"
+	_ "github.com/goreleaser/nfpm/apk" // blank import to register the format
 	_ "github.com/goreleaser/nfpm/deb" // blank import to register the format
 	_ "github.com/goreleaser/nfpm/rpm" // blank import to register the format
 	"github.com/imdario/mergo"
@@ -38,7 +42,7 @@ func (Pipe) Default(ctx *context.Context) error {
 	if len(ctx.Config.NFPMs) == 0 {
 		ctx.Config.NFPMs = append(ctx.Config.NFPMs, ctx.Config.NFPM)
 		if !reflect.DeepEqual(ctx.Config.NFPM, config.NFPM{}) {
-			deprecate.Notice("nfpm")
+			deprecate.Notice(ctx, "nfpm")
 		}
 	}
 	var ids = ids.New("nfpms")
@@ -61,6 +65,26 @@ func (Pipe) Default(ctx *context.Context) error {
 				fpm.Builds = append(fpm.Builds, b.ID)
 			}
 		}
+		if fpm.Vendor == "" {
+			fpm.Vendor = ctx.Config.Metadata.Vendor
+		}
+		if fpm.Homepage == "" {
+			fpm.Homepage = ctx.Config.Metadata.Homepage
+		}
+		if fpm.Maintainer == "" && len(ctx.Config.Metadata.Maintainers) > 0 {
+			// packages have a single maintainer, so the first one is used
+			fpm.Maintainer = ctx.Config.Metadata.Maintainers[0]
+		}
+		if fpm.Description == "" {
+			fpm.Description = ctx.Config.Metadata.Description
+		}
+		if fpm.License == "" {
+			fpm.License = ctx.Config.Metadata.License
+		}
+		var sig = fpm.Signature
+		if sig.KeyID == "" && (sig.KeyFile != "" || sig.Key != "") {
+			return fmt.Errorf("nfpm %s: signature.key_id is required to sign packages", fpm.ID)
+		}
 		ids.Inc(fpm.ID)
 	}
 	return ids.Validate()
@@ -73,6 +97,14 @@ func (Pipe) Run(ctx *context.Context) error {
 			// FIXME: this assumes other nfpm configs will fail too...
 			return pipe.Skip("no output formats configured")
 		}
+		ok, err := condition.Check(ctx, nfpm.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("nfpm", nfpm.ID).Info("skipped because its condition is false")
+			continue
+		}
 		if err := doRun(ctx, nfpm); err != nil {
 			return err
 		}
@@ -83,26 +115,67 @@ func (Pipe) Run(ctx *context.Context) error {
 func doRun(ctx *context.Context, fpm config.NFPM) error {
 	var linuxBinaries = ctx.Artifacts.Filter(artifact.And(
 		artifact.ByType(artifact.Binary),
-		artifact.ByGoos("linux"),
+		artifact.Or(artifact.ByGoos("linux"), artifact.ByGoos("android")),
 		artifact.ByIDs(fpm.Builds...),
 	)).GroupByPlatform()
 	if len(linuxBinaries) == 0 {
 		return fmt.Errorf("no linux binaries found for builds %v", fpm.Builds)
 	}
+	if fpm.Desktop.File != "" || len(fpm.Desktop.Icons) > 0 {
+		entry, err := desktop.Load(fpm.Desktop)
+		if err != nil {
+			return err
+		}
+		var files = map[string]string{}
+		for src, dst := range fpm.Files {
+			files[src] = dst
+		}
+		for src, dst := range entry.Files("/usr") {
+			files[src] = dst
+		}
+		fpm.Files = files
+	}
+	signer, err := newSigner(ctx, fpm.Signature)
+	if err != nil {
+		return err
+	}
+	defer signer.close()
 	var g = semerrgroup.New(ctx.Parallelism)
 	for _, format := range fpm.Formats {
 		for platform, artifacts := range linuxBinaries {
+			// termux packages android binaries only, and the other formats
+			// linux binaries only
+			if (format == termuxFormat) != (artifacts[0].Goos == "android") {
+				continue
+			}
 			format := format
-			arch := linux.Arch(platform)
+			arch := packageArch(format, platform)
+			if arch == "" {
+				log.WithField("format", format).WithField("platform", platform).Warn("ignored unsupported arch")
+				continue
+			}
 			artifacts := artifacts
 			g.Go(func() error {
-				return create(ctx, fpm, format, arch, artifacts)
+				return create(ctx, fpm, format, arch, artifacts, signer)
 			})
 		}
 	}
 	return g.Wait()
 }
 
+// packageArch returns the arch of the package for the given platform. The apk
+// packager maps the go arch to the alpine one itself, termux has its own
+// archs, while deb and rpm need the linux arch.
+func packageArch(format, platform string) string {
+	switch format {
+	case "apk":
+		return strings.TrimPrefix(platform, "linux")
+	case termuxFormat:
+		return termuxArch(platform)
+	}
+	return linux.Arch(platform)
+}
+
 func mergeOverrides(fpm config.NFPM, format string) (*config.NFPMOverridables, error) {
 	var overridden config.NFPMOverridables
 	if err := mergo.Merge(&overridden, fpm.NFPMOverridables); err != nil {
@@ -118,25 +191,69 @@ func mergeOverrides(fpm config.NFPM, format string) (*config.NFPMOverridables, e
 	return &overridden, nil
 }
 
-func create(ctx *context.Context, fpm config.NFPM, format, arch string, binaries []*artifact.Artifact) error {
+func create(ctx *context.Context, fpm config.NFPM, format, arch string, binaries []*artifact.Artifact, signer *signer) error {
 	overridden, err := mergeOverrides(fpm, format)
 	if err != nil {
 		return err
 	}
-	name, err := tmpl.New(ctx).
-		WithArtifact(binaries[0], overridden.Replacements).
-		Apply(overridden.NameTemplate)
+	var template = tmpl.New(ctx).WithArtifact(binaries[0], overridden.Replacements)
+	name, err := template.ApplyName(overridden.NameTemplate)
 	if err != nil {
 		return err
 	}
-	var files = map[string]string{}
-	for k, v := range overridden.Files {
-		files[k] = v
-	}
 	var log = log.WithField("package", name+"."+format).WithField("arch", arch)
+	files, err := templateFiles(template, overridden.Files)
+	if err != nil {
+		return err
+	}
+	configFiles, err := templateFiles(template, overridden.ConfigFiles)
+	if err != nil {
+		return err
+	}
+	symlinks, err := templateFiles(template, overridden.Symlinks)
+	if err != nil {
+		return err
+	}
+	emptyFolders, err := templateFolders(template, overridden.EmptyFolders)
+	if err != nil {
+		return err
+	}
+
+	// templated scripts and systemd units are written here
+	var dir = filepath.Join(ctx.Config.Dist, "nfpm", name+"."+format)
+	var units = overridden.SystemdUnits
+	unitDir, ok := systemdUnitDirs[format]
+	if !ok && len(units) > 0 {
+		log.Warnf("%s packages don't install systemd units", format)
+		units = nil
+	}
+	unitFiles, err := systemdUnits(template, units, unitDir, dir)
+	if err != nil {
+		return err
+	}
+	for src, dst := range unitFiles {
+		files[src] = dst
+	}
+	scripts, err := templateScripts(template, overridden.Scripts, units, dir)
+	if err != nil {
+		return err
+	}
+
+	var bindir = fpm.Bindir
+	var packagerFormat = format
+	if format == termuxFormat {
+		bindir = termuxPath(bindir)
+		files = termuxFiles(files)
+		configFiles = termuxFiles(configFiles)
+		symlinks = termuxSymlinks(symlinks)
+		for i, folder := range emptyFolders {
+			emptyFolders[i] = termuxPath(folder)
+		}
+		packagerFormat = "deb"
+	}
 	for _, binary := range binaries {
 		src := binary.Path
-		dst := filepath.Join(fpm.Bindir, binary.Name)
+		dst := filepath.Join(bindir, binary.Name)
 		log.WithField("src", src).WithField("dst", dst).Debug("adding binary to package")
 		files[src] = dst
 	}
@@ -155,20 +272,20 @@ func create(ctx *context.Context, fpm config.NFPM, format, arch string, binaries
 		Vendor:      fpm.Vendor,
 		Homepage:    fpm.Homepage,
 		License:     fpm.License,
-		Bindir:      fpm.Bindir,
 		Overridables: nfpm.Overridables{
 			Conflicts:    overridden.Conflicts,
 			Depends:      overridden.Dependencies,
 			Recommends:   overridden.Recommends,
 			Suggests:     overridden.Suggests,
-			EmptyFolders: overridden.EmptyFolders,
+			EmptyFolders: emptyFolders,
 			Files:        files,
-			ConfigFiles:  overridden.ConfigFiles,
+			ConfigFiles:  configFiles,
+			Symlinks:     symlinks,
 			Scripts: nfpm.Scripts{
-				PreInstall:  overridden.Scripts.PreInstall,
-				PostInstall: overridden.Scripts.PostInstall,
-				PreRemove:   overridden.Scripts.PreRemove,
-				PostRemove:  overridden.Scripts.PostRemove,
+				PreInstall:  scripts.PreInstall,
+				PostInstall: scripts.PostInstall,
+				PreRemove:   scripts.PreRemove,
+				PostRemove:  scripts.PostRemove,
 			},
 		},
 	}
@@ -177,7 +294,7 @@ func create(ctx *context.Context, fpm config.NFPM, format, arch string, binaries
 		return errors.Wrap(err, "invalid nfpm config")
 	}
 
-	packager, err := nfpm.Get(format)
+	packager, err := nfpm.Get(packagerFormat)
 	if err != nil {
 		return err
 	}
@@ -195,6 +312,11 @@ func create(ctx *context.Context, fpm config.NFPM, format, arch string, binaries
 	if err := w.Close(); err != nil {
 		return errors.Wrap(err, "could not close package file")
 	}
+	if signer != nil {
+		if err := signer.sign(ctx, packagerFormat, path); err != nil {
+			return err
+		}
+	}
 	ctx.Artifacts.Add(&artifact.Artifact{
 		Type:   artifact.LinuxPackage,
 		Name:   name + "." + format,
@@ -204,6 +326,7 @@ func create(ctx *context.Context, fpm config.NFPM, format, arch string, binaries
 		Goarm:  binaries[0].Goarm,
 		Extra: map[string]interface{}{
 			"Builds": binaries,
+			"ID":     fpm.ID,
 		},
 	})
 	return nil
@@ -83,12 +83,18 @@ func TestRunPipe(t *testing.T) {
 			{
 				Bindir:      "/usr/bin",
 				Builds:      []string{"default"},
-				Formats:     []string{"deb", "rpm"},
+				Formats:     []string{"deb", "rpm", "apk"},
 				Description: "Some description",
 				License:     "MIT",
 				Maintainer:  "me@me",
 				Vendor:      "asdf",
 				Homepage:    "https://goreleaser.github.io",
+				Desktop: config.Desktop{
+					File: "./testdata/mybin.desktop",
+					Icons: map[string]string{
+						"scalable": "./testdata/mybin.svg",
+					},
+				},
 				NFPMOverridables: config.NFPMOverridables{
 					NameTemplate: defaultNameTemplate,
 					Dependencies: []string{"make"},
@@ -134,13 +140,27 @@ func TestRunPipe(t *testing.T) {
 	}
 	require.NoError(t, Pipe{}.Run(ctx))
 	var packages = ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List()
-	require.Len(t, packages, 4)
+	require.Len(t, packages, 6)
 	for _, pkg := range packages {
 		require.Contains(t, pkg.Name, "mybin_1.0.0_Tux_", "linux should have been replaced by Tux")
+		require.FileExists(t, pkg.Path)
 	}
 	require.Len(t, ctx.Config.NFPMs[0].Files, 1, "should not modify the config file list")
 }
 
+func TestPackageArch(t *testing.T) {
+	for platform, archs := range map[string][2]string{
+		"linux386":   {"i386", "386"},
+		"linuxamd64": {"amd64", "amd64"},
+		"linuxarm6":  {"armhf", "arm6"},
+		"linuxarm64": {"arm64", "arm64"},
+	} {
+		require.Equal(t, archs[0], packageArch("deb", platform))
+		require.Equal(t, archs[0], packageArch("rpm", platform))
+		require.Equal(t, archs[1], packageArch("apk", platform))
+	}
+}
+
 func TestInvalidNameTemplate(t *testing.T) {
 	var ctx = &context.Context{
 		Parallelism: runtime.NumCPU(),
@@ -257,7 +277,7 @@ func TestInvalidConfig(t *testing.T) {
 			"ID": "default",
 		},
 	})
-	require.Contains(t, Pipe{}.Run(ctx).Error(), `invalid nfpm config: package name cannot be empty`)
+	require.Contains(t, Pipe{}.Run(ctx).Error(), `invalid nfpm config: package name must be provided`)
 }
 
 func TestDefault(t *testing.T) {
@@ -319,6 +339,30 @@ func TestDefaultSet(t *testing.T) {
 	require.Equal(t, []string{"foo"}, ctx.Config.NFPMs[0].Builds)
 }
 
+func TestDefaultMetadata(t *testing.T) {
+	var ctx = &context.Context{
+		Config: config.Project{
+			Metadata: config.Metadata{
+				Description: "Some description",
+				Homepage:    "https://example.com",
+				License:     "MIT",
+				Maintainers: []string{"Foo <foo@example.com>", "Bar <bar@example.com>"},
+				Vendor:      "Foo Inc",
+			},
+			NFPMs: []config.NFPM{
+				{Description: "Overridden description"},
+			},
+		},
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	var fpm = ctx.Config.NFPMs[0]
+	require.Equal(t, "Overridden description", fpm.Description)
+	require.Equal(t, "https://example.com", fpm.Homepage)
+	require.Equal(t, "MIT", fpm.License)
+	require.Equal(t, "Foo <foo@example.com>", fpm.Maintainer)
+	require.Equal(t, "Foo Inc", fpm.Vendor)
+}
+
 func TestOverrides(t *testing.T) {
 	var ctx = &context.Context{
 		Config: config.Project{
@@ -361,3 +405,33 @@ func TestSeveralNFPMsWithTheSameID(t *testing.T) {
 	}
 	require.EqualError(t, Pipe{}.Default(ctx), "found 2 nfpms with the ID 'a', please fix your config")
 }
+
+func TestRunPipeInvalidDesktopFile(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var path = filepath.Join(folder, "mybin.desktop")
+	require.NoError(t, ioutil.WriteFile(path, []byte("[Desktop Entry]\nName=foo\n"), 0644))
+	var ctx = context.New(config.Project{
+		ProjectName: "mybin",
+		NFPMs: []config.NFPM{
+			{
+				Formats: []string{"deb"},
+				Builds:  []string{"default"},
+				Desktop: config.Desktop{
+					File: path,
+				},
+			},
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   "mybin",
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"ID": "default",
+		},
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), "invalid desktop file "+path+": missing Type key")
+}
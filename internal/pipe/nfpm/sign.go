@@ -0,0 +1,140 @@
+package nfpm
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// signer embeds gpg signatures in the deb packages, with dpkg-sig, and in
+// the rpm packages, with rpmsign.
+type signer struct {
+	keyID string
+	// dir holds the gpg home of the imported key and the passphrase file,
+	// if any
+	dir        string
+	env        []string
+	gpgOptions []string
+}
+
+// newSigner returns the signer of the given signature config, importing its
+// key in a temporary gpg home if needed, or nil if packages should not be
+// signed.
+func newSigner(ctx *context.Context, sig config.NFPMSignature) (*signer, error) {
+	if sig.KeyID == "" {
+		return nil, nil
+	}
+	if ctx.SkipSign {
+		log.Warn("skipped signing packages because of --skip-sign")
+		return nil, nil
+	}
+	var template = tmpl.New(ctx)
+	var fields = []*string{&sig.KeyID, &sig.KeyFile, &sig.Key, &sig.Passphrase}
+	for _, field := range fields {
+		applied, err := template.Apply(*field)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to template the nfpm signature")
+		}
+		*field = applied
+	}
+	var s = &signer{keyID: sig.KeyID, env: ctx.Env.Strings()}
+	if sig.KeyFile == "" && sig.Key == "" && sig.Passphrase == "" {
+		return s, nil
+	}
+	dir, err := ioutil.TempDir("", "goreleaser-nfpm-gnupg")
+	if err != nil {
+		return nil, err
+	}
+	s.dir = dir
+	if sig.KeyFile != "" || sig.Key != "" {
+		s.env = append(s.env, "GNUPGHOME="+dir)
+		if err := s.importKey(ctx, sig); err != nil {
+			s.close()
+			return nil, err
+		}
+	}
+	if sig.Passphrase != "" {
+		var file = filepath.Join(dir, "passphrase")
+		if err := ioutil.WriteFile(file, []byte(sig.Passphrase), 0600); err != nil {
+			s.close()
+			return nil, err
+		}
+		s.gpgOptions = []string{"--pinentry-mode", "loopback", "--passphrase-file", file}
+	}
+	return s, nil
+}
+
+// importKey imports the armored private key, read either from the key file
+// or from the key itself, in the gpg home of the signer
+func (s *signer) importKey(ctx *context.Context, sig config.NFPMSignature) error {
+	var key = []byte(sig.Key)
+	if sig.KeyFile != "" {
+		bts, err := ioutil.ReadFile(sig.KeyFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to read the signing key")
+		}
+		key = bts
+	}
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "gpg", "--batch", "--import")
+	cmd.Env = s.env
+	cmd.Stdin = bytes.NewReader(key)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to import the signing key: %s", string(out))
+	}
+	return nil
+}
+
+// sign embeds a signature in the given package, if its format supports it
+func (s *signer) sign(ctx *context.Context, format, path string) error {
+	var tool string
+	var args []string
+	switch format {
+	case "deb":
+		tool = "dpkg-sig"
+		args = []string{"--sign", "builder", "-k", s.keyID}
+		if len(s.gpgOptions) > 0 {
+			args = append(args, "--gpg-options", strings.Join(s.gpgOptions, " "))
+		}
+	case "rpm":
+		tool = "rpmsign"
+		args = []string{"--addsign", "--define", "_gpg_name " + s.keyID}
+		if len(s.gpgOptions) > 0 {
+			args = append(args, "--define", "_gpg_sign_cmd_extra_args "+strings.Join(s.gpgOptions, " "))
+		}
+	default:
+		log.WithField("package", path).Warnf("%s packages can't be signed", format)
+		return nil
+	}
+	log.WithField("package", path).Info("signing")
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, tool, append(args, path)...)
+	cmd.Env = s.env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) == 0 {
+			return errors.Wrapf(err, "failed to run %s", tool)
+		}
+		return fmt.Errorf("failed to run %s: %s", tool, string(out))
+	}
+	return nil
+}
+
+// close removes the gpg home and passphrase of the signer
+func (s *signer) close() {
+	if s == nil || s.dir == "" {
+		return
+	}
+	if err := os.RemoveAll(s.dir); err != nil {
+		log.WithError(err).Warn("failed to remove the temporary gpg home")
+	}
+}
@@ -0,0 +1,148 @@
+package nfpm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSigners puts a fake gpg, dpkg-sig and rpmsign in the PATH, which log
+// their arguments, stdin and GNUPGHOME, returning a func that restores the
+// PATH.
+func fakeSigners(t *testing.T, folder, log string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"dpkg-sig": `echo "dpkg-sig $@" >> ` + log + "\n",
+		"rpmsign":  `echo "rpmsign $@" >> ` + log + "\n",
+		"gpg":      `echo "gpg $@ $GNUPGHOME $(cat)" >> ` + log + "\n",
+	})
+}
+
+func signContext(t *testing.T, folder string, sig config.NFPMSignature) *context.Context {
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0755))
+	var binPath = filepath.Join(dist, "mybin")
+	require.NoError(t, ioutil.WriteFile(binPath, []byte("mybin"), 0755))
+	var ctx = context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		NFPMs: []config.NFPM{{
+			Builds:     []string{"default"},
+			Formats:    []string{"deb", "rpm", "apk"},
+			Maintainer: "me@me",
+			Signature:  sig,
+		}},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Env = map[string]string{"GPG_KEY": "secret key", "GPG_PASSPHRASE": "secret"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   binPath,
+		Goarch: "amd64",
+		Goos:   "linux",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"ID": "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestDefaultSignatureWithoutKeyID(t *testing.T) {
+	var ctx = context.New(config.Project{
+		NFPMs: []config.NFPM{{
+			Signature: config.NFPMSignature{KeyFile: "key.gpg"},
+		}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "nfpm default: signature.key_id is required to sign packages")
+}
+
+func TestRunPipeSign(t *testing.T) {
+	folder, err := ioutil.TempDir("", "nfpmsign")
+	require.NoError(t, err)
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeSigners(t, folder, log)()
+	var ctx = signContext(t, folder, config.NFPMSignature{KeyID: "ABCD1234"})
+	require.NoError(t, Pipe{}.Run(ctx))
+	bts, err := ioutil.ReadFile(log)
+	require.NoError(t, err)
+	var dist = ctx.Config.Dist
+	require.Contains(t, string(bts), "dpkg-sig --sign builder -k ABCD1234 "+filepath.Join(dist, "mybin_1.0.0_linux_amd64.deb")+"\n")
+	require.Contains(t, string(bts), "rpmsign --addsign --define _gpg_name ABCD1234 "+filepath.Join(dist, "mybin_1.0.0_linux_amd64.rpm")+"\n")
+	require.NotContains(t, string(bts), "gpg ")
+	require.NotContains(t, string(bts), ".apk")
+}
+
+func TestRunPipeSignImportedKey(t *testing.T) {
+	folder, err := ioutil.TempDir("", "nfpmsign")
+	require.NoError(t, err)
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeSigners(t, folder, log)()
+	var ctx = signContext(t, folder, config.NFPMSignature{
+		KeyID:      "ABCD1234",
+		Key:        "{{ .Env.GPG_KEY }}",
+		Passphrase: "{{ .Env.GPG_PASSPHRASE }}",
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	bts, err := ioutil.ReadFile(log)
+	require.NoError(t, err)
+	require.Regexp(t, `gpg --batch --import .*goreleaser-nfpm-gnupg[0-9]+ secret key\n`, string(bts))
+	require.Regexp(t, `dpkg-sig --sign builder -k ABCD1234 --gpg-options --pinentry-mode loopback --passphrase-file .*/passphrase .*\.deb\n`, string(bts))
+	require.Regexp(t, `rpmsign --addsign --define _gpg_name ABCD1234 --define _gpg_sign_cmd_extra_args --pinentry-mode loopback --passphrase-file .*/passphrase .*\.rpm\n`, string(bts))
+}
+
+func TestRunPipeSignKeyFile(t *testing.T) {
+	folder, err := ioutil.TempDir("", "nfpmsign")
+	require.NoError(t, err)
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeSigners(t, folder, log)()
+	var keyFile = filepath.Join(folder, "key.gpg")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte("file key"), 0600))
+	var ctx = signContext(t, folder, config.NFPMSignature{KeyID: "ABCD1234", KeyFile: keyFile})
+	require.NoError(t, Pipe{}.Run(ctx))
+	bts, err := ioutil.ReadFile(log)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), " file key\n")
+	require.NotContains(t, string(bts), "--passphrase-file")
+}
+
+func TestRunPipeSignMissingKeyFile(t *testing.T) {
+	folder, err := ioutil.TempDir("", "nfpmsign")
+	require.NoError(t, err)
+	defer fakeSigners(t, folder, filepath.Join(folder, "tools.log"))()
+	var ctx = signContext(t, folder, config.NFPMSignature{KeyID: "ABCD1234", KeyFile: "nope.gpg"})
+	require.Contains(t, Pipe{}.Run(ctx).Error(), "failed to read the signing key")
+}
+
+func TestRunPipeSignFails(t *testing.T) {
+	folder, err := ioutil.TempDir("", "nfpmsign")
+	require.NoError(t, err)
+	defer fakeSigners(t, folder, filepath.Join(folder, "tools.log"))()
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "bin", "rpmsign"),
+		[]byte("#!/bin/sh\necho 'no secret key'\nexit 1\n"),
+		0755,
+	))
+	var ctx = signContext(t, folder, config.NFPMSignature{KeyID: "ABCD1234"})
+	require.EqualError(t, Pipe{}.Run(ctx), "failed to run rpmsign: no secret key\n")
+}
+
+func TestRunPipeSkipSign(t *testing.T) {
+	folder, err := ioutil.TempDir("", "nfpmsign")
+	require.NoError(t, err)
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeSigners(t, folder, log)()
+	var ctx = signContext(t, folder, config.NFPMSignature{KeyID: "ABCD1234"})
+	ctx.SkipSign = true
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoFileExists(t, log)
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List(), 3)
+}
@@ -0,0 +1,61 @@
+package nfpm
+
+import (
+	"strings"
+)
+
+// termuxFormat is the format of termux packages, which are deb packages of
+// android binaries installed under the termux prefix.
+const termuxFormat = "termux.deb"
+
+// termuxPrefix is the termux equivalent of /usr.
+const termuxPrefix = "/data/data/com.termux/files/usr"
+
+// termuxArch returns the termux arch of the given android platform, or an
+// empty string if it isn't supported.
+// 32 bits arm is not supported because the deb packager renames it to armhf.
+func termuxArch(platform string) string {
+	switch strings.TrimPrefix(platform, "android") {
+	case "amd64":
+		return "x86_64"
+	case "386":
+		return "i686"
+	case "arm64":
+		return "aarch64"
+	}
+	return ""
+}
+
+// termuxPath moves the given path under the termux prefix, so /usr/bin and
+// /usr/local/bin become $PREFIX/bin and /etc becomes $PREFIX/etc.
+func termuxPath(path string) string {
+	for _, dir := range []string{"/usr/local", "/usr"} {
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			return termuxPrefix + strings.TrimPrefix(path, dir)
+		}
+	}
+	return termuxPrefix + path
+}
+
+// termuxFiles returns a copy of the given files, source to destination, with
+// the destinations moved under the termux prefix.
+func termuxFiles(files map[string]string) map[string]string {
+	var result = map[string]string{}
+	for src, dst := range files {
+		result[src] = termuxPath(dst)
+	}
+	return result
+}
+
+// termuxSymlinks returns a copy of the given symlinks with the links, and
+// their absolute targets, moved under the termux prefix.
+func termuxSymlinks(symlinks map[string]string) map[string]string {
+	var result = map[string]string{}
+	for link, target := range symlinks {
+		if strings.HasPrefix(target, "/") {
+			target = termuxPath(target)
+		}
+		result[termuxPath(link)] = target
+	}
+	return result
+}
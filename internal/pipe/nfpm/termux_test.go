@@ -0,0 +1,148 @@
+package nfpm
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blakesmith/ar"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTermuxArch(t *testing.T) {
+	for platform, arch := range map[string]string{
+		"androidamd64": "x86_64",
+		"android386":   "i686",
+		"androidarm64": "aarch64",
+		"androidarm7":  "",
+		"androidmips":  "",
+	} {
+		require.Equal(t, arch, termuxArch(platform), platform)
+		require.Equal(t, arch, packageArch(termuxFormat, platform), platform)
+	}
+}
+
+func TestTermuxPath(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/usr/local/bin":       "/data/data/com.termux/files/usr/bin",
+		"/usr/bin":             "/data/data/com.termux/files/usr/bin",
+		"/usr":                 "/data/data/com.termux/files/usr",
+		"/usr/share/foo/a.txt": "/data/data/com.termux/files/usr/share/foo/a.txt",
+		"/etc/foo.conf":        "/data/data/com.termux/files/usr/etc/foo.conf",
+		"/usrfoo":              "/data/data/com.termux/files/usr/usrfoo",
+	} {
+		require.Equal(t, expected, termuxPath(path))
+	}
+}
+
+func TestRunPipeTermux(t *testing.T) {
+	folder, err := ioutil.TempDir("", "termuxtest")
+	require.NoError(t, err)
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0755))
+	var binPath = filepath.Join(dist, "mybin")
+	require.NoError(t, ioutil.WriteFile(binPath, []byte("mybin"), 0755))
+	var ctx = context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		NFPMs: []config.NFPM{
+			{
+				Bindir:      "/usr/local/bin",
+				Builds:      []string{"default"},
+				Formats:     []string{"deb", termuxFormat},
+				Description: "Some description",
+				Maintainer:  "me@me",
+				NFPMOverridables: config.NFPMOverridables{
+					NameTemplate: defaultNameTemplate,
+					Files: map[string]string{
+						"./testdata/testfile.txt": "/usr/share/testfile.txt",
+					},
+					ConfigFiles: map[string]string{
+						"./testdata/testfile.txt": "/etc/nope.conf",
+					},
+				},
+			},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	for _, goos := range []string{"linux", "android"} {
+		for _, goarch := range []string{"amd64", "arm64", "arm"} {
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name:   "mybin",
+				Path:   binPath,
+				Goarch: goarch,
+				Goarm:  map[bool]string{true: "7"}[goarch == "arm"],
+				Goos:   goos,
+				Type:   artifact.Binary,
+				Extra: map[string]interface{}{
+					"ID": "default",
+				},
+			})
+		}
+	}
+	require.NoError(t, Pipe{}.Run(ctx))
+	var names []string
+	for _, pkg := range ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List() {
+		names = append(names, pkg.Name)
+	}
+	require.ElementsMatch(t, []string{
+		"mybin_1.0.0_linux_amd64.deb",
+		"mybin_1.0.0_linux_arm64.deb",
+		"mybin_1.0.0_linux_armv7.deb",
+		"mybin_1.0.0_android_amd64.termux.deb",
+		"mybin_1.0.0_android_arm64.termux.deb",
+	}, names)
+
+	require.ElementsMatch(t, []string{
+		"data/data/com.termux/files/usr/bin/mybin",
+		"data/data/com.termux/files/usr/share/testfile.txt",
+		"data/data/com.termux/files/usr/etc/nope.conf",
+	}, debFiles(t, filepath.Join(dist, "mybin_1.0.0_android_arm64.termux.deb")))
+}
+
+// debFiles returns the files in the data.tar.gz of the given deb package.
+func debFiles(t *testing.T, path string) []string {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	var deb = ar.NewReader(f)
+	for {
+		header, err := deb.Next()
+		require.NoError(t, err)
+		if header.Name != "data.tar.gz" {
+			continue
+		}
+		gz, err := gzip.NewReader(deb)
+		require.NoError(t, err)
+		var files []string
+		var data = tar.NewReader(gz)
+		for {
+			header, err := data.Next()
+			if err == io.EOF {
+				return files
+			}
+			require.NoError(t, err)
+			if header.Typeflag == tar.TypeReg {
+				files = append(files, header.Name)
+			}
+		}
+	}
+}
+
+func TestTermuxSymlinks(t *testing.T) {
+	require.Equal(t, map[string]string{
+		"/data/data/com.termux/files/usr/bin/foo": "/data/data/com.termux/files/usr/lib/foo/foo",
+		"/data/data/com.termux/files/usr/bin/bar": "foo",
+	}, termuxSymlinks(map[string]string{
+		"/usr/bin/foo": "/usr/lib/foo/foo",
+		"/usr/bin/bar": "foo",
+	}))
+}
@@ -0,0 +1,2 @@
+#!/bin/sh
+echo "installed {{ .ProjectName }} {{ .Version }}"
\ No newline at end of file
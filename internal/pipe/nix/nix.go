@@ -0,0 +1,190 @@
+// Package nix provides a Pipe that generates a nix derivation building the
+// released source archive and pushes it to a NUR or nixpkgs-style repository.
+package nix
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoSourceArchive happens when there is no source archive to build
+var ErrNoSourceArchive = errors.New("no source archive found, please enable it in the source section")
+
+// Pipe for nix deployment
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "nix derivations"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Nix {
+		var nix = &ctx.Config.Nix[i]
+		if nix.Name == "" {
+			nix.Name = ctx.Config.ProjectName
+		}
+		if nix.Path == "" {
+			nix.Path = "pkgs/" + nix.Name + "/default.nix"
+		}
+		if nix.Description == "" {
+			nix.Description = ctx.Config.Metadata.Description
+		}
+		if nix.Homepage == "" {
+			nix.Homepage = ctx.Config.Metadata.Homepage
+		}
+		if nix.License == "" {
+			nix.License = ctx.Config.Metadata.License
+		}
+		if nix.CommitAuthor.Name == "" {
+			nix.CommitAuthor.Name = "goreleaserbot"
+		}
+		if nix.CommitAuthor.Email == "" {
+			nix.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+		}
+	}
+	return nil
+}
+
+// Publish the nix derivations
+func (Pipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.Nix) == 0 {
+		return pipe.Skip("nix section is not configured")
+	}
+	client, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	for _, nix := range ctx.Config.Nix {
+		ok, err := condition.Check(ctx, nix.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("nix", nix.Name).Info("skipped because its condition is false")
+			continue
+		}
+		if err := doRun(ctx, nix, client); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, nix config.Nix, client client.Client) error {
+	if nix.Repository.Name == "" {
+		return pipe.Skip("nix.repository is not set")
+	}
+	var archives = ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableSourceArchive)).List()
+	if len(archives) == 0 {
+		return ErrNoSourceArchive
+	}
+
+	data, err := dataFor(ctx, nix, archives[0])
+	if err != nil {
+		return err
+	}
+	content, err := buildDerivation(ctx, data)
+	if err != nil {
+		return err
+	}
+	var path = filepath.Join(ctx.Config.Dist, nix.Name+".nix")
+	log.WithField("derivation", path).Info("writing")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(nix.SkipUpload) == "true" {
+		return pipe.Skip("nix.skip_upload is set")
+	}
+	if ctx.SkipPublish {
+		return pipe.ErrSkipPublishEnabled
+	}
+	if ctx.Config.Release.Draft {
+		return pipe.Skip("release is marked as draft")
+	}
+	if ctx.Config.Release.Disable {
+		return pipe.Skip("release is disabled")
+	}
+	if strings.TrimSpace(nix.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping nix publish")
+	}
+
+	log.WithField("derivation", nix.Path).
+		WithField("repo", nix.Repository.String()).
+		Info("pushing")
+	var msg = fmt.Sprintf("Nix derivation update for %s version %s", ctx.Config.ProjectName, ctx.Git.CurrentTag)
+	if err := client.CreateFile(ctx, nix.CommitAuthor, nix.Repository, []byte(content), nix.Path, msg); err != nil {
+		return err
+	}
+	ctx.CommittedFiles = append(ctx.CommittedFiles, context.CommittedFile{
+		Repo:         nix.Repository,
+		Path:         nix.Path,
+		CommitAuthor: nix.CommitAuthor,
+	})
+	return nil
+}
+
+func buildDerivation(ctx *context.Context, data templateData) (string, error) {
+	t, err := template.New(data.Name).
+		Funcs(template.FuncMap{"quote": quote}).
+		Parse(derivationTemplate)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return tmpl.New(ctx).Apply(out.String())
+}
+
+func dataFor(ctx *context.Context, cfg config.Nix, archive *artifact.Artifact) (templateData, error) {
+	sum, err := archive.Checksum("sha256")
+	if err != nil {
+		return templateData{}, err
+	}
+	if cfg.URLTemplate == "" {
+		urlTemplate, err := client.DownloadURLTemplate(ctx)
+		if err != nil {
+			return templateData{}, err
+		}
+		cfg.URLTemplate = urlTemplate
+	}
+	url, err := tmpl.New(ctx).WithArtifact(archive, map[string]string{}).Apply(cfg.URLTemplate)
+	if err != nil {
+		return templateData{}, err
+	}
+	return templateData{
+		Name:        cfg.Name,
+		Version:     ctx.Version,
+		URL:         url,
+		SHA256:      sum,
+		Prefixed:    ctx.Config.Source.PrefixTemplate != "",
+		VendorHash:  cfg.VendorHash,
+		SubPackages: cfg.SubPackages,
+		Ldflags:     cfg.Ldflags,
+		Desc:        cfg.Description,
+		Homepage:    cfg.Homepage,
+		License:     cfg.License,
+	}, nil
+}
+
+// quote returns the given string as a nix string literal
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "${", `\${`).Replace(s) + `"`
+}
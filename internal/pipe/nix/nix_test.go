@@ -0,0 +1,223 @@
+package nix
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update .golden files")
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Metadata: config.Metadata{
+			Description: "A foo",
+			Homepage:    "https://example.com",
+			License:     "MIT",
+		},
+		Nix: []config.Nix{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Nix{
+		Name:        "foo",
+		Path:        "pkgs/foo/default.nix",
+		Description: "A foo",
+		Homepage:    "https://example.com",
+		License:     "MIT",
+		CommitAuthor: config.CommitAuthor{
+			Name:  "goreleaserbot",
+			Email: "goreleaser@carlosbecker.com",
+		},
+	}, ctx.Config.Nix[0])
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Publish(context.New(config.Project{})))
+}
+
+func TestQuote(t *testing.T) {
+	require.Equal(t, `"a \"b\" \\ \${c} $d"`, quote(`a "b" \ ${c} $d`))
+}
+
+func nixContext(t *testing.T, folder string, nix config.Nix) *context.Context {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        folder,
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "foo", Name: "bar"},
+			GitLab: config.Repo{Owner: "foo", Name: "bar"},
+		},
+		GitHubURLs: config.GitHubURLs{Download: "https://github.com"},
+		GitLabURLs: config.GitLabURLs{Download: "https://gitlab.com"},
+		Nix:        []config.Nix{nix},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	var path = filepath.Join(folder, "foo-1.0.0.tar.gz")
+	require.NoError(t, ioutil.WriteFile(path, []byte("source"), 0644))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableSourceArchive,
+		Name: "foo-1.0.0.tar.gz",
+		Path: path,
+		Extra: map[string]interface{}{
+			"Format": "tar.gz",
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestRunPipe(t *testing.T) {
+	for name, tt := range map[string]struct {
+		nix    config.Nix
+		prefix string
+		gitlab bool
+	}{
+		"default": {
+			nix: config.Nix{
+				Repository:  config.Repo{Owner: "foo", Name: "nur"},
+				Description: "A foo",
+				Homepage:    "https://example.com",
+				License:     "MIT",
+			},
+		},
+		"full": {
+			nix: config.Nix{
+				Name:        "foo-cli",
+				Repository:  config.Repo{Owner: "foo", Name: "nur"},
+				Description: `A "foo" for ${bar}`,
+				VendorHash:  "sha256-AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+				SubPackages: []string{"cmd/foo"},
+				Ldflags:     []string{"-s", "-w", "-X main.version={{ .Version }}"},
+			},
+			prefix: "foo-{{ .Version }}/",
+		},
+		"gitlab": {
+			nix: config.Nix{
+				Repository: config.Repo{Owner: "foo", Name: "nur"},
+			},
+			gitlab: true,
+		},
+		"url_template": {
+			nix: config.Nix{
+				Repository:  config.Repo{Owner: "foo", Name: "nur"},
+				URLTemplate: "https://dl.example.com/{{ .Tag }}/{{ .ArtifactName }}",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder, err := ioutil.TempDir("", "nixtest")
+			require.NoError(t, err)
+			var ctx = nixContext(t, folder, tt.nix)
+			ctx.Config.Source.PrefixTemplate = tt.prefix
+			if tt.gitlab {
+				ctx.TokenType = context.TokenTypeGitLab
+				ctx.Artifacts.List()[0].Extra["ArtifactUploadHash"] = "820ead5d9d2266c728dce6d4d55b6460"
+			}
+			var client = &DummyClient{}
+			require.NoError(t, doRun(ctx, ctx.Config.Nix[0], client))
+			require.True(t, client.CreatedFile)
+			require.Equal(t, ctx.Config.Nix[0].Path, client.Path)
+
+			var golden = "testdata/" + name + ".nix.golden"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(golden, []byte(client.Content), 0655))
+			}
+			bts, err := ioutil.ReadFile(golden)
+			require.NoError(t, err)
+			require.Equal(t, string(bts), client.Content)
+
+			dist, err := ioutil.ReadFile(filepath.Join(folder, ctx.Config.Nix[0].Name+".nix"))
+			require.NoError(t, err)
+			require.Equal(t, string(bts), string(dist))
+			require.Len(t, ctx.CommittedFiles, 1)
+		})
+	}
+}
+
+func TestRunPipeNoSourceArchive(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Nix: []config.Nix{{Repository: config.Repo{Owner: "foo", Name: "nur"}}},
+	})
+	var client = &DummyClient{}
+	require.Equal(t, ErrNoSourceArchive, doRun(ctx, ctx.Config.Nix[0], client))
+	require.False(t, client.CreatedFile)
+}
+
+func TestRunPipeNoRepository(t *testing.T) {
+	var client = &DummyClient{}
+	testlib.AssertSkipped(t, doRun(context.New(config.Project{}), config.Nix{}, client))
+	require.False(t, client.CreatedFile)
+}
+
+func TestRunPipeTokenTypeNotImplemented(t *testing.T) {
+	folder, err := ioutil.TempDir("", "nixtest")
+	require.NoError(t, err)
+	var ctx = nixContext(t, folder, config.Nix{Repository: config.Repo{Owner: "foo", Name: "nur"}})
+	ctx.TokenType = ""
+	require.Equal(t, client.ErrTokenTypeNotImplemented, doRun(ctx, ctx.Config.Nix[0], &DummyClient{}))
+}
+
+func TestRunPipeSkipPublish(t *testing.T) {
+	for name, setup := range map[string]func(ctx *context.Context){
+		"skip_upload": func(ctx *context.Context) {
+			ctx.Config.Nix[0].SkipUpload = "true"
+		},
+		"auto": func(ctx *context.Context) {
+			ctx.Config.Nix[0].SkipUpload = "auto"
+			ctx.Semver.Prerelease = "beta1"
+		},
+		"skip_publish": func(ctx *context.Context) {
+			ctx.SkipPublish = true
+		},
+		"draft": func(ctx *context.Context) {
+			ctx.Config.Release.Draft = true
+		},
+		"disabled": func(ctx *context.Context) {
+			ctx.Config.Release.Disable = true
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder, err := ioutil.TempDir("", "nixtest")
+			require.NoError(t, err)
+			var ctx = nixContext(t, folder, config.Nix{Repository: config.Repo{Owner: "foo", Name: "nur"}})
+			setup(ctx)
+			var client = &DummyClient{}
+			testlib.AssertSkipped(t, doRun(ctx, ctx.Config.Nix[0], client))
+			require.False(t, client.CreatedFile)
+			require.FileExists(t, filepath.Join(folder, "foo.nix"))
+		})
+	}
+}
+
+type DummyClient struct {
+	CreatedFile bool
+	Content     string
+	Path        string
+}
+
+func (client *DummyClient) CreateRelease(ctx *context.Context, body string) (releaseID string, err error) {
+	return
+}
+
+func (client *DummyClient) CreateFile(ctx *context.Context, commitAuthor config.CommitAuthor, repo config.Repo, content []byte, path, msg string) (err error) {
+	client.CreatedFile = true
+	client.Content = string(content)
+	client.Path = path
+	return
+}
+
+func (client *DummyClient) Upload(ctx *context.Context, releaseID string, artifact *artifact.Artifact, file *os.File) (err error) {
+	return
+}
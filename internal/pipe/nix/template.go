@@ -0,0 +1,62 @@
+package nix
+
+type templateData struct {
+	Name        string
+	Version     string
+	URL         string
+	SHA256      string
+	Prefixed    bool
+	VendorHash  string
+	SubPackages []string
+	Ldflags     []string
+	Desc        string
+	Homepage    string
+	License     string
+}
+
+const derivationTemplate = `# This file was generated by GoReleaser. DO NOT EDIT.
+{ lib, buildGoModule, fetchurl }:
+
+buildGoModule rec {
+  pname = {{ quote .Name }};
+  version = {{ quote .Version }};
+
+  src = fetchurl {
+    url = {{ quote .URL }};
+    sha256 = {{ quote .SHA256 }};
+  };
+{{- if not .Prefixed }}
+  sourceRoot = ".";
+{{- end }}
+
+  vendorHash = {{ if .VendorHash }}{{ quote .VendorHash }}{{ else }}null{{ end }};
+{{- with .SubPackages }}
+
+  subPackages = [
+{{- range . }}
+    {{ quote . }}
+{{- end }}
+  ];
+{{- end }}
+{{- with .Ldflags }}
+
+  ldflags = [
+{{- range . }}
+    {{ quote . }}
+{{- end }}
+  ];
+{{- end }}
+
+  meta = {
+{{- if .Desc }}
+    description = {{ quote .Desc }};
+{{- end }}
+{{- if .Homepage }}
+    homepage = {{ quote .Homepage }};
+{{- end }}
+{{- if .License }}
+    license = lib.getLicenseFromSpdxId {{ quote .License }};
+{{- end }}
+  };
+}
+`
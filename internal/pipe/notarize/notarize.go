@@ -0,0 +1,198 @@
+// Package notarize provides a Pipe that codesigns the darwin binaries and
+// notarizes them with Apple, before they are archived.
+package notarize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/archive/zip"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoIdentity happens when a notarize entry has no codesigning identity
+var ErrNoIdentity = errors.New("notarize.identity is required")
+
+// ErrStaple happens when a notarize entry asks to staple the binaries, as
+// tickets can only be stapled to apps, disk images and installer packages
+var ErrStaple = errors.New("notarize.staple: only .app, .pkg and .dmg files can be stapled, not binaries")
+
+// Pipe for codesigning and notarization
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "codesigning and notarizing darwin binaries"
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.Notarize) == 0 {
+		return pipe.Skip("notarize section is not configured")
+	}
+	if ctx.SkipSign {
+		return pipe.ErrSkipSignEnabled
+	}
+	var g = semerrgroup.New(ctx.Parallelism)
+	for _, cfg := range ctx.Config.Notarize {
+		cfg := cfg
+		if cfg.Identity == "" {
+			return ErrNoIdentity
+		}
+		if cfg.Staple {
+			return ErrStaple
+		}
+		ok, err := condition.Check(ctx, cfg.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("ids", cfg.IDs).Info("skipped because its condition is false")
+			continue
+		}
+		var filters = []artifact.Filter{
+			artifact.ByType(artifact.Binary),
+			artifact.ByGoos("darwin"),
+		}
+		if len(cfg.IDs) > 0 {
+			filters = append(filters, artifact.ByIDs(cfg.IDs...))
+		}
+		for _, binary := range ctx.Artifacts.Filter(artifact.And(filters...)).List() {
+			binary := binary
+			g.Go(func() error {
+				return notarize(ctx, cfg, binary)
+			})
+		}
+	}
+	return g.Wait()
+}
+
+// notarize codesigns the binary and, when credentials are given, submits it
+// to the notary service, waiting for it to be accepted.
+func notarize(ctx *context.Context, cfg config.Notarize, binary *artifact.Artifact) error {
+	var template = tmpl.New(ctx).WithArtifact(binary, map[string]string{})
+	var fields = []*string{
+		&cfg.Identity,
+		&cfg.Entitlements,
+		&cfg.KeychainProfile,
+		&cfg.Key,
+		&cfg.KeyID,
+		&cfg.Issuer,
+	}
+	for _, field := range fields {
+		s, err := template.Apply(*field)
+		if err != nil {
+			return errors.Wrap(err, "notarize: failed to execute template")
+		}
+		*field = s
+	}
+
+	log.WithField("binary", binary.Path).Info("codesigning")
+	var args = []string{"--force", "--timestamp", "--options", "runtime", "--sign", cfg.Identity}
+	if cfg.Entitlements != "" {
+		args = append(args, "--entitlements", cfg.Entitlements)
+	}
+	if err := run(ctx, "codesign", append(args, binary.Path)...); err != nil {
+		return err
+	}
+
+	args = credentials(cfg)
+	if len(args) == 0 {
+		log.WithField("binary", binary.Path).Debug("no notary credentials, skipping notarization")
+		return nil
+	}
+	log.WithField("binary", binary.Path).Info("notarizing")
+	return submit(ctx, cfg, binary, args)
+}
+
+// credentials returns the notarytool arguments of either the App Store
+// Connect API key or the keychain profile, if any. The Apple ID password
+// is only ever read from the keychain, so it doesn't show up in the
+// process list.
+func credentials(cfg config.Notarize) []string {
+	if cfg.Key != "" {
+		return []string{"--key", cfg.Key, "--key-id", cfg.KeyID, "--issuer", cfg.Issuer}
+	}
+	if cfg.KeychainProfile != "" {
+		return []string{"--keychain-profile", cfg.KeychainProfile}
+	}
+	return nil
+}
+
+// submit zips the binary, as the notary service only accepts zips, disk
+// images and packages, and submits it.
+func submit(ctx *context.Context, cfg config.Notarize, binary *artifact.Artifact, credentials []string) error {
+	dir, err := ioutil.TempDir("", "notarize")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+	var path = filepath.Join(dir, binary.Name+".zip")
+	if err := zipBinary(path, binary); err != nil {
+		return errors.Wrap(err, "notarize: failed to zip binary")
+	}
+
+	var args = append([]string{"notarytool", "submit", path, "--wait", "--output-format", "json"}, credentials...)
+	if cfg.Timeout != "" {
+		args = append(args, "--timeout", cfg.Timeout)
+	}
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "xcrun", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("notarize: failed to submit %s: %s", binary.Name, string(out))
+	}
+	var result struct {
+		ID      string `json:"id"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	}
+	// the json result is the last thing notarytool outputs, after the
+	// progress of the upload
+	if i := bytes.LastIndex(out, []byte("\n{")); i >= 0 {
+		out = out[i+1:]
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return errors.Wrapf(err, "notarize: failed to parse notarytool output: %s", string(out))
+	}
+	if result.Status != "Accepted" {
+		return fmt.Errorf("notarize: submission %s of %s is %s: %s", result.ID, binary.Name, result.Status, result.Message)
+	}
+	log.WithField("binary", binary.Path).WithField("id", result.ID).Info("notarized")
+	return nil
+}
+
+func zipBinary(path string, binary *artifact.Artifact) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close() // nolint: errcheck
+	var z = zip.New(file)
+	if err := z.Add(binary.Name, binary.Path); err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+func run(ctx *context.Context, name string, args ...string) error {
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, name, args...)
+	log.WithField("cmd", cmd.Args).Debug("running")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notarize: %s failed with %q", name, string(out))
+	}
+	return nil
+}
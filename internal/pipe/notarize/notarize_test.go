@@ -0,0 +1,212 @@
+package notarize
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipNotConfigured(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+func TestSkipSign(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Notarize: []config.Notarize{{Identity: "foo"}},
+	})
+	ctx.SkipSign = true
+	require.Equal(t, pipe.ErrSkipSignEnabled, Pipe{}.Run(ctx))
+}
+
+func TestNoIdentity(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Notarize: []config.Notarize{{}},
+	})
+	require.Equal(t, ErrNoIdentity, Pipe{}.Run(ctx))
+}
+
+func TestStaple(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Notarize: []config.Notarize{{Identity: "foo", Staple: true}},
+	})
+	require.Equal(t, ErrStaple, Pipe{}.Run(ctx))
+}
+
+// fakeTools puts fake codesign and xcrun commands in the PATH, which log
+// their arguments to calls.log. xcrun replies to notarytool with the given
+// status.
+func fakeTools(t *testing.T, folder, status string) func() {
+	var log = filepath.Join(folder, "calls.log")
+	return testlib.FakeTools(t, folder, map[string]string{
+		"codesign": "echo \"codesign $*\" >> " + log + "\n",
+		"xcrun": "echo \"xcrun $*\" | sed 's|[^ ]*/\\([^/ ]*\\.zip\\)|\\1|' >> " + log + "\n" +
+			"if [ \"$1\" = notarytool ]; then\n" +
+			"  [ -f \"$3\" ] || { echo no such file; exit 1; }\n" +
+			"  echo 'Conducting pre-submission checks' >&2\n" +
+			"  echo '{\"id\":\"123\",\"message\":\"done\",\"status\":\"" + status + "\"}'\n" +
+			"fi\n",
+	})
+}
+
+func addBinaries(t *testing.T, ctx *context.Context, folder string) {
+	for _, a := range []struct {
+		id, goos, name string
+	}{
+		{"foo", "darwin", "foo"},
+		{"bar", "darwin", "bar"},
+		{"foo", "windows", "foo.exe"},
+	} {
+		var path = filepath.Join(folder, a.goos+"_"+a.name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(a.name), 0755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:  a.name,
+			Path:  path,
+			Goos:  a.goos,
+			Type:  artifact.Binary,
+			Extra: map[string]interface{}{"ID": a.id},
+		})
+	}
+}
+
+func calls(t *testing.T, folder string) []string {
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "calls.log"))
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(bts)), "\n")
+}
+
+func TestRunPipe(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, "Accepted")()
+
+	var ctx = context.New(config.Project{
+		Notarize: []config.Notarize{
+			{
+				IDs:             []string{"foo"},
+				Identity:        "{{ .Env.IDENTITY }}",
+				Entitlements:    "entitlements.plist",
+				KeychainProfile: "{{ .Env.PROFILE }}",
+				Timeout:         "1h",
+			},
+		},
+	})
+	ctx.Env = map[string]string{
+		"IDENTITY": "Developer ID Application: Foo",
+		"PROFILE":  "goreleaser",
+	}
+	addBinaries(t, ctx, folder)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, []string{
+		"codesign --force --timestamp --options runtime --sign Developer ID Application: Foo --entitlements entitlements.plist " + filepath.Join(folder, "darwin_foo"),
+		"xcrun notarytool submit foo.zip --wait --output-format json --keychain-profile goreleaser --timeout 1h",
+	}, calls(t, folder))
+}
+
+func TestRunPipeCodesignOnly(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, "Accepted")()
+
+	var ctx = context.New(config.Project{
+		Notarize: []config.Notarize{
+			{IDs: []string{"bar"}, Identity: "foo"},
+			{IDs: []string{"foo"}, Identity: "foo", If: "false"},
+		},
+	})
+	addBinaries(t, ctx, folder)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, []string{
+		"codesign --force --timestamp --options runtime --sign foo " + filepath.Join(folder, "darwin_bar"),
+	}, calls(t, folder))
+}
+
+func TestRunPipeAPIKey(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, "Accepted")()
+
+	var ctx = context.New(config.Project{
+		Notarize: []config.Notarize{
+			{
+				IDs:      []string{"bar"},
+				Identity: "foo",
+				Key:      "AuthKey.p8",
+				KeyID:    "KEY",
+				Issuer:   "ISSUER",
+			},
+		},
+	})
+	addBinaries(t, ctx, folder)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, []string{
+		"codesign --force --timestamp --options runtime --sign foo " + filepath.Join(folder, "darwin_bar"),
+		"xcrun notarytool submit bar.zip --wait --output-format json --key AuthKey.p8 --key-id KEY --issuer ISSUER",
+	}, calls(t, folder))
+}
+
+func TestRunPipeRejected(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, "Invalid")()
+
+	var ctx = context.New(config.Project{
+		Notarize: []config.Notarize{
+			{IDs: []string{"bar"}, Identity: "foo", KeychainProfile: "goreleaser"},
+		},
+	})
+	addBinaries(t, ctx, folder)
+	require.EqualError(t, Pipe{}.Run(ctx), "notarize: submission 123 of bar is Invalid: done")
+}
+
+func TestRunPipeSubmitFailed(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, "Accepted")()
+
+	var ctx = context.New(config.Project{
+		Notarize: []config.Notarize{
+			{IDs: []string{"bar"}, Identity: "foo", KeychainProfile: "goreleaser"},
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:  "bar",
+		Path:  filepath.Join(folder, "bar"),
+		Goos:  "darwin",
+		Type:  artifact.Binary,
+		Extra: map[string]interface{}{"ID": "bar"},
+	})
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "bin", "xcrun"),
+		[]byte("#!/bin/sh\necho Error: no keychain profile >&2\nexit 1\n"),
+		0755,
+	))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "bar"), []byte("bar"), 0755))
+	require.EqualError(t, Pipe{}.Run(ctx), "notarize: failed to submit bar: Error: no keychain profile\n")
+}
+
+func TestRunPipeCodesignFailed(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, "Accepted")()
+
+	var ctx = context.New(config.Project{
+		Notarize: []config.Notarize{
+			{IDs: []string{"bar"}, Identity: "foo"},
+		},
+	})
+	addBinaries(t, ctx, folder)
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "bin", "codesign"),
+		[]byte("#!/bin/sh\necho no identity\nexit 1\n"),
+		0755,
+	))
+	require.EqualError(t, Pipe{}.Run(ctx), `notarize: codesign failed with "no identity\n"`)
+}
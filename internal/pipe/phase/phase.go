@@ -0,0 +1,55 @@
+// Package phase provides the pipes that run the user defined hooks of the
+// named phases of the release, e.g. after the build or before the publishing.
+package phase
+
+import (
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/pipe/before"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// nolint: gochecknoglobals
+var (
+	// AfterBuild runs once all binaries are built
+	AfterBuild = Pipe{name: "after_build"}
+	// BeforeArchive runs before the archives and packages are created
+	BeforeArchive = Pipe{name: "before_archive"}
+	// AfterSign runs once the checksums and signatures are created
+	AfterSign = Pipe{name: "after_sign"}
+	// BeforePublish runs before anything is published
+	BeforePublish = Pipe{name: "before_publish", publish: true}
+	// AfterPublish runs once everything is published
+	AfterPublish = Pipe{name: "after_publish", publish: true}
+)
+
+// nolint: gochecknoglobals
+var hooks = map[string]func(config.Phases) []string{
+	AfterBuild.name:    func(p config.Phases) []string { return p.AfterBuild },
+	BeforeArchive.name: func(p config.Phases) []string { return p.BeforeArchive },
+	AfterSign.name:     func(p config.Phases) []string { return p.AfterSign },
+	BeforePublish.name: func(p config.Phases) []string { return p.BeforePublish },
+	AfterPublish.name:  func(p config.Phases) []string { return p.AfterPublish },
+}
+
+// Pipe that runs the hooks of a phase
+type Pipe struct {
+	name    string
+	publish bool
+}
+
+func (p Pipe) String() string {
+	return "running " + p.name + " hooks"
+}
+
+// Run executes the hooks of the phase
+func (p Pipe) Run(ctx *context.Context) error {
+	var steps = hooks[p.name](ctx.Config.Phases)
+	if len(steps) == 0 {
+		return pipe.Skip("no " + p.name + " hooks")
+	}
+	if p.publish && ctx.SkipPublish {
+		return pipe.ErrSkipPublishEnabled
+	}
+	return before.RunHooks(ctx, steps)
+}
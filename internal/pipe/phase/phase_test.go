@@ -0,0 +1,59 @@
+package phase
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipNoHooks(t *testing.T) {
+	for _, p := range []Pipe{AfterBuild, BeforeArchive, AfterSign, BeforePublish, AfterPublish} {
+		testlib.AssertSkipped(t, p.Run(context.New(config.Project{})))
+	}
+}
+
+func TestRunPhases(t *testing.T) {
+	var folder, err = ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	var ctx = context.New(config.Project{
+		Phases: config.Phases{
+			AfterBuild:    []string{"touch " + filepath.Join(folder, "after_build")},
+			BeforeArchive: []string{"touch " + filepath.Join(folder, "before_archive")},
+			AfterSign:     []string{"touch " + filepath.Join(folder, "after_sign")},
+			BeforePublish: []string{"touch " + filepath.Join(folder, "before_publish")},
+			AfterPublish:  []string{"touch {{ .Env.FOLDER }}/after_publish"},
+		},
+	})
+	ctx.Env = map[string]string{"FOLDER": folder}
+	for _, p := range []Pipe{AfterBuild, BeforeArchive, AfterSign, BeforePublish, AfterPublish} {
+		require.NoError(t, p.Run(ctx))
+		require.FileExists(t, filepath.Join(folder, p.name))
+	}
+}
+
+func TestRunPhaseFail(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Phases: config.Phases{
+			AfterSign: []string{"go tool foobar"},
+		},
+	})
+	require.Error(t, AfterSign.Run(ctx))
+}
+
+func TestSkipPublish(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Phases: config.Phases{
+			AfterBuild:   []string{"go version"},
+			AfterPublish: []string{"go tool foobar"},
+		},
+	})
+	ctx.SkipPublish = true
+	require.NoError(t, AfterBuild.Run(ctx))
+	require.Equal(t, pipe.ErrSkipPublishEnabled, AfterPublish.Run(ctx))
+}
@@ -1,6 +1,13 @@
 // Package pipe provides generic erros for pipes to use.
 package pipe
 
+import (
+	"fmt"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
 // ErrSnapshotEnabled happens when goreleaser is running in snapshot mode.
 // It usually means that publishing and maybe some validations were skipped.
 var ErrSnapshotEnabled = Skip("disabled during snapshot mode")
@@ -23,9 +30,17 @@ func IsSkip(err error) bool {
 	return ok
 }
 
+// IsMisconfigured returns true if the error is an ErrSkip created with
+// Misconfigured
+func IsMisconfigured(err error) bool {
+	skip, ok := err.(ErrSkip)
+	return ok && skip.misconfigured
+}
+
 // ErrSkip occurs when a pipe is skipped for some reason
 type ErrSkip struct {
-	reason string
+	reason        string
+	misconfigured bool
 }
 
 // Error implements the error interface. returns the reason the pipe was skipped
@@ -37,3 +52,18 @@ func (e ErrSkip) Error() string {
 func Skip(reason string) ErrSkip {
 	return ErrSkip{reason: reason}
 }
+
+// Misconfigured skips this pipe because its configuration is incomplete, like
+// a missing token. In strict mode, it fails the release instead.
+func Misconfigured(reason string) ErrSkip {
+	return ErrSkip{reason: reason, misconfigured: true}
+}
+
+// Warn logs the given warning, or returns it as an error in strict mode.
+func Warn(ctx *context.Context, warning string) error {
+	if ctx.Config.Strict {
+		return fmt.Errorf("strict mode: %s", warning)
+	}
+	log.Warn(warning)
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,3 +20,19 @@ func TestIsSkip(t *testing.T) {
 	assert.True(t, IsSkip(Skip("whatever")))
 	assert.False(t, IsSkip(errors.New("nope")))
 }
+
+func TestIsMisconfigured(t *testing.T) {
+	assert.True(t, IsMisconfigured(Misconfigured("missing token")))
+	assert.True(t, IsSkip(Misconfigured("missing token")))
+	assert.False(t, IsMisconfigured(Skip("whatever")))
+	assert.False(t, IsMisconfigured(errors.New("nope")))
+}
+
+func TestWarn(t *testing.T) {
+	assert.NoError(t, Warn(context.New(config.Project{}), "careful"))
+	assert.EqualError(
+		t,
+		Warn(context.New(config.Project{Strict: true}), "careful"),
+		"strict mode: careful",
+	)
+}
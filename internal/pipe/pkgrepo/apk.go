@@ -0,0 +1,212 @@
+package pkgrepo
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoApk is shown when apk cannot be found in $PATH
+var ErrNoApk = errors.New("apk not present in $PATH")
+
+// ErrNoAbuildSign is shown when abuild-sign cannot be found in $PATH
+var ErrNoAbuildSign = errors.New("abuild-sign not present in $PATH")
+
+// apkArchs are the alpine archs of the go ones, as mapped by the apk packager
+var apkArchs = map[string]string{
+	"386":   "x86",
+	"amd64": "x86_64",
+	"arm":   "armhf",
+	"arm64": "aarch64",
+}
+
+// publishApk uploads the given apk packages to the folders of their archs in
+// the apk repository at root, and regenerates the APKINDEX.tar.gz of these
+// folders with apk index, signing them with abuild-sign.
+// apk index needs every package of the folder, so the folder is downloaded
+// first.
+func publishApk(ctx *context.Context, repo config.PackageRepo, b bucket, root string, apks []*artifact.Artifact) error {
+	if _, err := exec.LookPath("apk"); err != nil {
+		return ErrNoApk
+	}
+	key, err := tmpl.New(ctx).Apply(repo.ApkKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to template the apk key")
+	}
+	if ctx.SkipSign {
+		key = ""
+	}
+	if key != "" {
+		if _, err := exec.LookPath("abuild-sign"); err != nil {
+			return ErrNoAbuildSign
+		}
+		// abuild-sign runs in the folder of the index
+		if key, err = filepath.Abs(key); err != nil {
+			return err
+		}
+	}
+	var byArch = map[string][]*artifact.Artifact{}
+	for _, apk := range apks {
+		var arch = apk.Goarch
+		if alpine, ok := apkArchs[arch]; ok {
+			arch = alpine
+		}
+		byArch[arch] = append(byArch[arch], apk)
+	}
+	var archs []string
+	for arch := range byArch {
+		archs = append(archs, arch)
+	}
+	sort.Strings(archs)
+	for _, arch := range archs {
+		if err := publishApkArch(ctx, repo, b, path.Join(root, arch), arch, key, byArch[arch]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishApkArch(ctx *context.Context, repo config.PackageRepo, b bucket, root, arch, key string, apks []*artifact.Artifact) error {
+	dir, err := ioutil.TempDir("", "goreleaser-apk")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	keys, err := b.list(ctx, root+"/")
+	if err != nil {
+		return err
+	}
+	log.WithField("provider", b.url).WithField("arch", arch).WithField("files", len(keys)).Info("downloading apk repository")
+	var packages = map[string]string{}
+	for _, key := range keys {
+		var name = strings.TrimPrefix(key, root+"/")
+		if !strings.HasSuffix(name, ".apk") || strings.Contains(name, "/") {
+			continue
+		}
+		if err := b.download(ctx, key, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+		packages[name] = ""
+	}
+	// apk fetches the packages as <name>-<version>.apk, whatever their
+	// name in the release
+	for _, apk := range apks {
+		name, err := apkFileName(apk.Path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", apk.Path)
+		}
+		if err := copyFile(apk.Path, filepath.Join(dir, name)); err != nil {
+			return errors.Wrapf(err, "failed to copy %s", apk.Path)
+		}
+		packages[name] = apk.Path
+	}
+	var names []string
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var args = []string{
+		"index",
+		"--allow-untrusted",
+		"--rewrite-arch", arch,
+		"--description", repo.Label,
+		"--output", "APKINDEX.tar.gz",
+	}
+	if err := runIn(ctx, dir, "apk", append(args, names...)...); err != nil {
+		return fmt.Errorf("failed to create apk index: %s", err.Error())
+	}
+	if key != "" {
+		if err := runIn(ctx, dir, "abuild-sign", "-k", key, "APKINDEX.tar.gz"); err != nil {
+			return fmt.Errorf("failed to sign apk index: %s", err.Error())
+		}
+	}
+
+	for _, name := range names {
+		if packages[name] == "" {
+			continue
+		}
+		if err := b.upload(ctx, path.Join(root, name), packages[name]); err != nil {
+			return err
+		}
+	}
+	// the index references the packages, so it goes last
+	return b.upload(ctx, path.Join(root, "APKINDEX.tar.gz"), filepath.Join(dir, "APKINDEX.tar.gz"))
+}
+
+// apkFileName returns the name apk expects the given package to have in the
+// repository, from the pkgname and pkgver of its .PKGINFO
+func apkFileName(file string) (string, error) {
+	f, err := os.Open(file) // #nosec
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() // nolint: errcheck
+	// the signature and control tarballs of apk packages are cut, so the
+	// gzip streams read as a single tarball
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", err
+	}
+	var tr = tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return "", fmt.Errorf(".PKGINFO not found")
+		}
+		if err != nil {
+			return "", err
+		}
+		if header.Name != ".PKGINFO" {
+			continue
+		}
+		var fields = map[string]string{}
+		var scanner = bufio.NewScanner(tr)
+		for scanner.Scan() {
+			var parts = strings.SplitN(scanner.Text(), " = ", 2)
+			if len(parts) == 2 {
+				fields[parts[0]] = parts[1]
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return "", err
+		}
+		if fields["pkgname"] == "" || fields["pkgver"] == "" {
+			return "", fmt.Errorf(".PKGINFO has no pkgname or pkgver")
+		}
+		return fields["pkgname"] + "-" + fields["pkgver"] + ".apk", nil
+	}
+}
+
+// runIn runs the given tool in dir, returning its output as the error if it
+// fails
+func runIn(ctx *context.Context, dir, tool string, args ...string) error {
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, tool, args...)
+	cmd.Dir = dir
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) == 0 {
+			return err
+		}
+		return errors.New(string(out))
+	}
+	return nil
+}
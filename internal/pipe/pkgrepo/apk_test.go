@@ -0,0 +1,177 @@
+package pkgrepo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/nfpm"
+	"github.com/goreleaser/nfpm/apk"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTools puts fake apk, abuild-sign and rsync in the PATH, which log their
+// arguments, returning a func that restores the PATH.
+// apk writes an index listing the packages, abuild-sign appends to it and
+// rsync copies the source folder into the destination one.
+func fakeTools(t *testing.T, folder, log string) func() {
+	var tools = map[string]string{}
+	for tool, script := range map[string]string{
+		"apk": `ls *.apk > APKINDEX.tar.gz`,
+		"abuild-sign": `echo signed >> APKINDEX.tar.gz
+test -f "$2"`,
+		"rsync": `for dst; do :; done
+mkdir -p "$dst"
+cp -R "$(eval echo \${$(($#-1))})." "$dst"`,
+	} {
+		tools[tool] = `echo "` + tool + ` $@" >> ` + log + "\n" + script + "\n"
+	}
+	return testlib.FakeTools(t, folder, tools)
+}
+
+func createApk(t *testing.T, folder, version, arch string) *artifact.Artifact {
+	var name = "foo_" + version + "_linux_" + arch + ".apk"
+	f, err := os.Create(filepath.Join(folder, name))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	require.NoError(t, apk.Default.Package(nfpm.WithDefaults(&nfpm.Info{
+		Name:        "foo",
+		Arch:        arch,
+		Platform:    "linux",
+		Version:     version,
+		Maintainer:  "me@me",
+		Description: "Foo",
+	}), f))
+	return &artifact.Artifact{
+		Type:   artifact.LinuxPackage,
+		Name:   name,
+		Path:   f.Name(),
+		Goos:   "linux",
+		Goarch: arch,
+		Extra:  map[string]interface{}{"ID": "default"},
+	}
+}
+
+func TestApkFileName(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	name, err := apkFileName(createApk(t, folder, "1.0.0", "amd64").Path)
+	require.NoError(t, err)
+	require.Equal(t, "foo-1.0.0.apk", name)
+
+	require.NoError(t, ioutil.WriteFile("nope.apk", []byte("nope"), 0644))
+	_, err = apkFileName("nope.apk")
+	require.Error(t, err)
+}
+
+func TestPublishApk(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	var bucket = filepath.Join(folder, "bucket")
+	var root = filepath.Join(bucket, "apk")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "x86_64"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "x86_64", "foo-0.9.0.apk"), []byte("old"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "x86_64", "APKINDEX.tar.gz"), []byte("old"), 0644))
+	require.NoError(t, ioutil.WriteFile("key.rsa", []byte("key"), 0600))
+
+	var ctx = repoContext(t, folder, config.PackageRepo{
+		Provider: "file",
+		Bucket:   bucket,
+		ApkKey:   "{{ .Env.APK_KEY }}",
+	})
+	ctx.Env["APK_KEY"] = "key.rsa"
+	ctx.Artifacts.Add(createApk(t, folder, "1.0.0", "amd64"))
+	ctx.Artifacts.Add(createApk(t, folder, "1.0.0", "arm64"))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	require.FileExists(t, filepath.Join(root, "x86_64", "foo-1.0.0.apk"))
+	require.FileExists(t, filepath.Join(root, "aarch64", "foo-1.0.0.apk"))
+	require.Equal(t, "old", readFile(t, filepath.Join(root, "x86_64", "foo-0.9.0.apk")))
+	require.Equal(t, "foo-0.9.0.apk\nfoo-1.0.0.apk\nsigned\n", readFile(t, filepath.Join(root, "x86_64", "APKINDEX.tar.gz")))
+	require.Equal(t, "foo-1.0.0.apk\nsigned\n", readFile(t, filepath.Join(root, "aarch64", "APKINDEX.tar.gz")))
+	require.Contains(t, readFile(t, log), "apk index --allow-untrusted --rewrite-arch x86_64 --description foo --output APKINDEX.tar.gz foo-0.9.0.apk foo-1.0.0.apk\n")
+	require.Contains(t, readFile(t, log), "abuild-sign -k "+filepath.Join(folder, "key.rsa")+" APKINDEX.tar.gz\n")
+}
+
+func TestPublishApkSkipSign(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	var bucket = filepath.Join(folder, "bucket")
+	require.NoError(t, os.Mkdir(bucket, 0755))
+	var ctx = repoContext(t, folder, config.PackageRepo{
+		Provider: "file",
+		Bucket:   bucket,
+		ApkKey:   "key.rsa",
+	})
+	ctx.SkipSign = true
+	ctx.Artifacts.Add(createApk(t, folder, "1.0.0", "amd64"))
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.Equal(t, "foo-1.0.0.apk\n", readFile(t, filepath.Join(bucket, "apk", "x86_64", "APKINDEX.tar.gz")))
+	require.NotContains(t, readFile(t, log), "abuild-sign")
+}
+
+func TestPublishApkFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, filepath.Join(folder, "tools.log"))()
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "bin", "apk"),
+		[]byte("#!/bin/sh\necho 'bad package'\nexit 1\n"),
+		0755,
+	))
+	var bucket = filepath.Join(folder, "bucket")
+	require.NoError(t, os.Mkdir(bucket, 0755))
+	var ctx = repoContext(t, folder, config.PackageRepo{Provider: "file", Bucket: bucket})
+	ctx.Artifacts.Add(createApk(t, folder, "1.0.0", "amd64"))
+	require.EqualError(t, Pipe{}.Publish(ctx), "failed to create apk index: bad package\n")
+}
+
+func TestPublishRsync(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	var target = filepath.Join(folder, "target")
+	require.NoError(t, os.MkdirAll(filepath.Join(target, "apk", "x86_64"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(target, "apk", "x86_64", "foo-0.9.0.apk"), []byte("old"), 0644))
+
+	var ctx = repoContext(t, folder, config.PackageRepo{Rsync: target})
+	ctx.Artifacts.Add(createApk(t, folder, "1.0.0", "amd64"))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	require.FileExists(t, filepath.Join(target, "apk", "x86_64", "foo-1.0.0.apk"))
+	require.Equal(t, "foo-0.9.0.apk\nfoo-1.0.0.apk\n", readFile(t, filepath.Join(target, "apk", "x86_64", "APKINDEX.tar.gz")))
+	var logs = readFile(t, log)
+	require.Contains(t, logs, "rsync -rlt --exclude *.attrs "+target+"/ ")
+	require.Regexp(t, "rsync -rlt --exclude \\*.attrs --delete .*goreleaser-rsync[0-9]+/ "+target+"/\n", logs)
+}
+
+func TestDefaultRsync(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = repoContext(t, folder, config.PackageRepo{Rsync: "user@example.com:/srv/repo"})
+	require.Equal(t, "stable", ctx.Config.PackageRepos[0].Distribution)
+}
+
+func TestPublishNoApk(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var bucket = filepath.Join(folder, "bucket")
+	require.NoError(t, os.Mkdir(bucket, 0755))
+	var ctx = repoContext(t, folder, config.PackageRepo{Provider: "file", Bucket: bucket})
+	ctx.Artifacts.Add(createApk(t, folder, "1.0.0", "amd64"))
+	var path = os.Getenv("PATH")
+	defer func() {
+		require.NoError(t, os.Setenv("PATH", path))
+	}()
+	require.NoError(t, os.Setenv("PATH", ""))
+	require.Equal(t, ErrNoApk, Pipe{}.Publish(ctx))
+}
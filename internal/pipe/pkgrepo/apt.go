@@ -0,0 +1,265 @@
+package pkgrepo
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"  // #nosec
+	"crypto/sha1" // #nosec
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blakesmith/ar"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// publishApt uploads the given deb packages to the pool of the apt
+// repository at root, and regenerates the indexes of its distribution:
+//
+//	pool/<component>/<letter>/<package>/<file>.deb
+//	dists/<distribution>/<component>/binary-<arch>/Packages(.gz)
+//	dists/<distribution>/Release, Release.gpg and InRelease
+func publishApt(ctx *context.Context, repo config.PackageRepo, b bucket, root string, debs []*artifact.Artifact) error {
+	var dist = path.Join("dists", repo.Distribution)
+	var added = map[string][]string{}
+	for _, deb := range debs {
+		stanza, err := debStanza(repo, deb)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", deb.Name)
+		}
+		if err := b.upload(ctx, path.Join(root, field(stanza, "Filename")), deb.Path); err != nil {
+			return err
+		}
+		var arch = field(stanza, "Architecture")
+		added[arch] = append(added[arch], stanza)
+	}
+
+	release, err := b.read(ctx, path.Join(root, dist, "Release"))
+	if err != nil {
+		return err
+	}
+	var archs = strings.Fields(field(string(release), "Architectures"))
+	for arch := range added {
+		archs = append(archs, arch)
+	}
+	archs = unique(archs)
+
+	var indexes = map[string][]byte{}
+	for _, arch := range archs {
+		var index = path.Join(repo.Component, "binary-"+arch, "Packages")
+		packages, err := b.read(ctx, path.Join(root, dist, index))
+		if err != nil {
+			return err
+		}
+		packages = mergePackages(packages, added[arch])
+		gz, err := gzipData(packages)
+		if err != nil {
+			return err
+		}
+		indexes[index] = packages
+		indexes[index+".gz"] = gz
+	}
+	for _, name := range sortedKeys(indexes) {
+		if err := b.write(ctx, path.Join(root, dist, name), indexes[name]); err != nil {
+			return err
+		}
+	}
+
+	var files = map[string][]byte{
+		"Release": []byte(releaseFile(repo, archs, indexes, time.Now())),
+	}
+	if shouldSign(ctx, repo) {
+		signature, err := sign(ctx, repo, files["Release"], false)
+		if err != nil {
+			return err
+		}
+		files["Release.gpg"] = signature
+		inRelease, err := sign(ctx, repo, files["Release"], true)
+		if err != nil {
+			return err
+		}
+		files["InRelease"] = inRelease
+	}
+	for _, name := range sortedKeys(files) {
+		if err := b.write(ctx, path.Join(root, dist, name), files[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// debStanza returns the Packages index entry of the given deb package: its
+// control file along with its pool location, size and hashes.
+func debStanza(repo config.PackageRepo, deb *artifact.Artifact) (string, error) {
+	data, err := ioutil.ReadFile(deb.Path)
+	if err != nil {
+		return "", err
+	}
+	control, err := debControl(data)
+	if err != nil {
+		return "", err
+	}
+	var name = field(control, "Package")
+	if name == "" || field(control, "Architecture") == "" {
+		return "", errors.New("control file has no package or architecture")
+	}
+	var filename = path.Join("pool", repo.Component, name[:1], name, deb.Name)
+	return strings.Join([]string{
+		strings.TrimSpace(control),
+		"Filename: " + filename,
+		fmt.Sprintf("Size: %d", len(data)),
+		"MD5sum: " + hashOf(md5.New(), data), // #nosec
+		"SHA1: " + hashOf(sha1.New(), data),  // #nosec
+		"SHA256: " + hashOf(sha256.New(), data),
+	}, "\n"), nil
+}
+
+// debControl returns the control file of the given deb package
+func debControl(deb []byte) (string, error) {
+	var r = ar.NewReader(bytes.NewReader(deb))
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			return "", errors.New("control.tar.gz not found")
+		}
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSuffix(header.Name, "/") != "control.tar.gz" {
+			continue
+		}
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return "", err
+		}
+		var t = tar.NewReader(gz)
+		for {
+			header, err := t.Next()
+			if err == io.EOF {
+				return "", errors.New("control file not found")
+			}
+			if err != nil {
+				return "", err
+			}
+			if path.Clean(header.Name) == "control" {
+				bts, err := ioutil.ReadAll(t)
+				return string(bts), err
+			}
+		}
+	}
+}
+
+// mergePackages adds the given stanzas to the Packages index, replacing the
+// entries of the same package, version and architecture.
+func mergePackages(packages []byte, added []string) []byte {
+	var stanzas []string
+	for _, stanza := range strings.Split(string(packages), "\n\n") {
+		stanza = strings.TrimSpace(stanza)
+		if stanza == "" || containsPackage(added, stanza) {
+			continue
+		}
+		stanzas = append(stanzas, stanza)
+	}
+	stanzas = append(stanzas, added...)
+	if len(stanzas) == 0 {
+		return []byte{}
+	}
+	return []byte(strings.Join(stanzas, "\n\n") + "\n")
+}
+
+func containsPackage(stanzas []string, stanza string) bool {
+	for _, s := range stanzas {
+		if field(s, "Package") == field(stanza, "Package") &&
+			field(s, "Version") == field(stanza, "Version") &&
+			field(s, "Architecture") == field(stanza, "Architecture") {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseFile returns the Release file of the distribution, with the hashes
+// of the given indexes.
+func releaseFile(repo config.PackageRepo, archs []string, indexes map[string][]byte, date time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Origin: %s\n", repo.Origin)
+	fmt.Fprintf(&sb, "Label: %s\n", repo.Label)
+	fmt.Fprintf(&sb, "Suite: %s\n", repo.Distribution)
+	fmt.Fprintf(&sb, "Codename: %s\n", repo.Distribution)
+	fmt.Fprintf(&sb, "Date: %s\n", date.UTC().Format(time.RFC1123))
+	fmt.Fprintf(&sb, "Architectures: %s\n", strings.Join(archs, " "))
+	fmt.Fprintf(&sb, "Components: %s\n", repo.Component)
+	for _, h := range []struct {
+		name string
+		new  func() hash.Hash
+	}{
+		{"MD5Sum", md5.New},
+		{"SHA1", sha1.New},
+		{"SHA256", sha256.New},
+	} {
+		fmt.Fprintf(&sb, "%s:\n", h.name)
+		for _, name := range sortedKeys(indexes) {
+			fmt.Fprintf(&sb, " %s %d %s\n", hashOf(h.new(), indexes[name]), len(indexes[name]), name)
+		}
+	}
+	return sb.String()
+}
+
+// field returns the value of the given field of a deb822 stanza
+func field(stanza, name string) string {
+	for _, line := range strings.Split(stanza, "\n") {
+		if strings.HasPrefix(line, name+":") {
+			return strings.TrimSpace(strings.TrimPrefix(line, name+":"))
+		}
+	}
+	return ""
+}
+
+func hashOf(h hash.Hash, data []byte) string {
+	_, _ = h.Write(data)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+func gzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w = gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func unique(s []string) []string {
+	var seen = map[string]bool{}
+	var result []string
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,101 @@
+package pkgrepo
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+)
+
+// bucket wraps the connection to the bucket of a repository
+type bucket struct {
+	conn *blob.Bucket
+	url  string
+}
+
+// read returns the content of the given key, or nil if it doesn't exist
+func (b bucket) read(ctx *context.Context, key string) ([]byte, error) {
+	data, err := b.conn.ReadAll(ctx, key)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s from %s", key, b.url)
+	}
+	return data, nil
+}
+
+// write sets the content of the given key
+func (b bucket) write(ctx *context.Context, key string, data []byte) error {
+	log.WithField("provider", b.url).WithField("key", key).Info("uploading")
+	if err := b.conn.WriteAll(ctx, key, data, nil); err != nil {
+		return errors.Wrapf(err, "failed to write %s to %s", key, b.url)
+	}
+	return nil
+}
+
+// upload streams the file at path into the given key
+func (b bucket) upload(ctx *context.Context, key, path string) error {
+	log.WithField("provider", b.url).WithField("key", key).Info("uploading")
+	file, err := os.Open(path) // #nosec
+	if err != nil {
+		return err
+	}
+	defer file.Close() // nolint: errcheck
+	w, err := b.conn.NewWriter(ctx, key, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain writer")
+	}
+	if _, err := io.Copy(w, file); err != nil {
+		_ = w.Close()
+		return errors.Wrapf(err, "failed to write %s to %s", key, b.url)
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write %s to %s", key, b.url)
+	}
+	return nil
+}
+
+// download writes the given key into the file at path
+func (b bucket) download(ctx *context.Context, key, path string) error {
+	log.WithField("provider", b.url).WithField("key", key).Debug("downloading")
+	data, err := b.conn.ReadAll(ctx, key)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s from %s", key, b.url)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// list returns the keys with the given prefix
+func (b bucket) list(ctx *context.Context, prefix string) ([]string, error) {
+	var keys []string
+	var iter = b.conn.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			return keys, nil
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list %s in %s", prefix, b.url)
+		}
+		keys = append(keys, obj.Key)
+	}
+}
+
+// delete removes the given key
+func (b bucket) delete(ctx *context.Context, key string) error {
+	log.WithField("provider", b.url).WithField("key", key).Info("deleting")
+	if err := b.conn.Delete(ctx, key); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+		return errors.Wrapf(err, "failed to delete %s from %s", key, b.url)
+	}
+	return nil
+}
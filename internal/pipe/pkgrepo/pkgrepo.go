@@ -0,0 +1,178 @@
+// Package pkgrepo implements the Publisher interface maintaining apt, yum and
+// apk repositories of the linux packages in a bucket, or in a folder synced
+// with rsync.
+package pkgrepo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+	"gocloud.dev/blob"
+
+	// Import the blob packages we want to be able to open.
+	_ "gocloud.dev/blob/azureblob"
+	_ "gocloud.dev/blob/fileblob"
+	_ "gocloud.dev/blob/gcsblob"
+	_ "gocloud.dev/blob/s3blob"
+)
+
+// ErrNoRsync is shown when rsync cannot be found in $PATH
+var ErrNoRsync = errors.New("rsync not present in $PATH")
+
+// Pipe for apt, yum and apk repositories
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "apt, yum and apk repositories"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.PackageRepos {
+		var repo = &ctx.Config.PackageRepos[i]
+		if repo.Rsync == "" && (repo.Bucket == "" || repo.Provider == "") {
+			return fmt.Errorf("package_repos: bucket or provider cannot be empty")
+		}
+		if repo.Distribution == "" {
+			repo.Distribution = "stable"
+		}
+		if repo.Component == "" {
+			repo.Component = "main"
+		}
+		if repo.Origin == "" {
+			repo.Origin = ctx.Config.ProjectName
+		}
+		if repo.Label == "" {
+			repo.Label = ctx.Config.ProjectName
+		}
+	}
+	return nil
+}
+
+// Publish the packages to the repositories
+func (Pipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.PackageRepos) == 0 {
+		return pipe.Skip("package_repos section is not configured")
+	}
+	for _, repo := range ctx.Config.PackageRepos {
+		ok, err := condition.Check(ctx, repo.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("bucket", repo.Bucket).Info("skipped because its condition is false")
+			continue
+		}
+		if err := doPublish(ctx, repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doPublish(ctx *context.Context, repo config.PackageRepo) error {
+	folder, err := tmpl.New(ctx).Apply(repo.Folder)
+	if err != nil {
+		return err
+	}
+	var debs = ctx.Artifacts.Filter(filterFor(repo, ".deb")).List()
+	var rpms = ctx.Artifacts.Filter(filterFor(repo, ".rpm")).List()
+	var apks = ctx.Artifacts.Filter(filterFor(repo, ".apk")).List()
+	var url = fmt.Sprintf("%s://%s", repo.Provider, repo.Bucket)
+	if repo.Rsync != "" {
+		url = repo.Rsync
+	}
+	if len(debs) == 0 && len(rpms) == 0 && len(apks) == 0 {
+		return pipe.Warn(ctx, fmt.Sprintf("no deb, rpm or apk packages found for %s", url))
+	}
+
+	// rsync targets are synced into a local folder, which is then updated as
+	// a bucket and synced back
+	var local string
+	if repo.Rsync != "" {
+		if _, err := exec.LookPath("rsync"); err != nil {
+			return ErrNoRsync
+		}
+		if local, err = ioutil.TempDir("", "goreleaser-rsync"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(local) // nolint: errcheck
+		log.WithField("target", repo.Rsync).Info("syncing repositories")
+		if err := rsync(ctx, repo.Rsync+"/", local+"/"); err != nil {
+			return err
+		}
+	}
+	var conn *blob.Bucket
+	if local != "" {
+		conn, err = blob.OpenBucket(ctx, "file://"+local)
+	} else {
+		conn, err = blob.OpenBucket(ctx, url)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close() // nolint: errcheck
+	var b = bucket{conn: conn, url: url}
+
+	if len(debs) > 0 {
+		if err := publishApt(ctx, repo, b, path.Join(folder, "apt"), debs); err != nil {
+			return err
+		}
+	}
+	if len(rpms) > 0 {
+		if err := publishYum(ctx, repo, b, path.Join(folder, "yum"), rpms); err != nil {
+			return err
+		}
+	}
+	if len(apks) > 0 {
+		if err := publishApk(ctx, repo, b, path.Join(folder, "apk"), apks); err != nil {
+			return err
+		}
+	}
+	if local != "" {
+		log.WithField("target", repo.Rsync).Info("syncing repositories")
+		return rsync(ctx, local+"/", repo.Rsync+"/", "--delete")
+	}
+	return nil
+}
+
+// rsync syncs the src folder into the dst one, leaving the attributes files
+// of the local bucket out
+func rsync(ctx *context.Context, src, dst string, args ...string) error {
+	args = append([]string{"-rlt", "--exclude", "*.attrs"}, args...)
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "rsync", append(args, src, dst)...)
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to sync %s to %s: %s", src, dst, string(out))
+	}
+	return nil
+}
+
+// filterFor returns the filter of the linux packages with the given
+// extension. Termux packages are not published, as they are not installed
+// with apt on linux.
+func filterFor(repo config.PackageRepo, ext string) artifact.Filter {
+	var filter = artifact.And(
+		artifact.ByType(artifact.LinuxPackage),
+		func(a *artifact.Artifact) bool {
+			return strings.HasSuffix(a.Name, ext) && !strings.HasSuffix(a.Name, ".termux.deb")
+		},
+	)
+	if len(repo.IDs) > 0 {
+		filter = artifact.And(filter, artifact.ByIDs(repo.IDs...))
+	}
+	return filter
+}
@@ -0,0 +1,283 @@
+package pkgrepo
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/goreleaser/nfpm"
+	"github.com/goreleaser/nfpm/deb"
+	"github.com/stretchr/testify/require"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName:  "foo",
+		PackageRepos: []config.PackageRepo{{Provider: "s3", Bucket: "foo"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.PackageRepo{
+		Provider:     "s3",
+		Bucket:       "foo",
+		Distribution: "stable",
+		Component:    "main",
+		Origin:       "foo",
+		Label:        "foo",
+	}, ctx.Config.PackageRepos[0])
+}
+
+func TestDefaultNoBucket(t *testing.T) {
+	var ctx = context.New(config.Project{
+		PackageRepos: []config.PackageRepo{{Provider: "s3"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "package_repos: bucket or provider cannot be empty")
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Publish(context.New(config.Project{})))
+}
+
+// originKeyring is the test keyring of the sign pipe, resolved before the
+// tests change the working directory
+var originKeyring, _ = filepath.Abs("../sign/testdata/gnupg")
+
+// keyring copies the test keyring, as gpg needs to write to it, returning
+// its path.
+func keyring(t *testing.T, folder string) string {
+	var path = filepath.Join(folder, "gnupg")
+	require.NoError(t, exec.Command("cp", "-Rf", originKeyring, path).Run())
+	return path
+}
+
+func createDeb(t *testing.T, folder, version, arch string) *artifact.Artifact {
+	var name = "foo_" + version + "_" + arch + ".deb"
+	f, err := os.Create(filepath.Join(folder, name))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	require.NoError(t, deb.Default.Package(nfpm.WithDefaults(&nfpm.Info{
+		Name:        "foo",
+		Arch:        arch,
+		Platform:    "linux",
+		Version:     version,
+		Maintainer:  "me@me",
+		Description: "Foo",
+	}), f))
+	return &artifact.Artifact{
+		Type:  artifact.LinuxPackage,
+		Name:  name,
+		Path:  f.Name(),
+		Extra: map[string]interface{}{"ID": "default"},
+	}
+}
+
+func repoContext(t *testing.T, folder string, repo config.PackageRepo) *context.Context {
+	var ctx = context.New(config.Project{
+		ProjectName:  "foo",
+		PackageRepos: []config.PackageRepo{repo},
+	})
+	ctx.Env = map[string]string{"GNUPGHOME": keyring(t, folder)}
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func readFile(t *testing.T, path string) string {
+	bts, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	return string(bts)
+}
+
+func TestPublishApt(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var bucket = filepath.Join(folder, "bucket")
+	require.NoError(t, os.Mkdir(bucket, 0755))
+	var ctx = repoContext(t, folder, config.PackageRepo{
+		Provider:   "file",
+		Bucket:     bucket,
+		Folder:     "{{ .ProjectName }}",
+		SigningKey: "nopass",
+	})
+	ctx.Artifacts.Add(createDeb(t, folder, "1.0.0", "amd64"))
+	ctx.Artifacts.Add(&artifact.Artifact{Type: artifact.LinuxPackage, Name: "foo.termux.deb"})
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	var root = filepath.Join(bucket, "foo", "apt")
+	require.FileExists(t, filepath.Join(root, "pool/main/f/foo/foo_1.0.0_amd64.deb"))
+	var packages = readFile(t, filepath.Join(root, "dists/stable/main/binary-amd64/Packages"))
+	require.Contains(t, packages, "Package: foo\n")
+	require.Contains(t, packages, "Version: 1.0.0\n")
+	require.Contains(t, packages, "Filename: pool/main/f/foo/foo_1.0.0_amd64.deb\n")
+	require.Contains(t, packages, "SHA256: ")
+	require.FileExists(t, filepath.Join(root, "dists/stable/main/binary-amd64/Packages.gz"))
+	var release = readFile(t, filepath.Join(root, "dists/stable/Release"))
+	require.Contains(t, release, "Origin: foo\nLabel: foo\nSuite: stable\nCodename: stable\n")
+	require.Contains(t, release, "Architectures: amd64\nComponents: main\n")
+	require.Contains(t, release, " main/binary-amd64/Packages\n")
+	require.Contains(t, release, " main/binary-amd64/Packages.gz\n")
+
+	for _, args := range [][]string{
+		{"--verify", filepath.Join(root, "dists/stable/Release.gpg"), filepath.Join(root, "dists/stable/Release")},
+		{"--verify", filepath.Join(root, "dists/stable/InRelease")},
+	} {
+		var cmd = exec.Command("gpg", args...)
+		cmd.Env = ctx.Env.Strings()
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	// a second release adds its packages to the existing indexes
+	ctx.Artifacts = artifact.New()
+	ctx.Artifacts.Add(createDeb(t, folder, "1.1.0", "amd64"))
+	ctx.Artifacts.Add(createDeb(t, folder, "1.1.0", "arm64"))
+	require.NoError(t, Pipe{}.Publish(ctx))
+	packages = readFile(t, filepath.Join(root, "dists/stable/main/binary-amd64/Packages"))
+	require.Equal(t, 2, strings.Count(packages, "Package: foo\n"))
+	require.Contains(t, packages, "Version: 1.0.0\n")
+	require.Contains(t, packages, "Version: 1.1.0\n")
+	require.Contains(t, readFile(t, filepath.Join(root, "dists/stable/main/binary-arm64/Packages")), "Filename: pool/main/f/foo/foo_1.1.0_arm64.deb\n")
+	require.Contains(t, readFile(t, filepath.Join(root, "dists/stable/Release")), "Architectures: amd64 arm64\n")
+
+	// publishing the same release again doesn't duplicate its packages
+	require.NoError(t, Pipe{}.Publish(ctx))
+	packages = readFile(t, filepath.Join(root, "dists/stable/main/binary-amd64/Packages"))
+	require.Equal(t, 2, strings.Count(packages, "Package: foo\n"))
+}
+
+func TestPublishAptSkipSign(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var bucket = filepath.Join(folder, "bucket")
+	require.NoError(t, os.Mkdir(bucket, 0755))
+	var ctx = repoContext(t, folder, config.PackageRepo{
+		Provider:   "file",
+		Bucket:     bucket,
+		SigningKey: "nopass",
+	})
+	ctx.SkipSign = true
+	ctx.Artifacts.Add(createDeb(t, folder, "1.0.0", "amd64"))
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.FileExists(t, filepath.Join(bucket, "apt/dists/stable/Release"))
+	_, err := os.Stat(filepath.Join(bucket, "apt/dists/stable/InRelease"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestPublishAptInvalidPackage(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var bucket = filepath.Join(folder, "bucket")
+	require.NoError(t, os.Mkdir(bucket, 0755))
+	var ctx = repoContext(t, folder, config.PackageRepo{Provider: "file", Bucket: bucket})
+	require.NoError(t, ioutil.WriteFile("foo.deb", []byte("not a deb"), 0644))
+	ctx.Artifacts.Add(&artifact.Artifact{Type: artifact.LinuxPackage, Name: "foo.deb", Path: "foo.deb"})
+	var err = Pipe{}.Publish(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read foo.deb")
+}
+
+// fakeCreateRepo puts a fake createrepo_c in the PATH, which logs the
+// packages of the repository and writes its metadata, returning a func that
+// restores the PATH.
+func fakeCreateRepo(t *testing.T, folder, log string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"createrepo_c": `echo "$1" >> ` + log + `
+ls "$2/Packages" >> ` + log + `
+rm -rf "$2/repodata"
+mkdir "$2/repodata"
+echo repomd > "$2/repodata/repomd.xml"
+echo primary > "$2/repodata/new-primary.xml.gz"
+`,
+	})
+}
+
+func TestPublishYum(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "createrepo.log")
+	defer fakeCreateRepo(t, folder, log)()
+	var bucket = filepath.Join(folder, "bucket")
+	var root = filepath.Join(bucket, "yum")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "Packages"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "repodata"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "Packages", "foo-0.9.0.x86_64.rpm"), []byte("old"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "repodata", "old-primary.xml.gz"), []byte("old"), 0644))
+	require.NoError(t, ioutil.WriteFile("foo-1.0.0.x86_64.rpm", []byte("new"), 0644))
+
+	var ctx = repoContext(t, folder, config.PackageRepo{
+		Provider:   "file",
+		Bucket:     bucket,
+		SigningKey: "nopass",
+		IDs:        []string{"default"},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:  artifact.LinuxPackage,
+		Name:  "foo-1.0.0.x86_64.rpm",
+		Path:  "foo-1.0.0.x86_64.rpm",
+		Extra: map[string]interface{}{"ID": "default"},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:  artifact.LinuxPackage,
+		Name:  "bar-1.0.0.x86_64.rpm",
+		Path:  "bar-1.0.0.x86_64.rpm",
+		Extra: map[string]interface{}{"ID": "bar"},
+	})
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	require.Equal(t, "--update\nfoo-0.9.0.x86_64.rpm\nfoo-1.0.0.x86_64.rpm\n", readFile(t, log))
+	require.Equal(t, "new", readFile(t, filepath.Join(root, "Packages", "foo-1.0.0.x86_64.rpm")))
+	require.Equal(t, "old", readFile(t, filepath.Join(root, "Packages", "foo-0.9.0.x86_64.rpm")))
+	require.Equal(t, "repomd\n", readFile(t, filepath.Join(root, "repodata", "repomd.xml")))
+	require.Equal(t, "primary\n", readFile(t, filepath.Join(root, "repodata", "new-primary.xml.gz")))
+	require.FileExists(t, filepath.Join(root, "repodata", "repomd.xml.asc"))
+	_, err := os.Stat(filepath.Join(root, "repodata", "old-primary.xml.gz"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestPublishYumFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeCreateRepo(t, folder, filepath.Join(folder, "createrepo.log"))()
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "bin", "createrepo_c"),
+		[]byte("#!/bin/sh\necho 'invalid package'\nexit 1\n"),
+		0755,
+	))
+	var bucket = filepath.Join(folder, "bucket")
+	require.NoError(t, os.Mkdir(bucket, 0755))
+	require.NoError(t, ioutil.WriteFile("foo.rpm", []byte("new"), 0644))
+	var ctx = repoContext(t, folder, config.PackageRepo{Provider: "file", Bucket: bucket})
+	ctx.Artifacts.Add(&artifact.Artifact{Type: artifact.LinuxPackage, Name: "foo.rpm", Path: "foo.rpm"})
+	var err = Pipe{}.Publish(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid package")
+}
+
+func TestPublishNoPackages(t *testing.T) {
+	var ctx = context.New(config.Project{
+		PackageRepos: []config.PackageRepo{{Provider: "file", Bucket: "/nope"}},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{Type: artifact.LinuxPackage, Name: "foo.pkg.tar.zst"})
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	ctx.Config.Strict = true
+	require.EqualError(t, Pipe{}.Publish(ctx), "strict mode: no deb, rpm or apk packages found for file:///nope")
+}
+
+func TestMergePackages(t *testing.T) {
+	var existing = "Package: foo\nVersion: 1.0.0\nArchitecture: amd64\nFilename: old\n\n" +
+		"Package: foo\nVersion: 0.9.0\nArchitecture: amd64\nFilename: older\n"
+	require.Equal(
+		t,
+		"Package: foo\nVersion: 0.9.0\nArchitecture: amd64\nFilename: older\n\n"+
+			"Package: foo\nVersion: 1.0.0\nArchitecture: amd64\nFilename: new\n",
+		string(mergePackages([]byte(existing), []string{"Package: foo\nVersion: 1.0.0\nArchitecture: amd64\nFilename: new"})),
+	)
+	require.Equal(t, "", string(mergePackages(nil, nil)))
+}
@@ -0,0 +1,38 @@
+package pkgrepo
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// shouldSign tells whether the indexes of the repository should be signed
+func shouldSign(ctx *context.Context, repo config.PackageRepo) bool {
+	return repo.SigningKey != "" && !ctx.SkipSign
+}
+
+// sign signs the given data with gpg and the signing key of the repository,
+// returning either an armored detached signature or, when clear is true, the
+// clear text signed data.
+func sign(ctx *context.Context, repo config.PackageRepo, data []byte, clear bool) ([]byte, error) {
+	var args = []string{"--batch", "--yes", "--armor", "--local-user", repo.SigningKey}
+	if clear {
+		args = append(args, "--clearsign")
+	} else {
+		args = append(args, "--detach-sign")
+	}
+	var stdout, stderr bytes.Buffer
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "gpg", args...)
+	cmd.Env = ctx.Env.Strings()
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to sign with %s: %s", repo.SigningKey, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
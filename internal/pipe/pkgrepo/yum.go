@@ -0,0 +1,117 @@
+package pkgrepo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoCreateRepo is shown when createrepo_c cannot be found in $PATH
+var ErrNoCreateRepo = errors.New("createrepo_c not present in $PATH")
+
+// publishYum uploads the given rpm packages to the Packages folder of the yum
+// repository at root, and regenerates its repodata with createrepo_c.
+// createrepo_c needs every package of the repository, so the repository is
+// downloaded first.
+func publishYum(ctx *context.Context, repo config.PackageRepo, b bucket, root string, rpms []*artifact.Artifact) error {
+	if _, err := exec.LookPath("createrepo_c"); err != nil {
+		return ErrNoCreateRepo
+	}
+	dir, err := ioutil.TempDir("", "goreleaser-yum")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	keys, err := b.list(ctx, root+"/")
+	if err != nil {
+		return err
+	}
+	log.WithField("provider", b.url).WithField("files", len(keys)).Info("downloading yum repository")
+	for _, key := range keys {
+		var name = strings.TrimPrefix(key, root+"/")
+		if err := b.download(ctx, key, filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			return err
+		}
+	}
+	for _, rpm := range rpms {
+		if err := copyFile(rpm.Path, filepath.Join(dir, "Packages", rpm.Name)); err != nil {
+			return errors.Wrapf(err, "failed to copy %s", rpm.Path)
+		}
+	}
+
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "createrepo_c", "--update", dir)
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create yum repository: %s", string(out))
+	}
+	var repomd = filepath.Join(dir, "repodata", "repomd.xml")
+	if shouldSign(ctx, repo) {
+		data, err := ioutil.ReadFile(repomd)
+		if err != nil {
+			return err
+		}
+		signature, err := sign(ctx, repo, data, false)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(repomd+".asc", signature, 0644); err != nil {
+			return err
+		}
+	}
+
+	for _, rpm := range rpms {
+		if err := b.upload(ctx, path.Join(root, "Packages", rpm.Name), rpm.Path); err != nil {
+			return err
+		}
+	}
+	// repomd.xml references the other metadata files, so it goes last, and
+	// the metadata it no longer references is deleted after it
+	metadata, err := ioutil.ReadDir(filepath.Join(dir, "repodata"))
+	if err != nil {
+		return err
+	}
+	var uploaded = map[string]bool{}
+	for _, last := range []bool{false, true} {
+		for _, file := range metadata {
+			if strings.HasPrefix(file.Name(), "repomd.xml") != last {
+				continue
+			}
+			var key = path.Join(root, "repodata", file.Name())
+			if err := b.upload(ctx, key, filepath.Join(dir, "repodata", file.Name())); err != nil {
+				return err
+			}
+			uploaded[key] = true
+		}
+	}
+	for _, key := range keys {
+		if strings.HasPrefix(key, path.Join(root, "repodata")+"/") && !uploaded[key] {
+			if err := b.delete(ctx, key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	data, err := ioutil.ReadFile(src) // #nosec
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dst, data, 0644)
+}
@@ -0,0 +1,239 @@
+// Package provenance provides a Pipe that generates a SLSA provenance of the
+// artifacts, an in-toto statement of how, from what and by whom they were
+// built.
+package provenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+const (
+	statementType  = "https://in-toto.io/Statement/v0.1"
+	predicateType  = "https://slsa.dev/provenance/v0.2"
+	buildType      = "https://goreleaser.com/provenance/v1"
+	localBuilderID = "https://goreleaser.com/local"
+)
+
+// Statement is an in-toto statement with a SLSA provenance predicate
+type Statement struct {
+	Type          string    `json:"_type"`
+	PredicateType string    `json:"predicateType"`
+	Subject       []Subject `json:"subject"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Subject is an attested artifact
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Predicate is the SLSA provenance of the subjects
+type Predicate struct {
+	Builder    Builder    `json:"builder"`
+	BuildType  string     `json:"buildType"`
+	Invocation Invocation `json:"invocation"`
+	Metadata   Metadata   `json:"metadata"`
+	Materials  []Material `json:"materials,omitempty"`
+}
+
+// Builder is what ran the build
+type Builder struct {
+	ID string `json:"id"`
+}
+
+// Invocation is how the build was started
+type Invocation struct {
+	ConfigSource Material `json:"configSource"`
+}
+
+// Metadata of the build
+type Metadata struct {
+	BuildInvocationID string `json:"buildInvocationId,omitempty"`
+	BuildFinishedOn   string `json:"buildFinishedOn"`
+	Reproducible      bool   `json:"reproducible"`
+}
+
+// Material is a source the artifacts were built from
+type Material struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// Pipe for provenance
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "provenance"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	var cfg = &ctx.Config.Provenance
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.NameTemplate == "" {
+		cfg.NameTemplate = "{{ .ProjectName }}_{{ .Version }}.intoto.jsonl"
+	}
+	if cfg.Artifacts == "" {
+		cfg.Artifacts = "all"
+	}
+	_, err := filter(*cfg)
+	return err
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	var cfg = ctx.Config.Provenance
+	if !cfg.Enabled {
+		return pipe.Skip("provenance is not enabled")
+	}
+	f, err := filter(cfg)
+	if err != nil {
+		return err
+	}
+	var artifacts = ctx.Artifacts.Filter(f).List()
+	if len(artifacts) == 0 {
+		return pipe.Skip(fmt.Sprintf("no artifacts found to attest with artifacts: %s", cfg.Artifacts))
+	}
+	statement, err := attest(ctx, cfg, artifacts)
+	if err != nil {
+		return err
+	}
+	bts, err := json.Marshal(statement)
+	if err != nil {
+		return err
+	}
+
+	name, err := tmpl.New(ctx).Apply(cfg.NameTemplate)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute provenance name template")
+	}
+	var path = filepath.Join(ctx.Config.Dist, name)
+	log.WithField("file", path).WithField("subjects", len(statement.Subject)).Info("writing")
+	if err := ioutil.WriteFile(path, append(bts, '\n'), 0644); err != nil {
+		return err
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Provenance,
+		Name: name,
+		Path: path,
+	})
+	return nil
+}
+
+func filter(cfg config.Provenance) (artifact.Filter, error) {
+	var f artifact.Filter
+	switch cfg.Artifacts {
+	case "all":
+		// the checksums, signatures and attestations describe the
+		// artifacts, they aren't built
+		f = artifact.And(
+			artifact.ByUploadable(),
+			artifact.Not(artifact.Or(
+				artifact.ByType(artifact.Checksum),
+				artifact.ByType(artifact.Signature),
+				artifact.ByType(artifact.Certificate),
+				artifact.ByType(artifact.Provenance),
+			)),
+		)
+	case "archive":
+		f = artifact.ByType(artifact.UploadableArchive)
+	case "binary":
+		f = artifact.ByType(artifact.UploadableBinary)
+	case "package":
+		f = artifact.ByType(artifact.LinuxPackage)
+	case "source":
+		f = artifact.ByType(artifact.UploadableSourceArchive)
+	default:
+		return nil, fmt.Errorf("invalid list of artifacts to attest: %s", cfg.Artifacts)
+	}
+	if len(cfg.IDs) > 0 {
+		f = artifact.And(f, artifact.ByIDs(cfg.IDs...))
+	}
+	return f, nil
+}
+
+func attest(ctx *context.Context, cfg config.Provenance, artifacts []*artifact.Artifact) (Statement, error) {
+	var statement = Statement{
+		Type:          statementType,
+		PredicateType: predicateType,
+	}
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].Name < artifacts[j].Name
+	})
+	for _, a := range artifacts {
+		sum, err := a.Checksum("sha256")
+		if err != nil {
+			return statement, err
+		}
+		statement.Subject = append(statement.Subject, Subject{
+			Name:   a.Name,
+			Digest: map[string]string{"sha256": sum},
+		})
+	}
+
+	predicate, err := NewPredicate(ctx, cfg)
+	if err != nil {
+		return statement, err
+	}
+	statement.Predicate = predicate
+	return statement, nil
+}
+
+// NewPredicate returns the SLSA provenance of the artifacts of the release,
+// e.g. to attest docker images
+func NewPredicate(ctx *context.Context, cfg config.Provenance) (Predicate, error) {
+	builder, err := builderID(ctx, cfg)
+	if err != nil {
+		return Predicate{}, err
+	}
+	var source = Material{
+		URI:    "git+" + ctx.Git.URL,
+		Digest: map[string]string{"sha1": ctx.Git.FullCommit},
+	}
+	var configSource = source
+	if ctx.Git.CurrentTag != "" {
+		configSource.URI += "@refs/tags/" + ctx.Git.CurrentTag
+	}
+	return Predicate{
+		Builder:    Builder{ID: builder},
+		BuildType:  buildType,
+		Invocation: Invocation{ConfigSource: configSource},
+		Metadata: Metadata{
+			BuildInvocationID: ctx.Env["GITHUB_RUN_ID"],
+			BuildFinishedOn:   time.Now().UTC().Format(time.RFC3339),
+		},
+		Materials: []Material{source},
+	}, nil
+}
+
+// builderID returns the configured builder, or the CI workflow that runs the
+// release, if any.
+func builderID(ctx *context.Context, cfg config.Provenance) (string, error) {
+	if cfg.BuilderID != "" {
+		id, err := tmpl.New(ctx).Apply(cfg.BuilderID)
+		return id, errors.Wrap(err, "failed to execute provenance builder_id template")
+	}
+	if ref := ctx.Env["GITHUB_WORKFLOW_REF"]; ref != "" {
+		return ctx.Env["GITHUB_SERVER_URL"] + "/" + ref, nil
+	}
+	if url := ctx.Env["CI_JOB_URL"]; url != "" {
+		return url, nil
+	}
+	return localBuilderID, nil
+}
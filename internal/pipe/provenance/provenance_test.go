@@ -0,0 +1,169 @@
+package provenance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Provenance: config.Provenance{Enabled: true},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Provenance{
+		Enabled:      true,
+		NameTemplate: "{{ .ProjectName }}_{{ .Version }}.intoto.jsonl",
+		Artifacts:    "all",
+	}, ctx.Config.Provenance)
+}
+
+func TestDefaultDisabled(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Provenance{}, ctx.Config.Provenance)
+}
+
+func TestDefaultInvalidArtifacts(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Provenance: config.Provenance{Enabled: true, Artifacts: "checksum"},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "invalid list of artifacts to attest: checksum")
+}
+
+func TestSkipNotEnabled(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+func TestSkipNoArtifacts(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Provenance: config.Provenance{Enabled: true, Artifacts: "all"},
+	})
+	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+}
+
+func TestRunPipe(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        folder,
+		Provenance: config.Provenance{
+			Enabled:   true,
+			IDs:       []string{"foo"},
+			BuilderID: "https://ci.example.com/{{ .Env.JOB }}",
+		},
+	})
+	ctx.Env = map[string]string{"JOB": "42"}
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{
+		CurrentTag: "v1.0.0",
+		FullCommit: "a1b2c3d4",
+		URL:        "https://github.com/goreleaser/foo.git",
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	for _, a := range []struct {
+		name, id, content string
+		kind              artifact.Type
+	}{
+		{"foo_linux.tar.gz", "foo", "foo", artifact.UploadableArchive},
+		{"foo.deb", "foo", "bar", artifact.LinuxPackage},
+		{"bar_linux.tar.gz", "bar", "bar", artifact.UploadableArchive},
+		{"checksums.txt", "", "foo", artifact.Checksum},
+	} {
+		var path = filepath.Join(folder, a.name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(a.content), 0644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:  a.name,
+			Path:  path,
+			Type:  a.kind,
+			Extra: map[string]interface{}{"ID": a.id},
+		})
+	}
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var provenances = ctx.Artifacts.Filter(artifact.ByType(artifact.Provenance)).List()
+	require.Len(t, provenances, 1)
+	require.Equal(t, "foo_1.0.0.intoto.jsonl", provenances[0].Name)
+	bts, err := ioutil.ReadFile(provenances[0].Path)
+	require.NoError(t, err)
+	var statement Statement
+	require.NoError(t, json.Unmarshal(bts, &statement))
+	require.NotEmpty(t, statement.Predicate.Metadata.BuildFinishedOn)
+	statement.Predicate.Metadata.BuildFinishedOn = ""
+	require.Equal(t, Statement{
+		Type:          "https://in-toto.io/Statement/v0.1",
+		PredicateType: "https://slsa.dev/provenance/v0.2",
+		Subject: []Subject{
+			{
+				Name:   "foo.deb",
+				Digest: map[string]string{"sha256": "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"},
+			},
+			{
+				Name:   "foo_linux.tar.gz",
+				Digest: map[string]string{"sha256": "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"},
+			},
+		},
+		Predicate: Predicate{
+			Builder:   Builder{ID: "https://ci.example.com/42"},
+			BuildType: "https://goreleaser.com/provenance/v1",
+			Invocation: Invocation{
+				ConfigSource: Material{
+					URI:    "git+https://github.com/goreleaser/foo.git@refs/tags/v1.0.0",
+					Digest: map[string]string{"sha1": "a1b2c3d4"},
+				},
+			},
+			Materials: []Material{
+				{
+					URI:    "git+https://github.com/goreleaser/foo.git",
+					Digest: map[string]string{"sha1": "a1b2c3d4"},
+				},
+			},
+		},
+	}, statement)
+}
+
+func TestBuilderID(t *testing.T) {
+	for name, tt := range map[string]struct {
+		env    map[string]string
+		expect string
+	}{
+		"local": {
+			env:    map[string]string{},
+			expect: "https://goreleaser.com/local",
+		},
+		"github": {
+			env: map[string]string{
+				"GITHUB_SERVER_URL":   "https://github.com",
+				"GITHUB_WORKFLOW_REF": "goreleaser/foo/.github/workflows/release.yml@refs/tags/v1.0.0",
+			},
+			expect: "https://github.com/goreleaser/foo/.github/workflows/release.yml@refs/tags/v1.0.0",
+		},
+		"gitlab": {
+			env:    map[string]string{"CI_JOB_URL": "https://gitlab.com/foo/bar/-/jobs/1"},
+			expect: "https://gitlab.com/foo/bar/-/jobs/1",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{})
+			ctx.Env = tt.env
+			id, err := builderID(ctx, ctx.Config.Provenance)
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, id)
+		})
+	}
+}
+
+func TestBuilderIDInvalidTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	_, err := builderID(ctx, config.Provenance{BuilderID: "{{ .Nope }"})
+	require.Error(t, err)
+}
@@ -7,14 +7,30 @@ import (
 	"github.com/goreleaser/goreleaser/internal/middleware"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/internal/pipe/artifactory"
+	"github.com/goreleaser/goreleaser/internal/pipe/aur"
 	"github.com/goreleaser/goreleaser/internal/pipe/blob"
 	"github.com/goreleaser/goreleaser/internal/pipe/brew"
+	"github.com/goreleaser/goreleaser/internal/pipe/cask"
+	"github.com/goreleaser/goreleaser/internal/pipe/chocolatey"
 	"github.com/goreleaser/goreleaser/internal/pipe/docker"
+	"github.com/goreleaser/goreleaser/internal/pipe/flatpak"
+	"github.com/goreleaser/goreleaser/internal/pipe/githubpackages"
+	"github.com/goreleaser/goreleaser/internal/pipe/gitnote"
+	"github.com/goreleaser/goreleaser/internal/pipe/krew"
+	"github.com/goreleaser/goreleaser/internal/pipe/milestone"
+	"github.com/goreleaser/goreleaser/internal/pipe/mirror"
+	"github.com/goreleaser/goreleaser/internal/pipe/nix"
+	"github.com/goreleaser/goreleaser/internal/pipe/pkgrepo"
 	"github.com/goreleaser/goreleaser/internal/pipe/put"
 	"github.com/goreleaser/goreleaser/internal/pipe/release"
 	"github.com/goreleaser/goreleaser/internal/pipe/s3"
 	"github.com/goreleaser/goreleaser/internal/pipe/scoop"
+	"github.com/goreleaser/goreleaser/internal/pipe/scp"
+	"github.com/goreleaser/goreleaser/internal/pipe/sign"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapcraft"
+	"github.com/goreleaser/goreleaser/internal/pipe/upload"
+	"github.com/goreleaser/goreleaser/internal/pipe/versionbump"
+	"github.com/goreleaser/goreleaser/internal/pipe/winget"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/pkg/errors"
 )
@@ -38,15 +54,40 @@ type Publisher interface {
 var publishers = []Publisher{
 	s3.Pipe{},
 	blob.Pipe{},
+	pkgrepo.Pipe{},
 	put.Pipe{},
+	upload.Pipe{},
+	scp.Pipe{},
 	artifactory.Pipe{},
+	githubpackages.Pipe{},
 	docker.Pipe{},
+	// manifest lists need their images to be pushed
+	docker.ManifestPipe{},
+	// images are signed once pushed, by their digest
+	sign.DockerPipe{},
+	// and attested with the release metadata
+	sign.AttestPipe{},
 	snapcraft.Pipe{},
+	flatpak.Pipe{},
 	// This should be one of the last steps
 	release.Pipe{},
-	// brew and scoop use the release URL, so, they should be last
+	// mirrors get the final set of assets, once they are released
+	mirror.Pipe{},
+	// the milestone is done once the release is out
+	milestone.Pipe{},
+	// brew, casks, scoop, aur, nix, chocolatey, winget and krew use the release URL, so, they should be last
 	brew.Pipe{},
+	cask.Pipe{},
 	scoop.Pipe{},
+	aur.Pipe{},
+	nix.Pipe{},
+	chocolatey.Pipe{},
+	winget.Pipe{},
+	krew.Pipe{},
+	// version bumps point users to the release, so they go after it
+	versionbump.Pipe{},
+	// the note records everything that was published
+	gitnote.Pipe{},
 }
 
 // Run the pipe
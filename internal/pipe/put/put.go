@@ -4,6 +4,7 @@ package put
 import (
 	h "net/http"
 
+	"github.com/goreleaser/goreleaser/internal/deprecate"
 	"github.com/goreleaser/goreleaser/internal/http"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -20,6 +21,9 @@ func (Pipe) String() string {
 
 // Default sets the pipe defaults
 func (Pipe) Default(ctx *context.Context) error {
+	if len(ctx.Config.Puts) > 0 {
+		deprecate.Notice(ctx, "puts")
+	}
 	return http.Defaults(ctx.Config.Puts)
 }
 
@@ -34,7 +38,7 @@ func (Pipe) Publish(ctx *context.Context) error {
 	for _, instance := range ctx.Config.Puts {
 		instance := instance
 		if skip := http.CheckConfig(ctx, &instance, "put"); skip != nil {
-			return pipe.Skip(skip.Error())
+			return skip
 		}
 	}
 
@@ -19,6 +19,12 @@ const bodyTemplateText = `{{ .ReleaseNotes }}
 {{- end }}
 `
 
+// Body renders the release notes as they are published
+func Body(ctx *context.Context) (string, error) {
+	out, err := describeBody(ctx)
+	return out.String(), err
+}
+
 func describeBody(ctx *context.Context) (bytes.Buffer, error) {
 	var out bytes.Buffer
 	// nolint:prealloc
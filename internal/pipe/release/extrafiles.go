@@ -0,0 +1,70 @@
+package release
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	zglob "github.com/mattn/go-zglob"
+	"github.com/pkg/errors"
+)
+
+// addExtraFiles adds the files matching the globs of release.extra_files to
+// the artifacts, named by their name templates or else by their base names
+func addExtraFiles(ctx *context.Context) error {
+	var files = map[string]string{}
+	for _, extra := range ctx.Config.Release.ExtraFiles {
+		glob, err := tmpl.New(ctx).Apply(extra.Glob)
+		if err != nil {
+			return errors.Wrap(err, "failed to template the extra files glob")
+		}
+		matches, err := zglob.Glob(glob)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "globbing failed for pattern %s", glob)
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("no files matched the extra files glob %s", glob)
+		}
+		for _, path := range matches {
+			if info, err := os.Stat(path); err != nil || info.IsDir() {
+				continue
+			}
+			var name = filepath.Base(path)
+			if extra.NameTemplate != "" {
+				if len(matches) > 1 {
+					return fmt.Errorf("the extra files glob %s matched %d files, but has a name_template", glob, len(matches))
+				}
+				name, err = tmpl.New(ctx).
+					WithExtraFields(tmpl.Fields{"ArtifactName": name}).
+					Apply(extra.NameTemplate)
+				if err != nil {
+					return errors.Wrap(err, "failed to template the extra file name")
+				}
+			}
+			if previous, ok := files[name]; ok && previous != path {
+				return fmt.Errorf("extra files %s and %s would both be uploaded as %s", previous, path, name)
+			}
+			files[name] = path
+		}
+	}
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var path = files[name]
+		log.WithField("file", path).WithField("name", name).Debug("adding extra file")
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type: artifact.UploadableFile,
+			Name: name,
+			Path: path,
+		})
+	}
+	return nil
+}
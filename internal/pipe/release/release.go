@@ -47,40 +47,40 @@ func (Pipe) Default(ctx *context.Context) error {
 		ctx.Config.Release.NameTemplate = "{{.Tag}}"
 	}
 
+	switch ctx.Config.Release.Mode {
+	case "":
+		ctx.Config.Release.Mode = client.ReleaseModeKeepExisting
+	case client.ReleaseModeKeepExisting, client.ReleaseModeAppend, client.ReleaseModeReplace:
+	default:
+		return errors.Errorf("invalid release.mode %q, use keep-existing, append or replace", ctx.Config.Release.Mode)
+	}
+
 	switch ctx.TokenType {
 	case context.TokenTypeGitLab:
-		{
-			if ctx.Config.Release.GitLab.Name == "" {
-				repo, err := remoteRepo()
-				if err != nil {
-					return err
-				}
-				ctx.Config.Release.GitLab = repo
+		if ctx.Config.Release.GitLab.Name == "" {
+			repo, err := remoteRepo()
+			if err != nil {
+				return err
 			}
-
-			return nil
+			ctx.Config.Release.GitLab = repo
 		}
 	case context.TokenTypeGitea:
-		{
-			if ctx.Config.Release.Gitea.Name == "" {
-				repo, err := remoteRepo()
-				if err != nil {
-					return err
-				}
-				ctx.Config.Release.Gitea = repo
+		if ctx.Config.Release.Gitea.Name == "" {
+			repo, err := remoteRepo()
+			if err != nil {
+				return err
 			}
-
-			return nil
+			ctx.Config.Release.Gitea = repo
 		}
-	}
-
-	// We keep github as default for now
-	if ctx.Config.Release.GitHub.Name == "" {
-		repo, err := remoteRepo()
-		if err != nil && !ctx.Snapshot {
-			return err
+	default:
+		// We keep github as default for now
+		if ctx.Config.Release.GitHub.Name == "" {
+			repo, err := remoteRepo()
+			if err != nil && !ctx.Snapshot {
+				return err
+			}
+			ctx.Config.Release.GitHub = repo
 		}
-		ctx.Config.Release.GitHub = repo
 	}
 
 	// Check if we have to check the git tag for an indicator to mark as pre release
@@ -92,6 +92,9 @@ func (Pipe) Default(ctx *context.Context) error {
 		log.Debugf("pre-release was detected for tag %s: %v", ctx.Git.CurrentTag, ctx.PreRelease)
 	case "true":
 		ctx.PreRelease = true
+	case "", "false":
+	default:
+		return errors.Errorf("invalid release.prerelease %q, use auto, true or false", ctx.Config.Release.Prerelease)
 	}
 	log.Debugf("pre-release for tag %s set to %v", ctx.Git.CurrentTag, ctx.PreRelease)
 
@@ -118,18 +121,25 @@ func doPublish(ctx *context.Context, client client.Client) error {
 	if err != nil {
 		return err
 	}
+	// extra files are checked before creating the release, so a wrong glob
+	// doesn't leave an empty release behind
+	if err := addExtraFiles(ctx); err != nil {
+		return err
+	}
 	releaseID, err := client.CreateRelease(ctx, body.String())
 	if err != nil {
 		return err
 	}
 
+	uploads, err := loadUploads(ctx)
+	if err != nil {
+		return err
+	}
+
 	var filters = []artifact.Filter{
 		artifact.Or(
-			artifact.ByType(artifact.UploadableArchive),
-			artifact.ByType(artifact.UploadableBinary),
-			artifact.ByType(artifact.Checksum),
-			artifact.ByType(artifact.Signature),
-			artifact.ByType(artifact.LinuxPackage),
+			artifact.ByUploadable(),
+			artifact.ByType(artifact.Config),
 		),
 	}
 
@@ -141,6 +151,12 @@ func doPublish(ctx *context.Context, client client.Client) error {
 	for _, artifact := range ctx.Artifacts.Filter(artifact.And(filters...)).List() {
 		artifact := artifact
 		g.Go(func() error {
+			if ok, err := uploads.uploaded(ctx, releaseID, artifact); ok || err != nil {
+				if ok {
+					log.WithField("name", artifact.Name).Info("unchanged since its last upload, skipping")
+				}
+				return err
+			}
 			var repeats uint
 			what := func(try uint) error {
 				repeats = try + 1
@@ -160,7 +176,7 @@ func doPublish(ctx *context.Context, client client.Client) error {
 			if err := retry.Try(ctx, what, how...); err != nil {
 				return errors.Wrapf(err, "failed to upload %s after %d retries", artifact.Name, repeats)
 			}
-			return nil
+			return uploads.add(ctx, releaseID, artifact)
 		})
 	}
 	return g.Wait()
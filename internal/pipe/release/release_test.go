@@ -150,6 +150,86 @@ func TestRunPipeWithIDsThenFilters(t *testing.T) {
 	assert.NotContains(t, client.UploadedFileNames, "filtered.tar.gz")
 }
 
+func TestRunPipeWithExtraFiles(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(filepath.Join(folder, "docs", "pdf"), 0755))
+	for _, name := range []string{"coverage.html", "docs/manual.pdf", "docs/pdf/guide.pdf", "installer.exe"} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(folder, name), []byte(name), 0644))
+	}
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "test", Name: "test"},
+			IDs:    []string{"foo"},
+			ExtraFiles: []config.ExtraFile{
+				{Glob: filepath.Join(folder, "docs", "**", "*.pdf")},
+				{Glob: filepath.Join(folder, "coverage.html")},
+				{
+					Glob:         filepath.Join(folder, "installer.exe"),
+					NameTemplate: "{{ .ProjectName }}_{{ .Version }}_{{ .ArtifactName }}",
+				},
+			},
+		},
+	})
+	ctx.Config.ProjectName = "foo"
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	client := &DummyClient{}
+	assert.NoError(t, doPublish(ctx, client))
+	assert.ElementsMatch(t, []string{
+		"coverage.html",
+		"foo_1.0.0_installer.exe",
+		"guide.pdf",
+		"manual.pdf",
+	}, client.UploadedFileNames)
+}
+
+func TestRunPipeWithInvalidExtraFiles(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(filepath.Join(folder, "a"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(folder, "b"), 0755))
+	for _, name := range []string{"a/notes.txt", "b/notes.txt"} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(folder, name), []byte(name), 0644))
+	}
+	for name, tt := range map[string]struct {
+		files []config.ExtraFile
+		err   string
+	}{
+		"no matches": {
+			files: []config.ExtraFile{{Glob: filepath.Join(folder, "*.nope")}},
+			err:   "no files matched the extra files glob " + filepath.Join(folder, "*.nope"),
+		},
+		"same name": {
+			files: []config.ExtraFile{{Glob: filepath.Join(folder, "*", "notes.txt")}},
+			err:   "would both be uploaded as notes.txt",
+		},
+		"name template with several matches": {
+			files: []config.ExtraFile{{Glob: filepath.Join(folder, "*", "notes.txt"), NameTemplate: "notes"}},
+			err:   "matched 2 files, but has a name_template",
+		},
+		"invalid name template": {
+			files: []config.ExtraFile{{Glob: filepath.Join(folder, "a", "notes.txt"), NameTemplate: "{{ .Nope"}},
+			err:   "failed to template the extra file name",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{
+				Release: config.Release{
+					GitHub:     config.Repo{Owner: "test", Name: "test"},
+					ExtraFiles: tt.files,
+				},
+			})
+			ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+			client := &DummyClient{}
+			err := doPublish(ctx, client)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.False(t, client.CreatedRelease)
+		})
+	}
+}
+
 func TestRunPipeReleaseCreationFailed(t *testing.T) {
 	var config = config.Project{
 		Release: config.Release{
@@ -247,6 +327,61 @@ func TestRunPipeUploadRetry(t *testing.T) {
 	assert.True(t, client.UploadedFile)
 }
 
+func TestRunPipeIncrementalSkipsUploaded(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var tarfile = filepath.Join(folder, "bin.tar.gz")
+	var debfile = filepath.Join(folder, "bin.deb")
+	assert.NoError(t, ioutil.WriteFile(tarfile, []byte("tar"), 0644))
+	assert.NoError(t, ioutil.WriteFile(debfile, []byte("deb"), 0644))
+
+	var newContext = func() *context.Context {
+		var ctx = context.New(config.Project{
+			Dist: folder,
+			Incremental: config.Incremental{
+				Enabled:  true,
+				CacheDir: filepath.Join(folder, "cache"),
+			},
+			Release: config.Release{
+				GitHub: config.Repo{
+					Owner: "test",
+					Name:  "test",
+				},
+			},
+		})
+		ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type: artifact.UploadableArchive,
+			Name: "bin.tar.gz",
+			Path: tarfile,
+		})
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type: artifact.LinuxPackage,
+			Name: "bin.deb",
+			Path: debfile,
+		})
+		return ctx
+	}
+
+	var client = &DummyClient{FailToUpload: true}
+	assert.Error(t, doPublish(newContext(), client))
+
+	client = &DummyClient{}
+	assert.NoError(t, doPublish(newContext(), client))
+	assert.ElementsMatch(t, []string{"bin.tar.gz", "bin.deb"}, client.UploadedFileNames)
+
+	assert.NoError(t, ioutil.WriteFile(debfile, []byte("new deb"), 0644))
+	client = &DummyClient{}
+	assert.NoError(t, doPublish(newContext(), client))
+	assert.Equal(t, []string{"bin.deb"}, client.UploadedFileNames)
+
+	var ctx = newContext()
+	ctx.Git.CurrentTag = "v1.0.1"
+	client = &DummyClient{}
+	assert.NoError(t, doPublish(ctx, client))
+	assert.ElementsMatch(t, []string{"bin.tar.gz", "bin.deb"}, client.UploadedFileNames)
+}
+
 func TestPipeDisabled(t *testing.T) {
 	var ctx = context.New(config.Project{
 		Release: config.Release{
@@ -337,6 +472,50 @@ func TestDefaultPreReleaseAuto(t *testing.T) {
 		assert.Equal(t, true, ctx.PreRelease)
 	})
 
+	t.Run("auto-rc-gitlab", func(t *testing.T) {
+		var ctx = context.New(config.Project{
+			Release: config.Release{
+				Prerelease: "auto",
+			},
+		})
+		ctx.TokenType = context.TokenTypeGitLab
+		ctx.Semver = context.Semver{
+			Major:      1,
+			Minor:      0,
+			Patch:      0,
+			Prerelease: "rc1",
+		}
+		assert.NoError(t, Pipe{}.Default(ctx))
+		assert.Equal(t, true, ctx.PreRelease)
+	})
+
+	t.Run("auto-rc-gitea", func(t *testing.T) {
+		var ctx = context.New(config.Project{
+			Release: config.Release{
+				Prerelease: "auto",
+			},
+		})
+		ctx.TokenType = context.TokenTypeGitea
+		ctx.Semver = context.Semver{
+			Major:      1,
+			Minor:      0,
+			Patch:      0,
+			Prerelease: "beta.1",
+		}
+		assert.NoError(t, Pipe{}.Default(ctx))
+		assert.Equal(t, true, ctx.PreRelease)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var ctx = context.New(config.Project{
+			Release: config.Release{
+				Prerelease: "yes",
+			},
+		})
+		ctx.TokenType = context.TokenTypeGitHub
+		assert.EqualError(t, Pipe{}.Default(ctx), `invalid release.prerelease "yes", use auto, true or false`)
+	})
+
 	t.Run("auto-rc-github-setup", func(t *testing.T) {
 		var ctx = context.New(config.Project{
 			Release: config.Release{
@@ -359,6 +538,25 @@ func TestDefaultPreReleaseAuto(t *testing.T) {
 	})
 }
 
+func TestDefaultReleaseMode(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "git@github.com:goreleaser/goreleaser.git")
+
+	var ctx = context.New(config.Project{})
+	ctx.TokenType = context.TokenTypeGitHub
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Equal(t, "keep-existing", ctx.Config.Release.Mode)
+
+	ctx.Config.Release.Mode = "replace"
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Equal(t, "replace", ctx.Config.Release.Mode)
+
+	ctx.Config.Release.Mode = "overwrite"
+	assert.EqualError(t, Pipe{}.Default(ctx), `invalid release.mode "overwrite", use keep-existing, append or replace`)
+}
+
 func TestDefaultPipeDisabled(t *testing.T) {
 	_, back := testlib.Mktmp(t)
 	defer back()
@@ -0,0 +1,88 @@
+package release
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// uploads records the checksums of the artifacts uploaded to each release
+// when incremental builds are enabled, so running a release again, after a
+// failed publish for example, doesn't upload the unchanged artifacts again.
+type uploads struct {
+	path string
+	lock sync.Mutex
+	sums map[string]string
+}
+
+// loadUploads reads the uploads recorded in the cache dir, or returns nil if
+// incremental builds are disabled.
+func loadUploads(ctx *context.Context) (*uploads, error) {
+	if !ctx.Config.Incremental.Enabled {
+		return nil, nil
+	}
+	var u = &uploads{
+		path: filepath.Join(ctx.Config.Incremental.CacheDir, "uploads.json"),
+		sums: map[string]string{},
+	}
+	bts, err := ioutil.ReadFile(u.path)
+	if os.IsNotExist(err) {
+		return u, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bts, &u.sums); err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", u.path)
+	}
+	return u, nil
+}
+
+// uploadKey identifies an artifact of a release, the release id changing if
+// the release is deleted and created again.
+func uploadKey(ctx *context.Context, releaseID string, a *artifact.Artifact) string {
+	return ctx.Git.CurrentTag + "/" + releaseID + "/" + a.Name
+}
+
+// uploaded returns true if the artifact was already uploaded as is to the
+// release.
+func (u *uploads) uploaded(ctx *context.Context, releaseID string, a *artifact.Artifact) (bool, error) {
+	if u == nil {
+		return false, nil
+	}
+	sum, err := a.Checksum("sha256")
+	if err != nil {
+		return false, err
+	}
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	return u.sums[uploadKey(ctx, releaseID, a)] == sum, nil
+}
+
+// add records the upload of the artifact to the release.
+func (u *uploads) add(ctx *context.Context, releaseID string, a *artifact.Artifact) error {
+	if u == nil {
+		return nil
+	}
+	sum, err := a.Checksum("sha256")
+	if err != nil {
+		return err
+	}
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.sums[uploadKey(ctx, releaseID, a)] = sum
+	bts, err := json.Marshal(u.sums)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(u.path), 0755); err != nil {
+		return err
+	}
+	return errors.Wrapf(ioutil.WriteFile(u.path, bts, 0644), "failed to write %s", u.path)
+}
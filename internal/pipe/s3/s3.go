@@ -32,7 +32,7 @@ func (Pipe) Default(ctx *context.Context) error {
 		if s3.Bucket == "" {
 			continue
 		}
-		deprecate.Notice("s3")
+		deprecate.Notice(ctx, "s3")
 		if s3.Folder == "" {
 			s3.Folder = "{{ .ProjectName }}/{{ .Tag }}"
 		}
@@ -89,13 +89,7 @@ func upload(ctx *context.Context, conf config.S3) error {
 		return err
 	}
 
-	var filter = artifact.Or(
-		artifact.ByType(artifact.UploadableArchive),
-		artifact.ByType(artifact.UploadableBinary),
-		artifact.ByType(artifact.Checksum),
-		artifact.ByType(artifact.Signature),
-		artifact.ByType(artifact.LinuxPackage),
-	)
+	var filter = artifact.ByUploadable()
 	if len(conf.IDs) > 0 {
 		filter = artifact.And(filter, artifact.ByIDs(conf.IDs...))
 	}
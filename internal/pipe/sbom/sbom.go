@@ -0,0 +1,176 @@
+// Package sbom provides a Pipe that generates software bills of materials of
+// the archives, binaries, packages and docker images, by running a command
+// like syft for each of them.
+package sbom
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// Pipe for SBOMs
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "software bills of materials"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.SBOMs {
+		var cfg = &ctx.Config.SBOMs[i]
+		if cfg.Cmd == "" {
+			cfg.Cmd = "syft"
+		}
+		if cfg.Artifacts == "" {
+			cfg.Artifacts = "archive"
+		}
+		if cfg.Document == "" {
+			cfg.Document = "${artifact}.sbom.json"
+		}
+		if len(cfg.Args) == 0 {
+			cfg.Args = []string{"$artifact", "--output", "spdx-json=$document"}
+		}
+		if _, err := filter(*cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.SBOMs) == 0 {
+		return pipe.Skip("sboms section is not configured")
+	}
+	var g = semerrgroup.New(ctx.Parallelism)
+	for _, cfg := range ctx.Config.SBOMs {
+		cfg := cfg
+		ok, err := condition.Check(ctx, cfg.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("artifacts", cfg.Artifacts).Info("skipped because its condition is false")
+			continue
+		}
+		f, err := filter(cfg)
+		if err != nil {
+			return err
+		}
+		var artifacts = ctx.Artifacts.Filter(f).List()
+		if len(artifacts) == 0 {
+			if err := pipe.Warn(ctx, fmt.Sprintf("no artifacts found to catalog with artifacts: %s", cfg.Artifacts)); err != nil {
+				return err
+			}
+		}
+		for _, a := range artifacts {
+			a := a
+			g.Go(func() error {
+				sbom, err := catalog(ctx, cfg, a)
+				if err != nil {
+					return err
+				}
+				ctx.Artifacts.Add(sbom)
+				return nil
+			})
+		}
+	}
+	return g.Wait()
+}
+
+func filter(cfg config.SBOM) (artifact.Filter, error) {
+	var f artifact.Filter
+	switch cfg.Artifacts {
+	case "archive":
+		f = artifact.ByType(artifact.UploadableArchive)
+	case "binary":
+		f = artifact.ByType(artifact.UploadableBinary)
+	case "package":
+		f = artifact.ByType(artifact.LinuxPackage)
+	case "source":
+		f = artifact.ByType(artifact.UploadableSourceArchive)
+	case "docker":
+		f = artifact.ByType(artifact.PublishableDockerImage)
+	default:
+		return nil, fmt.Errorf("invalid list of artifacts to catalog: %s", cfg.Artifacts)
+	}
+	if len(cfg.IDs) > 0 {
+		f = artifact.And(f, artifact.ByIDs(cfg.IDs...))
+	}
+	return f, nil
+}
+
+// catalog runs the command for the given artifact, returning the generated
+// document, which is written to the dist folder.
+func catalog(ctx *context.Context, cfg config.SBOM, a *artifact.Artifact) (*artifact.Artifact, error) {
+	var source = a.Path
+	var name = a.Name
+	if a.Type == artifact.PublishableDockerImage {
+		// images are referenced by name, and their names aren't valid file
+		// names
+		source = a.Name
+		name = strings.NewReplacer("/", "_", ":", "_").Replace(a.Name)
+	}
+	var document = expand(cfg.Document, map[string]string{"artifact": name})
+	var path = filepath.Join(ctx.Config.Dist, document)
+	var vars = map[string]string{
+		"artifact": source,
+		"document": path,
+	}
+	// nolint: prealloc
+	var args []string
+	for _, arg := range cfg.Args {
+		args = append(args, expand(arg, vars))
+	}
+	var env = os.Environ()
+	for _, e := range cfg.Env {
+		s, err := tmpl.New(ctx).WithArtifact(a, map[string]string{}).Apply(e)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to execute env template '%s'", e)
+		}
+		env = append(env, s)
+	}
+
+	log.WithField("artifact", a.Name).WithField("document", document).Info("cataloging")
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, cfg.Cmd, args...)
+	cmd.Env = env
+	log.WithField("cmd", cmd.Args).Debug("running")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("sbom: %s failed with %q", cfg.Cmd, string(out))
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("sbom: %s didn't create %s", cfg.Cmd, document)
+	}
+	return &artifact.Artifact{
+		Type:   artifact.SBOM,
+		Name:   document,
+		Path:   path,
+		Goos:   a.Goos,
+		Goarch: a.Goarch,
+		Goarm:  a.Goarm,
+		Extra: map[string]interface{}{
+			"ID": a.ExtraOr("ID", ""),
+		},
+	}, nil
+}
+
+func expand(s string, vars map[string]string) string {
+	return os.Expand(s, func(key string) string {
+		return vars[key]
+	})
+}
@@ -0,0 +1,127 @@
+package sbom
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		SBOMs: []config.SBOM{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.SBOM{
+		Cmd:       "syft",
+		Artifacts: "archive",
+		Document:  "${artifact}.sbom.json",
+		Args:      []string{"$artifact", "--output", "spdx-json=$document"},
+	}, ctx.Config.SBOMs[0])
+}
+
+func TestDefaultInvalidArtifacts(t *testing.T) {
+	var ctx = context.New(config.Project{
+		SBOMs: []config.SBOM{{Artifacts: "checksum"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "invalid list of artifacts to catalog: checksum")
+}
+
+func TestSkipNotConfigured(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+// fakeSyft puts a fake syft in the PATH, which writes its first argument to
+// the file after the `=` of the third one.
+func fakeSyft(t *testing.T, folder string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"syft": "echo \"$1 $SBOM_FORMAT\" > \"${3#*=}\"\n",
+	})
+}
+
+func TestRunPipe(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeSyft(t, folder)()
+
+	var ctx = context.New(config.Project{
+		Dist: folder,
+		SBOMs: []config.SBOM{
+			{Env: []string{"SBOM_FORMAT=spdx-{{ .Os }}"}},
+			{Artifacts: "docker", Document: "${artifact}.cdx.json"},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	var archive = filepath.Join(folder, "foo_linux_amd64.tar.gz")
+	require.NoError(t, ioutil.WriteFile(archive, []byte("foo"), 0644))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo_linux_amd64.tar.gz",
+		Path:   archive,
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.UploadableArchive,
+		Extra: map[string]interface{}{
+			"ID": "default",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "goreleaser/foo:v1.0.0",
+		Path: "goreleaser/foo:v1.0.0",
+		Type: artifact.PublishableDockerImage,
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var sboms = ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List()
+	require.Len(t, sboms, 2)
+	for name, content := range map[string]string{
+		"foo_linux_amd64.tar.gz.sbom.json": archive + " spdx-linux\n",
+		"goreleaser_foo_v1.0.0.cdx.json":   "goreleaser/foo:v1.0.0 \n",
+	} {
+		bts, err := ioutil.ReadFile(filepath.Join(folder, name))
+		require.NoError(t, err)
+		require.Equal(t, content, string(bts))
+	}
+	for _, sbom := range sboms {
+		if sbom.Name == "foo_linux_amd64.tar.gz.sbom.json" {
+			require.Equal(t, "default", sbom.ExtraOr("ID", ""))
+			require.Equal(t, "linux", sbom.Goos)
+		}
+	}
+}
+
+func TestRunPipeFail(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		Dist:  folder,
+		SBOMs: []config.SBOM{{Cmd: "false"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "foo.tar.gz",
+		Path: "foo.tar.gz",
+		Type: artifact.UploadableArchive,
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), `sbom: false failed with ""`)
+}
+
+func TestRunPipeNoDocument(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		Dist:  folder,
+		SBOMs: []config.SBOM{{Cmd: "true"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "foo.tar.gz",
+		Path: "foo.tar.gz",
+		Type: artifact.UploadableArchive,
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), "sbom: true didn't create foo.tar.gz.sbom.json")
+}
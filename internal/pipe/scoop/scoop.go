@@ -6,20 +6,23 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
+
+	"github.com/apex/log"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/deprecate"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
 // ErrNoWindows when there is no build for windows (goos doesn't contain windows)
 var ErrNoWindows = errors.New("scoop requires a windows build")
 
-// ErrTokenTypeNotImplementedForScoop indicates that a new token type was not implemented for this pipe
-var ErrTokenTypeNotImplementedForScoop = errors.New("token type not implemented for scoop pipe")
-
 // Pipe for build
 type Pipe struct{}
 
@@ -27,32 +30,62 @@ func (Pipe) String() string {
 	return "scoop manifest"
 }
 
-// Publish scoop manifest
+// Publish scoop manifests
 func (Pipe) Publish(ctx *context.Context) error {
 	client, err := client.New(ctx)
 	if err != nil {
 		return err
 	}
-	return doRun(ctx, client)
+	for _, scoop := range ctx.Config.Scoops {
+		ok, err := condition.Check(ctx, scoop.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("scoop", scoop.Name).Info("skipped because its condition is false")
+			continue
+		}
+		if err := doRun(ctx, scoop, client); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Default sets the pipe defaults
 func (Pipe) Default(ctx *context.Context) error {
-	if ctx.Config.Scoop.Name == "" {
-		ctx.Config.Scoop.Name = ctx.Config.ProjectName
-	}
-	if ctx.Config.Scoop.CommitAuthor.Name == "" {
-		ctx.Config.Scoop.CommitAuthor.Name = "goreleaserbot"
+	if len(ctx.Config.Scoops) == 0 {
+		ctx.Config.Scoops = append(ctx.Config.Scoops, ctx.Config.Scoop)
+		if !reflect.DeepEqual(ctx.Config.Scoop, config.Scoop{}) {
+			deprecate.Notice(ctx, "scoop")
+		}
 	}
-	if ctx.Config.Scoop.CommitAuthor.Email == "" {
-		ctx.Config.Scoop.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+	for i := range ctx.Config.Scoops {
+		var scoop = &ctx.Config.Scoops[i]
+		if scoop.Name == "" {
+			scoop.Name = ctx.Config.ProjectName
+		}
+		if scoop.CommitAuthor.Name == "" {
+			scoop.CommitAuthor.Name = "goreleaserbot"
+		}
+		if scoop.CommitAuthor.Email == "" {
+			scoop.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+		}
+		if scoop.Homepage == "" {
+			scoop.Homepage = ctx.Config.Metadata.Homepage
+		}
+		if scoop.Description == "" {
+			scoop.Description = ctx.Config.Metadata.Description
+		}
+		if scoop.License == "" {
+			scoop.License = ctx.Config.Metadata.License
+		}
 	}
-
 	return nil
 }
 
-func doRun(ctx *context.Context, client client.Client) error {
-	if ctx.Config.Scoop.Bucket.Name == "" {
+func doRun(ctx *context.Context, scoop config.Scoop, client client.Client) error {
+	if scoop.Bucket.Name == "" {
 		return pipe.Skip("scoop section is not configured")
 	}
 
@@ -61,23 +94,24 @@ func doRun(ctx *context.Context, client client.Client) error {
 	// if ctx.Config.Release.Disable {
 	// }
 
-	if ctx.Config.Archive.Format == "binary" {
-		return pipe.Skip("archive format is binary")
-	}
-
-	var archives = ctx.Artifacts.Filter(
-		artifact.And(
-			artifact.ByGoos("windows"),
+	var filters = []artifact.Filter{
+		artifact.ByGoos("windows"),
+		artifact.Or(
 			artifact.ByType(artifact.UploadableArchive),
+			artifact.ByType(artifact.UploadableBinary),
 		),
-	).List()
+	}
+	if len(scoop.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(scoop.IDs...))
+	}
+	var archives = ctx.Artifacts.Filter(artifact.And(filters...)).List()
 	if len(archives) == 0 {
 		return ErrNoWindows
 	}
 
-	var path = ctx.Config.Scoop.Name + ".json"
+	var path = scoop.Name + ".json"
 
-	content, err := buildManifest(ctx, archives)
+	content, err := buildManifest(ctx, scoop, archives)
 	if err != nil {
 		return err
 	}
@@ -91,14 +125,22 @@ func doRun(ctx *context.Context, client client.Client) error {
 	if ctx.Config.Release.Disable {
 		return pipe.Skip("release is disabled")
 	}
-	return client.CreateFile(
+	if err := client.CreateFile(
 		ctx,
-		ctx.Config.Scoop.CommitAuthor,
-		ctx.Config.Scoop.Bucket,
+		scoop.CommitAuthor,
+		scoop.Bucket,
 		content.Bytes(),
 		path,
 		fmt.Sprintf("Scoop update for %s version %s", ctx.Config.ProjectName, ctx.Git.CurrentTag),
-	)
+	); err != nil {
+		return err
+	}
+	ctx.CommittedFiles = append(ctx.CommittedFiles, context.CommittedFile{
+		Repo:         scoop.Bucket,
+		Path:         path,
+		CommitAuthor: scoop.CommitAuthor,
+	})
+	return nil
 }
 
 // Manifest represents a scoop.sh App Manifest, more info:
@@ -114,41 +156,41 @@ type Manifest struct {
 
 // Resource represents a combination of a url and a binary name for an architecture
 type Resource struct {
-	URL  string   `json:"url"`  // URL to the archive
-	Bin  []string `json:"bin"`  // name of binary inside the archive
-	Hash string   `json:"hash"` // the archive checksum
+	URL        string   `json:"url"`                   // URL to the archive
+	Bin        []string `json:"bin"`                   // name of binary inside the archive
+	Hash       string   `json:"hash"`                  // the archive checksum
+	ExtractDir string   `json:"extract_dir,omitempty"` // folder inside the archive the binaries are in
 }
 
-func buildManifest(ctx *context.Context, artifacts []*artifact.Artifact) (bytes.Buffer, error) {
+func buildManifest(ctx *context.Context, scoop config.Scoop, artifacts []*artifact.Artifact) (bytes.Buffer, error) {
 	var result bytes.Buffer
+	var fields = []string{
+		scoop.Homepage,
+		scoop.License,
+		scoop.Description,
+	}
+	for i, field := range fields {
+		applied, err := tmpl.New(ctx).Apply(field)
+		if err != nil {
+			return result, err
+		}
+		fields[i] = applied
+	}
 	var manifest = Manifest{
 		Version:      ctx.Version,
 		Architecture: map[string]Resource{},
-		Homepage:     ctx.Config.Scoop.Homepage,
-		License:      ctx.Config.Scoop.License,
-		Description:  ctx.Config.Scoop.Description,
-		Persist:      ctx.Config.Scoop.Persist,
-	}
-
-	if ctx.Config.Scoop.URLTemplate == "" {
-		switch ctx.TokenType {
-		case context.TokenTypeGitHub:
-			ctx.Config.Scoop.URLTemplate = fmt.Sprintf(
-				"%s/%s/%s/releases/download/{{ .Tag }}/{{ .ArtifactName }}",
-				ctx.Config.GitHubURLs.Download,
-				ctx.Config.Release.GitHub.Owner,
-				ctx.Config.Release.GitHub.Name,
-			)
-		case context.TokenTypeGitLab:
-			ctx.Config.Scoop.URLTemplate = fmt.Sprintf(
-				"%s/%s/%s/uploads/{{ .ArtifactUploadHash }}/{{ .ArtifactName }}",
-				ctx.Config.GitLabURLs.Download,
-				ctx.Config.Release.GitLab.Owner,
-				ctx.Config.Release.GitLab.Name,
-			)
-		default:
-			return result, ErrTokenTypeNotImplementedForScoop
+		Homepage:     fields[0],
+		License:      fields[1],
+		Description:  fields[2],
+		Persist:      scoop.Persist,
+	}
+
+	if scoop.URLTemplate == "" {
+		urlTemplate, err := client.DownloadURLTemplate(ctx)
+		if err != nil {
+			return result, err
 		}
+		scoop.URLTemplate = urlTemplate
 	}
 
 	for _, artifact := range artifacts {
@@ -159,7 +201,7 @@ func buildManifest(ctx *context.Context, artifacts []*artifact.Artifact) (bytes.
 
 		url, err := tmpl.New(ctx).
 			WithArtifact(artifact, map[string]string{}).
-			Apply(ctx.Config.Scoop.URLTemplate)
+			Apply(scoop.URLTemplate)
 		if err != nil {
 			return result, err
 		}
@@ -170,9 +212,10 @@ func buildManifest(ctx *context.Context, artifacts []*artifact.Artifact) (bytes.
 		}
 
 		manifest.Architecture[arch] = Resource{
-			URL:  url,
-			Bin:  binaries(artifact),
-			Hash: sum,
+			URL:        url,
+			Bin:        binaries(artifact),
+			Hash:       sum,
+			ExtractDir: artifact.ExtraOr("WrappedIn", "").(string),
 		}
 	}
 
@@ -185,6 +228,10 @@ func buildManifest(ctx *context.Context, artifacts []*artifact.Artifact) (bytes.
 }
 
 func binaries(a *artifact.Artifact) []string {
+	// raw binaries are downloaded as is, so the bin is the file itself
+	if a.Type == artifact.UploadableBinary {
+		return []string{a.Name}
+	}
 	// nolint: prealloc
 	var bins []string
 	for _, b := range a.ExtraOr("Builds", []*artifact.Artifact{}).([]*artifact.Artifact) {
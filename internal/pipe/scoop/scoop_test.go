@@ -1,6 +1,7 @@
 package scoop
 
 import (
+	"encoding/json"
 	"flag"
 	"io/ioutil"
 	"os"
@@ -54,9 +55,95 @@ func TestDefault(t *testing.T) {
 		},
 	}
 	assert.NoError(t, Pipe{}.Default(ctx))
-	assert.Equal(t, ctx.Config.ProjectName, ctx.Config.Scoop.Name)
-	assert.NotEmpty(t, ctx.Config.Scoop.CommitAuthor.Name)
-	assert.NotEmpty(t, ctx.Config.Scoop.CommitAuthor.Email)
+	assert.Len(t, ctx.Config.Scoops, 1)
+	assert.Equal(t, ctx.Config.ProjectName, ctx.Config.Scoops[0].Name)
+	assert.NotEmpty(t, ctx.Config.Scoops[0].CommitAuthor.Name)
+	assert.NotEmpty(t, ctx.Config.Scoops[0].CommitAuthor.Email)
+	assert.False(t, ctx.Deprecated)
+}
+
+func TestDefaultDeprecated(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Scoop: config.Scoop{
+			Bucket: config.Repo{Owner: "foo", Name: "bar"},
+		},
+	})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Len(t, ctx.Config.Scoops, 1)
+	assert.Equal(t, config.Repo{Owner: "foo", Name: "bar"}, ctx.Config.Scoops[0].Bucket)
+	assert.Equal(t, "foo", ctx.Config.Scoops[0].Name)
+	assert.True(t, ctx.Deprecated)
+}
+
+func TestDefaultMultiple(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Scoops:      []config.Scoop{{Name: "foo"}, {Name: "bar"}},
+	})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Len(t, ctx.Config.Scoops, 2)
+	assert.Equal(t, "bar", ctx.Config.Scoops[1].Name)
+	assert.Equal(t, "goreleaserbot", ctx.Config.Scoops[1].CommitAuthor.Name)
+	assert.False(t, ctx.Deprecated)
+}
+
+func TestPublishConditionFalse(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Scoops: []config.Scoop{{
+			Bucket: config.Repo{Owner: "test", Name: "test"},
+			If:     "{{ .IsSnapshot }}",
+		}},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	assert.NoError(t, Pipe{}.Publish(ctx))
+}
+
+func TestRunPipeMultipleScoops(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var file = filepath.Join(folder, "archive")
+	require.NoError(t, ioutil.WriteFile(file, []byte("lorem ipsum"), 0644))
+	var ctx = context.New(config.Project{
+		ProjectName: "tools",
+		Dist:        folder,
+		GitHubURLs:  config.GitHubURLs{Download: "https://github.com"},
+		Release:     config.Release{GitHub: config.Repo{Owner: "test", Name: "test"}},
+		Scoops: []config.Scoop{
+			{Name: "foo", IDs: []string{"foo"}, Bucket: config.Repo{Owner: "test", Name: "bucket"}},
+			{Name: "bar", IDs: []string{"bar"}, Bucket: config.Repo{Owner: "test", Name: "bucket"}},
+		},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Version = "1.0.1"
+	ctx.Git.CurrentTag = "v1.0.1"
+	for _, id := range []string{"foo", "bar"} {
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   id + "_1.0.1_windows_amd64.zip",
+			Path:   file,
+			Goos:   "windows",
+			Goarch: "amd64",
+			Type:   artifact.UploadableArchive,
+			Extra: map[string]interface{}{
+				"ID": id,
+				"Builds": []*artifact.Artifact{
+					{Extra: map[string]interface{}{"Binary": id}},
+				},
+			},
+		})
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	for _, scoop := range ctx.Config.Scoops {
+		var client = &DummyClient{}
+		require.NoError(t, doRun(ctx, scoop, client))
+		var manifest Manifest
+		require.NoError(t, json.Unmarshal([]byte(client.Content), &manifest))
+		require.Equal(t, []string{scoop.Name + ".exe"}, manifest.Architecture["64bit"].Bin)
+		require.Contains(t, manifest.Architecture["64bit"].URL, scoop.Name+"_1.0.1_windows_amd64.zip")
+	}
+	require.Len(t, ctx.CommittedFiles, 2)
+	require.Equal(t, "foo.json", ctx.CommittedFiles[0].Path)
+	require.Equal(t, "bar.json", ctx.CommittedFiles[1].Path)
 }
 
 func Test_doRun(t *testing.T) {
@@ -331,7 +418,7 @@ func Test_doRun(t *testing.T) {
 				{Name: "foo_1.0.1_windows_amd64.tar.gz", Goos: "windows", Goarch: "amd64", Path: file},
 				{Name: "foo_1.0.1_windows_386.tar.gz", Goos: "windows", Goarch: "386", Path: file},
 			},
-			shouldErr(ErrTokenTypeNotImplementedForScoop.Error()),
+			shouldErr(client.ErrTokenTypeNotImplemented.Error()),
 		},
 		{
 			"no windows build",
@@ -536,7 +623,7 @@ func Test_doRun(t *testing.T) {
 			shouldErr("release is disabled"),
 		},
 		{
-			"no archive",
+			"binary format",
 			args{
 				&context.Context{
 					TokenType: context.TokenTypeGitHub,
@@ -555,7 +642,10 @@ func Test_doRun(t *testing.T) {
 							Format: "binary",
 						},
 						Release: config.Release{
-							Draft: true,
+							GitHub: config.Repo{
+								Owner: "test",
+								Name:  "test",
+							},
 						},
 						Scoop: config.Scoop{
 							Bucket: config.Repo{
@@ -570,10 +660,10 @@ func Test_doRun(t *testing.T) {
 				&DummyClient{},
 			},
 			[]*artifact.Artifact{
-				{Name: "foo_1.0.1_windows_amd64.tar.gz", Goos: "windows", Goarch: "amd64", Path: file},
-				{Name: "foo_1.0.1_windows_386.tar.gz", Goos: "windows", Goarch: "386", Path: file},
+				{Name: "foo_1.0.1_windows_amd64.exe", Goos: "windows", Goarch: "amd64", Path: file, Type: artifact.UploadableBinary},
+				{Name: "foo_1.0.1_windows_386.exe", Goos: "windows", Goarch: "386", Path: file, Type: artifact.UploadableBinary},
 			},
-			shouldErr("archive format is binary"),
+			shouldNotErr,
 		},
 	}
 	for _, tt := range tests {
@@ -583,7 +673,7 @@ func Test_doRun(t *testing.T) {
 				ctx.Artifacts.Add(a)
 			}
 			require.NoError(t, Pipe{}.Default(ctx))
-			tt.assertError(t, doRun(ctx, tt.args.client))
+			tt.assertError(t, doRun(ctx, ctx.Config.Scoops[0], tt.args.client))
 		})
 	}
 }
@@ -714,6 +804,72 @@ func Test_buildManifest(t *testing.T) {
 				},
 			},
 		},
+		{
+			"testdata/test_buildmanifest_gitea.json.golden",
+			&context.Context{
+				TokenType: context.TokenTypeGitea,
+				Git: context.GitInfo{
+					CurrentTag: "v1.0.1",
+				},
+				Version:   "1.0.1",
+				Artifacts: artifact.New(),
+				Config: config.Project{
+					GiteaURLs: config.GiteaURLs{
+						API: "https://gitea.com/api/v1",
+					},
+					Dist:        ".",
+					ProjectName: "run-pipe",
+					Release: config.Release{
+						Gitea: config.Repo{
+							Owner: "test",
+							Name:  "test",
+						},
+					},
+					Scoop: config.Scoop{
+						Bucket: config.Repo{
+							Owner: "test",
+							Name:  "test",
+						},
+						Description: "A run pipe test formula",
+						Homepage:    "https://gitea.com/goreleaser",
+					},
+				},
+			},
+		},
+		{
+			"testdata/test_buildmanifest_templated.json.golden",
+			&context.Context{
+				TokenType: context.TokenTypeGitHub,
+				Git: context.GitInfo{
+					CurrentTag: "v1.0.1",
+				},
+				Version:   "1.0.1",
+				Artifacts: artifact.New(),
+				Env:       map[string]string{"LICENSE": "MIT"},
+				Config: config.Project{
+					GitHubURLs: config.GitHubURLs{
+						Download: "https://github.com",
+					},
+					Dist:        ".",
+					ProjectName: "run-pipe",
+					Release: config.Release{
+						GitHub: config.Repo{
+							Owner: "test",
+							Name:  "test",
+						},
+					},
+					Scoop: config.Scoop{
+						Bucket: config.Repo{
+							Owner: "test",
+							Name:  "test",
+						},
+						Description: "{{ .ProjectName }} {{ .Version }}",
+						Homepage:    "https://github.com/goreleaser/{{ .ProjectName }}",
+						License:     "{{ .Env.LICENSE }}",
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -721,7 +877,7 @@ func Test_buildManifest(t *testing.T) {
 			var ctx = tt.ctx
 			err := Pipe{}.Default(ctx)
 			require.NoError(t, err)
-			out, err := buildManifest(ctx, []*artifact.Artifact{
+			out, err := buildManifest(ctx, ctx.Config.Scoops[0], []*artifact.Artifact{
 				{
 					Name:   "foo_1.0.1_windows_amd64.tar.gz",
 					Goos:   "windows",
@@ -778,6 +934,98 @@ func Test_buildManifest(t *testing.T) {
 	}
 }
 
+func Test_buildManifestWrappedInDir(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	var file = filepath.Join(folder, "archive")
+	require.NoError(t, ioutil.WriteFile(file, []byte("lorem ipsum"), 0644))
+
+	var golden = "testdata/test_buildmanifest_wrapped_in_dir.json.golden"
+	var ctx = context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			Download: "https://github.com",
+		},
+		ProjectName: "run-pipe",
+		Release: config.Release{
+			GitHub: config.Repo{
+				Owner: "test",
+				Name:  "test",
+			},
+		},
+		Scoop: config.Scoop{
+			Bucket: config.Repo{
+				Owner: "test",
+				Name:  "test",
+			},
+			Description: "A run pipe test formula",
+			Homepage:    "https://github.com/goreleaser",
+		},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Git.CurrentTag = "v1.0.1"
+	ctx.Version = "1.0.1"
+	require.NoError(t, Pipe{}.Default(ctx))
+	out, err := buildManifest(ctx, ctx.Config.Scoops[0], []*artifact.Artifact{
+		{
+			Name:   "foo_1.0.1_windows_amd64.zip",
+			Goos:   "windows",
+			Goarch: "amd64",
+			Path:   file,
+			Extra: map[string]interface{}{
+				"WrappedIn": "foo_1.0.1_windows_amd64",
+				"Builds": []*artifact.Artifact{
+					{Extra: map[string]interface{}{"Binary": "foo"}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	if *update {
+		require.NoError(t, ioutil.WriteFile(golden, out.Bytes(), 0655))
+	}
+	bts, err := ioutil.ReadFile(golden)
+	require.NoError(t, err)
+	require.Equal(t, string(bts), out.String())
+}
+
+func Test_buildManifestInvalidTemplate(t *testing.T) {
+	for name, scoop := range map[string]config.Scoop{
+		"description": {Description: "{{ .Nope }"},
+		"homepage":    {Homepage: "{{ .Nope }"},
+		"license":     {License: "{{ .Nope }"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{Scoop: scoop})
+			ctx.TokenType = context.TokenTypeGitHub
+			_, err := buildManifest(ctx, scoop, []*artifact.Artifact{})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestBinaries(t *testing.T) {
+	require.Equal(t, []string{"foo.exe", "bar.exe"}, binaries(&artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "foo_1.0.1_windows_amd64.zip",
+		Extra: map[string]interface{}{
+			"Builds": []*artifact.Artifact{
+				{Extra: map[string]interface{}{"Binary": "foo"}},
+				{Extra: map[string]interface{}{"Binary": "bar"}},
+			},
+		},
+	}))
+	require.Equal(t, []string{"foo_1.0.1_windows_amd64.exe"}, binaries(&artifact.Artifact{
+		Type: artifact.UploadableBinary,
+		Name: "foo_1.0.1_windows_amd64.exe",
+		Extra: map[string]interface{}{
+			"Builds": []*artifact.Artifact{
+				{Extra: map[string]interface{}{"Binary": "foo"}},
+			},
+		},
+	}))
+}
+
 type DummyClient struct {
 	CreatedFile bool
 	Content     string
@@ -0,0 +1,255 @@
+// Package scp provides a Pipe that copies artifacts to remote hosts over scp
+// or sftp
+package scp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+const (
+	protocolSCP  = "scp"
+	protocolSFTP = "sftp"
+)
+
+// home matches the ~ or ~user prefix of a path, which the remote shell
+// expands to a home folder
+var home = regexp.MustCompile(`^~[\w.-]*(/|$)`)
+
+// Pipe for scp and sftp uploads
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "scp and sftp uploads"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.SCPs {
+		var scp = &ctx.Config.SCPs[i]
+		if scp.Host == "" {
+			return fmt.Errorf("scps: host cannot be empty")
+		}
+		if scp.Protocol == "" {
+			scp.Protocol = protocolSCP
+		}
+		if scp.Protocol != protocolSCP && scp.Protocol != protocolSFTP {
+			return fmt.Errorf("scps: invalid protocol %q, use scp or sftp", scp.Protocol)
+		}
+		if scp.Folder == "" {
+			scp.Folder = "{{ .ProjectName }}/{{ .Tag }}"
+		}
+	}
+	return nil
+}
+
+// Publish the artifacts to the remote hosts
+func (Pipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.SCPs) == 0 {
+		return pipe.Skip("scps section is not configured")
+	}
+	for _, scp := range ctx.Config.SCPs {
+		ok, err := condition.Check(ctx, scp.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("host", scp.Host).Info("skipped because its condition is false")
+			continue
+		}
+		if err := doPublish(ctx, scp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remote is the templated destination of a scp config
+type remote struct {
+	host, key, folder string
+}
+
+func doPublish(ctx *context.Context, scp config.SCP) error {
+	var template = tmpl.New(ctx)
+	var r remote
+	for _, field := range []struct {
+		name, value string
+		dst         *string
+	}{
+		{"host", scp.Host, &r.host},
+		{"key", scp.Key, &r.key},
+		{"folder", scp.Folder, &r.folder},
+	} {
+		value, err := template.Apply(field.value)
+		if err != nil {
+			return errors.Wrapf(err, "failed to template the %s", field.name)
+		}
+		*field.dst = value
+	}
+	user, err := template.Apply(scp.User)
+	if err != nil {
+		return errors.Wrap(err, "failed to template the user")
+	}
+	if user != "" {
+		r.host = user + "@" + r.host
+	}
+	// relative folders are relative to the home of the user
+	r.folder = strings.TrimSuffix(r.folder, "/")
+
+	var artifacts = ctx.Artifacts.Filter(filterFor(scp)).List()
+	if len(artifacts) == 0 {
+		return pipe.Warn(ctx, fmt.Sprintf("no artifacts found to upload to %s", r.host))
+	}
+	log.WithField("host", r.host).
+		WithField("folder", r.folder).
+		WithField("files", len(artifacts)).
+		Info("uploading")
+	if scp.Protocol == protocolSFTP {
+		return sftp(ctx, scp, r, artifacts)
+	}
+	if err := run(ctx, "ssh", nil, append(sshArgs(scp, r, "-p"), r.host, "mkdir -p "+shellQuote(r.folder))...); err != nil {
+		return fmt.Errorf("failed to create %s on %s: %s", r.folder, r.host, err.Error())
+	}
+	for _, a := range artifacts {
+		if err := upload(ctx, scp, r, a); err != nil {
+			return fmt.Errorf("failed to upload %s to %s: %s", a.Name, r.host, err.Error())
+		}
+	}
+	return nil
+}
+
+// upload writes the artifact to the folder with the remote shell, which gets
+// its path quoted: depending on its version, scp either passes the remote
+// path to the shell or uses it as is, so it can't be quoted for both.
+func upload(ctx *context.Context, scp config.SCP, r remote, a *artifact.Artifact) error {
+	f, err := os.Open(a.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint: errcheck
+	var dst = shellQuote(path.Join(r.folder, a.Name))
+	return run(ctx, "ssh", f, append(sshArgs(scp, r, "-p"), r.host, "cat > "+dst)...)
+}
+
+// sftp uploads the artifacts with a single sftp batch, creating the folder
+// first
+func sftp(ctx *context.Context, scp config.SCP, r remote, artifacts []*artifact.Artifact) error {
+	// sftp starts in the home of the user, but doesn't expand ~
+	var folder = r.folder
+	if folder == "~" || strings.HasPrefix(folder, "~/") {
+		folder = strings.TrimPrefix(folder[1:], "/")
+	}
+	var batch bytes.Buffer
+	var dir string
+	if strings.HasPrefix(folder, "/") {
+		dir = "/"
+	}
+	// sftp can't create parent folders, and the - ignores the ones that
+	// exist already
+	for _, part := range strings.Split(strings.Trim(folder, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		dir = path.Join(dir, part)
+		fmt.Fprintf(&batch, "-mkdir %s\n", sftpQuote(dir))
+	}
+	for _, a := range artifacts {
+		var dst = path.Join(folder, a.Name)
+		// each command of the batch is a line
+		if strings.ContainsAny(a.Path+dst, "\r\n") {
+			return fmt.Errorf("failed to upload %s to %s: sftp can't handle line breaks in paths", a.Name, r.host)
+		}
+		fmt.Fprintf(&batch, "put %s %s\n", sftpQuote(a.Path), sftpQuote(dst))
+	}
+	var args = append(sshArgs(scp, r, "-P"), "-b", "-", r.host)
+	if err := run(ctx, "sftp", &batch, args...); err != nil {
+		return fmt.Errorf("failed to upload to %s: %s", r.host, err.Error())
+	}
+	return nil
+}
+
+// shellQuote quotes a path for the remote shell, leaving its ~ or ~user
+// prefix unquoted so it is still expanded
+func shellQuote(s string) string {
+	var prefix = home.FindString(s)
+	s = s[len(prefix):]
+	if s == "" {
+		return prefix
+	}
+	return prefix + "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// sftpQuote quotes a path for a sftp batch: inside double quotes, only
+// backslashes and double quotes need escaping, as sftp itself escapes the
+// glob characters
+func sftpQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// sshArgs returns the options of the ssh tools for the given config, using
+// the given flag for the port, as scp and sftp use -P and ssh -p
+func sshArgs(scp config.SCP, r remote, portFlag string) []string {
+	// batch mode makes them fail instead of asking for passwords
+	var args = []string{"-o", "BatchMode=yes"}
+	if scp.Port != 0 {
+		args = append(args, portFlag, strconv.Itoa(scp.Port))
+	}
+	if r.key != "" {
+		args = append(args, "-i", r.key)
+	}
+	return args
+}
+
+// run runs the given tool, returning its output as the error if it fails
+func run(ctx *context.Context, tool string, stdin io.Reader, args ...string) error {
+	if _, err := exec.LookPath(tool); err != nil {
+		return fmt.Errorf("%s not present in $PATH", tool)
+	}
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, tool, args...)
+	cmd.Env = ctx.Env.Strings()
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) == 0 {
+			return err
+		}
+		return errors.New(string(out))
+	}
+	return nil
+}
+
+func filterFor(scp config.SCP) artifact.Filter {
+	var excluded []artifact.Filter
+	if !scp.Checksum {
+		excluded = append(excluded, artifact.ByType(artifact.Checksum))
+	}
+	if !scp.Signature {
+		excluded = append(excluded,
+			artifact.ByType(artifact.Signature),
+			artifact.ByType(artifact.Certificate),
+		)
+	}
+	var filter = artifact.And(artifact.ByUploadable(), artifact.Not(artifact.Or(excluded...)))
+	if len(scp.IDs) > 0 {
+		filter = artifact.And(filter, artifact.ByIDs(scp.IDs...))
+	}
+	return filter
+}
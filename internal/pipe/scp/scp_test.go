@@ -0,0 +1,242 @@
+package scp
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTools puts fake ssh and sftp in the PATH, which log their arguments
+// and stdin, returning a func that restores the PATH.
+func fakeTools(t *testing.T, folder, log string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"ssh":  `echo "ssh $@" >> ` + log + "\ncat >> " + log + "\n",
+		"sftp": `echo "sftp $@" >> ` + log + "\ncat >> " + log + "\n",
+	})
+}
+
+func scpContext(t *testing.T, folder string, scp config.SCP) *context.Context {
+	var ctx = context.New(config.Project{
+		ProjectName: "mybin",
+		SCPs:        []config.SCP{scp},
+	})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Env = map[string]string{"SSH_KEY": "id_ed25519"}
+	for _, a := range []*artifact.Artifact{
+		{Type: artifact.UploadableArchive, Name: "mybin.tar.gz", Extra: map[string]interface{}{"ID": "default"}},
+		{Type: artifact.LinuxPackage, Name: "mybin.deb", Extra: map[string]interface{}{"ID": "nfpm"}},
+		{Type: artifact.SBOM, Name: "mybin.tar.gz.sbom.json", Extra: map[string]interface{}{"ID": "default"}},
+		{Type: artifact.Binary, Name: "mybin", Extra: map[string]interface{}{"ID": "default"}},
+		{Type: artifact.Checksum, Name: "checksums.txt"},
+	} {
+		a.Path = filepath.Join(folder, a.Name)
+		require.NoError(t, ioutil.WriteFile(a.Path, []byte(a.Name), 0644))
+		ctx.Artifacts.Add(a)
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func readFile(t *testing.T, path string) string {
+	bts, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	return string(bts)
+}
+
+func TestPublishSCP(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	var ctx = scpContext(t, folder, config.SCP{
+		Host:     "mirror.example.com",
+		User:     "deploy",
+		Port:     2222,
+		Key:      "{{ .Env.SSH_KEY }}",
+		Checksum: true,
+	})
+	require.NoError(t, Pipe{}.Publish(ctx))
+	var ssh = "ssh -o BatchMode=yes -p 2222 -i id_ed25519 deploy@mirror.example.com "
+	require.Equal(t, ssh+"mkdir -p 'mybin/v1.0.0'\n"+
+		ssh+"cat > 'mybin/v1.0.0/mybin.tar.gz'\nmybin.tar.gz"+
+		ssh+"cat > 'mybin/v1.0.0/mybin.deb'\nmybin.deb"+
+		ssh+"cat > 'mybin/v1.0.0/mybin.tar.gz.sbom.json'\nmybin.tar.gz.sbom.json"+
+		ssh+"cat > 'mybin/v1.0.0/checksums.txt'\nchecksums.txt",
+		readFile(t, log))
+}
+
+func TestPublishSCPQuoted(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	var ctx = scpContext(t, folder, config.SCP{
+		Host:   "mirror.example.com",
+		Folder: "~/it's $(mybin)",
+		IDs:    []string{"nfpm"},
+	})
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.Equal(t, "ssh -o BatchMode=yes mirror.example.com mkdir -p ~/'it'\\''s $(mybin)'\n"+
+		"ssh -o BatchMode=yes mirror.example.com cat > ~/'it'\\''s $(mybin)/mybin.deb'\nmybin.deb",
+		readFile(t, log))
+}
+
+func TestPublishSFTP(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	var ctx = scpContext(t, folder, config.SCP{
+		Protocol: "sftp",
+		Host:     "mirror.example.com",
+		Folder:   "/srv/{{ .ProjectName }}/",
+		IDs:      []string{"nfpm"},
+	})
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.Equal(t, "sftp -o BatchMode=yes -b - mirror.example.com\n"+
+		"-mkdir \"/srv\"\n"+
+		"-mkdir \"/srv/mybin\"\n"+
+		"put \""+filepath.Join(folder, "mybin.deb")+"\" \"/srv/mybin/mybin.deb\"\n",
+		readFile(t, log))
+}
+
+func TestPublishSFTPHome(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	var ctx = scpContext(t, folder, config.SCP{
+		Protocol: "sftp",
+		Host:     "mirror.example.com",
+		Folder:   `~/it's "{{ .ProjectName }}"`,
+		IDs:      []string{"nfpm"},
+	})
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.Equal(t, "sftp -o BatchMode=yes -b - mirror.example.com\n"+
+		`-mkdir "it's \"mybin\""`+"\n"+
+		`put "`+filepath.Join(folder, "mybin.deb")+`" "it's \"mybin\"/mybin.deb"`+"\n",
+		readFile(t, log))
+}
+
+func TestPublishFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeTools(t, folder, filepath.Join(folder, "tools.log"))()
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "bin", "ssh"),
+		[]byte("#!/bin/sh\ncase \"$*\" in *cat*) echo 'Permission denied'; exit 1;; esac\n"),
+		0755,
+	))
+	var ctx = scpContext(t, folder, config.SCP{Host: "mirror.example.com"})
+	require.EqualError(t, Pipe{}.Publish(ctx), "failed to upload mybin.tar.gz to mirror.example.com: Permission denied\n")
+}
+
+func TestPublishNoTool(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = scpContext(t, folder, config.SCP{Protocol: "sftp", Host: "mirror.example.com"})
+	var path = os.Getenv("PATH")
+	defer func() {
+		require.NoError(t, os.Setenv("PATH", path))
+	}()
+	require.NoError(t, os.Setenv("PATH", ""))
+	require.EqualError(t, Pipe{}.Publish(ctx), "failed to upload to mirror.example.com: sftp not present in $PATH")
+}
+
+func TestPublishNoArtifacts(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	var ctx = scpContext(t, folder, config.SCP{
+		Host: "mirror.example.com",
+		IDs:  []string{"nope"},
+	})
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.NoFileExists(t, log)
+
+	ctx.Config.Strict = true
+	require.EqualError(t, Pipe{}.Publish(ctx), "strict mode: no artifacts found to upload to mirror.example.com")
+}
+
+func TestShellQuote(t *testing.T) {
+	for folder, expected := range map[string]string{
+		"mybin/v1.0.0":     "mybin/v1.0.0",
+		"/srv/it's mine":   "/srv/it's mine",
+		"~/mybin/$HOME":    "/home/deploy/mybin/$HOME",
+		"~":                "/home/deploy",
+		"~root/mybin":      "/root/mybin",
+		"~nope;echo/mybin": "~nope;echo/mybin",
+		"mybin/~/v1.0.0":   "mybin/~/v1.0.0",
+	} {
+		t.Run(folder, func(t *testing.T) {
+			var cmd = exec.Command("sh", "-c", "printf %s "+shellQuote(folder))
+			cmd.Env = []string{"HOME=/home/deploy"}
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err)
+			require.Equal(t, expected, string(out))
+		})
+	}
+}
+
+func TestSFTPQuote(t *testing.T) {
+	require.Equal(t, `"/srv/mybin"`, sftpQuote("/srv/mybin"))
+	require.Equal(t, `"/srv/my bin/*.deb"`, sftpQuote("/srv/my bin/*.deb"))
+	require.Equal(t, `"it's \"mine\""`, sftpQuote(`it's "mine"`))
+	require.Equal(t, `"c:\\mybin"`, sftpQuote(`c:\mybin`))
+	require.Equal(t, `"/srv/mybin/é"`, sftpQuote("/srv/mybin/é"))
+}
+
+func TestPublishSFTPLineBreak(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	var ctx = scpContext(t, folder, config.SCP{
+		Protocol: "sftp",
+		Host:     "mirror.example.com",
+		Folder:   "mybin\nrm -r /",
+		IDs:      []string{"nfpm"},
+	})
+	require.EqualError(t, Pipe{}.Publish(ctx), "failed to upload mybin.deb to mirror.example.com: sftp can't handle line breaks in paths")
+	require.NoFileExists(t, log)
+}
+
+func TestPublishSkipCondition(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "tools.log")
+	defer fakeTools(t, folder, log)()
+	var ctx = scpContext(t, folder, config.SCP{Host: "mirror.example.com", If: "false"})
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.NoFileExists(t, log)
+}
+
+func TestPublishNotConfigured(t *testing.T) {
+	require.True(t, pipe.IsSkip(Pipe{}.Publish(context.New(config.Project{}))))
+}
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{SCPs: []config.SCP{{Host: "example.com"}}})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "scp", ctx.Config.SCPs[0].Protocol)
+	require.Equal(t, "{{ .ProjectName }}/{{ .Tag }}", ctx.Config.SCPs[0].Folder)
+}
+
+func TestDefaultInvalid(t *testing.T) {
+	require.EqualError(t, Pipe{}.Default(context.New(config.Project{
+		SCPs: []config.SCP{{}},
+	})), "scps: host cannot be empty")
+	require.EqualError(t, Pipe{}.Default(context.New(config.Project{
+		SCPs: []config.SCP{{Host: "example.com", Protocol: "ftp"}},
+	})), `scps: invalid protocol "ftp", use scp or sftp`)
+}
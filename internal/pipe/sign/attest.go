@@ -0,0 +1,159 @@
+package sign
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/pipe/docker"
+	"github.com/goreleaser/goreleaser/internal/pipe/provenance"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// AttestPipe attaches SBOM and provenance attestations to the pushed docker
+// images, with cosign.
+type AttestPipe struct{}
+
+func (AttestPipe) String() string {
+	return "attesting docker images"
+}
+
+// Publish attests the docker images which have attestations configured.
+func (AttestPipe) Publish(ctx *context.Context) error {
+	var images = ctx.Artifacts.Filter(func(a *artifact.Artifact) bool {
+		_, ok := a.Extra["Attest"]
+		return a.Type == artifact.DockerImage && ok
+	}).List()
+	if len(images) == 0 {
+		return pipe.Skip("no docker images to attest")
+	}
+	if ctx.SkipSign {
+		return pipe.ErrSkipSignEnabled
+	}
+	// cosign pushes the attestations next to the images
+	creds, err := docker.Login(ctx)
+	if err != nil {
+		return err
+	}
+	defer creds.Logout()
+	for _, image := range images {
+		if err := attestImage(ctx, creds, image); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func attestImage(ctx *context.Context, creds *docker.Credentials, image *artifact.Artifact) error {
+	var cfg = image.Extra["Attest"].(config.DockerAttest)
+	var digest = image.ExtraOr("Digest", "").(string)
+	if digest == "" {
+		return fmt.Errorf("attest: digest of %s is unknown", image.Name)
+	}
+	var ref = image.Name + "@" + digest
+	if cfg.SBOM {
+		path, err := imageSBOM(ctx, creds, cfg, image, ref)
+		if err != nil {
+			return err
+		}
+		if err := attest(ctx, creds, cfg, image, ref, "spdxjson", path); err != nil {
+			return err
+		}
+	}
+	if cfg.Provenance {
+		path, err := imageProvenance(ctx)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(path) // nolint: errcheck
+		if err := attest(ctx, creds, cfg, image, ref, "slsaprovenance", path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// imageSBOM catalogs the pushed image with syft, writing its SBOM to the dist
+// folder.
+func imageSBOM(ctx *context.Context, creds *docker.Credentials, cfg config.DockerAttest, image *artifact.Artifact, ref string) (string, error) {
+	var name = strings.NewReplacer("/", "_", ":", "_").Replace(image.Name) + ".att.sbom.json"
+	var path = filepath.Join(ctx.Config.Dist, name)
+	log.WithField("image", image.Name).WithField("document", name).Info("cataloging")
+	if err := run(ctx, creds, cfg, image, nil, "syft", ref, "--output", "spdx-json="+path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// imageProvenance writes the SLSA provenance of the release to a temporary
+// file
+func imageProvenance(ctx *context.Context) (string, error) {
+	predicate, err := provenance.NewPredicate(ctx, ctx.Config.Provenance)
+	if err != nil {
+		return "", err
+	}
+	bts, err := json.Marshal(predicate)
+	if err != nil {
+		return "", err
+	}
+	f, err := ioutil.TempFile("", "goreleaserprovenance")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() // nolint: errcheck
+	if _, err := f.Write(bts); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func attest(ctx *context.Context, creds *docker.Credentials, cfg config.DockerAttest, image *artifact.Artifact, ref, kind, predicate string) error {
+	key, cleanup, err := keyFile(ctx, config.Sign{Key: cfg.Key, KeyFile: cfg.KeyFile})
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	var args = []string{"attest", "--yes", "--type", kind, "--predicate", predicate}
+	var env []string
+	if key != "" {
+		args = append(args, "--key="+key)
+	} else {
+		// keyless, like the docker signs
+		env = append(env, "COSIGN_EXPERIMENTAL=1")
+	}
+	log.WithField("image", image.Name).WithField("type", kind).Info("attesting")
+	return run(ctx, creds, cfg, image, env, "cosign", append(args, ref)...)
+}
+
+func run(ctx *context.Context, creds *docker.Credentials, cfg config.DockerAttest, image *artifact.Artifact, env []string, name string, args ...string) error {
+	var template = tmpl.New(ctx).WithExtraFields(tmpl.Fields{
+		"Image":  image.Name,
+		"Digest": image.ExtraOr("Digest", ""),
+	})
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(creds.Env(ctx), env...)
+	for _, e := range cfg.Env {
+		s, err := template.Apply(e)
+		if err != nil {
+			return errors.Wrapf(err, "attest: failed to execute env template '%s'", e)
+		}
+		cmd.Env = append(cmd.Env, s)
+	}
+	log.WithField("cmd", cmd.Args).Debug("running")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("attest: %s failed for %s with %q", name, image.Name, string(out))
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package sign
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttestSkipped(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:  "goreleaser/test:v1.0.0",
+		Type:  artifact.DockerImage,
+		Extra: map[string]interface{}{"Digest": digest},
+	})
+	testlib.AssertSkipped(t, AttestPipe{}.Publish(ctx))
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "goreleaser/test:latest",
+		Type: artifact.DockerImage,
+		Extra: map[string]interface{}{
+			"Digest": digest,
+			"Attest": config.DockerAttest{SBOM: true},
+		},
+	})
+	ctx.SkipSign = true
+	assert.Equal(t, pipe.ErrSkipSignEnabled, AttestPipe{}.Publish(ctx))
+}
+
+// fakeSyft puts a fake syft in the PATH, which writes an empty document to
+// its spdx-json output.
+func fakeSyft(t *testing.T, folder string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"syft": "echo '{}' > \"${3#spdx-json=}\"\n",
+	})
+}
+
+func TestAttest(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "cosign.log")
+	defer fakeCosign(t, folder, log)()
+	defer fakeSyft(t, folder)()
+
+	var ctx = context.New(config.Project{Dist: folder})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Env = map[string]string{"COSIGN_KEY": "secret"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "goreleaser/test:v1.0.0",
+		Type: artifact.DockerImage,
+		Extra: map[string]interface{}{
+			"Digest": digest,
+			"Attest": config.DockerAttest{SBOM: true, Provenance: true},
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "goreleaser/test:latest",
+		Type: artifact.DockerImage,
+		Extra: map[string]interface{}{
+			"Digest": digest,
+			"Attest": config.DockerAttest{Provenance: true, Key: "{{ .Env.COSIGN_KEY }}"},
+		},
+	})
+	assert.NoError(t, AttestPipe{}.Publish(ctx))
+
+	assert.FileExists(t, filepath.Join(folder, "goreleaser_test_v1.0.0.att.sbom.json"))
+	bts, err := ioutil.ReadFile(log)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^"+
+		"1 attest --yes --type spdxjson --predicate "+filepath.Join(folder, "goreleaser_test_v1.0.0.att.sbom.json")+" goreleaser/test:v1.0.0@"+digest+"\n"+
+		"1 attest --yes --type slsaprovenance --predicate [^ ]+ goreleaser/test:v1.0.0@"+digest+"\n"+
+		" attest --yes --type slsaprovenance --predicate [^ ]+ --key=[^ ]+ goreleaser/test:latest@"+digest+"\n$", string(bts))
+}
+
+func TestAttestFailed(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeCosign(t, folder, filepath.Join(folder, "cosign.log"))()
+
+	var ctx = context.New(config.Project{Dist: folder})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "goreleaser/test:v1.0.0",
+		Type: artifact.DockerImage,
+		Extra: map[string]interface{}{
+			"Digest": digest,
+			"Attest": config.DockerAttest{Provenance: true, Env: []string{"FAIL=1"}},
+		},
+	})
+	assert.EqualError(t, AttestPipe{}.Publish(ctx), `attest: cosign failed for goreleaser/test:v1.0.0 with "failed\n"`)
+}
+
+func TestAttestNoDigest(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:  "goreleaser/test:v1.0.0",
+		Type:  artifact.DockerImage,
+		Extra: map[string]interface{}{"Attest": config.DockerAttest{SBOM: true}},
+	})
+	assert.EqualError(t, AttestPipe{}.Publish(ctx), "attest: digest of goreleaser/test:v1.0.0 is unknown")
+}
+
+func TestImageProvenance(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.Git.URL = "https://github.com/goreleaser/test.git"
+	path, err := imageProvenance(ctx)
+	assert.NoError(t, err)
+	defer os.Remove(path) // nolint: errcheck
+	bts, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(bts), `"uri":"git+https://github.com/goreleaser/test.git"`)
+}
@@ -0,0 +1,168 @@
+package sign
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/pipe/docker"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// DockerPipe signs the pushed docker images.
+type DockerPipe struct{}
+
+func (DockerPipe) String() string {
+	return "signing docker images"
+}
+
+// Default sets the Pipes defaults.
+func (DockerPipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.DockerSigns {
+		cfg := &ctx.Config.DockerSigns[i]
+		if cfg.Cmd == "" {
+			cfg.Cmd = "cosign"
+		}
+		if cfg.Artifacts == "" {
+			cfg.Artifacts = "all"
+		}
+		if cfg.Cmd == "cosign" && len(cfg.Args) == 0 {
+			cfg.Args = []string{"sign", "--yes", "${artifact}@${digest}"}
+			if cfg.Key != "" || cfg.KeyFile != "" {
+				cfg.Args = []string{"sign", "--yes", "--key=${key}", "${artifact}@${digest}"}
+			} else {
+				// keyless signing, with a certificate issued for the OIDC
+				// identity of whoever runs the release
+				cfg.Env = append(cfg.Env, "COSIGN_EXPERIMENTAL=1")
+			}
+		}
+		if _, err := dockerFilter(*cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Publish signs the docker images pushed by the docker pipe.
+func (DockerPipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.DockerSigns) == 0 {
+		return pipe.Skip("docker_signs section is not configured")
+	}
+	if ctx.SkipSign {
+		return pipe.ErrSkipSignEnabled
+	}
+	// cosign pushes the signatures next to the images
+	creds, err := docker.Login(ctx)
+	if err != nil {
+		return err
+	}
+	defer creds.Logout()
+	for _, cfg := range ctx.Config.DockerSigns {
+		ok, err := condition.Check(ctx, cfg.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("artifacts", cfg.Artifacts).Info("skipped because its condition is false")
+			continue
+		}
+		f, err := dockerFilter(cfg)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			continue
+		}
+		for _, image := range ctx.Artifacts.Filter(f).List() {
+			if err := signImage(ctx, creds, cfg, image); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dockerFilter returns the filter of the images and manifest lists to sign,
+// if any
+func dockerFilter(cfg config.Sign) (artifact.Filter, error) {
+	switch cfg.Artifacts {
+	case "all":
+		return artifact.Or(
+			artifact.ByType(artifact.DockerImage),
+			artifact.ByType(artifact.DockerManifest),
+		), nil
+	case "images":
+		return artifact.ByType(artifact.DockerImage), nil
+	case "manifests":
+		return artifact.ByType(artifact.DockerManifest), nil
+	case "none":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("invalid list of docker images to sign: %s", cfg.Artifacts)
+}
+
+// signImage signs the given image by its digest, as signing a tag may sign
+// whatever image it points to at the time.
+func signImage(ctx *context.Context, creds *docker.Credentials, cfg config.Sign, image *artifact.Artifact) error {
+	var digest = image.ExtraOr("Digest", "").(string)
+	if digest == "" {
+		return fmt.Errorf("sign: digest of %s is unknown", image.Name)
+	}
+	env := map[string]string{}
+	for k, v := range ctx.Env {
+		env[k] = v
+	}
+	env["artifact"] = image.Name
+	env["digest"] = digest
+	key, cleanup, err := keyFile(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	env["key"] = key
+
+	var template = tmpl.New(ctx).WithExtraFields(tmpl.Fields{
+		"Image":  image.Name,
+		"Digest": digest,
+	})
+	// nolint:prealloc
+	var args []string
+	for _, a := range cfg.Args {
+		s, err := template.Apply(expand(a, env))
+		if err != nil {
+			return errors.Wrapf(err, "sign: failed to execute arg template '%s'", a)
+		}
+		args = append(args, s)
+	}
+
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, cfg.Cmd, args...)
+	cmd.Env = creds.Env(ctx)
+	for _, e := range cfg.Env {
+		s, err := template.Apply(e)
+		if err != nil {
+			return errors.Wrapf(err, "sign: failed to execute env template '%s'", e)
+		}
+		cmd.Env = append(cmd.Env, s)
+	}
+	in, err := stdin(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		defer in.Close() // nolint: errcheck
+		cmd.Stdin = in
+	}
+	log.WithField("image", image.Name).WithField("digest", digest).Info("signing")
+	log.WithField("cmd", cmd.Args).Debug("running")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("sign: %s failed to sign %s with %q", cfg.Cmd, image.Name, string(output))
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package sign
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+const digest = "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+func TestDockerSignDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		DockerSigns: []config.Sign{{}, {KeyFile: "cosign.key"}},
+	})
+	assert.NoError(t, DockerPipe{}.Default(ctx))
+	assert.Equal(t, config.Sign{
+		Cmd:       "cosign",
+		Artifacts: "all",
+		Args:      []string{"sign", "--yes", "${artifact}@${digest}"},
+		Env:       []string{"COSIGN_EXPERIMENTAL=1"},
+	}, ctx.Config.DockerSigns[0])
+	assert.Equal(t, []string{"sign", "--yes", "--key=${key}", "${artifact}@${digest}"}, ctx.Config.DockerSigns[1].Args)
+	assert.Empty(t, ctx.Config.DockerSigns[1].Env)
+}
+
+func TestDockerSignDefaultInvalidArtifacts(t *testing.T) {
+	ctx := context.New(config.Project{
+		DockerSigns: []config.Sign{{Artifacts: "archive"}},
+	})
+	assert.EqualError(t, DockerPipe{}.Default(ctx), "invalid list of docker images to sign: archive")
+}
+
+func TestDockerSignSkipped(t *testing.T) {
+	testlib.AssertSkipped(t, DockerPipe{}.Publish(context.New(config.Project{})))
+
+	ctx := context.New(config.Project{
+		DockerSigns: []config.Sign{{}},
+	})
+	ctx.SkipSign = true
+	assert.Equal(t, pipe.ErrSkipSignEnabled, DockerPipe{}.Publish(ctx))
+}
+
+// fakeCosign puts a fake cosign in the PATH, which logs its arguments to
+// the given file, and fails when FAIL is set.
+func fakeCosign(t *testing.T, folder, log string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"cosign": "echo \"$COSIGN_EXPERIMENTAL $*\" >> " + log + "\n[ -z \"$FAIL\" ] || { echo failed; exit 1; }\n",
+	})
+}
+
+func TestDockerSign(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "cosign.log")
+	defer fakeCosign(t, folder, log)()
+
+	ctx := context.New(config.Project{
+		DockerSigns: []config.Sign{
+			{},
+			{
+				Args: []string{"sign", "--yes", "--key=${key}", "-a", "tag={{ .Tag }}", "{{ .Image }}@{{ .Digest }}"},
+				Key:  "{{ .Env.COSIGN_KEY }}",
+			},
+			{Artifacts: "none"},
+		},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Env = map[string]string{"COSIGN_KEY": "secret"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:  "goreleaser/test:v1.0.0",
+		Type:  artifact.DockerImage,
+		Extra: map[string]interface{}{"Digest": digest},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "goreleaser/test:v1.0.0",
+		Type: artifact.PublishableDockerImage,
+	})
+	assert.NoError(t, DockerPipe{}.Default(ctx))
+	assert.NoError(t, DockerPipe{}.Publish(ctx))
+
+	bts, err := ioutil.ReadFile(log)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^1 sign --yes goreleaser/test:v1.0.0@"+digest+"\n"+
+		" sign --yes --key=[^ ]+ -a tag=v1.0.0 goreleaser/test:v1.0.0@"+digest+"\n$", string(bts))
+}
+
+func TestDockerSignFailed(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeCosign(t, folder, filepath.Join(folder, "cosign.log"))()
+
+	ctx := context.New(config.Project{
+		DockerSigns: []config.Sign{{Env: []string{"FAIL=1"}}},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:  "goreleaser/test:v1.0.0",
+		Type:  artifact.DockerImage,
+		Extra: map[string]interface{}{"Digest": digest},
+	})
+	assert.NoError(t, DockerPipe{}.Default(ctx))
+	assert.EqualError(t, DockerPipe{}.Publish(ctx), `sign: cosign failed to sign goreleaser/test:v1.0.0 with "failed\n"`)
+}
+
+func TestDockerSignNoDigest(t *testing.T) {
+	ctx := context.New(config.Project{
+		DockerSigns: []config.Sign{{}},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "goreleaser/test:v1.0.0",
+		Type: artifact.DockerImage,
+	})
+	assert.NoError(t, DockerPipe{}.Default(ctx))
+	assert.EqualError(t, DockerPipe{}.Publish(ctx), "sign: digest of goreleaser/test:v1.0.0 is unknown")
+}
@@ -2,18 +2,24 @@ package sign
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"strings"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
 	"github.com/goreleaser/goreleaser/internal/deprecate"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
 )
 
 // Pipe for artifact signing.
@@ -28,7 +34,7 @@ func (Pipe) Default(ctx *context.Context) error {
 	if len(ctx.Config.Signs) == 0 {
 		ctx.Config.Signs = append(ctx.Config.Signs, ctx.Config.Sign)
 		if !reflect.DeepEqual(ctx.Config.Sign, config.Sign{}) {
-			deprecate.Notice("sign")
+			deprecate.Notice(ctx, "sign")
 		}
 	}
 	for i := range ctx.Config.Signs {
@@ -38,6 +44,24 @@ func (Pipe) Default(ctx *context.Context) error {
 		}
 		if cfg.Signature == "" {
 			cfg.Signature = "${artifact}.sig"
+			if cfg.Cmd == "minisign" {
+				cfg.Signature = "${artifact}.minisig"
+			}
+		}
+		if cfg.Cmd == "cosign" && len(cfg.Args) == 0 {
+			// keyless signing, with a certificate issued for the OIDC
+			// identity of whoever runs the release
+			if cfg.Certificate == "" {
+				cfg.Certificate = "${artifact}.pem"
+			}
+			cfg.Args = []string{"sign-blob", "--yes", "--output-signature=${signature}", "--output-certificate=${certificate}", "${artifact}"}
+			cfg.Env = append(cfg.Env, "COSIGN_EXPERIMENTAL=1")
+		}
+		if (cfg.Cmd == "minisign" || cfg.Cmd == "signify") && len(cfg.Args) == 0 {
+			if cfg.Key == "" && cfg.KeyFile == "" {
+				return fmt.Errorf("sign: %s needs a key or a key_file", cfg.Cmd)
+			}
+			cfg.Args = []string{"-S", "-s", "${key}", "-m", "${artifact}", "-x", "${signature}"}
 		}
 		if len(cfg.Args) == 0 {
 			cfg.Args = []string{"--output", "$signature", "--detach-sig", "$artifact"}
@@ -58,30 +82,61 @@ func (Pipe) Run(ctx *context.Context) error {
 	var g = semerrgroup.New(ctx.Parallelism)
 	for i := range ctx.Config.Signs {
 		cfg := ctx.Config.Signs[i]
+		ok, err := condition.Check(ctx, cfg.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("artifacts", cfg.Artifacts).Info("skipped because its condition is false")
+			continue
+		}
 		g.Go(func() error {
 			var filters []artifact.Filter
 			switch cfg.Artifacts {
 			case "checksum":
 				filters = append(filters, artifact.ByType(artifact.Checksum))
 				if len(cfg.IDs) > 0 {
-					log.Warn("when artifacts is `checksum`, `ids` has no effect. ignoring")
+					if err := pipe.Warn(ctx, "when artifacts is `checksum`, `ids` has no effect. ignoring"); err != nil {
+						return err
+					}
 				}
 			case "all":
-				filters = append(filters, artifact.Or(
-					artifact.ByType(artifact.UploadableArchive),
-					artifact.ByType(artifact.UploadableBinary),
-					artifact.ByType(artifact.Checksum),
-					artifact.ByType(artifact.LinuxPackage),
+				// signatures are not signed again, even the ones of the
+				// other signs running at the same time
+				filters = append(filters, artifact.And(
+					artifact.ByUploadable(),
+					artifact.Not(artifact.Or(
+						artifact.ByType(artifact.Signature),
+						artifact.ByType(artifact.Certificate),
+					)),
 				))
-				if len(cfg.IDs) > 0 {
-					filters = append(filters, artifact.ByIDs(cfg.IDs...))
-				}
+			case "archive":
+				filters = append(filters, artifact.ByType(artifact.UploadableArchive))
+			case "binary":
+				filters = append(filters, artifact.ByType(artifact.UploadableBinary))
+			case "package":
+				filters = append(filters, artifact.ByType(artifact.LinuxPackage))
+			case "source":
+				filters = append(filters, artifact.ByType(artifact.UploadableSourceArchive))
+			case "sbom":
+				filters = append(filters, artifact.ByType(artifact.SBOM))
+			case "provenance":
+				filters = append(filters, artifact.ByType(artifact.Provenance))
 			case "none":
 				return pipe.ErrSkipSignEnabled
 			default:
 				return fmt.Errorf("invalid list of artifacts to sign: %s", cfg.Artifacts)
 			}
-			return sign(ctx, cfg, ctx.Artifacts.Filter(artifact.And(filters...)).List())
+			if len(cfg.IDs) > 0 && cfg.Artifacts != "checksum" {
+				filters = append(filters, artifact.ByIDs(cfg.IDs...))
+			}
+			var artifacts = ctx.Artifacts.Filter(artifact.And(filters...)).List()
+			if len(artifacts) == 0 {
+				if err := pipe.Warn(ctx, fmt.Sprintf("no artifacts found to sign with artifacts: %s", cfg.Artifacts)); err != nil {
+					return err
+				}
+			}
+			return sign(ctx, cfg, artifacts)
 		})
 	}
 	return g.Wait()
@@ -89,19 +144,42 @@ func (Pipe) Run(ctx *context.Context) error {
 
 func sign(ctx *context.Context, cfg config.Sign, artifacts []*artifact.Artifact) error {
 	for _, a := range artifacts {
-		artifact, err := signone(ctx, cfg, a)
+		artifact, cert, err := signone(ctx, cfg, a)
 		if err != nil {
 			return err
 		}
 		ctx.Artifacts.Add(artifact)
+		if cert != nil {
+			ctx.Artifacts.Add(cert)
+		}
+		if cfg.TimestampURL == "" {
+			continue
+		}
+		ts, err := timestamp(ctx, cfg.TimestampURL, artifact)
+		if err != nil {
+			return err
+		}
+		ctx.Artifacts.Add(ts)
 	}
 	return nil
 }
 
-func signone(ctx *context.Context, cfg config.Sign, a *artifact.Artifact) (*artifact.Artifact, error) {
-	env := ctx.Env
+// signone signs the given artifact, returning its signature, and its
+// certificate if the sign config has one.
+func signone(ctx *context.Context, cfg config.Sign, a *artifact.Artifact) (*artifact.Artifact, *artifact.Artifact, error) {
+	env := map[string]string{}
+	for k, v := range ctx.Env {
+		env[k] = v
+	}
 	env["artifact"] = a.Path
 	env["signature"] = expand(cfg.Signature, env)
+	env["certificate"] = expand(cfg.Certificate, env)
+	key, cleanup, err := keyFile(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cleanup()
+	env["key"] = key
 
 	// nolint:prealloc
 	var args []string
@@ -114,23 +192,105 @@ func signone(ctx *context.Context, cfg config.Sign, a *artifact.Artifact) (*arti
 	// tells the scanner to ignore this.
 	// #nosec
 	cmd := exec.CommandContext(ctx, cfg.Cmd, args...)
+	if len(cfg.Env) > 0 {
+		cmd.Env = os.Environ()
+		for _, e := range cfg.Env {
+			s, err := tmpl.New(ctx).Apply(e)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "sign: failed to execute env template '%s'", e)
+			}
+			cmd.Env = append(cmd.Env, s)
+		}
+	}
+	in, err := stdin(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if in != nil {
+		defer in.Close() // nolint: errcheck
+		cmd.Stdin = in
+	}
 	log.WithField("cmd", cmd.Args).Debug("running")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return nil, fmt.Errorf("sign: %s failed with %q", cfg.Cmd, string(output))
+		return nil, nil, fmt.Errorf("sign: %s failed with %q", cfg.Cmd, string(output))
 	}
 
 	artifactPathBase, _ := filepath.Split(a.Path)
+	var path = env["signature"]
+	var certPath = env["certificate"]
 
 	env["artifact"] = a.Name
-	name := expand(cfg.Signature, env)
-
-	sigFilename := filepath.Base(env["signature"])
-	return &artifact.Artifact{
+	var sig = &artifact.Artifact{
 		Type: artifact.Signature,
-		Name: name,
-		Path: filepath.Join(artifactPathBase, sigFilename),
-	}, nil
+		Name: expand(cfg.Signature, env),
+		Path: filepath.Join(artifactPathBase, filepath.Base(path)),
+	}
+	var cert *artifact.Artifact
+	if cfg.Certificate != "" {
+		cert = &artifact.Artifact{
+			Type: artifact.Certificate,
+			Name: expand(cfg.Certificate, env),
+			Path: filepath.Join(artifactPathBase, filepath.Base(certPath)),
+		}
+	}
+	// signatures go wherever the signed artifact goes
+	if id, ok := a.Extra["ID"]; ok {
+		sig.Extra = map[string]interface{}{"ID": id}
+		if cert != nil {
+			cert.Extra = map[string]interface{}{"ID": id}
+		}
+	}
+	return sig, cert, nil
+}
+
+// keyFile returns the path of the key to sign with, writing it to a
+// temporary file when its contents are given, e.g. from the environment.
+func keyFile(ctx *context.Context, cfg config.Sign) (string, func(), error) {
+	if cfg.Key == "" {
+		return cfg.KeyFile, func() {}, nil
+	}
+	key, err := tmpl.New(ctx).Apply(cfg.Key)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "sign: failed to execute key template")
+	}
+	f, err := ioutil.TempFile("", "goreleaserkey")
+	if err != nil {
+		return "", nil, err
+	}
+	var cleanup = func() {
+		_ = os.Remove(f.Name())
+	}
+	if _, err := f.WriteString(key); err != nil {
+		_ = f.Close()
+		cleanup()
+		return "", nil, err
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return f.Name(), cleanup, nil
+}
+
+// stdin returns what should be piped to the sign command, e.g. the password
+// of the key, if any.
+func stdin(ctx *context.Context, cfg config.Sign) (io.ReadCloser, error) {
+	if cfg.Stdin != nil {
+		s, err := tmpl.New(ctx).Apply(*cfg.Stdin)
+		if err != nil {
+			return nil, errors.Wrap(err, "sign: failed to execute stdin template")
+		}
+		return ioutil.NopCloser(strings.NewReader(s)), nil
+	}
+	if cfg.StdinFile != "" {
+		f, err := os.Open(cfg.StdinFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "sign: failed to open stdin_file")
+		}
+		return f, nil
+	}
+	return nil, nil
 }
 
 func expand(s string, env map[string]string) string {
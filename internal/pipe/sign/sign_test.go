@@ -9,10 +9,12 @@ import (
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/stretchr/testify/assert"
@@ -47,6 +49,21 @@ func TestSignDefault(t *testing.T) {
 	assert.Equal(t, ctx.Config.Signs[0].Artifacts, "none")
 }
 
+func TestSignDefaultCosign(t *testing.T) {
+	ctx := context.New(config.Project{
+		Signs: []config.Sign{{Cmd: "cosign"}},
+	})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Equal(t, config.Sign{
+		Cmd:         "cosign",
+		Signature:   "${artifact}.sig",
+		Certificate: "${artifact}.pem",
+		Args:        []string{"sign-blob", "--yes", "--output-signature=${signature}", "--output-certificate=${certificate}", "${artifact}"},
+		Env:         []string{"COSIGN_EXPERIMENTAL=1"},
+		Artifacts:   "none",
+	}, ctx.Config.Signs[0])
+}
+
 func TestSignDisabled(t *testing.T) {
 	ctx := context.New(config.Project{})
 	ctx.Config.Signs = []config.Sign{
@@ -149,6 +166,35 @@ func TestSignArtifacts(t *testing.T) {
 			signaturePaths: []string{"checksum.sig", "checksum2.sig"},
 			signatureNames: []string{"checksum.sig", "checksum2.sig"},
 		},
+		{
+			desc: "sign only filtered binaries",
+			ctx: context.New(
+				config.Project{
+					Signs: []config.Sign{
+						{
+							Artifacts: "binary",
+							IDs:       []string{"foo"},
+						},
+					},
+				},
+			),
+			signaturePaths: []string{"artifact3.sig"},
+			signatureNames: []string{"artifact3_1.0.0_linux_amd64.sig"},
+		},
+		{
+			desc: "sign only archives",
+			ctx: context.New(
+				config.Project{
+					Signs: []config.Sign{
+						{
+							Artifacts: "archive",
+						},
+					},
+				},
+			),
+			signaturePaths: []string{"artifact1.sig", "artifact2.sig"},
+			signatureNames: []string{"artifact1.sig", "artifact2.sig"},
+		},
 		{
 			desc: "sign all artifacts with env",
 			ctx: context.New(
@@ -289,6 +335,15 @@ func testSign(t *testing.T, ctx *context.Context, signaturePaths []string, signa
 	}
 	// check signature is an artifact
 	assert.Equal(t, signArtifacts, signatureNames)
+
+	// check signatures keep the id of the signed artifact
+	for _, sig := range ctx.Artifacts.Filter(artifact.ByType(artifact.Signature)).List() {
+		if strings.HasPrefix(sig.Name, "checksum") {
+			assert.Nil(t, sig.Extra)
+			continue
+		}
+		assert.NotEmpty(t, sig.ExtraOr("ID", ""))
+	}
 }
 
 func verifySignature(t *testing.T, ctx *context.Context, sig string) {
@@ -307,3 +362,120 @@ func verifySignature(t *testing.T, ctx *context.Context, sig string) {
 		t.Fatalf("signature is not from %s", user)
 	}
 }
+
+func TestSignCertificateAndStdin(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "goreleaser")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	// fake cosign that writes its stdin and env to the signature and
+	// certificate
+	defer testlib.FakeTools(t, tmpdir, map[string]string{
+		"cosign": "cat > \"${3#*=}\"\necho $COSIGN_EXPERIMENTAL > \"${4#*=}\"\n",
+	})()
+
+	var file = filepath.Join(tmpdir, "checksums.txt")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("foo"), 0644))
+	var stdin = "{{ .Env.COSIGN_PWD }}"
+	ctx := context.New(config.Project{
+		Dist: tmpdir,
+		Signs: []config.Sign{
+			{Cmd: "cosign", Artifacts: "checksum", Stdin: &stdin},
+		},
+	})
+	ctx.Env = map[string]string{"COSIGN_PWD": "secret"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "checksums.txt",
+		Path: file,
+		Type: artifact.Checksum,
+	})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.NoError(t, Pipe{}.Run(ctx))
+
+	var sigs = ctx.Artifacts.Filter(artifact.ByType(artifact.Signature)).List()
+	assert.Len(t, sigs, 1)
+	assert.Equal(t, "checksums.txt.sig", sigs[0].Name)
+	bts, err := ioutil.ReadFile(sigs[0].Path)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", string(bts))
+
+	var certs = ctx.Artifacts.Filter(artifact.ByType(artifact.Certificate)).List()
+	assert.Len(t, certs, 1)
+	assert.Equal(t, "checksums.txt.pem", certs[0].Name)
+	bts, err = ioutil.ReadFile(certs[0].Path)
+	assert.NoError(t, err)
+	assert.Equal(t, "1\n", string(bts))
+}
+
+func TestSignStdinFileNotFound(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "goreleaser")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+	var file = filepath.Join(tmpdir, "checksums.txt")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("foo"), 0644))
+	ctx := context.New(config.Project{
+		Signs: []config.Sign{
+			{Artifacts: "checksum", StdinFile: filepath.Join(tmpdir, "nope")},
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "checksums.txt",
+		Path: file,
+		Type: artifact.Checksum,
+	})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	err = Pipe{}.Run(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "sign: failed to open stdin_file")
+}
+
+func TestSignDefaultMinisign(t *testing.T) {
+	ctx := context.New(config.Project{
+		Signs: []config.Sign{{Cmd: "minisign", KeyFile: "minisign.key"}},
+	})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Equal(t, "${artifact}.minisig", ctx.Config.Signs[0].Signature)
+	assert.Equal(t, []string{"-S", "-s", "${key}", "-m", "${artifact}", "-x", "${signature}"}, ctx.Config.Signs[0].Args)
+}
+
+func TestSignDefaultMinisignNoKey(t *testing.T) {
+	ctx := context.New(config.Project{
+		Signs: []config.Sign{{Cmd: "signify"}},
+	})
+	assert.EqualError(t, Pipe{}.Default(ctx), "sign: signify needs a key or a key_file")
+}
+
+func TestSignKeyFromEnv(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "goreleaser")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	// fake minisign that copies the key to the signature
+	defer testlib.FakeTools(t, tmpdir, map[string]string{
+		"minisign": "cp \"$3\" \"$7\"\n",
+	})()
+
+	var file = filepath.Join(tmpdir, "checksums.txt")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("foo"), 0644))
+	ctx := context.New(config.Project{
+		Dist: tmpdir,
+		Signs: []config.Sign{
+			{Cmd: "minisign", Artifacts: "checksum", Key: "{{ .Env.MINISIGN_KEY }}"},
+		},
+	})
+	ctx.Env = map[string]string{"MINISIGN_KEY": "untrusted comment: key\nRWQ...\n"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "checksums.txt",
+		Path: file,
+		Type: artifact.Checksum,
+	})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.NoError(t, Pipe{}.Run(ctx))
+
+	var sigs = ctx.Artifacts.Filter(artifact.ByType(artifact.Signature)).List()
+	assert.Len(t, sigs, 1)
+	assert.Equal(t, "checksums.txt.minisig", sigs[0].Name)
+	bts, err := ioutil.ReadFile(sigs[0].Path)
+	assert.NoError(t, err)
+	assert.Equal(t, "untrusted comment: key\nRWQ...\n", string(bts))
+}
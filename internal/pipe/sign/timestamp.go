@@ -0,0 +1,128 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// nolint: gochecknoglobals
+var oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+
+// tsaClient is the client of the TSAs, whose requests are also canceled
+// with the context
+// nolint: gochecknoglobals
+var tsaClient = &http.Client{Timeout: time.Minute}
+
+// RFC 3161 structures, only the parts we need.
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int `asn1:"optional"`
+	CertReq        bool     `asn1:"optional,default:false"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []string       `asn1:"optional"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+// timestamp asks the TSA at the given url for a RFC 3161 timestamp of the
+// signature, and writes its reply next to it, with the .tsr extension.
+func timestamp(ctx *context.Context, url string, sig *artifact.Artifact) (*artifact.Artifact, error) {
+	bts, err := ioutil.ReadFile(sig.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "timestamp: failed to read signature")
+	}
+	var sum = sha256.Sum256(bts)
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+	req, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{
+				Algorithm:  oidSHA256,
+				Parameters: asn1.NullRawValue,
+			},
+			HashedMessage: sum[:],
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithField("tsa", url).WithField("signature", sig.Name).Info("timestamping")
+	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	httpReq.Header.Set("Content-Type", "application/timestamp-query")
+	res, err := tsaClient.Do(httpReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "timestamp: request failed")
+	}
+	defer res.Body.Close() // nolint: errcheck
+	reply, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "timestamp: failed to read reply")
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("timestamp: %s replied with %s", url, res.Status)
+	}
+
+	var resp timeStampResp
+	if _, err := asn1.Unmarshal(reply, &resp); err != nil {
+		return nil, errors.Wrap(err, "timestamp: invalid reply")
+	}
+	// 0 is granted, and 1 is granted with modifications
+	if resp.Status.Status > 1 {
+		return nil, fmt.Errorf(
+			"timestamp: %s rejected the request with status %d: %s",
+			url,
+			resp.Status.Status,
+			strings.Join(resp.Status.StatusString, ", "),
+		)
+	}
+	if len(resp.TimeStampToken.FullBytes) == 0 {
+		return nil, fmt.Errorf("timestamp: %s replied without a timestamp token", url)
+	}
+
+	var path = sig.Path + ".tsr"
+	if err := ioutil.WriteFile(path, reply, 0644); err != nil {
+		return nil, err
+	}
+	return &artifact.Artifact{
+		Type: artifact.Signature,
+		Name: sig.Name + ".tsr",
+		Path: path,
+	}, nil
+}
@@ -0,0 +1,134 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"encoding/asn1"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTSA(t *testing.T, resp timeStampResp) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/timestamp-query", r.Header.Get("Content-Type"))
+		bts, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var req timeStampReq
+		_, err = asn1.Unmarshal(bts, &req)
+		require.NoError(t, err)
+		var sum = sha256.Sum256([]byte("fake signature"))
+		assert.Equal(t, sum[:], req.MessageImprint.HashedMessage)
+		assert.True(t, req.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256))
+		assert.True(t, req.CertReq)
+		assert.NotNil(t, req.Nonce)
+		reply, err := asn1.Marshal(resp)
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		_, _ = w.Write(reply)
+	}))
+}
+
+func fakeSignature(t *testing.T) *artifact.Artifact {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	var path = filepath.Join(folder, "checksums.txt.sig")
+	require.NoError(t, ioutil.WriteFile(path, []byte("fake signature"), 0644))
+	return &artifact.Artifact{
+		Type: artifact.Signature,
+		Name: "checksums.txt.sig",
+		Path: path,
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	token, err := asn1.Marshal([]int{1, 2, 3})
+	require.NoError(t, err)
+	var srv = newTSA(t, timeStampResp{
+		Status:         pkiStatusInfo{Status: 0},
+		TimeStampToken: asn1.RawValue{FullBytes: token},
+	})
+	defer srv.Close()
+
+	var sig = fakeSignature(t)
+	ts, err := timestamp(context.New(config.Project{}), srv.URL, sig)
+	require.NoError(t, err)
+	require.Equal(t, &artifact.Artifact{
+		Type: artifact.Signature,
+		Name: "checksums.txt.sig.tsr",
+		Path: sig.Path + ".tsr",
+	}, ts)
+	bts, err := ioutil.ReadFile(ts.Path)
+	require.NoError(t, err)
+	var resp timeStampResp
+	_, err = asn1.Unmarshal(bts, &resp)
+	require.NoError(t, err)
+	require.Equal(t, token, resp.TimeStampToken.FullBytes)
+}
+
+func TestTimestampRejected(t *testing.T) {
+	var srv = newTSA(t, timeStampResp{
+		Status: pkiStatusInfo{
+			Status:       2,
+			StatusString: []string{"bad request"},
+		},
+	})
+	defer srv.Close()
+
+	_, err := timestamp(context.New(config.Project{}), srv.URL, fakeSignature(t))
+	require.EqualError(t, err, "timestamp: "+srv.URL+" rejected the request with status 2: bad request")
+}
+
+func TestTimestampWithoutToken(t *testing.T) {
+	var srv = newTSA(t, timeStampResp{
+		Status: pkiStatusInfo{Status: 0},
+	})
+	defer srv.Close()
+
+	_, err := timestamp(context.New(config.Project{}), srv.URL, fakeSignature(t))
+	require.EqualError(t, err, "timestamp: "+srv.URL+" replied without a timestamp token")
+}
+
+func TestTimestampServerError(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	_, err := timestamp(context.New(config.Project{}), srv.URL, fakeSignature(t))
+	require.EqualError(t, err, "timestamp: "+srv.URL+" replied with 500 Internal Server Error")
+}
+
+func TestTimestampTimeout(t *testing.T) {
+	var done = make(chan struct{})
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	var timeout = tsaClient.Timeout
+	tsaClient.Timeout = 10 * time.Millisecond
+	defer func() {
+		tsaClient.Timeout = timeout
+	}()
+	_, err := timestamp(context.New(config.Project{}), srv.URL, fakeSignature(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "timestamp: request failed")
+}
+
+func TestTimestampMissingSignature(t *testing.T) {
+	_, err := timestamp(context.New(config.Project{}), "http://localhost", &artifact.Artifact{
+		Path: "/nope/checksums.txt.sig",
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "timestamp: failed to read signature")
+}
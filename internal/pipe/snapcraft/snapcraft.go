@@ -12,6 +12,7 @@ import (
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/condition"
 	"github.com/goreleaser/goreleaser/internal/deprecate"
 	"github.com/goreleaser/goreleaser/internal/ids"
 	"github.com/goreleaser/goreleaser/internal/linux"
@@ -70,7 +71,7 @@ func (Pipe) Default(ctx *context.Context) error {
 	if len(ctx.Config.Snapcrafts) == 0 {
 		ctx.Config.Snapcrafts = append(ctx.Config.Snapcrafts, ctx.Config.Snapcraft)
 		if !reflect.DeepEqual(ctx.Config.Snapcraft, config.Snapcraft{}) {
-			deprecate.Notice("snapcraft")
+			deprecate.Notice(ctx, "snapcraft")
 		}
 	}
 	var ids = ids.New("snapcrafts")
@@ -84,6 +85,21 @@ func (Pipe) Default(ctx *context.Context) error {
 				snap.Builds = append(snap.Builds, b.ID)
 			}
 		}
+		if snap.Description == "" {
+			snap.Description = ctx.Config.Metadata.Description
+		}
+		if snap.License == "" {
+			snap.License = ctx.Config.Metadata.License
+		}
+		if len(snap.ChannelTemplates) == 0 {
+			switch snap.Grade {
+			case "devel":
+				// the store only accepts devel snaps on these channels
+				snap.ChannelTemplates = []string{"edge", "beta"}
+			default:
+				snap.ChannelTemplates = []string{"edge", "beta", "candidate", "stable"}
+			}
+		}
 		ids.Inc(snap.ID)
 	}
 	return ids.Validate()
@@ -92,6 +108,14 @@ func (Pipe) Default(ctx *context.Context) error {
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) error {
 	for _, snap := range ctx.Config.Snapcrafts {
+		ok, err := condition.Check(ctx, snap.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("snapcraft", snap.ID).Info("skipped because its condition is false")
+			continue
+		}
 		// TODO: deal with pipe.skip?
 		if err := doRun(ctx, snap); err != nil {
 			return err
@@ -115,14 +139,21 @@ func doRun(ctx *context.Context, snap config.Snapcraft) error {
 		return ErrNoSnapcraft
 	}
 
-	var g = semerrgroup.New(ctx.Parallelism)
-	for platform, binaries := range ctx.Artifacts.Filter(
+	var linuxBinaries = ctx.Artifacts.Filter(
 		artifact.And(
 			artifact.ByGoos("linux"),
 			artifact.ByType(artifact.Binary),
 			artifact.ByIDs(snap.Builds...),
 		),
-	).GroupByPlatform() {
+	).GroupByPlatform()
+	if len(linuxBinaries) == 0 {
+		if err := pipe.Warn(ctx, fmt.Sprintf("no linux binaries found for snapcraft %s", snap.ID)); err != nil {
+			return err
+		}
+	}
+
+	var g = semerrgroup.New(ctx.Parallelism)
+	for platform, binaries := range linuxBinaries {
 		arch := linux.Arch(platform)
 		if arch == "armel" {
 			log.WithField("arch", arch).Warn("ignored unsupported arch")
@@ -153,7 +184,7 @@ func create(ctx *context.Context, snap config.Snapcraft, arch string, binaries [
 	var log = log.WithField("arch", arch)
 	folder, err := tmpl.New(ctx).
 		WithArtifact(binaries[0], snap.Replacements).
-		Apply(snap.NameTemplate)
+		ApplyName(snap.NameTemplate)
 	if err != nil {
 		return err
 	}
@@ -266,6 +297,14 @@ func create(ctx *context.Context, snap config.Snapcraft, arch string, binaries [
 	if !snap.Publish {
 		return nil
 	}
+	var channels = make([]string, 0, len(snap.ChannelTemplates))
+	for _, channel := range snap.ChannelTemplates {
+		channel, err := tmpl.New(ctx).WithArtifact(binaries[0], snap.Replacements).Apply(channel)
+		if err != nil {
+			return errors.Wrap(err, "failed to execute channel template")
+		}
+		channels = append(channels, channel)
+	}
 	ctx.Artifacts.Add(&artifact.Artifact{
 		Type:   artifact.PublishableSnapcraft,
 		Name:   folder + ".snap",
@@ -273,6 +312,9 @@ func create(ctx *context.Context, snap config.Snapcraft, arch string, binaries [
 		Goos:   binaries[0].Goos,
 		Goarch: binaries[0].Goarch,
 		Goarm:  binaries[0].Goarm,
+		Extra: map[string]interface{}{
+			"Channels": channels,
+		},
 	})
 	return nil
 }
@@ -281,10 +323,10 @@ const reviewWaitMsg = `Waiting for previous upload(s) to complete their review p
 
 func push(ctx *context.Context, snap *artifact.Artifact) error {
 	var log = log.WithField("snap", snap.Name)
-	log.Info("pushing snap")
-	// TODO: customize --release based on snap.Grade?
+	var channels = snap.ExtraOr("Channels", []string{"stable"}).([]string)
+	log.WithField("channels", channels).Info("pushing snap")
 	/* #nosec */
-	var cmd = exec.CommandContext(ctx, "snapcraft", "push", "--release=stable", snap.Path)
+	var cmd = exec.CommandContext(ctx, "snapcraft", "push", "--release="+strings.Join(channels, ","), snap.Path)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		if strings.Contains(string(out), reviewWaitMsg) {
 			log.Warn(reviewWaitMsg)
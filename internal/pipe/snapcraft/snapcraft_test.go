@@ -5,10 +5,12 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/testlib"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/stretchr/testify/assert"
@@ -330,6 +332,94 @@ func TestDefault(t *testing.T) {
 	assert.NoError(t, Pipe{}.Default(ctx))
 	assert.Equal(t, defaultNameTemplate, ctx.Config.Snapcrafts[0].NameTemplate)
 	assert.Equal(t, []string{"foo"}, ctx.Config.Snapcrafts[0].Builds)
+	assert.Equal(t, []string{"edge", "beta", "candidate", "stable"}, ctx.Config.Snapcrafts[0].ChannelTemplates)
+}
+
+func TestDefaultGradeDevel(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Snapcrafts: []config.Snapcraft{{Grade: "devel"}},
+	})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Equal(t, []string{"edge", "beta"}, ctx.Config.Snapcrafts[0].ChannelTemplates)
+}
+
+// fakeSnapcraft puts a fake snapcraft in the PATH, which creates the packed
+// snap and logs its pushes, returning a func that restores the PATH.
+func fakeSnapcraft(t *testing.T, folder, log string) func() {
+	return testlib.FakeTools(t, folder, map[string]string{
+		"snapcraft": `case "$1" in
+pack) touch "$4" ;;
+push) echo "$@" >> ` + log + ` ;;
+esac
+`,
+	})
+}
+
+func TestRunPipeChannels(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var log = filepath.Join(folder, "snapcraft.log")
+	defer fakeSnapcraft(t, folder, log)()
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0755))
+	var ctx = context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		Snapcrafts: []config.Snapcraft{
+			{
+				NameTemplate:     "foo_{{.Arch}}",
+				Summary:          "test summary",
+				Description:      "test description",
+				Publish:          true,
+				Builds:           []string{"foo"},
+				ChannelTemplates: []string{"edge", "{{ .Major }}.{{ .Minor }}/stable"},
+			},
+		},
+	})
+	ctx.Git.CurrentTag = "v1.2.3"
+	ctx.Version = "1.2.3"
+	ctx.Semver = context.Semver{Major: 1, Minor: 2, Patch: 3}
+	addBinaries(t, ctx, "foo", dist, "mybin")
+	require.NoError(t, Pipe{}.Run(ctx))
+	var snaps = ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableSnapcraft)).List()
+	require.Len(t, snaps, 3)
+	for _, snap := range snaps {
+		require.Equal(t, []string{"edge", "1.2/stable"}, snap.ExtraOr("Channels", nil))
+	}
+
+	require.NoError(t, Pipe{}.Publish(ctx))
+	bts, err := ioutil.ReadFile(log)
+	require.NoError(t, err)
+	require.Len(t, strings.Split(strings.TrimSpace(string(bts)), "\n"), 3)
+	require.Contains(t, string(bts), "push --release=edge,1.2/stable "+filepath.Join(dist, "foo_amd64.snap"))
+}
+
+func TestRunPipeInvalidChannelTemplate(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	defer fakeSnapcraft(t, folder, filepath.Join(folder, "snapcraft.log"))()
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0755))
+	var ctx = context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		Snapcrafts: []config.Snapcraft{
+			{
+				NameTemplate:     "foo_{{.Arch}}",
+				Summary:          "test summary",
+				Description:      "test description",
+				Publish:          true,
+				Builds:           []string{"foo"},
+				ChannelTemplates: []string{"{{ .Nope }"},
+			},
+		},
+	})
+	ctx.Git.CurrentTag = "v1.2.3"
+	ctx.Version = "1.2.3"
+	addBinaries(t, ctx, "foo", dist, "mybin")
+	var err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to execute channel template")
 }
 
 func TestPublish(t *testing.T) {
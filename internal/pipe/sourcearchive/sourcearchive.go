@@ -0,0 +1,131 @@
+// Package sourcearchive provides a Pipe that archives the source code of the
+// project, so it can be released along with the other artifacts.
+package sourcearchive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	archivelib "github.com/goreleaser/goreleaser/pkg/archive"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	zglob "github.com/mattn/go-zglob"
+	"github.com/pkg/errors"
+)
+
+// Pipe for source archives
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "creating source archive"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	var source = &ctx.Config.Source
+	if source.NameTemplate == "" {
+		source.NameTemplate = "{{ .ProjectName }}-{{ .Version }}"
+	}
+	if source.Format == "" {
+		source.Format = "tar.gz"
+	}
+	switch source.Format {
+	case "tar.gz", "tar.xz", "tar.zst", "zip":
+		return nil
+	}
+	return fmt.Errorf("invalid source archive format: %s", source.Format)
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	var source = ctx.Config.Source
+	if !source.Enabled {
+		return pipe.Skip("source pipe is disabled")
+	}
+	var template = tmpl.New(ctx)
+	name, err := template.ApplyName(source.NameTemplate)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply source archive name template")
+	}
+	prefix, err := template.Apply(source.PrefixTemplate)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply source archive prefix template")
+	}
+	files, err := sourceFiles(source)
+	if err != nil {
+		return err
+	}
+
+	var filename = name + "." + source.Format
+	var path = filepath.Join(ctx.Config.Dist, filename)
+	log.WithField("file", path).Info("creating source archive")
+	file, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", path)
+	}
+	defer file.Close() // nolint: errcheck
+	var archive = archivelib.New(file)
+	if source.Reproducible {
+		archive, err = archivelib.NewReproducible(file, 0, ctx.Git.CommitDate)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create %s", path)
+		}
+	}
+	for _, f := range files {
+		if err := archive.Add(filepath.ToSlash(filepath.Join(prefix, f)), f); err != nil {
+			_ = archive.Close()
+			return errors.Wrapf(err, "failed to add %s to the source archive", f)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close %s", path)
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableSourceArchive,
+		Name: filename,
+		Path: path,
+		Extra: map[string]interface{}{
+			"Format": source.Format,
+		},
+	})
+	return nil
+}
+
+// sourceFiles returns the files tracked by git plus the extra files matching
+// the configured globs, sorted and without duplicates.
+func sourceFiles(source config.Source) ([]string, error) {
+	out, err := git.Run("ls-files", "-z")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the files tracked by git")
+	}
+	var unique = map[string]bool{}
+	for _, f := range strings.Split(out, "\x00") {
+		if f != "" {
+			unique[f] = true
+		}
+	}
+	for _, glob := range source.Files {
+		matches, err := zglob.Glob(glob)
+		if err != nil {
+			return nil, errors.Wrapf(err, "globbing failed for pattern %s", glob)
+		}
+		for _, f := range matches {
+			unique[f] = true
+		}
+	}
+	var files = make([]string, 0, len(unique))
+	for f := range unique {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files, nil
+}
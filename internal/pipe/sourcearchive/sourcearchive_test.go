@@ -0,0 +1,102 @@
+package sourcearchive
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Source{
+		NameTemplate: "{{ .ProjectName }}-{{ .Version }}",
+		Format:       "tar.gz",
+	}, ctx.Config.Source)
+}
+
+func TestDefaultInvalidFormat(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Source: config.Source{Format: "7z"},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "invalid source archive format: 7z")
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+func TestRun(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	write(t, folder, "main.go")
+	write(t, folder, "code/foo.go")
+	testlib.GitAdd(t)
+	testlib.GitCommit(t, "first")
+	write(t, folder, "untracked.txt")
+	write(t, folder, "docs/generated.md")
+	require.NoError(t, os.Mkdir(filepath.Join(folder, "dist"), 0755))
+
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        "dist",
+		Source: config.Source{
+			Enabled:        true,
+			Format:         "zip",
+			PrefixTemplate: "{{ .ProjectName }}-{{ .Version }}/",
+			Files:          []string{"docs/*"},
+		},
+	})
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var artifacts = ctx.Artifacts.List()
+	require.Len(t, artifacts, 1)
+	require.Equal(t, &artifact.Artifact{
+		Type: artifact.UploadableSourceArchive,
+		Name: "foo-1.0.0.zip",
+		Path: "dist/foo-1.0.0.zip",
+		Extra: map[string]interface{}{
+			"Format": "zip",
+		},
+	}, artifacts[0])
+
+	r, err := zip.OpenReader(artifacts[0].Path)
+	require.NoError(t, err)
+	defer r.Close() // nolint: errcheck
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{
+		"foo-1.0.0/code/foo.go",
+		"foo-1.0.0/docs/generated.md",
+		"foo-1.0.0/main.go",
+	}, names)
+}
+
+func TestRunInvalidNameTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Source: config.Source{
+			Enabled:      true,
+			NameTemplate: "{{ .Nope }}",
+		},
+	})
+	require.Error(t, Pipe{}.Run(ctx))
+}
+
+func write(t *testing.T, folder, name string) {
+	var path = filepath.Join(folder, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(name), 0644))
+}
@@ -0,0 +1,179 @@
+// Package universalbinary provides a Pipe that merges darwin binaries of
+// the same build into a single macOS universal binary.
+package universalbinary
+
+import (
+	"bytes"
+	"debug/macho"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// Pipe for macos universal binaries
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "universal binaries"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	var ids = ids.New("universal_binaries")
+	for i := range ctx.Config.UniversalBinaries {
+		var unibin = &ctx.Config.UniversalBinaries[i]
+		if unibin.ID == "" {
+			unibin.ID = ctx.Config.ProjectName
+		}
+		if unibin.NameTemplate == "" {
+			unibin.NameTemplate = "{{ .Binary }}"
+		}
+		ids.Inc(unibin.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.UniversalBinaries) == 0 {
+		return pipe.Skip("universal_binaries section is not configured")
+	}
+	var g = semerrgroup.New(ctx.Parallelism)
+	for _, unibin := range ctx.Config.UniversalBinaries {
+		unibin := unibin
+		g.Go(func() error {
+			return makeUniversalBinary(ctx, unibin)
+		})
+	}
+	return g.Wait()
+}
+
+func makeUniversalBinary(ctx *context.Context, unibin config.UniversalBinary) error {
+	var filter = artifact.And(
+		artifact.ByType(artifact.Binary),
+		artifact.ByGoos("darwin"),
+		artifact.Or(
+			artifact.ByGoarch("amd64"),
+			artifact.ByGoarch("arm64"),
+		),
+		artifact.ByIDs(unibin.ID),
+	)
+	var binaries = ctx.Artifacts.Filter(filter).List()
+	if len(binaries) == 0 {
+		return fmt.Errorf("no darwin binaries found with id %q", unibin.ID)
+	}
+	sort.Slice(binaries, func(i, j int) bool {
+		return binaries[i].Goarch < binaries[j].Goarch
+	})
+
+	name, err := tmpl.New(ctx).
+		WithArtifact(binaries[0], map[string]string{}).
+		ApplyName(unibin.NameTemplate)
+	if err != nil {
+		return err
+	}
+	var folder = filepath.Join(ctx.Config.Dist, unibin.ID+"_darwin_all")
+	var path = filepath.Join(folder, name)
+	log.WithField("binary", path).Info("creating universal binary")
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return err
+	}
+	if err := writeFat(path, binaries); err != nil {
+		return errors.Wrapf(err, "failed to create universal binary for %s", unibin.ID)
+	}
+
+	if unibin.Replace {
+		ctx.Artifacts.Remove(filter)
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   artifact.Binary,
+		Name:   name,
+		Path:   path,
+		Goos:   "darwin",
+		Goarch: "all",
+		Extra: map[string]interface{}{
+			"Binary":    name,
+			"Ext":       "",
+			"ID":        unibin.ID,
+			"Universal": true,
+		},
+	})
+	return nil
+}
+
+const (
+	fatMagic       = 0xcafebabe
+	fatHeaderSize  = 8
+	fatArchSize    = 20
+	alignBits      = 12
+	arm64AlignBits = 14
+)
+
+type fatArch struct {
+	cpu, subCpu, offset, size, align uint32
+	data                             []byte
+}
+
+// writeFat writes a fat mach-o file made of the given binaries, like
+// `lipo -create` does.
+func writeFat(path string, binaries []*artifact.Artifact) error {
+	// nolint: prealloc
+	var archs []fatArch
+	var offset = uint32(fatHeaderSize + fatArchSize*len(binaries))
+	for _, bin := range binaries {
+		data, err := ioutil.ReadFile(bin.Path)
+		if err != nil {
+			return err
+		}
+		f, err := macho.NewFile(bytes.NewReader(data))
+		if err != nil {
+			return errors.Wrapf(err, "%s is not a mach-o file", bin.Path)
+		}
+		var align = uint32(alignBits)
+		if f.Cpu == macho.CpuArm64 {
+			align = arm64AlignBits
+		}
+		offset = alignTo(offset, align)
+		archs = append(archs, fatArch{
+			cpu:    uint32(f.Cpu),
+			subCpu: f.SubCpu,
+			offset: offset,
+			size:   uint32(len(data)),
+			align:  align,
+			data:   data,
+		})
+		offset += uint32(len(data))
+	}
+
+	var buf bytes.Buffer
+	var header = []uint32{fatMagic, uint32(len(archs))}
+	for _, arch := range archs {
+		header = append(header, arch.cpu, arch.subCpu, arch.offset, arch.size, arch.align)
+	}
+	if err := binary.Write(&buf, binary.BigEndian, header); err != nil {
+		return err
+	}
+	for _, arch := range archs {
+		buf.Write(make([]byte, int(arch.offset)-buf.Len()))
+		buf.Write(arch.data)
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0755)
+}
+
+func alignTo(offset, bits uint32) uint32 {
+	var align = uint32(1) << bits
+	return (offset + align - 1) &^ (align - 1)
+}
@@ -0,0 +1,166 @@
+package universalbinary
+
+import (
+	"bytes"
+	"debug/macho"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName:       "proj",
+		UniversalBinaries: []config.UniversalBinary{{}},
+	})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Equal(t, config.UniversalBinary{
+		ID:           "proj",
+		NameTemplate: "{{ .Binary }}",
+	}, ctx.Config.UniversalBinaries[0])
+}
+
+func TestDefaultDuplicatedIDs(t *testing.T) {
+	var ctx = context.New(config.Project{
+		UniversalBinaries: []config.UniversalBinary{
+			{ID: "foo"},
+			{ID: "foo"},
+		},
+	})
+	assert.EqualError(t, Pipe{}.Default(ctx), "found 2 universal_binaries with the ID 'foo', please fix your config")
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+func TestRun(t *testing.T) {
+	for name, replace := range map[string]bool{
+		"supplement": false,
+		"replace":    true,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var folder, back = testlib.Mktmp(t)
+			defer back()
+			var ctx = newContext(t, folder, replace)
+			require.NoError(t, Pipe{}.Run(ctx))
+
+			var binaries = ctx.Artifacts.Filter(artifact.ByType(artifact.Binary)).List()
+			if replace {
+				require.Len(t, binaries, 2)
+			} else {
+				require.Len(t, binaries, 4)
+			}
+			var unibin = binaries[len(binaries)-1]
+			assert.Equal(t, "darwin", unibin.Goos)
+			assert.Equal(t, "all", unibin.Goarch)
+			assert.Equal(t, "foo", unibin.ExtraOr("ID", ""))
+			assert.Equal(t, filepath.Join(folder, "dist", "foo_darwin_all", "bin"), unibin.Path)
+
+			f, err := macho.OpenFat(unibin.Path)
+			require.NoError(t, err)
+			defer f.Close() // nolint: errcheck
+			require.Len(t, f.Arches, 2)
+			assert.Equal(t, macho.CpuAmd64, f.Arches[0].Cpu)
+			assert.Equal(t, uint32(1<<alignBits), f.Arches[0].Offset)
+			assert.Equal(t, macho.CpuArm64, f.Arches[1].Cpu)
+			assert.Equal(t, uint32(0), f.Arches[1].Offset%(1<<arm64AlignBits))
+		})
+	}
+}
+
+func TestRunNoBinaries(t *testing.T) {
+	var ctx = context.New(config.Project{
+		UniversalBinaries: []config.UniversalBinary{{ID: "nope"}},
+	})
+	assert.EqualError(t, Pipe{}.Run(ctx), `no darwin binaries found with id "nope"`)
+}
+
+func TestRunNotMachO(t *testing.T) {
+	var folder, back = testlib.Mktmp(t)
+	defer back()
+	var bin = filepath.Join(folder, "bin")
+	require.NoError(t, ioutil.WriteFile(bin, []byte("not a binary"), 0755))
+	var ctx = context.New(config.Project{
+		Dist: folder,
+		UniversalBinaries: []config.UniversalBinary{
+			{ID: "foo", NameTemplate: "bin"},
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "bin",
+		Path:   bin,
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra:  map[string]interface{}{"ID": "foo"},
+	})
+	var err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a mach-o file")
+}
+
+func newContext(t *testing.T, folder string, replace bool) *context.Context {
+	var ctx = context.New(config.Project{
+		Dist: filepath.Join(folder, "dist"),
+		UniversalBinaries: []config.UniversalBinary{
+			{
+				ID:           "foo",
+				NameTemplate: "{{ .Binary }}",
+				Replace:      replace,
+			},
+		},
+	})
+	for arch, cpu := range map[string]macho.Cpu{
+		"amd64": macho.CpuAmd64,
+		"arm64": macho.CpuArm64,
+	} {
+		var path = filepath.Join(folder, "bin_"+arch)
+		require.NoError(t, ioutil.WriteFile(path, fakeMachO(t, cpu), 0755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "bin",
+			Path:   path,
+			Goos:   "darwin",
+			Goarch: arch,
+			Type:   artifact.Binary,
+			Extra: map[string]interface{}{
+				"ID":     "foo",
+				"Binary": "bin",
+			},
+		})
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "bin",
+		Path:   filepath.Join(folder, "bin_linux"),
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"ID":     "foo",
+			"Binary": "bin",
+		},
+	})
+	return ctx
+}
+
+// fakeMachO returns a minimal 64-bit mach-o executable header with no load
+// commands for the given cpu.
+func fakeMachO(t *testing.T, cpu macho.Cpu) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, macho.FileHeader{
+		Magic: macho.Magic64,
+		Cpu:   cpu,
+		Type:  macho.TypeExec,
+	}))
+	// 64-bit headers have an extra reserved field
+	buf.Write(make([]byte, 4))
+	return buf.Bytes()
+}
@@ -0,0 +1,121 @@
+package upload
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func uploadContext(t *testing.T, folder string, upload config.Put) *context.Context {
+	var tarfile = filepath.Join(folder, "bin.tar.gz")
+	require.NoError(t, ioutil.WriteFile(tarfile, []byte("fake tar"), 0644))
+	var ctx = context.New(config.Project{
+		ProjectName: "goreleaser",
+		Dist:        folder,
+		Uploads:     []config.Put{upload},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Env = map[string]string{"TOKEN": "secret-token"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "bin.tar.gz",
+		Path: tarfile,
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestRunPipeTokenPost(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var requests sync.Map
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Store(r.URL.Path, r)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	var ctx = uploadContext(t, folder, config.Put{
+		Name:           "production",
+		Method:         http.MethodPost,
+		Target:         srv.URL + "/releases/{{ .ProjectName }}/{{ .Version }}/",
+		ChecksumHeader: "X-SHA256",
+		CustomHeaders: map[string]string{
+			"Authorization": "Bearer {{ .Env.TOKEN }}",
+			"X-Artifact":    "{{ .ArtifactName }}",
+		},
+	})
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	r, ok := requests.Load("/releases/goreleaser/1.0.0/bin.tar.gz")
+	require.True(t, ok)
+	var req = r.(*http.Request)
+	require.Equal(t, http.MethodPost, req.Method)
+	require.Equal(t, "Bearer secret-token", req.Header.Get("Authorization"))
+	require.Equal(t, "bin.tar.gz", req.Header.Get("X-Artifact"))
+	require.Len(t, req.Header.Get("X-SHA256"), 64)
+}
+
+func TestRunPipeBadStatus(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	var ctx = uploadContext(t, folder, config.Put{
+		Name:   "production",
+		Target: srv.URL,
+	})
+	ctx.Env["UPLOAD_PRODUCTION_SECRET"] = "secret"
+	require.Contains(t, Pipe{}.Publish(ctx).Error(), "unexpected http response status: 403 Forbidden")
+}
+
+func TestRunPipeBadMethod(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = uploadContext(t, folder, config.Put{
+		Name:   "production",
+		Target: "http://example.com",
+		Method: http.MethodGet,
+	})
+	ctx.Env["UPLOAD_PRODUCTION_SECRET"] = "secret"
+	var err = Pipe{}.Publish(ctx)
+	require.True(t, pipe.IsSkip(err))
+	require.Contains(t, err.Error(), "method must be 'PUT' or 'POST'")
+}
+
+func TestRunPipeMissingSecret(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = uploadContext(t, folder, config.Put{
+		Name:   "production",
+		Target: "http://example.com",
+	})
+	require.Contains(t, Pipe{}.Publish(ctx).Error(), "missing UPLOAD_PRODUCTION_SECRET environment variable")
+}
+
+func TestRunPipeNoUploads(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	require.True(t, pipe.IsSkip(Pipe{}.Publish(ctx)))
+}
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Uploads: []config.Put{{Name: "production"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "archive", ctx.Config.Uploads[0].Mode)
+	require.Equal(t, http.MethodPut, ctx.Config.Uploads[0].Method)
+}
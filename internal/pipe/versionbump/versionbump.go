@@ -0,0 +1,154 @@
+// Package versionbump provides a Pipe that opens a pull request updating the
+// version references in the repository files, like README install snippets,
+// after a release.
+package versionbump
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoPullRequests happens when the client can't open pull requests
+var ErrNoPullRequests = errors.New("version_bump is only supported on GitHub")
+
+// Pipe for version bumps
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "version bump pull request"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	var bump = &ctx.Config.VersionBump
+	if bump.Repo.Name == "" {
+		bump.Repo = ctx.Config.Release.GitHub
+	}
+	if bump.Branch == "" {
+		bump.Branch = "goreleaser/bump-{{ .Tag }}"
+	}
+	if bump.Title == "" {
+		bump.Title = "Bump version to {{ .Tag }}"
+	}
+	if bump.CommitAuthor.Name == "" {
+		bump.CommitAuthor.Name = "goreleaserbot"
+	}
+	if bump.CommitAuthor.Email == "" {
+		bump.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+	}
+	return nil
+}
+
+// Publish opens the pull request
+func (Pipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.VersionBump.Files) == 0 {
+		return pipe.Skip("version_bump section is not configured")
+	}
+	previous, err := git.Clean(git.Run("describe", "--tags", "--abbrev=0", fmt.Sprintf("tags/%s^", ctx.Git.CurrentTag)))
+	if err != nil {
+		return pipe.Skip("no previous tag to bump from")
+	}
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return doPublish(ctx, cli, previous)
+}
+
+func doPublish(ctx *context.Context, cli client.Client, previous string) error {
+	var bump = ctx.Config.VersionBump
+	pr, ok := cli.(client.PullRequester)
+	if !ok {
+		return ErrNoPullRequests
+	}
+	branch, err := tmpl.New(ctx).Apply(bump.Branch)
+	if err != nil {
+		return errors.Wrap(err, "failed to template the version bump branch")
+	}
+	title, err := tmpl.New(ctx).Apply(bump.Title)
+	if err != nil {
+		return errors.Wrap(err, "failed to template the version bump title")
+	}
+
+	var files = map[string][]byte{}
+	for _, path := range bump.Files {
+		content, err := pr.GetFile(ctx, bump.Repo, path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get %s from %s", path, bump.Repo)
+		}
+		var bumped = bumpVersion(string(content), previous, ctx.Git.CurrentTag)
+		if bumped == string(content) {
+			log.WithField("file", path).Debug("no version references to bump")
+			continue
+		}
+		files[path] = []byte(bumped)
+	}
+	if len(files) == 0 {
+		return pipe.Skip("no version references to bump")
+	}
+
+	url, err := pr.OpenPullRequest(ctx, bump.CommitAuthor, bump.Repo, bump.Repo, files, branch, title, title)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open version bump pull request on %s", bump.Repo)
+	}
+	log.WithField("url", url).Info("opened pull request")
+	return nil
+}
+
+// bumpVersion replaces the references to the previous tag with the current
+// one. When tags are prefixed with a `v`, bare versions are replaced as well.
+func bumpVersion(content, previous, current string) string {
+	content = replaceVersion(content, previous, current)
+	if strings.HasPrefix(previous, "v") && strings.HasPrefix(current, "v") {
+		content = replaceVersion(content, previous[1:], current[1:])
+	}
+	return content
+}
+
+// replaceVersion replaces old with new, but only where old is not part of a
+// longer version, so 1.2.3 doesn't match 11.2.3 or 1.2.34.
+func replaceVersion(s, old, new string) string {
+	var b strings.Builder
+	var start = 0
+	for {
+		var i = strings.Index(s[start:], old)
+		if i < 0 {
+			b.WriteString(s[start:])
+			return b.String()
+		}
+		i += start
+		var j = i + len(old)
+		if (i == 0 || !isVersionChar(s[i-1])) && !continuesVersion(s[j:]) {
+			b.WriteString(s[start:i])
+			b.WriteString(new)
+		} else {
+			b.WriteString(s[start:j])
+		}
+		start = j
+	}
+}
+
+func isVersionChar(c byte) bool {
+	return c == '.' ||
+		('0' <= c && c <= '9') ||
+		('a' <= c && c <= 'z') ||
+		('A' <= c && c <= 'Z')
+}
+
+func continuesVersion(rest string) bool {
+	if rest == "" {
+		return false
+	}
+	if rest[0] == '.' {
+		return len(rest) > 1 && '0' <= rest[1] && rest[1] <= '9'
+	}
+	return isVersionChar(rest[0])
+}
@@ -0,0 +1,146 @@
+package versionbump
+
+import (
+	"os"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "goreleaser", Name: "goreleaser"},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.VersionBump{
+		Repo:   config.Repo{Owner: "goreleaser", Name: "goreleaser"},
+		Branch: "goreleaser/bump-{{ .Tag }}",
+		Title:  "Bump version to {{ .Tag }}",
+		CommitAuthor: config.CommitAuthor{
+			Name:  "goreleaserbot",
+			Email: "goreleaser@carlosbecker.com",
+		},
+	}, ctx.Config.VersionBump)
+}
+
+func TestSkipNotConfigured(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Publish(context.New(config.Project{})))
+}
+
+func TestBumpVersion(t *testing.T) {
+	for content, want := range map[string]string{
+		"go get foo@v1.2.3": "go get foo@v1.3.0",
+		"curl .../download/v1.2.3/foo_1.2.3_linux.tar.gz": "curl .../download/v1.3.0/foo_1.3.0_linux.tar.gz",
+		"version: 1.2.3\nversion: 1.2.3\n":                "version: 1.3.0\nversion: 1.3.0\n",
+		"1.2.3.":                                          "1.3.0.",
+		"v1.2.34 11.2.3 1.2.3.4 1.2.3a":                   "v1.2.34 11.2.3 1.2.3.4 1.2.3a",
+		"uses: goreleaser/action@v1.2.3":                  "uses: goreleaser/action@v1.3.0",
+	} {
+		require.Equal(t, want, bumpVersion(content, "v1.2.3", "v1.3.0"), content)
+	}
+}
+
+func TestBumpVersionNoPrefix(t *testing.T) {
+	require.Equal(t, "1.3.0 v1.2.3", bumpVersion("1.2.3 v1.2.3", "1.2.3", "1.3.0"))
+}
+
+func TestPublish(t *testing.T) {
+	var cli = &fakeClient{
+		files: map[string]string{
+			"README.md":  "go get github.com/foo/bar@v1.2.3",
+			"action.yml": "image: foo:1.2.3",
+			"LICENSE":    "MIT",
+		},
+	}
+	var ctx = newContext("README.md", "action.yml", "LICENSE")
+	require.NoError(t, doPublish(ctx, cli, "v1.2.3"))
+	require.Equal(t, "goreleaser/bump-v1.3.0", cli.branch)
+	require.Equal(t, "Bump version to v1.3.0", cli.title)
+	require.Equal(t, map[string][]byte{
+		"README.md":  []byte("go get github.com/foo/bar@v1.3.0"),
+		"action.yml": []byte("image: foo:1.3.0"),
+	}, cli.opened)
+}
+
+func TestPublishNothingToBump(t *testing.T) {
+	var cli = &fakeClient{
+		files: map[string]string{"README.md": "nothing here"},
+	}
+	testlib.AssertSkipped(t, doPublish(newContext("README.md"), cli, "v1.2.3"))
+	require.Nil(t, cli.opened)
+}
+
+func TestPublishMissingFile(t *testing.T) {
+	var cli = &fakeClient{}
+	require.EqualError(
+		t,
+		doPublish(newContext("README.md"), cli, "v1.2.3"),
+		"failed to get README.md from foo/bar: file does not exist",
+	)
+}
+
+func TestPublishInvalidBranch(t *testing.T) {
+	var ctx = newContext("README.md")
+	ctx.Config.VersionBump.Branch = "{{ .Nope }}"
+	require.Error(t, doPublish(ctx, &fakeClient{}, "v1.2.3"))
+}
+
+func TestPublishNoPullRequests(t *testing.T) {
+	require.EqualError(t, doPublish(newContext("README.md"), &basicClient{}, "v1.2.3"), ErrNoPullRequests.Error())
+}
+
+func newContext(files ...string) *context.Context {
+	var ctx = context.New(config.Project{
+		VersionBump: config.VersionBump{
+			Files: files,
+			Repo:  config.Repo{Owner: "foo", Name: "bar"},
+		},
+	})
+	ctx.Git.CurrentTag = "v1.3.0"
+	ctx.Version = "1.3.0"
+	_ = Pipe{}.Default(ctx)
+	return ctx
+}
+
+type basicClient struct{}
+
+func (*basicClient) CreateRelease(ctx *context.Context, body string) (string, error) {
+	return "", nil
+}
+
+func (*basicClient) CreateFile(ctx *context.Context, commitAuthor config.CommitAuthor, repo config.Repo, content []byte, path, message string) error {
+	return nil
+}
+
+func (*basicClient) Upload(ctx *context.Context, releaseID string, artifact *artifact.Artifact, file *os.File) error {
+	return nil
+}
+
+type fakeClient struct {
+	basicClient
+	files  map[string]string
+	opened map[string][]byte
+	branch string
+	title  string
+}
+
+func (c *fakeClient) GetFile(ctx *context.Context, repo config.Repo, path string) ([]byte, error) {
+	content, ok := c.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+func (c *fakeClient) OpenPullRequest(ctx *context.Context, commitAuthor config.CommitAuthor, repo, base config.Repo, files map[string][]byte, branch, title, message string) (string, error) {
+	c.opened = files
+	c.branch = branch
+	c.title = title
+	return "https://github.com/foo/bar/pull/1", nil
+}
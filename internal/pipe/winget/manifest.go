@@ -0,0 +1,89 @@
+package winget
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	manifestVersion = "1.5.0"
+	defaultLocale   = "en-US"
+)
+
+// Version is the version manifest, tying the other manifests together, more
+// info: https://github.com/microsoft/winget-pkgs/tree/master/doc/manifest
+type Version struct {
+	PackageIdentifier string `yaml:"PackageIdentifier"`
+	PackageVersion    string `yaml:"PackageVersion"`
+	DefaultLocale     string `yaml:"DefaultLocale"`
+	ManifestType      string `yaml:"ManifestType"`
+	ManifestVersion   string `yaml:"ManifestVersion"`
+}
+
+// Installer is the installer manifest, listing the installers per arch
+type Installer struct {
+	PackageIdentifier   string          `yaml:"PackageIdentifier"`
+	PackageVersion      string          `yaml:"PackageVersion"`
+	InstallerLocale     string          `yaml:"InstallerLocale"`
+	InstallerType       string          `yaml:"InstallerType"`
+	NestedInstallerType string          `yaml:"NestedInstallerType"`
+	Commands            []string        `yaml:"Commands,omitempty"`
+	ReleaseDate         string          `yaml:"ReleaseDate"`
+	Installers          []InstallerItem `yaml:"Installers"`
+	ManifestType        string          `yaml:"ManifestType"`
+	ManifestVersion     string          `yaml:"ManifestVersion"`
+}
+
+// InstallerItem is the installer of an arch
+type InstallerItem struct {
+	Architecture         string                `yaml:"Architecture"`
+	NestedInstallerFiles []NestedInstallerFile `yaml:"NestedInstallerFiles"`
+	InstallerURL         string                `yaml:"InstallerUrl"`
+	InstallerSha256      string                `yaml:"InstallerSha256"`
+	UpgradeBehavior      string                `yaml:"UpgradeBehavior"`
+}
+
+// NestedInstallerFile is an executable inside the zip installer, put in the
+// PATH under its alias
+type NestedInstallerFile struct {
+	RelativeFilePath     string `yaml:"RelativeFilePath"`
+	PortableCommandAlias string `yaml:"PortableCommandAlias"`
+}
+
+// Locale is the default locale manifest, describing the package
+type Locale struct {
+	PackageIdentifier string   `yaml:"PackageIdentifier"`
+	PackageVersion    string   `yaml:"PackageVersion"`
+	PackageLocale     string   `yaml:"PackageLocale"`
+	Publisher         string   `yaml:"Publisher"`
+	PublisherURL      string   `yaml:"PublisherUrl,omitempty"`
+	Author            string   `yaml:"Author,omitempty"`
+	PackageName       string   `yaml:"PackageName"`
+	PackageURL        string   `yaml:"PackageUrl,omitempty"`
+	License           string   `yaml:"License"`
+	LicenseURL        string   `yaml:"LicenseUrl,omitempty"`
+	Copyright         string   `yaml:"Copyright,omitempty"`
+	ShortDescription  string   `yaml:"ShortDescription"`
+	Description       string   `yaml:"Description,omitempty"`
+	Moniker           string   `yaml:"Moniker"`
+	Tags              []string `yaml:"Tags,omitempty"`
+	ReleaseNotes      string   `yaml:"ReleaseNotes,omitempty"`
+	ReleaseNotesURL   string   `yaml:"ReleaseNotesUrl,omitempty"`
+	ManifestType      string   `yaml:"ManifestType"`
+	ManifestVersion   string   `yaml:"ManifestVersion"`
+}
+
+// marshal returns the manifest as YAML, with the header pointing editors to
+// its schema
+func marshal(manifest interface{}, schema string) ([]byte, error) {
+	bts, err := yaml.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.WriteString("# This file was generated by GoReleaser. DO NOT EDIT.\n")
+	out.WriteString("# yaml-language-server: $schema=https://aka.ms/winget-manifest." + schema + "." + manifestVersion + ".schema.json\n")
+	out.Write(bts)
+	return out.Bytes(), nil
+}
@@ -0,0 +1,329 @@
+// Package winget provides a Pipe that generates the winget manifests of the
+// windows zip archives and proposes them to the winget-pkgs repository
+// through a pull request.
+package winget
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/condition"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// ErrNoWindowsArchives happens when there are no windows zip archives to
+// install
+var ErrNoWindowsArchives = errors.New("winget requires windows zip archives for amd64, 386 or arm64")
+
+// ErrNoPullRequests happens when the client can't open pull requests
+var ErrNoPullRequests = errors.New("winget is only supported on GitHub")
+
+// Pipe for winget manifests
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "winget manifests"
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Wingets {
+		var winget = &ctx.Config.Wingets[i]
+		if winget.Name == "" {
+			winget.Name = ctx.Config.ProjectName
+		}
+		if winget.Publisher == "" {
+			winget.Publisher = ctx.Config.Metadata.Vendor
+		}
+		if winget.Publisher == "" {
+			return fmt.Errorf("winget %s: publisher is required", winget.Name)
+		}
+		if winget.PackageIdentifier == "" {
+			winget.PackageIdentifier = strings.Replace(winget.Publisher, " ", "", -1) + "." +
+				strings.Replace(winget.Name, " ", "", -1)
+		}
+		if winget.ShortDescription == "" {
+			winget.ShortDescription = ctx.Config.Metadata.Description
+		}
+		if winget.Homepage == "" {
+			winget.Homepage = ctx.Config.Metadata.Homepage
+		}
+		if winget.License == "" {
+			winget.License = ctx.Config.Metadata.License
+		}
+		if winget.Base.Name == "" {
+			winget.Base = config.Repo{Owner: "microsoft", Name: "winget-pkgs"}
+		}
+		if winget.Path == "" {
+			winget.Path = path.Join(
+				"manifests",
+				string(unicode.ToLower(rune(winget.PackageIdentifier[0]))),
+				strings.Replace(winget.PackageIdentifier, ".", "/", -1),
+				"{{ .Version }}",
+			)
+		}
+		if winget.Branch == "" {
+			winget.Branch = winget.PackageIdentifier + "-{{ .Version }}"
+		}
+		if winget.CommitAuthor.Name == "" {
+			winget.CommitAuthor.Name = "goreleaserbot"
+		}
+		if winget.CommitAuthor.Email == "" {
+			winget.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+		}
+	}
+	return nil
+}
+
+// Publish the winget manifests
+func (Pipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.Wingets) == 0 {
+		return pipe.Skip("winget section is not configured")
+	}
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	for _, winget := range ctx.Config.Wingets {
+		ok, err := condition.Check(ctx, winget.If)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("winget", winget.PackageIdentifier).Info("skipped because its condition is false")
+			continue
+		}
+		if err := doRun(ctx, winget, cli); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, winget config.Winget, cli client.Client) error {
+	if winget.Repository.Name == "" {
+		return pipe.Skip("winget.repository is not set")
+	}
+	var filters = []artifact.Filter{
+		artifact.ByGoos("windows"),
+		artifact.ByFormats("zip"),
+		artifact.ByType(artifact.UploadableArchive),
+		artifact.Or(
+			artifact.ByGoarch("amd64"),
+			artifact.ByGoarch("386"),
+			artifact.ByGoarch("arm64"),
+		),
+	}
+	if len(winget.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(winget.IDs...))
+	}
+	var archives = ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(archives) == 0 {
+		return ErrNoWindowsArchives
+	}
+
+	files, err := buildManifests(ctx, winget, archives)
+	if err != nil {
+		return err
+	}
+	var dir = filepath.Join(ctx.Config.Dist, "winget", winget.PackageIdentifier)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for name, content := range files {
+		log.WithField("manifest", name).Info("writing")
+		if err := ioutil.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			return err
+		}
+	}
+
+	if strings.TrimSpace(winget.SkipUpload) == "true" {
+		return pipe.Skip("winget.skip_upload is set")
+	}
+	if ctx.SkipPublish {
+		return pipe.ErrSkipPublishEnabled
+	}
+	if ctx.Config.Release.Draft {
+		return pipe.Skip("release is marked as draft")
+	}
+	if ctx.Config.Release.Disable {
+		return pipe.Skip("release is disabled")
+	}
+	if strings.TrimSpace(winget.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping winget publish")
+	}
+
+	pr, ok := cli.(client.PullRequester)
+	if !ok {
+		return ErrNoPullRequests
+	}
+	folder, err := tmpl.New(ctx).Apply(winget.Path)
+	if err != nil {
+		return errors.Wrap(err, "failed to template the winget path")
+	}
+	branch, err := tmpl.New(ctx).Apply(winget.Branch)
+	if err != nil {
+		return errors.Wrap(err, "failed to template the winget branch")
+	}
+	var changes = map[string][]byte{}
+	for name, content := range files {
+		changes[path.Join(folder, name)] = content
+	}
+	var title = fmt.Sprintf("New version: %s %s", winget.PackageIdentifier, ctx.Version)
+	url, err := pr.OpenPullRequest(ctx, winget.CommitAuthor, winget.Repository, winget.Base, changes, branch, title, title)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open winget pull request on %s", winget.Base)
+	}
+	log.WithField("url", url).Info("opened pull request")
+	return nil
+}
+
+// buildManifests returns the version, installer and default locale
+// manifests, by file name
+func buildManifests(ctx *context.Context, winget config.Winget, archives []*artifact.Artifact) (map[string][]byte, error) {
+	if winget.URLTemplate == "" {
+		urlTemplate, err := client.DownloadURLTemplate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		winget.URLTemplate = urlTemplate
+	}
+
+	var installer = Installer{
+		PackageIdentifier:   winget.PackageIdentifier,
+		PackageVersion:      ctx.Version,
+		InstallerLocale:     defaultLocale,
+		InstallerType:       "zip",
+		NestedInstallerType: "portable",
+		ReleaseDate:         ctx.Git.CommitDate.Format("2006-01-02"),
+		ManifestType:        "installer",
+		ManifestVersion:     manifestVersion,
+	}
+	var commands = map[string]bool{}
+	var archs = map[string]bool{}
+	for _, archive := range archives {
+		var arch = archFor(archive)
+		if archs[arch] {
+			return nil, fmt.Errorf("winget %s: found multiple windows %s archives, use ids to pick one", winget.PackageIdentifier, archive.Goarch)
+		}
+		archs[arch] = true
+		url, err := tmpl.New(ctx).WithArtifact(archive, map[string]string{}).Apply(winget.URLTemplate)
+		if err != nil {
+			return nil, err
+		}
+		sum, err := archive.Checksum("sha256")
+		if err != nil {
+			return nil, err
+		}
+		var item = InstallerItem{
+			Architecture:    arch,
+			InstallerURL:    url,
+			InstallerSha256: sum,
+			UpgradeBehavior: "uninstallPrevious",
+		}
+		var wrap = archive.ExtraOr("WrappedIn", "").(string)
+		for _, binary := range archive.ExtraOr("Builds", []*artifact.Artifact{}).([]*artifact.Artifact) {
+			var name = binary.ExtraOr("Binary", "").(string)
+			var file = name + ".exe"
+			if wrap != "" {
+				file = wrap + `\` + file
+			}
+			item.NestedInstallerFiles = append(item.NestedInstallerFiles, NestedInstallerFile{
+				RelativeFilePath:     file,
+				PortableCommandAlias: name,
+			})
+			commands[name] = true
+		}
+		installer.Installers = append(installer.Installers, item)
+	}
+	sort.Slice(installer.Installers, func(i, j int) bool {
+		return installer.Installers[i].Architecture < installer.Installers[j].Architecture
+	})
+	for command := range commands {
+		installer.Commands = append(installer.Commands, command)
+	}
+	sort.Strings(installer.Commands)
+
+	var notes = winget.ReleaseNotes
+	if notes == "" {
+		notes = ctx.ReleaseNotes
+	} else {
+		var err error
+		if notes, err = tmpl.New(ctx).Apply(notes); err != nil {
+			return nil, err
+		}
+	}
+	notesURL, err := tmpl.New(ctx).Apply(winget.ReleaseNotesURL)
+	if err != nil {
+		return nil, err
+	}
+	var locale = Locale{
+		PackageIdentifier: winget.PackageIdentifier,
+		PackageVersion:    ctx.Version,
+		PackageLocale:     defaultLocale,
+		Publisher:         winget.Publisher,
+		PublisherURL:      winget.PublisherURL,
+		Author:            winget.Author,
+		PackageName:       winget.Name,
+		PackageURL:        winget.Homepage,
+		License:           winget.License,
+		LicenseURL:        winget.LicenseURL,
+		Copyright:         winget.Copyright,
+		ShortDescription:  winget.ShortDescription,
+		Description:       winget.Description,
+		Moniker:           strings.ToLower(strings.Replace(winget.Name, " ", "-", -1)),
+		Tags:              winget.Tags,
+		ReleaseNotes:      notes,
+		ReleaseNotesURL:   notesURL,
+		ManifestType:      "defaultLocale",
+		ManifestVersion:   manifestVersion,
+	}
+	var version = Version{
+		PackageIdentifier: winget.PackageIdentifier,
+		PackageVersion:    ctx.Version,
+		DefaultLocale:     defaultLocale,
+		ManifestType:      "version",
+		ManifestVersion:   manifestVersion,
+	}
+
+	var files = map[string][]byte{}
+	for name, manifest := range map[string]struct {
+		content interface{}
+		schema  string
+	}{
+		winget.PackageIdentifier + ".yaml":                              {version, "version"},
+		winget.PackageIdentifier + ".installer.yaml":                    {installer, "installer"},
+		winget.PackageIdentifier + ".locale." + defaultLocale + ".yaml": {locale, "defaultLocale"},
+	} {
+		bts, err := marshal(manifest.content, manifest.schema)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = bts
+	}
+	return files, nil
+}
+
+// archFor returns the winget arch of the given archive
+func archFor(archive *artifact.Artifact) string {
+	switch archive.Goarch {
+	case "386":
+		return "x86"
+	case "arm64":
+		return "arm64"
+	}
+	return "x64"
+}
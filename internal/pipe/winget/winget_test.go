@@ -0,0 +1,330 @@
+package winget
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update .golden files")
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Metadata: config.Metadata{
+			Description: "A foo",
+			Homepage:    "https://example.com",
+			License:     "MIT",
+			Vendor:      "Foo Inc",
+		},
+		Wingets: []config.Winget{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Winget{
+		Name:              "foo",
+		PackageIdentifier: "FooInc.foo",
+		Publisher:         "Foo Inc",
+		ShortDescription:  "A foo",
+		Homepage:          "https://example.com",
+		License:           "MIT",
+		Base:              config.Repo{Owner: "microsoft", Name: "winget-pkgs"},
+		Path:              "manifests/f/FooInc/foo/{{ .Version }}",
+		Branch:            "FooInc.foo-{{ .Version }}",
+		CommitAuthor: config.CommitAuthor{
+			Name:  "goreleaserbot",
+			Email: "goreleaser@carlosbecker.com",
+		},
+	}, ctx.Config.Wingets[0])
+}
+
+func TestDefaultNoPublisher(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Wingets:     []config.Winget{{}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "winget foo: publisher is required")
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Publish(context.New(config.Project{})))
+}
+
+func wingetContext(t *testing.T, folder string, winget config.Winget) *context.Context {
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        folder,
+		Metadata: config.Metadata{
+			Description: "A foo",
+			License:     "MIT",
+			Vendor:      "Foo Inc",
+		},
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "foo", Name: "bar"},
+			GitLab: config.Repo{Owner: "foo", Name: "bar"},
+		},
+		GitHubURLs: config.GitHubURLs{Download: "https://github.com"},
+		GitLabURLs: config.GitLabURLs{Download: "https://gitlab.com"},
+		Wingets:    []config.Winget{winget},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Git.CommitDate = time.Date(2020, 10, 15, 0, 0, 0, 0, time.UTC)
+	ctx.ReleaseNotes = "## Changelog\n\n* a change"
+	for _, archive := range []struct {
+		goarch, format, id string
+	}{
+		{"amd64", "zip", "default"},
+		{"386", "zip", "default"},
+		{"arm64", "zip", "default"},
+		{"arm", "zip", "default"},
+		{"amd64", "tar.gz", "default"},
+		{"amd64", "zip", "other"},
+	} {
+		var name = "foo_windows_" + archive.goarch + "_" + archive.id + "." + archive.format
+		var path = filepath.Join(folder, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(name), 0644))
+		var extra = map[string]interface{}{
+			"ID":     archive.id,
+			"Format": archive.format,
+			"Builds": []*artifact.Artifact{
+				{Extra: map[string]interface{}{"Binary": "foo"}},
+				{Extra: map[string]interface{}{"Binary": "foo-helper"}},
+			},
+		}
+		if archive.id == "other" {
+			extra["WrappedIn"] = "foo_1.0.0"
+		}
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type:   artifact.UploadableArchive,
+			Name:   name,
+			Path:   path,
+			Goos:   "windows",
+			Goarch: archive.goarch,
+			Extra:  extra,
+		})
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestRunPipe(t *testing.T) {
+	for name, tt := range map[string]struct {
+		winget config.Winget
+		gitlab bool
+	}{
+		"default": {
+			winget: config.Winget{
+				IDs:        []string{"default"},
+				Repository: config.Repo{Owner: "foo", Name: "winget-pkgs"},
+			},
+		},
+		"full": {
+			winget: config.Winget{
+				Name:              "Foo",
+				PackageIdentifier: "Foo.Foo",
+				IDs:               []string{"default"},
+				Publisher:         "Foo Inc",
+				PublisherURL:      "https://foo.example.com",
+				Author:            "Foo Bar",
+				ShortDescription:  "A foo",
+				Description:       "A foo for everyone.",
+				Homepage:          "https://example.com",
+				License:           "MIT",
+				LicenseURL:        "https://github.com/foo/bar/blob/master/LICENSE",
+				Copyright:         "2020 Foo Inc",
+				Tags:              []string{"foo", "cli"},
+				ReleaseNotes:      "See the release.",
+				ReleaseNotesURL:   "https://github.com/foo/bar/releases/tag/{{ .Tag }}",
+				Repository:        config.Repo{Owner: "foo", Name: "winget-pkgs"},
+			},
+		},
+		"gitlab": {
+			winget: config.Winget{
+				IDs:        []string{"default"},
+				Repository: config.Repo{Owner: "foo", Name: "winget-pkgs"},
+			},
+			gitlab: true,
+		},
+		"url_template": {
+			winget: config.Winget{
+				IDs:         []string{"other"},
+				URLTemplate: "https://dl.example.com/{{ .Tag }}/{{ .ArtifactName }}",
+				Repository:  config.Repo{Owner: "foo", Name: "winget-pkgs"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder, err := ioutil.TempDir("", "wingettest")
+			require.NoError(t, err)
+			var ctx = wingetContext(t, folder, tt.winget)
+			if tt.gitlab {
+				ctx.TokenType = context.TokenTypeGitLab
+				for _, a := range ctx.Artifacts.List() {
+					a.Extra["ArtifactUploadHash"] = "820ead5d9d2266c728dce6d4d55b6460"
+				}
+			}
+			var cli = &fakeClient{}
+			var winget = ctx.Config.Wingets[0]
+			require.NoError(t, doRun(ctx, winget, cli))
+
+			require.Equal(t, winget.Repository, cli.repo)
+			require.Equal(t, config.Repo{Owner: "microsoft", Name: "winget-pkgs"}, cli.base)
+			require.Equal(t, winget.PackageIdentifier+"-1.0.0", cli.branch)
+			require.Equal(t, "New version: "+winget.PackageIdentifier+" 1.0.0", cli.title)
+			require.Len(t, cli.files, 3)
+
+			var dir = "manifests/f/" + map[bool]string{true: "Foo/Foo", false: "FooInc/foo"}[name == "full"] + "/1.0.0/"
+			for file, kind := range map[string]string{
+				winget.PackageIdentifier + ".yaml":              "version",
+				winget.PackageIdentifier + ".installer.yaml":    "installer",
+				winget.PackageIdentifier + ".locale.en-US.yaml": "locale",
+			} {
+				var content = cli.files[dir+file]
+				require.NotEmpty(t, content, dir+file)
+				var golden = "testdata/" + name + "." + kind + ".yaml.golden"
+				if *update {
+					require.NoError(t, ioutil.WriteFile(golden, content, 0655))
+				}
+				bts, err := ioutil.ReadFile(golden)
+				require.NoError(t, err)
+				require.Equal(t, string(bts), string(content))
+
+				dist, err := ioutil.ReadFile(filepath.Join(folder, "winget", winget.PackageIdentifier, file))
+				require.NoError(t, err)
+				require.Equal(t, string(bts), string(dist))
+			}
+		})
+	}
+}
+
+func TestRunPipeNoArchives(t *testing.T) {
+	folder, err := ioutil.TempDir("", "wingettest")
+	require.NoError(t, err)
+	var ctx = wingetContext(t, folder, config.Winget{
+		IDs:        []string{"nope"},
+		Repository: config.Repo{Owner: "foo", Name: "winget-pkgs"},
+	})
+	require.Equal(t, ErrNoWindowsArchives, doRun(ctx, ctx.Config.Wingets[0], &fakeClient{}))
+}
+
+func TestRunPipeMultipleArchives(t *testing.T) {
+	folder, err := ioutil.TempDir("", "wingettest")
+	require.NoError(t, err)
+	var ctx = wingetContext(t, folder, config.Winget{
+		Repository: config.Repo{Owner: "foo", Name: "winget-pkgs"},
+	})
+	require.EqualError(
+		t,
+		doRun(ctx, ctx.Config.Wingets[0], &fakeClient{}),
+		"winget FooInc.foo: found multiple windows amd64 archives, use ids to pick one",
+	)
+}
+
+func TestRunPipeTokenTypeNotImplemented(t *testing.T) {
+	folder, err := ioutil.TempDir("", "wingettest")
+	require.NoError(t, err)
+	var ctx = wingetContext(t, folder, config.Winget{
+		IDs:        []string{"default"},
+		Repository: config.Repo{Owner: "foo", Name: "winget-pkgs"},
+	})
+	ctx.TokenType = ""
+	require.Equal(t, client.ErrTokenTypeNotImplemented, doRun(ctx, ctx.Config.Wingets[0], &fakeClient{}))
+}
+
+func TestRunPipeNoPullRequests(t *testing.T) {
+	folder, err := ioutil.TempDir("", "wingettest")
+	require.NoError(t, err)
+	var ctx = wingetContext(t, folder, config.Winget{
+		IDs:        []string{"default"},
+		Repository: config.Repo{Owner: "foo", Name: "winget-pkgs"},
+	})
+	require.Equal(t, ErrNoPullRequests, doRun(ctx, ctx.Config.Wingets[0], &basicClient{}))
+}
+
+func TestRunPipeNoRepository(t *testing.T) {
+	var cli = &fakeClient{}
+	testlib.AssertSkipped(t, doRun(context.New(config.Project{}), config.Winget{}, cli))
+	require.Nil(t, cli.files)
+}
+
+func TestRunPipeSkipPublish(t *testing.T) {
+	for name, setup := range map[string]func(ctx *context.Context){
+		"skip_upload": func(ctx *context.Context) {
+			ctx.Config.Wingets[0].SkipUpload = "true"
+		},
+		"auto": func(ctx *context.Context) {
+			ctx.Config.Wingets[0].SkipUpload = "auto"
+			ctx.Semver.Prerelease = "beta1"
+		},
+		"skip_publish": func(ctx *context.Context) {
+			ctx.SkipPublish = true
+		},
+		"draft": func(ctx *context.Context) {
+			ctx.Config.Release.Draft = true
+		},
+		"disabled": func(ctx *context.Context) {
+			ctx.Config.Release.Disable = true
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder, err := ioutil.TempDir("", "wingettest")
+			require.NoError(t, err)
+			var ctx = wingetContext(t, folder, config.Winget{
+				IDs:        []string{"default"},
+				Repository: config.Repo{Owner: "foo", Name: "winget-pkgs"},
+			})
+			setup(ctx)
+			var cli = &fakeClient{}
+			testlib.AssertSkipped(t, doRun(ctx, ctx.Config.Wingets[0], cli))
+			require.Nil(t, cli.files)
+			require.FileExists(t, filepath.Join(folder, "winget", "FooInc.foo", "FooInc.foo.installer.yaml"))
+		})
+	}
+}
+
+type basicClient struct{}
+
+func (*basicClient) CreateRelease(ctx *context.Context, body string) (string, error) {
+	return "", nil
+}
+
+func (*basicClient) CreateFile(ctx *context.Context, commitAuthor config.CommitAuthor, repo config.Repo, content []byte, path, message string) error {
+	return nil
+}
+
+func (*basicClient) Upload(ctx *context.Context, releaseID string, artifact *artifact.Artifact, file *os.File) error {
+	return nil
+}
+
+type fakeClient struct {
+	basicClient
+	repo   config.Repo
+	base   config.Repo
+	files  map[string][]byte
+	branch string
+	title  string
+}
+
+func (c *fakeClient) GetFile(ctx *context.Context, repo config.Repo, path string) ([]byte, error) {
+	return nil, os.ErrNotExist
+}
+
+func (c *fakeClient) OpenPullRequest(ctx *context.Context, commitAuthor config.CommitAuthor, repo, base config.Repo, files map[string][]byte, branch, title, message string) (string, error) {
+	c.repo = repo
+	c.base = base
+	c.files = files
+	c.branch = branch
+	c.title = title
+	return "https://github.com/microsoft/winget-pkgs/pull/1", nil
+}
@@ -0,0 +1,166 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/goreleaser/goreleaser/internal/pipe/semver"
+
+	"github.com/goreleaser/goreleaser/internal/pipe/appimage"
+	"github.com/goreleaser/goreleaser/internal/pipe/archive"
+	"github.com/goreleaser/goreleaser/internal/pipe/authenticode"
+	"github.com/goreleaser/goreleaser/internal/pipe/before"
+	"github.com/goreleaser/goreleaser/internal/pipe/build"
+	"github.com/goreleaser/goreleaser/internal/pipe/buildpacks"
+	"github.com/goreleaser/goreleaser/internal/pipe/changelog"
+	"github.com/goreleaser/goreleaser/internal/pipe/checksums"
+	"github.com/goreleaser/goreleaser/internal/pipe/ci"
+	"github.com/goreleaser/goreleaser/internal/pipe/defaults"
+	"github.com/goreleaser/goreleaser/internal/pipe/dist"
+	"github.com/goreleaser/goreleaser/internal/pipe/docker"
+	"github.com/goreleaser/goreleaser/internal/pipe/effectiveconfig"
+	"github.com/goreleaser/goreleaser/internal/pipe/env"
+	"github.com/goreleaser/goreleaser/internal/pipe/flatpak"
+	"github.com/goreleaser/goreleaser/internal/pipe/generate"
+	"github.com/goreleaser/goreleaser/internal/pipe/git"
+	"github.com/goreleaser/goreleaser/internal/pipe/macospkg"
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/internal/pipe/msi"
+	"github.com/goreleaser/goreleaser/internal/pipe/nfpm"
+	"github.com/goreleaser/goreleaser/internal/pipe/notarize"
+	"github.com/goreleaser/goreleaser/internal/pipe/phase"
+	"github.com/goreleaser/goreleaser/internal/pipe/provenance"
+	"github.com/goreleaser/goreleaser/internal/pipe/publish"
+	"github.com/goreleaser/goreleaser/internal/pipe/sbom"
+	"github.com/goreleaser/goreleaser/internal/pipe/sign"
+	"github.com/goreleaser/goreleaser/internal/pipe/snapcraft"
+	"github.com/goreleaser/goreleaser/internal/pipe/snapshot"
+	"github.com/goreleaser/goreleaser/internal/pipe/sourcearchive"
+	"github.com/goreleaser/goreleaser/internal/pipe/universalbinary"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Dependencies lists, for each pipe of the Pipeline, the pipes that must
+// finish before it can start. Pipes that do not depend on each other may run
+// concurrently.
+// nolint: gochecknoglobals
+var Dependencies = map[Piper][]Piper{
+	before.Pipe{}:          {},
+	env.Pipe{}:             {before.Pipe{}},
+	git.Pipe{}:             {env.Pipe{}},
+	semver.Pipe{}:          {git.Pipe{}},
+	defaults.Pipe{}:        {semver.Pipe{}},
+	snapshot.Pipe{}:        {defaults.Pipe{}},
+	dist.Pipe{}:            {snapshot.Pipe{}},
+	effectiveconfig.Pipe{}: {dist.Pipe{}},
+	changelog.Pipe{}:       {effectiveconfig.Pipe{}},
+	generate.Pipe{}:        {effectiveconfig.Pipe{}},
+	build.Pipe{}:           {generate.Pipe{}},
+	universalbinary.Pipe{}: {build.Pipe{}},
+	phase.AfterBuild:       {universalbinary.Pipe{}},
+	authenticode.Pipe{}:    {phase.AfterBuild},
+	notarize.Pipe{}:        {phase.AfterBuild},
+	phase.BeforeArchive:    {authenticode.Pipe{}, notarize.Pipe{}},
+	archive.Pipe{}:         {phase.BeforeArchive},
+	sourcearchive.Pipe{}:   {phase.BeforeArchive},
+	nfpm.Pipe{}:            {phase.BeforeArchive},
+	snapcraft.Pipe{}:       {phase.BeforeArchive},
+	flatpak.Pipe{}:         {phase.BeforeArchive},
+	appimage.Pipe{}:        {phase.BeforeArchive},
+	msi.Pipe{}:             {phase.BeforeArchive},
+	macospkg.Pipe{}:        {phase.BeforeArchive},
+	docker.Pipe{}:          {phase.BeforeArchive},
+	buildpacks.Pipe{}:      {phase.BeforeArchive},
+	checksums.Pipe{}:       {archive.Pipe{}, sourcearchive.Pipe{}, nfpm.Pipe{}, snapcraft.Pipe{}, flatpak.Pipe{}, appimage.Pipe{}, msi.Pipe{}, macospkg.Pipe{}, docker.Pipe{}, buildpacks.Pipe{}},
+	sbom.Pipe{}:            {checksums.Pipe{}},
+	provenance.Pipe{}:      {sbom.Pipe{}},
+	sign.Pipe{}:            {provenance.Pipe{}},
+	phase.AfterSign:        {sign.Pipe{}},
+	phase.BeforePublish:    {changelog.Pipe{}, phase.AfterSign},
+	publish.Pipe{}:         {phase.BeforePublish},
+	phase.AfterPublish:     {publish.Pipe{}},
+	metadata.Pipe{}:        {phase.AfterPublish},
+	ci.Pipe{}:              {metadata.Pipe{}},
+}
+
+// Run runs all pipes of the Pipeline, respecting their Dependencies and
+// running at most ctx.PipeParallelism pipes at the same time.
+func Run(ctx *context.Context, run func(Piper) error) error {
+	return Schedule(Pipeline, Dependencies, ctx.PipeParallelism, run)
+}
+
+// Schedule runs the given pipes as soon as all their dependencies are done,
+// with at most parallelism of them running at the same time.
+// Once a pipe fails, no more pipes are started, and the first error is
+// returned after the running ones finish.
+func Schedule(pipes []Piper, deps map[Piper][]Piper, parallelism int, run func(Piper) error) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var known = map[Piper]bool{}
+	for _, pipe := range pipes {
+		known[pipe] = true
+	}
+	var pending = map[Piper]int{}
+	var dependents = map[Piper][]Piper{}
+	for _, pipe := range pipes {
+		for _, dep := range deps[pipe] {
+			if !known[dep] {
+				return fmt.Errorf("pipe %q depends on %q, which is not in the pipeline", pipe, dep)
+			}
+			pending[pipe]++
+			dependents[dep] = append(dependents[dep], pipe)
+		}
+	}
+
+	var ready []Piper
+	for _, pipe := range pipes {
+		if pending[pipe] == 0 {
+			ready = append(ready, pipe)
+		}
+	}
+
+	type result struct {
+		pipe Piper
+		err  error
+	}
+	var results = make(chan result)
+	var running, finished int
+	var firstErr error
+	for {
+		for firstErr == nil && len(ready) > 0 && running < parallelism {
+			var pipe = ready[0]
+			ready = ready[1:]
+			running++
+			go func() {
+				results <- result{pipe: pipe, err: run(pipe)}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+		var r = <-results
+		running--
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+		finished++
+		for _, dependent := range dependents[r.pipe] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if finished != len(pipes) {
+		return fmt.Errorf("pipeline has a dependency cycle")
+	}
+	return nil
+}
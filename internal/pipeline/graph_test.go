@@ -0,0 +1,88 @@
+package pipeline
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePipe string
+
+func (p fakePipe) String() string                 { return string(p) }
+func (p fakePipe) Run(ctx *context.Context) error { return nil }
+
+func TestDependenciesCoverPipeline(t *testing.T) {
+	for _, pipe := range Pipeline {
+		_, ok := Dependencies[pipe]
+		assert.True(t, ok, "missing dependencies for %s", pipe)
+	}
+	assert.Len(t, Dependencies, len(Pipeline))
+	require.NoError(t, Schedule(Pipeline, Dependencies, 4, func(Piper) error {
+		return nil
+	}))
+}
+
+func TestSchedule(t *testing.T) {
+	var a, b, c, d = fakePipe("a"), fakePipe("b"), fakePipe("c"), fakePipe("d")
+	var deps = map[Piper][]Piper{
+		b: {a},
+		c: {a},
+		d: {b, c},
+	}
+	for _, parallelism := range []int{0, 1, 4} {
+		t.Run(fmt.Sprint(parallelism), func(t *testing.T) {
+			var lock sync.Mutex
+			var done = map[Piper]bool{}
+			require.NoError(t, Schedule([]Piper{a, b, c, d}, deps, parallelism, func(pipe Piper) error {
+				lock.Lock()
+				defer lock.Unlock()
+				for _, dep := range deps[pipe] {
+					assert.True(t, done[dep], "%s ran before %s", pipe, dep)
+				}
+				done[pipe] = true
+				return nil
+			}))
+			assert.Len(t, done, 4)
+		})
+	}
+}
+
+func TestScheduleError(t *testing.T) {
+	var a, b, c = fakePipe("a"), fakePipe("b"), fakePipe("c")
+	var ran []Piper
+	var err = Schedule([]Piper{a, b, c}, map[Piper][]Piper{
+		b: {a},
+		c: {b},
+	}, 4, func(pipe Piper) error {
+		ran = append(ran, pipe)
+		if pipe == b {
+			return fmt.Errorf("fake err")
+		}
+		return nil
+	})
+	require.EqualError(t, err, "fake err")
+	assert.Equal(t, []Piper{a, b}, ran)
+}
+
+func TestScheduleCycle(t *testing.T) {
+	var a, b = fakePipe("a"), fakePipe("b")
+	require.EqualError(t, Schedule([]Piper{a, b}, map[Piper][]Piper{
+		a: {b},
+		b: {a},
+	}, 4, func(Piper) error {
+		return nil
+	}), "pipeline has a dependency cycle")
+}
+
+func TestScheduleUnknownDependency(t *testing.T) {
+	var a = fakePipe("a")
+	require.EqualError(t, Schedule([]Piper{a}, map[Piper][]Piper{
+		a: {fakePipe("b")},
+	}, 4, func(Piper) error {
+		return nil
+	}), `pipe "a" depends on "b", which is not in the pipeline`)
+}
@@ -6,22 +6,37 @@ import (
 
 	"github.com/goreleaser/goreleaser/internal/pipe/semver"
 
+	"github.com/goreleaser/goreleaser/internal/pipe/appimage"
 	"github.com/goreleaser/goreleaser/internal/pipe/archive"
+	"github.com/goreleaser/goreleaser/internal/pipe/authenticode"
 	"github.com/goreleaser/goreleaser/internal/pipe/before"
 	"github.com/goreleaser/goreleaser/internal/pipe/build"
+	"github.com/goreleaser/goreleaser/internal/pipe/buildpacks"
 	"github.com/goreleaser/goreleaser/internal/pipe/changelog"
 	"github.com/goreleaser/goreleaser/internal/pipe/checksums"
+	"github.com/goreleaser/goreleaser/internal/pipe/ci"
 	"github.com/goreleaser/goreleaser/internal/pipe/defaults"
 	"github.com/goreleaser/goreleaser/internal/pipe/dist"
 	"github.com/goreleaser/goreleaser/internal/pipe/docker"
 	"github.com/goreleaser/goreleaser/internal/pipe/effectiveconfig"
 	"github.com/goreleaser/goreleaser/internal/pipe/env"
+	"github.com/goreleaser/goreleaser/internal/pipe/flatpak"
+	"github.com/goreleaser/goreleaser/internal/pipe/generate"
 	"github.com/goreleaser/goreleaser/internal/pipe/git"
+	"github.com/goreleaser/goreleaser/internal/pipe/macospkg"
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/internal/pipe/msi"
 	"github.com/goreleaser/goreleaser/internal/pipe/nfpm"
+	"github.com/goreleaser/goreleaser/internal/pipe/notarize"
+	"github.com/goreleaser/goreleaser/internal/pipe/phase"
+	"github.com/goreleaser/goreleaser/internal/pipe/provenance"
 	"github.com/goreleaser/goreleaser/internal/pipe/publish"
+	"github.com/goreleaser/goreleaser/internal/pipe/sbom"
 	"github.com/goreleaser/goreleaser/internal/pipe/sign"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapcraft"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapshot"
+	"github.com/goreleaser/goreleaser/internal/pipe/sourcearchive"
+	"github.com/goreleaser/goreleaser/internal/pipe/universalbinary"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
@@ -45,12 +60,31 @@ var Pipeline = []Piper{
 	dist.Pipe{},            // ensure ./dist is clean
 	effectiveconfig.Pipe{}, // writes the actual config (with defaults et al set) to dist
 	changelog.Pipe{},       // builds the release changelog
+	generate.Pipe{},        // run code generators whose inputs changed
 	build.Pipe{},           // build
+	universalbinary.Pipe{}, // merge darwin binaries into universal binaries
+	phase.AfterBuild,       // run after_build hooks
+	authenticode.Pipe{},    // sign windows binaries
+	notarize.Pipe{},        // codesign and notarize darwin binaries
+	phase.BeforeArchive,    // run before_archive hooks
 	archive.Pipe{},         // archive in tar.gz, zip or binary (which does no archiving at all)
-	nfpm.Pipe{},            // archive via fpm (deb, rpm) using "native" go impl
+	sourcearchive.Pipe{},   // archive the source code
+	nfpm.Pipe{},            // archive via fpm (deb, rpm, apk, termux) using "native" go impl
 	snapcraft.Pipe{},       // archive via snapcraft (snap)
+	flatpak.Pipe{},         // bundle via flatpak-builder (flatpak)
+	appimage.Pipe{},        // bundle via appimagetool (AppImage)
+	msi.Pipe{},             // windows installers via wixl (MSI)
+	macospkg.Pipe{},        // macOS installers via pkgbuild and productbuild (pkg)
+	docker.Pipe{},          // create and push docker images
+	buildpacks.Pipe{},      // create container images with buildpacks
 	checksums.Pipe{},       // checksums of the files
+	sbom.Pipe{},            // catalog the artifacts in software bills of materials
+	provenance.Pipe{},      // attest how the artifacts were built
 	sign.Pipe{},            // sign artifacts
-	docker.Pipe{},          // create and push docker images
+	phase.AfterSign,        // run after_sign hooks
+	phase.BeforePublish,    // run before_publish hooks
 	publish.Pipe{},         // publishes artifacts
+	phase.AfterPublish,     // run after_publish hooks
+	metadata.Pipe{},        // records what was published, so it can be rolled back
+	ci.Pipe{},              // exports outputs to the CI system
 }
@@ -0,0 +1,96 @@
+// Package rollback undoes what a release published, based on the metadata
+// recorded by the metadata pipe.
+package rollback
+
+import (
+	stdctx "context"
+	"fmt"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/pipe/blob"
+	"github.com/goreleaser/goreleaser/internal/pipe/docker"
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// Run rolls back the release described by the given metadata
+func Run(ctx *context.Context, meta metadata.Metadata) error {
+	if meta.TokenType != "" && meta.TokenType != ctx.TokenType {
+		return fmt.Errorf("release was published with a %s token, but a %s token was provided", meta.TokenType, ctx.TokenType)
+	}
+	c, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	remover, ok := c.(client.Remover)
+	if !ok {
+		return fmt.Errorf("rollback is not supported for %s releases", ctx.TokenType)
+	}
+
+	for _, file := range meta.CommittedFiles {
+		log.WithFields(log.Fields{
+			"repo": file.Repo.String(),
+			"path": file.Path,
+		}).Info("reverting")
+		var msg = fmt.Sprintf("Rollback %s version %s", meta.ProjectName, meta.Tag)
+		if err := remover.RevertFile(ctx, file.CommitAuthor, file.Repo, file.Path, msg); err != nil {
+			return errors.Wrapf(err, "failed to revert %s", file.Path)
+		}
+	}
+
+	for _, upload := range meta.Blobs {
+		if err := blob.Delete(ctx, upload); err != nil {
+			return err
+		}
+	}
+
+	if len(meta.DockerImages) > 0 {
+		creds, err := docker.Login(ctx)
+		if err != nil {
+			return err
+		}
+		defer creds.Logout()
+		for _, image := range meta.DockerImages {
+			log.WithField("image", image).Info("deleting")
+			if err := deleteImage(ctx, creds.Keychain(ctx), image); err != nil {
+				log.WithError(err).WithField("image", image).Warn("could not delete image, please remove it manually")
+			}
+		}
+	}
+
+	log.WithField("tag", meta.Tag).Info("deleting release")
+	return remover.DeleteRelease(ctx, meta.Tag)
+}
+
+// imageTimeout is the time deleting each docker image may take
+const imageTimeout = time.Minute
+
+// deleteImage deletes the manifest the given image points to using the
+// docker registry v2 API, with the credentials of the docker config.
+// Registries may not allow it, so its errors are only warned about.
+func deleteImage(ctx *context.Context, keychain authn.Keychain, image string) error {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return err
+	}
+	timeout, cancel := stdctx.WithTimeout(ctx, imageTimeout)
+	defer cancel()
+	var options = []remote.Option{
+		remote.WithContext(timeout),
+		remote.WithAuthFromKeychain(keychain),
+	}
+	desc, err := remote.Head(ref, options...)
+	if err != nil {
+		return errors.Wrap(err, "failed to get manifest")
+	}
+	if err := remote.Delete(ref.Context().Digest(desc.Digest.String()), options...); err != nil {
+		return errors.Wrap(err, "failed to delete manifest")
+	}
+	return nil
+}
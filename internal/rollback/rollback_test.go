@@ -0,0 +1,119 @@
+package rollback
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/goreleaser/goreleaser/internal/pipe/docker"
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// testRegistry is a registry which needs the given credentials, and has the
+// manifest sha256:abc... for every tag
+func testRegistry(t *testing.T, deleted *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "foo" || pass != "bar" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			require.Equal(t, "/v2/", r.URL.Path)
+		case http.MethodHead:
+			require.Equal(t, "/v2/goreleaser/foo/manifests/v1.0.0", r.URL.Path)
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Header().Set("Content-Length", "100")
+			w.Header().Set("Docker-Content-Digest", digest)
+		case http.MethodDelete:
+			if deleted == nil {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			*deleted = r.URL.Path
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+}
+
+const digest = "sha256:abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789"
+
+func loginContext(t *testing.T, registry string) (*context.Context, authn.Keychain, func()) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	var ctx = context.New(config.Project{
+		DockerRegistries: []config.DockerRegistry{
+			{Registry: registry, Username: "foo", Password: "bar"},
+		},
+	})
+	ctx.Env["DOCKER_CONFIG"] = folder
+	creds, err := docker.Login(ctx)
+	require.NoError(t, err)
+	return ctx, creds.Keychain(ctx), func() {
+		creds.Logout()
+		_ = os.RemoveAll(folder)
+	}
+}
+
+func TestDeleteImage(t *testing.T) {
+	var deleted string
+	srv := testRegistry(t, &deleted)
+	defer srv.Close()
+	var registry = strings.TrimPrefix(srv.URL, "http://")
+	ctx, keychain, logout := loginContext(t, registry)
+	defer logout()
+	require.NoError(t, deleteImage(ctx, keychain, registry+"/goreleaser/foo:v1.0.0"))
+	require.Equal(t, "/v2/goreleaser/foo/manifests/"+digest, deleted)
+}
+
+func TestDeleteImageNotAllowed(t *testing.T) {
+	srv := testRegistry(t, nil)
+	defer srv.Close()
+	var registry = strings.TrimPrefix(srv.URL, "http://")
+	ctx, keychain, logout := loginContext(t, registry)
+	defer logout()
+	var err = deleteImage(ctx, keychain, registry+"/goreleaser/foo:v1.0.0")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to delete manifest")
+}
+
+func TestDeleteImageUnauthorized(t *testing.T) {
+	var deleted string
+	srv := testRegistry(t, &deleted)
+	defer srv.Close()
+	var registry = strings.TrimPrefix(srv.URL, "http://")
+	ctx, _, logout := loginContext(t, registry)
+	defer logout()
+	var err = deleteImage(ctx, authn.NewMultiKeychain(), registry+"/goreleaser/foo:v1.0.0")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to get manifest")
+	require.Empty(t, deleted)
+}
+
+func TestRunTokenMismatch(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.TokenType = context.TokenTypeGitLab
+	require.EqualError(t, Run(ctx, metadata.Metadata{
+		TokenType: context.TokenTypeGitHub,
+	}), "release was published with a github token, but a gitlab token was provided")
+}
+
+func TestRunUnsupported(t *testing.T) {
+	var ctx = context.New(config.Project{
+		GiteaURLs: config.GiteaURLs{
+			API: "https://gitea.example.com/api/v1",
+		},
+	})
+	ctx.TokenType = context.TokenTypeGitea
+	require.EqualError(t, Run(ctx, metadata.Metadata{
+		TokenType: context.TokenTypeGitea,
+	}), "rollback is not supported for gitea releases")
+}
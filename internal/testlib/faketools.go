@@ -0,0 +1,26 @@
+package testlib
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// FakeTools writes the given shell scripts, indexed by the name of the tool
+// they fake, to the bin folder of folder and puts it first in the PATH. It
+// provides a back function that restores the previous PATH.
+func FakeTools(t *testing.T, folder string, tools map[string]string) (back func()) {
+	var bin = filepath.Join(folder, "bin")
+	assert.NoError(t, os.MkdirAll(bin, 0755))
+	for tool, script := range tools {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(bin, tool), []byte("#!/bin/sh\n"+script), 0755))
+	}
+	var path = os.Getenv("PATH")
+	assert.NoError(t, os.Setenv("PATH", bin+string(os.PathListSeparator)+path))
+	return func() {
+		assert.NoError(t, os.Setenv("PATH", path))
+	}
+}
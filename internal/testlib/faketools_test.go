@@ -0,0 +1,24 @@
+package testlib
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeTools(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var path = os.Getenv("PATH")
+	var back = FakeTools(t, folder, map[string]string{
+		"foo": "echo foo \"$@\"\n",
+	})
+	out, err := exec.Command("foo", "bar").CombinedOutput()
+	assert.NoError(t, err)
+	assert.Equal(t, "foo bar\n", string(out))
+	back()
+	assert.Equal(t, path, os.Getenv("PATH"))
+}
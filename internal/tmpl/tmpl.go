@@ -3,9 +3,13 @@ package tmpl
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 	"text/template"
 	"time"
+	"unicode"
+
+	"github.com/apex/log"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -13,10 +17,13 @@ import (
 
 // Template holds data that can be applied to a template string
 type Template struct {
-	fields fields
+	fields      Fields
+	funcs       template.FuncMap
+	strictNames bool
 }
 
-type fields map[string]interface{}
+// Fields that will be available to the template engine.
+type Fields map[string]interface{}
 
 const (
 	// general keys
@@ -33,6 +40,9 @@ const (
 	env         = "Env"
 	date        = "Date"
 	timestamp   = "Timestamp"
+	branch      = "Branch"
+	prerelease  = "Prerelease"
+	isSnapshot  = "IsSnapshot"
 
 	// artifact-only keys
 	os           = "Os"
@@ -47,7 +57,7 @@ const (
 // New Template
 func New(ctx *context.Context) *Template {
 	return &Template{
-		fields: fields{
+		fields: Fields{
 			projectName: ctx.Config.ProjectName,
 			version:     ctx.Version,
 			tag:         ctx.Git.CurrentTag,
@@ -61,8 +71,11 @@ func New(ctx *context.Context) *Template {
 			major:       ctx.Semver.Major,
 			minor:       ctx.Semver.Minor,
 			patch:       ctx.Semver.Patch,
-			// TODO: no reason not to add prerelease here too I guess
+			prerelease:  ctx.Semver.Prerelease,
+			branch:      ctx.Git.Branch,
+			isSnapshot:  ctx.Snapshot,
 		},
+		strictNames: ctx.Config.StrictNames,
 	}
 }
 
@@ -83,6 +96,21 @@ func (t *Template) WithEnv(e map[string]string) *Template {
 	return t
 }
 
+// WithExtraFields adds the given fields to the template, overriding the
+// ones with the same name
+func (t *Template) WithExtraFields(f Fields) *Template {
+	for k, v := range f {
+		t.fields[k] = v
+	}
+	return t
+}
+
+// WithFuncs adds the given functions to the ones available in the template
+func (t *Template) WithFuncs(funcs template.FuncMap) *Template {
+	t.funcs = funcs
+	return t
+}
+
 // WithArtifact populates fields from the artifact and replacements
 func (t *Template) WithArtifact(a *artifact.Artifact, replacements map[string]string) *Template {
 	var bin = a.Extra[binary]
@@ -112,10 +140,12 @@ func (t *Template) Apply(s string) (string, error) {
 			"time": func(s string) string {
 				return time.Now().UTC().Format(s)
 			},
-			"tolower": strings.ToLower,
-			"toupper": strings.ToUpper,
-			"trim":    strings.TrimSpace,
+			"tolower":  strings.ToLower,
+			"toupper":  strings.ToUpper,
+			"trim":     strings.TrimSpace,
+			"sanitize": Sanitize,
 		}).
+		Funcs(t.funcs).
 		Parse(s)
 	if err != nil {
 		return "", err
@@ -125,6 +155,37 @@ func (t *Template) Apply(s string) (string, error) {
 	return out.String(), err
 }
 
+// ApplyName applies the given name template and makes sure the result is
+// a valid file name on all platforms and in URLs, sanitizing it or, if
+// strict_names is set, failing.
+func (t *Template) ApplyName(s string) (string, error) {
+	name, err := t.Apply(s)
+	if err != nil {
+		return "", err
+	}
+	var sanitized = Sanitize(name)
+	if sanitized == name {
+		return name, nil
+	}
+	if t.strictNames {
+		return "", fmt.Errorf("%q is not a valid file name on all platforms", name)
+	}
+	log.WithField("name", name).WithField("sanitized", sanitized).Warn("sanitized invalid name")
+	return sanitized, nil
+}
+
+// Sanitize replaces the characters that are not valid in file names on
+// Windows or that are unsafe in URLs with an underscore, and removes the
+// trailing dots and spaces Windows can't handle.
+func Sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || unicode.IsSpace(r) || strings.ContainsRune(`<>:"/\|?*#%`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimRight(name, ". "))
+}
+
 func replace(replacements map[string]string, original string) string {
 	result := replacements[original]
 	if result == "" {
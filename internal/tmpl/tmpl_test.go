@@ -19,10 +19,12 @@ func TestWithArtifact(t *testing.T) {
 	ctx.Version = "1.2.3"
 	ctx.Git.CurrentTag = "v1.2.3"
 	ctx.Semver = context.Semver{
-		Major: 1,
-		Minor: 2,
-		Patch: 3,
+		Major:      1,
+		Minor:      2,
+		Patch:      3,
+		Prerelease: "beta",
 	}
+	ctx.Git.Branch = "master"
 	ctx.Git.Commit = "commit"
 	ctx.Git.FullCommit = "fullcommit"
 	ctx.Git.ShortCommit = "shortcommit"
@@ -40,6 +42,9 @@ func TestWithArtifact(t *testing.T) {
 		"binary":      "{{.Binary}}",
 		"proj":        "{{.ProjectName}}",
 		"":            "{{.ArtifactUploadHash}}",
+		"beta":        "{{.Prerelease}}",
+		"master":      "{{.Branch}}",
+		"false":       "{{.IsSnapshot}}",
 	} {
 		tmpl := tmpl
 		expect := expect
@@ -188,6 +193,11 @@ func TestFuncMap(t *testing.T) {
 			Name:     "trim",
 			Expected: "test",
 		},
+		{
+			Template: `{{ sanitize "my app: v1.0?" }}`,
+			Name:     "sanitize",
+			Expected: "my_app__v1.0_",
+		},
 	} {
 		out, err := New(ctx).Apply(tc.Template)
 		assert.NoError(t, err)
@@ -199,6 +209,43 @@ func TestFuncMap(t *testing.T) {
 	}
 }
 
+func TestApplyName(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "my app",
+	})
+	ctx.Git.CurrentTag = "v1.2.3"
+
+	t.Run("valid", func(t *testing.T) {
+		out, err := New(ctx).ApplyName("{{ .Tag }}")
+		assert.NoError(t, err)
+		assert.Equal(t, "v1.2.3", out)
+	})
+
+	t.Run("sanitized", func(t *testing.T) {
+		out, err := New(ctx).ApplyName("{{ .ProjectName }}|{{ .Tag }}.")
+		assert.NoError(t, err)
+		assert.Equal(t, "my_app_v1.2.3", out)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		ctx.Config.StrictNames = true
+		_, err := New(ctx).ApplyName("{{ .ProjectName }}_{{ .Tag }}")
+		assert.EqualError(t, err, `"my app_v1.2.3" is not a valid file name on all platforms`)
+	})
+}
+
+func TestSanitize(t *testing.T) {
+	for name, expected := range map[string]string{
+		"foo_1.0.0_linux_amd64":   "foo_1.0.0_linux_amd64",
+		"foo+meta_1.0.0":          "foo+meta_1.0.0",
+		`a<b>c:d"e/f\g|h?i*j#k%l`: "a_b_c_d_e_f_g_h_i_j_k_l",
+		"tab\there":               "tab_here",
+		"trailing. . ":            "trailing",
+	} {
+		assert.Equal(t, expected, Sanitize(name))
+	}
+}
+
 func TestInvalidTemplate(t *testing.T) {
 	ctx := context.New(config.Project{})
 	ctx.Git.CurrentTag = "v1.1.1"
@@ -213,3 +260,22 @@ func TestEnvNotFound(t *testing.T) {
 	assert.Empty(t, result)
 	assert.EqualError(t, err, `template: tmpl:1:6: executing "tmpl" at <.Env.FOO>: map has no entry for key "FOO"`)
 }
+
+func TestWithFuncs(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	result, err := New(ctx).WithFuncs(map[string]interface{}{
+		"double": func(s string) string { return s + s },
+	}).Apply(`{{ double "foo" }}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "foofoo", result)
+}
+
+func TestWithExtraFields(t *testing.T) {
+	var ctx = context.New(config.Project{ProjectName: "proj"})
+	out, err := New(ctx).WithExtraFields(Fields{
+		"Algorithm":   "sha512",
+		"ProjectName": "foo",
+	}).Apply("{{ .ProjectName }}_{{ .Algorithm }}")
+	assert.NoError(t, err)
+	assert.Equal(t, "foo_sha512", out)
+}
@@ -0,0 +1,293 @@
+// Package verify checks that the assets of a published release match their
+// checksums, signatures and provenance.
+package verify
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/pipe/provenance"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// Run downloads the assets of the release of the current tag to dir and
+// verifies them
+func Run(ctx *context.Context, dir string) error {
+	c, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	downloader, ok := c.(client.Downloader)
+	if !ok {
+		return fmt.Errorf("verify is not supported for %s releases", ctx.TokenType)
+	}
+	names, err := downloader.DownloadReleaseAssets(ctx, ctx.Git.CurrentTag, dir)
+	if err != nil {
+		return err
+	}
+	return Files(ctx, dir, names)
+}
+
+// Files verifies the given release assets, found in dir, against the
+// checksums, signatures and provenance released along with them
+func Files(ctx *context.Context, dir string, names []string) error {
+	var v = verifier{
+		ctx:    ctx,
+		dir:    dir,
+		assets: map[string]bool{},
+	}
+	for _, name := range names {
+		v.assets[name] = true
+	}
+	for _, check := range []func() error{
+		v.checksums,
+		v.signatures,
+		v.provenance,
+	} {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	if v.failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", v.failed, v.checked)
+	}
+	if v.checked == 0 {
+		return fmt.Errorf("nothing to verify in the release of %s", ctx.Git.CurrentTag)
+	}
+	log.WithField("checks", v.checked).WithField("skipped", v.skipped).Info("all checks passed")
+	return nil
+}
+
+type verifier struct {
+	ctx     *context.Context
+	dir     string
+	assets  map[string]bool
+	checked int
+	failed  int
+	skipped int
+}
+
+// skip is the error of the checks that can't be done, which are reported
+// but don't fail the verification
+type skip string
+
+func (s skip) Error() string {
+	return string(s)
+}
+
+// check records the result of a check, logging the failures so all of them
+// are reported at once
+func (v *verifier) check(subject, kind string, err error) {
+	var entry = log.WithField("file", subject).WithField("check", kind)
+	if reason, ok := err.(skip); ok {
+		v.skipped++
+		entry.WithField("reason", string(reason)).Warn("skipped")
+		return
+	}
+	v.checked++
+	if err != nil {
+		v.failed++
+		entry.WithError(err).Error("failed")
+		return
+	}
+	entry.Info("ok")
+}
+
+func (v *verifier) path(name string) string {
+	return filepath.Join(v.dir, name)
+}
+
+func (v *verifier) checksums() error {
+	var cfg = v.ctx.Config.Checksum
+	var algorithms = cfg.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []string{cfg.Algorithm}
+	}
+	for _, algorithm := range algorithms {
+		if cfg.Split {
+			for name := range v.assets {
+				if v.assets[name+"."+algorithm] {
+					v.checksumFile(name+"."+algorithm, algorithm)
+				}
+			}
+			continue
+		}
+		name, err := tmpl.New(v.ctx).
+			WithExtraFields(tmpl.Fields{"Algorithm": algorithm}).
+			ApplyName(cfg.NameTemplate)
+		if err != nil {
+			return err
+		}
+		if !v.assets[name] {
+			v.check(name, "checksum", errors.New("checksum file not found in the release"))
+			continue
+		}
+		v.checksumFile(name, algorithm)
+	}
+	return nil
+}
+
+// checksumFile checks every file listed in the given checksum file
+func (v *verifier) checksumFile(name, algorithm string) {
+	file, err := os.Open(v.path(name))
+	if err != nil {
+		v.check(name, "checksum", err)
+		return
+	}
+	defer file.Close() // nolint: errcheck
+	var scanner = bufio.NewScanner(file)
+	for scanner.Scan() {
+		var fields = strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		var expected, listed = fields[0], fields[1]
+		if !v.assets[listed] {
+			v.check(listed, algorithm, fmt.Errorf("listed in %s but not found in the release", name))
+			continue
+		}
+		var a = artifact.Artifact{Path: v.path(listed)}
+		sum, err := a.Checksum(algorithm)
+		if err == nil && sum != expected {
+			err = fmt.Errorf("%s is %s, but %s lists %s", algorithm, sum, name, expected)
+		}
+		v.check(listed, algorithm, err)
+	}
+	if err := scanner.Err(); err != nil {
+		v.check(name, "checksum", err)
+	}
+}
+
+func (v *verifier) signatures() error {
+	for _, cfg := range v.ctx.Config.Signs {
+		if cfg.Artifacts == "none" || cfg.Artifacts == "" {
+			continue
+		}
+		for name := range v.assets {
+			var signature = expand(cfg.Signature, map[string]string{"artifact": name})
+			if signature == name || !v.assets[signature] {
+				continue
+			}
+			var certificate string
+			if cfg.Certificate != "" {
+				certificate = expand(cfg.Certificate, map[string]string{"artifact": name})
+			}
+			v.check(name, "signature", v.signature(cfg, name, signature, certificate))
+		}
+	}
+	return nil
+}
+
+// signature verifies the signature of an asset with the tool that created
+// it, with the public key of the signs config, its certificate, or the gpg
+// keyring
+func (v *verifier) signature(cfg config.Sign, name, signature, certificate string) error {
+	publicKey, err := tmpl.New(v.ctx).Apply(cfg.PublicKey)
+	if err != nil {
+		return err
+	}
+	var args []string
+	var env []string
+	switch cfg.Cmd {
+	case "gpg", "gpg2":
+		args = []string{"--verify", v.path(signature), v.path(name)}
+	case "minisign", "signify":
+		if publicKey == "" {
+			return skip(cfg.Cmd + " signatures can only be verified with the public_key of their signs config")
+		}
+		args = []string{"-V", "-p", publicKey, "-m", v.path(name), "-x", v.path(signature)}
+	case "cosign":
+		switch {
+		case publicKey != "":
+			args = []string{
+				"verify-blob",
+				"--key=" + publicKey,
+				"--signature=" + v.path(signature),
+				v.path(name),
+			}
+		case certificate != "" && v.assets[certificate]:
+			args = []string{
+				"verify-blob",
+				"--certificate=" + v.path(certificate),
+				"--signature=" + v.path(signature),
+				v.path(name),
+			}
+			env = append(env, "COSIGN_EXPERIMENTAL=1")
+		default:
+			return skip("cosign signatures can only be verified with their certificate or the public_key of their signs config")
+		}
+	default:
+		return skip(cfg.Cmd + " signatures can't be verified")
+	}
+	/* #nosec */
+	var cmd = exec.CommandContext(v.ctx, cfg.Cmd, args...)
+	cmd.Env = append(os.Environ(), env...)
+	log.WithField("cmd", cmd.Args).Debug("running")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed with %q", cfg.Cmd, string(out))
+	}
+	return nil
+}
+
+func (v *verifier) provenance() error {
+	var cfg = v.ctx.Config.Provenance
+	if !cfg.Enabled {
+		return nil
+	}
+	name, err := tmpl.New(v.ctx).Apply(cfg.NameTemplate)
+	if err != nil {
+		return err
+	}
+	if !v.assets[name] {
+		v.check(name, "provenance", errors.New("provenance not found in the release"))
+		return nil
+	}
+	bts, err := ioutil.ReadFile(v.path(name))
+	if err != nil {
+		return err
+	}
+	var statement provenance.Statement
+	if err := json.Unmarshal(bts, &statement); err != nil {
+		v.check(name, "provenance", errors.Wrap(err, "invalid provenance"))
+		return nil
+	}
+	// the commit of the tag is only known when verifying from a clone
+	if commit := statement.Predicate.Invocation.ConfigSource.Digest["sha1"]; v.ctx.Git.FullCommit != "" {
+		var err error
+		if commit != v.ctx.Git.FullCommit {
+			err = fmt.Errorf("built from %s, but %s is %s", commit, v.ctx.Git.CurrentTag, v.ctx.Git.FullCommit)
+		}
+		v.check(name, "source", err)
+	}
+	for _, subject := range statement.Subject {
+		if !v.assets[subject.Name] {
+			v.check(subject.Name, "provenance", fmt.Errorf("attested in %s but not found in the release", name))
+			continue
+		}
+		var a = artifact.Artifact{Path: v.path(subject.Name)}
+		sum, err := a.Checksum("sha256")
+		if err == nil && sum != subject.Digest["sha256"] {
+			err = fmt.Errorf("sha256 is %s, but %s attests %s", sum, name, subject.Digest["sha256"])
+		}
+		v.check(subject.Name, "provenance", err)
+	}
+	return nil
+}
+
+func expand(s string, env map[string]string) string {
+	return os.Expand(s, func(key string) string {
+		return env[key]
+	})
+}
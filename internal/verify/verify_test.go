@@ -0,0 +1,186 @@
+package verify
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// sha256 of foo and bar
+const (
+	fooSHA256 = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	barSHA256 = "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+)
+
+func newContext(cfg config.Project) *context.Context {
+	cfg.ProjectName = "foo"
+	cfg.Checksum = config.Checksum{
+		NameTemplate: "checksums.txt",
+		Algorithm:    "sha256",
+		Split:        cfg.Checksum.Split,
+	}
+	var ctx = context.New(cfg)
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Version = "1.0.0"
+	return ctx
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) []string {
+	var names []string
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestChecksums(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var names = writeFiles(t, folder, map[string]string{
+		"foo.tar.gz":    "foo",
+		"bar.tar.gz":    "bar",
+		"checksums.txt": fooSHA256 + "  foo.tar.gz\n" + barSHA256 + "  bar.tar.gz\n",
+	})
+	require.NoError(t, Files(newContext(config.Project{}), folder, names))
+}
+
+func TestChecksumsSplit(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var names = writeFiles(t, folder, map[string]string{
+		"foo.tar.gz":        "foo",
+		"foo.tar.gz.sha256": fooSHA256 + "  foo.tar.gz\n",
+	})
+	require.NoError(t, Files(newContext(config.Project{
+		Checksum: config.Checksum{Split: true},
+	}), folder, names))
+}
+
+func TestChecksumsMismatch(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var names = writeFiles(t, folder, map[string]string{
+		"foo.tar.gz":    "tampered",
+		"checksums.txt": fooSHA256 + "  foo.tar.gz\n" + barSHA256 + "  bar.tar.gz\n",
+	})
+	require.EqualError(t, Files(newContext(config.Project{}), folder, names), "2 of 2 checks failed")
+}
+
+func TestChecksumsNotReleased(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var names = writeFiles(t, folder, map[string]string{
+		"foo.tar.gz": "foo",
+	})
+	require.EqualError(t, Files(newContext(config.Project{}), folder, names), "1 of 1 checks failed")
+}
+
+func TestNothingToVerify(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.EqualError(t, Files(newContext(config.Project{
+		Checksum: config.Checksum{Split: true},
+	}), folder, nil), "nothing to verify in the release of v1.0.0")
+}
+
+func TestSignatures(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+
+	// fake gpg that accepts the signatures that contain "good"
+	defer testlib.FakeTools(t, folder, map[string]string{
+		"gpg": "grep -q good \"$2\" || { echo BAD signature; exit 1; }\n",
+	})()
+
+	var ctx = newContext(config.Project{
+		Signs: []config.Sign{
+			{Cmd: "gpg", Signature: "${artifact}.sig", Artifacts: "checksum"},
+			{Cmd: "minisign", Signature: "${artifact}.minisig", Artifacts: "none"},
+		},
+	})
+	var files = map[string]string{
+		"foo.tar.gz":            "foo",
+		"checksums.txt":         fooSHA256 + "  foo.tar.gz\n",
+		"checksums.txt.sig":     "good",
+		"checksums.txt.minisig": "not verified",
+	}
+	require.NoError(t, Files(ctx, folder, writeFiles(t, folder, files)))
+
+	files["checksums.txt.sig"] = "bad"
+	require.EqualError(t, Files(ctx, folder, writeFiles(t, folder, files)), "1 of 2 checks failed")
+}
+
+func TestSignaturesPublicKey(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+
+	// fake minisign that accepts the signatures that contain the public key
+	defer testlib.FakeTools(t, folder, map[string]string{
+		"minisign": "[ \"$1 $2 $4\" = \"-V -p -m\" ] && grep -q \"$3\" \"$7\" || { echo BAD signature; exit 1; }\n",
+	})()
+
+	var ctx = newContext(config.Project{
+		Signs: []config.Sign{
+			{Cmd: "minisign", Signature: "${artifact}.minisig", PublicKey: "{{ .ProjectName }}.pub", Artifacts: "checksum"},
+		},
+	})
+	var files = map[string]string{
+		"foo.tar.gz":            "foo",
+		"checksums.txt":         fooSHA256 + "  foo.tar.gz\n",
+		"checksums.txt.minisig": "signed by foo.pub",
+	}
+	require.NoError(t, Files(ctx, folder, writeFiles(t, folder, files)))
+
+	files["checksums.txt.minisig"] = "signed by bar.pub"
+	require.EqualError(t, Files(ctx, folder, writeFiles(t, folder, files)), "1 of 2 checks failed")
+}
+
+func TestSignaturesSkipped(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = newContext(config.Project{
+		Signs: []config.Sign{
+			{Cmd: "cosign", Signature: "${artifact}.sig", Certificate: "${artifact}.pem", Artifacts: "checksum"},
+			{Cmd: "signify", Signature: "${artifact}.signify", Artifacts: "checksum"},
+		},
+	})
+	var names = writeFiles(t, folder, map[string]string{
+		"foo.tar.gz":            "foo",
+		"checksums.txt":         fooSHA256 + "  foo.tar.gz\n",
+		"checksums.txt.sig":     "sig",
+		"checksums.txt.signify": "sig",
+	})
+	require.NoError(t, Files(ctx, folder, names))
+}
+
+func TestProvenance(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = newContext(config.Project{
+		Checksum: config.Checksum{Split: true},
+		Provenance: config.Provenance{
+			Enabled:      true,
+			NameTemplate: "{{ .ProjectName }}.intoto.jsonl",
+		},
+	})
+	ctx.Git.FullCommit = "a1b2c3"
+	var provenance = `{"subject": [` +
+		`{"name": "foo.tar.gz", "digest": {"sha256": "` + fooSHA256 + `"}},` +
+		`{"name": "bar.tar.gz", "digest": {"sha256": "` + fooSHA256 + `"}}` +
+		`], "predicate": {"invocation": {"configSource": {"digest": {"sha1": "a1b2c3"}}}}}`
+	var names = writeFiles(t, folder, map[string]string{
+		"foo.tar.gz":       "foo",
+		"bar.tar.gz":       "bar",
+		"foo.intoto.jsonl": provenance,
+	})
+	require.EqualError(t, Files(ctx, folder, names), "1 of 3 checks failed")
+
+	ctx.Git.FullCommit = "d4e5f6"
+	require.EqualError(t, Files(ctx, folder, names), "2 of 3 checks failed")
+}
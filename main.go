@@ -4,15 +4,25 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/apex/log"
-	"github.com/apex/log/handlers/cli"
 	"github.com/caarlos0/ctrlc"
 	"github.com/fatih/color"
+	gitcmd "github.com/goreleaser/goreleaser/internal/git"
 	"github.com/goreleaser/goreleaser/internal/middleware"
+	"github.com/goreleaser/goreleaser/internal/pipe/changelog"
+	defaultspipe "github.com/goreleaser/goreleaser/internal/pipe/defaults"
+	"github.com/goreleaser/goreleaser/internal/pipe/env"
+	"github.com/goreleaser/goreleaser/internal/pipe/git"
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/internal/pipe/release"
+	"github.com/goreleaser/goreleaser/internal/pipe/semver"
 	"github.com/goreleaser/goreleaser/internal/pipeline"
+	"github.com/goreleaser/goreleaser/internal/rollback"
 	"github.com/goreleaser/goreleaser/internal/static"
+	"github.com/goreleaser/goreleaser/internal/verify"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/goreleaser/goreleaser/pkg/defaults"
@@ -27,16 +37,38 @@ var (
 	builtBy = ""
 )
 
+type changelogOptions struct {
+	Config  string
+	Preview bool
+}
+
+type rollbackOptions struct {
+	Config   string
+	Tag      string
+	Metadata string
+	Timeout  time.Duration
+}
+
+type verifyOptions struct {
+	Config  string
+	Tag     string
+	Dir     string
+	Timeout time.Duration
+}
+
 type releaseOptions struct {
-	Config       string
-	ReleaseNotes string
-	Snapshot     bool
-	SkipPublish  bool
-	SkipSign     bool
-	SkipValidate bool
-	RmDist       bool
-	Parallelism  int
-	Timeout      time.Duration
+	Config          string
+	ReleaseNotes    string
+	ReleaseHeader   string
+	ReleaseFooter   string
+	Snapshot        bool
+	SkipPublish     bool
+	SkipSign        bool
+	SkipValidate    bool
+	RmDist          bool
+	Parallelism     int
+	PipeParallelism int
+	Timeout         time.Duration
 }
 
 func main() {
@@ -44,7 +76,7 @@ func main() {
 	if os.Getenv("CI") != "" {
 		color.NoColor = false
 	}
-	log.SetHandler(cli.Default)
+	log.SetHandler(middleware.Handler)
 
 	fmt.Println()
 	defer fmt.Println()
@@ -56,13 +88,26 @@ func main() {
 	var checkCmd = app.Command("check", "Checks if configuration is valid").Alias("c")
 	var releaseCmd = app.Command("release", "Releases the current project").Alias("r").Default()
 	var releaseNotes = releaseCmd.Flag("release-notes", "Load custom release notes from a markdown file").PlaceHolder("notes.md").String()
+	var releaseHeader = releaseCmd.Flag("release-header", "Load the header of the generated release notes from a markdown file").PlaceHolder("header.md").String()
+	var releaseFooter = releaseCmd.Flag("release-footer", "Load the footer of the generated release notes from a markdown file").PlaceHolder("footer.md").String()
 	var snapshot = releaseCmd.Flag("snapshot", "Generate an unversioned snapshot release, skipping all validations and without publishing any artifacts").Bool()
 	var skipPublish = releaseCmd.Flag("skip-publish", "Skips publishing artifacts").Bool()
 	var skipSign = releaseCmd.Flag("skip-sign", "Skips signing the artifacts").Bool()
 	var skipValidate = releaseCmd.Flag("skip-validate", "Skips several sanity checks").Bool()
 	var rmDist = releaseCmd.Flag("rm-dist", "Remove the dist folder before building").Bool()
 	var parallelism = releaseCmd.Flag("parallelism", "Amount tasks to run concurrently").Short('p').Default("4").Int()
+	var pipeParallelism = releaseCmd.Flag("pipe-parallelism", "Amount of independent pipes to run concurrently, interleaving their logs").Default("1").Int()
 	var timeout = releaseCmd.Flag("timeout", "Timeout to the entire release process").Default("30m").Duration()
+	var changelogCmd = app.Command("changelog", "Prints the changelog of the current tag, without building anything")
+	var changelogPreview = changelogCmd.Flag("preview", "Print the full release notes, exactly as they will be published").Bool()
+	var rollbackCmd = app.Command("rollback", "Rolls back a previously published release")
+	var rollbackTag = rollbackCmd.Arg("tag", "Tag of the release to rollback").Required().String()
+	var rollbackMetadata = rollbackCmd.Flag("metadata", "Load the release metadata from file").Default("dist/metadata.json").String()
+	var rollbackTimeout = rollbackCmd.Flag("timeout", "Timeout to the entire rollback process").Default("10m").Duration()
+	var verifyCmd = app.Command("verify", "Verifies the checksums, signatures and provenance of a published release")
+	var verifyTag = verifyCmd.Arg("tag", "Tag of the release to verify").Required().String()
+	var verifyDir = verifyCmd.Flag("dir", "Download the release assets to this folder instead of a temporary one").String()
+	var verifyTimeout = verifyCmd.Flag("timeout", "Timeout to the entire verification process").Default("10m").Duration()
 
 	app.Version(buildVersion(version, commit, date, builtBy))
 	app.VersionFlag.Short('v')
@@ -96,15 +141,18 @@ func main() {
 		start := time.Now()
 		log.Infof(color.New(color.Bold).Sprintf("releasing using goreleaser %s...", version))
 		var options = releaseOptions{
-			Config:       *config,
-			ReleaseNotes: *releaseNotes,
-			Snapshot:     *snapshot,
-			SkipPublish:  *skipPublish,
-			SkipValidate: *skipValidate,
-			SkipSign:     *skipSign,
-			RmDist:       *rmDist,
-			Parallelism:  *parallelism,
-			Timeout:      *timeout,
+			Config:          *config,
+			ReleaseNotes:    *releaseNotes,
+			ReleaseHeader:   *releaseHeader,
+			ReleaseFooter:   *releaseFooter,
+			Snapshot:        *snapshot,
+			SkipPublish:     *skipPublish,
+			SkipValidate:    *skipValidate,
+			SkipSign:        *skipSign,
+			RmDist:          *rmDist,
+			Parallelism:     *parallelism,
+			PipeParallelism: *pipeParallelism,
+			Timeout:         *timeout,
 		}
 		if err := releaseProject(options); err != nil {
 			log.WithError(err).Errorf(color.New(color.Bold).Sprintf("release failed after %0.2fs", time.Since(start).Seconds()))
@@ -112,6 +160,44 @@ func main() {
 			return
 		}
 		log.Infof(color.New(color.Bold).Sprintf("release succeeded after %0.2fs", time.Since(start).Seconds()))
+	case changelogCmd.FullCommand():
+		var options = changelogOptions{
+			Config:  *config,
+			Preview: *changelogPreview,
+		}
+		notes, err := changelogProject(options)
+		if err != nil {
+			log.WithError(err).Errorf(color.New(color.Bold).Sprintf("failed to generate changelog"))
+			os.Exit(1)
+			return
+		}
+		fmt.Print(notes)
+	case rollbackCmd.FullCommand():
+		var options = rollbackOptions{
+			Config:   *config,
+			Tag:      *rollbackTag,
+			Metadata: *rollbackMetadata,
+			Timeout:  *rollbackTimeout,
+		}
+		if err := rollbackProject(options); err != nil {
+			log.WithError(err).Errorf(color.New(color.Bold).Sprintf("rollback failed"))
+			os.Exit(1)
+			return
+		}
+		log.Infof(color.New(color.Bold).Sprintf("rollback succeeded"))
+	case verifyCmd.FullCommand():
+		var options = verifyOptions{
+			Config:  *config,
+			Tag:     *verifyTag,
+			Dir:     *verifyDir,
+			Timeout: *verifyTimeout,
+		}
+		if err := verifyProject(options); err != nil {
+			log.WithError(err).Errorf(color.New(color.Bold).Sprintf("verification failed"))
+			os.Exit(1)
+			return
+		}
+		log.Infof(color.New(color.Bold).Sprintf("release %s verified", *verifyTag))
 	}
 }
 
@@ -139,24 +225,132 @@ func releaseProject(options releaseOptions) error {
 	ctx, cancel := context.NewWithTimeout(cfg, options.Timeout)
 	defer cancel()
 	ctx.Parallelism = options.Parallelism
+	ctx.PipeParallelism = options.PipeParallelism
 	log.Debugf("parallelism: %v", ctx.Parallelism)
+	log.Debugf("pipe parallelism: %v", ctx.PipeParallelism)
 	ctx.ReleaseNotes = options.ReleaseNotes
+	ctx.ReleaseHeaderFile = options.ReleaseHeader
+	ctx.ReleaseFooterFile = options.ReleaseFooter
 	ctx.Snapshot = options.Snapshot
 	ctx.SkipPublish = ctx.Snapshot || options.SkipPublish
 	ctx.SkipValidate = ctx.Snapshot || options.SkipValidate
 	ctx.SkipSign = options.SkipSign
 	ctx.RmDist = options.RmDist
 	return ctrlc.Default.Run(ctx, func() error {
-		for _, pipe := range pipeline.Pipeline {
-			if err := middleware.Logging(
+		return pipeline.Run(ctx, func(pipe pipeline.Piper) error {
+			return middleware.Logging(
 				pipe.String(),
 				middleware.ErrHandler(pipe.Run),
 				middleware.DefaultInitialPadding,
-			)(ctx); err != nil {
+			)(ctx)
+		})
+	})
+}
+
+func changelogProject(options changelogOptions) (string, error) {
+	cfg, err := loadConfig(options.Config)
+	if err != nil {
+		return "", err
+	}
+	var ctx = context.New(cfg)
+	ctx.SkipPublish = true
+	ctx.SkipValidate = true
+	// previewing should never create tags
+	ctx.Config.Git.TagAndPush.Enabled = false
+	for _, pipe := range []pipeline.Piper{
+		git.Pipe{},
+		semver.Pipe{},
+		defaultspipe.Pipe{},
+	} {
+		if err := middleware.ErrHandler(pipe.Run)(ctx); err != nil {
+			return "", err
+		}
+	}
+	if !ctx.Config.Changelog.Skip {
+		notes, err := changelog.Notes(ctx)
+		if err != nil {
+			return "", err
+		}
+		ctx.ReleaseNotes = notes
+	}
+	if !options.Preview {
+		return ctx.ReleaseNotes, nil
+	}
+	notes, err := changelog.WithHeaderAndFooter(ctx, ctx.ReleaseNotes)
+	if err != nil {
+		return "", err
+	}
+	ctx.ReleaseNotes = notes
+	return release.Body(ctx)
+}
+
+func rollbackProject(options rollbackOptions) error {
+	meta, err := metadata.Load(options.Metadata)
+	if err != nil {
+		return err
+	}
+	if meta.Tag != options.Tag {
+		return fmt.Errorf("%s describes release %s, not %s", options.Metadata, meta.Tag, options.Tag)
+	}
+	cfg, err := loadConfig(options.Config)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.NewWithTimeout(cfg, options.Timeout)
+	defer cancel()
+	ctx.Git.CurrentTag = meta.Tag
+	return ctrlc.Default.Run(ctx, func() error {
+		for _, pipe := range defaults.Defaulters {
+			if err := middleware.ErrHandler(pipe.Default)(ctx); err != nil {
 				return err
 			}
 		}
-		return nil
+		if err := (env.Pipe{}).Run(ctx); err != nil {
+			return err
+		}
+		return rollback.Run(ctx, meta)
+	})
+}
+
+func verifyProject(options verifyOptions) error {
+	cfg, err := loadConfig(options.Config)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.NewWithTimeout(cfg, options.Timeout)
+	defer cancel()
+	ctx.Git.CurrentTag = options.Tag
+	ctx.Version = strings.TrimPrefix(options.Tag, "v")
+	ctx.SkipValidate = true
+	// the tag's commit is only known from a clone, and checked against the
+	// provenance if so
+	if commit, err := gitcmd.Clean(gitcmd.Run("rev-list", "-n1", options.Tag)); err == nil {
+		ctx.Git.FullCommit = commit
+	}
+	var dir = options.Dir
+	if dir == "" {
+		if dir, err = ioutil.TempDir("", "goreleaser-verify"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir) // nolint: errcheck
+	} else if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return ctrlc.Default.Run(ctx, func() error {
+		for _, pipe := range defaults.Defaulters {
+			if err := middleware.ErrHandler(pipe.Default)(ctx); err != nil {
+				return err
+			}
+		}
+		for _, pipe := range []pipeline.Piper{
+			semver.Pipe{},
+			env.Pipe{},
+		} {
+			if err := middleware.ErrHandler(pipe.Run)(ctx); err != nil {
+				return err
+			}
+		}
+		return verify.Run(ctx, dir)
 	})
 }
 
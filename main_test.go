@@ -31,6 +31,28 @@ func TestCheckConfig(t *testing.T) {
 	assert.NoError(t, checkConfig(testParams().Config))
 }
 
+func TestRollbackProjectMissingMetadata(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	assert.Error(t, rollbackProject(rollbackOptions{
+		Tag:      "v1.0.0",
+		Metadata: "dist/nope.json",
+		Timeout:  time.Minute,
+	}))
+}
+
+func TestRollbackProjectWrongTag(t *testing.T) {
+	folder, back := setup(t)
+	defer back()
+	var path = filepath.Join(folder, "metadata.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"tag": "v0.1.0"}`), 0644))
+	assert.EqualError(t, rollbackProject(rollbackOptions{
+		Tag:      "v1.0.0",
+		Metadata: path,
+		Timeout:  time.Minute,
+	}), path+" describes release v0.1.0, not v1.0.0")
+}
+
 func TestCheckConfigFails(t *testing.T) {
 	_, back := setup(t)
 	defer back()
@@ -243,3 +265,24 @@ func TestVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestChangelogProject(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	notes, err := changelogProject(changelogOptions{})
+	require.NoError(t, err)
+	require.Contains(t, notes, "## Changelog")
+	require.Contains(t, notes, "assd")
+	require.NotContains(t, notes, "asdf")
+	_, err = os.Stat("dist")
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestChangelogProjectPreview(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	notes, err := changelogProject(changelogOptions{Preview: true})
+	require.NoError(t, err)
+	require.Contains(t, notes, "## Changelog")
+	require.Contains(t, notes, "asas89d")
+}
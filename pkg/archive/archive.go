@@ -1,12 +1,18 @@
-// Package archive provides tar.gz and zip archiving
+// Package archive provides tar.gz, tar.xz, tar.zst, zip and 7z archiving
 package archive
 
 import (
+	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/goreleaser/goreleaser/pkg/archive/gzip"
+	"github.com/goreleaser/goreleaser/pkg/archive/sevenzip"
 	"github.com/goreleaser/goreleaser/pkg/archive/targz"
+	"github.com/goreleaser/goreleaser/pkg/archive/tarxz"
+	"github.com/goreleaser/goreleaser/pkg/archive/tarzst"
 	"github.com/goreleaser/goreleaser/pkg/archive/zip"
 )
 
@@ -16,16 +22,155 @@ type Archive interface {
 	Add(name, path string) error
 }
 
+// Extended is an archive that can also add files with custom permissions
+// and store symlinks as symlinks.
+type Extended interface {
+	Archive
+	AddWithMode(name, path string, mode os.FileMode) error
+	AddSymlink(name, path string) error
+}
+
+// AddWithMode adds a file to the archive with the given permissions, or
+// with the permissions of the file if mode is zero.
+func AddWithMode(a Archive, name, path string, mode os.FileMode) error {
+	if mode == 0 {
+		return a.Add(name, path)
+	}
+	if e, ok := a.(Extended); ok {
+		return e.AddWithMode(name, path, mode)
+	}
+	return fmt.Errorf("%s: archive format doesn't support file modes", name)
+}
+
+// AddSymlink adds the symlink at the given path to the archive as a symlink.
+func AddSymlink(a Archive, name, path string) error {
+	if e, ok := a.(Extended); ok {
+		return e.AddSymlink(name, path)
+	}
+	return fmt.Errorf("%s: archive format doesn't support symlinks", name)
+}
+
 // New archive.
 func New(file *os.File) Archive {
 	if strings.HasSuffix(file.Name(), ".tar.gz") {
 		return targz.New(file)
 	}
+	if strings.HasSuffix(file.Name(), ".tar.xz") {
+		return tarxz.New(file)
+	}
+	if strings.HasSuffix(file.Name(), ".tar.zst") {
+		return tarzst.New(file)
+	}
 	if strings.HasSuffix(file.Name(), ".gz") {
 		return gzip.New(file)
 	}
 	if strings.HasSuffix(file.Name(), ".zip") {
 		return zip.New(file)
 	}
+	if strings.HasSuffix(file.Name(), ".7z") {
+		return sevenzip.New(file)
+	}
 	return targz.New(file)
 }
+
+// NewWithLevel creates a new archive with the given compression level.
+// Zero means the default level of the format.
+func NewWithLevel(file *os.File, level int) (Archive, error) {
+	if strings.HasSuffix(file.Name(), ".tar.gz") {
+		return targz.NewWithLevel(file, level)
+	}
+	if strings.HasSuffix(file.Name(), ".tar.xz") {
+		return tarxz.NewWithLevel(file, level)
+	}
+	if strings.HasSuffix(file.Name(), ".tar.zst") {
+		return tarzst.NewWithLevel(file, level)
+	}
+	if strings.HasSuffix(file.Name(), ".gz") {
+		return gzip.NewWithLevel(file, level)
+	}
+	if strings.HasSuffix(file.Name(), ".zip") {
+		return zip.NewWithLevel(file, level)
+	}
+	if strings.HasSuffix(file.Name(), ".7z") {
+		a, err := sevenzip.NewWithLevel(file, level)
+		if err != nil {
+			return nil, err
+		}
+		return a, nil
+	}
+	return targz.NewWithLevel(file, level)
+}
+
+// NewReproducible creates a new archive with the given compression level that
+// has the same content every time it is created from the same files: entries
+// are sorted by name, and have the given modification time and no owner.
+func NewReproducible(file *os.File, level int, mtime time.Time) (Archive, error) {
+	var a Archive
+	var err error
+	switch {
+	case strings.HasSuffix(file.Name(), ".tar.gz"):
+		a, err = targz.NewReproducible(file, level, mtime)
+	case strings.HasSuffix(file.Name(), ".tar.xz"):
+		a, err = tarxz.NewReproducible(file, level, mtime)
+	case strings.HasSuffix(file.Name(), ".tar.zst"):
+		a, err = tarzst.NewReproducible(file, level, mtime)
+	case strings.HasSuffix(file.Name(), ".gz"):
+		a, err = gzip.NewReproducible(file, level, mtime)
+	case strings.HasSuffix(file.Name(), ".zip"):
+		a, err = zip.NewReproducible(file, level, mtime)
+	case strings.HasSuffix(file.Name(), ".7z"):
+		err = fmt.Errorf("7z archives can't be reproducible")
+	default:
+		a, err = targz.NewReproducible(file, level, mtime)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sorted{archive: a}, nil
+}
+
+// sorted holds the files added to the archive until it is closed, and then
+// adds them sorted by name.
+type sorted struct {
+	archive Archive
+	entries []entry
+}
+
+type entry struct {
+	name, path string
+	mode       os.FileMode
+	symlink    bool
+}
+
+func (s *sorted) Add(name, path string) error {
+	return s.AddWithMode(name, path, 0)
+}
+
+func (s *sorted) AddWithMode(name, path string, mode os.FileMode) error {
+	s.entries = append(s.entries, entry{name: name, path: path, mode: mode})
+	return nil
+}
+
+func (s *sorted) AddSymlink(name, path string) error {
+	s.entries = append(s.entries, entry{name: name, path: path, symlink: true})
+	return nil
+}
+
+func (s *sorted) Close() error {
+	sort.SliceStable(s.entries, func(i, j int) bool {
+		return s.entries[i].name < s.entries[j].name
+	})
+	for _, e := range s.entries {
+		var err error
+		if e.symlink {
+			err = AddSymlink(s.archive, e.name, e.path)
+		} else {
+			err = AddWithMode(s.archive, e.name, e.path, e.mode)
+		}
+		if err != nil {
+			_ = s.archive.Close()
+			return err
+		}
+	}
+	return s.archive.Close()
+}
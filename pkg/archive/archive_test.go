@@ -1,9 +1,12 @@
 package archive
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -16,7 +19,7 @@ func TestArchive(t *testing.T) {
 	assert.NoError(err)
 	assert.NoError(os.Mkdir(folder+"/folder-inside", 0755))
 
-	for _, format := range []string{"tar.gz", "zip", "gz", "willbeatargzanyway"} {
+	for _, format := range []string{"tar.gz", "tar.xz", "tar.zst", "zip", "gz", "willbeatargzanyway"} {
 		format := format
 		t.Run(format, func(t *testing.T) {
 			var archive = newArchive(folder, format, t)
@@ -32,3 +35,120 @@ func newArchive(folder, format string, t *testing.T) Archive {
 	assert.NoError(t, err)
 	return New(file)
 }
+
+func TestArchiveWithLevel(t *testing.T) {
+	folder, err := ioutil.TempDir("", "archivetest")
+	assert.NoError(t, err)
+	empty, err := os.Create(folder + "/empty.txt")
+	assert.NoError(t, err)
+
+	for format, levels := range map[string][]int{
+		"tar.gz":  {0, 1, 9},
+		"tar.xz":  {0, 1, 9},
+		"tar.zst": {0, 1, 22},
+		"gz":      {0, 1, 9},
+		"zip":     {0, 1, 9},
+	} {
+		for _, level := range levels {
+			file, err := os.Create(fmt.Sprintf("%s/folder-%d.%s", folder, level, format))
+			assert.NoError(t, err)
+			archive, err := NewWithLevel(file, level)
+			assert.NoError(t, err, format)
+			assert.NoError(t, archive.Add("empty.txt", empty.Name()))
+			assert.NoError(t, archive.Close())
+		}
+	}
+}
+
+func TestArchiveWithInvalidLevel(t *testing.T) {
+	folder, err := ioutil.TempDir("", "archivetest")
+	assert.NoError(t, err)
+	for format, level := range map[string]int{
+		"tar.gz":  10,
+		"tar.xz":  10,
+		"tar.zst": 23,
+		"gz":      -3,
+		"zip":     42,
+	} {
+		file, err := os.Create(folder + "/folder." + format)
+		assert.NoError(t, err)
+		_, err = NewWithLevel(file, level)
+		assert.Error(t, err, format)
+	}
+}
+
+func TestArchiveReproducible(t *testing.T) {
+	folder, err := ioutil.TempDir("", "archivetest")
+	assert.NoError(t, err)
+	var a = filepath.Join(folder, "a.txt")
+	var b = filepath.Join(folder, "b.txt")
+	assert.NoError(t, ioutil.WriteFile(a, []byte("a"), 0644))
+	assert.NoError(t, ioutil.WriteFile(b, []byte("b"), 0644))
+	var mtime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var create = func(format string, names ...string) []byte {
+		var path = filepath.Join(folder, "folder."+format)
+		file, err := os.Create(path)
+		assert.NoError(t, err)
+		archive, err := NewReproducible(file, 0, mtime)
+		assert.NoError(t, err)
+		for _, name := range names {
+			assert.NoError(t, archive.Add(name, filepath.Join(folder, name)))
+		}
+		assert.NoError(t, archive.Close())
+		assert.NoError(t, file.Close())
+		bts, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		return bts
+	}
+
+	for _, format := range []string{"tar.gz", "tar.xz", "tar.zst", "zip"} {
+		t.Run(format, func(t *testing.T) {
+			var first = create(format, "a.txt", "b.txt")
+			var now = time.Now().Add(time.Hour)
+			assert.NoError(t, os.Chtimes(a, now, now))
+			assert.Equal(t, first, create(format, "b.txt", "a.txt"))
+		})
+	}
+
+	t.Run("gz", func(t *testing.T) {
+		var first = create("gz", "a.txt")
+		var now = time.Now().Add(2 * time.Hour)
+		assert.NoError(t, os.Chtimes(a, now, now))
+		assert.Equal(t, first, create("gz", "a.txt"))
+	})
+}
+
+func TestArchiveReproducibleInvalidLevel(t *testing.T) {
+	folder, err := ioutil.TempDir("", "archivetest")
+	assert.NoError(t, err)
+	file, err := os.Create(folder + "/folder.tar.zst")
+	assert.NoError(t, err)
+	_, err = NewReproducible(file, 23, time.Now())
+	assert.Error(t, err)
+}
+
+func TestArchiveReproducibleMissingFile(t *testing.T) {
+	folder, err := ioutil.TempDir("", "archivetest")
+	assert.NoError(t, err)
+	file, err := os.Create(folder + "/folder.tar.gz")
+	assert.NoError(t, err)
+	archive, err := NewReproducible(file, 0, time.Now())
+	assert.NoError(t, err)
+	assert.NoError(t, archive.Add("nope.txt", folder+"/nope.txt"))
+	assert.Error(t, archive.Close())
+}
+
+func TestAddWithModeAndSymlinkUnsupported(t *testing.T) {
+	folder, err := ioutil.TempDir("", "archivetest")
+	assert.NoError(t, err)
+	var path = filepath.Join(folder, "a.txt")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("a"), 0644))
+	file, err := os.Create(filepath.Join(folder, "folder.gz"))
+	assert.NoError(t, err)
+	var archive = New(file)
+	assert.EqualError(t, AddWithMode(archive, "a.txt", path, 0755), "a.txt: archive format doesn't support file modes")
+	assert.EqualError(t, AddSymlink(archive, "a.txt", path), "a.txt: archive format doesn't support symlinks")
+	assert.NoError(t, AddWithMode(archive, "a.txt", path, 0))
+	assert.NoError(t, archive.Close())
+}
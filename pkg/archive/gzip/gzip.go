@@ -7,11 +7,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 )
 
 // Archive as gz
 type Archive struct {
-	gw *gzip.Writer
+	gw    *gzip.Writer
+	mtime *time.Time
 }
 
 // Close all closeables
@@ -27,6 +29,29 @@ func New(target io.Writer) Archive {
 	}
 }
 
+// NewWithLevel creates a gz archive with the given compression level.
+// Zero means the default level.
+func NewWithLevel(target io.Writer, level int) (Archive, error) {
+	if level == 0 {
+		return New(target), nil
+	}
+	gw, err := gzip.NewWriterLevel(target, level)
+	if err != nil {
+		return Archive{}, err
+	}
+	return Archive{
+		gw: gw,
+	}, nil
+}
+
+// NewReproducible creates a gz archive with the given compression level
+// whose header has the given modification time.
+func NewReproducible(target io.Writer, level int, mtime time.Time) (Archive, error) {
+	a, err := NewWithLevel(target, level)
+	a.mtime = &mtime
+	return a, err
+}
+
 // Add file to the archive
 func (a Archive) Add(name, path string) error {
 	if a.gw.Header.Name != "" {
@@ -46,6 +71,9 @@ func (a Archive) Add(name, path string) error {
 	}
 	a.gw.Header.Name = name
 	a.gw.Header.ModTime = info.ModTime()
+	if a.mtime != nil {
+		a.gw.Header.ModTime = *a.mtime
+	}
 	_, err = io.Copy(a.gw, file)
 	return err
 }
@@ -0,0 +1,122 @@
+// Package sevenzip implements the Archive interface providing 7z archiving
+// and compression, using the 7z command line tool.
+package sevenzip
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Archive as 7z.
+// Files are staged in a temporary folder, which is compressed on Close.
+type Archive struct {
+	target io.Writer
+	level  int
+	root   string
+}
+
+// New 7z archive
+func New(target io.Writer) *Archive {
+	return &Archive{
+		target: target,
+	}
+}
+
+// NewWithLevel creates a 7z archive with the given compression level, from
+// 1 to 9. Zero means the default level.
+// It fails if the 7z command line tool is not in the PATH.
+func NewWithLevel(target io.Writer, level int) (*Archive, error) {
+	if level < 0 || level > 9 {
+		return nil, fmt.Errorf("7z: invalid compression level: %d", level)
+	}
+	if _, err := exec.LookPath("7z"); err != nil {
+		return nil, errors.New("7z: the 7z command line tool is required to create 7z archives, but it was not found in the PATH")
+	}
+	return &Archive{
+		target: target,
+		level:  level,
+	}, nil
+}
+
+// Close compresses the staged files into the target
+func (a *Archive) Close() error {
+	files, err := a.files()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(a.root) // nolint: errcheck
+	var path = filepath.Join(a.root, "archive.7z")
+	var args = []string{"a", "-t7z", "-bd", "-y"}
+	if a.level != 0 {
+		args = append(args, fmt.Sprintf("-mx=%d", a.level))
+	}
+	/* #nosec */
+	var cmd = exec.Command("7z", append(args, path, ".")...)
+	cmd.Dir = files
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "7z: failed to create archive: \n%s", string(out))
+	}
+	archive, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close() // nolint: errcheck
+	_, err = io.Copy(a.target, archive)
+	return err
+}
+
+// Add file to the archive
+func (a *Archive) Add(name, path string) error {
+	files, err := a.files()
+	if err != nil {
+		return err
+	}
+	var dst = filepath.Join(files, name)
+	file, err := os.Open(path) // #nosec
+	if err != nil {
+		return err
+	}
+	defer file.Close() // nolint: errcheck
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return os.MkdirAll(dst, info.Mode())
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, file); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// files returns the folder the files are staged in, creating it on the
+// first call
+func (a *Archive) files() (string, error) {
+	if a.root == "" {
+		root, err := ioutil.TempDir("", "goreleaser7z")
+		if err != nil {
+			return "", err
+		}
+		a.root = root
+	}
+	var files = filepath.Join(a.root, "files")
+	return files, os.MkdirAll(files, 0755)
+}
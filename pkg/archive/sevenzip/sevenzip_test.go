@@ -0,0 +1,76 @@
+package sevenzip
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/stretchr/testify/require"
+)
+
+// fake7z puts a fake 7z in the PATH, which records its args and the staged
+// files, and writes "7z" to the archive.
+func fake7z(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	return tmp, testlib.FakeTools(t, tmp, map[string]string{
+		"7z": `echo "$@" > ` + filepath.Join(tmp, "args") + `
+find . | sort > ` + filepath.Join(tmp, "files") + `
+for a in "$@"; do out=$prev; prev=$a; done
+printf 7z > "$out"
+`,
+	})
+}
+
+func TestSevenZipFile(t *testing.T) {
+	tmp, back := fake7z(t)
+	defer back()
+	f, err := os.Create(filepath.Join(tmp, "test.7z"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive, err := NewWithLevel(f, 9)
+	require.NoError(t, err)
+
+	require.Error(t, archive.Add("nope.txt", "../testdata/nope.txt"))
+	require.NoError(t, archive.Add("foo.txt", "../testdata/foo.txt"))
+	require.NoError(t, archive.Add("sub1", "../testdata/sub1"))
+	require.NoError(t, archive.Add("sub1/bar.txt", "../testdata/sub1/bar.txt"))
+	require.NoError(t, archive.Add("sub1/executable", "../testdata/sub1/executable"))
+	require.NoError(t, archive.Close())
+	require.NoError(t, f.Close())
+
+	bts, err := ioutil.ReadFile(f.Name())
+	require.NoError(t, err)
+	require.Equal(t, "7z", string(bts))
+
+	args, err := ioutil.ReadFile(filepath.Join(tmp, "args"))
+	require.NoError(t, err)
+	require.Contains(t, string(args), "a -t7z -bd -y -mx=9 ")
+	files, err := ioutil.ReadFile(filepath.Join(tmp, "files"))
+	require.NoError(t, err)
+	require.Equal(t, ".\n./foo.txt\n./sub1\n./sub1/bar.txt\n./sub1/executable\n", string(files))
+}
+
+func TestSevenZipInvalidLevel(t *testing.T) {
+	_, err := NewWithLevel(ioutil.Discard, 10)
+	require.EqualError(t, err, "7z: invalid compression level: 10")
+}
+
+func TestSevenZipFails(t *testing.T) {
+	var path = os.Getenv("PATH")
+	require.NoError(t, os.Setenv("PATH", ""))
+	defer os.Setenv("PATH", path) // nolint: errcheck
+	var archive = New(ioutil.Discard)
+	require.NoError(t, archive.Add("foo.txt", "../testdata/foo.txt"))
+	require.Error(t, archive.Close())
+}
+
+func TestSevenZipNotInPath(t *testing.T) {
+	var path = os.Getenv("PATH")
+	require.NoError(t, os.Setenv("PATH", ""))
+	defer os.Setenv("PATH", path) // nolint: errcheck
+	_, err := NewWithLevel(ioutil.Discard, 0)
+	require.EqualError(t, err, "7z: the 7z command line tool is required to create 7z archives, but it was not found in the PATH")
+}
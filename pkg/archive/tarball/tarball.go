@@ -0,0 +1,110 @@
+// Package tarball implements the Archive interface providing tar archiving
+// on top of a compressor, and is shared by the tar based archive formats.
+package tarball
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"time"
+)
+
+// Archive as tar, written to a compressor
+type Archive struct {
+	cw    io.WriteCloser
+	tw    *tar.Writer
+	mtime *time.Time
+}
+
+// New tar archive, written to the given compressor, which is closed with
+// the archive
+func New(compressor io.WriteCloser) Archive {
+	return Archive{
+		cw: compressor,
+		tw: tar.NewWriter(compressor),
+	}
+}
+
+// NewReproducible creates a tar archive written to the given compressor
+// whose entries all have the given modification time and no owner.
+func NewReproducible(compressor io.WriteCloser, mtime time.Time) Archive {
+	var a = New(compressor)
+	a.mtime = &mtime
+	return a
+}
+
+// Close all closeables
+func (a Archive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.cw.Close()
+}
+
+// Add file to the archive
+func (a Archive) Add(name, path string) error {
+	return a.AddWithMode(name, path, 0)
+}
+
+// AddWithMode adds a file to the archive with the given permissions, or
+// with the permissions of the file if mode is zero
+func (a Archive) AddWithMode(name, path string, mode os.FileMode) error {
+	file, err := os.Open(path) // #nosec
+	if err != nil {
+		return err
+	}
+	defer file.Close() // nolint: errcheck
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := a.header(info, name, "")
+	if err != nil {
+		return err
+	}
+	if mode != 0 {
+		header.Mode = int64(mode.Perm())
+	}
+	if err = a.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+	_, err = io.Copy(a.tw, file)
+	return err
+}
+
+// AddSymlink adds the symlink at the given path as a symlink, instead of
+// the file it points to
+func (a Archive) AddSymlink(name, path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	link, err := os.Readlink(path)
+	if err != nil {
+		return err
+	}
+	header, err := a.header(info, name, link)
+	if err != nil {
+		return err
+	}
+	return a.tw.WriteHeader(header)
+}
+
+func (a Archive) header(info os.FileInfo, name, link string) (*tar.Header, error) {
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return nil, err
+	}
+	header.Name = name
+	if a.mtime != nil {
+		header.ModTime = *a.mtime
+		header.AccessTime = time.Time{}
+		header.ChangeTime = time.Time{}
+		header.Uid, header.Gid = 0, 0
+		header.Uname, header.Gname = "", ""
+	}
+	return header, nil
+}
@@ -3,58 +3,46 @@
 package targz
 
 import (
-	"archive/tar"
 	"compress/gzip"
 	"io"
-	"os"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/archive/tarball"
 )
 
 // Archive as tar.gz
 type Archive struct {
-	gw *gzip.Writer
-	tw *tar.Writer
-}
-
-// Close all closeables
-func (a Archive) Close() error {
-	if err := a.tw.Close(); err != nil {
-		return err
-	}
-	return a.gw.Close()
+	tarball.Archive
 }
 
 // New tar.gz archive
 func New(target io.Writer) Archive {
-	gw := gzip.NewWriter(target)
-	tw := tar.NewWriter(gw)
-	return Archive{
-		gw: gw,
-		tw: tw,
-	}
+	return Archive{tarball.New(gzip.NewWriter(target))}
 }
 
-// Add file to the archive
-func (a Archive) Add(name, path string) error {
-	file, err := os.Open(path) // #nosec
-	if err != nil {
-		return err
-	}
-	defer file.Close() // nolint: errcheck
-	info, err := file.Stat()
+// NewWithLevel creates a tar.gz archive with the given gzip compression
+// level. Zero means the default level.
+func NewWithLevel(target io.Writer, level int) (Archive, error) {
+	gw, err := newWriter(target, level)
 	if err != nil {
-		return err
+		return Archive{}, err
 	}
-	header, err := tar.FileInfoHeader(info, name)
+	return Archive{tarball.New(gw)}, nil
+}
+
+// NewReproducible creates a tar.gz archive with the given compression level
+// whose entries all have the given modification time and no owner.
+func NewReproducible(target io.Writer, level int, mtime time.Time) (Archive, error) {
+	gw, err := newWriter(target, level)
 	if err != nil {
-		return err
+		return Archive{}, err
 	}
-	header.Name = name
-	if err = a.tw.WriteHeader(header); err != nil {
-		return err
-	}
-	if info.IsDir() {
-		return nil
+	return Archive{tarball.NewReproducible(gw, mtime)}, nil
+}
+
+func newWriter(target io.Writer, level int) (*gzip.Writer, error) {
+	if level == 0 {
+		return gzip.NewWriter(target), nil
 	}
-	_, err = io.Copy(a.tw, file)
-	return err
+	return gzip.NewWriterLevel(target, level)
 }
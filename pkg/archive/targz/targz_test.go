@@ -70,3 +70,37 @@ func TestTarGzFile(t *testing.T) {
 		"sub1/sub2/subfoo.txt",
 	}, paths)
 }
+
+func TestTarGzSymlinkAndMode(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(t, err)
+	var link = filepath.Join(tmp, "link")
+	assert.NoError(t, os.Symlink("foo.txt", link))
+	f, err := os.Create(filepath.Join(tmp, "test.tar.gz"))
+	assert.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive := New(f)
+	assert.NoError(t, archive.AddWithMode("foo.txt", "../testdata/foo.txt", 0700))
+	assert.NoError(t, archive.AddSymlink("link", link))
+	assert.Error(t, archive.AddSymlink("nope", "../testdata/foo.txt"))
+	assert.NoError(t, archive.Close())
+	assert.NoError(t, f.Close())
+
+	f, err = os.Open(f.Name())
+	assert.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	gzf, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	r := tar.NewReader(gzf)
+
+	header, err := r.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "foo.txt", header.Name)
+	assert.Equal(t, int64(0700), header.Mode)
+
+	header, err = r.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "link", header.Name)
+	assert.Equal(t, byte(tar.TypeSymlink), header.Typeflag)
+	assert.Equal(t, "foo.txt", header.Linkname)
+}
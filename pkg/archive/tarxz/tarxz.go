@@ -0,0 +1,69 @@
+// Package tarxz implements the Archive interface providing tar.xz archiving
+// and compression.
+package tarxz
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/archive/tarball"
+	"github.com/ulikunitz/xz"
+)
+
+// Archive as tar.xz
+type Archive struct {
+	tarball.Archive
+}
+
+// New tar.xz archive
+func New(target io.Writer) Archive {
+	// the default config is valid, so this never fails
+	xzw, _ := xz.NewWriter(target)
+	return Archive{tarball.New(xzw)}
+}
+
+// dictionary sizes of the xz presets, from 0 to 9
+// nolint: gochecknoglobals
+var dictCaps = []int{
+	256 << 10,
+	1 << 20,
+	2 << 20,
+	4 << 20,
+	4 << 20,
+	8 << 20,
+	8 << 20,
+	16 << 20,
+	32 << 20,
+	64 << 20,
+}
+
+// NewWithLevel creates a tar.xz archive with the given xz preset level,
+// from 1 to 9, which sets the dictionary size. Zero means the default level.
+func NewWithLevel(target io.Writer, level int) (Archive, error) {
+	xzw, err := newWriter(target, level)
+	if err != nil {
+		return Archive{}, err
+	}
+	return Archive{tarball.New(xzw)}, nil
+}
+
+// NewReproducible creates a tar.xz archive with the given compression level
+// whose entries all have the given modification time and no owner.
+func NewReproducible(target io.Writer, level int, mtime time.Time) (Archive, error) {
+	xzw, err := newWriter(target, level)
+	if err != nil {
+		return Archive{}, err
+	}
+	return Archive{tarball.NewReproducible(xzw, mtime)}, nil
+}
+
+func newWriter(target io.Writer, level int) (*xz.Writer, error) {
+	if level == 0 {
+		return xz.NewWriter(target)
+	}
+	if level < 1 || level >= len(dictCaps) {
+		return nil, fmt.Errorf("xz: invalid compression level: %d", level)
+	}
+	return xz.WriterConfig{DictCap: dictCaps[level]}.NewWriter(target)
+}
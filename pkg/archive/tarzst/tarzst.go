@@ -0,0 +1,54 @@
+// Package tarzst implements the Archive interface providing tar.zst archiving
+// and compression.
+package tarzst
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/archive/tarball"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Archive as tar.zst
+type Archive struct {
+	tarball.Archive
+}
+
+// New tar.zst archive
+func New(target io.Writer) Archive {
+	// no options are given, so this never fails
+	zw, _ := zstd.NewWriter(target)
+	return Archive{tarball.New(zw)}
+}
+
+// NewWithLevel creates a tar.zst archive with the given zstd compression
+// level, from 1 to 22. Zero means the default level.
+func NewWithLevel(target io.Writer, level int) (Archive, error) {
+	zw, err := newWriter(target, level)
+	if err != nil {
+		return Archive{}, err
+	}
+	return Archive{tarball.New(zw)}, nil
+}
+
+// NewReproducible creates a tar.zst archive with the given compression level
+// whose entries all have the given modification time and no owner.
+func NewReproducible(target io.Writer, level int, mtime time.Time) (Archive, error) {
+	zw, err := newWriter(target, level)
+	if err != nil {
+		return Archive{}, err
+	}
+	return Archive{tarball.NewReproducible(zw, mtime)}, nil
+}
+
+func newWriter(target io.Writer, level int) (*zstd.Encoder, error) {
+	if level == 0 {
+		return zstd.NewWriter(target)
+	}
+	if level < 1 || level > 22 {
+		return nil, fmt.Errorf("zstd: invalid compression level: %d", level)
+	}
+	return zstd.NewWriter(target, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+}
@@ -4,13 +4,17 @@ package zip
 
 import (
 	"archive/zip"
+	"compress/flate"
 	"io"
+	"io/ioutil"
 	"os"
+	"time"
 )
 
 // Archive zip struct
 type Archive struct {
-	z *zip.Writer
+	z     *zip.Writer
+	mtime *time.Time
 }
 
 // Close all closeables
@@ -25,8 +29,41 @@ func New(target io.Writer) Archive {
 	}
 }
 
+// NewWithLevel creates a zip archive with the given deflate compression
+// level. Zero means the default level.
+func NewWithLevel(target io.Writer, level int) (Archive, error) {
+	if level == 0 {
+		return New(target), nil
+	}
+	// validates the level upfront, as compressors can't return errors
+	if _, err := flate.NewWriter(ioutil.Discard, level); err != nil {
+		return Archive{}, err
+	}
+	var z = zip.NewWriter(target)
+	z.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, level)
+	})
+	return Archive{
+		z: z,
+	}, nil
+}
+
+// NewReproducible creates a zip archive with the given compression level
+// whose entries all have the given modification time.
+func NewReproducible(target io.Writer, level int, mtime time.Time) (Archive, error) {
+	a, err := NewWithLevel(target, level)
+	a.mtime = &mtime
+	return a, err
+}
+
 // Add a file to the zip archive
-func (a Archive) Add(name, path string) (err error) {
+func (a Archive) Add(name, path string) error {
+	return a.AddWithMode(name, path, 0)
+}
+
+// AddWithMode adds a file to the zip archive with the given permissions, or
+// with the permissions of the file if mode is zero
+func (a Archive) AddWithMode(name, path string, mode os.FileMode) (err error) {
 	file, err := os.Open(path) // #nosec
 	if err != nil {
 		return
@@ -39,12 +76,14 @@ func (a Archive) Add(name, path string) (err error) {
 	if info.IsDir() {
 		return
 	}
-	header, err := zip.FileInfoHeader(info)
+	header, err := a.header(info, name)
 	if err != nil {
 		return err
 	}
-	header.Name = name
 	header.Method = zip.Deflate
+	if mode != 0 {
+		header.SetMode(mode.Perm())
+	}
 	w, err := a.z.CreateHeader(header)
 	if err != nil {
 		return err
@@ -52,3 +91,39 @@ func (a Archive) Add(name, path string) (err error) {
 	_, err = io.Copy(w, file)
 	return err
 }
+
+// AddSymlink adds the symlink at the given path as a symlink, instead of
+// the file it points to
+func (a Archive) AddSymlink(name, path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	link, err := os.Readlink(path)
+	if err != nil {
+		return err
+	}
+	header, err := a.header(info, name)
+	if err != nil {
+		return err
+	}
+	w, err := a.z.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	// zip stores the symlink target as the content of the entry
+	_, err = io.WriteString(w, link)
+	return err
+}
+
+func (a Archive) header(info os.FileInfo, name string) (*zip.FileHeader, error) {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return nil, err
+	}
+	header.Name = name
+	if a.mtime != nil {
+		header.Modified = a.mtime.UTC()
+	}
+	return header, nil
+}
@@ -61,3 +61,31 @@ func TestZipFile(t *testing.T) {
 		"sub1/sub2/subfoo.txt",
 	}, paths)
 }
+
+func TestZipSymlinkAndMode(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(t, err)
+	var link = filepath.Join(tmp, "link")
+	assert.NoError(t, os.Symlink("foo.txt", link))
+	f, err := os.Create(filepath.Join(tmp, "test.zip"))
+	assert.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive := New(f)
+	assert.NoError(t, archive.AddWithMode("foo.txt", "../testdata/foo.txt", 0700))
+	assert.NoError(t, archive.AddSymlink("link", link))
+	assert.NoError(t, archive.Close())
+	assert.NoError(t, f.Close())
+
+	r, err := zip.OpenReader(f.Name())
+	assert.NoError(t, err)
+	defer r.Close() // nolint: errcheck
+	assert.Len(t, r.File, 2)
+	assert.Equal(t, os.FileMode(0700), r.File[0].Mode())
+	assert.Equal(t, os.ModeSymlink, r.File[1].Mode()&os.ModeSymlink)
+	rc, err := r.File[1].Open()
+	assert.NoError(t, err)
+	defer rc.Close() // nolint: errcheck
+	bts, err := ioutil.ReadAll(rc)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo.txt", string(bts))
+}
@@ -0,0 +1,189 @@
+package build
+
+import (
+	"bytes"
+	stdctx "context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"reflect"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/pkg/errors"
+)
+
+// PluginRequest is the JSON document written to a builder plugin's stdin
+type PluginRequest struct {
+	// Action is either "defaults" or "build"
+	Action  string       `json:"action"`
+	Build   config.Build `json:"build"`
+	Target  string       `json:"target,omitempty"`
+	Name    string       `json:"name,omitempty"`
+	Path    string       `json:"path,omitempty"`
+	Ext     string       `json:"ext,omitempty"`
+	Env     []string     `json:"env,omitempty"`
+	Version string       `json:"version,omitempty"`
+	Tag     string       `json:"tag,omitempty"`
+	Commit  string       `json:"commit,omitempty"`
+}
+
+// PluginResponse is the JSON document a builder plugin writes to its stdout
+type PluginResponse struct {
+	// Build is the build with its defaults set, for the "defaults" action
+	Build *config.Build `json:"build,omitempty"`
+	// Artifacts built by the "build" action. If empty, the binary is
+	// assumed to be at the requested path.
+	Artifacts []PluginArtifact `json:"artifacts,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// PluginArtifact is a binary built by a builder plugin
+type PluginArtifact struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Goos   string `json:"goos,omitempty"`
+	Goarch string `json:"goarch,omitempty"`
+	Goarm  string `json:"goarm,omitempty"`
+}
+
+// DefaultsFailer is implemented by builders which may fail to set the
+// build defaults, like plugins
+type DefaultsFailer interface {
+	Defaults(ctx *context.Context, build config.Build) (config.Build, error)
+}
+
+// RegisterPlugins registers the given builder plugins. Their langs can't be
+// the ones of other builders, but registering the same plugin again is fine.
+func RegisterPlugins(plugins []config.BuilderPlugin) error {
+	for _, plugin := range plugins {
+		if plugin.Lang == "" || plugin.Cmd == "" {
+			return fmt.Errorf("builder plugins must have both lang and cmd set")
+		}
+		var p = &Plugin{Cmd: strings.Fields(plugin.Cmd)}
+		if existing := For(plugin.Lang); existing != nil {
+			if reflect.DeepEqual(existing, p) {
+				continue
+			}
+			return fmt.Errorf("builder plugin %s: lang %s already has a builder", plugin.Cmd, plugin.Lang)
+		}
+		Register(plugin.Lang, p)
+	}
+	return nil
+}
+
+// Plugin is a builder implemented by an external command, which receives a
+// PluginRequest on its stdin and answers with a PluginResponse on its stdout
+type Plugin struct {
+	Cmd []string
+}
+
+// Defaults asks the plugin to set the build defaults
+func (p *Plugin) Defaults(ctx *context.Context, build config.Build) (config.Build, error) {
+	return p.defaults(ctx, build)
+}
+
+func (p *Plugin) defaults(ctx stdctx.Context, build config.Build) (config.Build, error) {
+	resp, err := p.call(ctx, nil, PluginRequest{
+		Action: "defaults",
+		Build:  build,
+	})
+	if err != nil {
+		return build, err
+	}
+	if resp.Build == nil {
+		return build, nil
+	}
+	return *resp.Build, nil
+}
+
+// WithDefaults sets the build defaults, leaving the build untouched if the
+// plugin fails
+func (p *Plugin) WithDefaults(build config.Build) config.Build {
+	result, err := p.defaults(stdctx.Background(), build)
+	if err != nil {
+		log.WithError(err).Warn("builder plugin failed to set defaults")
+	}
+	return result
+}
+
+// Build asks the plugin to build the given target
+func (p *Plugin) Build(ctx *context.Context, build config.Build, options Options) error {
+	resp, err := p.call(ctx, ctx.Env.Strings(), PluginRequest{
+		Action:  "build",
+		Build:   build,
+		Target:  options.Target,
+		Name:    options.Name,
+		Path:    options.Path,
+		Ext:     options.Ext,
+		Env:     build.Env,
+		Version: ctx.Version,
+		Tag:     ctx.Git.CurrentTag,
+		Commit:  ctx.Git.Commit,
+	})
+	if err != nil {
+		return err
+	}
+	if len(resp.Artifacts) == 0 {
+		var parts = strings.Split(options.Target, "_")
+		var art = PluginArtifact{
+			Name: options.Name,
+			Path: options.Path,
+			Goos: parts[0],
+		}
+		if len(parts) > 1 {
+			art.Goarch = parts[1]
+		}
+		if len(parts) > 2 {
+			art.Goarm = parts[2]
+		}
+		resp.Artifacts = append(resp.Artifacts, art)
+	}
+	for _, art := range resp.Artifacts {
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type:   artifact.Binary,
+			Name:   art.Name,
+			Path:   art.Path,
+			Goos:   art.Goos,
+			Goarch: art.Goarch,
+			Goarm:  art.Goarm,
+			Extra: map[string]interface{}{
+				"Binary": build.Binary,
+				"Ext":    options.Ext,
+				"ID":     build.ID,
+			},
+		})
+	}
+	return nil
+}
+
+func (p *Plugin) call(ctx stdctx.Context, env []string, req PluginRequest) (PluginResponse, error) {
+	var resp PluginResponse
+	in, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, p.Cmd[0], p.Cmd[1:]...)
+	if env != nil {
+		cmd.Env = append(env, req.Env...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	log.WithField("cmd", p.Cmd).WithField("action", req.Action).Debug("calling builder plugin")
+	if err := cmd.Run(); err != nil {
+		return resp, errors.Wrapf(err, "builder plugin %s failed: %s", p.Cmd[0], stderr.String())
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return resp, errors.Wrapf(err, "builder plugin %s returned an invalid response", p.Cmd[0])
+	}
+	if resp.Error != "" {
+		return resp, fmt.Errorf("builder plugin %s failed: %s", p.Cmd[0], resp.Error)
+	}
+	return resp, nil
+}
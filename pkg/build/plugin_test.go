@@ -0,0 +1,128 @@
+package build
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func writePlugin(t *testing.T, script string) string {
+	folder, err := ioutil.TempDir("", "goreleaserplugin")
+	require.NoError(t, err)
+	var path = filepath.Join(folder, "plugin")
+	require.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755))
+	return path
+}
+
+func TestRegisterPlugins(t *testing.T) {
+	require.NoError(t, RegisterPlugins([]config.BuilderPlugin{
+		{Lang: "rust", Cmd: "goreleaser-rust --verbose"},
+	}))
+	require.Equal(t, &Plugin{Cmd: []string{"goreleaser-rust", "--verbose"}}, For("rust"))
+}
+
+func TestRegisterPluginsAlreadyRegistered(t *testing.T) {
+	Register("builtin", &Plugin{Cmd: []string{"builtin"}})
+	require.EqualError(t, RegisterPlugins([]config.BuilderPlugin{
+		{Lang: "builtin", Cmd: "goreleaser-builtin"},
+	}), "builder plugin goreleaser-builtin: lang builtin already has a builder")
+	require.Equal(t, &Plugin{Cmd: []string{"builtin"}}, For("builtin"))
+
+	var plugins = []config.BuilderPlugin{{Lang: "zig", Cmd: "goreleaser-zig"}}
+	require.NoError(t, RegisterPlugins(plugins))
+	require.NoError(t, RegisterPlugins(plugins))
+}
+
+func TestRegisterPluginsInvalid(t *testing.T) {
+	require.EqualError(t, RegisterPlugins([]config.BuilderPlugin{
+		{Lang: "rust"},
+	}), "builder plugins must have both lang and cmd set")
+}
+
+func TestPluginDefaults(t *testing.T) {
+	var plugin = &Plugin{Cmd: []string{writePlugin(t, `
+grep -q '"action":"defaults"' || exit 1
+echo '{"build": {"Binary": "foo", "Targets": ["linux_amd64"]}}'
+`)}}
+	build, err := plugin.Defaults(context.New(config.Project{}), config.Build{Lang: "rust"})
+	require.NoError(t, err)
+	require.Equal(t, config.Build{
+		Binary:  "foo",
+		Targets: []string{"linux_amd64"},
+	}, build)
+}
+
+func TestPluginDefaultsError(t *testing.T) {
+	var plugin = &Plugin{Cmd: []string{writePlugin(t, `echo '{"error": "no cargo"}'`)}}
+	var build = config.Build{Lang: "rust"}
+	_, err := plugin.Defaults(context.New(config.Project{}), build)
+	require.Contains(t, err.Error(), "failed: no cargo")
+	require.Equal(t, build, plugin.WithDefaults(build))
+}
+
+func TestPluginInvalidResponse(t *testing.T) {
+	var plugin = &Plugin{Cmd: []string{writePlugin(t, `echo nope`)}}
+	_, err := plugin.Defaults(context.New(config.Project{}), config.Build{})
+	require.Contains(t, err.Error(), "returned an invalid response")
+}
+
+func TestPluginBuild(t *testing.T) {
+	var plugin = &Plugin{Cmd: []string{writePlugin(t, `
+grep -q '"target":"linux_arm_7"' || exit 1
+echo "{}"
+`)}}
+	var ctx = context.New(config.Project{})
+	require.NoError(t, plugin.Build(ctx, config.Build{ID: "foo", Binary: "foo"}, Options{
+		Target: "linux_arm_7",
+		Name:   "foo",
+		Path:   "dist/foo_linux_arm_7/foo",
+	}))
+	require.Equal(t, []*artifact.Artifact{
+		{
+			Type:   artifact.Binary,
+			Name:   "foo",
+			Path:   "dist/foo_linux_arm_7/foo",
+			Goos:   "linux",
+			Goarch: "arm",
+			Goarm:  "7",
+			Extra: map[string]interface{}{
+				"Binary": "foo",
+				"Ext":    "",
+				"ID":     "foo",
+			},
+		},
+	}, ctx.Artifacts.List())
+}
+
+func TestPluginBuildArtifacts(t *testing.T) {
+	var plugin = &Plugin{Cmd: []string{writePlugin(t, `
+echo '{"artifacts": [{"name": "a", "path": "out/a", "goos": "linux", "goarch": "amd64"}, {"name": "b", "path": "out/b", "goos": "linux", "goarch": "amd64"}]}'
+`)}}
+	var ctx = context.New(config.Project{})
+	require.NoError(t, plugin.Build(ctx, config.Build{ID: "foo"}, Options{
+		Target: "linux_amd64",
+	}))
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.Binary)).List(), 2)
+}
+
+func TestPluginBuildFails(t *testing.T) {
+	var plugin = &Plugin{Cmd: []string{writePlugin(t, `echo "cargo exploded" >&2; exit 1`)}}
+	var ctx = context.New(config.Project{})
+	var err = plugin.Build(ctx, config.Build{}, Options{Target: "linux_amd64"})
+	require.Contains(t, err.Error(), "cargo exploded")
+}
+
+func TestPluginBuildTimeout(t *testing.T) {
+	var plugin = &Plugin{Cmd: []string{writePlugin(t, `exec sleep 10`)}}
+	ctx, cancel := context.NewWithTimeout(config.Project{}, 50*time.Millisecond)
+	defer cancel()
+	var start = time.Now()
+	require.Error(t, plugin.Build(ctx, config.Build{}, Options{Target: "linux_amd64"}))
+	require.True(t, time.Since(start) < 5*time.Second)
+}
@@ -22,14 +22,16 @@ type GitHubURLs struct {
 
 // GitLabURLs holds the URLs to be used when using gitlab ce/enterprise
 type GitLabURLs struct {
-	API           string `yaml:"api,omitempty"`
-	Download      string `yaml:"download,omitempty"`
-	SkipTLSVerify bool   `yaml:"skip_tls_verify,omitempty"`
+	API                string `yaml:"api,omitempty"`
+	Download           string `yaml:"download,omitempty"`
+	SkipTLSVerify      bool   `yaml:"skip_tls_verify,omitempty"`
+	UsePackageRegistry bool   `yaml:"use_package_registry,omitempty"`
 }
 
 // GiteaURLs holds the URLs to be used when using gitea
 type GiteaURLs struct {
 	API           string `yaml:"api,omitempty"`
+	Download      string `yaml:"download,omitempty"`
 	SkipTLSVerify bool   `yaml:"skip_tls_verify,omitempty"`
 }
 
@@ -49,31 +51,94 @@ func (r Repo) String() string {
 
 // Homebrew contains the brew section
 type Homebrew struct {
-	Name             string       `yaml:",omitempty"`
-	GitHub           Repo         `yaml:",omitempty"`
-	GitLab           Repo         `yaml:",omitempty"`
-	CommitAuthor     CommitAuthor `yaml:"commit_author,omitempty"`
-	Folder           string       `yaml:",omitempty"`
-	Caveats          string       `yaml:",omitempty"`
-	Plist            string       `yaml:",omitempty"`
-	Install          string       `yaml:",omitempty"`
-	Dependencies     []string     `yaml:",omitempty"`
-	Test             string       `yaml:",omitempty"`
-	Conflicts        []string     `yaml:",omitempty"`
-	Description      string       `yaml:",omitempty"`
-	Homepage         string       `yaml:",omitempty"`
-	SkipUpload       string       `yaml:"skip_upload,omitempty"`
-	DownloadStrategy string       `yaml:"download_strategy,omitempty"`
-	URLTemplate      string       `yaml:"url_template,omitempty"`
-	CustomRequire    string       `yaml:"custom_require,omitempty"`
-	CustomBlock      string       `yaml:"custom_block,omitempty"`
-	IDs              []string     `yaml:"ids,omitempty"`
-	Goarm            string       `yaml:"goarm,omitempty"`
+	Name             string               `yaml:",omitempty"`
+	GitHub           Repo                 `yaml:",omitempty"`
+	GitLab           Repo                 `yaml:",omitempty"`
+	Gitea            Repo                 `yaml:",omitempty"`
+	CommitAuthor     CommitAuthor         `yaml:"commit_author,omitempty"`
+	Folder           string               `yaml:",omitempty"`
+	Caveats          string               `yaml:",omitempty"`
+	Plist            string               `yaml:",omitempty"`
+	Service          string               `yaml:",omitempty"`
+	Install          string               `yaml:",omitempty"`
+	Dependencies     []HomebrewDependency `yaml:",omitempty"`
+	Test             string               `yaml:",omitempty"`
+	Conflicts        []string             `yaml:",omitempty"`
+	Head             HomebrewHead         `yaml:",omitempty"`
+	Description      string               `yaml:",omitempty"`
+	Homepage         string               `yaml:",omitempty"`
+	SkipUpload       string               `yaml:"skip_upload,omitempty"`
+	DownloadStrategy string               `yaml:"download_strategy,omitempty"`
+	URLTemplate      string               `yaml:"url_template,omitempty"`
+	CustomRequire    string               `yaml:"custom_require,omitempty"`
+	CustomBlock      string               `yaml:"custom_block,omitempty"`
+	IDs              []string             `yaml:"ids,omitempty"`
+	Goarm            string               `yaml:"goarm,omitempty"`
+	If               string               `yaml:"if,omitempty"`
+}
+
+// HomebrewDependency is a formula the formula depends on, optionally only
+// for a given step, e.g. build, test or optional
+type HomebrewDependency struct {
+	Name string `yaml:",omitempty"`
+	Type string `yaml:",omitempty"`
+}
+
+// UnmarshalYAML is a custom unmarshaler that also accepts a plain name
+func (d *HomebrewDependency) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*d = HomebrewDependency{Name: name}
+		return nil
+	}
+	type dependency HomebrewDependency
+	var t dependency
+	if err := unmarshal(&t); err != nil {
+		return err
+	}
+	*d = HomebrewDependency(t)
+	return nil
+}
+
+// HomebrewHead is the repository the formula is built from when installed
+// with --HEAD
+type HomebrewHead struct {
+	URL    string `yaml:"url,omitempty"`
+	Branch string `yaml:",omitempty"`
+}
+
+// HomebrewCask contains the config of the homebrew cask of a macOS
+// application, pushed to a tap
+type HomebrewCask struct {
+	Name         string                   `yaml:",omitempty"`
+	IDs          []string                 `yaml:"ids,omitempty"`
+	Repository   Repo                     `yaml:",omitempty"`
+	CommitAuthor CommitAuthor             `yaml:"commit_author,omitempty"`
+	Folder       string                   `yaml:",omitempty"`
+	Description  string                   `yaml:",omitempty"`
+	Homepage     string                   `yaml:",omitempty"`
+	App          string                   `yaml:",omitempty"`
+	Pkg          string                   `yaml:",omitempty"`
+	Binaries     []string                 `yaml:",omitempty"`
+	Dependencies []HomebrewCaskDependency `yaml:",omitempty"`
+	Conflicts    []string                 `yaml:",omitempty"`
+	Zap          []string                 `yaml:",omitempty"`
+	Caveats      string                   `yaml:",omitempty"`
+	URLTemplate  string                   `yaml:"url_template,omitempty"`
+	SkipUpload   string                   `yaml:"skip_upload,omitempty"`
+	If           string                   `yaml:"if,omitempty"`
+}
+
+// HomebrewCaskDependency is a cask or a formula the cask depends on
+type HomebrewCaskDependency struct {
+	Cask    string `yaml:",omitempty"`
+	Formula string `yaml:",omitempty"`
 }
 
 // Scoop contains the scoop.sh section
 type Scoop struct {
 	Name         string       `yaml:",omitempty"`
+	IDs          []string     `yaml:"ids,omitempty"`
 	Bucket       Repo         `yaml:",omitempty"`
 	CommitAuthor CommitAuthor `yaml:"commit_author,omitempty"`
 	Homepage     string       `yaml:",omitempty"`
@@ -81,6 +146,132 @@ type Scoop struct {
 	License      string       `yaml:",omitempty"`
 	URLTemplate  string       `yaml:"url_template,omitempty"`
 	Persist      []string     `yaml:"persist,omitempty"`
+	If           string       `yaml:"if,omitempty"`
+}
+
+// AUR contains the AUR (Arch User Repository) section
+type AUR struct {
+	Name         string       `yaml:",omitempty"`
+	IDs          []string     `yaml:"ids,omitempty"`
+	CommitAuthor CommitAuthor `yaml:"commit_author,omitempty"`
+	Description  string       `yaml:",omitempty"`
+	Homepage     string       `yaml:",omitempty"`
+	License      string       `yaml:",omitempty"`
+	Maintainers  []string     `yaml:",omitempty"`
+	Contributors []string     `yaml:",omitempty"`
+	Provides     []string     `yaml:",omitempty"`
+	Conflicts    []string     `yaml:",omitempty"`
+	Depends      []string     `yaml:",omitempty"`
+	OptDepends   []string     `yaml:"optdepends,omitempty"`
+	Rel          string       `yaml:",omitempty"`
+	Package      string       `yaml:",omitempty"`
+	URLTemplate  string       `yaml:"url_template,omitempty"`
+	GitURL       string       `yaml:"git_url,omitempty"`
+	PrivateKey   string       `yaml:"private_key,omitempty"`
+	Auth         GitAuth      `yaml:",omitempty"`
+	SkipUpload   string       `yaml:"skip_upload,omitempty"`
+	If           string       `yaml:"if,omitempty"`
+}
+
+// Nix contains the config of the nix derivation pushed to a NUR or a
+// nixpkgs-style repository
+type Nix struct {
+	Name         string       `yaml:",omitempty"`
+	Repository   Repo         `yaml:",omitempty"`
+	CommitAuthor CommitAuthor `yaml:"commit_author,omitempty"`
+	Path         string       `yaml:",omitempty"`
+	Description  string       `yaml:",omitempty"`
+	Homepage     string       `yaml:",omitempty"`
+	License      string       `yaml:",omitempty"`
+	VendorHash   string       `yaml:"vendor_hash,omitempty"`
+	SubPackages  []string     `yaml:"sub_packages,omitempty"`
+	Ldflags      []string     `yaml:",omitempty"`
+	URLTemplate  string       `yaml:"url_template,omitempty"`
+	SkipUpload   string       `yaml:"skip_upload,omitempty"`
+	If           string       `yaml:"if,omitempty"`
+}
+
+// Chocolatey contains the config of the chocolatey package pushed to a
+// nuget feed
+type Chocolatey struct {
+	Name                     string                 `yaml:",omitempty"`
+	IDs                      []string               `yaml:"ids,omitempty"`
+	Title                    string                 `yaml:",omitempty"`
+	Authors                  string                 `yaml:",omitempty"`
+	Owners                   string                 `yaml:",omitempty"`
+	Summary                  string                 `yaml:",omitempty"`
+	Description              string                 `yaml:",omitempty"`
+	Tags                     string                 `yaml:",omitempty"`
+	Copyright                string                 `yaml:",omitempty"`
+	ProjectURL               string                 `yaml:"project_url,omitempty"`
+	PackageSourceURL         string                 `yaml:"package_source_url,omitempty"`
+	ProjectSourceURL         string                 `yaml:"project_source_url,omitempty"`
+	DocsURL                  string                 `yaml:"docs_url,omitempty"`
+	BugTrackerURL            string                 `yaml:"bug_tracker_url,omitempty"`
+	IconURL                  string                 `yaml:"icon_url,omitempty"`
+	LicenseURL               string                 `yaml:"license_url,omitempty"`
+	RequireLicenseAcceptance bool                   `yaml:"require_license_acceptance,omitempty"`
+	ReleaseNotes             string                 `yaml:"release_notes,omitempty"`
+	Dependencies             []ChocolateyDependency `yaml:",omitempty"`
+	URLTemplate              string                 `yaml:"url_template,omitempty"`
+	SourceRepo               string                 `yaml:"source_repo,omitempty"`
+	APIKey                   string                 `yaml:"api_key,omitempty"`
+	SkipPublish              bool                   `yaml:"skip_publish,omitempty"`
+	If                       string                 `yaml:"if,omitempty"`
+}
+
+// ChocolateyDependency is a package the chocolatey package depends on
+type ChocolateyDependency struct {
+	ID      string `yaml:"id,omitempty"`
+	Version string `yaml:",omitempty"`
+}
+
+// Winget contains the config of the winget manifests proposed to the
+// winget-pkgs repository
+type Winget struct {
+	Name              string       `yaml:",omitempty"`
+	PackageIdentifier string       `yaml:"package_identifier,omitempty"`
+	IDs               []string     `yaml:"ids,omitempty"`
+	Publisher         string       `yaml:",omitempty"`
+	PublisherURL      string       `yaml:"publisher_url,omitempty"`
+	Author            string       `yaml:",omitempty"`
+	ShortDescription  string       `yaml:"short_description,omitempty"`
+	Description       string       `yaml:",omitempty"`
+	Homepage          string       `yaml:",omitempty"`
+	License           string       `yaml:",omitempty"`
+	LicenseURL        string       `yaml:"license_url,omitempty"`
+	Copyright         string       `yaml:",omitempty"`
+	Tags              []string     `yaml:",omitempty"`
+	ReleaseNotes      string       `yaml:"release_notes,omitempty"`
+	ReleaseNotesURL   string       `yaml:"release_notes_url,omitempty"`
+	URLTemplate       string       `yaml:"url_template,omitempty"`
+	Repository        Repo         `yaml:",omitempty"`
+	Base              Repo         `yaml:",omitempty"`
+	Path              string       `yaml:",omitempty"`
+	Branch            string       `yaml:",omitempty"`
+	CommitAuthor      CommitAuthor `yaml:"commit_author,omitempty"`
+	SkipUpload        string       `yaml:"skip_upload,omitempty"`
+	If                string       `yaml:"if,omitempty"`
+}
+
+// Krew contains the config of the krew plugin manifest of a kubectl plugin,
+// proposed to the krew index through a pull request
+type Krew struct {
+	Name             string       `yaml:",omitempty"`
+	IDs              []string     `yaml:"ids,omitempty"`
+	Goarm            string       `yaml:"goarm,omitempty"`
+	ShortDescription string       `yaml:"short_description,omitempty"`
+	Description      string       `yaml:",omitempty"`
+	Homepage         string       `yaml:",omitempty"`
+	Caveats          string       `yaml:",omitempty"`
+	License          string       `yaml:",omitempty"`
+	URLTemplate      string       `yaml:"url_template,omitempty"`
+	Repository       Repo         `yaml:",omitempty"`
+	Base             Repo         `yaml:",omitempty"`
+	Branch           string       `yaml:",omitempty"`
+	CommitAuthor     CommitAuthor `yaml:"commit_author,omitempty"`
+	SkipUpload       string       `yaml:"skip_upload,omitempty"`
+	If               string       `yaml:"if,omitempty"`
 }
 
 // CommitAuthor is the author of a Git commit
@@ -138,21 +329,55 @@ func (a *FlagArray) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 // Build contains the build configuration section
 type Build struct {
-	ID       string         `yaml:",omitempty"`
-	Goos     []string       `yaml:",omitempty"`
-	Goarch   []string       `yaml:",omitempty"`
-	Goarm    []string       `yaml:",omitempty"`
-	Targets  []string       `yaml:",omitempty"`
-	Ignore   []IgnoredBuild `yaml:",omitempty"`
-	Main     string         `yaml:",omitempty"`
-	Ldflags  StringArray    `yaml:",omitempty"`
-	Flags    FlagArray      `yaml:",omitempty"`
-	Binary   string         `yaml:",omitempty"`
-	Hooks    Hooks          `yaml:",omitempty"`
-	Env      []string       `yaml:",omitempty"`
-	Lang     string         `yaml:",omitempty"`
-	Asmflags StringArray    `yaml:",omitempty"`
-	Gcflags  StringArray    `yaml:",omitempty"`
+	ID             string            `yaml:",omitempty"`
+	Goos           []string          `yaml:",omitempty"`
+	Goarch         []string          `yaml:",omitempty"`
+	Goarm          []string          `yaml:",omitempty"`
+	Targets        []string          `yaml:",omitempty"`
+	Ignore         []IgnoredBuild    `yaml:",omitempty"`
+	Main           string            `yaml:",omitempty"`
+	Ldflags        StringArray       `yaml:",omitempty"`
+	LdflagsPresets []string          `yaml:"ldflags_presets,omitempty"`
+	LdflagsPackage string            `yaml:"ldflags_package,omitempty"`
+	Flags          FlagArray         `yaml:",omitempty"`
+	Binary         string            `yaml:",omitempty"`
+	Hooks          Hooks             `yaml:",omitempty"`
+	Env            []string          `yaml:",omitempty"`
+	Lang           string            `yaml:",omitempty"`
+	Asmflags       StringArray       `yaml:",omitempty"`
+	Gcflags        StringArray       `yaml:",omitempty"`
+	Generate       bool              `yaml:",omitempty"`
+	Test           bool              `yaml:",omitempty"`
+	Containers     map[string]string `yaml:",omitempty"`
+	CrossCompile   CrossCompile      `yaml:"cross_compile,omitempty"`
+}
+
+// CrossCompile builds the cgo targets in a cross compiler image
+type CrossCompile struct {
+	Enabled bool   `yaml:",omitempty"`
+	Image   string `yaml:",omitempty"`
+	Libc    string `yaml:",omitempty"`
+}
+
+// BuilderPlugin is an external builder, registered for the given lang
+type BuilderPlugin struct {
+	Lang string `yaml:",omitempty"`
+	Cmd  string `yaml:",omitempty"`
+}
+
+// Toolchain is the C toolchain used to build a given target when cgo is
+// enabled
+type Toolchain struct {
+	CC  string `yaml:"cc,omitempty"`
+	CXX string `yaml:"cxx,omitempty"`
+	AR  string `yaml:"ar,omitempty"`
+}
+
+// UniversalBinary setups macos universal binaries
+type UniversalBinary struct {
+	ID           string `yaml:"id,omitempty"`
+	NameTemplate string `yaml:"name_template,omitempty"`
+	Replace      bool   `yaml:",omitempty"`
 }
 
 // FormatOverride is used to specify a custom format for a specific GOOS.
@@ -163,26 +388,87 @@ type FormatOverride struct {
 
 // Archive config used for the archive
 type Archive struct {
-	ID              string            `yaml:",omitempty"`
-	Builds          []string          `yaml:",omitempty"`
-	NameTemplate    string            `yaml:"name_template,omitempty"`
-	Replacements    map[string]string `yaml:",omitempty"`
-	Format          string            `yaml:",omitempty"`
-	FormatOverrides []FormatOverride  `yaml:"format_overrides,omitempty"`
-	WrapInDirectory string            `yaml:"wrap_in_directory,omitempty"`
-	Files           []string          `yaml:",omitempty"`
+	ID               string            `yaml:",omitempty"`
+	Builds           []string          `yaml:",omitempty"`
+	NameTemplate     string            `yaml:"name_template,omitempty"`
+	Replacements     map[string]string `yaml:",omitempty"`
+	Format           string            `yaml:",omitempty"`
+	FormatOverrides  []FormatOverride  `yaml:"format_overrides,omitempty"`
+	CompressionLevel int               `yaml:"compression_level,omitempty"`
+	Reproducible     bool              `yaml:",omitempty"`
+	PreserveSymlinks bool              `yaml:"preserve_symlinks,omitempty"`
+	Manifest         string            `yaml:",omitempty"`
+	WrapInDirectory  string            `yaml:"wrap_in_directory,omitempty"`
+	Files            []File            `yaml:",omitempty"`
+	If               string            `yaml:"if,omitempty"`
+}
+
+// File is a file or glob to be added to an archive
+type File struct {
+	Source      string      `yaml:"src,omitempty"`
+	Destination string      `yaml:"dst,omitempty"`
+	StripParent bool        `yaml:"strip_parent,omitempty"`
+	Mode        os.FileMode `yaml:",omitempty"`
+	Contents    string      `yaml:",omitempty"`
+}
+
+// UnmarshalYAML is a custom unmarshaler that also accepts a plain glob
+func (f *File) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var glob string
+	if err := unmarshal(&glob); err == nil {
+		*f = File{Source: glob}
+		return nil
+	}
+	type file File
+	var t file
+	if err := unmarshal(&t); err != nil {
+		return err
+	}
+	*f = File(t)
+	return nil
+}
+
+// Source configures the source archive
+type Source struct {
+	Enabled        bool     `yaml:",omitempty"`
+	NameTemplate   string   `yaml:"name_template,omitempty"`
+	Format         string   `yaml:",omitempty"`
+	PrefixTemplate string   `yaml:"prefix_template,omitempty"`
+	Files          []string `yaml:",omitempty"`
+	Reproducible   bool     `yaml:",omitempty"`
 }
 
 // Release config used for the GitHub/GitLab release
 type Release struct {
-	GitHub       Repo     `yaml:",omitempty"`
-	GitLab       Repo     `yaml:",omitempty"`
-	Gitea        Repo     `yaml:",omitempty"`
-	Draft        bool     `yaml:",omitempty"`
-	Disable      bool     `yaml:",omitempty"`
-	Prerelease   string   `yaml:",omitempty"`
-	NameTemplate string   `yaml:"name_template,omitempty"`
-	IDs          []string `yaml:"ids,omitempty"`
+	GitHub        Repo     `yaml:",omitempty"`
+	GitLab        Repo     `yaml:",omitempty"`
+	Gitea         Repo     `yaml:",omitempty"`
+	Draft         bool     `yaml:",omitempty"`
+	Disable       bool     `yaml:",omitempty"`
+	Prerelease    string   `yaml:",omitempty"`
+	NameTemplate  string   `yaml:"name_template,omitempty"`
+	IDs           []string `yaml:"ids,omitempty"`
+	IncludeConfig bool     `yaml:"include_config,omitempty"`
+	Header        string   `yaml:",omitempty"`
+	Footer        string   `yaml:",omitempty"`
+	Mode          string   `yaml:",omitempty"`
+
+	DiscussionCategoryName string      `yaml:"discussion_category_name,omitempty"`
+	ExtraFiles             []ExtraFile `yaml:"extra_files,omitempty"`
+}
+
+// ExtraFile are files matching a glob to upload to the release
+type ExtraFile struct {
+	Glob         string `yaml:",omitempty"`
+	NameTemplate string `yaml:"name_template,omitempty"`
+}
+
+// Milestone configures the closing of the milestone of the release
+type Milestone struct {
+	Repo         Repo   `yaml:",omitempty"`
+	Close        bool   `yaml:",omitempty"`
+	FailOnError  bool   `yaml:"fail_on_error,omitempty"`
+	NameTemplate string `yaml:"name_template,omitempty"`
 }
 
 // NFPM config
@@ -190,15 +476,32 @@ type NFPM struct {
 	NFPMOverridables `yaml:",inline"`
 	Overrides        map[string]NFPMOverridables `yaml:"overrides,omitempty"`
 
-	ID          string   `yaml:",omitempty"`
-	Builds      []string `yaml:",omitempty"`
-	Formats     []string `yaml:",omitempty"`
-	Vendor      string   `yaml:",omitempty"`
-	Homepage    string   `yaml:",omitempty"`
-	Maintainer  string   `yaml:",omitempty"`
-	Description string   `yaml:",omitempty"`
-	License     string   `yaml:",omitempty"`
-	Bindir      string   `yaml:",omitempty"`
+	ID          string        `yaml:",omitempty"`
+	Builds      []string      `yaml:",omitempty"`
+	Formats     []string      `yaml:",omitempty"`
+	Vendor      string        `yaml:",omitempty"`
+	Homepage    string        `yaml:",omitempty"`
+	Maintainer  string        `yaml:",omitempty"`
+	Description string        `yaml:",omitempty"`
+	License     string        `yaml:",omitempty"`
+	Bindir      string        `yaml:",omitempty"`
+	Desktop     Desktop       `yaml:"desktop,omitempty"`
+	Signature   NFPMSignature `yaml:"signature,omitempty"`
+	If          string        `yaml:"if,omitempty"`
+}
+
+// NFPMSignature is used to embed a signature in the deb and rpm packages
+type NFPMSignature struct {
+	KeyID      string `yaml:"key_id,omitempty"`
+	KeyFile    string `yaml:"key_file,omitempty"`
+	Key        string `yaml:"key,omitempty"`
+	Passphrase string `yaml:"passphrase,omitempty"`
+}
+
+// Desktop is a freedesktop.org desktop entry and its icons
+type Desktop struct {
+	File  string            `yaml:"file,omitempty"`
+	Icons map[string]string `yaml:"icons,omitempty"`
 }
 
 // NFPMScripts is used to specify maintainer scripts
@@ -221,16 +524,75 @@ type NFPMOverridables struct {
 	EmptyFolders []string          `yaml:"empty_folders,omitempty"`
 	Files        map[string]string `yaml:",omitempty"`
 	ConfigFiles  map[string]string `yaml:"config_files,omitempty"`
+	Symlinks     map[string]string `yaml:"symlinks,omitempty"`
+	SystemdUnits []string          `yaml:"systemd_units,omitempty"`
 	Scripts      NFPMScripts       `yaml:"scripts,omitempty"`
 }
 
 // Sign config
 type Sign struct {
+	Cmd          string   `yaml:"cmd,omitempty"`
+	Args         []string `yaml:"args,omitempty"`
+	Env          []string `yaml:"env,omitempty"`
+	Stdin        *string  `yaml:"stdin,omitempty"`
+	StdinFile    string   `yaml:"stdin_file,omitempty"`
+	Signature    string   `yaml:"signature,omitempty"`
+	Certificate  string   `yaml:"certificate,omitempty"`
+	Key          string   `yaml:"key,omitempty"`
+	KeyFile      string   `yaml:"key_file,omitempty"`
+	PublicKey    string   `yaml:"public_key,omitempty"`
+	Artifacts    string   `yaml:"artifacts,omitempty"`
+	IDs          []string `yaml:"ids,omitempty"`
+	TimestampURL string   `yaml:"timestamp_url,omitempty"`
+	If           string   `yaml:"if,omitempty"`
+}
+
+// Authenticode config used to sign the windows binaries
+type Authenticode struct {
+	Enabled      bool     `yaml:",omitempty"`
+	IDs          []string `yaml:"ids,omitempty"`
+	Cmd          string   `yaml:"cmd,omitempty"`
+	Args         []string `yaml:"args,omitempty"`
+	Certificate  string   `yaml:"certificate,omitempty"`
+	Key          string   `yaml:"key,omitempty"`
+	Password     string   `yaml:"password,omitempty"`
+	Description  string   `yaml:"description,omitempty"`
+	TimestampURL string   `yaml:"timestamp_url,omitempty"`
+	If           string   `yaml:"if,omitempty"`
+}
+
+// Notarize config used to codesign and notarize the darwin binaries
+type Notarize struct {
+	IDs             []string `yaml:"ids,omitempty"`
+	Identity        string   `yaml:"identity,omitempty"`
+	Entitlements    string   `yaml:"entitlements,omitempty"`
+	KeychainProfile string   `yaml:"keychain_profile,omitempty"`
+	Key             string   `yaml:"key,omitempty"`
+	KeyID           string   `yaml:"key_id,omitempty"`
+	Issuer          string   `yaml:"issuer,omitempty"`
+	Timeout         string   `yaml:"timeout,omitempty"`
+	Staple          bool     `yaml:",omitempty"`
+	If              string   `yaml:"if,omitempty"`
+}
+
+// Provenance config used to attest how the artifacts were built
+type Provenance struct {
+	Enabled      bool     `yaml:",omitempty"`
+	NameTemplate string   `yaml:"name_template,omitempty"`
+	Artifacts    string   `yaml:"artifacts,omitempty"`
+	IDs          []string `yaml:"ids,omitempty"`
+	BuilderID    string   `yaml:"builder_id,omitempty"`
+}
+
+// SBOM config
+type SBOM struct {
 	Cmd       string   `yaml:"cmd,omitempty"`
+	Env       []string `yaml:"env,omitempty"`
 	Args      []string `yaml:"args,omitempty"`
-	Signature string   `yaml:"signature,omitempty"`
+	Document  string   `yaml:"document,omitempty"`
 	Artifacts string   `yaml:"artifacts,omitempty"`
 	IDs       []string `yaml:"ids,omitempty"`
+	If        string   `yaml:"if,omitempty"`
 }
 
 // SnapcraftAppMetadata for the binaries that will be in the snap package
@@ -247,6 +609,8 @@ type Snapcraft struct {
 	Replacements map[string]string `yaml:",omitempty"`
 	Publish      bool              `yaml:",omitempty"`
 
+	ChannelTemplates []string `yaml:"channel_templates,omitempty"`
+
 	ID          string                          `yaml:",omitempty"`
 	Builds      []string                        `yaml:",omitempty"`
 	Name        string                          `yaml:",omitempty"`
@@ -258,6 +622,65 @@ type Snapcraft struct {
 	Confinement string                          `yaml:",omitempty"`
 	Apps        map[string]SnapcraftAppMetadata `yaml:",omitempty"`
 	Plugs       map[string]interface{}          `yaml:",omitempty"`
+	If          string                          `yaml:"if,omitempty"`
+}
+
+// Flatpak config
+type Flatpak struct {
+	ID             string            `yaml:",omitempty"`
+	Builds         []string          `yaml:",omitempty"`
+	NameTemplate   string            `yaml:"name_template,omitempty"`
+	AppID          string            `yaml:"app_id,omitempty"`
+	Runtime        string            `yaml:",omitempty"`
+	RuntimeVersion string            `yaml:"runtime_version,omitempty"`
+	SDK            string            `yaml:"sdk,omitempty"`
+	Command        string            `yaml:",omitempty"`
+	Branch         string            `yaml:",omitempty"`
+	FinishArgs     []string          `yaml:"finish_args,omitempty"`
+	Desktop        Desktop           `yaml:"desktop,omitempty"`
+	Files          map[string]string `yaml:",omitempty"`
+	Repo           string            `yaml:",omitempty"`
+	If             string            `yaml:"if,omitempty"`
+}
+
+// AppImage config
+type AppImage struct {
+	ID             string            `yaml:",omitempty"`
+	Builds         []string          `yaml:",omitempty"`
+	NameTemplate   string            `yaml:"name_template,omitempty"`
+	Command        string            `yaml:",omitempty"`
+	Desktop        Desktop           `yaml:"desktop,omitempty"`
+	AppRunTemplate string            `yaml:"apprun_template,omitempty"`
+	Files          map[string]string `yaml:",omitempty"`
+	If             string            `yaml:"if,omitempty"`
+}
+
+// MSI config
+type MSI struct {
+	ID           string   `yaml:",omitempty"`
+	Builds       []string `yaml:",omitempty"`
+	NameTemplate string   `yaml:"name_template,omitempty"`
+	WXS          string   `yaml:"wxs,omitempty"`
+	Name         string   `yaml:",omitempty"`
+	Manufacturer string   `yaml:",omitempty"`
+	UpgradeCode  string   `yaml:"upgrade_code,omitempty"`
+	InstallDir   string   `yaml:"install_dir,omitempty"`
+	Path         bool     `yaml:",omitempty"`
+	ExtraFiles   []string `yaml:"extra_files,omitempty"`
+	If           string   `yaml:"if,omitempty"`
+}
+
+// MacOSPkg config used to build macOS installer packages
+type MacOSPkg struct {
+	ID              string   `yaml:",omitempty"`
+	Builds          []string `yaml:",omitempty"`
+	NameTemplate    string   `yaml:"name_template,omitempty"`
+	Identifier      string   `yaml:",omitempty"`
+	InstallLocation string   `yaml:"install_location,omitempty"`
+	Scripts         string   `yaml:",omitempty"`
+	SigningIdentity string   `yaml:"signing_identity,omitempty"`
+	ExtraFiles      []string `yaml:"extra_files,omitempty"`
+	If              string   `yaml:"if,omitempty"`
 }
 
 // Snapshot config
@@ -267,21 +690,73 @@ type Snapshot struct {
 
 // Checksum config
 type Checksum struct {
-	NameTemplate string `yaml:"name_template,omitempty"`
-	Algorithm    string `yaml:"algorithm,omitempty"`
+	NameTemplate string   `yaml:"name_template,omitempty"`
+	Algorithm    string   `yaml:"algorithm,omitempty"`
+	Algorithms   []string `yaml:"algorithms,omitempty"`
+	Split        bool     `yaml:"split,omitempty"`
 }
 
 // Docker image config
 type Docker struct {
-	Binaries           []string `yaml:",omitempty"`
-	Goos               string   `yaml:",omitempty"`
-	Goarch             string   `yaml:",omitempty"`
-	Goarm              string   `yaml:",omitempty"`
-	Dockerfile         string   `yaml:",omitempty"`
-	ImageTemplates     []string `yaml:"image_templates,omitempty"`
-	SkipPush           string   `yaml:"skip_push,omitempty"`
-	Files              []string `yaml:"extra_files,omitempty"`
-	BuildFlagTemplates []string `yaml:"build_flag_templates,omitempty"`
+	Binaries           []string          `yaml:",omitempty"`
+	Goos               string            `yaml:",omitempty"`
+	Goarch             string            `yaml:",omitempty"`
+	Goarm              string            `yaml:",omitempty"`
+	Dockerfile         string            `yaml:",omitempty"`
+	Use                string            `yaml:",omitempty"`
+	BaseImage          string            `yaml:"base_image,omitempty"`
+	ImageTemplates     []string          `yaml:"image_templates,omitempty"`
+	SkipPush           string            `yaml:"skip_push,omitempty"`
+	Files              []string          `yaml:"extra_files,omitempty"`
+	BuildFlagTemplates []string          `yaml:"build_flag_templates,omitempty"`
+	Save               bool              `yaml:",omitempty"`
+	SaveNameTemplate   string            `yaml:"save_name_template,omitempty"`
+	Labels             map[string]string `yaml:"labels,omitempty"`
+	Attest             DockerAttest      `yaml:"attest,omitempty"`
+	If                 string            `yaml:"if,omitempty"`
+}
+
+// DockerAttest config, the attestations attached to the pushed images
+type DockerAttest struct {
+	SBOM       bool     `yaml:"sbom,omitempty"`
+	Provenance bool     `yaml:"provenance,omitempty"`
+	Key        string   `yaml:"key,omitempty"`
+	KeyFile    string   `yaml:"key_file,omitempty"`
+	Env        []string `yaml:"env,omitempty"`
+}
+
+// DockerRegistry config, the credentials used to push images to a registry
+type DockerRegistry struct {
+	Registry         string `yaml:",omitempty"`
+	Username         string `yaml:",omitempty"`
+	Password         string `yaml:",omitempty"`
+	CredentialHelper string `yaml:"credential_helper,omitempty"`
+}
+
+// DockerManifest config, a manifest list of per platform images
+type DockerManifest struct {
+	NameTemplate   string            `yaml:"name_template,omitempty"`
+	ImageTemplates []string          `yaml:"image_templates,omitempty"`
+	Use            string            `yaml:"use,omitempty"`
+	Annotations    map[string]string `yaml:"annotations,omitempty"`
+	CreateFlags    []string          `yaml:"create_flags,omitempty"`
+	PushFlags      []string          `yaml:"push_flags,omitempty"`
+	SkipPush       string            `yaml:"skip_push,omitempty"`
+}
+
+// Buildpack image config, built with cloud native buildpacks
+type Buildpack struct {
+	Binaries       []string `yaml:",omitempty"`
+	Goos           string   `yaml:",omitempty"`
+	Goarch         string   `yaml:",omitempty"`
+	Goarm          string   `yaml:",omitempty"`
+	Builder        string   `yaml:",omitempty"`
+	Buildpacks     []string `yaml:",omitempty"`
+	Env            []string `yaml:",omitempty"`
+	ImageTemplates []string `yaml:"image_templates,omitempty"`
+	SkipPush       string   `yaml:"skip_push,omitempty"`
+	Files          []string `yaml:"extra_files,omitempty"`
+	If             string   `yaml:"if,omitempty"`
 }
 
 // Filters config
@@ -305,11 +780,61 @@ type EnvFiles struct {
 	GiteaToken  string `yaml:"gitea_token,omitempty"`
 }
 
+// GitAuth config used to authenticate git operations against remotes
+type GitAuth struct {
+	CredentialHelper string `yaml:"credential_helper,omitempty"`
+	SSHCommand       string `yaml:"ssh_command,omitempty"`
+}
+
+// TagAndPush config used to create and push the release tag
+type TagAndPush struct {
+	Enabled bool    `yaml:",omitempty"`
+	Tag     string  `yaml:",omitempty"`
+	Sign    bool    `yaml:",omitempty"`
+	Remote  string  `yaml:",omitempty"`
+	Auth    GitAuth `yaml:",omitempty"`
+}
+
+// GitNote config used to record the release in a git note
+type GitNote struct {
+	Enabled bool    `yaml:",omitempty"`
+	Ref     string  `yaml:",omitempty"`
+	Sign    bool    `yaml:",omitempty"`
+	Remote  string  `yaml:",omitempty"`
+	Auth    GitAuth `yaml:",omitempty"`
+}
+
+// Git config
+type Git struct {
+	Auth       GitAuth    `yaml:",omitempty"`
+	TagAndPush TagAndPush `yaml:"tag_and_push,omitempty"`
+	Note       GitNote    `yaml:",omitempty"`
+}
+
 // Before config
 type Before struct {
 	Hooks []string `yaml:",omitempty"`
 }
 
+// Phases are the hooks run at given points of the release, after the
+// pipes they follow are done
+type Phases struct {
+	AfterBuild    []string `yaml:"after_build,omitempty"`
+	BeforeArchive []string `yaml:"before_archive,omitempty"`
+	AfterSign     []string `yaml:"after_sign,omitempty"`
+	BeforePublish []string `yaml:"before_publish,omitempty"`
+	AfterPublish  []string `yaml:"after_publish,omitempty"`
+}
+
+// Generator is a code generation step run before the builds, skipped when
+// its inputs didn't change since the last run
+type Generator struct {
+	Cmd     string   `yaml:",omitempty"`
+	Env     []string `yaml:",omitempty"`
+	Inputs  []string `yaml:",omitempty"`
+	Outputs []string `yaml:",omitempty"`
+}
+
 // S3 contains s3 config
 type S3 struct {
 	Region   string   `yaml:",omitempty"`
@@ -323,55 +848,178 @@ type S3 struct {
 
 // Blob contains config for GO CDK blob
 type Blob struct {
-	Bucket   string   `yaml:",omitempty"`
-	Provider string   `yaml:",omitempty"`
-	Folder   string   `yaml:",omitempty"`
-	KMSKey   string   `yaml:",omitempty"`
-	IDs      []string `yaml:"ids,omitempty"`
+	Bucket     string   `yaml:",omitempty"`
+	Provider   string   `yaml:",omitempty"`
+	Region     string   `yaml:",omitempty"`
+	Endpoint   string   `yaml:",omitempty"`
+	DisableSSL bool     `yaml:"disable_ssl,omitempty"`
+	Folder     string   `yaml:",omitempty"`
+	KMSKey     string   `yaml:",omitempty"`
+	IDs        []string `yaml:"ids,omitempty"`
+	If         string   `yaml:"if,omitempty"`
 }
 
 // Put HTTP upload configuration
 type Put struct {
-	Name           string   `yaml:",omitempty"`
-	IDs            []string `yaml:"ids,omitempty"`
-	Target         string   `yaml:",omitempty"`
-	Username       string   `yaml:",omitempty"`
-	Mode           string   `yaml:",omitempty"`
-	ChecksumHeader string   `yaml:"checksum_header,omitempty"`
-	TrustedCerts   string   `yaml:"trusted_certificates,omitempty"`
-	Checksum       bool     `yaml:",omitempty"`
-	Signature      bool     `yaml:",omitempty"`
+	Name            string            `yaml:",omitempty"`
+	IDs             []string          `yaml:"ids,omitempty"`
+	Target          string            `yaml:",omitempty"`
+	Username        string            `yaml:",omitempty"`
+	Mode            string            `yaml:",omitempty"`
+	Method          string            `yaml:",omitempty"`
+	CustomHeaders   map[string]string `yaml:"custom_headers,omitempty"`
+	ChecksumHeader  string            `yaml:"checksum_header,omitempty"`
+	ChecksumHeaders map[string]string `yaml:"checksum_headers,omitempty"`
+	TrustedCerts    string            `yaml:"trusted_certificates,omitempty"`
+	Checksum        bool              `yaml:",omitempty"`
+	Signature       bool              `yaml:",omitempty"`
+}
+
+// GitHubPackage configures the upload of library artifacts to GitHub
+// Packages
+type GitHubPackage struct {
+	Type     string   `yaml:",omitempty"`
+	Repo     Repo     `yaml:",omitempty"`
+	Files    []string `yaml:",omitempty"`
+	Group    string   `yaml:",omitempty"`
+	Artifact string   `yaml:",omitempty"`
+	Version  string   `yaml:",omitempty"`
+}
+
+// VersionBump configures the pull request that updates version references
+// in the repository files after a release
+type VersionBump struct {
+	Files        []string     `yaml:",omitempty"`
+	Repo         Repo         `yaml:",omitempty"`
+	Branch       string       `yaml:",omitempty"`
+	Title        string       `yaml:",omitempty"`
+	CommitAuthor CommitAuthor `yaml:"commit_author,omitempty"`
+}
+
+// Metadata is the project metadata shared by the packagers
+type Metadata struct {
+	Description string   `yaml:",omitempty"`
+	Homepage    string   `yaml:",omitempty"`
+	License     string   `yaml:",omitempty"`
+	Maintainers []string `yaml:",omitempty"`
+	Vendor      string   `yaml:",omitempty"`
+}
+
+// Incremental reuses the binaries of unchanged builds from previous runs
+type Incremental struct {
+	Enabled  bool   `yaml:",omitempty"`
+	CacheDir string `yaml:"cache_dir,omitempty"`
+}
+
+// Mirror re-uploads the release assets to another location, and checks that
+// it serves the same files
+type Mirror struct {
+	Name         string   `yaml:",omitempty"`
+	IDs          []string `yaml:"ids,omitempty"`
+	Target       string   `yaml:",omitempty"`
+	DownloadURL  string   `yaml:"download_url,omitempty"`
+	Username     string   `yaml:",omitempty"`
+	TrustedCerts string   `yaml:"trusted_certificates,omitempty"`
+}
+
+// PackageRepo maintains apt, yum and apk repositories of the linux packages
+// in a bucket, or in a folder synced with rsync
+type PackageRepo struct {
+	Bucket       string   `yaml:",omitempty"`
+	Provider     string   `yaml:",omitempty"`
+	Rsync        string   `yaml:"rsync,omitempty"`
+	Folder       string   `yaml:",omitempty"`
+	IDs          []string `yaml:"ids,omitempty"`
+	Distribution string   `yaml:",omitempty"`
+	Component    string   `yaml:",omitempty"`
+	Origin       string   `yaml:",omitempty"`
+	Label        string   `yaml:",omitempty"`
+	SigningKey   string   `yaml:"signing_key,omitempty"`
+	ApkKey       string   `yaml:"apk_key,omitempty"`
+	If           string   `yaml:"if,omitempty"`
+}
+
+// SCP configures the copy of artifacts to remote hosts over scp or sftp
+type SCP struct {
+	Protocol  string   `yaml:",omitempty"`
+	Host      string   `yaml:",omitempty"`
+	Port      int      `yaml:",omitempty"`
+	User      string   `yaml:",omitempty"`
+	Key       string   `yaml:",omitempty"`
+	Folder    string   `yaml:",omitempty"`
+	IDs       []string `yaml:"ids,omitempty"`
+	Checksum  bool     `yaml:",omitempty"`
+	Signature bool     `yaml:",omitempty"`
+	If        string   `yaml:"if,omitempty"`
 }
 
 // Project includes all project configuration
 type Project struct {
-	ProjectName   string      `yaml:"project_name,omitempty"`
-	Env           []string    `yaml:",omitempty"`
-	Release       Release     `yaml:",omitempty"`
-	Brew          Homebrew    `yaml:",omitempty"` // TODO: remove this
-	Brews         []Homebrew  `yaml:",omitempty"`
-	Scoop         Scoop       `yaml:",omitempty"`
-	Builds        []Build     `yaml:",omitempty"`
-	Archive       Archive     `yaml:",omitempty"` // TODO: remove this
-	Archives      []Archive   `yaml:",omitempty"`
-	NFPM          NFPM        `yaml:",omitempty"` // TODO: remove this
-	NFPMs         []NFPM      `yaml:"nfpms,omitempty"`
-	Snapcraft     Snapcraft   `yaml:",omitempty"` // TODO: remove this
-	Snapcrafts    []Snapcraft `yaml:",omitempty"`
-	Snapshot      Snapshot    `yaml:",omitempty"`
-	Checksum      Checksum    `yaml:",omitempty"`
-	Dockers       []Docker    `yaml:",omitempty"`
-	Artifactories []Put       `yaml:",omitempty"`
-	Puts          []Put       `yaml:",omitempty"`
-	S3            []S3        `yaml:"s3,omitempty"`
-	Blob          []Blob      `yaml:"blob,omitempty"` // TODO: remove this
-	Blobs         []Blob      `yaml:"blobs,omitempty"`
-	Changelog     Changelog   `yaml:",omitempty"`
-	Dist          string      `yaml:",omitempty"`
-	Sign          Sign        `yaml:",omitempty"` // TODO: remove this
-	Signs         []Sign      `yaml:",omitempty"`
-	EnvFiles      EnvFiles    `yaml:"env_files,omitempty"`
-	Before        Before      `yaml:",omitempty"`
+	ProjectName       string               `yaml:"project_name,omitempty"`
+	Env               []string             `yaml:",omitempty"`
+	Metadata          Metadata             `yaml:",omitempty"`
+	Release           Release              `yaml:",omitempty"`
+	Brew              Homebrew             `yaml:",omitempty"` // TODO: remove this
+	Brews             []Homebrew           `yaml:",omitempty"`
+	HomebrewCasks     []HomebrewCask       `yaml:"homebrew_casks,omitempty"`
+	Scoop             Scoop                `yaml:",omitempty"` // TODO: remove this
+	Scoops            []Scoop              `yaml:",omitempty"`
+	AURs              []AUR                `yaml:"aurs,omitempty"`
+	Nix               []Nix                `yaml:"nix,omitempty"`
+	Chocolateys       []Chocolatey         `yaml:"chocolateys,omitempty"`
+	Wingets           []Winget             `yaml:"wingets,omitempty"`
+	Krews             []Krew               `yaml:"krews,omitempty"`
+	VersionBump       VersionBump          `yaml:"version_bump,omitempty"`
+	Builds            []Build              `yaml:",omitempty"`
+	BuilderPlugins    []BuilderPlugin      `yaml:"builder_plugins,omitempty"`
+	UniversalBinaries []UniversalBinary    `yaml:"universal_binaries,omitempty"`
+	Archive           Archive              `yaml:",omitempty"` // TODO: remove this
+	Archives          []Archive            `yaml:",omitempty"`
+	Source            Source               `yaml:",omitempty"`
+	NFPM              NFPM                 `yaml:",omitempty"` // TODO: remove this
+	NFPMs             []NFPM               `yaml:"nfpms,omitempty"`
+	Snapcraft         Snapcraft            `yaml:",omitempty"` // TODO: remove this
+	Snapcrafts        []Snapcraft          `yaml:",omitempty"`
+	Flatpaks          []Flatpak            `yaml:"flatpaks,omitempty"`
+	AppImages         []AppImage           `yaml:"appimages,omitempty"`
+	MSI               []MSI                `yaml:"msi,omitempty"`
+	MacOSPkgs         []MacOSPkg           `yaml:"macos_pkgs,omitempty"`
+	Snapshot          Snapshot             `yaml:",omitempty"`
+	Checksum          Checksum             `yaml:",omitempty"`
+	Dockers           []Docker             `yaml:",omitempty"`
+	DockerManifests   []DockerManifest     `yaml:"docker_manifests,omitempty"`
+	DockerRegistries  []DockerRegistry     `yaml:"docker_registries,omitempty"`
+	Buildpacks        []Buildpack          `yaml:",omitempty"`
+	Artifactories     []Put                `yaml:",omitempty"`
+	Puts              []Put                `yaml:",omitempty"` // deprecated
+	Uploads           []Put                `yaml:",omitempty"`
+	Mirrors           []Mirror             `yaml:",omitempty"`
+	GitHubPackages    []GitHubPackage      `yaml:"github_packages,omitempty"`
+	S3                []S3                 `yaml:"s3,omitempty"`
+	Blob              []Blob               `yaml:"blob,omitempty"` // TODO: remove this
+	Blobs             []Blob               `yaml:"blobs,omitempty"`
+	PackageRepos      []PackageRepo        `yaml:"package_repos,omitempty"`
+	SCPs              []SCP                `yaml:"scps,omitempty"`
+	Milestones        []Milestone          `yaml:",omitempty"`
+	Changelog         Changelog            `yaml:",omitempty"`
+	Dist              string               `yaml:",omitempty"`
+	StrictNames       bool                 `yaml:"strict_names,omitempty"`
+	Strict            bool                 `yaml:",omitempty"`
+	Sign              Sign                 `yaml:",omitempty"` // TODO: remove this
+	Signs             []Sign               `yaml:",omitempty"`
+	DockerSigns       []Sign               `yaml:"docker_signs,omitempty"`
+	SBOMs             []SBOM               `yaml:"sboms,omitempty"`
+	Provenance        Provenance           `yaml:",omitempty"`
+	Authenticode      Authenticode         `yaml:",omitempty"`
+	Notarize          []Notarize           `yaml:",omitempty"`
+	EnvFiles          EnvFiles             `yaml:"env_files,omitempty"`
+	ForceToken        string               `yaml:"force_token,omitempty"`
+	Before            Before               `yaml:",omitempty"`
+	Phases            Phases               `yaml:",omitempty"`
+	Generate          []Generator          `yaml:",omitempty"`
+	Git               Git                  `yaml:",omitempty"`
+	Toolchains        map[string]Toolchain `yaml:",omitempty"`
+	Incremental       Incremental          `yaml:",omitempty"`
 
 	// this is a hack ¯\_(ツ)_/¯
 	SingleBuild Build `yaml:"build,omitempty"`
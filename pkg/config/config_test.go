@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
 )
 
 func TestRepo(t *testing.T) {
@@ -71,3 +72,42 @@ func TestConfigWithAnchors(t *testing.T) {
 	_, err := Load("testdata/anchor.yaml")
 	assert.NoError(t, err)
 }
+
+func TestArchiveFiles(t *testing.T) {
+	var archive Archive
+	assert.NoError(t, yaml.UnmarshalStrict([]byte(`
+files:
+  - LICENSE
+  - src: "completions/*"
+    dst: completions
+    strip_parent: true
+`), &archive))
+	assert.Equal(t, []File{
+		{Source: "LICENSE"},
+		{Source: "completions/*", Destination: "completions", StripParent: true},
+	}, archive.Files)
+
+	assert.Error(t, yaml.UnmarshalStrict([]byte(`
+files:
+  - source: LICENSE
+`), &archive))
+}
+
+func TestHomebrewDependencies(t *testing.T) {
+	var brew Homebrew
+	assert.NoError(t, yaml.UnmarshalStrict([]byte(`
+dependencies:
+  - git
+  - name: go
+    type: build
+`), &brew))
+	assert.Equal(t, []HomebrewDependency{
+		{Name: "git"},
+		{Name: "go", Type: "build"},
+	}, brew.Dependencies)
+
+	assert.Error(t, yaml.UnmarshalStrict([]byte(`
+dependencies:
+  - formula: git
+`), &brew))
+}
@@ -19,9 +19,11 @@ import (
 // GitInfo includes tags and diffs used in some point
 type GitInfo struct {
 	CurrentTag  string
+	Branch      string
 	Commit      string
 	ShortCommit string
 	FullCommit  string
+	CommitDate  time.Time
 	URL         string
 }
 
@@ -50,6 +52,14 @@ const (
 	TokenTypeGitea TokenType = "gitea"
 )
 
+// CommittedFile is a file a publisher committed to a repository, like a
+// homebrew formula or a scoop manifest
+type CommittedFile struct {
+	Repo         config.Repo         `json:"repo"`
+	Path         string              `json:"path"`
+	CommitAuthor config.CommitAuthor `json:"commit_author"`
+}
+
 // Context carries along some data through the pipes
 type Context struct {
 	ctx.Context
@@ -60,15 +70,24 @@ type Context struct {
 	Git          GitInfo
 	Artifacts    artifact.Artifacts
 	ReleaseNotes string
-	Version      string
-	Snapshot     bool
-	SkipPublish  bool
-	SkipSign     bool
-	SkipValidate bool
-	RmDist       bool
-	PreRelease   bool
-	Parallelism  int
-	Semver       Semver
+	// ReleaseHeaderFile and ReleaseFooterFile replace the release header
+	// and footer of the config
+	ReleaseHeaderFile string
+	ReleaseFooterFile string
+	ReleaseURL        string
+	Version           string
+	Snapshot          bool
+	SkipPublish       bool
+	SkipSign          bool
+	SkipValidate      bool
+	RmDist            bool
+	PreRelease        bool
+	Parallelism       int
+	PipeParallelism   int
+	Semver            Semver
+	Deprecated        bool
+
+	CommittedFiles []CommittedFile
 }
 
 // Semver represents a semantic version
@@ -93,11 +112,12 @@ func NewWithTimeout(config config.Project, timeout time.Duration) (*Context, ctx
 // Wrap wraps an existing context
 func Wrap(ctx ctx.Context, config config.Project) *Context {
 	return &Context{
-		Context:     ctx,
-		Config:      config,
-		Env:         splitEnv(append(os.Environ(), config.Env...)),
-		Parallelism: 4,
-		Artifacts:   artifact.New(),
+		Context:         ctx,
+		Config:          config,
+		Env:             splitEnv(append(os.Environ(), config.Env...)),
+		Parallelism:     4,
+		PipeParallelism: 1,
+		Artifacts:       artifact.New(),
 	}
 }
 
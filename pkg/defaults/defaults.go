@@ -5,22 +5,48 @@ package defaults
 import (
 	"fmt"
 
+	"github.com/goreleaser/goreleaser/internal/pipe/appimage"
 	"github.com/goreleaser/goreleaser/internal/pipe/archive"
 	"github.com/goreleaser/goreleaser/internal/pipe/artifactory"
+	"github.com/goreleaser/goreleaser/internal/pipe/aur"
+	"github.com/goreleaser/goreleaser/internal/pipe/authenticode"
 	"github.com/goreleaser/goreleaser/internal/pipe/blob"
 	"github.com/goreleaser/goreleaser/internal/pipe/brew"
 	"github.com/goreleaser/goreleaser/internal/pipe/build"
+	"github.com/goreleaser/goreleaser/internal/pipe/buildpacks"
+	"github.com/goreleaser/goreleaser/internal/pipe/cask"
 	"github.com/goreleaser/goreleaser/internal/pipe/checksums"
+	"github.com/goreleaser/goreleaser/internal/pipe/chocolatey"
 	"github.com/goreleaser/goreleaser/internal/pipe/docker"
 	"github.com/goreleaser/goreleaser/internal/pipe/env"
+	"github.com/goreleaser/goreleaser/internal/pipe/flatpak"
+	"github.com/goreleaser/goreleaser/internal/pipe/generate"
+	"github.com/goreleaser/goreleaser/internal/pipe/githubpackages"
+	"github.com/goreleaser/goreleaser/internal/pipe/gitnote"
+	"github.com/goreleaser/goreleaser/internal/pipe/krew"
+	"github.com/goreleaser/goreleaser/internal/pipe/macospkg"
+	"github.com/goreleaser/goreleaser/internal/pipe/milestone"
+	"github.com/goreleaser/goreleaser/internal/pipe/mirror"
+	"github.com/goreleaser/goreleaser/internal/pipe/msi"
 	"github.com/goreleaser/goreleaser/internal/pipe/nfpm"
+	"github.com/goreleaser/goreleaser/internal/pipe/nix"
+	"github.com/goreleaser/goreleaser/internal/pipe/pkgrepo"
 	"github.com/goreleaser/goreleaser/internal/pipe/project"
+	"github.com/goreleaser/goreleaser/internal/pipe/provenance"
+	"github.com/goreleaser/goreleaser/internal/pipe/put"
 	"github.com/goreleaser/goreleaser/internal/pipe/release"
 	"github.com/goreleaser/goreleaser/internal/pipe/s3"
+	"github.com/goreleaser/goreleaser/internal/pipe/sbom"
 	"github.com/goreleaser/goreleaser/internal/pipe/scoop"
+	"github.com/goreleaser/goreleaser/internal/pipe/scp"
 	"github.com/goreleaser/goreleaser/internal/pipe/sign"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapcraft"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapshot"
+	"github.com/goreleaser/goreleaser/internal/pipe/sourcearchive"
+	"github.com/goreleaser/goreleaser/internal/pipe/universalbinary"
+	"github.com/goreleaser/goreleaser/internal/pipe/upload"
+	"github.com/goreleaser/goreleaser/internal/pipe/versionbump"
+	"github.com/goreleaser/goreleaser/internal/pipe/winget"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
@@ -40,16 +66,44 @@ var Defaulters = []Defaulter{
 	snapshot.Pipe{},
 	release.Pipe{},
 	project.Pipe{},
+	generate.Pipe{},
 	build.Pipe{},
+	universalbinary.Pipe{},
+	authenticode.Pipe{},
 	archive.Pipe{},
+	sourcearchive.Pipe{},
 	nfpm.Pipe{},
 	snapcraft.Pipe{},
+	flatpak.Pipe{},
+	appimage.Pipe{},
+	msi.Pipe{},
+	macospkg.Pipe{},
 	checksums.Pipe{},
 	sign.Pipe{},
+	sign.DockerPipe{},
 	docker.Pipe{},
+	docker.ManifestPipe{},
+	buildpacks.Pipe{},
+	sbom.Pipe{},
+	provenance.Pipe{},
+	put.Pipe{},
+	upload.Pipe{},
+	scp.Pipe{},
 	artifactory.Pipe{},
+	githubpackages.Pipe{},
 	s3.Pipe{},
 	blob.Pipe{},
+	pkgrepo.Pipe{},
+	mirror.Pipe{},
+	milestone.Pipe{},
 	brew.Pipe{},
+	cask.Pipe{},
 	scoop.Pipe{},
+	aur.Pipe{},
+	nix.Pipe{},
+	chocolatey.Pipe{},
+	winget.Pipe{},
+	krew.Pipe{},
+	versionbump.Pipe{},
+	gitnote.Pipe{},
 }